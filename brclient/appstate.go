@@ -65,6 +65,10 @@ const (
 	connStateOnline
 
 	urlExchangeRate = "https://explorer.dcrdata.org/api/exchangerate"
+
+	// payApprovalTimeout is how long to wait for the user to approve an
+	// outbound payment before rejecting it.
+	payApprovalTimeout = 5 * time.Minute
 )
 
 type exchangeRate struct {
@@ -125,7 +129,7 @@ type appState struct {
 	connectedMtx   sync.Mutex
 	connected      connState
 	serverAddr     string
-	pushRate       uint64 // milliatoms / byte
+	pushRate       uint64 // milliatoms / KB
 	subRate        uint64 // milliatoms / byte
 	expirationDays uint64
 
@@ -146,11 +150,19 @@ type appState struct {
 	postSumm   clientdb.PostSummary
 	myComments []string // Unreplicated comments
 	postStatus []rpc.PostMetadataStatus
+	feedFilter string // Active search filter of the feed window
 
 	contentMtx  sync.Mutex
 	remoteFiles map[clientintf.UserID]map[clientdb.FileID]clientdb.RemoteFile
 	progressMsg map[clientdb.FileID]*chatMsg
 
+	// uploadProgressMsg tracks the msgs that display the progress of
+	// uploads, keyed by user and file.
+	uploadProgressMsg map[[2]clientdb.FileID]*chatMsg
+
+	// previews tracks the files with outstanding previews.
+	previews map[clientdb.FileID]struct{}
+
 	qlenMtx sync.Mutex
 	qlen    int
 
@@ -158,6 +170,12 @@ type appState struct {
 	gcInvitesMtx sync.Mutex
 	gcInvites    map[string]uint64
 
+	// payApprovals tracks the outbound payments waiting for approval
+	// (approval id => reply chan).
+	payApprovalsMtx sync.Mutex
+	payApprovals    map[uint64]chan bool
+	payApprovalID   uint64
+
 	// Reply chans for notifications that require confirmation.
 	clientIDChan      chan getClientIDReply
 	lnOpenChannelChan chan msgLNOpenChannelReply
@@ -1314,10 +1332,19 @@ func (as *appState) pm(cw *chatWindow, msg string) {
 
 // payTip sends a tip to the user of the given window. This blocks until the
 // tip has been paid.
-func (as *appState) payTip(cw *chatWindow, dcrAmount float64) {
-	m := cw.newInternalMsg(fmt.Sprintf("Sending %.8f DCR as tip", dcrAmount))
+func (as *appState) payTip(cw *chatWindow, dcrAmount float64, memo string,
+	post *clientintf.PostID) {
+
+	msg := fmt.Sprintf("Sending %.8f DCR as tip", dcrAmount)
+	if post != nil {
+		msg += fmt.Sprintf(" for post %s", post.ShortLogID())
+	}
+	if memo != "" {
+		msg += ": " + memo
+	}
+	m := cw.newInternalMsg(msg)
 	as.repaintIfActive(cw)
-	err := as.c.TipUser(as.ctx, cw.uid, dcrAmount)
+	err := as.c.TipUserWithMemo(as.ctx, cw.uid, dcrAmount, memo, post)
 	if err != nil {
 		as.cwHelpMsg("Unable to tip user %q: %v",
 			cw.alias, err)
@@ -1327,6 +1354,83 @@ func (as *appState) payTip(cw *chatWindow, dcrAmount float64) {
 	}
 }
 
+func (as *appState) payPostage(cw *chatWindow, nbMsgs uint32) {
+	m := cw.newInternalMsg(fmt.Sprintf("Paying postage of %d messages", nbMsgs))
+	as.repaintIfActive(cw)
+	err := as.c.PayPostage(as.ctx, cw.uid, nbMsgs)
+	if err != nil {
+		as.cwHelpMsg("Unable to pay postage to user %q: %v",
+			cw.alias, err)
+	} else {
+		cw.setMsgSent(m)
+		as.repaintIfActive(cw)
+	}
+}
+
+// fetchInvoice fetches an invoice from the given user and shows it.
+func (as *appState) fetchInvoice(uid clientintf.UserID, dcrAmount float64, memo string) {
+	nick, _ := as.c.UserNick(uid)
+	as.cwHelpMsg("Fetching invoice for %.8f DCR from %s", dcrAmount,
+		strescape.Nick(nick))
+	req, err := as.c.RequestInvoice(as.ctx, uid, dcrAmount, memo)
+	if err != nil {
+		as.cwHelpMsg("Unable to fetch invoice from %s: %v",
+			strescape.Nick(nick), err)
+		return
+	}
+	as.cwHelpMsg("Fetched invoice %s. Use '/invoice pay %s' to pay it:\n%s",
+		req.ID.ShortLogID(), req.ID.ShortLogID(), req.Invoice)
+}
+
+// payPaymentRequest pays the given payment request.
+func (as *appState) payPaymentRequest(req clientdb.PaymentRequest) {
+	as.cwHelpMsg("Paying %.8f DCR for payment request %s",
+		float64(req.MilliAtoms)/1e11, req.ID.ShortLogID())
+	err := as.c.PayPaymentRequest(as.ctx, req.UID, req.ID)
+	if err != nil {
+		as.cwHelpMsg("Unable to pay payment request %s: %v",
+			req.ID.ShortLogID(), err)
+		return
+	}
+	as.cwHelpMsg("Paid payment request %s", req.ID.ShortLogID())
+}
+
+// showServerCredit fetches and shows the balance of the credit prepaid to the
+// server.
+func (as *appState) showServerCredit() {
+	balance, err := as.c.ServerCreditBalance(as.ctx)
+	if err != nil {
+		as.cwHelpMsg("Unable to fetch server credit balance: %v", err)
+		return
+	}
+	as.cwHelpMsg("Server credit balance: %.8f DCR", float64(balance)/1e11)
+}
+
+// buyServerCredit buys the given amount of credit (in milliatoms) from the
+// server.
+func (as *appState) buyServerCredit(amount uint64) {
+	as.cwHelpMsg("Buying %.8f DCR of server credit", float64(amount)/1e11)
+	balance, fees, err := as.c.BuyServerCredit(as.ctx, amount)
+	if err != nil {
+		as.cwHelpMsg("Unable to buy server credit: %v", err)
+		return
+	}
+	as.cwHelpMsg("Bought server credit (fees %.8f DCR). New balance: "+
+		"%.8f DCR", float64(fees)/1e11, float64(balance)/1e11)
+}
+
+// payPostsSubscription pays for the subscription to the posts of the given
+// user.
+func (as *appState) payPostsSubscription(uid clientintf.UserID) {
+	nick, _ := as.c.UserNick(uid)
+	as.cwHelpMsg("Paying for subscription to %s posts", strescape.Nick(nick))
+	err := as.c.PayPostsSubscription(as.ctx, uid)
+	if err != nil {
+		as.cwHelpMsg("Unable to pay for subscription to %s posts: %v",
+			strescape.Nick(nick), err)
+	}
+}
+
 // block blocks a user.
 func (as *appState) block(cw *chatWindow, uid clientintf.UserID) {
 	m := cw.newInternalMsg("Blocked user")
@@ -1415,30 +1519,34 @@ func (as *appState) listUserContent(cw *chatWindow, dir, filter string) {
 	as.repaintIfActive(cw)
 }
 
-func (as *appState) getUserContent(cw *chatWindow, filename string) {
+// remoteFileID returns the ID of the file with the given name (or ID) offered
+// by the user of the chat window.
+func (as *appState) remoteFileID(cw *chatWindow, filename string) (clientdb.FileID, clientdb.RemoteFile, bool) {
 	var rf clientdb.RemoteFile
-	var fid, emptyFID clientdb.FileID
+	var fid clientdb.FileID
 
 	// If `filename` is a file ID, use that directly, otherwise try to find
 	// the file id of a file we know the user has.
-	if err := fid.FromString(filename); err != nil {
-		fid = emptyFID
-		as.contentMtx.Lock()
-		if userFiles, ok := as.remoteFiles[cw.uid]; ok {
-			for id, file := range userFiles {
-				if file.Metadata.Filename == filename {
-					fid = id
-					rf = file
-					break
-				}
-			}
+	if err := fid.FromString(filename); err == nil {
+		return fid, rf, true
+	}
+	as.contentMtx.Lock()
+	userFiles := as.remoteFiles[cw.uid]
+	for id, file := range userFiles {
+		if file.Metadata.Filename == filename {
+			as.contentMtx.Unlock()
+			return id, file, true
 		}
-		as.contentMtx.Unlock()
 	}
+	as.contentMtx.Unlock()
+	as.cwHelpMsg("Cannot find file ID for file %q. Try `/ft ls <user>` first.",
+		filename)
+	return fid, rf, false
+}
 
-	if fid == emptyFID {
-		as.cwHelpMsg("Cannot find file ID for file %q. Try `/ft ls <user>` first.",
-			filename)
+func (as *appState) getUserContent(cw *chatWindow, filename string) {
+	fid, rf, ok := as.remoteFileID(cw, filename)
+	if !ok {
 		return
 	}
 
@@ -1457,6 +1565,91 @@ func (as *appState) getUserContent(cw *chatWindow, filename string) {
 	as.repaintIfActive(cw)
 }
 
+// previewUserContent fetches the first bytes of the given file to preview it.
+func (as *appState) previewUserContent(cw *chatWindow, filename string, size uint64) {
+	fid, _, ok := as.remoteFileID(cw, filename)
+	if !ok {
+		return
+	}
+
+	as.contentMtx.Lock()
+	as.previews[fid] = struct{}{}
+	as.contentMtx.Unlock()
+
+	err := as.c.FetchFileRange(cw.uid, fid, 0, size)
+	if err != nil {
+		as.contentMtx.Lock()
+		delete(as.previews, fid)
+		as.contentMtx.Unlock()
+		as.cwHelpMsg("Unable to fetch preview: %v", err)
+		return
+	}
+	as.cwHelpMsg("Fetching the first %s of file %s for preview",
+		hbytes(int64(size)), filename)
+	as.repaintIfActive(cw)
+}
+
+// showPreview opens the fetched range of a file being previewed with the
+// external viewer configured for its type.
+func (as *appState) showPreview(cw *chatWindow, fid clientdb.FileID, r clientdb.FileRange) error {
+	var fm *rpc.FileMetadata
+	fds, err := as.c.ListDownloads()
+	if err != nil {
+		return err
+	}
+	for i := range fds {
+		if fds[i].FID == fid {
+			fm = fds[i].Metadata
+			break
+		}
+	}
+	if fm == nil {
+		// The range covered the entire file, so the download was
+		// completed.
+		path, err := as.c.HasDownloadedFile(fid)
+		if err != nil {
+			return err
+		}
+		if path == "" {
+			return fmt.Errorf("download of file %s not found", fid)
+		}
+		return as.viewDownloadedFile(path)
+	}
+	if fm.Compression() != "" {
+		return fmt.Errorf("file %q is compressed and cannot be "+
+			"previewed before the download completes", fm.Filename)
+	}
+
+	data, err := as.c.ReadFileRange(fid, r.Offset, r.Length)
+	if err != nil {
+		return err
+	}
+
+	// Keep the extension of the file, as some viewers rely on it.
+	f, err := os.CreateTemp("", tempFileTemplate+"*"+filepath.Ext(fm.Filename))
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %v", err)
+	}
+	if _, err = f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write temp file: %v", err)
+	}
+	f.Close()
+
+	typ := clientdb.MetadataMIMEType(fm)
+	prog := programByMimeType(as.mimeMap, typ)
+	if prog == "" {
+		cw.newHelpMsg("Preview of %s of file %q saved in %s (no external "+
+			"viewer configured for %q)", hbytes(int64(len(data))),
+			fm.Filename, f.Name(), typ)
+		return nil
+	}
+	cw.newHelpMsg("Opening preview of %s of file %q with %s",
+		hbytes(int64(len(data))), fm.Filename, prog)
+	as.sendMsg(msgViewFile{prog: prog, path: f.Name(), removeAfter: true})
+	return nil
+}
+
 func (as *appState) subscribeToPosts(uid clientintf.UserID) error {
 	cw := as.findChatWindow(uid)
 	nick, err := as.c.UserNick(uid)
@@ -1796,6 +1989,110 @@ func (as *appState) createPost(post string) {
 	}
 }
 
+func (as *appState) createPaywalledPost(summary, body string, dcrPrice float64) {
+	as.loadPosts()
+	summ, err := as.c.CreatePaywalledPost(summary, body, "", dcrPrice)
+	if err != nil {
+		as.cwHelpMsg("Unable to create paywalled post: %v", err)
+	} else {
+		as.cwHelpMsg("Created paywalled post %s", summ.ID)
+		as.postsMtx.Lock()
+		as.posts = append(as.posts, summ)
+		as.postsMtx.Unlock()
+		as.sendMsg(summ)
+	}
+}
+
+func (as *appState) buyPaywalledPost(from clientintf.UserID, pid clientintf.PostID) {
+	as.diagMsg("Paying to unlock post %s", pid)
+	err := as.c.BuyPaywalledPost(as.ctx, from, pid)
+	if err != nil {
+		as.diagMsg("Unable to unlock post: %v", err)
+	}
+}
+
+// findDraft returns the post draft that has an id with the given prefix.
+func (as *appState) findDraft(prefix string) (clientdb.PostDraft, error) {
+	drafts, err := as.c.ListDrafts()
+	if err != nil {
+		return clientdb.PostDraft{}, err
+	}
+	var res []clientdb.PostDraft
+	for _, d := range drafts {
+		if strings.HasPrefix(d.ID.String(), prefix) {
+			res = append(res, d)
+		}
+	}
+	switch len(res) {
+	case 0:
+		return clientdb.PostDraft{}, fmt.Errorf("draft %q not found", prefix)
+	case 1:
+		return res[0], nil
+	default:
+		return clientdb.PostDraft{}, fmt.Errorf("draft id %q is ambiguous", prefix)
+	}
+}
+
+// findPostSummary returns the summary of the given local post.
+func (as *appState) findPostSummary(from clientintf.UserID, pid clientintf.PostID) (clientdb.PostSummary, error) {
+	as.loadPosts()
+	as.postsMtx.Lock()
+	defer as.postsMtx.Unlock()
+	for _, summ := range as.posts {
+		if summ.From == from && summ.ID == pid {
+			return summ, nil
+		}
+	}
+	return clientdb.PostSummary{}, fmt.Errorf("post %s not found", pid)
+}
+
+func (as *appState) saveDraft(draft clientdb.PostDraft) {
+	draft, err := as.c.SaveDraft(draft)
+	if err != nil {
+		as.cwHelpMsg("Unable to save draft: %v", err)
+		return
+	}
+	as.cwHelpMsg("Saved draft %s (estimated size %s)", draft.ID.ShortLogID(),
+		hbytes(int64(draft.EstSize)))
+}
+
+// publishDraft saves the latest version of the draft and publishes it.
+func (as *appState) publishDraft(draft clientdb.PostDraft) {
+	draft, err := as.c.SaveDraft(draft)
+	if err != nil {
+		as.cwHelpMsg("Unable to save draft: %v", err)
+		return
+	}
+	summ, err := as.c.PublishDraft(draft.ID)
+	switch {
+	case err != nil:
+		as.cwHelpMsg("Unable to publish draft: %v", err)
+	case draft.IsComment():
+		as.cwHelpMsg("Published comment draft %s", draft.ID.ShortLogID())
+	default:
+		as.cwHelpMsg("Published draft %s as post %s", draft.ID.ShortLogID(),
+			summ.ID)
+		as.postsMtx.Lock()
+		as.posts = append(as.posts, summ)
+		as.postsMtx.Unlock()
+		as.sendMsg(summ)
+	}
+}
+
+// draftSummary returns the first line of a draft, truncated to a size
+// suitable for listing.
+func draftSummary(content string) string {
+	const maxLen = 40
+	content = strings.TrimSpace(content)
+	if i := strings.IndexByte(content, '\n'); i > -1 {
+		content = content[:i]
+	}
+	if len(content) > maxLen {
+		content = content[:maxLen] + "…"
+	}
+	return strescape.Content(content)
+}
+
 func (as *appState) loadPosts() {
 	posts, err := as.c.ListPosts()
 	if err != nil {
@@ -1814,6 +2111,54 @@ func (as *appState) allPosts() []clientdb.PostSummary {
 	return res
 }
 
+// searchPosts returns the posts that match the given feed filter. The filter
+// is a list of search terms, optionally including "author:<nick>",
+// "since:<yyyy-mm-dd>", "until:<yyyy-mm-dd>" and "has:attachment" filters.
+func (as *appState) searchPosts(filter string) ([]clientdb.PostSummary, error) {
+	var filters clientdb.PostSearchFilters
+	var terms []string
+	for _, arg := range strings.Fields(filter) {
+		key, value, _ := strings.Cut(arg, ":")
+		switch {
+		case key == "author" && value == "me":
+			me := as.c.PublicID()
+			filters.Author = &me
+
+		case key == "author" && value != "":
+			var uid clientintf.UserID
+			if err := uid.FromString(value); err != nil {
+				uid, err = as.c.UIDByNick(value)
+				if err != nil {
+					return nil, err
+				}
+			}
+			filters.Author = &uid
+
+		case key == "since" && value != "":
+			t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+			if err != nil {
+				return nil, err
+			}
+			filters.Since = t
+
+		case key == "until" && value != "":
+			t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+			if err != nil {
+				return nil, err
+			}
+			filters.Until = t.Add(24*time.Hour - time.Nanosecond)
+
+		case key == "has" && value == "attachment":
+			filters.HasAttachment = true
+
+		default:
+			terms = append(terms, arg)
+		}
+	}
+
+	return as.c.SearchPosts(strings.Join(terms, " "), filters)
+}
+
 func (as *appState) activePost() (rpc.PostMetadata, clientdb.PostSummary,
 	[]rpc.PostMetadataStatus, []string) {
 
@@ -2051,6 +2396,15 @@ func (as *appState) msgInActiveWindow(msg string) {
 	go as.pm(cw, msg)
 }
 
+// estimateMsgCost estimates the cost (in milliatoms) of sending the given msg
+// in the specified chat window.
+func (as *appState) estimateMsgCost(cw *chatWindow, msg string) (uint64, error) {
+	if cw.isGC {
+		return as.c.EstimateGCMessageCost(cw.gc, msg)
+	}
+	return as.c.EstimatePMCost(cw.uid, msg)
+}
+
 func (as *appState) channelBalance() (dcrutil.Amount, dcrutil.Amount, dcrutil.Amount) {
 	as.balMtx.RLock()
 	total := as.bal.total
@@ -2107,6 +2461,21 @@ func (as *appState) viewEmbed(embedded embeddedArgs) (tea.Cmd, error) {
 	return cmd, nil
 }
 
+// viewDownloadedFile opens the given downloaded file with the external viewer
+// configured for its MIME type.
+func (as *appState) viewDownloadedFile(path string) error {
+	typ, err := clientdb.DetectFileMIMEType(path)
+	if err != nil {
+		return err
+	}
+	prog := programByMimeType(as.mimeMap, typ)
+	if prog == "" {
+		return fmt.Errorf("no external viewer configured for %q", typ)
+	}
+	as.sendMsg(msgViewFile{prog: prog, path: path})
+	return nil
+}
+
 func (as *appState) downloadEmbed(source clientintf.UserID, embedded embeddedArgs) error {
 
 	if source == as.c.PublicID() {
@@ -2296,6 +2665,8 @@ func newAppState(sendMsg func(tea.Msg), lndLogLines *sloglinesbuffer.Buffer,
 		DownloadsRoot: args.DownloadsRoot,
 		Logger:        logBknd.logger("FDDB"),
 		ChunkSize:     rpc.MaxChunkSize,
+
+		MerkleManifestChunks: clientdb.DefaultMerkleManifestChunks,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("unable to initialize DB: %v", err)
@@ -2407,6 +2778,178 @@ func newAppState(sendMsg func(tea.Msg), lndLogLines *sloglinesbuffer.Buffer,
 		as.sendMsg(feedUpdated{})
 	}))
 
+	ntfns.Register(client.OnPostPaywallUnlockedNtfn(func(user *client.RemoteUser,
+		pid clientintf.PostID, body string) {
+		as.diagMsg("Unlocked paywalled post %s from %s", pid, strescape.Nick(user.Nick()))
+		as.sendMsg(postPaywallUnlocked{pid: pid})
+	}))
+
+	ntfns.Register(client.OnMsgsHeldForPostageNtfn(func(user *client.RemoteUser,
+		nbHeld int, postage uint64) {
+		// Only alert about the first held message, to avoid spamming
+		// the UI.
+		if nbHeld > 1 {
+			return
+		}
+		as.diagMsg("Holding messages from %s until postage of %.8f DCR "+
+			"per message is paid. Use '/postage release' or "+
+			"'/postage drop' to deliver or discard them.",
+			strescape.Nick(user.Nick()), float64(postage)/1e11)
+	}))
+
+	ntfns.Register(client.OnPostageRequiredNtfn(func(user *client.RemoteUser,
+		postage uint64, nbHeld uint32) {
+		cw := as.findOrNewChatWindow(user.ID(), user.Nick())
+		cw.newHelpMsg("User requires postage of %.8f DCR per message "+
+			"(%d messages held). Use '/postage pay %s [<nb of msgs>]' "+
+			"to pay it.", float64(postage)/1e11, nbHeld,
+			strescape.Nick(user.Nick()))
+		as.repaintIfActive(cw)
+	}))
+
+	ntfns.Register(client.OnPaymentRequestReceivedNtfn(func(user *client.RemoteUser,
+		req clientdb.PaymentRequest) {
+		cw := as.findOrNewChatWindow(user.ID(), strescape.Nick(user.Nick()))
+		msg := fmt.Sprintf("Requested payment of %.8f DCR",
+			float64(req.MilliAtoms)/1e11)
+		if req.Memo != "" {
+			msg += ": " + strescape.Content(req.Memo)
+		}
+		cw.newInternalMsg(msg)
+		cw.newHelpMsg("Use '/invoice pay %s' or '/invoice decline %s "+
+			"[<reason>]' to pay or decline the request",
+			req.ID.ShortLogID(), req.ID.ShortLogID())
+		as.repaintIfActive(cw)
+	}))
+
+	ntfns.Register(client.OnPaymentRequestUpdatedNtfn(func(user *client.RemoteUser,
+		req clientdb.PaymentRequest) {
+		cw := as.findOrNewChatWindow(user.ID(), strescape.Nick(user.Nick()))
+		switch {
+		case req.Status == clientdb.PaymentRequestPaid:
+			cw.newHelpMsg("Payment request %s for %.8f DCR was paid",
+				req.ID.ShortLogID(), float64(req.MilliAtoms)/1e11)
+		case req.DeclineReason != "":
+			cw.newHelpMsg("Payment request %s was declined: %s",
+				req.ID.ShortLogID(), strescape.Content(req.DeclineReason))
+		default:
+			cw.newHelpMsg("Payment request %s was declined",
+				req.ID.ShortLogID())
+		}
+		as.repaintIfActive(cw)
+	}))
+
+	ntfns.Register(client.OnTipReceivedNtfn(func(user *client.RemoteUser,
+		tip clientdb.TipHistoryEntry) {
+		cw := as.findOrNewChatWindow(user.ID(), strescape.Nick(user.Nick()))
+		msg := fmt.Sprintf("Received tip of %.8f DCR", float64(tip.MilliAtoms)/1e11)
+		if tip.Post != nil {
+			msg += fmt.Sprintf(" for post %s", tip.Post.ShortLogID())
+		}
+		if tip.Memo != "" {
+			msg += ": " + strescape.Content(tip.Memo)
+		}
+		cw.newInternalMsg(msg)
+		as.repaintIfActive(cw)
+	}))
+
+	ntfns.Register(client.OnPostsSubscriptionPaymentRequiredNtfn(func(user *client.RemoteUser,
+		price rpc.PostsSubscriptionPrice, autoRenew bool) {
+		cw := as.findOrNewChatWindow(user.ID(), user.Nick())
+		if autoRenew {
+			cw.newHelpMsg("Automatically paying %.8f DCR for "+
+				"subscription to posts for %s",
+				float64(price.MilliAtoms)/1e11, price.Period())
+		} else {
+			cw.newHelpMsg("User requires %.8f DCR for subscription "+
+				"to posts for %s. Use '/post paysub %s' to pay it.",
+				float64(price.MilliAtoms)/1e11, price.Period(),
+				strescape.Nick(user.Nick()))
+		}
+		as.repaintIfActive(cw)
+	}))
+
+	ntfns.Register(client.OnTransferCanceledNtfn(func(user *client.RemoteUser,
+		fid clientdb.FileID, isUpload bool) {
+		if isUpload {
+			as.diagMsg("%s canceled the download of file %s",
+				strescape.Nick(user.Nick()), fid)
+		} else {
+			as.diagMsg("%s canceled the upload of file %s",
+				strescape.Nick(user.Nick()), fid)
+		}
+	}))
+
+	ntfns.Register(client.OnFileRangeFetchedNtfn(func(user *client.RemoteUser,
+		fid clientdb.FileID, r clientdb.FileRange) {
+		as.contentMtx.Lock()
+		_, isPreview := as.previews[fid]
+		delete(as.previews, fid)
+		as.contentMtx.Unlock()
+		if !isPreview {
+			return
+		}
+
+		cw := as.findOrNewChatWindow(user.ID(), strescape.Nick(user.Nick()))
+		if err := as.showPreview(cw, fid, r); err != nil {
+			cw.newHelpMsg("Unable to preview file %s: %v", fid, err)
+		}
+		as.repaintIfActive(cw)
+	}))
+
+	ntfns.Register(client.OnDownloadSourceAddedNtfn(func(user *client.RemoteUser,
+		fid clientdb.FileID) {
+		as.diagMsg("Added %s as a source of the download of file %s",
+			strescape.Nick(user.Nick()), fid)
+	}))
+
+	ntfns.Register(client.OnCollectionsListedNtfn(func(user *client.RemoteUser,
+		collections []rpc.CollectionSummary) {
+		cw := as.findOrNewChatWindow(user.ID(), strescape.Nick(user.Nick()))
+		cw.manyHelpMsgs(func(pf printf) {
+			pf("")
+			pf("Received collection list")
+			eRate := as.exchangeRate()
+			for _, col := range collections {
+				dcrCost := float64(col.Cost) / 1e8
+				usdCost := eRate.DCRPrice * dcrCost
+				pf("ID         : %s", col.ID)
+				pf("Name       : %q", col.Name)
+				pf("Description: %q", col.Description)
+				pf("Files      : %d", col.NbFiles)
+				pf("Size       : %d", col.Size)
+				pf("Cost       : %.8f DCR / %0.8f USD", dcrCost, usdCost)
+				pf("")
+			}
+		})
+		as.repaintIfActive(cw)
+	}))
+
+	ntfns.Register(client.OnCollectionReceivedNtfn(func(user *client.RemoteUser,
+		fc rpc.FileCollection) {
+		cw := as.findOrNewChatWindow(user.ID(), strescape.Nick(user.Nick()))
+		cw.manyHelpMsgs(func(pf printf) {
+			pf("")
+			pf("Collection %q (%x)", fc.Name, fc.ID())
+			if fc.Description != "" {
+				pf("%s", fc.Description)
+			}
+			for _, f := range fc.Files {
+				pf("%s  %s  %d B  %.8f DCR", f.FileID, f.Path, f.Size,
+					float64(f.Cost)/1e8)
+			}
+			pf("Total: %d files, %d B, %.8f DCR", len(fc.Files), fc.Size,
+				float64(fc.Cost)/1e8)
+		})
+		as.repaintIfActive(cw)
+	}))
+
+	ntfns.Register(client.OnCollectionDownloadStartedNtfn(func(user *client.RemoteUser,
+		fc rpc.FileCollection, nbQueued int) {
+		as.diagMsg("Started download of collection %q from %s (%d of %d files queued)",
+			fc.Name, strescape.Nick(user.Nick()), nbQueued, len(fc.Files))
+	}))
+
 	ntfns.Register(client.OnPostStatusRcvdNtfn(func(user *client.RemoteUser, pid clientintf.PostID,
 		statusFrom clientintf.UserID, status rpc.PostMetadataStatus) {
 		as.postsMtx.Lock()
@@ -2657,6 +3200,8 @@ func newAppState(sendMsg func(tea.Msg), lndLogLines *sloglinesbuffer.Buffer,
 		CompressLevel:  args.CompressLevel,
 		Notifications:  ntfns,
 
+		SendPostsToNewSubscribers: args.SendPostsToNewSubs,
+
 		CertConfirmer: func(ctx context.Context, cs *tls.ConnectionState,
 			svrID *zkidentity.PublicIdentity) error {
 			msg := msgConfirmServerCert{
@@ -2675,6 +3220,10 @@ func newAppState(sendMsg func(tea.Msg), lndLogLines *sloglinesbuffer.Buffer,
 			return err
 		},
 
+		PaymentApprover: func(req client.PaymentApprovalRequest) bool {
+			return as.approvePayment(req)
+		},
+
 		LocalIDIniter: func(ctx context.Context) (*zkidentity.FullIdentity, error) {
 			// Client needs ID info from user. Request and wait for
 			// user response from the UI.
@@ -2801,7 +3350,7 @@ func newAppState(sendMsg func(tea.Msg), lndLogLines *sloglinesbuffer.Buffer,
 			if connected {
 				if showRates {
 					as.diagMsg("Push Rate: %.8f DCR/kB, Sub Rate: %.8f DCR/sub",
-						float64(pushRate)/1e8, float64(subRate)/1e11)
+						float64(pushRate)/1e11, float64(subRate)/1e11)
 				}
 				if showExpDays {
 					as.diagMsg("Days to Expire Data: %d", expDays)
@@ -2862,13 +3411,6 @@ func newAppState(sendMsg func(tea.Msg), lndLogLines *sloglinesbuffer.Buffer,
 			})
 		},
 
-		TipReceived: func(user *client.RemoteUser, dcrAmount float64) {
-			cw := as.findOrNewChatWindow(user.ID(), strescape.Nick(user.Nick()))
-			msg := fmt.Sprintf("Received tip of %.8f DCR", dcrAmount)
-			cw.newInternalMsg(msg)
-			as.repaintIfActive(cw)
-		},
-
 		PostsListReceived: func(user *client.RemoteUser, postList rpc.RMListPostsReply) {
 			cw := as.findOrNewChatWindow(user.ID(), strescape.Nick(user.Nick()))
 			cw.manyHelpMsgs(func(pf printf) {
@@ -2948,13 +3490,20 @@ func newAppState(sendMsg func(tea.Msg), lndLogLines *sloglinesbuffer.Buffer,
 			cw := as.findOrNewChatWindow(user.ID(), strescape.Nick(user.Nick()))
 			cw.newInternalMsg(fmt.Sprintf("Download completed: %s",
 				diskPath))
+			if typ := fm.MIMEType(); typ != "" {
+				if prog := programByMimeType(as.mimeMap, typ); prog != "" {
+					cw.newInternalMsg(fmt.Sprintf("File type is %s. "+
+						"Use /ft open %s to view it with %s",
+						typ, clientdb.FileID(fm.MetadataHash()), prog))
+				}
+			}
 
 			fid := clientdb.FileID(fm.MetadataHash())
 			as.contentMtx.Lock()
 			msg := as.progressMsg[fid]
 			if msg != nil {
 				delete(as.progressMsg, fid)
-				totChunks := len(fm.Manifest)
+				totChunks := fm.ChunkCount()
 				msg.msg = fmt.Sprintf("Downloaded %d/%d chunks (100.00%%)- %q",
 					totChunks, totChunks, fm.Filename)
 			}
@@ -2972,7 +3521,7 @@ func newAppState(sendMsg func(tea.Msg), lndLogLines *sloglinesbuffer.Buffer,
 			nbMissingChunks int) {
 
 			cw := as.findOrNewChatWindow(user.ID(), strescape.Nick(user.Nick()))
-			totChunks := len(fm.Manifest)
+			totChunks := fm.ChunkCount()
 			gotChunks := totChunks - nbMissingChunks
 
 			fid := clientdb.FileID(fm.MetadataHash())
@@ -2990,6 +3539,31 @@ func newAppState(sendMsg func(tea.Msg), lndLogLines *sloglinesbuffer.Buffer,
 			as.repaintIfActive(cw)
 		},
 
+		FileUploadProgress: func(user *client.RemoteUser, fm rpc.FileMetadata,
+			stats clientdb.FileUploadStats) {
+
+			cw := as.findOrNewChatWindow(user.ID(), strescape.Nick(user.Nick()))
+			totChunks := fm.ChunkCount()
+			sentChunks := len(stats.SentChunks)
+
+			key := [2]clientdb.FileID{user.ID(), fm.MetadataHash()}
+			as.contentMtx.Lock()
+			msg := as.uploadProgressMsg[key]
+			if msg == nil {
+				msg = cw.newInternalMsg("")
+				as.uploadProgressMsg[key] = msg
+			}
+			msg.msg = fmt.Sprintf("Uploaded %d/%d chunks (%.2f%%) - %q",
+				sentChunks, totChunks,
+				float64(sentChunks)*100/float64(totChunks), fm.Filename)
+			if sentChunks >= totChunks {
+				delete(as.uploadProgressMsg, key)
+			}
+			as.contentMtx.Unlock()
+
+			as.repaintIfActive(cw)
+		},
+
 		TransitiveEvent: func(src, dst client.UserID, event client.TransitiveEvent) {
 			srcRU, err := as.c.UserByID(src)
 			if err != nil {
@@ -3165,6 +3739,15 @@ func newAppState(sendMsg func(tea.Msg), lndLogLines *sloglinesbuffer.Buffer,
 		if err != nil {
 			return nil, err
 		}
+
+		contentRPCServerCfg := rpcserver.ContentServerCfg{
+			Log:    logBknd.logger("RPCS"),
+			Client: c,
+		}
+		err = rpcServer.InitContentService(contentRPCServerCfg)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -3205,8 +3788,11 @@ func newAppState(sendMsg func(tea.Msg), lndLogLines *sloglinesbuffer.Buffer,
 		cmdHistory:     cmdHistory,
 		cmdHistoryIdx:  len(cmdHistory),
 
-		remoteFiles: make(map[clientintf.UserID]map[clientdb.FileID]clientdb.RemoteFile),
-		progressMsg: make(map[clientdb.FileID]*chatMsg),
+		remoteFiles:       make(map[clientintf.UserID]map[clientdb.FileID]clientdb.RemoteFile),
+		progressMsg:       make(map[clientdb.FileID]*chatMsg),
+		uploadProgressMsg: make(map[[2]clientdb.FileID]*chatMsg),
+		previews:          make(map[clientdb.FileID]struct{}),
+		payApprovals:      make(map[uint64]chan bool),
 
 		activeCW:  activeCWDiag,
 		updatedCW: make(map[int]bool),
@@ -3232,3 +3818,56 @@ func newAppState(sendMsg func(tea.Msg), lndLogLines *sloglinesbuffer.Buffer,
 
 	return as, nil
 }
+
+// approvePayment asks the user to approve an outbound payment above the
+// approval threshold. The payment is rejected if the user does not reply
+// within payApprovalTimeout.
+func (as *appState) approvePayment(req client.PaymentApprovalRequest) bool {
+	replyChan := make(chan bool, 1)
+	as.payApprovalsMtx.Lock()
+	as.payApprovalID++
+	id := as.payApprovalID
+	as.payApprovals[id] = replyChan
+	as.payApprovalsMtx.Unlock()
+
+	defer func() {
+		as.payApprovalsMtx.Lock()
+		delete(as.payApprovals, id)
+		as.payApprovalsMtx.Unlock()
+	}()
+
+	var target string
+	if req.UID != nil {
+		nick, _ := as.c.UserNick(*req.UID)
+		target = fmt.Sprintf(" related to %s", strescape.Nick(nick))
+	}
+	as.diagMsg("Payment of %.8f DCR%s for %s needs approval. Type "+
+		"'/paylimits approve %d' or '/paylimits reject %d'",
+		float64(req.MAtoms)/1e11, target, req.Descr, id, id)
+
+	select {
+	case ok := <-replyChan:
+		return ok
+	case <-time.After(payApprovalTimeout):
+		as.diagMsg("Payment approval %d timed out", id)
+		return false
+	case <-as.ctx.Done():
+		return false
+	}
+}
+
+// replyPaymentApproval replies to the payment approval with the given id.
+func (as *appState) replyPaymentApproval(id uint64, approve bool) error {
+	as.payApprovalsMtx.Lock()
+	replyChan, ok := as.payApprovals[id]
+	as.payApprovalsMtx.Unlock()
+	if !ok {
+		return fmt.Errorf("payment approval %d not found", id)
+	}
+	select {
+	case replyChan <- approve:
+		return nil
+	default:
+		return fmt.Errorf("payment approval %d already replied", id)
+	}
+}
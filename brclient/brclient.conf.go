@@ -23,6 +23,10 @@ root = {{ .Root }}
 # proxypass =
 # torisolation = 0
 
+# Whether to send a post to subscribers that do not have it (because they
+# subscribed after it was created) when commenting on it.
+# sendpoststonewsubs = false
+
 # external viewer for mimetypes
 # mimetype=image/*,ristretto
 # mimetype=video/*,mplayer
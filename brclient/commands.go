@@ -16,6 +16,7 @@ import (
 	"github.com/companyzero/bisonrelay/client/clientdb"
 	"github.com/companyzero/bisonrelay/client/clientintf"
 	"github.com/companyzero/bisonrelay/internal/strescape"
+	"github.com/companyzero/bisonrelay/rpc"
 	"github.com/companyzero/bisonrelay/zkidentity"
 	"github.com/decred/dcrd/dcrutil/v4"
 	"github.com/decred/dcrlnd/lnrpc"
@@ -164,6 +165,22 @@ func gcCompleter(arg string, as *appState) []string {
 	return res
 }
 
+// draftCompleter returns completions for post drafts with an id that has the
+// given prefix.
+func draftCompleter(arg string, as *appState) []string {
+	drafts, err := as.c.ListDrafts()
+	if err != nil {
+		return nil
+	}
+	var res []string
+	for _, d := range drafts {
+		if id := d.ID.String(); strings.HasPrefix(id, arg) {
+			res = append(res, id)
+		}
+	}
+	return res
+}
+
 // subcmdNeededHandler is used on top-level commands that only work with a
 // subcommand.
 func subcmdNeededHandler(args []string, as *appState) error {
@@ -224,6 +241,68 @@ var listCommands = []tuicmd{
 			})
 			return nil
 		},
+	}, {
+		cmd:           "tips",
+		usableOffline: true,
+		usage:         "[<nick>]",
+		descr:         "List the totals of tips exchanged with users or the tip history of a user",
+		completer: func(args []string, arg string, as *appState) []string {
+			if len(args) == 0 {
+				return nickCompleter(arg, as)
+			}
+			return nil
+		},
+		handler: func(args []string, as *appState) error {
+			if len(args) > 0 {
+				uid, err := as.c.UIDByNick(args[0])
+				if err != nil {
+					return err
+				}
+				tips, err := as.c.ListTipHistory(uid)
+				if err != nil {
+					return err
+				}
+				as.cwHelpMsgs(func(pf printf) {
+					pf("")
+					pf("Tips exchanged with %s", strescape.Nick(args[0]))
+					for _, tip := range tips {
+						dir, amount := "received", tip.MilliAtoms
+						if amount < 0 {
+							dir, amount = "sent", -amount
+						}
+						line := fmt.Sprintf("%s %-8s %.8f DCR",
+							tip.Timestamp.Format(ISO8601DateTime), dir,
+							float64(amount)/1e11)
+						if tip.Post != nil {
+							line += fmt.Sprintf(" (post %s)", tip.Post.ShortLogID())
+						}
+						if tip.Memo != "" {
+							line += " - " + strescape.Content(tip.Memo)
+						}
+						pf("%s", line)
+					}
+				})
+				return nil
+			}
+
+			totals, err := as.c.ListTipTotals()
+			if err != nil {
+				return err
+			}
+			as.cwHelpMsgs(func(pf printf) {
+				pf("")
+				pf("Tip totals")
+				pf("%-16s %18s %18s", "User", "Sent", "Received")
+				for uid, t := range totals {
+					nick, _ := as.c.UserNick(uid)
+					pf("%-16s %13.8f (%2d) %13.8f (%2d)",
+						strescape.Nick(nick),
+						float64(t.Sent)/1e11, t.NbSent,
+						float64(t.Received)/1e11, t.NbReceived)
+				}
+			})
+			return nil
+		},
 	}, {
 		cmd:           "subscriptions",
 		usableOffline: true,
@@ -364,6 +443,13 @@ var listCommands = []tuicmd{
 						nick, _ := as.c.UserNick(id)
 						pf("  %s - %q", id, nick)
 					}
+					if len(f.GCs) > 0 {
+						pf("Shared with GCs")
+					}
+					for _, id := range f.GCs {
+						gcName, _ := as.c.GetGCAlias(id)
+						pf("  %s - %q", id, gcName)
+					}
 				}
 			})
 
@@ -386,24 +472,118 @@ var listCommands = []tuicmd{
 					if nick == "" {
 						nick = fd.UID.String()
 					}
-					pf("%s - %s", fd.FID, strescape.Nick(nick))
+					paused := ""
+					if fd.Paused {
+						paused = " (paused)"
+					}
+					pf("%s - %s%s", fd.FID, strescape.Nick(nick), paused)
 					if fd.Metadata == nil {
 						pf("(no data)")
 						pf("")
 						continue
 					}
 					downChunks := fd.CountChunks(clientdb.ChunkStateDownloaded)
-					totalChunks := len(fd.Metadata.Manifest)
+					totalChunks := fd.Metadata.ChunkCount()
 					progress := float64(downChunks) / float64(totalChunks) * 100
 					pf("Filename: %q", fd.Metadata.Filename)
 					pf("Cost: %s", dcrutil.Amount(fd.Metadata.Cost))
 					pf("Progress: %.2f (%d/%d)", progress,
 						downChunks, totalChunks)
+					if len(fd.Sources) > 0 {
+						srcs := make([]string, 0, len(fd.Sources))
+						for _, src := range fd.Sources {
+							srcNick, _ := as.c.UserNick(src.UID)
+							if srcNick == "" {
+								srcNick = src.UID.String()
+							}
+							srcs = append(srcs, strescape.Nick(srcNick))
+						}
+						pf("Other sources: %s", strings.Join(srcs, ", "))
+					}
 					pf("")
 				}
 			})
 			return nil
 		},
+	}, {
+		cmd:           "transfers",
+		usableOffline: true,
+		aliases:       []string{"tr"},
+		descr:         "Show the in-progress uploads and downloads",
+		long: []string{
+			"Opens a window listing all uploads and downloads, with their progress, per-chunk state, transfer rate, amount paid or received and estimated time to completion.",
+		},
+		handler: func(args []string, as *appState) error {
+			as.sendMsg(showTransfersWindow{})
+			return nil
+		},
+	}, {
+		cmd:           "rules",
+		usableOffline: true,
+		descr:         "List the rules to automatically accept or reject file downloads",
+		long: []string{
+			"Rules are evaluated in order and the first rule that matches a file offered by a remote user decides whether the file is downloaded. Offers that do not match any rule are downloaded as usual.",
+		},
+		handler: func(args []string, as *appState) error {
+			rules, err := as.c.ListDownloadRules()
+			if err != nil {
+				return err
+			}
+			as.cwHelpMsgs(func(pf printf) {
+				if len(rules) == 0 {
+					pf("No download rules")
+					return
+				}
+				pf("")
+				pf("Download rules")
+				for i := range rules {
+					pf("%3d %s", rules[i].ID, downloadRuleDescr(&rules[i], as))
+				}
+				pf("")
+			})
+			return nil
+		},
+	}, {
+		cmd:           "addrule",
+		usableOffline: true,
+		usage:         "<accept|reject> [user=<nick>] [gc=<gc>] [minsize=<bytes>] [maxsize=<bytes>] [mincost=<dcr>] [maxcost=<dcr>] [mime=<type>,...]",
+		descr:         "Add a rule to automatically accept or reject file downloads",
+		long: []string{
+			"The rule matches files offered by remote users (either sent directly or fetched with 'get') that match all of the specified conditions. A rule without conditions matches every file.",
+			"MIME types are detected from the file extension and may end in '/*' to match any subtype (e.g. 'mime=image/*,application/pdf').",
+		},
+		handler: func(args []string, as *appState) error {
+			rule, err := parseDownloadRule(args, as)
+			if err != nil {
+				return err
+			}
+			rule, err = as.c.AddDownloadRule(rule)
+			if err != nil {
+				return err
+			}
+			as.cwHelpMsg("Added download rule %d: %s", rule.ID,
+				downloadRuleDescr(&rule, as))
+			return nil
+		},
+	}, {
+		cmd:           "delrule",
+		usableOffline: true,
+		usage:         "<rule id>",
+		descr:         "Remove a download rule",
+		handler: func(args []string, as *appState) error {
+			if len(args) < 1 {
+				return usageError{msg: "rule id cannot be empty"}
+			}
+			id, err := strconv.ParseUint(args[0], 10, 64)
+			if err != nil {
+				return usageError{msg: fmt.Sprintf("invalid rule id: %v", err)}
+			}
+			if err := as.c.RemoveDownloadRule(id); err != nil {
+				return err
+			}
+			as.cwHelpMsg("Removed download rule %d", id)
+			return nil
+		},
 	}, {
 		cmd:           "paystats",
 		usableOffline: true,
@@ -437,6 +617,11 @@ var listCommands = []tuicmd{
 					pf("%12.8f   %12.8f - Totals",
 						float64(totalSent)/1e11,
 						float64(totalRecv)/1e11)
+					if eRate := as.exchangeRate(); eRate.DCRPrice > 0 {
+						pf("%12.2f   %12.2f - Totals (USD)",
+							float64(totalSent)/1e11*eRate.DCRPrice,
+							float64(totalRecv)/1e11*eRate.DCRPrice)
+					}
 				})
 				return nil
 			}
@@ -472,7 +657,7 @@ var listCommands = []tuicmd{
 			as.cwHelpMsgs(func(pf printf) {
 				pf("")
 				pf("Server Fee Rates")
-				pf("Push Rate: %.8f DCR/kB", float64(pushRate)/1e8)
+				pf("Push Rate: %.8f DCR/kB", float64(pushRate)/1e11)
 				pf("Subscribe Rate: %.8f DCR/RV", float64(subRate)/1e11)
 			})
 			return nil
@@ -527,87 +712,124 @@ var listCommands = []tuicmd{
 	},
 }
 
-var gcCommands = []tuicmd{
+// updatePayLimits updates the payment limits of the client with the given
+// function.
+func updatePayLimits(as *appState, f func(limits *clientdb.PaymentLimits)) error {
+	limits, err := as.c.PaymentLimits()
+	if err != nil {
+		return err
+	}
+	f(&limits)
+	return as.c.SetPaymentLimits(limits)
+}
+
+var payLimitsCommands = []tuicmd{
 	{
-		cmd:           "new",
+		cmd:           "daily",
 		usableOffline: true,
-		usage:         "<gc name>",
-		descr:         "Create a new group chat named <gc name>",
+		usage:         "<dcr|none>",
+		descr:         "Set the max amount spent per day on all payments",
 		handler: func(args []string, as *appState) error {
 			if len(args) < 1 {
-				return usageError{msg: "gc name cannot be empty"}
+				return usageError{msg: "limit cannot be empty"}
 			}
-			if _, err := as.c.NewGroupChat(args[0]); err != nil {
+			limit, err := parsePayLimit(args[0])
+			if err != nil {
 				return err
 			}
-			as.cwHelpMsg("GC %q created", args[0])
+			err = updatePayLimits(as, func(limits *clientdb.PaymentLimits) {
+				limits.DailyLimit = limit
+			})
+			if err != nil {
+				return err
+			}
+			as.cwHelpMsg("Daily payment limit set to %s", formatPayLimit(limit))
 			return nil
 		},
 	}, {
-		cmd:   "invite",
-		usage: "<gc name> <nick>",
-		descr: "invite the user with the given nick to join the given gc",
+		cmd:           "threshold",
+		usableOffline: true,
+		usage:         "<dcr|none>",
+		descr:         "Set the amount above which payments need approval",
+		long: []string{
+			"Any single payment above the threshold needs to be approved with '/paylimits approve <id>' within 5 minutes, otherwise it is rejected.",
+		},
 		handler: func(args []string, as *appState) error {
 			if len(args) < 1 {
-				return usageError{"gc name cannot be empty"}
+				return usageError{msg: "threshold cannot be empty"}
+			}
+			threshold, err := parsePayLimit(args[0])
+			if err != nil {
+				return err
+			}
+			err = updatePayLimits(as, func(limits *clientdb.PaymentLimits) {
+				limits.ApprovalThreshold = threshold
+			})
+			if err != nil {
+				return err
 			}
+			as.cwHelpMsg("Payment approval threshold set to %s",
+				formatPayLimit(threshold))
+			return nil
+		},
+	}, {
+		cmd:           "user",
+		usableOffline: true,
+		usage:         "<nick> <dcr|none>",
+		descr:         "Set the max amount spent per day on payments related to a user",
+		handler: func(args []string, as *appState) error {
 			if len(args) < 2 {
-				return usageError{"invitee name cannot be empty"}
+				return usageError{msg: "nick and limit cannot be empty"}
 			}
-
-			gcname, nick := args[0], args[1]
-			gcID, err := as.c.GCIDByName(gcname)
+			uid, err := as.c.UIDByNick(args[0])
 			if err != nil {
 				return err
 			}
-			if _, err := as.c.GetGC(gcID); err != nil {
+			limit, err := parsePayLimit(args[1])
+			if err != nil {
 				return err
 			}
-			cw := as.findOrNewGCWindow(gcID)
-			uid, err := as.c.UIDByNick(nick)
+			err = updatePayLimits(as, func(limits *clientdb.PaymentLimits) {
+				limits.SetUserLimit(uid, limit)
+			})
 			if err != nil {
 				return err
 			}
-
-			go as.inviteToGC(cw, args[1], uid)
+			as.cwHelpMsg("Daily payment limit for %s set to %s",
+				strescape.Nick(args[0]), formatPayLimit(limit))
 			return nil
 		},
 		completer: func(args []string, arg string, as *appState) []string {
 			if len(args) == 0 {
-				return gcCompleter(arg, as)
-			}
-			if len(args) == 1 {
 				return nickCompleter(arg, as)
 			}
 			return nil
 		},
-	},
-	{
-		cmd:     "msg",
-		aliases: []string{"m"},
-		usage:   "<gc name> <message>",
-		descr:   "send a message to the given GC",
-		rawHandler: func(rawCmd string, args []string, as *appState) error {
-			if len(args) < 1 {
-				return usageError{"gc name cannot be empty"}
-			}
+	}, {
+		cmd:           "gc",
+		usableOffline: true,
+		usage:         "<gc name> <dcr|none>",
+		descr:         "Set the max amount spent per day on payments related to a GC",
+		handler: func(args []string, as *appState) error {
 			if len(args) < 2 {
-				return usageError{"message cannot be empty"}
+				return usageError{msg: "gc name and limit cannot be empty"}
 			}
-
-			gcname := args[0]
-			gcID, err := as.c.GCIDByName(gcname)
+			gcID, err := as.c.GCIDByName(args[0])
 			if err != nil {
 				return err
 			}
-
-			_, msg := popNArgs(rawCmd, 3) // cmd + subcmd + gcname
-
-			if _, err := as.c.GetGC(gcID); err != nil {
+			limit, err := parsePayLimit(args[1])
+			if err != nil {
 				return err
 			}
-			cw := as.findOrNewGCWindow(gcID)
-			go as.pm(cw, msg)
+			err = updatePayLimits(as, func(limits *clientdb.PaymentLimits) {
+				limits.SetGCLimit(gcID, limit)
+			})
+			if err != nil {
+				return err
+			}
+			as.cwHelpMsg("Daily payment limit for GC %s set to %s",
+				args[0], formatPayLimit(limit))
 			return nil
 		},
 		completer: func(args []string, arg string, as *appState) []string {
@@ -616,100 +838,1028 @@ var gcCommands = []tuicmd{
 			}
 			return nil
 		},
+	}, {
+		cmd:           "approve",
+		usableOffline: true,
+		usage:         "<approval id>",
+		descr:         "Approve a payment above the approval threshold",
+		handler: func(args []string, as *appState) error {
+			if len(args) < 1 {
+				return usageError{msg: "approval id cannot be empty"}
+			}
+			id, err := strconv.ParseUint(args[0], 10, 64)
+			if err != nil {
+				return usageError{msg: fmt.Sprintf("invalid approval id: %v", err)}
+			}
+			if err := as.replyPaymentApproval(id, true); err != nil {
+				return err
+			}
+			as.cwHelpMsg("Approved payment %d", id)
+			return nil
+		},
+	}, {
+		cmd:           "reject",
+		usableOffline: true,
+		usage:         "<approval id>",
+		descr:         "Reject a payment above the approval threshold",
+		handler: func(args []string, as *appState) error {
+			if len(args) < 1 {
+				return usageError{msg: "approval id cannot be empty"}
+			}
+			id, err := strconv.ParseUint(args[0], 10, 64)
+			if err != nil {
+				return usageError{msg: fmt.Sprintf("invalid approval id: %v", err)}
+			}
+			if err := as.replyPaymentApproval(id, false); err != nil {
+				return err
+			}
+			as.cwHelpMsg("Rejected payment %d", id)
+			return nil
+		},
 	},
+}
+
+var postageCommands = []tuicmd{
 	{
-		cmd:   "join",
-		usage: "<gc name>",
-		descr: "Join the given GC we were invited to",
+		cmd:           "default",
+		usableOffline: true,
+		usage:         "<dcr|none>",
+		descr:         "Set the postage required for each message of users",
 		handler: func(args []string, as *appState) error {
 			if len(args) < 1 {
-				return usageError{"invitation id cannot be empty"}
+				return usageError{msg: "postage cannot be empty"}
 			}
-
-			gcName := args[0]
-			as.gcInvitesMtx.Lock()
-			iid, ok := as.gcInvites[gcName]
-			as.gcInvitesMtx.Unlock()
-			if !ok {
-				// Try to find it in the db.
-				invites, err := as.c.ListGCInvitesFor(nil)
-				if err != nil {
-					return err
-				}
-
-				for i := len(invites) - 1; i >= 0; i-- {
-					if invites[i].Invite.Name == gcName && !invites[i].Accepted {
-						iid = invites[i].ID
-						ok = true
-						break
-					}
-				}
-
-				if !ok {
-					return fmt.Errorf("unrecognized gc invite %q", gcName)
-				}
+			postage, err := parsePayLimit(args[0])
+			if err != nil {
+				return err
 			}
-
-			go func() {
-				err := as.c.AcceptGroupChatInvite(iid)
-				if err != nil {
-					as.diagMsg("Unable to join gc %q: %v",
-						gcName, err)
-				} else {
-					as.diagMsg("Accepting invitation to join gc %q", gcName)
-				}
-			}()
+			err = updatePostageSettings(as, func(settings *clientdb.PostageSettings) {
+				settings.Default = uint64(postage)
+			})
+			if err != nil {
+				return err
+			}
+			as.cwHelpMsg("Default postage set to %s", formatPayLimit(postage))
 			return nil
 		},
 	}, {
-		cmd:           "list",
+		cmd:           "user",
 		usableOffline: true,
-		usage:         "[<gc name>]",
-		aliases:       []string{"l"},
-		descr:         "List the GCs we're a member of or members of a GC",
-		handler: func(args []string, as *appState) error {
+		usage:         "<nick> <dcr|none|default>",
+		descr:         "Set the postage required for each message of a user",
+		long: []string{
+			"'none' exempts the user from paying postage, while 'default' makes the user pay the default postage.",
+		},
+		completer: func(args []string, arg string, as *appState) []string {
 			if len(args) == 0 {
-				gcs, err := as.c.ListGCs()
-				if err != nil {
-					return err
+				return nickCompleter(arg, as)
+			}
+			return nil
+		},
+		handler: func(args []string, as *appState) error {
+			if len(args) < 2 {
+				return usageError{msg: "nick and postage cannot be empty"}
+			}
+			uid, err := as.c.UIDByNick(args[0])
+			if err != nil {
+				return err
+			}
+			if args[1] == "default" {
+				err = updatePostageSettings(as, func(settings *clientdb.PostageSettings) {
+					settings.ClearUserPostage(uid)
+				})
+				if err != nil {
+					return err
 				}
-				var maxNameLen int
-				gcNames := make(map[clientintf.ID]string, len(gcs))
-				for _, gc := range gcs {
-					alias, err := as.c.GetGCAlias(gc.ID)
-					if err != nil {
-						alias = gc.ID.ShortLogID()
-					} else {
-						alias = strescape.Nick(alias)
+				as.cwHelpMsg("User %s pays the default postage",
+					strescape.Nick(args[0]))
+				return nil
+			}
+			postage, err := parsePayLimit(args[1])
+			if err != nil {
+				return err
+			}
+			err = updatePostageSettings(as, func(settings *clientdb.PostageSettings) {
+				settings.SetUserPostage(uid, uint64(postage))
+			})
+			if err != nil {
+				return err
+			}
+			as.cwHelpMsg("Postage of user %s set to %s",
+				strescape.Nick(args[0]), formatPayLimit(postage))
+			return nil
+		},
+	}, {
+		cmd:           "held",
+		usableOffline: true,
+		descr:         "List the number of messages held from each user",
+		handler: func(args []string, as *appState) error {
+			held, err := as.c.ListHeldMessagesCount()
+			if err != nil {
+				return err
+			}
+			as.cwHelpMsgs(func(pf printf) {
+				pf("")
+				pf("Messages held until postage is paid")
+				for uid, n := range held {
+					nick, _ := as.c.UserNick(uid)
+					pf("%5d - %s %s", n, strescape.Nick(nick),
+						as.styles.help.Render(uid.String()))
+				}
+			})
+			return nil
+		},
+	}, {
+		cmd:           "release",
+		usableOffline: true,
+		usage:         "<nick>",
+		descr:         "Deliver the messages held from a user without postage",
+		completer: func(args []string, arg string, as *appState) []string {
+			if len(args) == 0 {
+				return nickCompleter(arg, as)
+			}
+			return nil
+		},
+		handler: func(args []string, as *appState) error {
+			if len(args) < 1 {
+				return usageError{msg: "nick cannot be empty"}
+			}
+			uid, err := as.c.UIDByNick(args[0])
+			if err != nil {
+				return err
+			}
+			return as.c.ReleaseHeldMessages(uid)
+		},
+	}, {
+		cmd:           "drop",
+		usableOffline: true,
+		usage:         "<nick>",
+		descr:         "Discard the messages held from a user",
+		completer: func(args []string, arg string, as *appState) []string {
+			if len(args) == 0 {
+				return nickCompleter(arg, as)
+			}
+			return nil
+		},
+		handler: func(args []string, as *appState) error {
+			if len(args) < 1 {
+				return usageError{msg: "nick cannot be empty"}
+			}
+			uid, err := as.c.UIDByNick(args[0])
+			if err != nil {
+				return err
+			}
+			n, err := as.c.DropHeldMessages(uid)
+			if err != nil {
+				return err
+			}
+			as.cwHelpMsg("Dropped %d messages held from %s", n,
+				strescape.Nick(args[0]))
+			return nil
+		},
+	}, {
+		cmd:   "pay",
+		usage: "<nick> [<nb of msgs>]",
+		descr: "Pay the postage of messages sent to a user",
+		long: []string{
+			"The user must have requested postage for the messages sent to them. Messages held by the user are delivered once their postage is paid. By default, the postage of a single message is paid.",
+		},
+		completer: func(args []string, arg string, as *appState) []string {
+			if len(args) == 0 {
+				return nickCompleter(arg, as)
+			}
+			return nil
+		},
+		handler: func(args []string, as *appState) error {
+			if len(args) < 1 {
+				return usageError{msg: "nick cannot be empty"}
+			}
+			uid, err := as.c.UIDByNick(args[0])
+			if err != nil {
+				return err
+			}
+			nbMsgs := uint64(1)
+			if len(args) > 1 {
+				nbMsgs, err = strconv.ParseUint(args[1], 10, 32)
+				if err != nil {
+					return usageError{msg: fmt.Sprintf("invalid nb of msgs: %v", err)}
+				}
+			}
+			cw := as.findOrNewChatWindow(uid, args[0])
+			go as.payPostage(cw, uint32(nbMsgs))
+			return nil
+		},
+	},
+}
+
+var invoiceCommands = []tuicmd{
+	{
+		cmd:   "fetch",
+		usage: "<nick> <dcr amount> [<memo>]",
+		descr: "Fetch an invoice from a user without paying it",
+		long:  []string{"The invoice may be paid later with '/invoice pay' or externally. Once paid, the user receives the payment as a tip."},
+		completer: func(args []string, arg string, as *appState) []string {
+			if len(args) == 0 {
+				return nickCompleter(arg, as)
+			}
+			return nil
+		},
+		handler: func(args []string, as *appState) error {
+			uid, dcrAmount, memo, err := parseInvoiceArgs(args, as)
+			if err != nil {
+				return err
+			}
+			go as.fetchInvoice(uid, dcrAmount, memo)
+			return nil
+		},
+	}, {
+		cmd:   "send",
+		usage: "<nick> <dcr amount> [<memo>]",
+		descr: "Request a payment from a user by sending them an invoice",
+		completer: func(args []string, arg string, as *appState) []string {
+			if len(args) == 0 {
+				return nickCompleter(arg, as)
+			}
+			return nil
+		},
+		handler: func(args []string, as *appState) error {
+			uid, dcrAmount, memo, err := parseInvoiceArgs(args, as)
+			if err != nil {
+				return err
+			}
+			req, err := as.c.SendInvoice(uid, dcrAmount, memo)
+			if err != nil {
+				return err
+			}
+			as.cwHelpMsg("Sent payment request %s for %.8f DCR",
+				req.ID.ShortLogID(), dcrAmount)
+			return nil
+		},
+	}, {
+		cmd:           "list",
+		usableOffline: true,
+		usage:         "[<nick>]",
+		descr:         "List payment requests exchanged with users",
+		completer: func(args []string, arg string, as *appState) []string {
+			if len(args) == 0 {
+				return nickCompleter(arg, as)
+			}
+			return nil
+		},
+		handler: func(args []string, as *appState) error {
+			var uid *clientintf.UserID
+			if len(args) > 0 {
+				id, err := as.c.UIDByNick(args[0])
+				if err != nil {
+					return err
+				}
+				uid = &id
+			}
+			reqs, err := as.c.ListPaymentRequests(uid)
+			if err != nil {
+				return err
+			}
+			as.cwHelpMsgs(func(pf printf) {
+				pf("")
+				if len(reqs) == 0 {
+					pf("No payment requests")
+					return
+				}
+				pf("Payment requests")
+				for _, req := range reqs {
+					nick, _ := as.c.UserNick(req.UID)
+					dir := "from"
+					if req.Outbound {
+						dir = "to"
 					}
-					gcNames[gc.ID] = alias
-					nameLen := lipgloss.Width(alias)
-					if nameLen > maxNameLen {
-						maxNameLen = nameLen
+					line := fmt.Sprintf("%s %s %s %s %.8f DCR %s",
+						req.ID.ShortLogID(),
+						req.Created.Format(ISO8601DateTime), dir,
+						strescape.Nick(nick),
+						float64(req.MilliAtoms)/1e11, req.Status)
+					if req.Memo != "" {
+						line += " - " + strescape.Content(req.Memo)
 					}
+					pf("%s", line)
 				}
-				maxNameLen = clamp(maxNameLen, 5, as.winW-64-10)
-
-				sort.Slice(gcs, func(i, j int) bool {
-					ni := gcNames[gcs[i].ID]
-					nj := gcNames[gcs[j].ID]
-					return as.collator.CompareString(ni, nj) < 0
-				})
+			})
+			return nil
+		},
+	}, {
+		cmd:           "show",
+		usableOffline: true,
+		usage:         "<request id>",
+		descr:         "Show the invoice of a payment request",
+		handler: func(args []string, as *appState) error {
+			if len(args) < 1 {
+				return usageError{msg: "request id cannot be empty"}
+			}
+			req, err := as.c.PaymentRequestByPrefix(args[0])
+			if err != nil {
+				return err
+			}
+			as.cwHelpMsg("Invoice of payment request %s:\n%s",
+				req.ID.ShortLogID(), req.Invoice)
+			return nil
+		},
+	}, {
+		cmd:   "pay",
+		usage: "<request id>",
+		descr: "Pay a payment request",
+		handler: func(args []string, as *appState) error {
+			if len(args) < 1 {
+				return usageError{msg: "request id cannot be empty"}
+			}
+			req, err := as.c.PaymentRequestByPrefix(args[0])
+			if err != nil {
+				return err
+			}
+			go as.payPaymentRequest(req)
+			return nil
+		},
+	}, {
+		cmd:   "decline",
+		usage: "<request id> [<reason>]",
+		descr: "Decline a payment request",
+		handler: func(args []string, as *appState) error {
+			if len(args) < 1 {
+				return usageError{msg: "request id cannot be empty"}
+			}
+			req, err := as.c.PaymentRequestByPrefix(args[0])
+			if err != nil {
+				return err
+			}
+			reason := strings.Join(args[1:], " ")
+			if err := as.c.DeclinePaymentRequest(req.UID, req.ID, reason); err != nil {
+				return err
+			}
+			as.cwHelpMsg("Declined payment request %s", req.ID.ShortLogID())
+			return nil
+		},
+	},
+}
 
-				as.cwHelpMsgs(func(pf printf) {
-					pf("")
-					pf("List of GCs:")
-					for _, gc := range gcs {
-						gcAlias := gcNames[gc.ID]
-						pf("%*s - %s - %d members",
-							maxNameLen,
-							gcAlias,
-							gc.ID,
-							len(gc.Members))
-					}
-				})
+var creditCommands = []tuicmd{
+	{
+		cmd:   "buy",
+		usage: "<dcr amount>",
+		descr: "Buy prepaid credit from the server",
+		handler: func(args []string, as *appState) error {
+			if len(args) < 1 {
+				return usageError{msg: "amount cannot be empty"}
+			}
+			dcrAmount, err := strconv.ParseFloat(args[0], 64)
+			if err != nil {
+				return usageError{msg: fmt.Sprintf("invalid amount %q: %v", args[0], err)}
+			}
+			if dcrAmount <= 0 {
+				return usageError{msg: "cannot buy non-positive dcr amount"}
+			}
+			amount, err := dcrutil.NewAmount(dcrAmount)
+			if err != nil {
+				return err
+			}
+			go as.buyServerCredit(uint64(amount) * 1000)
+			return nil
+		},
+	}, {
+		cmd:           "receipts",
+		usableOffline: true,
+		descr:         "List the receipts of changes to the server credit balance",
+		handler: func(args []string, as *appState) error {
+			receipts, err := as.c.ListServerCreditReceipts()
+			if err != nil {
+				return err
+			}
+			as.cwHelpMsgs(func(pf printf) {
+				pf("")
+				pf("Server credit receipts")
+				for _, r := range receipts {
+					ts := time.Unix(r.Timestamp, 0)
+					pf("%s %-6s %+12.8f  balance %.8f",
+						ts.Format(ISO8601DateTime), r.Action,
+						float64(r.MilliAtoms)/1e11,
+						float64(r.Balance)/1e11)
+				}
+			})
+			return nil
+		},
+	},
+}
 
-				return nil
+var gcCommands = []tuicmd{
+	{
+		cmd:           "new",
+		usableOffline: true,
+		usage:         "<gc name>",
+		descr:         "Create a new group chat named <gc name>",
+		handler: func(args []string, as *appState) error {
+			if len(args) < 1 {
+				return usageError{msg: "gc name cannot be empty"}
+			}
+			if _, err := as.c.NewGroupChat(args[0]); err != nil {
+				return err
+			}
+			as.cwHelpMsg("GC %q created", args[0])
+			return nil
+		},
+	}, {
+		cmd:   "invite",
+		usage: "<gc name> <nick>",
+		descr: "invite the user with the given nick to join the given gc",
+		handler: func(args []string, as *appState) error {
+			if len(args) < 1 {
+				return usageError{"gc name cannot be empty"}
+			}
+			if len(args) < 2 {
+				return usageError{"invitee name cannot be empty"}
+			}
+
+			gcname, nick := args[0], args[1]
+			gcID, err := as.c.GCIDByName(gcname)
+			if err != nil {
+				return err
+			}
+			if _, err := as.c.GetGC(gcID); err != nil {
+				return err
+			}
+			cw := as.findOrNewGCWindow(gcID)
+			uid, err := as.c.UIDByNick(nick)
+			if err != nil {
+				return err
+			}
+
+			go as.inviteToGC(cw, args[1], uid)
+			return nil
+		},
+		completer: func(args []string, arg string, as *appState) []string {
+			if len(args) == 0 {
+				return gcCompleter(arg, as)
+			}
+			if len(args) == 1 {
+				return nickCompleter(arg, as)
+			}
+			return nil
+		},
+	},
+	{
+		cmd:     "msg",
+		aliases: []string{"m"},
+		usage:   "<gc name> <message>",
+		descr:   "send a message to the given GC",
+		rawHandler: func(rawCmd string, args []string, as *appState) error {
+			if len(args) < 1 {
+				return usageError{"gc name cannot be empty"}
+			}
+			if len(args) < 2 {
+				return usageError{"message cannot be empty"}
+			}
+
+			gcname := args[0]
+			gcID, err := as.c.GCIDByName(gcname)
+			if err != nil {
+				return err
+			}
+
+			_, msg := popNArgs(rawCmd, 3) // cmd + subcmd + gcname
+
+			if _, err := as.c.GetGC(gcID); err != nil {
+				return err
+			}
+			cw := as.findOrNewGCWindow(gcID)
+			go as.pm(cw, msg)
+			return nil
+		},
+		completer: func(args []string, arg string, as *appState) []string {
+			if len(args) == 0 {
+				return gcCompleter(arg, as)
+			}
+			return nil
+		},
+	},
+	{
+		cmd:   "join",
+		usage: "<gc name>",
+		descr: "Join the given GC we were invited to",
+		handler: func(args []string, as *appState) error {
+			if len(args) < 1 {
+				return usageError{"invitation id cannot be empty"}
+			}
+
+			gcName := args[0]
+			as.gcInvitesMtx.Lock()
+			iid, ok := as.gcInvites[gcName]
+			as.gcInvitesMtx.Unlock()
+			if !ok {
+				// Try to find it in the db.
+				invites, err := as.c.ListGCInvitesFor(nil)
+				if err != nil {
+					return err
+				}
+
+				for i := len(invites) - 1; i >= 0; i-- {
+					if invites[i].Invite.Name == gcName && !invites[i].Accepted {
+						iid = invites[i].ID
+						ok = true
+						break
+					}
+				}
+
+				if !ok {
+					return fmt.Errorf("unrecognized gc invite %q", gcName)
+				}
+			}
+
+			go func() {
+				err := as.c.AcceptGroupChatInvite(iid)
+				if err != nil {
+					as.diagMsg("Unable to join gc %q: %v",
+						gcName, err)
+				} else {
+					as.diagMsg("Accepting invitation to join gc %q", gcName)
+				}
+			}()
+			return nil
+		},
+	}, {
+		cmd:           "list",
+		usableOffline: true,
+		usage:         "[<gc name>]",
+		aliases:       []string{"l"},
+		descr:         "List the GCs we're a member of or members of a GC",
+		handler: func(args []string, as *appState) error {
+			if len(args) == 0 {
+				gcs, err := as.c.ListGCs()
+				if err != nil {
+					return err
+				}
+				var maxNameLen int
+				gcNames := make(map[clientintf.ID]string, len(gcs))
+				for _, gc := range gcs {
+					alias, err := as.c.GetGCAlias(gc.ID)
+					if err != nil {
+						alias = gc.ID.ShortLogID()
+					} else {
+						alias = strescape.Nick(alias)
+					}
+					gcNames[gc.ID] = alias
+					nameLen := lipgloss.Width(alias)
+					if nameLen > maxNameLen {
+						maxNameLen = nameLen
+					}
+				}
+				maxNameLen = clamp(maxNameLen, 5, as.winW-64-10)
+
+				sort.Slice(gcs, func(i, j int) bool {
+					ni := gcNames[gcs[i].ID]
+					nj := gcNames[gcs[j].ID]
+					return as.collator.CompareString(ni, nj) < 0
+				})
+
+				as.cwHelpMsgs(func(pf printf) {
+					pf("")
+					pf("List of GCs:")
+					for _, gc := range gcs {
+						gcAlias := gcNames[gc.ID]
+						pf("%*s - %s - %d members",
+							maxNameLen,
+							gcAlias,
+							gc.ID,
+							len(gc.Members))
+					}
+				})
+
+				return nil
+			}
+
+			gcID, err := as.c.GCIDByName(args[0])
+			if err != nil {
+				return err
+			}
+
+			gc, err := as.c.GetGC(gcID)
+			if err != nil {
+				return err
+			}
+			gcName, _ := as.c.GetGCAlias(gcID)
+			if gcName == "" {
+				gcName = args[0]
+			}
+
+			gcbl, err := as.c.GetGCBlockList(gcID)
+			if err != nil {
+				return err
+			}
+
+			// Collect and sort members according to display order.
+			var maxNickW int
+			members := slices.Clone(gc.Members[:])
+			myID := as.c.PublicID()
+			if idx := slices.Index(members, myID); idx > -1 {
+				// Remove local client id from list.
+				members = slices.Delete(members, idx, idx+1)
+			}
+			users := make(map[clientintf.UserID]*client.RemoteUser, len(members))
+			for _, uid := range members {
+				ru, _ := as.c.UserByID(uid)
+				users[uid] = ru
+				if ru != nil {
+					nick := ru.Nick()
+					nicklen := lipgloss.Width(nick)
+					if nicklen > maxNickW {
+						maxNickW = nicklen
+					}
+				}
+			}
+			sort.Slice(members, func(i, j int) bool {
+				ui := users[members[i]]
+				uj := users[members[j]]
+				if ui != nil && uj != nil {
+					ni := ui.Nick()
+					nj := uj.Nick()
+					return as.collator.CompareString(ni, nj) < 0
+				}
+				if uj == nil && ui == nil {
+					return members[i].Less(&members[j])
+				}
+				if uj == nil && ui != nil {
+					return true
+				}
+				return false
+			})
+
+			maxNickW = clamp(maxNickW, 5, as.winW-64-10)
+
+			as.cwHelpMsgs(func(pf printf) {
+				pf("")
+				pf("GC %q - %s", gcName, gc.ID.String())
+				pf("Version: %d, Generation: %d, Timestamp: %s",
+					gc.Version, gc.Generation,
+					time.Unix(gc.Timestamp, 0).Format(ISO8601DateTime))
+				if gc.Members[0] == myID {
+					pf("Local client is owner of this GC")
+				} else if slices.Contains(gc.ExtraAdmins, myID) {
+					pf("Local client is admin of this GC")
+				}
+				pf("Members (%d + local client)", len(members))
+				firstUknown := true
+				for _, uid := range members {
+					var ignored string
+					if uid == gc.Members[0] {
+						ignored += " (owner)"
+					} else if slices.Contains(gc.ExtraAdmins, uid) {
+						ignored += " (admin)"
+					}
+					if gcbl.IsBlocked(uid) {
+						ignored += " (in GC blocklist)"
+					}
+					ru := users[uid]
+					if ru == nil {
+						if firstUknown {
+							pf("")
+							pf("Uknonwn or KX incomplete members")
+							firstUknown = false
+						}
+						pf("%s", uid)
+					} else {
+						nick := strescape.Nick(ru.Nick())
+						if len(nick) > maxNickW {
+							nick = nick[:maxNickW]
+						}
+						if ignored == "" && ru.IsIgnored() {
+							ignored = " (ignored)"
+						}
+						pf("%*s - %s%s", maxNickW, nick, uid, ignored)
+					}
+				}
+			})
+
+			return nil
+		},
+	}, {
+		cmd:   "kick",
+		usage: "<gc> <nick> [<reason>]",
+		descr: "Kick the given user from the specified GC",
+		handler: func(args []string, as *appState) error {
+			if len(args) < 1 {
+				return usageError{msg: "gc name cannot be empty"}
+			}
+			if len(args) < 2 {
+				return usageError{msg: "nick cannot be empty"}
+			}
+			var reason string
+			if len(args) > 2 {
+				reason = strings.Join(args[2:], " ")
+			}
+			gcID, err := as.c.GCIDByName(args[0])
+			if err != nil {
+				return err
+			}
+			nick := args[1]
+
+			if _, err := as.c.GetGC(gcID); err != nil {
+				return err
+			}
+			var uid clientintf.UserID
+			if len(nick) == 64 {
+				// Handle gc kicks with UserIDs instead of
+				// relying on UIDbyNick to do that to handle
+				// kicking blocked users from the GC.
+				err := uid.FromString(nick)
+				if err != nil {
+					return err
+				}
+			} else {
+				uid, err = as.c.UIDByNick(nick)
+				if err != nil {
+					return err
+				}
+			}
+			gcWin := as.findOrNewGCWindow(gcID)
+
+			go as.kickFromGC(gcWin, uid, nick, reason)
+			return nil
+		},
+		completer: func(args []string, arg string, as *appState) []string {
+			if len(args) == 0 {
+				return gcCompleter(arg, as)
+			}
+			if len(args) == 1 {
+				return nickCompleter(arg, as)
+			}
+			return nil
+		},
+	}, {
+		cmd:   "part",
+		usage: "<gc> [<reason>]",
+		descr: "Exit from the specified GC",
+		handler: func(args []string, as *appState) error {
+			if len(args) < 1 {
+				return usageError{msg: "gc name cannot be empty"}
+			}
+			var reason string
+			if len(args) > 1 {
+				reason = strings.Join(args[1:], " ")
+			}
+			gcID, err := as.c.GCIDByName(args[0])
+			if err != nil {
+				return err
+			}
+			if _, err := as.c.GetGC(gcID); err != nil {
+				return err
+			}
+			gcWin := as.findOrNewGCWindow(gcID)
+			go as.partFromGC(gcWin, reason)
+			return nil
+		},
+		completer: func(args []string, arg string, as *appState) []string {
+			if len(args) == 0 {
+				return gcCompleter(arg, as)
+			}
+			return nil
+		},
+	}, {
+		cmd:   "kill",
+		usage: "<gc> [<reason>]",
+		descr: "Dissolve the specified GC",
+		handler: func(args []string, as *appState) error {
+			if len(args) < 1 {
+				return usageError{msg: "gc name cannot be empty"}
+			}
+			var reason string
+			if len(args) > 1 {
+				reason = strings.Join(args[1:], " ")
+			}
+			gcID, err := as.c.GCIDByName(args[0])
+			if err != nil {
+				return err
+			}
+			if _, err := as.c.GetGC(gcID); err != nil {
+				return err
+			}
+			gcWin := as.findOrNewGCWindow(gcID)
+			go as.killGC(gcWin, reason)
+			return nil
+		},
+		completer: func(args []string, arg string, as *appState) []string {
+			if len(args) == 0 {
+				return gcCompleter(arg, as)
+			}
+			return nil
+		},
+	}, {
+		cmd:           "ignore",
+		usableOffline: true,
+		usage:         "<gc> <user>",
+		descr:         "Ignore a user's messages in this specific GC",
+		long: []string{
+			"This also stops sending messages to the specified user in this GC",
+		},
+		handler: func(args []string, as *appState) error {
+			if len(args) < 1 {
+				return usageError{msg: "gc name cannot be empty"}
+			}
+			if len(args) < 2 {
+				return usageError{msg: "user cannot be empty"}
+			}
+
+			gcID, err := as.c.GCIDByName(args[0])
+			if err != nil {
+				return err
+			}
+			uid, err := as.c.UIDByNick(args[1])
+			if err != nil {
+				return err
+			}
+
+			gcWin := as.findOrNewGCWindow(gcID)
+			if err := as.c.AddToGCBlockList(gcID, uid); err != nil {
+				return err
+			}
+			gcWin.newInternalMsg(fmt.Sprintf("Ignored user %s in GC", args[1]))
+			as.repaintIfActive(gcWin)
+			return nil
+		},
+		completer: func(args []string, arg string, as *appState) []string {
+			if len(args) == 0 {
+				return gcCompleter(arg, as)
+			}
+			if len(args) == 1 {
+				return nickCompleter(arg, as)
+			}
+			return nil
+		},
+	}, {
+		cmd:           "unignore",
+		usableOffline: true,
+		usage:         "<gc> <user>",
+		descr:         "Un-ignore a user's messages in this specific GC",
+		handler: func(args []string, as *appState) error {
+			if len(args) < 1 {
+				return usageError{msg: "gc name cannot be empty"}
+			}
+			if len(args) < 2 {
+				return usageError{msg: "user cannot be empty"}
+			}
+
+			gcID, err := as.c.GCIDByName(args[0])
+			if err != nil {
+				return err
+			}
+			uid, err := as.c.UIDByNick(args[1])
+			if err != nil {
+				return err
+			}
+
+			gcWin := as.findOrNewGCWindow(gcID)
+			if err := as.c.RemoveFromGCBlockList(gcID, uid); err != nil {
+				return err
+			}
+			gcWin.newInternalMsg(fmt.Sprintf("Un-ignored user %s in GC", args[1]))
+			as.repaintIfActive(gcWin)
+			return nil
+		},
+		completer: func(args []string, arg string, as *appState) []string {
+			if len(args) == 0 {
+				return gcCompleter(arg, as)
+			}
+			if len(args) == 1 {
+				return nickCompleter(arg, as)
+			}
+			return nil
+		},
+	}, {
+		cmd:           "alias",
+		usableOffline: true,
+		usage:         "<existing gc> <new alias>",
+		descr:         "Modify the local alias of a GC",
+		handler: func(args []string, as *appState) error {
+			if len(args) < 1 {
+				return usageError{msg: "existing gc name cannot be empty"}
+			}
+			if len(args) < 2 {
+				return usageError{msg: "new gc alias cannot be empty"}
+			}
+
+			gcID, err := as.c.GCIDByName(args[0])
+			if err != nil {
+				return err
+			}
+			newAlias := args[1]
+			if err := as.c.AliasGC(gcID, newAlias); err != nil {
+				return err
+			}
+
+			gcWin := as.findOrNewGCWindow(gcID)
+			gcWin.newInternalMsg(fmt.Sprintf("Renamed GC to %s", newAlias))
+			gcWin.alias = newAlias // FIXME: this is racing.
+			as.repaintIfActive(gcWin)
+			return nil
+		},
+		completer: func(args []string, arg string, as *appState) []string {
+			if len(args) == 0 {
+				return gcCompleter(arg, as)
+			}
+			return nil
+		},
+	}, {
+		cmd:   "resendlist",
+		usage: "<gc> [<user>]",
+		descr: "Resends the GC definition to the specified user or all users",
+		handler: func(args []string, as *appState) error {
+			if len(args) < 1 {
+				return usageError{msg: "GC cannot be empty"}
+			}
+
+			gcID, err := as.c.GCIDByName(args[0])
+			if err != nil {
+				return err
+			}
+
+			var uid *clientintf.UserID
+			if len(args) > 1 {
+				user, err := as.c.UIDByNick(args[1])
+				if err != nil {
+					return err
+				}
+				uid = &user
+			}
+
+			go func() {
+				gcWin := as.findOrNewGCWindow(gcID)
+				var msg *chatMsg
+				if uid == nil {
+					msg = gcWin.newInternalMsg("Resending GC list to all GC members")
+				} else {
+					nick, _ := as.c.UserNick(*uid)
+					msg = gcWin.newInternalMsg(fmt.Sprintf("Resending GC list to %q", nick))
+				}
+				as.repaintIfActive(gcWin)
+				err := as.c.ResendGCList(gcID, uid)
+				if err != nil {
+					gcWin.newHelpMsg("Unable to resent GC List: %v", err)
+				} else {
+					gcWin.setMsgSent(msg)
+				}
+				as.repaintIfActive(gcWin)
+			}()
+
+			return nil
+		},
+
+		completer: func(args []string, arg string, as *appState) []string {
+			if len(args) == 0 {
+				return gcCompleter(arg, as)
+			}
+			if len(args) == 1 {
+				return nickCompleter(arg, as)
+			}
+			return nil
+		},
+	}, {
+		cmd:   "upgrade",
+		usage: "<gc>",
+		descr: "Upgrades the GC to the next available version",
+		handler: func(args []string, as *appState) error {
+			if len(args) < 1 {
+				return usageError{msg: "GC cannot be empty"}
+			}
+			gcID, err := as.c.GCIDByName(args[0])
+			if err != nil {
+				return err
+			}
+			gc, err := as.c.GetGC(gcID)
+			if err != nil {
+				return err
+			}
+			newVersion := gc.Version + 1
+			err = as.c.UpgradeGC(gcID, newVersion)
+			if err != nil {
+				return err
+			}
+
+			cw := as.findOrNewGCWindow(gc.ID)
+			cw.newHelpMsg("Upgraded GC to version %d", newVersion)
+			as.repaintIfActive(cw)
+			return nil
+		},
+
+		completer: func(args []string, arg string, as *appState) []string {
+			if len(args) == 0 {
+				return gcCompleter(arg, as)
+			}
+			return nil
+		},
+	}, {
+		cmd:   "addadmin",
+		usage: "<gc> <new admin>",
+		descr: "Add a user as an admin of a GC",
+		handler: func(args []string, as *appState) error {
+			if len(args) < 1 {
+				return usageError{msg: "GC cannot be empty"}
+			}
+			if len(args) < 2 {
+				return usageError{msg: "New admin cannot be empty"}
 			}
 
 			gcID, err := as.c.GCIDByName(args[0])
@@ -717,629 +1867,1165 @@ var gcCommands = []tuicmd{
 				return err
 			}
 
-			gc, err := as.c.GetGC(gcID)
+			uid, err := as.c.UIDByNick(args[1])
 			if err != nil {
 				return err
 			}
-			gcName, _ := as.c.GetGCAlias(gcID)
-			if gcName == "" {
-				gcName = args[0]
+
+			return as.modifyGCAdmins(gcID, uid, clientintf.UserID{})
+		},
+
+		completer: func(args []string, arg string, as *appState) []string {
+			if len(args) == 0 {
+				return gcCompleter(arg, as)
+			}
+			if len(args) == 1 {
+				return nickCompleter(arg, as)
+			}
+			return nil
+		},
+	}, {
+		cmd:   "deladmin",
+		usage: "<gc> <existing admin>",
+		descr: "Removes a user as an admin of a GC",
+		handler: func(args []string, as *appState) error {
+			if len(args) < 1 {
+				return usageError{msg: "GC cannot be empty"}
+			}
+			if len(args) < 2 {
+				return usageError{msg: "New admin cannot be empty"}
 			}
 
-			gcbl, err := as.c.GetGCBlockList(gcID)
+			gcID, err := as.c.GCIDByName(args[0])
 			if err != nil {
 				return err
 			}
 
-			// Collect and sort members according to display order.
-			var maxNickW int
-			members := slices.Clone(gc.Members[:])
-			myID := as.c.PublicID()
-			if idx := slices.Index(members, myID); idx > -1 {
-				// Remove local client id from list.
-				members = slices.Delete(members, idx, idx+1)
-			}
-			users := make(map[clientintf.UserID]*client.RemoteUser, len(members))
-			for _, uid := range members {
-				ru, _ := as.c.UserByID(uid)
-				users[uid] = ru
-				if ru != nil {
-					nick := ru.Nick()
-					nicklen := lipgloss.Width(nick)
-					if nicklen > maxNickW {
-						maxNickW = nicklen
-					}
-				}
+			uid, err := as.c.UIDByNick(args[1])
+			if err != nil {
+				return err
 			}
-			sort.Slice(members, func(i, j int) bool {
-				ui := users[members[i]]
-				uj := users[members[j]]
-				if ui != nil && uj != nil {
-					ni := ui.Nick()
-					nj := uj.Nick()
-					return as.collator.CompareString(ni, nj) < 0
-				}
-				if uj == nil && ui == nil {
-					return members[i].Less(&members[j])
-				}
-				if uj == nil && ui != nil {
-					return true
-				}
-				return false
-			})
 
-			maxNickW = clamp(maxNickW, 5, as.winW-64-10)
+			return as.modifyGCAdmins(gcID, clientintf.UserID{}, uid)
+		},
 
-			as.cwHelpMsgs(func(pf printf) {
-				pf("")
-				pf("GC %q - %s", gcName, gc.ID.String())
-				pf("Version: %d, Generation: %d, Timestamp: %s",
-					gc.Version, gc.Generation,
-					time.Unix(gc.Timestamp, 0).Format(ISO8601DateTime))
-				if gc.Members[0] == myID {
-					pf("Local client is owner of this GC")
-				} else if slices.Contains(gc.ExtraAdmins, myID) {
-					pf("Local client is admin of this GC")
-				}
-				pf("Members (%d + local client)", len(members))
-				firstUknown := true
-				for _, uid := range members {
-					var ignored string
-					if uid == gc.Members[0] {
-						ignored += " (owner)"
-					} else if slices.Contains(gc.ExtraAdmins, uid) {
-						ignored += " (admin)"
-					}
-					if gcbl.IsBlocked(uid) {
-						ignored += " (in GC blocklist)"
-					}
-					ru := users[uid]
-					if ru == nil {
-						if firstUknown {
-							pf("")
-							pf("Uknonwn or KX incomplete members")
-							firstUknown = false
-						}
-						pf("%s", uid)
-					} else {
-						nick := strescape.Nick(ru.Nick())
-						if len(nick) > maxNickW {
-							nick = nick[:maxNickW]
-						}
-						if ignored == "" && ru.IsIgnored() {
-							ignored = " (ignored)"
-						}
-						pf("%*s - %s%s", maxNickW, nick, uid, ignored)
-					}
-				}
-			})
+		completer: func(args []string, arg string, as *appState) []string {
+			if len(args) == 0 {
+				return gcCompleter(arg, as)
+			}
+			if len(args) == 1 {
+				return nickCompleter(arg, as)
+			}
+			return nil
+		},
+	},
+}
 
+var ftCommands = []tuicmd{
+	{
+		cmd:           "share",
+		usableOffline: true,
+		usage:         "<filename> <cost> [<nick>]",
+		descr:         "Share the given file for distribution",
+		long: []string{
+			"Imports the passed file into the local FTP repository. The cost is specified in DCR.",
+			"If a nick or user ID is specified, the file is shared only to that user.",
+			"By default, the passed cost is *added* to the estimated upload cost. Use \"=<amount>\" to directly specify the full cost",
+		},
+		completer: func(args []string, arg string, as *appState) []string {
+			if len(args) == 0 {
+				return fileCompleter(arg)
+			}
+			if len(args) == 2 {
+				return nickCompleter(arg, as)
+			}
 			return nil
 		},
+		handler: func(args []string, as *appState) error {
+			return shareFileCmd(args, as, false)
+		},
 	}, {
-		cmd:   "kick",
-		usage: "<gc> <nick> [<reason>]",
-		descr: "Kick the given user from the specified GC",
+		cmd:           "sharez",
+		usableOffline: true,
+		usage:         "<filename> <cost> [<nick>]",
+		descr:         "Share the given file compressed",
+		long: []string{
+			"Same as the share command, but the file is compressed before being shared, which reduces the cost of transferring compressible files (text, documents, uncompressed archives, etc).",
+			"The file is shared uncompressed if compressing it does not reduce its size.",
+		},
+		completer: func(args []string, arg string, as *appState) []string {
+			if len(args) == 0 {
+				return fileCompleter(arg)
+			}
+			if len(args) == 2 {
+				return nickCompleter(arg, as)
+			}
+			return nil
+		},
+		handler: func(args []string, as *appState) error {
+			return shareFileCmd(args, as, true)
+		},
+	}, {
+		cmd:           "list",
+		usableOffline: true,
+		aliases:       []string{"ls", "l"},
+		usage:         "<nick> <*|shared|global> [<filename_regex>]",
+		descr:         "List files of a remote peer",
 		handler: func(args []string, as *appState) error {
 			if len(args) < 1 {
-				return usageError{msg: "gc name cannot be empty"}
+				return usageError{msg: "nick cannot be empty"}
 			}
 			if len(args) < 2 {
-				return usageError{msg: "nick cannot be empty"}
+				return usageError{msg: "dir cannot be empty"}
 			}
-			var reason string
+
+			uid, err := as.c.UIDByNick(args[0])
+			if err != nil {
+				return err
+			}
+
+			cw := as.findOrNewChatWindow(uid, args[0])
+			var filter string
 			if len(args) > 2 {
-				reason = strings.Join(args[2:], " ")
+				filter = args[2]
 			}
-			gcID, err := as.c.GCIDByName(args[0])
+			go as.listUserContent(cw, args[1], filter)
+			return nil
+		},
+		completer: func(args []string, arg string, as *appState) []string {
+			if len(args) == 0 {
+				return nickCompleter(arg, as)
+			}
+			return nil
+		},
+	}, {
+		cmd:   "get",
+		usage: "<nick> [<filename> | <FID>]",
+		descr: "Fetch the given file from the remote peer",
+		long: []string{
+			"The file can be referenced either as a filename (in which case the local client must have had a /ft ls issued first) or a full file ID.",
+			"If the file requires payment, the remote peer will send an invoice that will be automatically paid before actually receiving the file's contents",
+		},
+		handler: func(args []string, as *appState) error {
+			if len(args) < 1 {
+				return usageError{msg: "nick cannot be empty"}
+			}
+			if len(args) < 2 {
+				return usageError{msg: "filename cannot be empty"}
+			}
+
+			uid, err := as.c.UIDByNick(args[0])
 			if err != nil {
 				return err
 			}
-			nick := args[1]
 
-			if _, err := as.c.GetGC(gcID); err != nil {
-				return err
+			cw := as.findOrNewChatWindow(uid, args[0])
+			go as.getUserContent(cw, args[1])
+			return nil
+		},
+		completer: func(args []string, arg string, as *appState) []string {
+			if len(args) == 0 {
+				return nickCompleter(arg, as)
 			}
-			var uid clientintf.UserID
-			if len(nick) == 64 {
-				// Handle gc kicks with UserIDs instead of
-				// relying on UIDbyNick to do that to handle
-				// kicking blocked users from the GC.
-				err := uid.FromString(nick)
-				if err != nil {
-					return err
-				}
-			} else {
-				uid, err = as.c.UIDByNick(nick)
-				if err != nil {
-					return err
+			return nil
+		},
+	}, {
+		cmd:   "preview",
+		usage: "<nick> [<filename> | <FID>] [<size in KiB>]",
+		descr: "Fetch the start of the given file to preview it",
+		long: []string{
+			"Fetches (and pays for) only the chunks needed for the first bytes of the file (by default, 1024 KiB) and opens them with the external viewer configured for the type of the file.",
+			"The fetched chunks are reused if the full file is later fetched with /ft get.",
+		},
+		handler: func(args []string, as *appState) error {
+			if len(args) < 1 {
+				return usageError{msg: "nick cannot be empty"}
+			}
+			if len(args) < 2 {
+				return usageError{msg: "filename cannot be empty"}
+			}
+			size := uint64(1024)
+			if len(args) > 2 {
+				var err error
+				size, err = strconv.ParseUint(args[2], 10, 64)
+				if err != nil || size == 0 {
+					return usageError{msg: fmt.Sprintf("invalid size %q", args[2])}
 				}
 			}
-			gcWin := as.findOrNewGCWindow(gcID)
 
-			go as.kickFromGC(gcWin, uid, nick, reason)
+			uid, err := as.c.UIDByNick(args[0])
+			if err != nil {
+				return err
+			}
+
+			cw := as.findOrNewChatWindow(uid, args[0])
+			go as.previewUserContent(cw, args[1], size*1024)
 			return nil
 		},
 		completer: func(args []string, arg string, as *appState) []string {
 			if len(args) == 0 {
-				return gcCompleter(arg, as)
-			}
-			if len(args) == 1 {
 				return nickCompleter(arg, as)
 			}
 			return nil
 		},
 	}, {
-		cmd:   "part",
-		usage: "<gc> [<reason>]",
-		descr: "Exit from the specified GC",
+		cmd:           "estimatecost",
+		usableOffline: true,
+		aliases:       []string{"estcost"},
+		descr:         "Estimate cost of uploading the specified file",
+		usage:         "<filepath>",
+		completer: func(args []string, arg string, as *appState) []string {
+			return fileCompleter(arg)
+		},
 		handler: func(args []string, as *appState) error {
 			if len(args) < 1 {
-				return usageError{msg: "gc name cannot be empty"}
-			}
-			var reason string
-			if len(args) > 1 {
-				reason = strings.Join(args[1:], " ")
+				return usageError{msg: "file path cannot be empty"}
 			}
-			gcID, err := as.c.GCIDByName(args[0])
+
+			fpath := args[0]
+			stat, err := os.Stat(fpath)
 			if err != nil {
 				return err
 			}
-			if _, err := as.c.GetGC(gcID); err != nil {
+
+			feeRate, _ := as.serverPaymentRates()
+			size := stat.Size()
+			cost, err := clientintf.EstimateUploadCost(size, feeRate)
+			if err != nil {
 				return err
 			}
-			gcWin := as.findOrNewGCWindow(gcID)
-			go as.partFromGC(gcWin, reason)
-			return nil
-		},
-		completer: func(args []string, arg string, as *appState) []string {
-			if len(args) == 0 {
-				return gcCompleter(arg, as)
-			}
+			as.cwHelpMsg("Cost to upload file (%d B): %s", size,
+				dcrutil.Amount(cost/1e3))
+
 			return nil
 		},
 	}, {
-		cmd:   "kill",
-		usage: "<gc> [<reason>]",
-		descr: "Dissolve the specified GC",
+		cmd:           "unshare",
+		usableOffline: true,
+		usage:         "<file> [<user>]",
+		descr:         "Unshare a file",
 		handler: func(args []string, as *appState) error {
 			if len(args) < 1 {
-				return usageError{msg: "gc name cannot be empty"}
+				return usageError{msg: "file cannot be empty"}
 			}
-			var reason string
+			var user *clientintf.UserID
 			if len(args) > 1 {
-				reason = strings.Join(args[1:], " ")
+				uid, err := as.c.UIDByNick(args[1])
+				if err != nil {
+					return err
+				}
+				user = &uid
 			}
-			gcID, err := as.c.GCIDByName(args[0])
+
+			files, err := as.c.ListLocalSharedFiles()
 			if err != nil {
-				return err
+				return nil
 			}
-			if _, err := as.c.GetGC(gcID); err != nil {
+			var fid zkidentity.ShortID
+			if err := fid.FromString(args[0]); err != nil {
+				// Try to find the named file.
+				for _, f := range files {
+					if f.SF.Filename == args[0] {
+						fid = f.SF.FID
+						break
+					}
+				}
+				if fid.IsEmpty() {
+					return fmt.Errorf("could not find shared file %q",
+						args[0])
+				}
+			}
+
+			err = as.c.UnshareFile(fid, user)
+			if err != nil {
 				return err
 			}
-			gcWin := as.findOrNewGCWindow(gcID)
-			go as.killGC(gcWin, reason)
+
+			as.cwHelpMsg("Unshared file %s", fid)
 			return nil
 		},
+	}, {
+		cmd:           "sharegc",
+		usableOffline: true,
+		usage:         "<filename> <cost> <gc>",
+		descr:         "Share the given file with the members of a GC",
+		long: []string{
+			"Imports the passed file into the local FTP repository and shares it with the current members of the GC. The cost is specified in DCR, as in the 'share' command.",
+			"Members that leave or are kicked from the GC lose access to the file. GC members are notified of the shared file with a GC message.",
+		},
 		completer: func(args []string, arg string, as *appState) []string {
 			if len(args) == 0 {
+				return fileCompleter(arg)
+			}
+			if len(args) == 2 {
 				return gcCompleter(arg, as)
 			}
 			return nil
 		},
-	}, {
-		cmd:           "ignore",
-		usableOffline: true,
-		usage:         "<gc> <user>",
-		descr:         "Ignore a user's messages in this specific GC",
-		long: []string{
-			"This also stops sending messages to the specified user in this GC",
-		},
 		handler: func(args []string, as *appState) error {
 			if len(args) < 1 {
-				return usageError{msg: "gc name cannot be empty"}
+				return usageError{msg: "filename cannot be empty"}
 			}
-			if len(args) < 2 {
-				return usageError{msg: "user cannot be empty"}
+			if len(args) < 2 || len(args[1]) < 1 {
+				return usageError{msg: "cost cannot be empty"}
+			}
+			if len(args) < 3 {
+				return usageError{msg: "gc cannot be empty"}
 			}
 
-			gcID, err := as.c.GCIDByName(args[0])
+			filename, err := homedir.Expand(args[0])
 			if err != nil {
 				return err
 			}
-			uid, err := as.c.UIDByNick(args[1])
+			dcrCost, dcrUploadCost, err := shareCost(filename, args[1], as)
+			if err != nil {
+				return err
+			}
+			gcID, err := as.c.GCIDByName(args[2])
 			if err != nil {
 				return err
 			}
 
-			gcWin := as.findOrNewGCWindow(gcID)
-			if err := as.c.AddToGCBlockList(gcID, uid); err != nil {
+			atomCost := uint64(dcrCost * 1e8)
+			sf, _, err := as.c.ShareFileWithGC(filename, gcID, atomCost, "")
+			if err != nil {
 				return err
 			}
-			gcWin.newInternalMsg(fmt.Sprintf("Ignored user %s in GC", args[1]))
-			as.repaintIfActive(gcWin)
+			as.cwHelpMsg("Shared file %q for %.8f DCR (est. cost %.8f DCR) "+
+				"with GC %q. FID: %s", sf.Filename, dcrCost,
+				dcrUploadCost, args[2], sf.FID)
 			return nil
 		},
+	}, {
+		cmd:           "unsharegc",
+		usableOffline: true,
+		usage:         "<file> <gc>",
+		descr:         "Unshare a file shared with a GC",
 		completer: func(args []string, arg string, as *appState) []string {
-			if len(args) == 0 {
-				return gcCompleter(arg, as)
-			}
 			if len(args) == 1 {
-				return nickCompleter(arg, as)
+				return gcCompleter(arg, as)
 			}
 			return nil
 		},
-	}, {
-		cmd:           "unignore",
-		usableOffline: true,
-		usage:         "<gc> <user>",
-		descr:         "Un-ignore a user's messages in this specific GC",
 		handler: func(args []string, as *appState) error {
-			if len(args) < 1 {
-				return usageError{msg: "gc name cannot be empty"}
-			}
 			if len(args) < 2 {
-				return usageError{msg: "user cannot be empty"}
+				return usageError{msg: "file and gc cannot be empty"}
 			}
-
-			gcID, err := as.c.GCIDByName(args[0])
+			gcID, err := as.c.GCIDByName(args[1])
 			if err != nil {
 				return err
 			}
-			uid, err := as.c.UIDByNick(args[1])
+			files, err := as.c.ListGCSharedFiles(gcID)
 			if err != nil {
 				return err
 			}
+			var fid zkidentity.ShortID
+			if err := fid.FromString(args[0]); err != nil {
+				// Try to find the named file.
+				for i := range files {
+					if files[i].Filename == args[0] {
+						fid = files[i].MetadataHash()
+						break
+					}
+				}
+				if fid.IsEmpty() {
+					return fmt.Errorf("could not find file %q shared "+
+						"with GC", args[0])
+				}
+			}
 
-			gcWin := as.findOrNewGCWindow(gcID)
-			if err := as.c.RemoveFromGCBlockList(gcID, uid); err != nil {
+			if err := as.c.UnshareFileWithGC(fid, gcID); err != nil {
 				return err
 			}
-			gcWin.newInternalMsg(fmt.Sprintf("Un-ignored user %s in GC", args[1]))
-			as.repaintIfActive(gcWin)
+			as.cwHelpMsg("Unshared file %s with GC %q", fid, args[1])
 			return nil
 		},
+	}, {
+		cmd:   "send",
+		usage: "<user> <filename>",
+		descr: "Send a file to user",
 		completer: func(args []string, arg string, as *appState) []string {
 			if len(args) == 0 {
-				return gcCompleter(arg, as)
+				return nickCompleter(arg, as)
 			}
 			if len(args) == 1 {
-				return nickCompleter(arg, as)
+				return fileCompleter(arg)
 			}
 			return nil
 		},
-	}, {
-		cmd:           "alias",
-		usableOffline: true,
-		usage:         "<existing gc> <new alias>",
-		descr:         "Modify the local alias of a GC",
 		handler: func(args []string, as *appState) error {
 			if len(args) < 1 {
-				return usageError{msg: "existing gc name cannot be empty"}
+				return usageError{msg: "user cannot be empty"}
 			}
 			if len(args) < 2 {
-				return usageError{msg: "new gc alias cannot be empty"}
+				return usageError{msg: "filename cannot be empty"}
 			}
 
-			gcID, err := as.c.GCIDByName(args[0])
+			uid, err := as.c.UIDByNick(args[0])
 			if err != nil {
 				return err
 			}
-			newAlias := args[1]
-			if err := as.c.AliasGC(gcID, newAlias); err != nil {
+			nick, err := as.c.UserNick(uid)
+			if err != nil {
+				nick = args[0]
+			}
+
+			filename := args[1]
+			err = as.c.SendFile(uid, filename)
+			if err != nil {
 				return err
 			}
 
-			gcWin := as.findOrNewGCWindow(gcID)
-			gcWin.newInternalMsg(fmt.Sprintf("Renamed GC to %s", newAlias))
-			gcWin.alias = newAlias // FIXME: this is racing.
-			as.repaintIfActive(gcWin)
-			return nil
-		},
-		completer: func(args []string, arg string, as *appState) []string {
-			if len(args) == 0 {
-				return gcCompleter(arg, as)
+			cw := as.findChatWindow(uid)
+			msg := fmt.Sprintf("Sending file %q to user %q",
+				filepath.Base(filename), strescape.Nick(nick))
+			if cw == nil {
+				as.cwHelpMsg(msg)
+			} else {
+				cw.newHelpMsg(msg)
 			}
+
 			return nil
 		},
 	}, {
-		cmd:   "resendlist",
-		usage: "<gc> [<user>]",
-		descr: "Resends the GC definition to the specified user or all users",
+		cmd:           "uploads",
+		usableOffline: true,
+		descr:         "List in-progress uploads",
 		handler: func(args []string, as *appState) error {
-			if len(args) < 1 {
-				return usageError{msg: "GC cannot be empty"}
-			}
-
-			gcID, err := as.c.GCIDByName(args[0])
+			ups, err := as.c.ListUploads()
 			if err != nil {
 				return err
 			}
-
-			var uid *clientintf.UserID
-			if len(args) > 1 {
-				user, err := as.c.UIDByNick(args[1])
-				if err != nil {
-					return err
-				}
-				uid = &user
-			}
-
-			go func() {
-				gcWin := as.findOrNewGCWindow(gcID)
-				var msg *chatMsg
-				if uid == nil {
-					msg = gcWin.newInternalMsg("Resending GC list to all GC members")
-				} else {
-					nick, _ := as.c.UserNick(*uid)
-					msg = gcWin.newInternalMsg(fmt.Sprintf("Resending GC list to %q", nick))
-				}
-				as.repaintIfActive(gcWin)
-				err := as.c.ResendGCList(gcID, uid)
-				if err != nil {
-					gcWin.newHelpMsg("Unable to resent GC List: %v", err)
-				} else {
-					gcWin.setMsgSent(msg)
+			as.cwHelpMsgs(func(pf printf) {
+				pf("")
+				pf("Uploads")
+				for _, up := range ups {
+					nick, _ := as.c.UserNick(up.UID)
+					if nick == "" {
+						nick = up.UID.String()
+					}
+					paused := ""
+					if up.Paused {
+						paused = " (paused)"
+					}
+					pf("%s - %s%s", up.FID, strescape.Nick(nick), paused)
+					pf("Outstanding chunks: %d", len(up.Chunks))
+					pf("")
 				}
-				as.repaintIfActive(gcWin)
-			}()
-
+			})
 			return nil
 		},
-
-		completer: func(args []string, arg string, as *appState) []string {
-			if len(args) == 0 {
-				return gcCompleter(arg, as)
+	}, {
+		cmd:           "pause",
+		usableOffline: true,
+		usage:         "<down|up> <fid> [<nick>]",
+		descr:         "Pause an in-progress download or upload",
+		long: []string{
+			"Paused downloads do not request or pay for new chunks. Paused uploads do not send invoices or chunks to the downloader.",
+			"The nick is only needed for uploads, when the file is being uploaded to multiple users.",
+		},
+		completer: transferCmdCompleter,
+		handler: func(args []string, as *appState) error {
+			isUpload, fid, uid, err := transferArgs(args, as)
+			if err != nil {
+				return err
+			}
+			if isUpload {
+				err = as.c.PauseUpload(uid, fid)
+			} else {
+				err = as.c.PauseDownload(fid)
 			}
-			if len(args) == 1 {
-				return nickCompleter(arg, as)
+			if err != nil {
+				return err
 			}
+			as.cwHelpMsg("Paused transfer of file %s", fid)
 			return nil
 		},
 	}, {
-		cmd:   "upgrade",
-		usage: "<gc>",
-		descr: "Upgrades the GC to the next available version",
+		cmd:   "resume",
+		usage: "<down|up> <fid> [<nick>]",
+		descr: "Resume a paused download or upload",
+		long: []string{
+			"The nick is only needed for uploads, when the file is being uploaded to multiple users.",
+		},
+		completer: transferCmdCompleter,
 		handler: func(args []string, as *appState) error {
-			if len(args) < 1 {
-				return usageError{msg: "GC cannot be empty"}
+			isUpload, fid, uid, err := transferArgs(args, as)
+			if err != nil {
+				return err
+			}
+			if isUpload {
+				err = as.c.ResumeUpload(uid, fid)
+			} else {
+				err = as.c.ResumeDownload(fid)
 			}
-			gcID, err := as.c.GCIDByName(args[0])
 			if err != nil {
 				return err
 			}
-			gc, err := as.c.GetGC(gcID)
+			as.cwHelpMsg("Resumed transfer of file %s", fid)
+			return nil
+		},
+	}, {
+		cmd:   "cancel",
+		usage: "<down|up> <fid> [<nick>]",
+		descr: "Cancel an in-progress download or upload",
+		long: []string{
+			"The remote user is notified that the transfer was canceled. Data downloaded so far is removed.",
+			"The nick is only needed for uploads, when the file is being uploaded to multiple users.",
+		},
+		completer: transferCmdCompleter,
+		handler: func(args []string, as *appState) error {
+			isUpload, fid, uid, err := transferArgs(args, as)
 			if err != nil {
 				return err
 			}
-			newVersion := gc.Version + 1
-			err = as.c.UpgradeGC(gcID, newVersion)
+			if isUpload {
+				err = as.c.CancelUpload(uid, fid)
+			} else {
+				err = as.c.CancelDownload(fid)
+			}
 			if err != nil {
 				return err
 			}
-
-			cw := as.findOrNewGCWindow(gc.ID)
-			cw.newHelpMsg("Upgraded GC to version %d", newVersion)
-			as.repaintIfActive(cw)
+			as.cwHelpMsg("Canceled transfer of file %s", fid)
 			return nil
 		},
-
+	}, {
+		cmd:           "open",
+		usableOffline: true,
+		usage:         "<fid | filename>",
+		descr:         "Open a downloaded file with an external viewer",
+		long: []string{
+			"Opens the given file with the external viewer program configured for its MIME type (see the mimetype config option).",
+			"The file may be specified as the ID of a completed download or as a path to a local file.",
+		},
 		completer: func(args []string, arg string, as *appState) []string {
 			if len(args) == 0 {
-				return gcCompleter(arg, as)
+				return fileCompleter(arg)
 			}
 			return nil
 		},
+		handler: func(args []string, as *appState) error {
+			if len(args) < 1 {
+				return usageError{msg: "file cannot be empty"}
+			}
+
+			var fid clientdb.FileID
+			path := args[0]
+			if err := fid.FromString(args[0]); err == nil {
+				path, err = as.c.HasDownloadedFile(fid)
+				if err != nil {
+					return err
+				}
+				if path == "" {
+					return fmt.Errorf("file %s was not downloaded", fid)
+				}
+			} else if path, err = homedir.Expand(path); err != nil {
+				return err
+			}
+			return as.viewDownloadedFile(path)
+		},
 	}, {
-		cmd:   "addadmin",
-		usage: "<gc> <new admin>",
-		descr: "Add a user as an admin of a GC",
+		cmd:   "sources",
+		usage: "<fid> <nick>...",
+		descr: "Find other users sharing the same file as an in-progress download",
+		long: []string{
+			"Lists the shared files of the given users. Users sharing a file with the same content are added as sources of the download, and chunks are fetched from all sources in parallel.",
+			"Listing the files of each user requires a paid message. Users charging more than the confirmed cost of the download are not added as sources.",
+		},
+		completer: func(args []string, arg string, as *appState) []string {
+			if len(args) == 0 {
+				return nil
+			}
+			return nickCompleter(arg, as)
+		},
 		handler: func(args []string, as *appState) error {
 			if len(args) < 1 {
-				return usageError{msg: "GC cannot be empty"}
+				return usageError{msg: "file ID cannot be empty"}
 			}
 			if len(args) < 2 {
-				return usageError{msg: "New admin cannot be empty"}
+				return usageError{msg: "at least one user must be specified"}
 			}
-
-			gcID, err := as.c.GCIDByName(args[0])
-			if err != nil {
-				return err
+			var fid clientdb.FileID
+			if err := fid.FromString(args[0]); err != nil {
+				return usageError{msg: fmt.Sprintf("invalid file ID: %v", err)}
 			}
-
-			uid, err := as.c.UIDByNick(args[1])
-			if err != nil {
+			uids := make([]clientintf.UserID, 0, len(args)-1)
+			for _, nick := range args[1:] {
+				ru, err := as.c.UserByNick(nick)
+				if err != nil {
+					return err
+				}
+				uids = append(uids, ru.ID())
+			}
+			if err := as.c.FindDownloadSources(fid, uids); err != nil {
 				return err
 			}
-
-			return as.modifyGCAdmins(gcID, uid, clientintf.UserID{})
+			as.cwHelpMsg("Looking for other sources of file %s", fid)
+			return nil
+		},
+	}, {
+		cmd:           "sharedir",
+		usableOffline: true,
+		usage:         "<dir> <cost> [<nick>]",
+		descr:         "Share all files of the given dir as a collection",
+		long: []string{
+			"Every file inside the dir (recursively) is shared, and a signed manifest listing the files with their paths is shared as the collection.",
+			"The cost is the total cost of the collection in DCR, which is split among its files proportionally to their sizes.",
+			"If a nick or user ID is specified, the collection is shared only to that user.",
 		},
-
 		completer: func(args []string, arg string, as *appState) []string {
 			if len(args) == 0 {
-				return gcCompleter(arg, as)
+				return fileCompleter(arg)
 			}
-			if len(args) == 1 {
+			if len(args) == 2 {
 				return nickCompleter(arg, as)
 			}
 			return nil
 		},
-	}, {
-		cmd:   "deladmin",
-		usage: "<gc> <existing admin>",
-		descr: "Removes a user as an admin of a GC",
 		handler: func(args []string, as *appState) error {
 			if len(args) < 1 {
-				return usageError{msg: "GC cannot be empty"}
+				return usageError{msg: "dir cannot be empty"}
 			}
 			if len(args) < 2 {
-				return usageError{msg: "New admin cannot be empty"}
+				return usageError{msg: "cost cannot be empty"}
 			}
 
-			gcID, err := as.c.GCIDByName(args[0])
+			dir, err := homedir.Expand(args[0])
 			if err != nil {
 				return err
 			}
-
-			uid, err := as.c.UIDByNick(args[1])
+			dcrCost, err := strconv.ParseFloat(args[1], 64)
 			if err != nil {
 				return err
 			}
 
-			return as.modifyGCAdmins(gcID, clientintf.UserID{}, uid)
+			var uid *clientintf.UserID
+			with := ""
+			if len(args) > 2 {
+				id, err := as.c.UIDByNick(args[2])
+				if err != nil {
+					return err
+				}
+				uid = &id
+				with = fmt.Sprintf(" with %q", args[2])
+			}
+			atomCost := uint64(dcrCost * 1e8)
+			sc, err := as.c.ShareCollection(dir, uid, "", atomCost, "")
+			if err != nil {
+				return err
+			}
+			as.cwHelpMsg("Shared collection %q (%d files) for %.8f DCR%s. ID: %s",
+				sc.Collection.Name, len(sc.Collection.Files),
+				float64(sc.Collection.Cost)/1e8, with, sc.ID)
+			return nil
+		},
+	}, {
+		cmd:           "collections",
+		usableOffline: true,
+		aliases:       []string{"cols"},
+		descr:         "List local shared collections",
+		handler: func(args []string, as *appState) error {
+			scs, err := as.c.ListLocalSharedCollections()
+			if err != nil {
+				return err
+			}
+			as.cwHelpMsgs(func(pf printf) {
+				pf("")
+				pf("Shared collections")
+				for _, sc := range scs {
+					with := "everyone"
+					if sc.UID != nil {
+						with, _ = as.c.UserNick(*sc.UID)
+					}
+					pf("%s %q (%d files, %.8f DCR) with %s",
+						sc.ID, sc.Collection.Name,
+						len(sc.Collection.Files),
+						float64(sc.Collection.Cost)/1e8, with)
+				}
+			})
+			return nil
+		},
+	}, {
+		cmd:   "browse",
+		usage: "<nick> <collection id>",
+		descr: "List the files of a collection shared by a remote peer",
+		handler: func(args []string, as *appState) error {
+			uid, id, err := collectionArgs(args, as)
+			if err != nil {
+				return err
+			}
+			if err := as.c.ListUserCollection(uid, id); err != nil {
+				return err
+			}
+			as.cwHelpMsg("Requested files of collection %s", id)
+			return nil
 		},
-
 		completer: func(args []string, arg string, as *appState) []string {
 			if len(args) == 0 {
-				return gcCompleter(arg, as)
-			}
-			if len(args) == 1 {
 				return nickCompleter(arg, as)
 			}
 			return nil
 		},
-	},
-}
-
-var ftCommands = []tuicmd{
-	{
-		cmd:           "share",
-		usableOffline: true,
-		usage:         "<filename> <cost> [<nick>]",
-		descr:         "Share the given file for distribution",
+	}, {
+		cmd:     "getcollection",
+		aliases: []string{"getcol"},
+		usage:   "<nick> <collection id>",
+		descr:   "Download all files of a collection shared by a remote peer",
 		long: []string{
-			"Imports the passed file into the local FTP repository. The cost is specified in DCR.",
-			"If a nick or user ID is specified, the file is shared only to that user.",
-			"By default, the passed cost is *added* to the estimated upload cost. Use \"=<amount>\" to directly specify the full cost",
+			"Files are downloaded to a dir named after the collection, keeping the folder structure of the collection.",
+			"Invoices for the files of the collection are automatically paid, without further confirmation.",
+		},
+		handler: func(args []string, as *appState) error {
+			uid, id, err := collectionArgs(args, as)
+			if err != nil {
+				return err
+			}
+			if err := as.c.GetUserCollection(uid, id); err != nil {
+				return err
+			}
+			as.cwHelpMsg("Requested download of collection %s", id)
+			return nil
 		},
 		completer: func(args []string, arg string, as *appState) []string {
 			if len(args) == 0 {
-				return fileCompleter(arg)
-			}
-			if len(args) == 2 {
 				return nickCompleter(arg, as)
 			}
 			return nil
 		},
+	}, {
+		cmd:           "unsharecollection",
+		aliases:       []string{"unsharecol"},
+		usableOffline: true,
+		usage:         "<collection id> [<nick>]",
+		descr:         "Unshare a collection and its files",
 		handler: func(args []string, as *appState) error {
 			if len(args) < 1 {
-				return usageError{msg: "filename cannot be empty"}
+				return usageError{msg: "collection id cannot be empty"}
 			}
-			if len(args) < 2 {
-				return usageError{msg: "cost cannot be empty"}
+			var id clientdb.FileID
+			if err := id.FromString(args[0]); err != nil {
+				return usageError{msg: fmt.Sprintf("invalid collection ID: %v", err)}
+			}
+			var uid *clientintf.UserID
+			if len(args) > 1 {
+				id, err := as.c.UIDByNick(args[1])
+				if err != nil {
+					return err
+				}
+				uid = &id
 			}
-			if len(args[1]) < 1 {
-				return usageError{msg: "cost cannot be the empty string"}
+			if err := as.c.UnshareCollection(id, uid); err != nil {
+				return err
 			}
+			as.cwHelpMsg("Unshared collection %s", id)
+			return nil
+		},
+	},
+}
+
+// collectionArgs parses the arguments of commands that refer to a collection
+// of a remote user: <nick> <collection id>.
+func collectionArgs(args []string, as *appState) (clientintf.UserID, clientdb.FileID, error) {
+	var uid clientintf.UserID
+	var id clientdb.FileID
+	if len(args) < 2 {
+		return uid, id, usageError{msg: "nick and collection id cannot be empty"}
+	}
+	uid, err := as.c.UIDByNick(args[0])
+	if err != nil {
+		return uid, id, err
+	}
+	if err := id.FromString(args[1]); err != nil {
+		return uid, id, usageError{msg: fmt.Sprintf("invalid collection ID: %v", err)}
+	}
+	return uid, id, nil
+}
+
+// shareFileCmd handles the share and sharez commands.
+func shareFileCmd(args []string, as *appState, compress bool) error {
+	if len(args) < 1 {
+		return usageError{msg: "filename cannot be empty"}
+	}
+	if len(args) < 2 {
+		return usageError{msg: "cost cannot be empty"}
+	}
+	if len(args[1]) < 1 {
+		return usageError{msg: "cost cannot be the empty string"}
+	}
+
+	filename, err := homedir.Expand(args[0])
+	if err != nil {
+		return err
+	}
+	dcrCost, dcrUploadCost, err := shareCost(filename, args[1], as)
+	if err != nil {
+		return err
+	}
+
+	var uid *clientintf.UserID
+	with := ""
+	if len(args) > 2 {
+		id, err := as.c.UIDByNick(args[2])
+		if err != nil {
+			return err
+		}
+		uid = &id
+		with = fmt.Sprintf(" with %q", args[2])
+	}
+	atomCost := uint64(dcrCost * 1e8)
+	var sf clientdb.SharedFile
+	var md rpc.FileMetadata
+	if compress {
+		sf, md, err = as.c.ShareCompressedFile(filename, uid, atomCost, "")
+	} else {
+		sf, md, err = as.c.ShareFile(filename, uid, atomCost, false, "")
+	}
+	if err != nil {
+		return err
+	}
+	as.cwHelpMsg("Shared file %q for %.8f DCR (est. cost %.8f DCR)%s. FID: %s",
+		sf.Filename, dcrCost, dcrUploadCost, with,
+		sf.FID)
+	if md.Compression() != "" {
+		as.cwHelpMsg("File compressed with %s from %s to %s",
+			md.Compression(), hbytes(int64(md.OriginalSize())),
+			hbytes(int64(md.Size)))
+	}
+	return nil
+}
+
+// shareCost returns the cost (in DCR) to share the given file with, along with
+// its estimated upload cost. By default, the passed cost is added to the
+// estimated upload cost, unless it is prefixed with "=".
+func shareCost(filename, arg string, as *appState) (float64, float64, error) {
+	stat, err := os.Stat(filename)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Figure out upload cost.
+	feeRate, _ := as.serverPaymentRates()
+	uploadCost, err := clientintf.EstimateUploadCost(stat.Size(), feeRate)
+	if err != nil {
+		return 0, 0, err
+	}
+	dcrUploadCost := float64(uploadCost) / 1e11
+
+	if arg[0] == '=' {
+		// Exact cost specified.
+		dcrCost, err := strconv.ParseFloat(arg[1:], 64)
+		return dcrCost, dcrUploadCost, err
+	}
+
+	// Upload cost + overcharge
+	dcrCost, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	return dcrCost + dcrUploadCost, dcrUploadCost, nil
+}
+
+// updatePostageSettings modifies and saves the postage settings.
+func updatePostageSettings(as *appState, f func(settings *clientdb.PostageSettings)) error {
+	settings, err := as.c.PostageSettings()
+	if err != nil {
+		return err
+	}
+	f(&settings)
+	return as.c.SetPostageSettings(settings)
+}
+
+// parseInvoiceArgs parses the "<nick> <dcr amount> [<memo>]" arguments of the
+// invoice commands.
+func parseInvoiceArgs(args []string, as *appState) (clientintf.UserID, float64, string, error) {
+	if len(args) < 1 {
+		return clientintf.UserID{}, 0, "", usageError{msg: "nick cannot be empty"}
+	}
+	if len(args) < 2 {
+		return clientintf.UserID{}, 0, "", usageError{msg: "amount cannot be empty"}
+	}
+	uid, err := as.c.UIDByNick(args[0])
+	if err != nil {
+		return uid, 0, "", err
+	}
+	dcrAmount, err := strconv.ParseFloat(args[1], 64)
+	if err != nil {
+		return uid, 0, "", usageError{msg: fmt.Sprintf("invalid amount: %v", err)}
+	}
+	return uid, dcrAmount, strings.Join(args[2:], " "), nil
+}
+
+// parsePayLimit parses a payment limit specified in DCR into milliatoms. "none"
+// means no limit.
+func parsePayLimit(arg string) (int64, error) {
+	if arg == "none" {
+		return 0, nil
+	}
+	dcr, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return 0, usageError{msg: fmt.Sprintf("invalid limit %q: %v", arg, err)}
+	}
+	amount, err := dcrutil.NewAmount(dcr)
+	if err != nil {
+		return 0, err
+	}
+	if amount < 0 {
+		return 0, usageError{msg: "limit cannot be negative"}
+	}
+	return int64(amount) * 1000, nil
+}
+
+// formatPayLimit formats a payment limit in milliatoms.
+func formatPayLimit(limit int64) string {
+	if limit == 0 {
+		return "none"
+	}
+	return fmt.Sprintf("%.8f DCR", float64(limit)/1e11)
+}
+
+// payStatsPeriods are the valid periods of payment stats reports.
+var payStatsPeriods = []string{
+	string(clientdb.PayStatsDaily),
+	string(clientdb.PayStatsWeekly),
+	string(clientdb.PayStatsMonthly),
+}
+
+func payStatsPeriodCompleter(arg string) []string {
+	var res []string
+	for _, p := range payStatsPeriods {
+		if strings.HasPrefix(p, arg) {
+			res = append(res, p)
+		}
+	}
+	return res
+}
+
+// parsePayStatsArgs parses the optional period and nick args of the payment
+// stats report commands.
+func parsePayStatsArgs(args []string, as *appState) (clientdb.PayStatsPeriod, *clientintf.UserID, error) {
+	period := clientdb.PayStatsMonthly
+	if len(args) > 0 {
+		period = clientdb.PayStatsPeriod(args[0])
+		if !slices.Contains(payStatsPeriods, args[0]) {
+			return period, nil, usageError{msg: fmt.Sprintf("invalid period %q", args[0])}
+		}
+	}
+	if len(args) < 2 {
+		return period, nil, nil
+	}
+	uid, err := as.c.UIDByNick(args[1])
+	if err != nil {
+		return period, nil, err
+	}
+	return period, &uid, nil
+}
 
-			filename, err := homedir.Expand(args[0])
-			if err != nil {
-				return err
-			}
-			stat, err := os.Stat(filename)
-			if err != nil {
-				return err
-			}
+// parseDownloadRule parses the arguments of the addrule command.
+func parseDownloadRule(args []string, as *appState) (clientdb.DownloadRule, error) {
+	var rule clientdb.DownloadRule
+	if len(args) < 1 {
+		return rule, usageError{msg: "action cannot be empty"}
+	}
+	switch args[0] {
+	case "accept":
+		rule.Action = clientdb.DownloadRuleAccept
+	case "reject":
+		rule.Action = clientdb.DownloadRuleReject
+	default:
+		return rule, usageError{msg: fmt.Sprintf("unknown action %q", args[0])}
+	}
 
-			var dcrCost, dcrUploadCost float64
-			// Figure out upload cost.
-			feeRate, _ := as.serverPaymentRates()
-			size := stat.Size()
-			uploadCost, err := clientintf.EstimateUploadCost(size, feeRate)
-			if err != nil {
-				return err
-			}
-			dcrUploadCost = float64(uploadCost) / 1e11
+	parseDCR := func(v string) (uint64, error) {
+		dcr, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, err
+		}
+		amount, err := dcrutil.NewAmount(dcr)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(amount), nil
+	}
 
-			if args[1][0] == '=' {
-				// Exact cost specified.
-				dcrCost, err = strconv.ParseFloat(args[1][1:], 64)
-				if err != nil {
-					return err
-				}
-			} else {
-				// Upload cost + overcharge
-				dcrCost, err = strconv.ParseFloat(args[1], 64)
-				if err != nil {
-					return err
-				}
-				dcrCost += dcrUploadCost
-			}
+	for _, arg := range args[1:] {
+		k, v, ok := strings.Cut(arg, "=")
+		if !ok || v == "" {
+			return rule, usageError{msg: fmt.Sprintf("invalid condition %q", arg)}
+		}
+		var err error
+		switch k {
+		case "user":
+			var uid clientintf.UserID
+			uid, err = as.c.UIDByNick(v)
+			rule.UID = &uid
+		case "gc":
+			var gcID zkidentity.ShortID
+			gcID, err = as.c.GCIDByName(v)
+			rule.GC = &gcID
+		case "minsize":
+			rule.MinSize, err = strconv.ParseUint(v, 10, 64)
+		case "maxsize":
+			rule.MaxSize, err = strconv.ParseUint(v, 10, 64)
+		case "mincost":
+			rule.MinCost, err = parseDCR(v)
+		case "maxcost":
+			rule.MaxCost, err = parseDCR(v)
+		case "mime":
+			rule.MIMETypes = strings.Split(v, ",")
+		default:
+			return rule, usageError{msg: fmt.Sprintf("unknown condition %q", k)}
+		}
+		if err != nil {
+			return rule, fmt.Errorf("invalid %s: %v", k, err)
+		}
+	}
+	return rule, nil
+}
 
-			var uid *clientintf.UserID
-			with := ""
-			if len(args) > 2 {
-				id, err := as.c.UIDByNick(args[2])
-				if err != nil {
-					return err
-				}
-				uid = &id
-				with = fmt.Sprintf(" with %q", args[2])
-			}
-			atomCost := uint64(dcrCost * 1e8)
-			sf, _, err := as.c.ShareFile(filename, uid, atomCost, false, "")
-			as.cwHelpMsg("Shared file %q for %.8f DCR (est. cost %.8f DCR)%s. FID: %s",
-				sf.Filename, dcrCost, dcrUploadCost, with,
-				sf.FID)
-			return err
+// downloadRuleDescr returns a description of the download rule.
+func downloadRuleDescr(rule *clientdb.DownloadRule, as *appState) string {
+	conds := []string{string(rule.Action)}
+	if rule.UID != nil {
+		nick, _ := as.c.UserNick(*rule.UID)
+		if nick == "" {
+			nick = rule.UID.String()
+		}
+		conds = append(conds, "user="+strescape.Nick(nick))
+	}
+	if rule.GC != nil {
+		gcName, _ := as.c.GetGCAlias(*rule.GC)
+		if gcName == "" {
+			gcName = rule.GC.String()
+		}
+		conds = append(conds, "gc="+strescape.Nick(gcName))
+	}
+	if rule.MinSize > 0 {
+		conds = append(conds, fmt.Sprintf("minsize=%d", rule.MinSize))
+	}
+	if rule.MaxSize > 0 {
+		conds = append(conds, fmt.Sprintf("maxsize=%d", rule.MaxSize))
+	}
+	if rule.MinCost > 0 {
+		conds = append(conds, fmt.Sprintf("mincost=%.8f", float64(rule.MinCost)/1e8))
+	}
+	if rule.MaxCost > 0 {
+		conds = append(conds, fmt.Sprintf("maxcost=%.8f", float64(rule.MaxCost)/1e8))
+	}
+	if len(rule.MIMETypes) > 0 {
+		conds = append(conds, "mime="+strings.Join(rule.MIMETypes, ","))
+	}
+	return strings.Join(conds, " ")
+}
+
+// transferArgs parses the arguments of the commands that control file
+// transfers: <down|up> <fid> [<nick>]. The user is only returned for uploads.
+func transferArgs(args []string, as *appState) (bool, clientdb.FileID, clientintf.UserID, error) {
+	var fid clientdb.FileID
+	var uid clientintf.UserID
+	if len(args) < 2 {
+		return false, fid, uid, usageError{msg: "direction and file ID cannot be empty"}
+	}
+	var isUpload bool
+	switch args[0] {
+	case "down", "download":
+	case "up", "upload":
+		isUpload = true
+	default:
+		return false, fid, uid, usageError{msg: fmt.Sprintf("unknown transfer direction %q", args[0])}
+	}
+	if err := fid.FromString(args[1]); err != nil {
+		return false, fid, uid, usageError{msg: fmt.Sprintf("invalid file ID: %v", err)}
+	}
+	if !isUpload {
+		return false, fid, uid, nil
+	}
+
+	if len(args) > 2 {
+		var err error
+		uid, err = as.c.UIDByNick(args[2])
+		return true, fid, uid, err
+	}
+
+	// Nick not specified. Find the single upload of the file.
+	ups, err := as.c.ListUploads()
+	if err != nil {
+		return false, fid, uid, err
+	}
+	var found int
+	for _, up := range ups {
+		if up.FID == fid {
+			uid = up.UID
+			found++
+		}
+	}
+	switch found {
+	case 0:
+		return false, fid, uid, fmt.Errorf("no uploads of file %s", fid)
+	case 1:
+		return true, fid, uid, nil
+	default:
+		return false, fid, uid, usageError{msg: "file is being uploaded to multiple users; specify the nick"}
+	}
+}
+
+func transferCmdCompleter(args []string, arg string, as *appState) []string {
+	switch len(args) {
+	case 0:
+		return cmdCompleter([]tuicmd{{cmd: "down"}, {cmd: "up"}}, arg, false)
+	case 2:
+		return nickCompleter(arg, as)
+	}
+	return nil
+}
 
+var postCommands = []tuicmd{
+	{
+		cmd:   "new",
+		usage: "[<post>]",
+		descr: "Create a new post",
+		long:  []string{"If called without arguments, opens the create post window. Otherwise, it creates the given post."},
+		handler: func(args []string, as *appState) error {
+			as.sendMsg(showNewPostWindow{})
+			return nil
 		},
 	}, {
-		cmd:           "list",
-		usableOffline: true,
-		aliases:       []string{"ls", "l"},
-		usage:         "<nick> <*|shared|global> [<filename_regex>]",
-		descr:         "List files of a remote peer",
+		cmd:   "paywall",
+		usage: "<dcr price> <summary>",
+		descr: "Create a new paywalled post",
+		long: []string{"Opens the create post window to type the body of a paywalled post.",
+			"The summary is shared for free with all subscribers, while the body is only sent to readers after they pay the specified price."},
 		handler: func(args []string, as *appState) error {
-			if len(args) < 1 {
-				return usageError{msg: "nick cannot be empty"}
-			}
 			if len(args) < 2 {
-				return usageError{msg: "dir cannot be empty"}
+				return usageError{msg: "price and summary cannot be empty"}
 			}
-
-			uid, err := as.c.UIDByNick(args[0])
+			price, err := strconv.ParseFloat(args[0], 64)
 			if err != nil {
-				return err
-			}
-
-			cw := as.findOrNewChatWindow(uid, args[0])
-			var filter string
-			if len(args) > 2 {
-				filter = args[2]
+				return usageError{msg: fmt.Sprintf("invalid price: %v", err)}
 			}
-			go as.listUserContent(cw, args[1], filter)
-			return nil
-		},
-		completer: func(args []string, arg string, as *appState) []string {
-			if len(args) == 0 {
-				return nickCompleter(arg, as)
+			if price <= 0 {
+				return usageError{msg: "price must be positive"}
 			}
+			as.sendMsg(showNewPostWindow{
+				paywallPrice:   price,
+				paywallSummary: strings.Join(args[1:], " "),
+			})
 			return nil
 		},
 	}, {
-		cmd:   "get",
-		usage: "<nick> [<filename> | <FID>]",
-		descr: "Fetch the given file from the remote peer",
-		long: []string{
-			"The file can be referenced either as a filename (in which case the local client must have had a /ft ls issued first) or a full file ID.",
-			"If the file requires payment, the remote peer will send an invoice that will be automatically paid before actually receiving the file's contents",
-		},
+		cmd:     "subscribe",
+		aliases: []string{"sub"},
+		usage:   "<nick>",
+		descr:   "Subscribe to posts by the given nick",
 		handler: func(args []string, as *appState) error {
 			if len(args) < 1 {
 				return usageError{msg: "nick cannot be empty"}
 			}
-			if len(args) < 2 {
-				return usageError{msg: "filename cannot be empty"}
-			}
-
 			uid, err := as.c.UIDByNick(args[0])
 			if err != nil {
 				return err
 			}
-
-			cw := as.findOrNewChatWindow(uid, args[0])
-			go as.getUserContent(cw, args[1])
+			go as.subscribeToPosts(uid)
 			return nil
 		},
 		completer: func(args []string, arg string, as *appState) []string {
@@ -1349,146 +3035,84 @@ var ftCommands = []tuicmd{
 			return nil
 		},
 	}, {
-		cmd:           "estimatecost",
+		cmd:           "subprice",
 		usableOffline: true,
-		aliases:       []string{"estcost"},
-		descr:         "Estimate cost of uploading the specified file",
-		usage:         "<filepath>",
-		completer: func(args []string, arg string, as *appState) []string {
-			return fileCompleter(arg)
-		},
+		usage:         "<dcr|none> [<days>]",
+		descr:         "Set the price for subscribing to the local client's posts",
+		long: []string{"Subscribers must pay the price for each period (by default 30 days) to keep receiving posts. 'none' makes subscriptions free.",
+			"Without arguments, shows the current subscription settings."},
 		handler: func(args []string, as *appState) error {
-			if len(args) < 1 {
-				return usageError{msg: "file path cannot be empty"}
-			}
-
-			fpath := args[0]
-			stat, err := os.Stat(fpath)
-			if err != nil {
-				return err
-			}
-
-			feeRate, _ := as.serverPaymentRates()
-			size := stat.Size()
-			cost, err := clientintf.EstimateUploadCost(size, feeRate)
+			settings, err := as.c.PostsSubscriptionSettings()
 			if err != nil {
 				return err
 			}
-			as.cwHelpMsg("Cost to upload file (%d B): %s", size,
-				dcrutil.Amount(cost/1e3))
-
-			return nil
-		},
-	}, {
-		cmd:           "unshare",
-		usableOffline: true,
-		usage:         "<file> [<user>]",
-		descr:         "Unshare a file",
-		handler: func(args []string, as *appState) error {
 			if len(args) < 1 {
-				return usageError{msg: "file cannot be empty"}
-			}
-			var user *clientintf.UserID
-			if len(args) > 1 {
-				uid, err := as.c.UIDByNick(args[1])
-				if err != nil {
-					return err
-				}
-				user = &uid
+				as.cwHelpMsgs(func(pf printf) {
+					pf("")
+					pf("Posts subscription settings")
+					pf("Price: %s", formatPayLimit(int64(settings.Price)))
+					if settings.Price > 0 {
+						pf("Period: %s", settings.Period)
+					}
+					pf("Auto renew max price (per 30 days): %s",
+						formatPayLimit(int64(settings.AutoRenewMaxPrice)))
+				})
+				return nil
 			}
-
-			files, err := as.c.ListLocalSharedFiles()
+			price, err := parsePayLimit(args[0])
 			if err != nil {
-				return nil
+				return err
 			}
-			var fid zkidentity.ShortID
-			if err := fid.FromString(args[0]); err != nil {
-				// Try to find the named file.
-				for _, f := range files {
-					if f.SF.Filename == args[0] {
-						fid = f.SF.FID
-						break
-					}
-				}
-				if fid.IsEmpty() {
-					return fmt.Errorf("could not find shared file %q",
-						args[0])
+			days := 30
+			if len(args) > 1 {
+				days, err = strconv.Atoi(args[1])
+				if err != nil || days <= 0 {
+					return usageError{msg: fmt.Sprintf("invalid number of days %q", args[1])}
 				}
 			}
-
-			err = as.c.UnshareFile(fid, user)
-			if err != nil {
+			settings.Price = uint64(price)
+			settings.Period = time.Duration(days) * 24 * time.Hour
+			if err := as.c.SetPostsSubscriptionSettings(settings); err != nil {
 				return err
 			}
-
-			as.cwHelpMsg("Unshared file %s", fid)
-			return nil
-		},
-	}, {
-		cmd:   "send",
-		usage: "<user> <filename>",
-		descr: "Send a file to user",
-		completer: func(args []string, arg string, as *appState) []string {
-			if len(args) == 0 {
-				return nickCompleter(arg, as)
-			}
-			if len(args) == 1 {
-				return fileCompleter(arg)
+			if price == 0 {
+				as.cwHelpMsg("Subscriptions to posts are free")
+			} else {
+				as.cwHelpMsg("Subscription price set to %s per %d days",
+					formatPayLimit(price), days)
 			}
 			return nil
 		},
+	}, {
+		cmd:           "autorenew",
+		usableOffline: true,
+		usage:         "<dcr|none>",
+		descr:         "Set the max price automatically paid for subscriptions to posts",
+		long:          []string{"Paid subscriptions to posts of other users are automatically paid and renewed when their price (per 30 days) is at most the specified amount. 'none' disables automatic payments."},
 		handler: func(args []string, as *appState) error {
 			if len(args) < 1 {
-				return usageError{msg: "user cannot be empty"}
-			}
-			if len(args) < 2 {
-				return usageError{msg: "filename cannot be empty"}
+				return usageError{msg: "max price cannot be empty"}
 			}
-
-			uid, err := as.c.UIDByNick(args[0])
+			maxPrice, err := parsePayLimit(args[0])
 			if err != nil {
 				return err
 			}
-			nick, err := as.c.UserNick(uid)
-			if err != nil {
-				nick = args[0]
-			}
-
-			filename := args[1]
-			err = as.c.SendFile(uid, filename)
+			settings, err := as.c.PostsSubscriptionSettings()
 			if err != nil {
 				return err
 			}
-
-			cw := as.findChatWindow(uid)
-			msg := fmt.Sprintf("Sending file %q to user %q",
-				filepath.Base(filename), strescape.Nick(nick))
-			if cw == nil {
-				as.cwHelpMsg(msg)
-			} else {
-				cw.newHelpMsg(msg)
+			settings.AutoRenewMaxPrice = uint64(maxPrice)
+			if err := as.c.SetPostsSubscriptionSettings(settings); err != nil {
+				return err
 			}
-
-			return nil
-		},
-	},
-}
-
-var postCommands = []tuicmd{
-	{
-		cmd:   "new",
-		usage: "[<post>]",
-		descr: "Create a new post",
-		long:  []string{"If called without arguments, opens the create post window. Otherwise, it creates the given post."},
-		handler: func(args []string, as *appState) error {
-			as.sendMsg(showNewPostWindow{})
+			as.cwHelpMsg("Auto renew max price set to %s per 30 days",
+				formatPayLimit(maxPrice))
 			return nil
 		},
 	}, {
-		cmd:     "subscribe",
-		aliases: []string{"sub"},
-		usage:   "<nick>",
-		descr:   "Subscribe to posts by the given nick",
+		cmd:   "paysub",
+		usage: "<nick>",
+		descr: "Pay for the subscription to posts by the given nick",
 		handler: func(args []string, as *appState) error {
 			if len(args) < 1 {
 				return usageError{msg: "nick cannot be empty"}
@@ -1497,7 +3121,7 @@ var postCommands = []tuicmd{
 			if err != nil {
 				return err
 			}
-			go as.subscribeToPosts(uid)
+			go as.payPostsSubscription(uid)
 			return nil
 		},
 		completer: func(args []string, arg string, as *appState) []string {
@@ -1506,6 +3130,35 @@ var postCommands = []tuicmd{
 			}
 			return nil
 		},
+	}, {
+		cmd:           "paidsubs",
+		usableOffline: true,
+		descr:         "List the dates until which subscribers have paid for their subscriptions",
+		handler: func(args []string, as *appState) error {
+			paid, err := as.c.ListPaidPostsSubscribers()
+			if err != nil {
+				return err
+			}
+			as.cwHelpMsgs(func(pf printf) {
+				pf("")
+				if len(paid) == 0 {
+					pf("No paid subscriptions to our posts")
+					return
+				}
+				pf("Paid subscriptions to our posts")
+				now := time.Now()
+				for uid, until := range paid {
+					nick, _ := as.c.UserNick(uid)
+					status := ""
+					if until.Before(now) {
+						status = " (lapsed)"
+					}
+					pf("%s - %s%s", until.Format(ISO8601DateTime),
+						strescape.Nick(nick), status)
+				}
+			})
+			return nil
+		},
 	}, {
 		cmd:     "unsubscribe",
 		aliases: []string{"unsub"},
@@ -1591,6 +3244,46 @@ var postCommands = []tuicmd{
 			}
 			return nil
 		},
+	}, {
+		cmd:   "tip",
+		usage: "<nick> <post id> <dcr amount> [<memo>]",
+		descr: "Send a tip to the author of a post",
+		long:  []string{"The author is shown which of their posts was tipped, along with the optional memo."},
+		handler: func(args []string, as *appState) error {
+			if len(args) < 1 {
+				return usageError{msg: "nick cannot be empty"}
+			}
+			if len(args) < 2 {
+				return usageError{msg: "post id cannot be empty"}
+			}
+			if len(args) < 3 {
+				return usageError{msg: "amount cannot be empty"}
+			}
+
+			uid, err := as.c.UIDByNick(args[0])
+			if err != nil {
+				return err
+			}
+			var pid clientintf.PostID
+			if err := pid.FromString(args[1]); err != nil {
+				return err
+			}
+			dcrAmount, err := strconv.ParseFloat(args[2], 64)
+			if err != nil {
+				return err
+			}
+
+			cw := as.findOrNewChatWindow(uid, args[0])
+			memo := strings.Join(args[3:], " ")
+			go as.payTip(cw, dcrAmount, memo, &pid)
+			return nil
+		},
+		completer: func(args []string, arg string, as *appState) []string {
+			if len(args) == 0 {
+				return nickCompleter(arg, as)
+			}
+			return nil
+		},
 	}, {
 		cmd:   "relay",
 		usage: "<from user> <post id> <to user>",
@@ -1629,6 +3322,162 @@ var postCommands = []tuicmd{
 			}
 			return nil
 		},
+	}, {
+		cmd:           "drafts",
+		usableOffline: true,
+		descr:         "List the saved post and comment drafts",
+		handler: func(args []string, as *appState) error {
+			drafts, err := as.c.ListDrafts()
+			if err != nil {
+				return err
+			}
+
+			as.cwHelpMsgs(func(pf printf) {
+				pf("")
+				if len(drafts) == 0 {
+					pf("No saved drafts")
+					return
+				}
+
+				pf("Drafts")
+				for _, d := range drafts {
+					kind := "post"
+					if d.IsComment() {
+						kind = fmt.Sprintf("comment on %s", d.CommentPID.ShortLogID())
+					}
+					pf("%s - %s - %s (%s) - %s", d.ID.ShortLogID(), kind,
+						d.Updated.Format(ISO8601DateTime),
+						hbytes(int64(d.EstSize)), draftSummary(d.Content))
+				}
+			})
+			return nil
+		},
+	}, {
+		cmd:           "editdraft",
+		usableOffline: true,
+		usage:         "<draft id>",
+		descr:         "Edit a saved post or comment draft",
+		long:          []string{"The draft id may be any unique prefix of the id shown by /post drafts."},
+		handler: func(args []string, as *appState) error {
+			if len(args) < 1 {
+				return usageError{msg: "draft id cannot be empty"}
+			}
+			draft, err := as.findDraft(args[0])
+			if err != nil {
+				return err
+			}
+			if !draft.IsComment() {
+				as.sendMsg(showNewPostWindow{draft: &draft})
+				return nil
+			}
+
+			summ, err := as.findPostSummary(*draft.CommentFrom, *draft.CommentPID)
+			if err != nil {
+				return err
+			}
+			as.sendMsg(showPostWindow{summ: summ, commentDraft: &draft})
+			return nil
+		},
+		completer: func(args []string, arg string, as *appState) []string {
+			if len(args) == 0 {
+				return draftCompleter(arg, as)
+			}
+			return nil
+		},
+	}, {
+		cmd:   "publishdraft",
+		usage: "<draft id>",
+		descr: "Publish a saved post or comment draft",
+		handler: func(args []string, as *appState) error {
+			if len(args) < 1 {
+				return usageError{msg: "draft id cannot be empty"}
+			}
+			draft, err := as.findDraft(args[0])
+			if err != nil {
+				return err
+			}
+			go as.publishDraft(draft)
+			return nil
+		},
+		completer: func(args []string, arg string, as *appState) []string {
+			if len(args) == 0 {
+				return draftCompleter(arg, as)
+			}
+			return nil
+		},
+	}, {
+		cmd:           "rmdraft",
+		usableOffline: true,
+		usage:         "<draft id>",
+		descr:         "Remove a saved post or comment draft",
+		handler: func(args []string, as *appState) error {
+			if len(args) < 1 {
+				return usageError{msg: "draft id cannot be empty"}
+			}
+			draft, err := as.findDraft(args[0])
+			if err != nil {
+				return err
+			}
+			if err := as.c.RemoveDraft(draft.ID); err != nil {
+				return err
+			}
+			as.cwHelpMsg("Removed draft %s", draft.ID.ShortLogID())
+			return nil
+		},
+		completer: func(args []string, arg string, as *appState) []string {
+			if len(args) == 0 {
+				return draftCompleter(arg, as)
+			}
+			return nil
+		},
+	}, {
+		cmd:           "export",
+		usableOffline: true,
+		usage:         "<atom|html> <dest> [<nick>...]",
+		descr:         "Export posts and their comments as an Atom feed or static HTML site",
+		long: []string{
+			"When exporting to atom, dest is the file to write. When exporting to html, dest is the directory where the site is written.",
+			"If nicks are specified, only posts authored by these users (use \"me\" for the local client's posts) are exported. Otherwise, all posts are exported.",
+		},
+		completer: func(args []string, arg string, as *appState) []string {
+			switch {
+			case len(args) == 0:
+				return []string{"atom", "html"}
+			case len(args) == 1:
+				return fileCompleter(arg)
+			default:
+				return nickCompleter(arg, as)
+			}
+		},
+		handler: func(args []string, as *appState) error {
+			if len(args) < 2 {
+				return usageError{msg: "format and destination cannot be empty"}
+			}
+			opts := client.FeedExportOpts{
+				Format: client.FeedExportFormat(args[0]),
+				Dest:   args[1],
+			}
+			for _, nick := range args[2:] {
+				if nick == "me" {
+					opts.Authors = append(opts.Authors, as.c.PublicID())
+					continue
+				}
+				uid, err := as.c.UIDByNick(nick)
+				if err != nil {
+					return err
+				}
+				opts.Authors = append(opts.Authors, uid)
+			}
+			go func() {
+				nbPosts, err := as.c.ExportFeed(opts)
+				if err != nil {
+					as.cwHelpMsg("Unable to export posts: %v", err)
+					return
+				}
+				as.cwHelpMsg("Exported %d posts to %s", nbPosts, opts.Dest)
+			}()
+			return nil
+		},
 	},
 }
 
@@ -2432,6 +4281,132 @@ var commands = []tuicmd{
 			return nil
 		},
 		handler: subcmdNeededHandler,
+	}, {
+		cmd:           "paylimits",
+		usableOffline: true,
+		usage:         "[subcmd]",
+		descr:         "Show or set the spending limits of outbound payments",
+		long: []string{
+			"Without a subcommand, shows the configured limits and the amounts spent today. Limits are specified in DCR and include payment fees. 'none' removes a limit.",
+		},
+		sub: payLimitsCommands,
+		completer: func(args []string, arg string, as *appState) []string {
+			if len(args) == 0 {
+				return cmdCompleter(payLimitsCommands, arg, false)
+			}
+			return nil
+		},
+		handler: func(args []string, as *appState) error {
+			if len(args) > 0 {
+				return subcmdNeededHandler(args, as)
+			}
+			limits, err := as.c.PaymentLimits()
+			if err != nil {
+				return err
+			}
+			budget, err := as.c.PaymentBudget()
+			if err != nil {
+				return err
+			}
+			as.cwHelpMsgs(func(pf printf) {
+				pf("")
+				pf("Payment limits for %s", budget.Day)
+				pf("Daily limit: %s (spent %.8f DCR)",
+					formatPayLimit(limits.DailyLimit),
+					float64(budget.Spent)/1e11)
+				pf("Approval threshold: %s",
+					formatPayLimit(limits.ApprovalThreshold))
+				for _, l := range limits.Users {
+					nick, _ := as.c.UserNick(l.ID)
+					pf("User %s: %s (spent %.8f DCR)",
+						strescape.Nick(nick),
+						formatPayLimit(l.DailyLimit),
+						float64(budget.UserSpent(l.ID))/1e11)
+				}
+				for _, l := range limits.GCs {
+					name, _ := as.c.GetGCAlias(l.ID)
+					if name == "" {
+						name = l.ID.String()
+					}
+					pf("GC %s: %s (spent %.8f DCR)", name,
+						formatPayLimit(l.DailyLimit),
+						float64(budget.GCSpent(l.ID))/1e11)
+				}
+			})
+			return nil
+		},
+	}, {
+		cmd:   "invoice",
+		usage: "[subcmd]",
+		descr: "Fetch, send, pay and decline invoices of other users",
+		sub:   invoiceCommands,
+		completer: func(args []string, arg string, as *appState) []string {
+			if len(args) == 0 {
+				return cmdCompleter(invoiceCommands, arg, false)
+			}
+			return nil
+		},
+		handler: subcmdNeededHandler,
+	}, {
+		cmd:   "credit",
+		usage: "[subcmd]",
+		descr: "Show or buy credit prepaid to the server",
+		long: []string{
+			"When the server accepts prepaid credit, pushing messages and subscribing to RVs are paid from the credit instead of paying individual invoices. Without a subcommand, shows the credit balance.",
+		},
+		sub: creditCommands,
+		completer: func(args []string, arg string, as *appState) []string {
+			if len(args) == 0 {
+				return cmdCompleter(creditCommands, arg, false)
+			}
+			return nil
+		},
+		handler: func(args []string, as *appState) error {
+			if len(args) > 0 {
+				return subcmdNeededHandler(args, as)
+			}
+			go as.showServerCredit()
+			return nil
+		},
+	}, {
+		cmd:           "postage",
+		usableOffline: true,
+		usage:         "[subcmd]",
+		descr:         "Show or set the postage required for messages of other users",
+		long: []string{
+			"Messages (PMs and GC messages) from users that have not paid their postage are held until the postage is paid. Without a subcommand, shows the postage settings. Postage is specified in DCR per message. 'none' means no postage.",
+		},
+		sub: postageCommands,
+		completer: func(args []string, arg string, as *appState) []string {
+			if len(args) == 0 {
+				return cmdCompleter(postageCommands, arg, false)
+			}
+			return nil
+		},
+		handler: func(args []string, as *appState) error {
+			if len(args) > 0 {
+				return subcmdNeededHandler(args, as)
+			}
+			settings, err := as.c.PostageSettings()
+			if err != nil {
+				return err
+			}
+			as.cwHelpMsgs(func(pf printf) {
+				pf("")
+				pf("Postage settings")
+				pf("Default postage: %s", formatPayLimit(int64(settings.Default)))
+				for sid, postage := range settings.Users {
+					var uid clientintf.UserID
+					if err := uid.FromString(sid); err != nil {
+						continue
+					}
+					nick, _ := as.c.UserNick(uid)
+					pf("User %s: %s", strescape.Nick(nick),
+						formatPayLimit(int64(postage)))
+				}
+			})
+			return nil
+		},
 	}, {
 		cmd:           "query",
 		usableOffline: true,
@@ -2687,9 +4662,10 @@ var commands = []tuicmd{
 		handler: subcmdNeededHandler,
 	}, {
 		cmd:   "paytip",
-		usage: "<nick or id> <dcr amount>",
+		usage: "<nick or id> <dcr amount> [<memo>]",
 		descr: "Send a tip with the given dcr amount to the user",
 		long: []string{
+			"The optional memo is signed and shown to the user once the tip is received.",
 			"Note: the tip is sent via LN, so the other peer only receives the tip if it is also online an connected to LN.",
 		},
 		handler: func(args []string, as *appState) error {
@@ -2710,7 +4686,8 @@ var commands = []tuicmd{
 				return err
 			}
 
-			go as.payTip(cw, dcrAmount)
+			memo := strings.Join(args[2:], " ")
+			go as.payTip(cw, dcrAmount, memo, nil)
 			return nil
 		},
 		completer: func(args []string, arg string, as *appState) []string {
@@ -2939,6 +4916,122 @@ var commands = []tuicmd{
 			}
 			return nil
 		},
+	}, {
+		cmd:           "payreport",
+		usableOffline: true,
+		usage:         "[<daily|weekly|monthly>] [<nick>]",
+		descr:         "Show payment stats per period, user and category",
+		long: []string{
+			"Shows the last 30 days, 12 weeks or 12 months of payments, depending on the period (monthly by default). Amounts are also valued in USD at the current exchange rate.",
+		},
+		completer: func(args []string, arg string, as *appState) []string {
+			switch len(args) {
+			case 0:
+				return payStatsPeriodCompleter(arg)
+			case 1:
+				return nickCompleter(arg, as)
+			}
+			return nil
+		},
+		handler: func(args []string, as *appState) error {
+			period, uid, err := parsePayStatsArgs(args, as)
+			if err != nil {
+				return err
+			}
+
+			now := time.Now()
+			var since time.Time
+			switch period {
+			case clientdb.PayStatsDaily:
+				since = now.AddDate(0, 0, -30)
+			case clientdb.PayStatsWeekly:
+				since = now.AddDate(0, 0, -12*7)
+			default:
+				since = now.AddDate(0, -12, 0)
+			}
+			report, err := as.c.PayStatsReport(period, since, uid)
+			if err != nil {
+				return err
+			}
+
+			eRate := as.exchangeRate()
+			as.cwHelpMsgs(func(pf printf) {
+				pf("")
+				pf("Payment Report (%s)", period)
+				pf("Start              Sent           Recv           Fees (DCR)")
+				var totalSent, totalRecv, totalFees int64
+				for _, b := range report {
+					nick, _ := as.c.UserNick(b.UID)
+					pf("%s %12.8f   %12.8f   %12.8f - %s %s",
+						b.Start.Format("2006-01-02"),
+						float64(b.Sent)/1e11,
+						float64(b.Received)/1e11,
+						float64(b.PayFee)/1e11,
+						ltjustify(strescape.Nick(nick), 12),
+						b.Category)
+					totalSent += b.Sent
+					totalRecv += b.Received
+					totalFees += b.PayFee
+				}
+				pf("%10s %12.8f   %12.8f   %12.8f - Totals", "",
+					float64(totalSent)/1e11,
+					float64(totalRecv)/1e11,
+					float64(totalFees)/1e11)
+				if eRate.DCRPrice > 0 {
+					pf("%10s %12.2f   %12.2f   %12.2f - Totals (USD)", "",
+						float64(totalSent)/1e11*eRate.DCRPrice,
+						float64(totalRecv)/1e11*eRate.DCRPrice,
+						float64(totalFees)/1e11*eRate.DCRPrice)
+				}
+			})
+			return nil
+		},
+	}, {
+		cmd:           "exportpaystats",
+		usableOffline: true,
+		usage:         "<csv|json> <dest> [<daily|weekly|monthly>] [<nick>]",
+		descr:         "Export payment stats per period, user and category",
+		long: []string{
+			"Exports all recorded payments, aggregated per period (monthly by default), user and category. When the exchange rate is known, amounts are also valued in USD at the current rate.",
+		},
+		completer: func(args []string, arg string, as *appState) []string {
+			switch len(args) {
+			case 0:
+				return []string{"csv", "json"}
+			case 1:
+				return fileCompleter(arg)
+			case 2:
+				return payStatsPeriodCompleter(arg)
+			case 3:
+				return nickCompleter(arg, as)
+			}
+			return nil
+		},
+		handler: func(args []string, as *appState) error {
+			if len(args) < 2 {
+				return usageError{msg: "format and destination cannot be empty"}
+			}
+			period, uid, err := parsePayStatsArgs(args[2:], as)
+			if err != nil {
+				return err
+			}
+			opts := client.PayStatsExportOpts{
+				Format:   client.PayStatsExportFormat(args[0]),
+				Dest:     args[1],
+				Period:   period,
+				UID:      uid,
+				DCRPrice: as.exchangeRate().DCRPrice,
+			}
+			go func() {
+				nb, err := as.c.ExportPayStats(opts)
+				if err != nil {
+					as.cwHelpMsg("Unable to export payment stats: %v", err)
+					return
+				}
+				as.cwHelpMsg("Exported %d payment stats entries to %s", nb, opts.Dest)
+			}()
+			return nil
+		},
 	}, {
 		cmd:           "rmpaystats",
 		usableOffline: true,
@@ -75,6 +75,8 @@ type config struct {
 	CPUProfileHz   int
 	LogPings       bool
 
+	SendPostsToNewSubs bool
+
 	ProxyAddr    string
 	ProxyUser    string
 	ProxyPass    string
@@ -237,6 +239,7 @@ func loadConfig() (*config, error) {
 	flagWalletType := fs.String("wallettype", defaultWalletType, "Wallet type to use")
 	flagNetwork := fs.String("network", "mainnet", "Network to connect")
 	flagLogPings := fs.Bool("logpings", false, "Whether to log pings")
+	flagSendPostsToNewSubs := fs.Bool("sendpoststonewsubs", false, "Whether to send posts to new subscribers when they are updated")
 	flagMinWalletBal := fs.Float64("minimumwalletbalance", 1.0, "Minimum wallet balance before warn")
 	flagMinRecvBal := fs.Float64("minimumrecvbalance", 0.01, "Minimum receive balance before warn")
 	flagMinSendBal := fs.Float64("minimumsendbalance", 0.01, "Minimum send balance before warn")
@@ -367,6 +370,7 @@ func loadConfig() (*config, error) {
 		CPUProfile:         *flagCPUProfile,
 		CPUProfileHz:       *flagCPUProfileHz,
 		LogPings:           *flagLogPings,
+		SendPostsToNewSubs: *flagSendPostsToNewSubs,
 		ProxyAddr:          *flagProxyAddr,
 		ProxyUser:          *flagProxyUser,
 		ProxyPass:          *flagProxyPass,
@@ -2,10 +2,8 @@ package main
 
 import (
 	"fmt"
-	"mime"
 	"net/url"
 	"os"
-	"path/filepath"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -104,7 +102,7 @@ func (ew *embedWidget) tryEmbed() error {
 			return fmt.Errorf("file too big to embed")
 		}
 
-		args.typ = mime.TypeByExtension(filepath.Ext(filename))
+		args.typ = clientdb.FileMIMEType(filename)
 		id = chainhash.HashH(data).String()[:8]
 		pseudoData := fmt.Sprintf("[content %s]", id)
 		args.data = []byte(pseudoData)
@@ -20,6 +20,12 @@ type feedWindow struct {
 	posts []clientdb.PostSummary
 	idx   int
 
+	// search is the input used to edit the feed filter (stored in
+	// as.feedFilter) while searching is true.
+	search    *textInputHelper
+	searching bool
+	searchErr string
+
 	viewport viewport.Model
 }
 
@@ -83,7 +89,16 @@ func (fw *feedWindow) renderPostSumm(post clientdb.PostSummary,
 }
 
 func (fw *feedWindow) listPosts() {
-	fw.posts = fw.as.allPosts()
+	fw.searchErr = ""
+	if filter := fw.as.feedFilter; filter != "" {
+		posts, err := fw.as.searchPosts(filter)
+		if err != nil {
+			fw.searchErr = err.Error()
+		}
+		fw.posts = posts
+	} else {
+		fw.posts = fw.as.allPosts()
+	}
 	sort.Slice(fw.posts, func(i, j int) bool {
 		return fw.posts[i].Date.Sub(fw.posts[j].Date) < 0
 	})
@@ -140,6 +155,34 @@ func (fw feedWindow) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case tea.KeyMsg:
 		switch {
+		case fw.searching && msg.Type == tea.KeyEsc:
+			// Cancel editing the filter.
+			fw.searching = false
+			fw.search.Blur()
+
+		case fw.searching && msg.Type == tea.KeyEnter:
+			// Apply the filter.
+			fw.searching = false
+			fw.search.Blur()
+			fw.as.feedFilter = strings.TrimSpace(fw.search.Value())
+			fw.listPosts()
+			fw.renderPosts()
+
+		case fw.searching:
+			_, cmd = fw.search.Update(msg)
+
+		case msg.String() == "/":
+			fw.searching = true
+			fw.search.SetValue(fw.as.feedFilter)
+			fw.search.CursorEnd()
+			cmd = fw.search.Focus()
+
+		case msg.Type == tea.KeyEsc && fw.as.feedFilter != "":
+			// Clear the filter.
+			fw.as.feedFilter = ""
+			fw.listPosts()
+			fw.renderPosts()
+
 		case msg.Type == tea.KeyEsc:
 			// Return to main window
 			fw.as.markWindowSeen(activeCWFeed)
@@ -191,20 +234,35 @@ func (fw feedWindow) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (fw feedWindow) headerView() string {
-	msg := " Posts Feed - Press ESC to return"
+	msg := " Posts Feed - Press ESC to return, / to search"
 	headerMsg := fw.as.styles.header.Render(msg)
 	spaces := fw.as.styles.header.Render(strings.Repeat(" ",
 		max(0, fw.as.winW-lipgloss.Width(headerMsg))))
 	return headerMsg + spaces
 }
 
+func (fw feedWindow) searchView() string {
+	switch {
+	case fw.searching:
+		return fw.search.View()
+	case fw.searchErr != "":
+		return fw.as.styles.err.Render("Search error: " + fw.searchErr)
+	case fw.as.feedFilter != "":
+		return fw.as.styles.help.Render(fmt.Sprintf("Filter: %s (%d posts) - Press ESC to clear",
+			fw.as.feedFilter, len(fw.posts)))
+	default:
+		return ""
+	}
+}
+
 func (fw feedWindow) footerView() string {
 	return fw.as.footerView("")
 }
 
 func (fw feedWindow) View() string {
-	return fmt.Sprintf("%s\n\n%s\n%s",
+	return fmt.Sprintf("%s\n%s\n%s\n%s",
 		fw.headerView(),
+		fw.searchView(),
 		fw.viewport.View(),
 		fw.footerView(),
 	)
@@ -213,7 +271,11 @@ func (fw feedWindow) View() string {
 func newFeedWindow(as *appState, feedActiveIdx, yOffsetHint int) (feedWindow, tea.Cmd) {
 	as.loadPosts()
 	as.markWindowSeen(activeCWFeed)
-	fw := feedWindow{as: as}
+	fw := feedWindow{
+		as: as,
+		search: newTextInputHelper(as.styles,
+			tihWithPrompt("Search: ")),
+	}
 	fw.listPosts()
 	if feedActiveIdx > -1 && feedActiveIdx < len(fw.posts) {
 		fw.idx = feedActiveIdx
@@ -2,6 +2,8 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"os/exec"
 	"strconv"
 	"strings"
 	"time"
@@ -11,6 +13,7 @@ import (
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/decred/dcrd/dcrutil/v4"
 )
 
 type mainWindowState struct {
@@ -33,6 +36,10 @@ type mainWindowState struct {
 
 	header string
 
+	// costEstimate is the estimated cost of sending the current input in
+	// the active chat window.
+	costEstimate string
+
 	debug string
 }
 
@@ -94,6 +101,27 @@ func (mws *mainWindowState) addEmbedCB(id string, data []byte, embedStr string)
 	return nil
 }
 
+// updateCostEstimate updates the estimated cost of sending the current input
+// in the active chat window.
+func (mws *mainWindowState) updateCostEstimate() {
+	mws.costEstimate = ""
+	text := mws.textArea.Value()
+	if text == "" || text[0] == leader {
+		return
+	}
+	cw := mws.as.activeChatWindow()
+	if cw == nil {
+		return
+	}
+
+	cost, err := mws.as.estimateMsgCost(cw, text)
+	if err != nil {
+		return
+	}
+	mws.costEstimate = mws.as.styles.footer.Render(fmt.Sprintf("(est. cost %s) ",
+		dcrutil.Amount(cost/1e3)))
+}
+
 func (mws *mainWindowState) onTextInputAction() {
 	text := mws.textArea.Value()
 	if text == "" {
@@ -121,6 +149,7 @@ func (mws *mainWindowState) onTextInputAction() {
 
 	// Clear line editor
 	mws.textArea.Reset()
+	mws.costEstimate = ""
 	mws.recalcViewportSize()
 }
 
@@ -305,6 +334,7 @@ func (mws mainWindowState) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			if newValue != mws.textArea.Value() {
 				mws.textArea.SetValue(newValue)
+				mws.updateCostEstimate()
 				mws.recalcViewportSize()
 
 				// Moving down, go to first line of multiline
@@ -392,6 +422,7 @@ func (mws mainWindowState) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Store working cmd if the text input changed in
 			// response to this msg.
 			if prevVal != newVal {
+				mws.updateCostEstimate()
 				mws.recalcViewportSize()
 				mws.as.workingCmd = newVal
 				mws.as.cmdHistoryIdx = len(mws.as.cmdHistory)
@@ -410,6 +441,7 @@ func (mws mainWindowState) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		mws.updateViewportContent()
 
 	case msgActiveWindowChanged:
+		mws.updateCostEstimate()
 		cw := mws.as.activeChatWindow()
 		if cw != nil {
 			if cw.unreadCount() < mws.as.winH {
@@ -447,12 +479,25 @@ func (mws mainWindowState) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case showNewPostWindow:
 		mws.as.workingCmd = ""
-		return newNewPostWindow(mws.as)
+		return newNewPostWindow(mws.as, msg)
 
 	case showFeedWindow:
 		mws.as.workingCmd = ""
 		return newFeedWindow(mws.as, -1, -1)
 
+	case showTransfersWindow:
+		mws.as.workingCmd = ""
+		return newTransfersWindow(mws.as)
+
+	case showPostWindow:
+		mws.as.workingCmd = ""
+		mws.as.activatePost(&msg.summ)
+		pw, cmd := newPostWin(mws.as, -1, -1)
+		if msg.commentDraft != nil {
+			cmd = batchCmds([]tea.Cmd{cmd, pw.editCommentDraft(*msg.commentDraft)})
+		}
+		return pw, cmd
+
 	case msgLNRequestRecv:
 		mws.as.workingCmd = ""
 		return newLNRequestRecvWindow(mws.as, false)
@@ -488,6 +533,21 @@ func (mws mainWindowState) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case msgDownloadCompleted:
 		mws.updateViewportContent()
 
+	case msgViewFile:
+		c := exec.Command(msg.prog, msg.path)
+		return mws, tea.ExecProcess(c, func(err error) tea.Msg {
+			if msg.removeAfter {
+				os.Remove(msg.path)
+			}
+			return externalViewer{err: err}
+		})
+
+	case externalViewer:
+		if msg.err != nil {
+			mws.as.diagMsg("External viewer failed: %v", msg.err)
+			mws.updateViewportContent()
+		}
+
 	default:
 		// Handle other messages.
 		mws.textArea, cmd = mws.textArea.Update(msg)
@@ -506,6 +566,8 @@ func (mws mainWindowState) footerView() string {
 		esc = mws.debug
 	} else if mws.escMode {
 		esc = "ESC"
+	} else if mws.costEstimate != "" {
+		esc = mws.costEstimate + esc
 	}
 
 	return mws.as.footerView(esc)
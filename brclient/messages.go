@@ -69,6 +69,14 @@ type msgProcessEsc struct{}
 
 type msgDownloadCompleted clientdb.FileID
 
+// msgViewFile is sent to open a local file with an external viewer program.
+// If removeAfter is set, the file is removed once the viewer exits.
+type msgViewFile struct {
+	prog        string
+	path        string
+	removeAfter bool
+}
+
 type msgActiveWindowChanged struct{}
 
 func paste() tea.Msg {
@@ -98,18 +106,37 @@ type requestShutdown struct{}
 // UI update.
 type currentTimeChanged struct{}
 
-// showNewPostWindow shows the create post window.
-type showNewPostWindow struct{}
+// showNewPostWindow shows the create post window. If paywallPrice is set,
+// the post is created as a paywalled post where the contents of the window are
+// the paid body. If draft is set, the window edits the given draft.
+type showNewPostWindow struct {
+	paywallPrice   float64
+	paywallSummary string
+	draft          *clientdb.PostDraft
+}
+
+// showPostWindow shows the post window for the given post. If commentDraft is
+// set, the window starts editing the given comment draft.
+type showPostWindow struct {
+	summ         clientdb.PostSummary
+	commentDraft *clientdb.PostDraft
+}
 
 // showFeedWindow shows the feed window.
 type showFeedWindow struct{}
 
+// showTransfersWindow shows the window with in-progress file transfers.
+type showTransfersWindow struct{}
+
 // feedUpdated when the feed of posts should be updated.
 type feedUpdated struct{}
 
 // sentPostComment is sent when a new local comment to a post is sent.
 type sentPostComment struct{}
 
+// postPaywallUnlocked is sent when the body of a paywalled post is received.
+type postPaywallUnlocked struct{ pid clientintf.PostID }
+
 // kxCompleted is sent when a KX process has completed with a remote peer.
 type kxCompleted struct{ uid clientintf.UserID }
 
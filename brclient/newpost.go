@@ -6,8 +6,10 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/companyzero/bisonrelay/client/clientdb"
 	"github.com/companyzero/bisonrelay/client/clientintf"
 	"github.com/companyzero/bisonrelay/rpc"
+	"github.com/decred/dcrd/chaincfg/chainhash"
 )
 
 type newPostWindow struct {
@@ -24,6 +26,12 @@ type newPostWindow struct {
 	ew           *embedWidget
 
 	estSize uint64
+
+	paywallPrice   float64
+	paywallSummary string
+
+	// draft is set when editing a saved draft.
+	draft *clientdb.PostDraft
 }
 
 func (pw *newPostWindow) updateTextAreaSize() {
@@ -46,9 +54,9 @@ func (pw *newPostWindow) addEmbedCB(id string, data []byte, embedStr string) err
 	return nil
 }
 
-func (pw *newPostWindow) createPost(post string) {
-	// Replace pseudo-data with data.
-	fullPost := replaceEmbeds(post, func(args embeddedArgs) string {
+// fullPost replaces the pseudo-data of embeds with the actual data.
+func (pw *newPostWindow) fullPost(post string) string {
+	return replaceEmbeds(post, func(args embeddedArgs) string {
 		data := string(args.data)
 		if strings.HasPrefix(data, "[content ") {
 			id := data[9 : len(args.data)-1]
@@ -58,6 +66,46 @@ func (pw *newPostWindow) createPost(post string) {
 		return args.String()
 
 	})
+}
+
+// loadDraft loads the content of the draft into the window, replacing the
+// embedded data with pseudo-data.
+func (pw *newPostWindow) loadDraft(draft *clientdb.PostDraft) {
+	post := replaceEmbeds(draft.Content, func(args embeddedArgs) string {
+		if len(args.data) > 0 {
+			id := chainhash.HashH(args.data).String()[:8]
+			pw.embedContent[id] = args.data
+			args.data = []byte(fmt.Sprintf("[content %s]", id))
+		}
+		return args.String()
+	})
+	pw.textArea.SetValue(post)
+	pw.estSize = draft.EstSize
+	pw.draft = draft
+}
+
+// saveDraft saves the current post as a draft.
+func (pw *newPostWindow) saveDraft(post string) {
+	var draft clientdb.PostDraft
+	if pw.draft != nil {
+		draft = *pw.draft
+	}
+	draft.Content = pw.fullPost(post)
+	go pw.as.saveDraft(draft)
+}
+
+func (pw *newPostWindow) createPost(post string) {
+	fullPost := pw.fullPost(post)
+	if pw.draft != nil {
+		draft := *pw.draft
+		draft.Content = fullPost
+		go pw.as.publishDraft(draft)
+		return
+	}
+	if pw.paywallPrice > 0 {
+		go pw.as.createPaywalledPost(pw.paywallSummary, fullPost, pw.paywallPrice)
+		return
+	}
 	go pw.as.createPost(fullPost)
 }
 
@@ -104,6 +152,18 @@ func (pw newPostWindow) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Cancel post.
 			return newMainWindowState(pw.as)
 
+		case msg.Type == tea.KeyCtrlS:
+			if pw.paywallPrice > 0 {
+				pw.errMsg = "Paywalled posts cannot be saved as drafts"
+				return pw, nil
+			}
+			post := pw.textArea.Value()
+			if post != "" {
+				pw.saveDraft(post)
+			}
+
+			return newMainWindowState(pw.as)
+
 		case pw.focusIdx == 1 && msg.Type == tea.KeyEnter:
 			post := pw.textArea.Value()
 			if post != "" {
@@ -142,7 +202,14 @@ func (pw newPostWindow) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (pw *newPostWindow) headerView() string {
-	msg := " Create Post - F2 to Embed/Link File"
+	msg := " Create Post - F2 to Embed/Link File, Ctrl+S to Save Draft"
+	if pw.draft != nil {
+		msg = fmt.Sprintf(" Edit Draft %s - F2 to Embed/Link File, Ctrl+S to Save Draft",
+			pw.draft.ID.ShortLogID())
+	} else if pw.paywallPrice > 0 {
+		msg = fmt.Sprintf(" Create Paywalled Post (%.8f DCR) - F2 to Embed/Link File",
+			pw.paywallPrice)
+	}
 	headerMsg := pw.as.styles.header.Render(msg)
 	spaces := pw.as.styles.header.Render(strings.Repeat(" ",
 		max(0, pw.as.winW-lipgloss.Width(headerMsg))))
@@ -189,11 +256,14 @@ func (pw newPostWindow) View() string {
 	return b.String()
 }
 
-func newNewPostWindow(as *appState) (newPostWindow, tea.Cmd) {
+func newNewPostWindow(as *appState, msg showNewPostWindow) (newPostWindow, tea.Cmd) {
 	var cmds []tea.Cmd
 
 	t := newTextAreaModel(as.styles)
 	t.Placeholder = "Post"
+	if msg.paywallPrice > 0 {
+		t.Placeholder = "Paywalled body"
+	}
 	t.CharLimit = 0
 	t.FocusedStyle.Prompt = as.styles.focused
 	t.FocusedStyle.Text = as.styles.focused
@@ -205,6 +275,12 @@ func newNewPostWindow(as *appState) (newPostWindow, tea.Cmd) {
 		as:           as,
 		textArea:     t,
 		embedContent: make(map[string][]byte),
+
+		paywallPrice:   msg.paywallPrice,
+		paywallSummary: msg.paywallSummary,
+	}
+	if msg.draft != nil {
+		nw.loadDraft(msg.draft)
 	}
 
 	nw.ew = newEmbedWidget(as, nw.addEmbedCB)
@@ -50,6 +50,9 @@ type postWindow struct {
 	author     string
 	relayedBy  string
 
+	paywallPrice uint64
+	paywallBody  string
+
 	feedActiveIdx   int
 	feedYOffsetHint int
 
@@ -68,6 +71,9 @@ type postWindow struct {
 	relaying          bool
 	cmdErr            string
 
+	// commentDraft is set when the comment being edited is a saved draft.
+	commentDraft *clientdb.PostDraft
+
 	viewport viewport.Model
 	textArea *textAreaModel
 }
@@ -148,6 +154,12 @@ func (pw *postWindow) updatePost() {
 
 	pw.author, pw.relayedBy = pw.as.postAuthorRelayer(pw.summ)
 
+	pw.paywallPrice = clientintf.PaywalledPostPrice(&pw.post)
+	pw.paywallBody = ""
+	if pw.paywallPrice > 0 {
+		pw.paywallBody, _ = pw.as.c.PaywalledPostBody(pw.summ.From, pw.summ.ID)
+	}
+
 	_, err := pw.as.c.GetKXSearch(pw.summ.AuthorID)
 	if err == nil {
 		pw.kxSearchingAuthor = true
@@ -326,7 +338,21 @@ func (pw *postWindow) renderPost() {
 	//write(styles.help.Render(pf(" - %d ♥", pw.hearts)))
 	write("\n\n")
 
+	if pw.paywallPrice > 0 {
+		dcrPrice := dcrutil.Amount(int64(pw.paywallPrice / 1000)).ToCoin()
+		if pw.paywallBody == "" {
+			write(styles.help.Render(pf("Paywalled post - (S+P) to pay %.8f DCR "+
+				"and unlock the full content", dcrPrice)))
+		} else {
+			write(styles.help.Render(pf("Unlocked paywalled post (%.8f DCR)", dcrPrice)))
+		}
+		write("\n\n")
+	}
+
 	content := strings.TrimSpace(attr[rpc.RMPMain])
+	if pw.paywallBody != "" {
+		content += "\n\n" + strings.TrimSpace(pw.paywallBody)
+	}
 	if content == "" {
 		content = " (empty content) "
 	}
@@ -387,7 +413,7 @@ func (pw *postWindow) renderPost() {
 	content = wrap.String(wordwrap.String(content, lineLimit), lineLimit)
 	write(content)
 	write("\n\n")
-	write(styles.help.Render("═════ Comments ══════════ (R)eply, (C)omment, (S+I) Req. Invite "))
+	write(styles.help.Render("═════ Comments ══════════ (R)eply, (C)omment, (Ctrl+S) Save Draft, (S+I) Req. Invite "))
 	write(styles.help.Render(strings.Repeat("═", pw.as.winW-15)))
 	write("\n\n")
 	pw.startCommentsLine = lineCount
@@ -562,6 +588,69 @@ func (pw *postWindow) kxSearchAuthor() {
 	pw.renderPost()
 }
 
+// findCommentDraft returns the most recent saved comment draft to this post
+// with the given parent.
+func (pw *postWindow) findCommentDraft(parent *clientintf.ID) *clientdb.PostDraft {
+	drafts, err := pw.as.c.ListDrafts()
+	if err != nil {
+		pw.as.diagMsg("Unable to list drafts: %v", err)
+		return nil
+	}
+	for i := len(drafts) - 1; i >= 0; i-- {
+		d := &drafts[i]
+		if !d.IsComment() || *d.CommentFrom != pw.summ.From ||
+			*d.CommentPID != pw.summ.ID {
+			continue
+		}
+		if (parent == nil) != (d.CommentParent == nil) ||
+			(parent != nil && *parent != *d.CommentParent) {
+			continue
+		}
+		return d
+	}
+	return nil
+}
+
+// editCommentDraft starts editing the given comment draft.
+func (pw *postWindow) editCommentDraft(draft clientdb.PostDraft) tea.Cmd {
+	pw.debug = ""
+	pw.cmdErr = ""
+	pw.commenting = true
+	pw.replying = draft.CommentParent != nil
+	pw.textArea.Placeholder = "Type top-level comment"
+	if pw.replying {
+		pw.textArea.Placeholder = "Type reply to comment"
+		for i, cmt := range pw.comments {
+			if cmt.id == *draft.CommentParent {
+				pw.selComment = i
+				break
+			}
+		}
+	}
+	pw.commentDraft = &draft
+	pw.textArea.SetValue(draft.Content)
+	cmd := pw.textArea.Focus()
+	pw.textArea.SetWidth(pw.as.winW)
+	pw.recalcViewportSize()
+	return cmd
+}
+
+// saveCommentDraft saves the comment being edited as a draft.
+func (pw *postWindow) saveCommentDraft() {
+	draft := clientdb.PostDraft{
+		CommentFrom: &pw.summ.From,
+		CommentPID:  &pw.summ.ID,
+	}
+	if pw.commentDraft != nil {
+		draft = *pw.commentDraft
+	}
+	if pw.replying && pw.selComment < len(pw.comments) {
+		draft.CommentParent = &pw.comments[pw.selComment].id
+	}
+	draft.Content = pw.textArea.Value()
+	go pw.as.saveDraft(draft)
+}
+
 func (pw *postWindow) recalcViewportSize() {
 	// First, update the edit line height. This is not entirely accurate
 	// because textArea does its own wrapping.
@@ -611,6 +700,7 @@ func (pw postWindow) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			pw.cmdErr = ""
 			if pw.commenting {
 				pw.commenting = false
+				pw.commentDraft = nil
 				pw.recalcViewportSize()
 			} else if pw.relaying {
 				pw.relaying = false
@@ -711,6 +801,15 @@ func (pw postWindow) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			cmds = appendCmd(cmds, cmd)
 			pw.recalcViewportSize()
 
+		case msg.Type == tea.KeyCtrlS && pw.commenting:
+			if pw.textArea.Value() != "" {
+				pw.saveCommentDraft()
+			}
+			pw.textArea.SetValue("")
+			pw.commentDraft = nil
+			pw.commenting = false
+			pw.recalcViewportSize()
+
 		case msg.Type == tea.KeyEnter && pw.commenting:
 			var parent *clientintf.ID
 			if pw.replying && pw.selComment < len(pw.comments) {
@@ -718,9 +817,17 @@ func (pw postWindow) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				parent = &selComment.id
 			}
 			text := pw.textArea.Value()
-			go pw.as.commentPost(pw.summ.From, pw.summ.ID,
-				text, parent)
+			if pw.commentDraft != nil {
+				draft := *pw.commentDraft
+				draft.Content = text
+				draft.CommentParent = parent
+				go pw.as.publishDraft(draft)
+			} else {
+				go pw.as.commentPost(pw.summ.From, pw.summ.ID,
+					text, parent)
+			}
 			pw.textArea.SetValue("")
+			pw.commentDraft = nil
 			pw.recalcViewportSize()
 			pw.commenting = false
 
@@ -746,6 +853,9 @@ func (pw postWindow) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return pw, cmd
 
 		case msg.String() == "c":
+			if draft := pw.findCommentDraft(nil); draft != nil {
+				return pw, pw.editCommentDraft(*draft)
+			}
 			pw.debug = ""
 			pw.cmdErr = ""
 			pw.commenting = true
@@ -757,6 +867,12 @@ func (pw postWindow) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return pw, cmd
 
 		case msg.String() == "r":
+			if pw.selComment < len(pw.comments) {
+				parent := pw.comments[pw.selComment].id
+				if draft := pw.findCommentDraft(&parent); draft != nil {
+					return pw, pw.editCommentDraft(*draft)
+				}
+			}
 			pw.debug = ""
 			pw.cmdErr = ""
 			pw.commenting = true
@@ -783,6 +899,15 @@ func (pw postWindow) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			pw.debug = "Relaying post to subscribers"
 			return pw, cmd
 
+		case msg.String() == "P":
+			pw.debug = ""
+			if pw.paywallPrice == 0 || pw.paywallBody != "" {
+				return pw, cmd
+			}
+			go pw.as.buyPaywalledPost(pw.summ.From, pw.summ.ID)
+			pw.debug = "Paying to unlock post"
+			return pw, cmd
+
 		case msg.String() == "U":
 			pw.debug = ""
 			pw.textArea.SetValue("")
@@ -808,6 +933,13 @@ func (pw postWindow) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			pw.viewport.GotoBottom()
 		}
 
+	case postPaywallUnlocked:
+		if msg.pid != pw.summ.ID {
+			return pw, cmd
+		}
+		pw.updatePost()
+		pw.renderPost()
+
 	case sentPostComment:
 		pw.as.postsMtx.Lock()
 		pw.myComments = pw.as.myComments
@@ -865,7 +997,7 @@ done:
 
 func (pw postWindow) headerView() string {
 	msg := " Post - ESC to return, " +
-		"(S+R) Relay Post, (S+S) KX Search Author, (S+U) Relay to User, (Ctrl+D) Download, (Ctrl+V) View File"
+		"(S+R) Relay Post, (S+S) KX Search Author, (S+U) Relay to User, (S+P) Unlock Paywall, (Ctrl+D) Download, (Ctrl+V) View File"
 	headerMsg := pw.as.styles.header.Render(msg)
 	spaces := pw.as.styles.header.Render(strings.Repeat(" ",
 		max(0, pw.as.winW-lipgloss.Width(headerMsg))))
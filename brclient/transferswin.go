@@ -0,0 +1,302 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/companyzero/bisonrelay/client"
+	"github.com/companyzero/bisonrelay/client/clientdb"
+	"github.com/companyzero/bisonrelay/internal/strescape"
+)
+
+// transfersTick is sent to periodically refresh the transfers window.
+type transfersTick struct{}
+
+func transfersTickCmd() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return transfersTick{}
+	})
+}
+
+// chunkStateRune returns the rune used to represent a chunk state in the
+// chunk map of a transfer.
+func chunkStateRune(cs clientdb.ChunkState, ok bool) rune {
+	switch {
+	case !ok, cs == "":
+		return '.'
+	case cs == clientdb.ChunkStateDownloaded, cs == clientdb.ChunkStateUploaded:
+		return '#'
+	case cs == clientdb.ChunkStatePaid:
+		return '$'
+	case cs == clientdb.ChunkStateHasInvoice, cs == clientdb.ChunkStateSentInvoice,
+		cs == clientdb.ChunkStatePayingInvoice:
+		return 'i'
+	case cs == clientdb.ChunkStateInvoiceExpired:
+		return 'x'
+	default:
+		return 'r'
+	}
+}
+
+// transfersWindow shows all in-progress uploads and downloads.
+type transfersWindow struct {
+	as        *appState
+	transfers []client.TransferStatus
+	idx       int
+	err       string
+
+	viewport viewport.Model
+}
+
+func (tw transfersWindow) Init() tea.Cmd {
+	return nil
+}
+
+func (tw *transfersWindow) listTransfers() {
+	transfers, err := tw.as.c.ListTransfers()
+	if err != nil {
+		tw.err = err.Error()
+		return
+	}
+	tw.err = ""
+	sort.Slice(transfers, func(i, j int) bool {
+		if transfers[i].IsUpload != transfers[j].IsUpload {
+			return !transfers[i].IsUpload
+		}
+		return transfers[i].StartTime.Before(transfers[j].StartTime)
+	})
+	tw.transfers = transfers
+	if tw.idx >= len(tw.transfers) {
+		tw.idx = max(0, len(tw.transfers)-1)
+	}
+}
+
+func (tw *transfersWindow) renderTransfer(ts *client.TransferStatus, b *strings.Builder, i int) {
+	pf := fmt.Sprintf
+	st := tw.as.styles
+
+	dir := "down"
+	fromTo := "from"
+	if ts.IsUpload {
+		dir = "up"
+		fromTo = "to"
+	}
+	nick, _ := tw.as.c.UserNick(ts.UID)
+	if nick == "" {
+		nick = ts.UID.ShortLogID()
+	}
+	filename := ts.Filename
+	if filename == "" {
+		filename = "[no metadata]"
+	}
+	title := pf("%-4s %s %s %s", dir, filename, fromTo, strescape.Nick(nick))
+	if ts.Paused {
+		title += " (paused)"
+	}
+	if tw.idx == i {
+		b.WriteString(st.focused.Render(title))
+	} else {
+		b.WriteString(st.msg.Render(title))
+	}
+	b.WriteString("\n")
+
+	var progress float64
+	if ts.Size > 0 {
+		progress = float64(ts.DoneBytes) / float64(ts.Size) * 100
+	}
+	eta := "-"
+	if ts.ETA > 0 {
+		eta = ts.ETA.Truncate(time.Second).String()
+	}
+	paidLabel := "Paid"
+	if ts.IsUpload {
+		paidLabel = "Received"
+	}
+	b.WriteString(st.help.Render(pf("    %.2f%% (%d/%d chunks, %d/%d B) - "+
+		"%.2f B/s - ETA %s - %s %.8f DCR", progress, ts.DoneChunks,
+		ts.NbChunks, ts.DoneBytes, ts.Size, ts.Rate, eta, paidLabel,
+		float64(ts.MAtoms)/1e11)))
+	b.WriteString("\n")
+
+	// Chunk map, limited to the window width.
+	if ts.NbChunks > 0 {
+		width := max(10, tw.as.winW-6)
+		var chunks strings.Builder
+		for idx := 0; idx < ts.NbChunks && idx < width; idx++ {
+			cs, ok := ts.ChunkStates[idx]
+			chunks.WriteRune(chunkStateRune(cs, ok))
+		}
+		if ts.NbChunks > width {
+			chunks.WriteString("…")
+		}
+		b.WriteString(st.help.Render("    " + chunks.String()))
+		b.WriteString("\n")
+	}
+	b.WriteString(st.timestampHelp.Render(pf("    %s", ts.FID)))
+	b.WriteString("\n\n")
+}
+
+func (tw *transfersWindow) renderTransfers() {
+	if tw.as.winW > 0 && tw.as.winH > 0 {
+		tw.viewport.YPosition = 4
+		tw.viewport.Width = tw.as.winW
+		tw.viewport.Height = tw.as.winH - 4
+	}
+
+	var minOffset, maxOffset int
+	b := new(strings.Builder)
+	if len(tw.transfers) == 0 {
+		b.WriteString(tw.as.styles.help.Render("No transfers in progress"))
+	}
+	for i := range tw.transfers {
+		if i == tw.idx {
+			minOffset = strings.Count(b.String(), "\n")
+		}
+		tw.renderTransfer(&tw.transfers[i], b, i)
+		if i == tw.idx {
+			maxOffset = strings.Count(b.String(), "\n")
+		}
+	}
+
+	tw.viewport.SetContent(b.String())
+
+	// Ensure the currently selected index is visible.
+	if tw.viewport.YOffset > minOffset {
+		tw.viewport.SetYOffset(minOffset)
+	} else if bottom := tw.viewport.YOffset + tw.viewport.Height; bottom < maxOffset {
+		tw.viewport.SetYOffset(tw.viewport.YOffset + (maxOffset - bottom))
+	}
+}
+
+// togglePause pauses or resumes the selected transfer.
+func (tw *transfersWindow) togglePause() error {
+	if tw.idx >= len(tw.transfers) {
+		return nil
+	}
+	ts := tw.transfers[tw.idx]
+	c := tw.as.c
+	switch {
+	case ts.IsUpload && ts.Paused:
+		return c.ResumeUpload(ts.UID, ts.FID)
+	case ts.IsUpload:
+		return c.PauseUpload(ts.UID, ts.FID)
+	case ts.Paused:
+		return c.ResumeDownload(ts.FID)
+	default:
+		return c.PauseDownload(ts.FID)
+	}
+}
+
+// cancelSelected cancels the selected transfer.
+func (tw *transfersWindow) cancelSelected() error {
+	if tw.idx >= len(tw.transfers) {
+		return nil
+	}
+	ts := tw.transfers[tw.idx]
+	if ts.IsUpload {
+		return tw.as.c.CancelUpload(ts.UID, ts.FID)
+	}
+	return tw.as.c.CancelDownload(ts.FID)
+}
+
+func (tw transfersWindow) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	if ss, cmd := maybeShutdown(tw.as, msg); ss != nil {
+		return ss, cmd
+	}
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg: // resize window
+		tw.as.winW = msg.Width
+		tw.as.winH = msg.Height
+		tw.renderTransfers()
+
+	case tea.KeyMsg:
+		switch {
+		case msg.Type == tea.KeyEsc:
+			// Return to main window
+			return newMainWindowState(tw.as)
+
+		case msg.Type == tea.KeyUp, msg.String() == "k":
+			if tw.idx > 0 {
+				tw.idx -= 1
+				tw.renderTransfers()
+			}
+
+		case msg.Type == tea.KeyDown, msg.String() == "j":
+			if tw.idx < len(tw.transfers)-1 {
+				tw.idx += 1
+				tw.renderTransfers()
+			}
+
+		case msg.String() == "p":
+			if err := tw.togglePause(); err != nil {
+				tw.err = err.Error()
+			}
+			tw.listTransfers()
+			tw.renderTransfers()
+
+		case msg.String() == "c":
+			if err := tw.cancelSelected(); err != nil {
+				tw.err = err.Error()
+			}
+			tw.listTransfers()
+			tw.renderTransfers()
+		}
+
+	case transfersTick:
+		tw.listTransfers()
+		tw.renderTransfers()
+		cmd = transfersTickCmd()
+
+	case currentTimeChanged:
+		tw.as.footerInvalidate()
+
+	default:
+		tw.viewport, cmd = tw.viewport.Update(tw)
+	}
+
+	return tw, cmd
+}
+
+func (tw transfersWindow) headerView() string {
+	msg := " Transfers - Press ESC to return, p to pause/resume, c to cancel"
+	headerMsg := tw.as.styles.header.Render(msg)
+	spaces := tw.as.styles.header.Render(strings.Repeat(" ",
+		max(0, tw.as.winW-lipgloss.Width(headerMsg))))
+	return headerMsg + spaces
+}
+
+func (tw transfersWindow) statusView() string {
+	if tw.err != "" {
+		return tw.as.styles.err.Render(tw.err)
+	}
+	return tw.as.styles.help.Render(fmt.Sprintf("%d transfers - "+
+		"chunks: # done, $ paid, i invoiced, x invoice expired, r requested", len(tw.transfers)))
+}
+
+func (tw transfersWindow) footerView() string {
+	return tw.as.footerView("")
+}
+
+func (tw transfersWindow) View() string {
+	return fmt.Sprintf("%s\n%s\n%s\n%s",
+		tw.headerView(),
+		tw.statusView(),
+		tw.viewport.View(),
+		tw.footerView(),
+	)
+}
+
+func newTransfersWindow(as *appState) (transfersWindow, tea.Cmd) {
+	tw := transfersWindow{as: as}
+	tw.listTransfers()
+	tw.renderTransfers()
+	return tw, transfersTickCmd()
+}
@@ -141,7 +141,7 @@ class DownloadsModel extends ChangeNotifier {
         continue;
       }
       var f = _downloads[idx];
-      var nbChunks = update.metadata.manifest.length;
+      var nbChunks = update.metadata.chunkCount;
       f.progress = (nbChunks - update.nbMissingChunks) / nbChunks;
     }
   }
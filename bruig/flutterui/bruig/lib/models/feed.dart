@@ -190,6 +190,12 @@ class FeedModel extends ChangeNotifier {
     notifyListeners();
   }
 
+  Future<void> publishPostDraft(String draftID) async {
+    var newPost = await Golib.publishPostDraft(draftID);
+    _posts.insert(0, FeedPostModel(newPost));
+    notifyListeners();
+  }
+
   FeedModel() {
     _handleFeedPosts();
     _handlePostStatus();
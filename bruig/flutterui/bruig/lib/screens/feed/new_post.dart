@@ -30,6 +30,10 @@ class _NewPostScreenState extends State<NewPostScreen> {
   TextEditingController embedAlt = TextEditingController();
   int estimatedSize = 0;
 
+  // Drafts.
+  String? draftID;
+  List<PostDraft> drafts = [];
+
   void goBack() {
     Navigator.pop(context);
   }
@@ -54,10 +58,17 @@ class _NewPostScreenState extends State<NewPostScreen> {
       loading = true;
     });
     try {
-      await widget.feed.createPost(getFullContent());
+      if (draftID != null) {
+        var draft = await Golib.savePostDraft(
+            SavePostDraftArgs(draftID, getFullContent(), "", null, null, null));
+        await widget.feed.publishPostDraft(draft.id);
+      } else {
+        await widget.feed.createPost(getFullContent());
+      }
       setState(() {
         contentCtrl.clear();
         estimatedSize = 0;
+        draftID = null;
       });
       showSuccessSnackbar(context, "Created new post");
       Navigator.of(context).pushNamed(FeedScreen.routeName);
@@ -70,6 +81,49 @@ class _NewPostScreenState extends State<NewPostScreen> {
     }
   }
 
+  void listDrafts() async {
+    try {
+      var res = await Golib.listPostDrafts();
+      setState(() {
+        drafts = res.where((d) => !d.isComment).toList();
+      });
+    } catch (exception) {
+      showErrorSnackbar(context, "Unable to list drafts: $exception");
+    }
+  }
+
+  void saveDraft() async {
+    setState(() {
+      loading = true;
+    });
+    try {
+      var draft = await Golib.savePostDraft(
+          SavePostDraftArgs(draftID, getFullContent(), "", null, null, null));
+      setState(() {
+        draftID = draft.id;
+      });
+      showSuccessSnackbar(context, "Saved draft");
+      listDrafts();
+    } catch (exception) {
+      showErrorSnackbar(context, "Unable to save draft: $exception");
+    } finally {
+      setState(() {
+        loading = false;
+      });
+    }
+  }
+
+  void loadDraft(PostDraft? draft) {
+    if (draft == null) {
+      return;
+    }
+    setState(() {
+      draftID = draft.id;
+      embedContents = {};
+      contentCtrl.text = draft.content;
+    });
+  }
+
   void recalcEstimatedSize() async {
     try {
       var estSize = await Golib.estimatePostSize(getFullContent());
@@ -176,6 +230,7 @@ class _NewPostScreenState extends State<NewPostScreen> {
   void initState() {
     super.initState();
     contentCtrl.addListener(recalcEstimatedSize); // TODO: debounce.
+    listDrafts();
   }
 
   @override
@@ -196,7 +251,21 @@ class _NewPostScreenState extends State<NewPostScreen> {
       padding: const EdgeInsets.all(16),
       child: Column(
         children: [
-          Text("New Post", style: TextStyle(color: textColor, fontSize: 20)),
+          Row(mainAxisAlignment: MainAxisAlignment.center, children: [
+            Text(draftID == null ? "New Post" : "Edit Draft",
+                style: TextStyle(color: textColor, fontSize: 20)),
+            const SizedBox(width: 20),
+            DropdownButton<PostDraft>(
+              hint: const Text("Load Draft"),
+              items: drafts
+                  .map((d) => DropdownMenuItem<PostDraft>(
+                      value: d,
+                      child: Text(
+                          "${d.updated.toLocal()} - ${humanReadableSize(d.estSize)}")))
+                  .toList(),
+              onChanged: loading ? null : loadDraft,
+            ),
+          ]),
           Expanded(
               child: Container(
                   margin: const EdgeInsets.only(bottom: 15),
@@ -252,6 +321,10 @@ class _NewPostScreenState extends State<NewPostScreen> {
                 onPressed: !loading && validSize ? createPost : null,
                 child: const Text("Create Post")),
             const SizedBox(width: 20),
+            OutlinedButton(
+                onPressed: !loading ? saveDraft : null,
+                child: const Text("Save Draft")),
+            const SizedBox(width: 20),
           ])
         ],
       ),
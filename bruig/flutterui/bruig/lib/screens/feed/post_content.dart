@@ -227,6 +227,47 @@ class _PostContentScreenForArgsState extends State<_PostContentScreenForArgs> {
   String markdownData = "";
   Iterable<FeedCommentModel> comments = [];
   TextEditingController newCommentCtrl = TextEditingController();
+  String? commentDraftID;
+
+  // Loads the latest top-level comment draft for this post, if there is one.
+  void loadCommentDraft() async {
+    var summ = widget.args.post.summ;
+    try {
+      var drafts = await Golib.listPostDrafts();
+      PostDraft? draft;
+      for (var d in drafts) {
+        if (d.commentFrom == summ.from &&
+            d.commentPID == summ.id &&
+            d.commentParent == null) {
+          draft = d;
+        }
+      }
+      if (draft == null) {
+        return;
+      }
+      var found = draft;
+      setState(() {
+        commentDraftID = found.id;
+        newCommentCtrl.text = found.content;
+      });
+    } catch (exception) {
+      showErrorSnackbar(context, "Unable to load comment drafts: $exception");
+    }
+  }
+
+  Future<void> saveCommentDraft() async {
+    var summ = widget.args.post.summ;
+    try {
+      var draft = await Golib.savePostDraft(SavePostDraftArgs(commentDraftID,
+          newCommentCtrl.text, "", summ.from, summ.id, null));
+      setState(() {
+        commentDraftID = draft.id;
+      });
+      showSuccessSnackbar(context, "Saved comment draft");
+    } catch (exception) {
+      showErrorSnackbar(context, "Unable to save comment draft: $exception");
+    }
+  }
 
   void loadContent() async {
     setState(() {
@@ -259,10 +300,19 @@ class _PostContentScreenForArgsState extends State<_PostContentScreenForArgs> {
 
   Future<void> addComment() async {
     var newComment = newCommentCtrl.text;
+    var draftID = commentDraftID;
     setState(() {
       newCommentCtrl.clear();
+      commentDraftID = null;
     });
     widget.args.post.addNewComment(newComment);
+    if (draftID != null) {
+      var summ = widget.args.post.summ;
+      await Golib.savePostDraft(SavePostDraftArgs(
+          draftID, newComment, "", summ.from, summ.id, null));
+      await Golib.publishPostDraft(draftID);
+      return;
+    }
     await Golib.commentPost(
         widget.args.post.summ.from, widget.args.post.summ.id, newComment, null);
   }
@@ -289,6 +339,7 @@ class _PostContentScreenForArgsState extends State<_PostContentScreenForArgs> {
     super.initState();
     widget.args.post.addListener(postUpdated);
     loadContent();
+    loadCommentDraft();
   }
 
   @override
@@ -544,7 +595,20 @@ class _PostContentScreenForArgsState extends State<_PostContentScreenForArgs> {
                         "Add Comment",
                         style: TextStyle(
                             color: textColor, fontSize: 11, letterSpacing: 1),
-                      ))
+                      )),
+                  const SizedBox(width: 10),
+                  OutlinedButton(
+                      style: OutlinedButton.styleFrom(
+                          textStyle: TextStyle(
+                              color: textColor, fontSize: 11, letterSpacing: 1),
+                          padding: const EdgeInsets.only(
+                              bottom: 4, top: 4, left: 8, right: 8)),
+                      onPressed: saveCommentDraft,
+                      child: Text(
+                        "Save Draft",
+                        style: TextStyle(
+                            color: textColor, fontSize: 11, letterSpacing: 1),
+                      )),
                 ]),
 
                 // end of post area
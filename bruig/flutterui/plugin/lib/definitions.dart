@@ -351,9 +351,14 @@ class FileMetadata {
   final String filename;
   final String description;
   final String hash;
+  @JsonKey(defaultValue: [])
   final List<FileManifest> manifest;
   final String signature;
   final Map<String, dynamic>? attributes;
+  @JsonKey(name: "merkle_root")
+  final String? merkleRoot;
+  @JsonKey(name: "chunk_size", defaultValue: 0)
+  final int chunkSize;
 
   FileMetadata(
       this.version,
@@ -365,10 +370,20 @@ class FileMetadata {
       this.hash,
       this.manifest,
       this.signature,
-      this.attributes);
+      this.attributes,
+      this.merkleRoot,
+      this.chunkSize);
 
   factory FileMetadata.fromJson(Map<String, dynamic> json) =>
       _$FileMetadataFromJson(json);
+
+  // Files with a merkle root do not list their chunks in the manifest.
+  int get chunkCount {
+    if (merkleRoot == null) return manifest.length;
+    if (size == 0) return 0;
+    if (chunkSize == 0) return 1;
+    return (size + chunkSize - 1) ~/ chunkSize;
+  }
 }
 
 @JsonSerializable()
@@ -552,6 +567,49 @@ class CommentPostArgs {
   Map<String, dynamic> toJson() => _$CommentPostArgsToJson(this);
 }
 
+@JsonSerializable()
+class PostDraft {
+  final String id;
+  final String content;
+  final String descr;
+  @JsonKey(name: "comment_from")
+  final String? commentFrom;
+  @JsonKey(name: "comment_pid")
+  final String? commentPID;
+  @JsonKey(name: "comment_parent")
+  final String? commentParent;
+  @JsonKey(name: "est_size")
+  final int estSize;
+  final DateTime created;
+  final DateTime updated;
+
+  PostDraft(this.id, this.content, this.descr, this.commentFrom,
+      this.commentPID, this.commentParent, this.estSize, this.created,
+      this.updated);
+  factory PostDraft.fromJson(Map<String, dynamic> json) =>
+      _$PostDraftFromJson(json);
+
+  bool get isComment => commentPID != null;
+}
+
+@JsonSerializable()
+class SavePostDraftArgs {
+  @JsonKey(includeIfNull: false)
+  final String? id;
+  final String content;
+  final String descr;
+  @JsonKey(name: "comment_from", includeIfNull: false)
+  final String? commentFrom;
+  @JsonKey(name: "comment_pid", includeIfNull: false)
+  final String? commentPID;
+  @JsonKey(name: "comment_parent", includeIfNull: false)
+  final String? commentParent;
+
+  SavePostDraftArgs(this.id, this.content, this.descr, this.commentFrom,
+      this.commentPID, this.commentParent);
+  Map<String, dynamic> toJson() => _$SavePostDraftArgsToJson(this);
+}
+
 @JsonSerializable()
 class PostStatusReceived {
   @JsonKey(name: "post_from")
@@ -600,12 +658,110 @@ class OutstandingFileDownload {
   final Map<int, String> invoices;
   @JsonKey(name: "chunkstates", defaultValue: {})
   final Map<int, String> chunkStates;
+  @JsonKey(defaultValue: false)
+  final bool paused;
   OutstandingFileDownload(this.uid, this.fid, this.completedName, this.metadata,
-      this.invoices, this.chunkStates);
+      this.invoices, this.chunkStates, this.paused);
   factory OutstandingFileDownload.fromJson(Map<String, dynamic> json) =>
       _$OutstandingFileDownloadFromJson(json);
 }
 
+@JsonSerializable()
+class OutstandingFileUpload {
+  final String uid;
+  final String fid;
+  final bool paused;
+  @JsonKey(defaultValue: [])
+  final List<dynamic> chunks;
+  OutstandingFileUpload(this.uid, this.fid, this.paused, this.chunks);
+  factory OutstandingFileUpload.fromJson(Map<String, dynamic> json) =>
+      _$OutstandingFileUploadFromJson(json);
+}
+
+@JsonSerializable()
+class TransferArgs {
+  @JsonKey(name: "is_upload")
+  final bool isUpload;
+  final String fid;
+  @JsonKey(includeIfNull: false)
+  final String? uid;
+  TransferArgs(this.isUpload, this.fid, this.uid);
+  Map<String, dynamic> toJson() => _$TransferArgsToJson(this);
+}
+
+@JsonSerializable()
+class TransferCanceled {
+  final String uid;
+  final String fid;
+  @JsonKey(name: "is_upload")
+  final bool isUpload;
+  TransferCanceled(this.uid, this.fid, this.isUpload);
+  factory TransferCanceled.fromJson(Map<String, dynamic> json) =>
+      _$TransferCanceledFromJson(json);
+}
+
+@JsonSerializable()
+class InvoiceArgs {
+  final String uid;
+  final double amount;
+  final String memo;
+  InvoiceArgs(this.uid, this.amount, this.memo);
+  Map<String, dynamic> toJson() => _$InvoiceArgsToJson(this);
+}
+
+@JsonSerializable()
+class PaymentRequestArgs {
+  final String uid;
+  final String id;
+  final String reason;
+  PaymentRequestArgs(this.uid, this.id, this.reason);
+  Map<String, dynamic> toJson() => _$PaymentRequestArgsToJson(this);
+}
+
+@JsonSerializable()
+class PaymentRequest {
+  final String id;
+  final String uid;
+  final bool outbound;
+  @JsonKey(defaultValue: false)
+  final bool fetched;
+  final String invoice;
+  final int milliatoms;
+  @JsonKey(defaultValue: "")
+  final String memo;
+  final DateTime created;
+  final String status;
+  @JsonKey(name: "decline_reason", defaultValue: "")
+  final String declineReason;
+  PaymentRequest(this.id, this.uid, this.outbound, this.fetched, this.invoice,
+      this.milliatoms, this.memo, this.created, this.status, this.declineReason);
+  factory PaymentRequest.fromJson(Map<String, dynamic> json) =>
+      _$PaymentRequestFromJson(json);
+
+  bool get isPending => status == "pending";
+}
+
+@JsonSerializable()
+class BuyServerCreditResult {
+  final int balance;
+  final int fees;
+  BuyServerCreditResult(this.balance, this.fees);
+  factory BuyServerCreditResult.fromJson(Map<String, dynamic> json) =>
+      _$BuyServerCreditResultFromJson(json);
+}
+
+@JsonSerializable()
+class ServerCreditReceipt {
+  final String action;
+  final int milliatoms;
+  final int balance;
+  final int timestamp;
+  ServerCreditReceipt(
+      this.action, this.milliatoms, this.balance, this.timestamp);
+  factory ServerCreditReceipt.fromJson(Map<String, dynamic> json) =>
+      _$ServerCreditReceiptFromJson(json);
+}
+
 @JsonSerializable()
 class FileDownloadProgress {
   final String uid;
@@ -1474,6 +1630,14 @@ mixin NtfStreams {
       StreamController<FileDownloadProgress>();
   Stream<FileDownloadProgress> downloadProgress() => ntfDownloadProgress.stream;
 
+  StreamController<TransferCanceled> ntfTransferCanceled =
+      StreamController<TransferCanceled>();
+  Stream<TransferCanceled> transfersCanceled() => ntfTransferCanceled.stream;
+
+  StreamController<PaymentRequest> ntfPaymentRequests =
+      StreamController<PaymentRequest>();
+  Stream<PaymentRequest> paymentRequests() => ntfPaymentRequests.stream;
+
   StreamController<LNInitialChainSyncUpdate> ntfLNInitChainSync =
       StreamController<LNInitialChainSyncUpdate>();
   Stream<LNInitialChainSyncUpdate> lnInitChainSyncProgress() =>
@@ -1504,6 +1668,8 @@ abstract class PluginPlatform {
   Stream<PostStatusReceived> postStatusFeed() => throw "unimplemented";
   Stream<String> logLines() => throw "unimplemented";
   Stream<FileDownloadProgress> downloadProgress() => throw "unimplemented";
+  Stream<TransferCanceled> transfersCanceled() => throw "unimplemented";
+  Stream<PaymentRequest> paymentRequests() => throw "unimplemented";
   Stream<LNInitialChainSyncUpdate> lnInitChainSyncProgress() =>
       throw "unimplemented";
 
@@ -1712,6 +1878,23 @@ abstract class PluginPlatform {
     return PostSummary.fromJson(await asyncCall(CTCreatePost, content));
   }
 
+  Future<PostDraft> savePostDraft(SavePostDraftArgs args) async =>
+      PostDraft.fromJson(await asyncCall(CTSavePostDraft, args));
+
+  Future<List<PostDraft>> listPostDrafts() async {
+    var res = await asyncCall(CTListPostDrafts, null);
+    if (res == null) {
+      return [];
+    }
+    return (res as List).map<PostDraft>((v) => PostDraft.fromJson(v)).toList();
+  }
+
+  Future<PostSummary> publishPostDraft(String id) async =>
+      PostSummary.fromJson(await asyncCall(CTPublishPostDraft, id));
+
+  Future<void> removePostDraft(String id) async =>
+      await asyncCall(CTRemovePostDraft, id);
+
   Future<Map<String, dynamic>> getGCBlockList(String gcID) async {
     var res = await asyncCall(CTGCGetBlockList, gcID);
     if (res == null) {
@@ -1769,6 +1952,69 @@ abstract class PluginPlatform {
         .toList();
   }
 
+  Future<List<OutstandingFileUpload>> listUploads() async {
+    var res = await asyncCall(CTListUploads, null);
+    if (res == null) {
+      return [];
+    }
+    return (res as List)
+        .map<OutstandingFileUpload>((v) => OutstandingFileUpload.fromJson(v))
+        .toList();
+  }
+
+  Future<void> pauseTransfer(TransferArgs args) async =>
+      await asyncCall(CTPauseTransfer, args);
+
+  Future<void> resumeTransfer(TransferArgs args) async =>
+      await asyncCall(CTResumeTransfer, args);
+
+  Future<void> cancelTransfer(TransferArgs args) async =>
+      await asyncCall(CTCancelTransfer, args);
+
+  Future<PaymentRequest> requestInvoice(InvoiceArgs args) async {
+    var res = await asyncCall(CTRequestInvoice, args);
+    return PaymentRequest.fromJson(res);
+  }
+
+  Future<PaymentRequest> sendInvoice(InvoiceArgs args) async {
+    var res = await asyncCall(CTSendInvoice, args);
+    return PaymentRequest.fromJson(res);
+  }
+
+  Future<List<PaymentRequest>> listPaymentRequests(String? uid) async {
+    var res = await asyncCall(CTListPaymentRequests, uid);
+    if (res == null) {
+      return [];
+    }
+    return (res as List)
+        .map<PaymentRequest>((v) => PaymentRequest.fromJson(v))
+        .toList();
+  }
+
+  Future<void> payPaymentRequest(PaymentRequestArgs args) async =>
+      await asyncCall(CTPayPaymentRequest, args);
+
+  Future<void> declinePaymentRequest(PaymentRequestArgs args) async =>
+      await asyncCall(CTDeclinePaymentRequest, args);
+
+  Future<int> serverCreditBalance() async =>
+      await asyncCall(CTServerCreditBalance, null);
+
+  Future<BuyServerCreditResult> buyServerCredit(int milliatoms) async {
+    var res = await asyncCall(CTBuyServerCredit, milliatoms);
+    return BuyServerCreditResult.fromJson(res);
+  }
+
+  Future<List<ServerCreditReceipt>> serverCreditReceipts() async {
+    var res = await asyncCall(CTServerCreditReceipts, null);
+    if (res == null) {
+      return [];
+    }
+    return (res as List)
+        .map<ServerCreditReceipt>((v) => ServerCreditReceipt.fromJson(v))
+        .toList();
+  }
+
   Future<LNInfo> lnGetInfo() async {
     var res = await asyncCall(CTLNGetInfo, null);
     return LNInfo.fromJson(res);
@@ -2076,6 +2322,22 @@ const int CTUserRatchetDebugInfo = 0x66;
 const int CTResendGCList = 0x67;
 const int CTGCUpgradeVersion = 0x68;
 const int CTGCModifyAdmins = 0x69;
+const int CTSavePostDraft = 0x6a;
+const int CTListPostDrafts = 0x6b;
+const int CTPublishPostDraft = 0x6c;
+const int CTRemovePostDraft = 0x6d;
+const int CTListUploads = 0x6e;
+const int CTPauseTransfer = 0x6f;
+const int CTResumeTransfer = 0x70;
+const int CTCancelTransfer = 0x71;
+const int CTRequestInvoice = 0x72;
+const int CTSendInvoice = 0x73;
+const int CTListPaymentRequests = 0x74;
+const int CTPayPaymentRequest = 0x75;
+const int CTDeclinePaymentRequest = 0x76;
+const int CTServerCreditBalance = 0x77;
+const int CTBuyServerCredit = 0x78;
+const int CTServerCreditReceipts = 0x79;
 
 const int notificationsStartID = 0x1000;
 
@@ -2113,3 +2375,6 @@ const int NTGCAddedMembers = 0x101f;
 const int NTGCUpgradedVersion = 0x1020;
 const int NTGCMemberParted = 0x1021;
 const int NTGCAdminsChanged = 0x1022;
+const int NTTransferCanceled = 0x1023;
+const int NTPaymentRequestReceived = 0x1024;
+const int NTPaymentRequestUpdated = 0x1025;
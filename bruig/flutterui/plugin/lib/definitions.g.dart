@@ -318,11 +318,14 @@ FileMetadata _$FileMetadataFromJson(Map<String, dynamic> json) => FileMetadata(
       json['filename'] as String,
       json['description'] as String,
       json['hash'] as String,
-      (json['manifest'] as List<dynamic>)
-          .map((e) => FileManifest.fromJson(e as Map<String, dynamic>))
-          .toList(),
+      (json['manifest'] as List<dynamic>?)
+              ?.map((e) => FileManifest.fromJson(e as Map<String, dynamic>))
+              .toList() ??
+          [],
       json['signature'] as String,
       json['attributes'] as Map<String, dynamic>?,
+      json['merkle_root'] as String?,
+      json['chunk_size'] as int? ?? 0,
     );
 
 Map<String, dynamic> _$FileMetadataToJson(FileMetadata instance) =>
@@ -337,6 +340,8 @@ Map<String, dynamic> _$FileMetadataToJson(FileMetadata instance) =>
       'manifest': instance.manifest,
       'signature': instance.signature,
       'attributes': instance.attributes,
+      'merkle_root': instance.merkleRoot,
+      'chunk_size': instance.chunkSize,
     };
 
 SharedFile _$SharedFileFromJson(Map<String, dynamic> json) => SharedFile(
@@ -497,6 +502,58 @@ Map<String, dynamic> _$CommentPostArgsToJson(CommentPostArgs instance) =>
       'parent': instance.parent,
     };
 
+PostDraft _$PostDraftFromJson(Map<String, dynamic> json) => PostDraft(
+      json['id'] as String,
+      json['content'] as String,
+      json['descr'] as String,
+      json['comment_from'] as String?,
+      json['comment_pid'] as String?,
+      json['comment_parent'] as String?,
+      json['est_size'] as int,
+      DateTime.parse(json['created'] as String),
+      DateTime.parse(json['updated'] as String),
+    );
+
+Map<String, dynamic> _$PostDraftToJson(PostDraft instance) => <String, dynamic>{
+      'id': instance.id,
+      'content': instance.content,
+      'descr': instance.descr,
+      'comment_from': instance.commentFrom,
+      'comment_pid': instance.commentPID,
+      'comment_parent': instance.commentParent,
+      'est_size': instance.estSize,
+      'created': instance.created.toIso8601String(),
+      'updated': instance.updated.toIso8601String(),
+    };
+
+SavePostDraftArgs _$SavePostDraftArgsFromJson(Map<String, dynamic> json) =>
+    SavePostDraftArgs(
+      json['id'] as String?,
+      json['content'] as String,
+      json['descr'] as String,
+      json['comment_from'] as String?,
+      json['comment_pid'] as String?,
+      json['comment_parent'] as String?,
+    );
+
+Map<String, dynamic> _$SavePostDraftArgsToJson(SavePostDraftArgs instance) {
+  final val = <String, dynamic>{};
+
+  void writeNotNull(String key, dynamic value) {
+    if (value != null) {
+      val[key] = value;
+    }
+  }
+
+  writeNotNull('id', instance.id);
+  val['content'] = instance.content;
+  val['descr'] = instance.descr;
+  writeNotNull('comment_from', instance.commentFrom);
+  writeNotNull('comment_pid', instance.commentPID);
+  writeNotNull('comment_parent', instance.commentParent);
+  return val;
+}
+
 PostStatusReceived _$PostStatusReceivedFromJson(Map<String, dynamic> json) =>
     PostStatusReceived(
       json['post_from'] as String,
@@ -556,6 +613,7 @@ OutstandingFileDownload _$OutstandingFileDownloadFromJson(
             (k, e) => MapEntry(int.parse(k), e as String),
           ) ??
           {},
+      json['paused'] as bool? ?? false,
     );
 
 Map<String, dynamic> _$OutstandingFileDownloadToJson(
@@ -568,6 +626,147 @@ Map<String, dynamic> _$OutstandingFileDownloadToJson(
       'invoices': instance.invoices.map((k, e) => MapEntry(k.toString(), e)),
       'chunkstates':
           instance.chunkStates.map((k, e) => MapEntry(k.toString(), e)),
+      'paused': instance.paused,
+    };
+
+OutstandingFileUpload _$OutstandingFileUploadFromJson(
+        Map<String, dynamic> json) =>
+    OutstandingFileUpload(
+      json['uid'] as String,
+      json['fid'] as String,
+      json['paused'] as bool,
+      json['chunks'] as List<dynamic>? ?? [],
+    );
+
+Map<String, dynamic> _$OutstandingFileUploadToJson(
+        OutstandingFileUpload instance) =>
+    <String, dynamic>{
+      'uid': instance.uid,
+      'fid': instance.fid,
+      'paused': instance.paused,
+      'chunks': instance.chunks,
+    };
+
+TransferArgs _$TransferArgsFromJson(Map<String, dynamic> json) => TransferArgs(
+      json['is_upload'] as bool,
+      json['fid'] as String,
+      json['uid'] as String?,
+    );
+
+Map<String, dynamic> _$TransferArgsToJson(TransferArgs instance) {
+  final val = <String, dynamic>{
+    'is_upload': instance.isUpload,
+    'fid': instance.fid,
+  };
+
+  void writeNotNull(String key, dynamic value) {
+    if (value != null) {
+      val[key] = value;
+    }
+  }
+
+  writeNotNull('uid', instance.uid);
+  return val;
+}
+
+TransferCanceled _$TransferCanceledFromJson(Map<String, dynamic> json) =>
+    TransferCanceled(
+      json['uid'] as String,
+      json['fid'] as String,
+      json['is_upload'] as bool,
+    );
+
+Map<String, dynamic> _$TransferCanceledToJson(TransferCanceled instance) =>
+    <String, dynamic>{
+      'uid': instance.uid,
+      'fid': instance.fid,
+      'is_upload': instance.isUpload,
+    };
+
+InvoiceArgs _$InvoiceArgsFromJson(Map<String, dynamic> json) => InvoiceArgs(
+      json['uid'] as String,
+      (json['amount'] as num).toDouble(),
+      json['memo'] as String,
+    );
+
+Map<String, dynamic> _$InvoiceArgsToJson(InvoiceArgs instance) =>
+    <String, dynamic>{
+      'uid': instance.uid,
+      'amount': instance.amount,
+      'memo': instance.memo,
+    };
+
+PaymentRequestArgs _$PaymentRequestArgsFromJson(Map<String, dynamic> json) =>
+    PaymentRequestArgs(
+      json['uid'] as String,
+      json['id'] as String,
+      json['reason'] as String,
+    );
+
+Map<String, dynamic> _$PaymentRequestArgsToJson(PaymentRequestArgs instance) =>
+    <String, dynamic>{
+      'uid': instance.uid,
+      'id': instance.id,
+      'reason': instance.reason,
+    };
+
+PaymentRequest _$PaymentRequestFromJson(Map<String, dynamic> json) =>
+    PaymentRequest(
+      json['id'] as String,
+      json['uid'] as String,
+      json['outbound'] as bool,
+      json['fetched'] as bool? ?? false,
+      json['invoice'] as String,
+      json['milliatoms'] as int,
+      json['memo'] as String? ?? '',
+      DateTime.parse(json['created'] as String),
+      json['status'] as String,
+      json['decline_reason'] as String? ?? '',
+    );
+
+Map<String, dynamic> _$PaymentRequestToJson(PaymentRequest instance) =>
+    <String, dynamic>{
+      'id': instance.id,
+      'uid': instance.uid,
+      'outbound': instance.outbound,
+      'fetched': instance.fetched,
+      'invoice': instance.invoice,
+      'milliatoms': instance.milliatoms,
+      'memo': instance.memo,
+      'created': instance.created.toIso8601String(),
+      'status': instance.status,
+      'decline_reason': instance.declineReason,
+    };
+
+BuyServerCreditResult _$BuyServerCreditResultFromJson(
+        Map<String, dynamic> json) =>
+    BuyServerCreditResult(
+      json['balance'] as int,
+      json['fees'] as int,
+    );
+
+Map<String, dynamic> _$BuyServerCreditResultToJson(
+        BuyServerCreditResult instance) =>
+    <String, dynamic>{
+      'balance': instance.balance,
+      'fees': instance.fees,
+    };
+
+ServerCreditReceipt _$ServerCreditReceiptFromJson(Map<String, dynamic> json) =>
+    ServerCreditReceipt(
+      json['action'] as String,
+      json['milliatoms'] as int,
+      json['balance'] as int,
+      json['timestamp'] as int,
+    );
+
+Map<String, dynamic> _$ServerCreditReceiptToJson(
+        ServerCreditReceipt instance) =>
+    <String, dynamic>{
+      'action': instance.action,
+      'milliatoms': instance.milliatoms,
+      'balance': instance.balance,
+      'timestamp': instance.timestamp,
     };
 
 FileDownloadProgress _$FileDownloadProgressFromJson(
@@ -296,6 +296,17 @@ mixin BaseDesktopPlatform on NtfStreams {
         ntfChatEvents.add(event);
         break;
 
+      case NTTransferCanceled:
+        var event = TransferCanceled.fromJson(payload);
+        ntfTransferCanceled.add(event);
+        break;
+
+      case NTPaymentRequestReceived:
+      case NTPaymentRequestUpdated:
+        var event = PaymentRequest.fromJson(payload);
+        ntfPaymentRequests.add(event);
+        break;
+
       default:
         print("Received unknown notification ${cmd.toRadixString(16)}");
     }
@@ -92,6 +92,8 @@ func handleInitClient(handle uint32, args InitClient) error {
 		DownloadsRoot: args.DownloadsDir,
 		Logger:        logBknd.logger("FDDB"),
 		ChunkSize:     rpc.MaxChunkSize,
+
+		MerkleManifestChunks: clientdb.DefaultMerkleManifestChunks,
 	})
 	if err != nil {
 		return fmt.Errorf("unable to initialize DB: %v", err)
@@ -267,6 +269,23 @@ func handleInitClient(handle uint32, args InitClient) error {
 		notify(NTGCAdminsChanged, ntfn, nil)
 	}))
 
+	ntfns.Register(client.OnTransferCanceledNtfn(func(ru *client.RemoteUser, fid clientdb.FileID, isUpload bool) {
+		ntfn := TransferCanceled{
+			UID:      ru.ID(),
+			FID:      fid,
+			IsUpload: isUpload,
+		}
+		notify(NTTransferCanceled, ntfn, nil)
+	}))
+
+	ntfns.Register(client.OnPaymentRequestReceivedNtfn(func(ru *client.RemoteUser, req clientdb.PaymentRequest) {
+		notify(NTPaymentRequestReceived, req, nil)
+	}))
+
+	ntfns.Register(client.OnPaymentRequestUpdatedNtfn(func(ru *client.RemoteUser, req clientdb.PaymentRequest) {
+		notify(NTPaymentRequestUpdated, req, nil)
+	}))
+
 	cfg := client.Config{
 		DB:             db,
 		Dialer:         clientintf.NetDialer(args.ServerAddr, logBknd.logger("CONN")),
@@ -1329,6 +1348,137 @@ func handleClientCmd(cc *clientCtx, cmd *cmd) (interface{}, error) {
 			return nil, err
 		}
 		return nil, c.ModifyGCAdmins(args.GCID, args.NewAdmins, "")
+
+	case CTSavePostDraft:
+		var args clientdb.PostDraft
+		if err := cmd.decode(&args); err != nil {
+			return nil, err
+		}
+		return c.SaveDraft(args)
+
+	case CTListPostDrafts:
+		return c.ListDrafts()
+
+	case CTPublishPostDraft:
+		var args clientintf.ID
+		if err := cmd.decode(&args); err != nil {
+			return nil, err
+		}
+		return c.PublishDraft(args)
+
+	case CTRemovePostDraft:
+		var args clientintf.ID
+		if err := cmd.decode(&args); err != nil {
+			return nil, err
+		}
+		return nil, c.RemoveDraft(args)
+
+	case CTListUploads:
+		return c.ListUploads()
+
+	case CTPauseTransfer:
+		var args TransferArgs
+		if err := cmd.decode(&args); err != nil {
+			return nil, err
+		}
+		if args.IsUpload {
+			if args.UID == nil {
+				return nil, fmt.Errorf("uid must be specified for uploads")
+			}
+			return nil, c.PauseUpload(*args.UID, args.FID)
+		}
+		return nil, c.PauseDownload(args.FID)
+
+	case CTResumeTransfer:
+		var args TransferArgs
+		if err := cmd.decode(&args); err != nil {
+			return nil, err
+		}
+		if args.IsUpload {
+			if args.UID == nil {
+				return nil, fmt.Errorf("uid must be specified for uploads")
+			}
+			return nil, c.ResumeUpload(*args.UID, args.FID)
+		}
+		return nil, c.ResumeDownload(args.FID)
+
+	case CTCancelTransfer:
+		var args TransferArgs
+		if err := cmd.decode(&args); err != nil {
+			return nil, err
+		}
+		if args.IsUpload {
+			if args.UID == nil {
+				return nil, fmt.Errorf("uid must be specified for uploads")
+			}
+			return nil, c.CancelUpload(*args.UID, args.FID)
+		}
+		return nil, c.CancelDownload(args.FID)
+
+	case CTRequestInvoice:
+		var args InvoiceArgs
+		if err := cmd.decode(&args); err != nil {
+			return nil, err
+		}
+		return c.RequestInvoice(cc.ctx, args.UID, args.Amount, args.Memo)
+
+	case CTSendInvoice:
+		var args InvoiceArgs
+		if err := cmd.decode(&args); err != nil {
+			return nil, err
+		}
+		return c.SendInvoice(args.UID, args.Amount, args.Memo)
+
+	case CTListPaymentRequests:
+		var uid *clientintf.UserID
+		if err := cmd.decode(&uid); err != nil {
+			return nil, err
+		}
+		return c.ListPaymentRequests(uid)
+
+	case CTPayPaymentRequest:
+		var args PaymentRequestArgs
+		if err := cmd.decode(&args); err != nil {
+			return nil, err
+		}
+		return nil, c.PayPaymentRequest(cc.ctx, args.UID, args.ID)
+
+	case CTDeclinePaymentRequest:
+		var args PaymentRequestArgs
+		if err := cmd.decode(&args); err != nil {
+			return nil, err
+		}
+		return nil, c.DeclinePaymentRequest(args.UID, args.ID, args.Reason)
+
+	case CTServerCreditBalance:
+		return c.ServerCreditBalance(cc.ctx)
+
+	case CTBuyServerCredit:
+		var amount uint64
+		if err := cmd.decode(&amount); err != nil {
+			return nil, err
+		}
+		balance, fees, err := c.BuyServerCredit(cc.ctx, amount)
+		if err != nil {
+			return nil, err
+		}
+		return BuyServerCreditResult{Balance: balance, Fees: fees}, nil
+
+	case CTServerCreditReceipts:
+		receipts, err := c.ListServerCreditReceipts()
+		if err != nil {
+			return nil, err
+		}
+		res := make([]ServerCreditReceipt, len(receipts))
+		for i, r := range receipts {
+			res[i] = ServerCreditReceipt{
+				Action:     string(r.Action),
+				MilliAtoms: r.MilliAtoms,
+				Balance:    r.Balance,
+				Timestamp:  r.Timestamp,
+			}
+		}
+		return res, nil
 	}
 
 	return nil, nil
@@ -103,6 +103,22 @@ const (
 	CTResendGCList                    = 0x67
 	CTGCUpgradeVersion                = 0x68
 	CTGCModifyAdmins                  = 0x69
+	CTSavePostDraft                   = 0x6a
+	CTListPostDrafts                  = 0x6b
+	CTPublishPostDraft                = 0x6c
+	CTRemovePostDraft                 = 0x6d
+	CTListUploads                     = 0x6e
+	CTPauseTransfer                   = 0x6f
+	CTResumeTransfer                  = 0x70
+	CTCancelTransfer                  = 0x71
+	CTRequestInvoice                  = 0x72
+	CTSendInvoice                     = 0x73
+	CTListPaymentRequests             = 0x74
+	CTPayPaymentRequest               = 0x75
+	CTDeclinePaymentRequest           = 0x76
+	CTServerCreditBalance             = 0x77
+	CTBuyServerCredit                 = 0x78
+	CTServerCreditReceipts            = 0x79
 
 	NTInviteReceived         = 0x1001
 	NTInviteAccepted         = 0x1002
@@ -138,6 +154,9 @@ const (
 	NTGCUpgradedVersion      = 0x1020
 	NTGCMemberParted         = 0x1021
 	NTGCAdminsChanged        = 0x1022
+	NTTransferCanceled       = 0x1023
+	NTPaymentRequestReceived = 0x1024
+	NTPaymentRequestUpdated  = 0x1025
 )
 
 type cmd struct {
@@ -167,6 +167,43 @@ type FileDownloadProgress struct {
 	NbMissingChunks int               `json:"nb_missing_chunks"`
 }
 
+// TransferArgs identifies a file transfer. UID is only needed for uploads.
+type TransferArgs struct {
+	IsUpload bool               `json:"is_upload"`
+	FID      clientdb.FileID    `json:"fid"`
+	UID      *clientintf.UserID `json:"uid,omitempty"`
+}
+
+type TransferCanceled struct {
+	UID      clientintf.UserID `json:"uid"`
+	FID      clientdb.FileID   `json:"fid"`
+	IsUpload bool              `json:"is_upload"`
+}
+
+type InvoiceArgs struct {
+	UID    clientintf.UserID `json:"uid"`
+	Amount float64           `json:"amount"`
+	Memo   string            `json:"memo"`
+}
+
+type PaymentRequestArgs struct {
+	UID    clientintf.UserID  `json:"uid"`
+	ID     zkidentity.ShortID `json:"id"`
+	Reason string             `json:"reason"`
+}
+
+type BuyServerCreditResult struct {
+	Balance uint64 `json:"balance"`
+	Fees    int64  `json:"fees"`
+}
+
+type ServerCreditReceipt struct {
+	Action     string `json:"action"`
+	MilliAtoms int64  `json:"milliatoms"`
+	Balance    uint64 `json:"balance"`
+	Timestamp  int64  `json:"timestamp"`
+}
+
 type LNBalances struct {
 	Channel *lnrpc.ChannelBalanceResponse `json:"channel"`
 	Wallet  *lnrpc.WalletBalanceResponse  `json:"wallet"`
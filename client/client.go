@@ -67,6 +67,12 @@ type Config struct {
 	// the client and the server.
 	LogPings bool
 
+	// SendPostsToNewSubscribers indicates whether to send a local post
+	// (along with its status updates) to subscribers that do not have it
+	// when a new status update is added to it. When false, these
+	// subscribers do not receive the status update.
+	SendPostsToNewSubscribers bool
+
 	// CheckServerSession is called after a server session is established
 	// but before ServerSessionChanged is called and allows clients to check
 	// whether the connection is acceptable or if other preconditions are
@@ -84,7 +90,8 @@ type Config struct {
 	// ServerSessionChanged is called indicating that the connection to the
 	// server changed to the specified state (either connected or not).
 	//
-	// The push and subscription rates are specified in milliatoms/byte.
+	// The push rate is specified in milliatoms/KB and the subscription
+	// rate in milliatoms/sub.
 	ServerSessionChanged func(connected bool, pushRate, subRate, expirationDays uint64)
 
 	// GCWithUnkxdMember is called when an attempt to send a GC message
@@ -135,17 +142,28 @@ type Config struct {
 	ContentListReceived func(user *RemoteUser, files []clientdb.RemoteFile, listErr error)
 
 	// FileDownloadConfirmer is called to confirm the start of a file
-	// download with the user.
+	// download with the user. It is only called for files that do not
+	// match any of the download rules.
 	FileDownloadConfirmer func(user *RemoteUser, fm rpc.FileMetadata) bool
 
+	// PaymentApprover is called to approve outbound payments above the
+	// approval threshold of the payment limits. If nil, those payments
+	// are rejected.
+	PaymentApprover func(req PaymentApprovalRequest) bool
+
 	// FileDownloadCompleted is called whenever a download of a file has
-	// completed.
+	// completed. The MIME type attribute of fm is filled with the type
+	// detected for the downloaded file, if the sharer did not specify it.
 	FileDownloadCompleted func(user *RemoteUser, fm rpc.FileMetadata, diskPath string)
 
 	// FileDownloadProgress is called reporting the progress of a file
 	// download process.
 	FileDownloadProgress func(user *RemoteUser, fm rpc.FileMetadata, nbMissingChunks int)
 
+	// FileUploadProgress is called reporting the progress of a file upload
+	// to a remote user, whenever a chunk is paid for or sent.
+	FileUploadProgress func(user *RemoteUser, fm rpc.FileMetadata, stats clientdb.FileUploadStats)
+
 	// TransitiveEvent is called whenever a request is made by source for
 	// the local client to forward a message to dst.
 	TransitiveEvent func(src, dst UserID, event TransitiveEvent)
@@ -193,12 +211,21 @@ type Client struct {
 	gcmq  *gcmcacher.Cacher
 	ntfns *NotificationManager
 
+	// credit is the credit prepaid to the server. It is loaded during
+	// Run().
+	credit *lowlevel.ServerCredit
+
 	// abLoaded is closed when the address book has finished loading.
 	abLoaded chan struct{}
 
 	svrLnNodeMtx sync.Mutex
 	svrLnNode    string
 
+	svrRatesMtx    sync.Mutex
+	svrPushRate    uint64
+	svrSubRate     uint64
+	svrMinPushPymt uint64
+
 	newUsersChan chan *RemoteUser
 
 	// gcAliasMap maps a local gc name to a global gc id.
@@ -208,6 +235,11 @@ type Client struct {
 	// gcWarnedVersions tracks GCs for which the warning about an
 	// incompatible version has been issued.
 	gcWarnedVersions *singlesetmap.Map[zkidentity.ShortID]
+
+	// postSends tracks the number of sends of local posts to users that
+	// are in flight.
+	postSendsMtx sync.Mutex
+	postSends    map[postSendKey]int
 }
 
 // New creates a new CR client with the given config.
@@ -290,6 +322,7 @@ func New(cfg Config) (*Client, error) {
 		abLoaded:         make(chan struct{}),
 		newUsersChan:     make(chan *RemoteUser),
 		gcWarnedVersions: &singlesetmap.Map[zkidentity.ShortID]{},
+		postSends:        make(map[postSendKey]int),
 	}
 
 	// Use the GC message cacher to collect gc messages for a few seconds
@@ -491,6 +524,9 @@ func (c *Client) loadInitialDBData(ctx context.Context) error {
 	if err := c.loadGCAliases(ctx); err != nil {
 		return err
 	}
+	if err := c.loadServerCredit(ctx); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -568,6 +604,16 @@ func (c *Client) ServerLNNode() string {
 	return res
 }
 
+// ServerPaymentRates returns the push (in milliatoms/KB) and subscription (in
+// milliatoms/sub) payment rates of the currently connected server. Both rates
+// are zero when the client is not connected to the server.
+func (c *Client) ServerPaymentRates() (uint64, uint64) {
+	c.svrRatesMtx.Lock()
+	push, sub := c.svrPushRate, c.svrSubRate
+	c.svrRatesMtx.Unlock()
+	return push, sub
+}
+
 // RemainOffline requests the client to remain offline.
 func (c *Client) RemainOffline() {
 	c.ck.RemainOffline()
@@ -827,13 +873,14 @@ func (c *Client) Run(ctx context.Context) error {
 				}
 			}
 
-			var pushRate, subRate uint64
+			var pushRate, subRate, minPushPymt uint64
 			var expDays int
 
 			// Clean old unpaid RVs based on server expirationDays
 			// setting if it changed.
 			if nextSess != nil {
 				pushRate, subRate = nextSess.PaymentRates()
+				minPushPymt = nextSess.Policy().MinPushPayment
 				expDays = nextSess.ExpirationDays()
 
 				if lastExpDays != expDays {
@@ -851,6 +898,12 @@ func (c *Client) Run(ctx context.Context) error {
 				c.gcmq.SessionChanged(false)
 			}
 
+			c.svrRatesMtx.Lock()
+			c.svrPushRate, c.svrSubRate = pushRate, subRate
+			c.svrMinPushPymt = minPushPymt
+			c.svrRatesMtx.Unlock()
+
+			c.credit.BindToSession(nextSess)
 			c.rmgr.BindToSession(nextSess)
 			c.q.BindToSession(nextSess)
 			if c.cfg.ServerSessionChanged != nil {
@@ -948,6 +1001,14 @@ func (c *Client) Run(ctx context.Context) error {
 		return c.restartUploads(gctx)
 	})
 
+	// Complete sales of paywalled posts.
+	g.Go(func() error {
+		if err := waitAfterFirstConn(1 * time.Second); err != nil {
+			return err
+		}
+		return c.restartPaywalledPostSales(gctx)
+	})
+
 	// Clear old mediate id requests.
 	g.Go(func() error {
 		c.clearOldMediateIDs()
@@ -0,0 +1,214 @@
+package client
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/companyzero/bisonrelay/client/clientdb"
+	"github.com/companyzero/bisonrelay/client/clientintf"
+	"github.com/companyzero/bisonrelay/rpc"
+)
+
+// ftListTagDownloadCollection is the tag of RMFTList requests sent to fetch
+// the manifest of a collection that will be downloaded.
+const ftListTagDownloadCollection uint32 = 2
+
+// ShareCollection shares all files inside the given dir as a collection with
+// the given user (or to all users if none is specified). If name is empty,
+// the base name of the dir is used.
+//
+// Cost is the total cost of the collection in atoms.
+func (c *Client) ShareCollection(dir string, uid *UserID, name string,
+	cost uint64, descr string) (clientdb.SharedCollection, error) {
+
+	var sc clientdb.SharedCollection
+	sign := func(hash []byte) ([]byte, error) {
+		sig := c.id.SignMessage(hash)
+		return sig[:], nil
+	}
+
+	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		var err error
+		sc, err = c.db.ShareCollection(tx, dir, uid, name, cost, descr, sign)
+		return err
+	})
+	if err != nil {
+		return sc, err
+	}
+
+	if uid == nil {
+		c.log.Infof("Shared global collection %q with %d files",
+			sc.Collection.Name, len(sc.Collection.Files))
+	} else {
+		c.log.Infof("Shared collection %q with %d files with user %s",
+			sc.Collection.Name, len(sc.Collection.Files), uid)
+	}
+	return sc, nil
+}
+
+// UnshareCollection stops sharing the given collection (and its files) with
+// the given user (or all users if unspecified).
+func (c *Client) UnshareCollection(id clientdb.FileID, uid *UserID) error {
+	return c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		return c.db.UnshareCollection(tx, id, uid)
+	})
+}
+
+// ListLocalSharedCollections lists all locally shared collections.
+func (c *Client) ListLocalSharedCollections() ([]clientdb.SharedCollection, error) {
+	var res []clientdb.SharedCollection
+	err := c.dbView(func(tx clientdb.ReadTx) error {
+		var err error
+		res, err = c.db.ListAllSharedCollections(tx)
+		return err
+	})
+	return res, err
+}
+
+// ListUserCollection requests the manifest of a collection shared by the
+// remote user. The manifest is reported through OnCollectionReceivedNtfn.
+func (c *Client) ListUserCollection(uid UserID, id clientdb.FileID) error {
+	ru, err := c.rul.byID(uid)
+	if err != nil {
+		return err
+	}
+	return ru.sendRM(rpc.RMFTList{
+		Collection: id.String(),
+	}, "ftlist")
+}
+
+// GetUserCollection starts the download of every file of a collection shared
+// by the remote user. Files are placed in a dir named after the collection,
+// keeping the folder structure of the collection.
+func (c *Client) GetUserCollection(uid UserID, id clientdb.FileID) error {
+	ru, err := c.rul.byID(uid)
+	if err != nil {
+		return err
+	}
+	ru.log.Infof("Starting download of collection %s", id)
+	return ru.sendRM(rpc.RMFTList{
+		Collection: id.String(),
+		Tag:        ftListTagDownloadCollection,
+	}, "ftlist")
+}
+
+// handleFTListCollection replies to a remote user's request for the manifest
+// of a collection.
+func (c *Client) handleFTListCollection(ru *RemoteUser, ftls rpc.RMFTList) error {
+	var sc clientdb.SharedCollection
+	var id clientdb.FileID
+	err := id.FromString(ftls.Collection)
+	if err != nil {
+		err = fmt.Errorf("invalid collection id: %v", err)
+	} else {
+		err = c.dbView(func(tx clientdb.ReadTx) error {
+			var err error
+			sc, err = c.db.GetSharedCollectionForUpload(tx, ru.ID(), id)
+			return err
+		})
+	}
+	if err != nil {
+		if !errors.Is(err, clientintf.ErrSubsysExiting) && !errors.Is(err, context.Canceled) {
+			errStr := err.Error()
+			err := ru.sendRM(rpc.RMFTListReply{
+				Tag:   ftls.Tag,
+				Error: &errStr,
+			}, "ftlistreply")
+			if err != nil {
+				ru.log.Warnf("Error sending RMFTListReply: %v", err)
+			}
+		}
+		return err
+	}
+
+	ru.log.Infof("Sending manifest of collection %q to user", sc.Collection.Name)
+	return ru.sendRM(rpc.RMFTListReply{
+		Tag:        ftls.Tag,
+		Collection: &sc.Collection,
+	}, "ftlistreply")
+}
+
+// verifyCollection verifies that the collection was signed by the remote user
+// and that its totals match its files.
+func verifyCollection(ru *RemoteUser, fc *rpc.FileCollection) error {
+	var sig [ed25519.SignatureSize]byte
+	if len(fc.Signature) != len(sig)*2 {
+		return fmt.Errorf("collection signature has wrong len (%d != %d)",
+			len(fc.Signature), len(sig)*2)
+	}
+	if _, err := hex.Decode(sig[:], []byte(fc.Signature)); err != nil {
+		return fmt.Errorf("unable to decode collection signature: %v", err)
+	}
+	if !ru.PublicIdentity().VerifyMessage(fc.SignedHash(), sig) {
+		return fmt.Errorf("collection signature failed verification")
+	}
+
+	var size, cost uint64
+	for _, f := range fc.Files {
+		size += f.Size
+		cost += f.Cost
+	}
+	if size != fc.Size || cost != fc.Cost {
+		return fmt.Errorf("collection totals do not match its files")
+	}
+	return nil
+}
+
+// handleFTCollectionReply handles a reply with the manifest of a collection.
+func (c *Client) handleFTCollectionReply(ru *RemoteUser, ftrp rpc.RMFTListReply) error {
+	fc := ftrp.Collection
+	if err := verifyCollection(ru, fc); err != nil {
+		return err
+	}
+
+	if ftrp.Tag != ftListTagDownloadCollection {
+		c.ntfns.notifyCollectionReceived(ru, *fc)
+		return nil
+	}
+
+	// Queue the download of every file. Files that are already being
+	// downloaded (or were downloaded) are not requested again.
+	var id clientdb.FileID = fc.ID()
+	var queued []clientdb.FileID
+	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		queued = queued[:0]
+		for i := range fc.Files {
+			f := &fc.Files[i]
+			var fid clientdb.FileID
+			if err := fid.FromString(f.FileID); err != nil {
+				return fmt.Errorf("invalid file id in collection: %v", err)
+			}
+			destDir := clientdb.CollectionFileDestDir(fc, f)
+			_, isNew, err := c.db.StartCollectionFileDownload(tx,
+				ru.ID(), fid, id.String(), f, destDir)
+			if err != nil {
+				return err
+			}
+			if isNew {
+				queued = append(queued, fid)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	ru.log.Infof("Queued download of %d files of collection %q", len(queued),
+		fc.Name)
+	c.ntfns.notifyCollectionDownloadStarted(ru, *fc, len(queued))
+
+	for _, fid := range queued {
+		rmftg := rpc.RMFTGet{
+			FileID: fid.String(),
+		}
+		payEvent := fmt.Sprintf("ftget.%s", fid.ShortLogID())
+		if err := ru.sendRM(rmftg, payEvent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -15,6 +15,45 @@ import (
 	"github.com/decred/slog"
 )
 
+// swarmChunkRequestTimeout is how long to wait for a reply to a chunk request
+// before requesting the chunk from a different source, when the download has
+// more than one source.
+const swarmChunkRequestTimeout = time.Hour
+
+const (
+	// maxChunkInvoiceRetries is the max number of times a new invoice is
+	// automatically requested for a chunk after the previous one expired
+	// before it could be paid. Further attempts are only made when the
+	// download is resumed or the client is restarted.
+	maxChunkInvoiceRetries = 5
+
+	// chunkInvoiceRetryDelay is the delay before requesting a new invoice
+	// for a chunk after its second invoice expired. The delay doubles for
+	// every following expired invoice.
+	chunkInvoiceRetryDelay = 10 * time.Second
+
+	// maxChunkInvoiceRetryDelay is the max delay before requesting a new
+	// invoice for a chunk.
+	maxChunkInvoiceRetryDelay = 10 * time.Minute
+)
+
+// chunkInvoiceRetryBackoff returns how long to wait before requesting a new
+// invoice for a chunk, after the given number of its invoices expired. The
+// first new invoice is requested immediately.
+func chunkInvoiceRetryBackoff(retries int) time.Duration {
+	if retries <= 1 {
+		return 0
+	}
+	delay := chunkInvoiceRetryDelay
+	for i := 2; i < retries && delay < maxChunkInvoiceRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxChunkInvoiceRetryDelay {
+		delay = maxChunkInvoiceRetryDelay
+	}
+	return delay
+}
+
 // The list content flow is:
 //
 //          Alice                                    Bob
@@ -67,6 +106,22 @@ import (
 func (c *Client) ShareFile(fname string, uid *UserID,
 	cost uint64, isRef bool, descr string,
 ) (clientdb.SharedFile, rpc.FileMetadata, error) {
+	return c.shareFile(fname, uid, cost, descr, false)
+}
+
+// ShareCompressedFile is like ShareFile, but the file is compressed before
+// being chunked, which reduces the cost of transferring compressible files.
+// The file is shared uncompressed if compressing it does not reduce its size
+// or if it was previously shared uncompressed.
+//
+// Cost is in atoms.
+func (c *Client) ShareCompressedFile(fname string, uid *UserID,
+	cost uint64, descr string) (clientdb.SharedFile, rpc.FileMetadata, error) {
+	return c.shareFile(fname, uid, cost, descr, true)
+}
+
+func (c *Client) shareFile(fname string, uid *UserID, cost uint64,
+	descr string, compress bool) (clientdb.SharedFile, rpc.FileMetadata, error) {
 
 	var f clientdb.SharedFile
 	var md rpc.FileMetadata
@@ -77,7 +132,7 @@ func (c *Client) ShareFile(fname string, uid *UserID,
 
 	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
 		var err error
-		f, md, err = c.db.ShareFile(tx, fname, uid, cost, descr, sign)
+		f, md, err = c.db.ShareFile(tx, fname, uid, cost, descr, compress, sign)
 		return err
 	})
 
@@ -109,6 +164,131 @@ func (c *Client) UnshareFile(fid clientdb.FileID, uid *UserID) error {
 	})
 }
 
+// ShareFileWithGC shares the given file with the members of the given GC. Any
+// user that is a member of the GC may list and fetch the file, for as long as
+// they remain a member. Members are notified of the new file through a GC
+// message.
+//
+// Cost is in atoms.
+func (c *Client) ShareFileWithGC(fname string, gcID zkidentity.ShortID,
+	cost uint64, descr string) (clientdb.SharedFile, rpc.FileMetadata, error) {
+
+	var f clientdb.SharedFile
+	var md rpc.FileMetadata
+	sign := func(hash []byte) ([]byte, error) {
+		sig := c.id.SignMessage(hash)
+		return sig[:], nil
+	}
+
+	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		if _, err := c.db.GetGC(tx, gcID); err != nil {
+			return err
+		}
+		var err error
+		f, md, err = c.db.ShareFileWithGC(tx, fname, gcID, cost, descr, sign)
+		return err
+	})
+	if err != nil {
+		return f, md, err
+	}
+
+	c.log.Infof("Shared file %q with GC %s", f.Filename, gcID)
+
+	msg := fmt.Sprintf("Shared file %q (%d bytes, %.8f DCR) with the group. "+
+		"File ID: %s", md.Filename, md.Size, float64(md.Cost)/1e8, f.FID)
+	if err := c.GCMessage(gcID, msg, rpc.MessageModeNormal, nil); err != nil {
+		return f, md, fmt.Errorf("unable to notify GC of shared file: %v", err)
+	}
+	return f, md, nil
+}
+
+// UnshareFileWithGC stops sharing the given file with the members of the
+// given GC. Outstanding uploads of the file to members that no longer have
+// access to it are canceled.
+func (c *Client) UnshareFileWithGC(fid clientdb.FileID, gcID zkidentity.ShortID) error {
+	var members []UserID
+	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		if gc, err := c.db.GetGC(tx, gcID); err == nil {
+			members = gc.Members
+		}
+		return c.db.UnshareFileWithGC(tx, fid, gcID)
+	})
+	if err != nil {
+		return err
+	}
+	return c.revokeSharedFileUploads(members, map[clientdb.FileID]struct{}{fid: {}})
+}
+
+// ListGCSharedFiles lists the files shared with the members of the given GC.
+func (c *Client) ListGCSharedFiles(gcID zkidentity.ShortID) ([]rpc.FileMetadata, error) {
+	var files []rpc.FileMetadata
+	err := c.dbView(func(tx clientdb.ReadTx) error {
+		var err error
+		files, err = c.db.ListGCSharedFiles(tx, gcID)
+		return err
+	})
+	return files, err
+}
+
+// revokeGCSharedFiles is called when the given users are no longer members of
+// the given GC. It cancels the outstanding uploads to them of files shared
+// with the GC that they are no longer allowed to fetch.
+func (c *Client) revokeGCSharedFiles(gcID zkidentity.ShortID, uids []UserID) error {
+	files, err := c.ListGCSharedFiles(gcID)
+	if err != nil || len(files) == 0 {
+		return err
+	}
+	fids := make(map[clientdb.FileID]struct{}, len(files))
+	for i := range files {
+		fids[files[i].MetadataHash()] = struct{}{}
+	}
+	return c.revokeSharedFileUploads(uids, fids)
+}
+
+// revokeSharedFileUploads cancels the outstanding uploads of the given files
+// to the given users, if the users are no longer allowed to fetch them.
+func (c *Client) revokeSharedFileUploads(uids []UserID, fids map[clientdb.FileID]struct{}) error {
+	var revoked []clientdb.FileUpload
+	err := c.dbView(func(tx clientdb.ReadTx) error {
+		uploads, err := c.db.ListFileUploads(tx)
+		if err != nil {
+			return err
+		}
+		for _, up := range uploads {
+			if _, ok := fids[up.FID]; !ok {
+				continue
+			}
+			isUser := false
+			for _, uid := range uids {
+				if uid == up.UID {
+					isUser = true
+					break
+				}
+			}
+			if !isUser {
+				continue
+			}
+			_, _, err := c.db.GetSharedFileForUpload(tx, up.UID, up.FID)
+			if errors.Is(err, clientdb.ErrNotFound) {
+				revoked = append(revoked, up)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, up := range revoked {
+		c.log.Infof("Canceling upload of file %s to user %s due to "+
+			"revoked access", up.FID, up.UID)
+		if err := c.CancelUpload(up.UID, up.FID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ListLocalSharedFiles lists all locally shared files.
 func (c *Client) ListLocalSharedFiles() ([]clientdb.SharedFileAndShares, error) {
 	var files []clientdb.SharedFileAndShares
@@ -153,7 +333,12 @@ func (c *Client) ListUserContent(uid UserID, dirs []string, filter string) error
 
 // handleFTList handles listing of local user files requested by a remote user.
 func (c *Client) handleFTList(ru *RemoteUser, ftls rpc.RMFTList) error {
+	if ftls.Collection != "" {
+		return c.handleFTListCollection(ru, ftls)
+	}
+
 	var global, shared []rpc.FileMetadata
+	var collections []rpc.CollectionSummary
 	err := c.dbView(func(tx clientdb.ReadTx) error {
 		// Ensure unique list of dirs.
 		dirs := make(map[string]struct{}, 2)
@@ -167,11 +352,16 @@ func (c *Client) handleFTList(ru *RemoteUser, ftls rpc.RMFTList) error {
 		}
 
 		var err error
+		var scs []clientdb.SharedCollection
 		if _, ok := dirs[rpc.RMFTDGlobal]; ok {
 			global, err = c.db.ListSharedFiles(tx, nil)
 			if err != nil {
 				return err
 			}
+			scs, err = c.db.ListSharedCollections(tx, nil)
+			if err != nil {
+				return err
+			}
 		}
 		if _, ok := dirs[rpc.RMFTDShared]; ok {
 			id := ru.ID()
@@ -179,6 +369,34 @@ func (c *Client) handleFTList(ru *RemoteUser, ftls rpc.RMFTList) error {
 			if err != nil {
 				return err
 			}
+
+			// Files shared with GCs the user is a member of are
+			// also listed as shared with the user.
+			gcShared, err := c.db.ListGCSharedFilesForUser(tx, id)
+			if err != nil {
+				return err
+			}
+			for i := range gcShared {
+				var fid clientdb.FileID = gcShared[i].MetadataHash()
+				found := false
+				for j := range shared {
+					if shared[j].MetadataHash() == fid {
+						found = true
+						break
+					}
+				}
+				if !found {
+					shared = append(shared, gcShared[i])
+				}
+			}
+			userScs, err := c.db.ListSharedCollections(tx, &id)
+			if err != nil {
+				return err
+			}
+			scs = append(scs, userScs...)
+		}
+		for i := range scs {
+			collections = append(collections, scs[i].Summary())
 		}
 
 		return nil
@@ -199,9 +417,10 @@ func (c *Client) handleFTList(ru *RemoteUser, ftls rpc.RMFTList) error {
 	}
 
 	return ru.sendRM(rpc.RMFTListReply{
-		Tag:    ftls.Tag,
-		Global: global,
-		Shared: shared,
+		Tag:         ftls.Tag,
+		Global:      global,
+		Shared:      shared,
+		Collections: collections,
 	}, "ftlistreply")
 }
 
@@ -218,14 +437,31 @@ func (c *Client) HasDownloadedFile(fid zkidentity.ShortID) (string, error) {
 
 // handleFTListReply handles a reply for list from a remote user.
 func (c *Client) handleFTListReply(ru *RemoteUser, ftrp rpc.RMFTListReply) error {
+	if ftrp.Tag == ftListTagFindSources {
+		if ftrp.Error != nil {
+			ru.log.Debugf("Unable to list content to find download "+
+				"sources: %s", *ftrp.Error)
+			return nil
+		}
+		files := append(ftrp.Global, ftrp.Shared...)
+		return c.addDownloadSources(ru, files)
+	}
+
 	if ftrp.Error != nil {
 		err := errors.New(*ftrp.Error)
+		if ftrp.Tag == ftListTagDownloadCollection {
+			return fmt.Errorf("unable to download collection: %v", err)
+		}
 		if c.cfg.ContentListReceived != nil {
 			c.cfg.ContentListReceived(ru, nil, err)
 		}
 		return err
 	}
 
+	if ftrp.Collection != nil {
+		return c.handleFTCollectionReply(ru, ftrp)
+	}
+
 	files := append(ftrp.Global, ftrp.Shared...)
 	var res []clientdb.RemoteFile
 	err := c.dbView(func(tx clientdb.ReadTx) error {
@@ -238,9 +474,16 @@ func (c *Client) handleFTListReply(ru *RemoteUser, ftrp rpc.RMFTListReply) error
 	}
 	c.log.Infof("User listed %d files", len(res))
 
+	if err := c.addDownloadSources(ru, files); err != nil {
+		ru.log.Warnf("Unable to add user as a download source: %v", err)
+	}
+
 	if c.cfg.ContentListReceived != nil {
 		c.cfg.ContentListReceived(ru, res, nil)
 	}
+	if len(ftrp.Collections) > 0 {
+		c.ntfns.notifyCollectionsListed(ru, ftrp.Collections)
+	}
 
 	return nil
 }
@@ -325,11 +568,12 @@ func (c *Client) handleFTGet(ru *RemoteUser, ftg rpc.RMFTGet) error {
 }
 
 // requestFileChunk sends a request to a remote host for one chunk of one of
-// its files.
+// its files. If expiredInvoice is specified, the request asks for a new
+// invoice to replace it.
 func (c *Client) requestFileChunk(ru *RemoteUser, fid clientdb.FileID, chunkIdx int,
-	fm rpc.FileMetadata) error {
+	fm rpc.FileMetadata, expiredInvoice string) error {
 
-	chunkHash := fm.Manifest[chunkIdx].Hash
+	chunkHash := fm.ChunkHash(chunkIdx)
 
 	if ru.log.Level() <= slog.LevelDebug {
 		ru.log.Debugf("Requesting chunk %d (%x) of file %q (%s)",
@@ -339,10 +583,16 @@ func (c *Client) requestFileChunk(ru *RemoteUser, fid clientdb.FileID, chunkIdx
 			chunkIdx, fm.Filename)
 	}
 
+	if expiredInvoice != "" {
+		ru.log.Infof("Requesting new invoice for chunk %d of file %q "+
+			"after previous one expired", chunkIdx, fm.Filename)
+	}
+
 	rm := rpc.RMFTGetChunk{
-		FileID: fid.String(),
-		Index:  chunkIdx,
-		Hash:   chunkHash,
+		FileID:         fid.String(),
+		Index:          chunkIdx,
+		Hash:           chunkHash,
+		ExpiredInvoice: expiredInvoice,
 	}
 	payEvent := fmt.Sprintf("ftgetchunk.%s.%d", fid.ShortLogID(), rm.Index)
 	if err := ru.sendRM(rm, payEvent); err != nil {
@@ -350,12 +600,11 @@ func (c *Client) requestFileChunk(ru *RemoteUser, fid clientdb.FileID, chunkIdx
 	}
 
 	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
-		fd, err := c.db.ReadFileDownload(tx, ru.ID(), fid)
+		fd, err := c.db.ReadFileDownloadBySource(tx, ru.ID(), fid)
 		if err != nil {
 			return err
 		}
-		return c.db.ReplaceFileDownloadChunkState(tx, &fd, chunkIdx,
-			clientdb.ChunkStateRequestedChunk)
+		return c.db.MarkFileDownloadChunkRequested(tx, &fd, chunkIdx, ru.ID())
 	})
 	if err != nil {
 		return err
@@ -367,9 +616,17 @@ func (c *Client) requestFileChunk(ru *RemoteUser, fid clientdb.FileID, chunkIdx
 func (c *Client) payFileChunkInvoice(ru *RemoteUser, fid clientdb.FileID,
 	chunkIdx int, invoice string, matoms int64) error {
 
+	// Check the payment is allowed. If not, the invoice is kept so that
+	// the payment is attempted again when the download is resumed.
+	uid := ru.ID()
+	descr := fmt.Sprintf("chunk %d of file %s", chunkIdx, fid.ShortLogID())
+	if err := c.approvePayment(&uid, nil, matoms, descr); err != nil {
+		return err
+	}
+
 	// Mark invoice as attempting to pay.
 	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
-		fd, err := c.db.ReadFileDownload(tx, ru.ID(), fid)
+		fd, err := c.db.ReadFileDownloadBySource(tx, ru.ID(), fid)
 		if err != nil {
 			return err
 		}
@@ -386,6 +643,7 @@ func (c *Client) payFileChunkInvoice(ru *RemoteUser, fid clientdb.FileID,
 			clientdb.ChunkStatePayingInvoice)
 	})
 	if err != nil {
+		c.releasePayment(&uid, nil, matoms)
 		return err
 	}
 
@@ -396,13 +654,27 @@ func (c *Client) payFileChunkInvoice(ru *RemoteUser, fid clientdb.FileID,
 	}
 
 	// Record result of attempting the payment.
+	var fd clientdb.FileDownload
+	var expiredRetries int
 	dbErr := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
-		fd, dbErr := c.db.ReadFileDownload(tx, ru.ID(), fid)
+		var dbErr error
+		fd, dbErr = c.db.ReadFileDownloadBySource(tx, ru.ID(), fid)
 		if dbErr != nil {
 			return dbErr
 		}
 
 		if invErr != nil {
+			c.db.ReleasePayment(tx, &uid, nil, matoms)
+
+			// If the invoice expired in the meantime, a new
+			// one needs to be requested from the uploader.
+			decoded, err := c.pc.DecodeInvoice(c.ctx, invoice)
+			if err == nil && decoded.IsExpired(rpc.InvoiceExpiryAffordance) {
+				expiredRetries, dbErr = c.db.MarkFileDownloadChunkInvoiceExpired(
+					tx, &fd, chunkIdx)
+				return dbErr
+			}
+
 			// Unable to pay for invoice. Clear it to request a new
 			// one on the next attempt.
 			invoices := map[int]string{chunkIdx: ""}
@@ -419,11 +691,12 @@ func (c *Client) payFileChunkInvoice(ru *RemoteUser, fid clientdb.FileID,
 
 		// Invoice paid, we expect the sender to detect this and send
 		// the chunk.
-		return c.db.ReplaceFileDownloadChunkState(tx, &fd, chunkIdx,
-			clientdb.ChunkStatePaid)
+		return c.db.MarkFileDownloadChunkPaid(tx, &fd, chunkIdx, matoms)
 	})
 	if dbErr != nil {
 		ru.log.Errorf("Unable to update invoice for file get in DB: %v", dbErr)
+	} else if expiredRetries > 0 {
+		c.retryExpiredChunkInvoice(ru, fid, &fd, chunkIdx, invoice, expiredRetries)
 	}
 
 	// Decide which error to return.
@@ -434,6 +707,57 @@ func (c *Client) payFileChunkInvoice(ru *RemoteUser, fid clientdb.FileID,
 	return err
 }
 
+// retryExpiredChunkInvoice requests a new invoice for a chunk of a download,
+// after the given number of its invoices expired before they could be paid.
+// The request is delayed according to the number of retries and is not sent
+// once the max number of retries is reached.
+func (c *Client) retryExpiredChunkInvoice(ru *RemoteUser, fid clientdb.FileID,
+	fd *clientdb.FileDownload, chunkIdx int, invoice string, retries int) {
+
+	if retries > maxChunkInvoiceRetries {
+		ru.log.Errorf("Giving up on chunk %d of file %s after %d expired "+
+			"invoices. Resume the download to try again", chunkIdx,
+			fd.FID, retries)
+		return
+	}
+
+	delay := chunkInvoiceRetryBackoff(retries)
+	ru.log.Warnf("Invoice for chunk %d of file %s expired before it could "+
+		"be paid (attempt %d). Requesting a new one in %s", chunkIdx,
+		fd.FID, retries, delay)
+	if fd.SourceMetadata(ru.ID()) == nil {
+		return
+	}
+	md := *fd.SourceMetadata(ru.ID())
+	go func() {
+		select {
+		case <-time.After(delay):
+		case <-c.ctx.Done():
+			return
+		}
+
+		// Only request if the chunk is still waiting for a new invoice.
+		var cs clientdb.ChunkState
+		err := c.dbView(func(tx clientdb.ReadTx) error {
+			fd, err := c.db.ReadFileDownloadBySource(tx, ru.ID(), fid)
+			if err != nil {
+				return err
+			}
+			if !fd.Paused {
+				cs = fd.GetChunkState(chunkIdx)
+			}
+			return nil
+		})
+		if err != nil || cs != clientdb.ChunkStateInvoiceExpired {
+			return
+		}
+		err = c.requestFileChunk(ru, fid, chunkIdx, md, invoice)
+		if err != nil && !errors.Is(err, clientintf.ErrSubsysExiting) {
+			ru.log.Errorf("Unable to request new invoice for chunk: %v", err)
+		}
+	}()
+}
+
 // downloadChunks is the main workhorse for chunked file download. It is called
 // both for initial download and for restarting old downloads (on client
 // startup).
@@ -448,7 +772,7 @@ func (c *Client) downloadChunks(ru *RemoteUser, fd clientdb.FileDownload) error
 
 	var missing []int
 	err := c.dbView(func(tx clientdb.ReadTx) error {
-		missing = c.db.MissingFileDownloadChunks(tx, &fd)
+		missing = c.db.MissingFileDownloadRangeChunks(tx, &fd)
 		return nil
 	})
 	if err != nil {
@@ -480,6 +804,7 @@ func (c *Client) downloadChunks(ru *RemoteUser, fd clientdb.FileDownload) error
 		// - Requested, but no reply received yet
 		// - Received invoice, but not acted on it
 		// - Received invoice, but it expired before acting on it
+		// - Invoice expired, waiting to request a new one
 		// - Attempt to pay invoice in-flight
 		// - Attempt to pay invoice succeeded, but not received chunk
 		// - Received chunk
@@ -489,48 +814,121 @@ func (c *Client) downloadChunks(ru *RemoteUser, fd clientdb.FileDownload) error
 				return err
 			}
 
-			if chunkIdx >= len(fd.Metadata.Manifest) {
+			if fd.Paused {
+				return errTransferPaused
+			}
+
+			if fd.Metadata == nil {
+				// Download was canceled and requested again
+				// while processing chunks.
+				return fmt.Errorf("download of file %s was restarted", fd.FID)
+			}
+
+			if chunkIdx >= fd.Metadata.ChunkCount() {
 				// Shouldn't happen, but avoid panic.
 				return fmt.Errorf("Assertion error: chunkIdx %d >= len(manifest) %d",
-					chunkIdx, len(fd.Metadata.Manifest))
+					chunkIdx, fd.Metadata.ChunkCount())
+			}
+
+			// Figure out which users the chunk may be fetched from.
+			// Chunks are spread among all known sources.
+			srcs := make([]*RemoteUser, 0, len(fd.Sources)+1)
+			for _, uid := range fd.SourceUIDs() {
+				if src, err := c.rul.byID(uid); err == nil {
+					srcs = append(srcs, src)
+				}
+			}
+			if len(srcs) == 0 {
+				return fmt.Errorf("no known sources for download %s", fd.FID)
+			}
+			srcIndex := func(uid UserID) int {
+				for i := range srcs {
+					if srcs[i].ID() == uid {
+						return i
+					}
+				}
+				return -1
 			}
+			src := srcs[chunkIdx%len(srcs)]
+			prevSrcIdx := srcIndex(fd.ChunkSource(chunkIdx))
 
 			var payMAtoms int64
+			var expiredInvoice string
+			var requestDelay time.Duration
 			chunkState := fd.ChunkStates[chunkIdx]
-			switch chunkState {
-			case "":
+			switch {
+			case chunkState == "":
 				// Safe to request again.
 				actionToTake = actRequest
 
-			case clientdb.ChunkStateRequestedChunk:
+			case prevSrcIdx < 0 && (chunkState == clientdb.ChunkStateRequestedChunk ||
+				chunkState == clientdb.ChunkStateHasInvoice ||
+				chunkState == clientdb.ChunkStateInvoiceExpired):
+				// The source of this chunk is no longer
+				// available. Request it from another one.
+				actionToTake = actRequest
+
+			case chunkState == clientdb.ChunkStateRequestedChunk:
 				// Request again if it's been at least one day
 				// since we last requested (to avoid sending
-				// multiple redundant requests).
+				// multiple redundant requests). When there are
+				// other sources, request it from the next one
+				// sooner, in case the previous source went
+				// offline.
 				var chunkUpdtTime time.Time
 				if fd.ChunkUpdatedTime != nil {
 					chunkUpdtTime = fd.ChunkUpdatedTime[chunkIdx]
 				}
-				if chunkUpdtTime.Before(time.Now().Add(-time.Hour * 24)) {
+				timeout := time.Hour * 24
+				if len(srcs) > 1 {
+					timeout = swarmChunkRequestTimeout
+				}
+				if chunkUpdtTime.Before(time.Now().Add(-timeout)) {
 					actionToTake = actRequest
+					src = srcs[(prevSrcIdx+1)%len(srcs)]
 				}
 
-			case clientdb.ChunkStateHasInvoice:
+			case chunkState == clientdb.ChunkStateHasInvoice:
 				// Have invoice, but haven't tried paying. See
 				// if it's still valid to attempt payment.
+				src = srcs[prevSrcIdx]
 				invoice := fd.GetChunkInvoice(chunkIdx)
 				decoded, err := c.pc.DecodeInvoice(c.ctx, invoice)
 				if err != nil {
 					return fmt.Errorf("unable to decode chunk invoice: %v", err)
 				}
 
-				if decoded.IsExpired(0) {
+				if decoded.IsExpired(rpc.InvoiceExpiryAffordance) {
+					// Request a new invoice.
+					retries, err := c.db.MarkFileDownloadChunkInvoiceExpired(
+						tx, &fd, chunkIdx)
+					if err != nil {
+						return err
+					}
 					actionToTake = actRequest
+					expiredInvoice = invoice
+					requestDelay = chunkInvoiceRetryBackoff(retries)
 				} else {
 					actionToTake = actSendPayment
 					payMAtoms = decoded.MAtoms
 				}
 
-			case clientdb.ChunkStatePayingInvoice:
+			case chunkState == clientdb.ChunkStateInvoiceExpired:
+				// Invoice expired and a new one wasn't received
+				// yet. Request a new one (after waiting the
+				// backoff time since the last attempt).
+				src = srcs[prevSrcIdx]
+				expiredInvoice = fd.GetChunkInvoice(chunkIdx)
+				actionToTake = actRequest
+				var chunkUpdtTime time.Time
+				if fd.ChunkUpdatedTime != nil {
+					chunkUpdtTime = fd.ChunkUpdatedTime[chunkIdx]
+				}
+				retries := fd.InvoiceRetries[chunkIdx]
+				requestDelay = time.Until(chunkUpdtTime.Add(
+					chunkInvoiceRetryBackoff(retries)))
+
+			case chunkState == clientdb.ChunkStatePayingInvoice:
 				// Already attempting to pay this invoice. Do
 				// nothing. Ordinarily, we would't expect to
 				// reach this state here, so log a warning for now.
@@ -542,7 +940,7 @@ func (c *Client) downloadChunks(ru *RemoteUser, fd clientdb.FileDownload) error
 				ru.log.Warnf("Chunk %d of file %s has in-flight payment",
 					chunkIdx, fd.FID)
 
-			case clientdb.ChunkStatePaid:
+			case chunkState == clientdb.ChunkStatePaid:
 				// Paid for chunk, but haven't received it yet.
 				// Wait for the remote client to send it.
 				//
@@ -552,16 +950,25 @@ func (c *Client) downloadChunks(ru *RemoteUser, fd clientdb.FileDownload) error
 					"but hasn't been received yet",
 					chunkIdx, fd.FID)
 
-			case clientdb.ChunkStateDownloaded:
+			case chunkState == clientdb.ChunkStateDownloaded:
 				// Already downloaded chunk, nothing to do.
 			}
 
 			// Actually take an action on this chunk.
+			srcFID, _ := fd.SourceFID(src.ID())
 			switch actionToTake {
 			case actRequest:
 				// Re-request it.
 				go func() {
-					err := c.requestFileChunk(ru, fd.FID, chunkIdx, *fd.Metadata)
+					if requestDelay > 0 {
+						select {
+						case <-time.After(requestDelay):
+						case <-c.ctx.Done():
+							return
+						}
+					}
+					err := c.requestFileChunk(src, srcFID, chunkIdx,
+						*fd.Metadata, expiredInvoice)
 					logErr(err, "Unable to request file chunk: %v")
 				}()
 
@@ -569,7 +976,7 @@ func (c *Client) downloadChunks(ru *RemoteUser, fd clientdb.FileDownload) error
 				// Attempt payment.
 				go func() {
 					invoice := fd.GetChunkInvoice(chunkIdx)
-					err := c.payFileChunkInvoice(ru, fd.FID,
+					err := c.payFileChunkInvoice(src, srcFID,
 						chunkIdx, invoice, payMAtoms)
 					logErr(err, "unable to pay for chunk: %v")
 				}()
@@ -577,6 +984,11 @@ func (c *Client) downloadChunks(ru *RemoteUser, fd clientdb.FileDownload) error
 
 			return nil
 		})
+		if errors.Is(err, errTransferPaused) {
+			ru.log.Infof("Stopped downloading chunks of paused download %s",
+				fd.FID)
+			return nil
+		}
 		if err != nil {
 			return err
 		}
@@ -592,6 +1004,7 @@ func (c *Client) downloadChunks(ru *RemoteUser, fd clientdb.FileDownload) error
 func (c *Client) handleFTGetReply(ru *RemoteUser, gr rpc.RMFTGetReply) error {
 	var fid clientdb.FileID = gr.Metadata.MetadataHash()
 	var fd clientdb.FileDownload
+	var collectionMismatch, compressedRange bool
 	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
 		var err error
 		fd, err = c.db.ReadFileDownload(tx, ru.ID(), fid)
@@ -599,11 +1012,35 @@ func (c *Client) handleFTGetReply(ru *RemoteUser, gr rpc.RMFTGetReply) error {
 			return err
 		}
 
+		// Ranges of compressed files cannot be fetched.
+		if fd.Partial && gr.Metadata.Compression() != "" {
+			compressedRange = true
+			return c.db.CancelFileDownload(tx, fid)
+		}
+
+		// Files of collections must have the cost and size listed in
+		// the signed collection, which is what the user confirmed.
+		if fd.Collection != "" && (gr.Metadata.Cost != fd.CollectionCost ||
+			gr.Metadata.Size != fd.CollectionSize) {
+			collectionMismatch = true
+			return c.db.CancelFileDownload(tx, fid)
+		}
+
 		return c.db.UpdateFileDownloadMetadata(tx, &fd, gr.Metadata)
 	})
 	if err != nil {
 		return err
 	}
+	if compressedRange {
+		return fmt.Errorf("cannot fetch range of file %s compressed "+
+			"with %q", fid, gr.Metadata.Compression())
+	}
+	if collectionMismatch {
+		return fmt.Errorf("metadata of file %s (cost %d, size %d) does "+
+			"not match collection %s (cost %d, size %d)", fid,
+			gr.Metadata.Cost, gr.Metadata.Size, fd.Collection,
+			fd.CollectionCost, fd.CollectionSize)
+	}
 
 	// Ignore this request when download is supposed to be entirely sent
 	// by the uploader.
@@ -612,10 +1049,15 @@ func (c *Client) handleFTGetReply(ru *RemoteUser, gr rpc.RMFTGetReply) error {
 			fid)
 	}
 
-	// Ask user for confirmation before downloading file (specially
-	// due to cost).
-	if c.cfg.FileDownloadConfirmer != nil {
-		if !c.cfg.FileDownloadConfirmer(ru, gr.Metadata) {
+	// Check the download rules or ask user for confirmation before
+	// downloading file (specially due to cost). Files of collections were
+	// confirmed when the download of the collection was requested.
+	if fd.Collection == "" {
+		accept, err := c.confirmFileDownload(ru, gr.Metadata)
+		if err != nil {
+			return err
+		}
+		if !accept {
 			// Canceled. Remove download.
 			ru.log.Infof("User canceled download of file %s", fid)
 			return c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
@@ -624,6 +1066,11 @@ func (c *Client) handleFTGetReply(ru *RemoteUser, gr rpc.RMFTGetReply) error {
 		}
 	}
 
+	if fd.Paused {
+		ru.log.Infof("Received metadata of paused download %s", fid)
+		return nil
+	}
+
 	// Fetched metadata for the given file. Request chunks.
 	go func() {
 		err := c.downloadChunks(ru, fd)
@@ -641,9 +1088,19 @@ func (c *Client) sendFileChunk(ru *RemoteUser, sf clientdb.SharedFile,
 	chunkIdx int, cid clientdb.ChunkID, tag uint32) error {
 
 	var data []byte
+	var proof [][]byte
+	var md rpc.FileMetadata
 	err := c.dbView(func(tx clientdb.ReadTx) error {
 		var err error
+		_, md, err = c.db.GetSharedFileForUpload(tx, ru.ID(), sf.FID)
+		if err != nil {
+			return err
+		}
 		data, err = c.db.GetSharedFileChunkData(tx, &sf, chunkIdx)
+		if err != nil {
+			return err
+		}
+		_, proof, err = c.db.GetSharedFileChunkProof(tx, &sf, chunkIdx)
 		return err
 	})
 	if err != nil {
@@ -655,6 +1112,7 @@ func (c *Client) sendFileChunk(ru *RemoteUser, sf clientdb.SharedFile,
 		Index:  chunkIdx,
 		Chunk:  data,
 		Tag:    tag,
+		Proof:  proof,
 	}
 	payEvent := fmt.Sprintf("ftchunkupload.%s.%d", sf.FID.ShortLogID(), chunkIdx)
 	err = ru.sendRMPriority(rm, payEvent, priorityUpload)
@@ -665,9 +1123,21 @@ func (c *Client) sendFileChunk(ru *RemoteUser, sf clientdb.SharedFile,
 	ru.log.Debugf("Sent chunk %d of file %s to remote user", chunkIdx, sf.FID)
 
 	// Sent successfully (to server)! Mark chunk as sent.
-	return c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
-		return c.db.MarkChunkUploadSent(tx, ru.ID(), sf.FID, cid, chunkIdx)
+	var stats clientdb.FileUploadStats
+	err = c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		var err error
+		stats, err = c.db.MarkChunkUploadSent(tx, ru.ID(), sf.FID, cid,
+			chunkIdx, len(data))
+		return err
 	})
+	if err != nil {
+		return err
+	}
+
+	if c.cfg.FileUploadProgress != nil {
+		c.cfg.FileUploadProgress(ru, md, stats)
+	}
+	return nil
 }
 
 // ftPaymentForChunkCompleted is called as a callback when the payment for the
@@ -677,13 +1147,23 @@ func (c *Client) ftPaymentForChunkCompleted(ru *RemoteUser, sf clientdb.SharedFi
 
 	// Mark payment as completed on the DB.
 	uid := ru.ID()
+	var paused bool
+	var stats clientdb.FileUploadStats
+	var md rpc.FileMetadata
 	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
-		err := c.db.MarkChunkUploadPaid(tx, uid, sf.FID, cid, chunkIdx, invoice)
+		var err error
+		stats, err = c.db.MarkChunkUploadPaid(tx, uid, sf.FID, cid, chunkIdx,
+			invoice, receivedMAtoms)
 		if err != nil {
 			return err
 		}
+		paused = c.db.IsFileUploadPaused(tx, uid, sf.FID)
 		payEvent := fmt.Sprintf("ftrecvforchunk.%s.%d", sf.FID.ShortLogID(), chunkIdx)
-		return c.db.RecordUserPayEvent(tx, ru.ID(), payEvent, receivedMAtoms, 0)
+		if err := c.db.RecordUserPayEvent(tx, ru.ID(), payEvent, receivedMAtoms, 0); err != nil {
+			return err
+		}
+		_, md, err = c.db.GetSharedFileForUpload(tx, uid, sf.FID)
+		return err
 	})
 	if err != nil {
 		return err
@@ -692,6 +1172,15 @@ func (c *Client) ftPaymentForChunkCompleted(ru *RemoteUser, sf clientdb.SharedFi
 	ru.log.Debugf("Marked chunk %d of file %s paid by remote user",
 		chunkIdx, sf.FID)
 
+	if c.cfg.FileUploadProgress != nil {
+		c.cfg.FileUploadProgress(ru, md, stats)
+	}
+
+	if paused {
+		// The chunk will be sent once the upload is resumed.
+		return nil
+	}
+
 	// Attempt to send chunk to remote user.
 	return c.sendFileChunk(ru, sf, chunkIdx, cid, 0)
 }
@@ -769,10 +1258,6 @@ func (c *Client) handleFTGetChunk(ru *RemoteUser, gc rpc.RMFTGetChunk) error {
 	}
 
 	var cid clientdb.ChunkID
-	if err := cid.FromBytes(gc.Hash); err != nil {
-		return err
-	}
-
 	var f clientdb.SharedFile
 	var md rpc.FileMetadata
 	var inv string
@@ -784,10 +1269,27 @@ func (c *Client) handleFTGetChunk(ru *RemoteUser, gc rpc.RMFTGetChunk) error {
 			return err
 		}
 
-		// Ensure chunk index is correct.
+		// Ensure chunk index is correct. Requests for chunks of files
+		// with Merkle metadata do not include the chunk hash, so use
+		// the one from the local manifest.
 		if !clientintf.ChunkIndexMatches(&md, chunkIdx, gc.Hash) {
 			return fmt.Errorf("data does not hash to specified chunk index")
 		}
+		chunkHash, _, err := c.db.GetSharedFileChunkProof(tx, &f, chunkIdx)
+		if err != nil {
+			return err
+		}
+		if err := cid.FromBytes(chunkHash); err != nil {
+			return err
+		}
+
+		// Track the request to process it once the upload is resumed.
+		if c.db.IsFileUploadPaused(tx, ru.ID(), fid) {
+			if err := c.db.AddChunkUploadRequest(tx, ru.ID(), fid, cid, chunkIdx); err != nil {
+				return err
+			}
+			return errTransferPaused
+		}
 
 		// Generate invoice for the given amount.
 		amountMAtoms := clientintf.FileChunkMAtoms(chunkIdx, &md)
@@ -798,14 +1300,31 @@ func (c *Client) handleFTGetChunk(ru *RemoteUser, gc rpc.RMFTGetChunk) error {
 
 		// See if there's an existing, unexpired, unpaid invoice.
 		cup, err := c.db.GetFileChunkUpload(tx, ru.ID(), fid, cid)
+
+		// The remote user is asking for a new invoice because the
+		// previous one expired before it could be paid. Drop it
+		// from the outstanding invoices of the chunk.
+		if err == nil && gc.ExpiredInvoice != "" && cup.HasInvoice(gc.ExpiredInvoice) {
+			decoded, decodeErr := c.pc.DecodeInvoice(c.ctx, gc.ExpiredInvoice)
+			if decodeErr == nil && decoded.IsExpired(rpc.InvoiceExpiryAffordance) {
+				ru.log.Infof("Renegotiating expired invoice for "+
+					"chunk %d of file %s", chunkIdx, fid)
+				err = c.db.MarkChunkUploadInvoiceExpired(tx,
+					ru.ID(), fid, cid, chunkIdx, gc.ExpiredInvoice)
+				if err != nil {
+					return err
+				}
+				cup, err = c.db.GetFileChunkUpload(tx, ru.ID(), fid, cid)
+			}
+		}
 		if err == nil && len(cup.Invoices) > 0 {
 			oldInv := cup.Invoices[len(cup.Invoices)-1]
-			err := c.pc.IsInvoicePaid(c.ctx, int64(amountMAtoms), inv)
+			err := c.pc.IsInvoicePaid(c.ctx, int64(amountMAtoms), oldInv)
 			// err == nil only if the invoice is settled (in which
 			// case we still want to generate a new one).
 			if err != nil {
 				decodedInv, err := c.pc.DecodeInvoice(c.ctx, oldInv)
-				if err == nil && !decodedInv.IsExpired(0) {
+				if err == nil && !decodedInv.IsExpired(rpc.InvoiceExpiryAffordance) {
 					// Invoice is unsettled and hasn't
 					// expired. Use it.
 					inv = oldInv
@@ -818,6 +1337,11 @@ func (c *Client) handleFTGetChunk(ru *RemoteUser, gc rpc.RMFTGetChunk) error {
 			chunkIdx, amountMAtoms)
 		return err
 	})
+	if errors.Is(err, errTransferPaused) {
+		ru.log.Debugf("Received request for chunk %d of paused upload %s",
+			chunkIdx, fid)
+		return nil
+	}
 	if err != nil {
 		return err
 	}
@@ -847,8 +1371,12 @@ func (c *Client) handleFTPayForChunk(ru *RemoteUser, pfc rpc.RMFTPayForChunk) er
 	}
 
 	chunkIdx := pfc.Index
+	var paused bool
+	var fd clientdb.FileDownload
+	var expiredRetries int
 	err = c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
-		fd, err := c.db.ReadFileDownload(tx, ru.ID(), fid)
+		var err error
+		fd, err = c.db.ReadFileDownloadBySource(tx, ru.ID(), fid)
 		if err != nil {
 			return err
 		}
@@ -858,6 +1386,11 @@ func (c *Client) handleFTPayForChunk(ru *RemoteUser, pfc rpc.RMFTPayForChunk) er
 				chunkIdx)
 		}
 
+		if fd.ChunkSource(chunkIdx) != ru.ID() {
+			return fmt.Errorf("chunk %d was not requested from user",
+				chunkIdx)
+		}
+
 		if !clientintf.ChunkIndexMatches(fd.Metadata, chunkIdx, pfc.Hash) {
 			return fmt.Errorf("data does not hash to specified chunk index")
 		}
@@ -871,26 +1404,59 @@ func (c *Client) handleFTPayForChunk(ru *RemoteUser, pfc rpc.RMFTPayForChunk) er
 		}
 
 		// TODO: check whether the invoice has a payment attempt in
-		// flight or is already expired.
+		// flight.
 
-		// Double check amount to pay for chunk.
-		wantMAtoms := clientintf.FileChunkMAtoms(chunkIdx, fd.Metadata)
+		// Double check amount to pay for chunk. Additional sources
+		// may not charge more than the cost confirmed for the download.
+		wantMAtoms := clientintf.FileChunkMAtoms(chunkIdx, fd.SourceMetadata(ru.ID()))
 		if uint64(inv.MAtoms) != wantMAtoms {
 			return fmt.Errorf("unexpected value of invoice (got %d, want %d)",
 				inv.MAtoms, wantMAtoms)
 		}
+		maxMAtoms := clientintf.FileChunkMAtoms(chunkIdx, fd.Metadata)
+		if uint64(inv.MAtoms) > maxMAtoms {
+			return fmt.Errorf("invoice value %d exceeds confirmed "+
+				"chunk cost %d", inv.MAtoms, maxMAtoms)
+		}
 
 		// Replace the outstanding invoice for this chunk.
 		invoices := map[int]string{chunkIdx: pfc.Invoice}
 		if err := c.db.ReplaceFileDownloadInvoices(tx, &fd, invoices); err != nil {
 			return err
 		}
+		paused = fd.Paused
+
+		// The invoice may have expired while this client was
+		// offline. In that case, a new one needs to be requested.
+		if inv.IsExpired(rpc.InvoiceExpiryAffordance) {
+			expiredRetries, err = c.db.MarkFileDownloadChunkInvoiceExpired(
+				tx, &fd, chunkIdx)
+		}
 		return err
 	})
 	if err != nil {
 		return err
 	}
 
+	if expiredRetries > 0 {
+		if paused {
+			// A new invoice will be requested once the download
+			// is resumed.
+			return nil
+		}
+		c.retryExpiredChunkInvoice(ru, fid, &fd, chunkIdx, pfc.Invoice,
+			expiredRetries)
+		return nil
+	}
+
+	if paused {
+		// The invoice will be paid (if it hasn't expired) once the
+		// download is resumed.
+		ru.log.Debugf("Received invoice for chunk %d of paused download %s",
+			chunkIdx, fid)
+		return nil
+	}
+
 	// Start to pay for this chunk.
 	return c.payFileChunkInvoice(ru, fid, chunkIdx, pfc.Invoice, inv.MAtoms)
 }
@@ -906,14 +1472,25 @@ func (c *Client) handleFTGetChunkReply(ru *RemoteUser, gcr rpc.RMFTGetChunkReply
 	var fd clientdb.FileDownload
 	var completedFname string
 	var nbMissingChunks int
+	downRU := ru
 	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
 		var err error
-		fd, err = c.db.ReadFileDownload(tx, ru.ID(), fid)
+		fd, err = c.db.ReadFileDownloadBySource(tx, ru.ID(), fid)
 		if err != nil {
 			return err
 		}
 
-		completedFname, err = c.db.SaveFileDownloadChunk(tx, ru.Nick(), &fd, gcr.Index, gcr.Chunk)
+		// Downloads are always saved (and reported) as coming from
+		// the original uploader, even if this chunk came from a
+		// different source.
+		if fd.UID != ru.ID() {
+			if origRU, err := c.rul.byID(fd.UID); err == nil {
+				downRU = origRU
+			}
+		}
+
+		completedFname, err = c.db.SaveFileDownloadChunk(tx, downRU.Nick(), &fd,
+			gcr.Index, gcr.Chunk, gcr.Proof)
 		nbMissingChunks = len(c.db.MissingFileDownloadChunks(tx, &fd))
 		return err
 	})
@@ -923,15 +1500,27 @@ func (c *Client) handleFTGetChunkReply(ru *RemoteUser, gcr rpc.RMFTGetChunkReply
 
 	ru.log.Debugf("Downloaded chunk %d of file %s", gcr.Index, fd.FID)
 
+	if err := c.completeFileRanges(downRU, &fd); err != nil {
+		return err
+	}
+
 	if completedFname != "" {
 		baseName := filepath.Base(completedFname)
 		ru.log.Infof("Completed file download %q (%s, saved as %q",
 			fd.Metadata.Filename, fd.FID, baseName)
 		if c.cfg.FileDownloadCompleted != nil {
-			c.cfg.FileDownloadCompleted(ru, *fd.Metadata, completedFname)
+			fm := *fd.Metadata
+			if fm.MIMEType() == "" && fd.MIMEType != "" {
+				fm.Attributes = make(map[string]string, len(fd.Metadata.Attributes)+1)
+				for k, v := range fd.Metadata.Attributes {
+					fm.Attributes[k] = v
+				}
+				fm.Attributes[rpc.FileAttrMIMEType] = fd.MIMEType
+			}
+			c.cfg.FileDownloadCompleted(downRU, fm, completedFname)
 		}
 	} else if c.cfg.FileDownloadProgress != nil {
-		c.cfg.FileDownloadProgress(ru, *fd.Metadata, nbMissingChunks)
+		c.cfg.FileDownloadProgress(downRU, *fd.Metadata, nbMissingChunks)
 	}
 	return err
 }
@@ -970,11 +1559,16 @@ func (c *Client) SendFile(uid UserID, filepath string) error {
 	}
 
 	// Send the chunks.
-	for i := range fm.Manifest {
+	for i := 0; i < fm.ChunkCount(); i++ {
 		var chunk []byte
+		var proof [][]byte
 		err := c.dbView(func(tx clientdb.ReadTx) error {
 			var err error
 			chunk, err = c.db.GetSharedFileChunkData(tx, &sf, i)
+			if err != nil {
+				return err
+			}
+			_, proof, err = c.db.GetSharedFileChunkProof(tx, &sf, i)
 			return err
 		})
 		if err != nil {
@@ -985,6 +1579,7 @@ func (c *Client) SendFile(uid UserID, filepath string) error {
 			FileID: sf.FID.String(),
 			Index:  i,
 			Chunk:  chunk,
+			Proof:  proof,
 		}
 		payEvent := fmt.Sprintf("ftsendfile.%s.%d", sf.FID.ShortLogID(), i)
 		if err = c.sendWithSendQPriority(payEvent, rmSFC, priorityUpload, uid); err != nil {
@@ -999,8 +1594,20 @@ func (c *Client) SendFile(uid UserID, filepath string) error {
 func (c *Client) handleFTSendFile(ru *RemoteUser, sf rpc.RMFTSendFile) error {
 	var fid clientdb.FileID = sf.Metadata.MetadataHash()
 
+	accept, err := c.confirmFileDownload(ru, sf.Metadata)
+	if err != nil {
+		return err
+	}
+	if !accept {
+		ru.log.Infof("Rejected remote-user-initiated download of %q (%s)",
+			sf.Metadata.Filename, fid)
+		rm := rpc.RMFTCancel{FileID: fid.String()}
+		payEvent := fmt.Sprintf("ftcancel.%s", fid.ShortLogID())
+		return c.sendWithSendQ(payEvent, rm, ru.ID())
+	}
+
 	// Store that we'll receive this file.
-	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+	err = c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
 		fd, err := c.db.StartFileDownload(tx, ru.ID(), fid, true)
 		if err != nil {
 			return err
@@ -1032,6 +1639,11 @@ func (c *Client) restartDownloads(ctx context.Context) error {
 
 	for _, fd := range fds {
 		fd := fd
+		if fd.Paused || fd.IsSentFile {
+			// Chunks of sent files are pushed by the uploader, so
+			// there's nothing to request.
+			continue
+		}
 		ru, err := c.rul.byID(fd.UID)
 		if err != nil {
 			// This could happen if we removed the ratchet/user
@@ -1054,8 +1666,115 @@ func (c *Client) restartDownloads(ctx context.Context) error {
 	return nil
 }
 
+// processChunkUpload takes the appropriate action on an outstanding chunk
+// upload, depending on the state of its invoices. It is used both when
+// restarting uploads and when resuming paused uploads.
+//
+// The messages that need to be sent to the remote user are not sent from
+// within the DB transaction. Instead, the returned function (which may be nil)
+// must be called after the transaction is committed to send them.
+func (c *Client) processChunkUpload(ctx context.Context, tx clientdb.ReadWriteTx,
+	ru *RemoteUser, cup clientdb.ChunkUpload) (func(), error) {
+
+	sf, md, err := c.db.GetSharedFileForUpload(tx, cup.UID, cup.FID)
+	if err != nil {
+		c.log.Warnf("Unable to fetch file for chunk upload: %v", err)
+		return nil, nil
+	}
+
+	chunkIdx := cup.Index
+
+	// This chunk can be in one of several states:
+	// - Requested while the upload was paused
+	// - Invoices generated but unsent
+	// - Invoices sent but unpaid
+	// - Invoices expired (sent or unsent)
+	// - Invoices paid
+
+	wantMAtoms := int64(clientintf.FileChunkMAtoms(chunkIdx, &md))
+
+	// First: Verify how many invoices were paid or expired.
+	unexpiredInvoice := ""
+	for _, inv := range cup.Invoices {
+		err := c.pc.IsInvoicePaid(ctx, wantMAtoms, inv)
+		if err == nil {
+			// Paid! Increase nb of paid invoices.
+			_, err := c.db.MarkChunkUploadPaid(tx,
+				cup.UID, cup.FID, cup.CID, chunkIdx, inv, wantMAtoms)
+			if err != nil {
+				return nil, err
+			}
+
+			payEvent := fmt.Sprintf("ftrecvforchunk.%s.%d",
+				cup.FID.ShortLogID(), chunkIdx)
+			err = c.db.RecordUserPayEvent(tx, cup.UID,
+				payEvent, wantMAtoms, 0)
+			if err != nil {
+				return nil, err
+			}
+
+			cup.Paid += 1
+			continue
+		}
+
+		// Unpaid. If it's expired, remove it.
+		decoded, err := c.pc.DecodeInvoice(c.ctx, inv)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode chunk invoice: %v", err)
+		}
+		if decoded.IsExpired(0) {
+			err := c.db.MarkChunkUploadInvoiceExpired(tx,
+				cup.UID, cup.FID, cup.CID, chunkIdx, inv)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			unexpiredInvoice = inv
+		}
+	}
+
+	// Re-generate invoice if it was requested (including while the upload
+	// was paused), it expired without being paid and was not sent.
+	if unexpiredInvoice == "" && cup.Paid == 0 && cup.State != clientdb.ChunkStateSentInvoice {
+		// Generate a new one.
+		unexpiredInvoice, err = c.genInvoiceForFTUpload(tx, ru, sf, cup.CID,
+			chunkIdx, uint64(wantMAtoms))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// If we still have an unexpired, unsent invoice, send it.
+	sendInvoice := unexpiredInvoice != "" && cup.Paid == 0 &&
+		cup.State != clientdb.ChunkStateSentInvoice
+	if !sendInvoice && cup.Paid == 0 {
+		return nil, nil
+	}
+
+	paid := cup.Paid
+	send := func() {
+		if sendInvoice {
+			go c.sendInvoiceForChunk(ru, sf.FID, unexpiredInvoice, chunkIdx, cup.CID)
+		}
+
+		// Then: send as many copies of the chunk as were
+		// already paid (but unsent).
+		for i := 0; i < paid; i++ {
+			go func() {
+				err := c.sendFileChunk(ru, sf, chunkIdx, cup.CID, 0)
+				if err != nil && !errors.Is(err, clientintf.ErrSubsysExiting) {
+					ru.log.Errorf("Unable to send chunk %s: %v",
+						cup.CID, err)
+				}
+			}()
+		}
+	}
+	return send, nil
+}
+
 // restartUploads is called during client startup to restart all uploads.
 func (c *Client) restartUploads(ctx context.Context) error {
+	var sends []func()
 	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
 		cups, err := c.db.ListOutstandingUploads(tx)
 		if err != nil {
@@ -1063,7 +1782,6 @@ func (c *Client) restartUploads(ctx context.Context) error {
 		}
 
 		for _, cup := range cups {
-			cup := cup
 			ru, err := c.rul.byID(cup.UID)
 			if err != nil {
 				c.log.Warnf("Chunk upload found for unknown user %s",
@@ -1071,95 +1789,30 @@ func (c *Client) restartUploads(ctx context.Context) error {
 				continue
 			}
 
-			sf, md, err := c.db.GetSharedFileForUpload(tx, cup.UID, cup.FID)
-			if err != nil {
-				c.log.Warnf("Unable to fetch file for chunk upload: %v", err)
+			if c.db.IsFileUploadPaused(tx, cup.UID, cup.FID) {
 				continue
 			}
 
-			chunkIdx := cup.Index
-
-			// This chunk can be in one of several states:
-			// - Invoices generated but unsent
-			// - Invoices sent but unpaid
-			// - Invoices expired (sent or unsent)
-			// - Invoices paid
-
-			wantMAtoms := int64(clientintf.FileChunkMAtoms(chunkIdx, &md))
-
-			// First: Verify how many invoices were paid or expired.
-			unexpiredInvoice := ""
-			for _, inv := range cup.Invoices {
-				err := c.pc.IsInvoicePaid(ctx, wantMAtoms, inv)
-				if err == nil {
-					// Paid! Increase nb of paid invoices.
-					err := c.db.MarkChunkUploadPaid(tx,
-						cup.UID, cup.FID, cup.CID, chunkIdx, inv)
-					if err != nil {
-						return err
-					}
-
-					payEvent := fmt.Sprintf("ftrecvforchunk.%s.%d",
-						cup.FID.ShortLogID(), chunkIdx)
-					err = c.db.RecordUserPayEvent(tx, cup.UID,
-						payEvent, wantMAtoms, 0)
-					if err != nil {
-						return err
-					}
-
-					cup.Paid += 1
-					continue
-				}
-
-				// Unpaid. If it's expired, remove it.
-				decoded, err := c.pc.DecodeInvoice(c.ctx, inv)
-				if err != nil {
-					return fmt.Errorf("unable to decode chunk invoice: %v", err)
-				}
-				if decoded.IsExpired(0) {
-					err := c.db.MarkChunkUploadInvoiceExpired(tx,
-						cup.UID, cup.FID, cup.CID, chunkIdx, inv)
-					if err != nil {
-						return err
-					}
-				} else {
-					unexpiredInvoice = inv
-				}
-			}
-
-			// Re-generate invoice if it was requested, it expired
-			// without being paid and was not sent.
-			if unexpiredInvoice == "" && cup.Paid == 0 && cup.State != clientdb.ChunkStateSentInvoice {
-				// Generate a new one.
-				unexpiredInvoice, err = c.genInvoiceForFTUpload(tx, ru, sf, cup.CID,
-					chunkIdx, uint64(wantMAtoms))
-				if err != nil {
-					return err
-				}
-			}
-
-			// If we still have an unexpired, unsent invoice, send it.
-			if unexpiredInvoice != "" && cup.Paid == 0 && cup.State != clientdb.ChunkStateSentInvoice {
-				go c.sendInvoiceForChunk(ru, sf.FID, unexpiredInvoice, chunkIdx, cup.CID)
+			send, err := c.processChunkUpload(ctx, tx, ru, cup)
+			if err != nil {
+				return err
 			}
-
-			// Then: send as many copies of the chunk as were
-			// already paid (but unsent).
-			for i := 0; i < cup.Paid; i++ {
-				go func() {
-					err := c.sendFileChunk(ru, sf, chunkIdx, cup.CID, 0)
-					if err != nil && !errors.Is(err, clientintf.ErrSubsysExiting) {
-						ru.log.Errorf("Unable to send chunk %s: %v",
-							cup.CID, err)
-					}
-				}()
+			if send != nil {
+				sends = append(sends, send)
 			}
-
-			// Small sleep to bias uploading sequentially.
-			time.Sleep(100 * time.Millisecond)
 		}
 
 		return err
 	})
-	return err
+	if err != nil {
+		return err
+	}
+
+	for _, send := range sends {
+		send()
+
+		// Small sleep to bias uploading sequentially.
+		time.Sleep(100 * time.Millisecond)
+	}
+	return nil
 }
@@ -0,0 +1,94 @@
+package client
+
+import (
+	"github.com/companyzero/bisonrelay/client/clientdb"
+	"github.com/companyzero/bisonrelay/rpc"
+	"github.com/companyzero/bisonrelay/zkidentity"
+)
+
+// ListDownloadRules lists the rules used to automatically accept or reject
+// file downloads, in the order they are evaluated.
+func (c *Client) ListDownloadRules() ([]clientdb.DownloadRule, error) {
+	var rules []clientdb.DownloadRule
+	err := c.dbView(func(tx clientdb.ReadTx) error {
+		var err error
+		rules, err = c.db.ListDownloadRules(tx)
+		return err
+	})
+	return rules, err
+}
+
+// AddDownloadRule adds a rule to automatically accept or reject file
+// downloads. Rules are evaluated in the order they were added and the first
+// matching rule is used.
+func (c *Client) AddDownloadRule(rule clientdb.DownloadRule) (clientdb.DownloadRule, error) {
+	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		var err error
+		rule, err = c.db.AddDownloadRule(tx, rule)
+		return err
+	})
+	return rule, err
+}
+
+// RemoveDownloadRule removes the download rule with the given ID.
+func (c *Client) RemoveDownloadRule(id uint64) error {
+	return c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		return c.db.RemoveDownloadRule(tx, id)
+	})
+}
+
+// MatchDownloadRule returns the first download rule that matches a file with
+// the given metadata offered by the given user. It returns false if no rule
+// matches the file.
+func (c *Client) MatchDownloadRule(uid UserID, fm rpc.FileMetadata) (clientdb.DownloadRule, bool, error) {
+	var rules []clientdb.DownloadRule
+	var gcs []clientdb.GCAddressBookEntry
+	err := c.dbView(func(tx clientdb.ReadTx) error {
+		var err error
+		rules, err = c.db.ListDownloadRules(tx)
+		if err != nil || len(rules) == 0 {
+			return err
+		}
+		gcs, err = c.db.ListGCs(tx)
+		return err
+	})
+	if err != nil {
+		return clientdb.DownloadRule{}, false, err
+	}
+
+	var userGCs []zkidentity.ShortID
+	for _, gc := range gcs {
+		for _, member := range gc.Members {
+			if member == uid {
+				userGCs = append(userGCs, gc.ID)
+				break
+			}
+		}
+	}
+
+	for _, rule := range rules {
+		if rule.Matches(uid, userGCs, &fm) {
+			return rule, true, nil
+		}
+	}
+	return clientdb.DownloadRule{}, false, nil
+}
+
+// confirmFileDownload decides whether to download the given file offered by
+// the remote user. The download rules are checked first and, if none matches
+// the file, the FileDownloadConfirmer callback is used.
+func (c *Client) confirmFileDownload(ru *RemoteUser, fm rpc.FileMetadata) (bool, error) {
+	rule, ok, err := c.MatchDownloadRule(ru.ID(), fm)
+	if err != nil {
+		return false, err
+	}
+	if ok {
+		ru.log.Infof("Download rule %d %ss download of %q", rule.ID,
+			rule.Action, fm.Filename)
+		return rule.Action == clientdb.DownloadRuleAccept, nil
+	}
+	if c.cfg.FileDownloadConfirmer != nil {
+		return c.cfg.FileDownloadConfirmer(ru, fm), nil
+	}
+	return true, nil
+}
@@ -0,0 +1,94 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/companyzero/bisonrelay/client/clientdb"
+	"github.com/companyzero/bisonrelay/client/clientintf"
+)
+
+// SaveDraft saves the given post or comment draft. If the draft does not have
+// an ID, a new one is generated and the draft is created. Otherwise, the
+// existing draft is replaced. The estimated size of the draft is recomputed
+// on every save.
+func (c *Client) SaveDraft(draft clientdb.PostDraft) (clientdb.PostDraft, error) {
+	if (draft.CommentFrom == nil) != (draft.CommentPID == nil) {
+		return draft, errors.New("comment drafts must specify both the " +
+			"post author and post id")
+	}
+	if draft.CommentParent != nil && draft.CommentPID == nil {
+		return draft, errors.New("only comment drafts may specify a parent")
+	}
+
+	var err error
+	draft.EstSize, err = clientintf.EstimatePostSize(draft.Content, draft.Descr)
+	if err != nil {
+		return draft, fmt.Errorf("unable to estimate draft size: %w", err)
+	}
+
+	draft.Updated = time.Now()
+	err = c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		if draft.ID.IsEmpty() {
+			draft.ID = clientintf.RandomID()
+			draft.Created = draft.Updated
+		} else {
+			old, err := c.db.ReadDraft(tx, draft.ID)
+			if err != nil {
+				return err
+			}
+			draft.Created = old.Created
+		}
+		return c.db.SaveDraft(tx, &draft)
+	})
+	return draft, err
+}
+
+// ListDrafts lists the existing post and comment drafts, sorted by last update
+// time.
+func (c *Client) ListDrafts() ([]clientdb.PostDraft, error) {
+	var res []clientdb.PostDraft
+	err := c.dbView(func(tx clientdb.ReadTx) error {
+		var err error
+		res, err = c.db.ListDrafts(tx)
+		return err
+	})
+	return res, err
+}
+
+// RemoveDraft removes the draft with the given id.
+func (c *Client) RemoveDraft(id clientintf.ID) error {
+	return c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		return c.db.RemoveDraft(tx, id)
+	})
+}
+
+// PublishDraft publishes the draft with the given id (either creating a new
+// post or commenting on an existing one) and removes the draft. The returned
+// summary is only filled when the draft is of a post.
+func (c *Client) PublishDraft(id clientintf.ID) (clientdb.PostSummary, error) {
+	var draft clientdb.PostDraft
+	err := c.dbView(func(tx clientdb.ReadTx) error {
+		var err error
+		draft, err = c.db.ReadDraft(tx, id)
+		return err
+	})
+	if err != nil {
+		return clientdb.PostSummary{}, err
+	}
+
+	var summ clientdb.PostSummary
+	if draft.IsComment() {
+		err = c.CommentPost(*draft.CommentFrom, *draft.CommentPID,
+			draft.Content, draft.CommentParent)
+	} else {
+		summ, err = c.CreatePost(draft.Content, draft.Descr)
+	}
+	if err != nil {
+		return summ, err
+	}
+
+	c.log.Debugf("Published draft %s", id)
+	return summ, c.RemoveDraft(id)
+}
@@ -0,0 +1,195 @@
+package client
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/companyzero/bisonrelay/client/clientdb"
+	"github.com/companyzero/bisonrelay/client/clientintf"
+	"github.com/companyzero/bisonrelay/client/internal/lowlevel"
+	"github.com/companyzero/bisonrelay/ratchet"
+	"github.com/companyzero/bisonrelay/rpc"
+	"github.com/companyzero/bisonrelay/zkidentity"
+)
+
+// errNoPushRate is returned by the estimation functions when the push payment
+// rate of the server is unknown.
+var errNoPushRate = errors.New("push payment rate is unknown while offline from server")
+
+// estimatePushCost estimates the cost (in milliatoms) of pushing the given
+// payload to nbRecipients remote users, given the current server push rate.
+//
+// The estimate does not include the fees required to route the payments.
+func (c *Client) estimatePushCost(payload interface{}, nbRecipients int) (uint64, error) {
+	c.svrRatesMtx.Lock()
+	pushRate, minPushPymt := c.svrPushRate, c.svrMinPushPymt
+	c.svrRatesMtx.Unlock()
+	if pushRate == 0 {
+		return 0, errNoPushRate
+	}
+	if nbRecipients == 0 {
+		return 0, nil
+	}
+
+	me, err := rpc.ComposeCompressedRM(c.id, payload, c.cfg.CompressLevel)
+	if err != nil {
+		return 0, err
+	}
+	if rpc.EstimateRoutedRMWireSize(len(me)) > rpc.MaxMsgSize {
+		return 0, fmt.Errorf("message %T estimated as larger than "+
+			"max message size %d > %d: %w", payload,
+			rpc.EstimateRoutedRMWireSize(len(me)),
+			rpc.MaxMsgSize, errRMTooLarge)
+	}
+
+	encLen := uint32(ratchet.EncryptedSize(len(me)))
+	amount := lowlevel.PushPaymentAmount(encLen, pushRate, minPushPymt)
+	return uint64(amount) * uint64(nbRecipients), nil
+}
+
+// countKnownUsers returns how many of the given users are known (i.e. have
+// completed KX with the local client), excluding the local client itself.
+// Messages are not sent (and thus not paid for) to unknown users.
+func (c *Client) countKnownUsers(uids []clientintf.UserID) int {
+	localID := c.PublicID()
+	var n int
+	for _, uid := range uids {
+		if uid == localID {
+			continue
+		}
+		if _, err := c.rul.byID(uid); err != nil {
+			continue
+		}
+		n++
+	}
+	return n
+}
+
+// EstimatePMCost estimates the cost (in milliatoms) of sending the given
+// message as a PM to the specified user.
+func (c *Client) EstimatePMCost(uid UserID, msg string) (uint64, error) {
+	if _, err := c.rul.byID(uid); err != nil {
+		return 0, err
+	}
+
+	rm := rpc.RMPrivateMessage{
+		Mode:    rpc.RMPrivateMessageModeNormal,
+		Message: msg,
+	}
+	return c.estimatePushCost(rm, 1)
+}
+
+// EstimateGCMessageCost estimates the cost (in milliatoms) of sending the
+// given message to all (non-blocked) members of the specified GC.
+func (c *Client) EstimateGCMessageCost(gcID zkidentity.ShortID, msg string) (uint64, error) {
+	var gc rpc.RMGroupList
+	var gcBlockList clientdb.GCBlockList
+	err := c.dbView(func(tx clientdb.ReadTx) error {
+		var err error
+		if gc, err = c.db.GetGC(tx, gcID); err != nil {
+			return err
+		}
+		gcBlockList, err = c.db.GetGCBlockList(tx, gcID)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	rm := rpc.RMGroupMessage{
+		ID:         gcID,
+		Generation: gc.Generation,
+		Message:    msg,
+		Mode:       rpc.MessageModeNormal,
+	}
+	members := gcBlockList.FilterMembers(gc.Members)
+	return c.estimatePushCost(rm, c.countKnownUsers(members))
+}
+
+// EstimatePostShareCost estimates the cost (in milliatoms) of creating a post
+// with the given content and description, which is shared with all current
+// post subscribers.
+func (c *Client) EstimatePostShareCost(post, descr string) (uint64, error) {
+	if post == "" {
+		return 0, errors.New("post cannot be empty")
+	}
+
+	subs, err := c.ListPostSubscribers()
+	if err != nil {
+		return 0, err
+	}
+
+	// Build the post metadata in the same way it is created by the DB, so
+	// that the estimated size matches the one of the actual post.
+	attrs := map[string]string{
+		rpc.RMPStatusFrom: c.id.Public.Identity.String(),
+		rpc.RMPFromNick:   c.id.Public.Nick,
+		rpc.RMPMain:       post,
+	}
+	if descr != "" {
+		attrs[rpc.RMPDescription] = descr
+	}
+	pm := rpc.PostMetadata{
+		Version:    rpc.PostMetadataVersion,
+		Attributes: attrs,
+	}
+	pmHash := pm.Hash()
+	signature := c.id.SignMessage(pmHash[:])
+	attrs[rpc.RMPSignature] = hex.EncodeToString(signature[:])
+	attrs[rpc.RMPIdentifier] = hex.EncodeToString(pmHash[:])
+
+	return c.estimatePushCost(rpc.RMPostShare(pm), c.countKnownUsers(subs))
+}
+
+// EstimateRelayCost estimates the cost (in milliatoms) of relaying the given
+// post (and its status updates) to the specified user. If toUser is nil, then
+// this estimates the cost of relaying the post to all current post
+// subscribers.
+func (c *Client) EstimateRelayCost(postFrom clientintf.UserID, pid clientintf.PostID,
+	toUser *clientintf.UserID) (uint64, error) {
+
+	var post rpc.PostMetadata
+	var updates []rpc.PostMetadataStatus
+	var subs []clientintf.UserID
+	err := c.dbView(func(tx clientdb.ReadTx) error {
+		var err error
+		post, err = c.db.ReadPost(tx, postFrom, pid)
+		if err != nil {
+			return err
+		}
+
+		updates, err = c.db.ListPostStatusUpdates(tx, postFrom, pid)
+		if err != nil {
+			return err
+		}
+
+		if toUser != nil {
+			subs = []clientintf.UserID{*toUser}
+			return nil
+		}
+		subs, err = c.db.ListPostSubscribers(tx)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	nbRecipients := c.countKnownUsers(subs)
+	total, err := c.estimatePushCost(rpc.RMPostShare(post), nbRecipients)
+	if err != nil {
+		return 0, err
+	}
+	for _, update := range updates {
+		rm := rpc.RMPostShare{
+			Version:    update.Version,
+			Attributes: update.Attributes,
+		}
+		cost, err := c.estimatePushCost(rm, nbRecipients)
+		if err != nil {
+			return 0, err
+		}
+		total += cost
+	}
+	return total, nil
+}
@@ -0,0 +1,145 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/companyzero/bisonrelay/client/clientdb"
+	"github.com/companyzero/bisonrelay/client/clientintf"
+	"github.com/companyzero/bisonrelay/client/internal/feedexport"
+	"github.com/companyzero/bisonrelay/rpc"
+)
+
+// FeedExportFormat is the format used to export posts.
+type FeedExportFormat string
+
+const (
+	// FeedExportAtom exports posts as an Atom feed file.
+	FeedExportAtom FeedExportFormat = "atom"
+
+	// FeedExportHTML exports posts as a static HTML site.
+	FeedExportHTML FeedExportFormat = "html"
+)
+
+// FeedExportOpts are the options used when exporting posts.
+type FeedExportOpts struct {
+	// Format is the format of the export.
+	Format FeedExportFormat
+
+	// Dest is the destination of the export. This is a file when exporting
+	// to Atom and a directory when exporting to HTML.
+	Dest string
+
+	// Authors restricts the export to posts authored by these users. If
+	// empty, all posts (both the local client's and the ones received
+	// from subscriptions) are exported.
+	Authors []UserID
+
+	// Title is the title of the exported feed.
+	Title string
+}
+
+// ExportFeed exports the local posts (along with their comments) to a format
+// that can be read outside the client. It returns the number of exported
+// posts.
+func (c *Client) ExportFeed(opts FeedExportOpts) (int, error) {
+	if opts.Dest == "" {
+		return 0, errors.New("export destination cannot be empty")
+	}
+	if opts.Format != FeedExportAtom && opts.Format != FeedExportHTML {
+		return 0, fmt.Errorf("unknown feed export format %q", opts.Format)
+	}
+	authors := make(map[UserID]struct{}, len(opts.Authors))
+	for _, uid := range opts.Authors {
+		authors[uid] = struct{}{}
+	}
+
+	feed := &feedexport.Feed{
+		Title: opts.Title,
+		ID:    "urn:bisonrelay:feed:" + c.PublicID().String(),
+	}
+	if feed.Title == "" {
+		feed.Title = "Bison Relay posts"
+	}
+
+	err := c.dbView(func(tx clientdb.ReadTx) error {
+		summaries, err := c.db.ListPosts(tx)
+		if err != nil {
+			return err
+		}
+
+		// The same post may have been received from multiple relayers,
+		// so only export one copy of each post, preferring the one
+		// received directly from its author.
+		type postKey struct {
+			author UserID
+			pid    clientintf.PostID
+		}
+		copies := make(map[postKey]clientdb.PostSummary)
+		var keys []postKey
+		for _, summ := range summaries {
+			if _, ok := authors[summ.AuthorID]; len(authors) > 0 && !ok {
+				continue
+			}
+			key := postKey{author: summ.AuthorID, pid: summ.ID}
+			old, ok := copies[key]
+			if !ok {
+				keys = append(keys, key)
+			}
+			if !ok || (old.From != old.AuthorID && summ.From == summ.AuthorID) {
+				copies[key] = summ
+			}
+		}
+
+		for _, key := range keys {
+			summ := copies[key]
+			post, err := c.db.ReadPost(tx, summ.From, summ.ID)
+			if err != nil {
+				return err
+			}
+			status, err := c.db.ListPostStatusUpdates(tx, summ.From, summ.ID)
+			if err != nil {
+				return err
+			}
+
+			// Include the body of unlocked paywalled posts.
+			if clientintf.PaywalledPostPrice(&post) > 0 {
+				content, err := c.db.ReadPaywalledPostContent(tx, summ.From, summ.ID)
+				if err == nil {
+					post.Attributes[rpc.RMPMain] += "\n\n" + content.Body
+				}
+			}
+
+			feed.Posts = append(feed.Posts, feedexport.Post{
+				Summary:  summ,
+				Metadata: post,
+				Status:   status,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	switch opts.Format {
+	case FeedExportAtom:
+		var b bytes.Buffer
+		if err := feedexport.WriteAtom(&b, feed); err != nil {
+			return 0, err
+		}
+		if err := os.WriteFile(opts.Dest, b.Bytes(), 0o600); err != nil {
+			return 0, err
+		}
+	case FeedExportHTML:
+		if err := feedexport.WriteHTML(opts.Dest, feed); err != nil {
+			return 0, err
+		}
+	}
+
+	c.log.Infof("Exported %d posts as %s to %s", len(feed.Posts), opts.Format,
+		opts.Dest)
+	return len(feed.Posts), nil
+}
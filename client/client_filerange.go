@@ -0,0 +1,174 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/companyzero/bisonrelay/client/clientdb"
+	"github.com/companyzero/bisonrelay/client/clientintf"
+	"github.com/companyzero/bisonrelay/rpc"
+)
+
+// FetchFileRange fetches only the chunks of the given file from the remote
+// user that are needed to read the bytes in the range [offset, offset+length).
+// Ranges that extend past the end of the file are truncated. This allows
+// previewing parts of large files (for example, the start of an audio or video
+// file) without paying for the entire file.
+//
+// Fetched chunks are kept and reused when the full file is later requested
+// with GetUserContent. Once all chunks of the range have been downloaded, an
+// OnFileRangeFetchedNtfn notification is emitted and the data may be read with
+// ReadFileRange.
+//
+// Ranges of compressed files cannot be fetched, because their chunks do not
+// hold the original contents of the file.
+func (c *Client) FetchFileRange(uid UserID, fid clientdb.FileID, offset, length uint64) error {
+	ru, err := c.rul.byID(uid)
+	if err != nil {
+		return err
+	}
+	if length == 0 {
+		return fmt.Errorf("length of range cannot be zero")
+	}
+
+	r := clientdb.FileRange{Offset: offset, Length: length}
+	var fd clientdb.FileDownload
+	var isNew bool
+	err = c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		var err error
+		fd, err = c.db.ReadFileDownload(tx, uid, fid)
+		if errors.Is(err, clientdb.ErrNotFound) {
+			fd, err = c.db.StartFileDownload(tx, uid, fid, false)
+			fd.Partial = true
+			isNew = true
+		}
+		if err != nil {
+			return err
+		}
+		if fd.CompletedName != "" {
+			return fmt.Errorf("download of file %s already completed", fid)
+		}
+		if fd.IsSentFile {
+			return fmt.Errorf("download %s is supposed to be uploader-sent",
+				fid)
+		}
+		return c.db.AddFileDownloadRange(tx, &fd, r)
+	})
+	if err != nil {
+		return err
+	}
+
+	ru.log.Infof("Fetching range [%d, %d) of file %s", offset, offset+length, fid)
+
+	if isNew {
+		// Request the metadata. The chunks of the range are requested
+		// once it is received.
+		rmftg := rpc.RMFTGet{
+			FileID: fid.String(),
+		}
+		payEvent := fmt.Sprintf("ftget.%s", fid.ShortLogID())
+		return ru.sendRM(rmftg, payEvent)
+	}
+
+	if fd.Metadata == nil || fd.Paused {
+		// The range will be fetched once the metadata is received or
+		// the download is resumed.
+		return nil
+	}
+
+	go func() {
+		err := c.downloadChunks(ru, fd)
+		if err != nil && !errors.Is(err, clientintf.ErrSubsysExiting) {
+			ru.log.Errorf("Unable to download file chunk: %v", err)
+		}
+	}()
+	return nil
+}
+
+// ReadFileRange returns the data in the range [offset, offset+length) of a
+// file being downloaded or already downloaded. All chunks of the range must
+// have been fetched (see FetchFileRange). Once the download is completed, the
+// data is read from the downloaded file. Ranges of compressed files may only
+// be read once their download is completed.
+func (c *Client) ReadFileRange(fid clientdb.FileID, offset, length uint64) ([]byte, error) {
+	r := clientdb.FileRange{Offset: offset, Length: length}
+	var data []byte
+	var completedPath string
+	err := c.dbView(func(tx clientdb.ReadTx) error {
+		fd, err := c.findDownload(tx, fid)
+		if errors.Is(err, clientdb.ErrNotFound) {
+			// Possibly already completed.
+			completedPath, err = c.db.HasDownloadedFile(tx, fid)
+			if err == nil && completedPath == "" {
+				err = fmt.Errorf("download of file %s: %w", fid,
+					clientdb.ErrNotFound)
+			}
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		data, err = c.db.ReadFileDownloadRange(tx, &fd, r)
+		return err
+	})
+	if err != nil || completedPath == "" {
+		return data, err
+	}
+
+	// Read the range from the completed file.
+	f, err := os.Open(completedPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	// Clamp the range to the size of the file before allocating the
+	// buffer.
+	size := uint64(fi.Size())
+	if length == 0 {
+		return nil, fmt.Errorf("empty file range")
+	}
+	if offset >= size {
+		return nil, fmt.Errorf("range offset %d is outside file of size %d",
+			offset, size)
+	}
+	if length > size-offset {
+		length = size - offset
+	}
+
+	data = make([]byte, length)
+	n, err := f.ReadAt(data, int64(offset))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+// completeFileRanges notifies about the requested ranges of the download that
+// have been fully fetched.
+func (c *Client) completeFileRanges(ru *RemoteUser, fd *clientdb.FileDownload) error {
+	if len(fd.Ranges) == 0 {
+		return nil
+	}
+	var completed []clientdb.FileRange
+	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		var err error
+		completed, err = c.db.CompleteFileDownloadRanges(tx, fd)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	for _, r := range completed {
+		ru.log.Debugf("Fetched range [%d, %d) of file %s", r.Offset,
+			r.Offset+r.Length, fd.FID)
+		c.ntfns.notifyFileRangeFetched(ru, fd.FID, r)
+	}
+	return nil
+}
@@ -1030,6 +1030,11 @@ func (c *Client) GCKick(gcID zkidentity.ShortID, uid UserID, reason string) erro
 	}
 	c.log.Infof("Kicking %s from GC %q", us, gcID.String())
 
+	// The user no longer has access to files shared with the GC.
+	if err := c.revokeGCSharedFiles(gcID, []UserID{uid}); err != nil {
+		c.log.Errorf("Unable to revoke access to GC shared files: %v", err)
+	}
+
 	// Saved updated GC members list. Send kick event to list of old
 	// members (which includes the kickee).
 	return c.sendToGCMembers(gcID, oldMembers, "kick", rmgk, nil)
@@ -1053,6 +1058,12 @@ func (c *Client) handleGCKick(ru *RemoteUser, rmgk rpc.RMGroupKick) error {
 	c.log.Infof("User %s %s from GC %q. Reason: %q", us, verb,
 		rmgk.NewGroupList.ID.String(), rmgk.Reason)
 
+	// The user no longer has access to files shared with the GC.
+	err = c.revokeGCSharedFiles(rmgk.NewGroupList.ID, []UserID{rmgk.Member})
+	if err != nil {
+		c.log.Errorf("Unable to revoke access to GC shared files: %v", err)
+	}
+
 	// Notify specific part and any other updates.
 	c.ntfns.notifyGCUserParted(rmgk.NewGroupList.ID, rmgk.Member,
 		rmgk.Reason, !rmgk.Parted)
@@ -1094,6 +1105,11 @@ func (c *Client) PartFromGC(gcID zkidentity.ShortID, reason string) error {
 
 	c.log.Infof("Parting from GC %q", gcID.String())
 
+	// Members no longer have access to files shared with the GC.
+	if err := c.revokeGCSharedFiles(gcID, gc.Members); err != nil {
+		c.log.Errorf("Unable to revoke access to GC shared files: %v", err)
+	}
+
 	// Send GroupPart msg to all members.
 	rmgp := rpc.RMGroupPart{
 		ID:     gcID,
@@ -1113,6 +1129,11 @@ func (c *Client) handleGCPart(ru *RemoteUser, rmgp rpc.RMGroupPart) error {
 	c.log.Infof("User %s parting from GC %q. Reason: %q", ru, rmgp.ID.String(),
 		rmgp.Reason)
 
+	// The user no longer has access to files shared with the GC.
+	if err := c.revokeGCSharedFiles(rmgp.ID, []UserID{ru.ID()}); err != nil {
+		c.log.Errorf("Unable to revoke access to GC shared files: %v", err)
+	}
+
 	c.ntfns.notifyGCUserParted(rmgp.ID, ru.ID(), rmgp.Reason, false)
 	return nil
 }
@@ -1152,6 +1173,11 @@ func (c *Client) KillGroupChat(gcID zkidentity.ShortID, reason string) error {
 
 	c.log.Infof("Killed GC %s. Reason: %q", gcID.String(), reason)
 
+	// Members no longer have access to files shared with the GC.
+	if err := c.revokeGCSharedFiles(gcID, oldMembers); err != nil {
+		c.log.Errorf("Unable to revoke access to GC shared files: %v", err)
+	}
+
 	rmgk := rpc.RMGroupKill{
 		ID:     gcID,
 		Reason: reason,
@@ -1163,12 +1189,14 @@ func (c *Client) KillGroupChat(gcID zkidentity.ShortID, reason string) error {
 }
 
 func (c *Client) handleGCKill(ru *RemoteUser, rmgk rpc.RMGroupKill) error {
+	var oldMembers []UserID
 	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
 		// Ensure gc exists.
 		gc, err := c.db.GetGC(tx, rmgk.ID)
 		if err != nil {
 			return err
 		}
+		oldMembers = gc.Members
 
 		// Ensure we received this from the existing admin.
 		if len(gc.Members) == 0 || gc.Members[0] != ru.ID() {
@@ -1191,6 +1219,11 @@ func (c *Client) handleGCKill(ru *RemoteUser, rmgk rpc.RMGroupKill) error {
 
 	c.log.Infof("User %s killed GC %q. Reason: %q", ru, rmgk.ID.String(), rmgk.Reason)
 
+	// Members no longer have access to files shared with the GC.
+	if err := c.revokeGCSharedFiles(rmgk.ID, oldMembers); err != nil {
+		c.log.Errorf("Unable to revoke access to GC shared files: %v", err)
+	}
+
 	c.ntfns.notifyOnGCKilled(rmgk.ID, rmgk.Reason)
 	return nil
 }
@@ -0,0 +1,237 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/companyzero/bisonrelay/client/clientdb"
+	"github.com/companyzero/bisonrelay/client/internal/lowlevel"
+	"github.com/companyzero/bisonrelay/zkidentity"
+)
+
+// PaymentApprovalRequest is a request to approve an outbound payment above the
+// approval threshold of the payment limits.
+type PaymentApprovalRequest struct {
+	// UID is the user related to the payment, if there is one.
+	UID *UserID
+
+	// GC is the GC related to the payment, if there is one.
+	GC *zkidentity.ShortID
+
+	// MAtoms is the amount of the payment, not including fees.
+	MAtoms int64
+
+	// Descr is a description of what is being paid for.
+	Descr string
+}
+
+// PaymentLimits returns the configured limits of outbound payments.
+func (c *Client) PaymentLimits() (clientdb.PaymentLimits, error) {
+	var limits clientdb.PaymentLimits
+	err := c.dbView(func(tx clientdb.ReadTx) error {
+		var err error
+		limits, err = c.db.GetPaymentLimits(tx)
+		return err
+	})
+	return limits, err
+}
+
+// SetPaymentLimits replaces the configured limits of outbound payments.
+func (c *Client) SetPaymentLimits(limits clientdb.PaymentLimits) error {
+	return c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		return c.db.SetPaymentLimits(tx, limits)
+	})
+}
+
+// PaymentBudget returns the amounts spent on outbound payments today.
+func (c *Client) PaymentBudget() (clientdb.PaymentBudget, error) {
+	var budget clientdb.PaymentBudget
+	err := c.dbView(func(tx clientdb.ReadTx) error {
+		var err error
+		budget, err = c.db.GetPaymentBudget(tx)
+		return err
+	})
+	return budget, err
+}
+
+// approvePayment returns an error if an outbound payment of the given amount
+// would exceed the spending limits or if the payment is above the approval
+// threshold and is not approved by the PaymentApprover callback.
+//
+// The amount is reserved in today's budget in the same transaction as the
+// limits are checked, so that concurrent payments cannot overrun the limits.
+func (c *Client) approvePayment(uid *UserID, gcID *zkidentity.ShortID,
+	matoms int64, descr string) error {
+
+	var limits clientdb.PaymentLimits
+	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		var err error
+		limits, err = c.db.GetPaymentLimits(tx)
+		if err != nil {
+			return err
+		}
+		return c.db.ReservePayment(tx, uid, gcID, matoms)
+	})
+	if err != nil {
+		return err
+	}
+
+	if limits.ApprovalThreshold == 0 || matoms <= limits.ApprovalThreshold {
+		return nil
+	}
+
+	var approved bool
+	if c.cfg.PaymentApprover != nil {
+		req := PaymentApprovalRequest{
+			UID:    uid,
+			GC:     gcID,
+			MAtoms: matoms,
+			Descr:  descr,
+		}
+		approved = c.cfg.PaymentApprover(req)
+	}
+	if approved {
+		return nil
+	}
+
+	// Release the reserved amount, as the payment will not be made.
+	c.releasePayment(uid, gcID, matoms)
+	if c.cfg.PaymentApprover == nil {
+		return fmt.Errorf("%w: payment of %d milliatoms is above the "+
+			"approval threshold", ErrPaymentNotApproved, matoms)
+	}
+	return fmt.Errorf("%w: %s", ErrPaymentNotApproved, descr)
+}
+
+// releasePayment releases the amount reserved by approvePayment for a payment
+// that was not made.
+func (c *Client) releasePayment(uid *UserID, gcID *zkidentity.ShortID, matoms int64) {
+	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		c.db.ReleasePayment(tx, uid, gcID, matoms)
+		return nil
+	})
+	if err != nil {
+		c.log.Warnf("Unable to release reserved payment amount: %v", err)
+	}
+}
+
+// payUserInvoice approves and pays an invoice of up to matoms generated by the
+// remote user. The invoice is obtained by calling fetchInvoice after the
+// payment is approved. If exact is true, the invoice must be for exactly
+// matoms. Once paid, record is called with the (negative) amount and fees of
+// the payment to record it in the DB. It returns the amount paid.
+//
+// The amount reserved when approving the payment is released when the payment
+// is not made. When an invoice for a lower amount is paid, the remainder of
+// the reservation is released.
+func (c *Client) payUserInvoice(ctx context.Context, ru *RemoteUser, matoms int64,
+	exact bool, descr string, fetchInvoice func(context.Context) (string, error),
+	record func(tx clientdb.ReadWriteTx, amount, fees int64) error) (int64, error) {
+
+	uid := ru.ID()
+	if err := c.approvePayment(&uid, nil, matoms, descr); err != nil {
+		return 0, err
+	}
+
+	// Release whatever was not paid. Recording the payment releases the
+	// paid amount.
+	var paid int64
+	defer func() {
+		if paid < matoms {
+			c.releasePayment(&uid, nil, matoms-paid)
+		}
+	}()
+
+	invoice, err := fetchInvoice(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if invoice == "" {
+		return 0, fmt.Errorf("user %s did not send an invoice", ru)
+	}
+
+	ctx, cancel := multiCtx(c.ctx, ctx)
+	defer cancel()
+
+	inv, err := c.pc.DecodeInvoice(ctx, invoice)
+	if err != nil {
+		return 0, err
+	}
+	if inv.MAtoms < 0 || inv.MAtoms > matoms || (exact && inv.MAtoms != matoms) {
+		return 0, fmt.Errorf("user generated invoice for amount different "+
+			"then requested (%d vs %d matoms)", inv.MAtoms, matoms)
+	}
+
+	fees, err := c.pc.PayInvoice(ctx, invoice)
+	if err != nil {
+		return 0, err
+	}
+	paid = inv.MAtoms
+
+	err = c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		return record(tx, -inv.MAtoms, -fees)
+	})
+	return paid, err
+}
+
+// gcFromPayEvent returns the ID of the GC referenced by a GC related payment
+// event (for example, "gc.<id>.msg"). It returns nil if the event is not
+// related to a known GC.
+func (c *Client) gcFromPayEvent(event string) *zkidentity.ShortID {
+	if !strings.HasPrefix(event, "gc.") {
+		return nil
+	}
+	logID, _, _ := strings.Cut(event[len("gc."):], ".")
+
+	// Check the GCs with a local alias first, to avoid reading all GCs
+	// from the DB.
+	c.gcAliasMtx.Lock()
+	for _, id := range c.gcAliasMap {
+		if id.ShortLogID() == logID {
+			id := id
+			c.gcAliasMtx.Unlock()
+			return &id
+		}
+	}
+	c.gcAliasMtx.Unlock()
+
+	var gcs []clientdb.GCAddressBookEntry
+	err := c.dbView(func(tx clientdb.ReadTx) error {
+		var err error
+		gcs, err = c.db.ListGCs(tx)
+		return err
+	})
+	if err != nil {
+		return nil
+	}
+	for _, gc := range gcs {
+		if gc.ID.ShortLogID() == logID {
+			id := gc.ID
+			return &id
+		}
+	}
+	return nil
+}
+
+// approvePushPayment approves the payment to push the given RM.
+func (c *Client) approvePushPayment(orm lowlevel.OutboundRM, matoms int64) error {
+	// Already encrypted RMs (KX and resends of unacked RMs) are always
+	// sent, because dropping them would break the ratchet with the remote
+	// user.
+	rm, ok := orm.(*remoteUserRM)
+	if !ok {
+		return nil
+	}
+
+	uid := rm.ru.ID()
+	gcID := c.gcFromPayEvent(rm.payEvent)
+	descr := fmt.Sprintf("push of %s to user %s", rm.payloadT, rm.ru)
+	return c.approvePayment(&uid, gcID, matoms, descr)
+}
+
+// approveSubsPayment approves the payment to subscribe to the given RVs.
+func (c *Client) approveSubsPayment(rvs []lowlevel.RVID, matoms int64) error {
+	descr := fmt.Sprintf("subscription to %d RVs", len(rvs))
+	return c.approvePayment(nil, nil, matoms, descr)
+}
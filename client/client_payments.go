@@ -2,8 +2,11 @@ package client
 
 import (
 	"context"
+	"crypto/ed25519"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/companyzero/bisonrelay/client/clientdb"
 	"github.com/companyzero/bisonrelay/client/clientintf"
@@ -37,9 +40,25 @@ import (
 // Tip payment is not persisted, therefore a client shutdown behaves as if the
 // context had been canceled.
 func (c *Client) TipUser(ctx context.Context, uid UserID, dcrAmount float64) error {
+	return c.TipUserWithMemo(ctx, uid, dcrAmount, "", nil)
+}
+
+// TipUserWithMemo sends a tip to the remote user, along with a memo signed by
+// the local client. If post is specified, the tip refers to that post of the
+// remote user. The memo and post are shown to the remote user once the tip is
+// received.
+//
+// See TipUser for details about the tip payment.
+func (c *Client) TipUserWithMemo(ctx context.Context, uid UserID, dcrAmount float64,
+	memo string, post *clientintf.PostID) error {
+
 	if dcrAmount <= 0 {
 		return fmt.Errorf("cannot pay user %f <= 0", dcrAmount)
 	}
+	if len(memo) > rpc.MaxTipMemoLen {
+		return fmt.Errorf("tip memo is too long (%d > %d)", len(memo),
+			rpc.MaxTipMemoLen)
+	}
 
 	ru, err := c.rul.byID(uid)
 	if err != nil {
@@ -47,74 +66,71 @@ func (c *Client) TipUser(ctx context.Context, uid UserID, dcrAmount float64) err
 	}
 
 	milliAmt := uint64(dcrAmount * 1e11)
+	descr := fmt.Sprintf("tip of %.8f DCR", dcrAmount)
+	fetchInvoice := func(ctx context.Context) (string, error) {
+		getInvoice := rpc.RMGetInvoice{
+			PayScheme:  rpc.PaySchemeDCRLN,
+			MilliAtoms: milliAmt,
+		}
+		if memo != "" || post != nil {
+			getInvoice.Tip = c.signTipMemo(memo, post, milliAmt)
+		}
 
-	replyChan := make(chan interface{})
-	tag := ru.tagForMsg(replyChan)
-
-	getInvoice := rpc.RMGetInvoice{
-		PayScheme:  rpc.PaySchemeDCRLN,
-		MilliAtoms: milliAmt,
-		Tag:        tag,
+		ru.log.Debugf("Requesting invoice to pay user %.8f DCR", dcrAmount)
+		ir, err := c.fetchInvoice(ctx, ru, getInvoice, "gettipinvoice")
+		if err == nil {
+			ru.log.Debugf("Got invoice to pay user: %q", ir.Invoice)
+		}
+		return ir.Invoice, err
 	}
+	record := func(tx clientdb.ReadWriteTx, amount, fees int64) error {
+		tip := clientdb.TipHistoryEntry{
+			MilliAtoms: amount,
+			Fees:       fees,
+			Memo:       memo,
+			Post:       post,
+		}
+		return c.db.RecordTip(tx, uid, tip)
+	}
+	paid, err := c.payUserInvoice(ctx, ru, int64(milliAmt), false, descr,
+		fetchInvoice, record)
+	if err != nil {
+		return err
+	}
+	ru.log.Infof("Paid user %.8f DCR", float64(paid)/1e11)
+	return nil
+}
+
+// fetchInvoice requests an invoice from the remote user and waits until it is
+// received. The tag of getInvoice is filled by this function.
+func (c *Client) fetchInvoice(ctx context.Context, ru *RemoteUser,
+	getInvoice rpc.RMGetInvoice, payEvent string) (rpc.RMInvoice, error) {
 
-	ru.log.Debugf("Requesting invoice to pay user %.8f DCR", dcrAmount)
+	replyChan := make(chan interface{})
+	getInvoice.Tag = ru.tagForMsg(replyChan)
 
-	payEvent := "gettipinvoice"
-	err = ru.sendRM(getInvoice, payEvent)
+	err := ru.sendRM(getInvoice, payEvent)
 	if err != nil {
-		return err
+		return rpc.RMInvoice{}, err
 	}
 
 	// Wait until a reply with the invoice is received.
-	var ir rpc.RMInvoice
 	select {
 	case res := <-replyChan:
 		switch res := res.(type) {
 		case rpc.RMInvoice:
-			ir = res
+			return res, nil
 		case error:
-			return res
+			return rpc.RMInvoice{}, res
 		default:
-			return fmt.Errorf("unknown result type %T", res)
+			return rpc.RMInvoice{}, fmt.Errorf("unknown result type %T", res)
 		}
 	case <-ctx.Done():
 		ru.log.Debugf("caller context done while waiting for invoice: %v", ctx.Err())
-		return ctx.Err()
+		return rpc.RMInvoice{}, ctx.Err()
 	case <-c.ctx.Done():
-		return errClientExiting
+		return rpc.RMInvoice{}, errClientExiting
 	}
-
-	ru.log.Debugf("Got invoice to pay user: %q", ir.Invoice)
-
-	ctx, cancel := multiCtx(c.ctx, ctx)
-	defer cancel()
-
-	// Decode invoice and verify.
-	if ir.Invoice == "" {
-		return nil
-	}
-	inv, err := c.pc.DecodeInvoice(ctx, ir.Invoice)
-	if err != nil {
-		return err
-	}
-	if inv.MAtoms < 0 || uint64(inv.MAtoms) > milliAmt {
-		return fmt.Errorf("client generated invoice for amount different "+
-			"then requested (%d vs %d matoms)", inv.MAtoms, milliAmt)
-	}
-
-	// Pay for invoice.
-	fees, err := c.pc.PayInvoice(ctx, ir.Invoice)
-	if err == nil {
-		ru.log.Infof("Paid user %.8f DCR", float64(inv.MAtoms)/1e11)
-		err = c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
-			// Amount is negative because we're paying an invoice.
-			payEvent := "paytip"
-			amount := -inv.MAtoms
-			fees := -fees
-			return c.db.RecordUserPayEvent(tx, uid, payEvent, amount, fees)
-		})
-	}
-	return err
 }
 
 func (c *Client) handleGetInvoice(ru *RemoteUser, getInvoice rpc.RMGetInvoice) error {
@@ -140,11 +156,34 @@ func (c *Client) handleGetInvoice(ru *RemoteUser, getInvoice rpc.RMGetInvoice) e
 		return err
 	}
 
+	if getInvoice.PaywalledPost != nil {
+		return c.handleGetPaywalledPostInvoice(ru, getInvoice, replyWithErr)
+	}
+	if getInvoice.Postage {
+		return c.handleGetPostageInvoice(ru, getInvoice, replyWithErr)
+	}
+	if getInvoice.PostsSubscription {
+		return c.handleGetPostsSubscriptionInvoice(ru, getInvoice, replyWithErr)
+	}
+
+	var tip clientdb.TipHistoryEntry
+	if getInvoice.Tip != nil {
+		if err := c.verifyTipMemo(ru, getInvoice.Tip, getInvoice.MilliAtoms); err != nil {
+			replyWithErr(err)
+			return err
+		}
+		tip.Memo = getInvoice.Tip.Memo
+		tip.Post = getInvoice.Tip.Post
+	}
+
 	cb := func(receivedMAtoms int64) {
 		dcrAmt := float64(receivedMAtoms) / 1e11
 		ru.log.Infof("Received %f DCR as tip", dcrAmt)
+		tip := tip
+		tip.Timestamp = time.Now()
+		tip.MilliAtoms = receivedMAtoms
 		err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
-			return c.db.RecordUserPayEvent(tx, ru.ID(), "tip", receivedMAtoms, 0)
+			return c.db.RecordTip(tx, ru.ID(), tip)
 		})
 		if err != nil {
 			c.log.Warnf("Error while updating DB to store tip payment status: %v", err)
@@ -152,6 +191,7 @@ func (c *Client) handleGetInvoice(ru *RemoteUser, getInvoice rpc.RMGetInvoice) e
 		if c.cfg.TipReceived != nil {
 			c.cfg.TipReceived(ru, dcrAmt)
 		}
+		c.ntfns.notifyTipReceived(ru, tip)
 	}
 
 	amountMAtoms := int64(getInvoice.MilliAtoms)
@@ -185,6 +225,69 @@ func (c *Client) handleGetInvoice(ru *RemoteUser, getInvoice rpc.RMGetInvoice) e
 	return ru.sendRM(reply, "getinvoicereply")
 }
 
+// signTipMemo creates a tip memo signed by the local client for a tip of the
+// given amount.
+func (c *Client) signTipMemo(memo string, post *clientintf.PostID, milliAtoms uint64) *rpc.TipMemo {
+	tip := &rpc.TipMemo{Memo: memo, Post: post}
+	signature := c.id.SignMessage(tip.SignedHash(milliAtoms))
+	tip.Signature = hex.EncodeToString(signature[:])
+	return tip
+}
+
+// verifyTipMemo verifies that the memo of a tip of the given amount was signed
+// by the remote user and that it refers to a post of the local client.
+func (c *Client) verifyTipMemo(ru *RemoteUser, tip *rpc.TipMemo, milliAtoms uint64) error {
+	if len(tip.Memo) > rpc.MaxTipMemoLen {
+		return fmt.Errorf("tip memo is too long (%d > %d)", len(tip.Memo),
+			rpc.MaxTipMemoLen)
+	}
+
+	var sig [ed25519.SignatureSize]byte
+	if len(tip.Signature) != len(sig)*2 {
+		return fmt.Errorf("tip memo signature has wrong len (%d != %d)",
+			len(tip.Signature), len(sig)*2)
+	}
+	if _, err := hex.Decode(sig[:], []byte(tip.Signature)); err != nil {
+		return fmt.Errorf("unable to decode tip memo signature: %v", err)
+	}
+	if !ru.PublicIdentity().VerifyMessage(tip.SignedHash(milliAtoms), sig) {
+		return fmt.Errorf("tip memo signature failed verification")
+	}
+
+	if tip.Post == nil {
+		return nil
+	}
+	return c.dbView(func(tx clientdb.ReadTx) error {
+		_, err := c.db.ReadPost(tx, c.PublicID(), *tip.Post)
+		if errors.Is(err, clientdb.ErrNotFound) {
+			return fmt.Errorf("tipped post %s not found", tip.Post)
+		}
+		return err
+	})
+}
+
+// ListTipHistory lists the tips exchanged with the given user.
+func (c *Client) ListTipHistory(uid UserID) ([]clientdb.TipHistoryEntry, error) {
+	var res []clientdb.TipHistoryEntry
+	err := c.dbView(func(tx clientdb.ReadTx) error {
+		var err error
+		res, err = c.db.ListTipHistory(tx, uid)
+		return err
+	})
+	return res, err
+}
+
+// ListTipTotals returns the totals of the tips exchanged with each user.
+func (c *Client) ListTipTotals() (map[UserID]clientdb.TipTotals, error) {
+	var res map[UserID]clientdb.TipTotals
+	err := c.dbView(func(tx clientdb.ReadTx) error {
+		var err error
+		res, err = c.db.ListTipTotals(tx)
+		return err
+	})
+	return res, err
+}
+
 func (c *Client) handleInvoice(ru *RemoteUser, invoice rpc.RMInvoice) error {
 	var v interface{}
 	if invoice.Error != nil {
@@ -220,6 +323,21 @@ func (c *Client) SummarizeUserPayStats(uid UserID) ([]clientdb.PayStatsSummary,
 
 }
 
+// PayStatsReport returns the payment stats aggregated in buckets of the given
+// period, per user and category. Only payments made after since are included.
+// If uid is nil, then the payments of all users are included.
+func (c *Client) PayStatsReport(period clientdb.PayStatsPeriod, since time.Time,
+	uid *UserID) ([]clientdb.PayStatsBucket, error) {
+
+	var res []clientdb.PayStatsBucket
+	err := c.dbView(func(tx clientdb.ReadTx) error {
+		var err error
+		res, err = c.db.PayStatsReport(tx, period, since, uid)
+		return err
+	})
+	return res, err
+}
+
 // ClearPayStats removes the payment stats associated with the given user. If
 // nil is passed, then the payment stats for all users are cleared.
 func (c *Client) ClearPayStats(uid *UserID) error {
@@ -0,0 +1,433 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/companyzero/bisonrelay/client/clientdb"
+	"github.com/companyzero/bisonrelay/rpc"
+	"github.com/companyzero/bisonrelay/zkidentity"
+)
+
+// The payment request flows are:
+//
+//          Alice                                    Bob
+//         -------                                  -----
+//
+//   RequestInvoice()
+//       \-------- RMGetInvoice -->
+//
+//                                            handleGetInvoice()
+//                               <-- RMInvoice --------/
+//
+//   PayPaymentRequest()
+//     (out-of-band payment, received by Bob as a tip)
+//
+// And:
+//
+//   SendInvoice()
+//       \------ RMPaymentRequest -->
+//
+//                                            handlePaymentRequest()
+//
+//                                            PayPaymentRequest()
+//                                              (out-of-band payment)
+//                                              or
+//                                            DeclinePaymentRequest()
+//                   <-- RMPaymentRequestDeclined ----/
+//
+//   handlePaymentRequestDeclined()
+
+// newPaymentRequestID returns a new random payment request ID.
+func newPaymentRequestID() (zkidentity.ShortID, error) {
+	var id zkidentity.ShortID
+	_, err := rand.Read(id[:])
+	return id, err
+}
+
+// RequestInvoice fetches an invoice for the given dcr amount from the remote
+// user without paying it. The memo is sent to the remote user along with the
+// request.
+//
+// The invoice is stored as a pending payment request that may be paid with
+// PayPaymentRequest (or externally, by using the returned invoice). Once paid,
+// the remote user receives the payment as a tip.
+func (c *Client) RequestInvoice(ctx context.Context, uid UserID, dcrAmount float64,
+	memo string) (clientdb.PaymentRequest, error) {
+
+	var req clientdb.PaymentRequest
+	if dcrAmount <= 0 {
+		return req, fmt.Errorf("cannot request invoice for %f <= 0", dcrAmount)
+	}
+	if len(memo) > rpc.MaxTipMemoLen {
+		return req, fmt.Errorf("memo is too long (%d > %d)", len(memo),
+			rpc.MaxTipMemoLen)
+	}
+
+	ru, err := c.rul.byID(uid)
+	if err != nil {
+		return req, err
+	}
+	id, err := newPaymentRequestID()
+	if err != nil {
+		return req, err
+	}
+
+	milliAmt := uint64(dcrAmount * 1e11)
+	getInvoice := rpc.RMGetInvoice{
+		PayScheme:  rpc.PaySchemeDCRLN,
+		MilliAtoms: milliAmt,
+	}
+	if memo != "" {
+		getInvoice.Tip = c.signTipMemo(memo, nil, milliAmt)
+	}
+
+	ru.log.Debugf("Requesting invoice for %.8f DCR", dcrAmount)
+	ir, err := c.fetchInvoice(ctx, ru, getInvoice, "getinvoice")
+	if err != nil {
+		return req, err
+	}
+
+	ctx, cancel := multiCtx(c.ctx, ctx)
+	defer cancel()
+
+	inv, err := c.pc.DecodeInvoice(ctx, ir.Invoice)
+	if err != nil {
+		return req, err
+	}
+	if inv.MAtoms < 0 || uint64(inv.MAtoms) > milliAmt {
+		return req, fmt.Errorf("user generated invoice for amount different "+
+			"then requested (%d vs %d matoms)", inv.MAtoms, milliAmt)
+	}
+
+	req = clientdb.PaymentRequest{
+		ID:         id,
+		UID:        uid,
+		Fetched:    true,
+		Invoice:    ir.Invoice,
+		MilliAtoms: milliAmt,
+		Memo:       memo,
+		Created:    time.Now(),
+		Status:     clientdb.PaymentRequestPending,
+	}
+	err = c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		return c.db.SavePaymentRequest(tx, &req)
+	})
+	if err != nil {
+		return req, err
+	}
+	ru.log.Infof("Fetched invoice for %.8f DCR", dcrAmount)
+	return req, nil
+}
+
+// SendInvoice requests a payment of the given dcr amount from the remote user
+// by sending them an invoice along with the memo. The remote user may pay or
+// decline the request, at which point the OnPaymentRequestUpdatedNtfn
+// notification is triggered.
+func (c *Client) SendInvoice(uid UserID, dcrAmount float64, memo string) (clientdb.PaymentRequest, error) {
+	var req clientdb.PaymentRequest
+	if dcrAmount <= 0 {
+		return req, fmt.Errorf("cannot send invoice for %f <= 0", dcrAmount)
+	}
+	if len(memo) > rpc.MaxTipMemoLen {
+		return req, fmt.Errorf("memo is too long (%d > %d)", len(memo),
+			rpc.MaxTipMemoLen)
+	}
+
+	ru, err := c.rul.byID(uid)
+	if err != nil {
+		return req, err
+	}
+	id, err := newPaymentRequestID()
+	if err != nil {
+		return req, err
+	}
+
+	cb := func(receivedMAtoms int64) {
+		var req clientdb.PaymentRequest
+		err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+			var err error
+			req, err = c.db.GetPaymentRequest(tx, uid, id)
+			if err != nil {
+				return err
+			}
+			req.Status = clientdb.PaymentRequestPaid
+			if err := c.db.SavePaymentRequest(tx, &req); err != nil {
+				return err
+			}
+			return c.db.RecordUserPayEvent(tx, uid, "payrequest",
+				receivedMAtoms, 0)
+		})
+		if err != nil {
+			ru.log.Errorf("Unable to record paid payment request: %v", err)
+			return
+		}
+		ru.log.Infof("Received %.8f DCR for payment request %s",
+			float64(receivedMAtoms)/1e11, id.ShortLogID())
+		c.ntfns.notifyPaymentRequestUpdated(ru, req)
+	}
+
+	milliAmt := uint64(dcrAmount * 1e11)
+	inv, err := c.pc.GetInvoice(c.ctx, int64(milliAmt), cb)
+	if err != nil {
+		return req, fmt.Errorf("unable to generate invoice: %w", err)
+	}
+
+	req = clientdb.PaymentRequest{
+		ID:         id,
+		UID:        uid,
+		Outbound:   true,
+		Invoice:    inv,
+		MilliAtoms: milliAmt,
+		Memo:       memo,
+		Created:    time.Now(),
+		Status:     clientdb.PaymentRequestPending,
+	}
+	err = c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		return c.db.SavePaymentRequest(tx, &req)
+	})
+	if err != nil {
+		return req, err
+	}
+
+	rm := rpc.RMPaymentRequest{
+		ID:         id,
+		Invoice:    inv,
+		MilliAtoms: milliAmt,
+		Memo:       memo,
+	}
+	if err := c.sendWithSendQ("paymentrequest", rm, uid); err != nil {
+		return req, err
+	}
+	ru.log.Infof("Sent payment request %s for %.8f DCR", id.ShortLogID(),
+		dcrAmount)
+	return req, nil
+}
+
+// handlePaymentRequest handles a remote user requesting a payment from the
+// local client.
+func (c *Client) handlePaymentRequest(ru *RemoteUser, pr rpc.RMPaymentRequest) error {
+	if len(pr.Memo) > rpc.MaxTipMemoLen {
+		return fmt.Errorf("payment request memo is too long (%d > %d)",
+			len(pr.Memo), rpc.MaxTipMemoLen)
+	}
+	if pr.MilliAtoms == 0 {
+		return fmt.Errorf("payment request %s has zero amount",
+			pr.ID.ShortLogID())
+	}
+
+	// The invoice is only decoded and verified when the request is paid.
+	req := clientdb.PaymentRequest{
+		ID:         pr.ID,
+		UID:        ru.ID(),
+		Invoice:    pr.Invoice,
+		MilliAtoms: pr.MilliAtoms,
+		Memo:       pr.Memo,
+		Created:    time.Now(),
+		Status:     clientdb.PaymentRequestPending,
+	}
+	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		_, err := c.db.GetPaymentRequest(tx, ru.ID(), pr.ID)
+		if err == nil {
+			return fmt.Errorf("payment request %s: %w", pr.ID,
+				errAlreadyExists)
+		}
+		if !errors.Is(err, clientdb.ErrNotFound) {
+			return err
+		}
+		return c.db.SavePaymentRequest(tx, &req)
+	})
+	if err != nil {
+		return err
+	}
+
+	ru.log.Infof("Received payment request %s for %.8f DCR",
+		pr.ID.ShortLogID(), float64(pr.MilliAtoms)/1e11)
+	c.ntfns.notifyPaymentRequestReceived(ru, req)
+	return nil
+}
+
+// PayPaymentRequest pays the given pending payment request, which was either
+// received from or fetched from the remote user.
+func (c *Client) PayPaymentRequest(ctx context.Context, uid UserID, id zkidentity.ShortID) error {
+	ru, err := c.rul.byID(uid)
+	if err != nil {
+		return err
+	}
+
+	var req clientdb.PaymentRequest
+	err = c.dbView(func(tx clientdb.ReadTx) error {
+		var err error
+		req, err = c.db.GetPaymentRequest(tx, uid, id)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if req.Outbound {
+		return fmt.Errorf("cannot pay payment request sent by the local client")
+	}
+	if req.Status != clientdb.PaymentRequestPending {
+		return fmt.Errorf("payment request %s is already %s",
+			id.ShortLogID(), req.Status)
+	}
+
+	// Check the invoice before approving the payment, so that a payment
+	// request that can never be paid does not reserve any funds.
+	decodeCtx, cancel := multiCtx(c.ctx, ctx)
+	inv, err := c.pc.DecodeInvoice(decodeCtx, req.Invoice)
+	cancel()
+	if err != nil {
+		return err
+	}
+	if inv.MAtoms < 0 || uint64(inv.MAtoms) > req.MilliAtoms {
+		return fmt.Errorf("invoice amount different then requested "+
+			"(%d vs %d matoms)", inv.MAtoms, req.MilliAtoms)
+	}
+	if inv.IsExpired(0) {
+		return fmt.Errorf("invoice of payment request %s is expired",
+			id.ShortLogID())
+	}
+
+	descr := fmt.Sprintf("payment request %s", id.ShortLogID())
+	fetchInvoice := func(context.Context) (string, error) {
+		return req.Invoice, nil
+	}
+	record := func(tx clientdb.ReadWriteTx, amount, fees int64) error {
+		req.Status = clientdb.PaymentRequestPaid
+		if err := c.db.SavePaymentRequest(tx, &req); err != nil {
+			return err
+		}
+
+		if req.Fetched {
+			// Fetched invoices are received as tips by the remote
+			// user.
+			tip := clientdb.TipHistoryEntry{
+				MilliAtoms: amount,
+				Fees:       fees,
+				Memo:       req.Memo,
+			}
+			return c.db.RecordTip(tx, uid, tip)
+		}
+		return c.db.RecordUserPayEvent(tx, uid, "paypayrequest",
+			amount, fees)
+	}
+	paid, err := c.payUserInvoice(ctx, ru, int64(req.MilliAtoms), false,
+		descr, fetchInvoice, record)
+	if err != nil {
+		return err
+	}
+	ru.log.Infof("Paid %.8f DCR for payment request %s",
+		float64(paid)/1e11, id.ShortLogID())
+	return nil
+}
+
+// DeclinePaymentRequest declines the given pending payment request. The
+// remote user is alerted that their request was declined, unless the request
+// was created by fetching an invoice from them.
+func (c *Client) DeclinePaymentRequest(uid UserID, id zkidentity.ShortID, reason string) error {
+	ru, err := c.rul.byID(uid)
+	if err != nil {
+		return err
+	}
+
+	var req clientdb.PaymentRequest
+	err = c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		var err error
+		req, err = c.db.GetPaymentRequest(tx, uid, id)
+		if err != nil {
+			return err
+		}
+		if req.Outbound {
+			return fmt.Errorf("cannot decline payment request sent " +
+				"by the local client")
+		}
+		if req.Status != clientdb.PaymentRequestPending {
+			return fmt.Errorf("payment request %s is already %s",
+				id.ShortLogID(), req.Status)
+		}
+		req.Status = clientdb.PaymentRequestDeclined
+		req.DeclineReason = reason
+		return c.db.SavePaymentRequest(tx, &req)
+	})
+	if err != nil {
+		return err
+	}
+
+	ru.log.Infof("Declined payment request %s", id.ShortLogID())
+	if req.Fetched {
+		return nil
+	}
+	rm := rpc.RMPaymentRequestDeclined{ID: id, Reason: reason}
+	return c.sendWithSendQ("paymentrequestdeclined", rm, uid)
+}
+
+// handlePaymentRequestDeclined handles a remote user declining a payment
+// request sent by the local client.
+func (c *Client) handlePaymentRequestDeclined(ru *RemoteUser, prd rpc.RMPaymentRequestDeclined) error {
+	var req clientdb.PaymentRequest
+	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		var err error
+		req, err = c.db.GetPaymentRequest(tx, ru.ID(), prd.ID)
+		if err != nil {
+			return err
+		}
+		if !req.Outbound || req.Status != clientdb.PaymentRequestPending {
+			return fmt.Errorf("payment request %s is not an outbound "+
+				"pending request", prd.ID.ShortLogID())
+		}
+		req.Status = clientdb.PaymentRequestDeclined
+		req.DeclineReason = prd.Reason
+		return c.db.SavePaymentRequest(tx, &req)
+	})
+	if err != nil {
+		return err
+	}
+
+	ru.log.Infof("Payment request %s declined (reason: %q)",
+		prd.ID.ShortLogID(), prd.Reason)
+	c.ntfns.notifyPaymentRequestUpdated(ru, req)
+	return nil
+}
+
+// ListPaymentRequests lists the payment requests exchanged with the given user
+// or with all users if uid is nil.
+func (c *Client) ListPaymentRequests(uid *UserID) ([]clientdb.PaymentRequest, error) {
+	var res []clientdb.PaymentRequest
+	err := c.dbView(func(tx clientdb.ReadTx) error {
+		var err error
+		res, err = c.db.ListPaymentRequests(tx, uid)
+		return err
+	})
+	return res, err
+}
+
+// PaymentRequestByPrefix returns the payment request exchanged with any user
+// whose ID starts with the given hex prefix. It fails if more than one request
+// matches the prefix.
+func (c *Client) PaymentRequestByPrefix(prefix string) (clientdb.PaymentRequest, error) {
+	reqs, err := c.ListPaymentRequests(nil)
+	if err != nil {
+		return clientdb.PaymentRequest{}, err
+	}
+	var res *clientdb.PaymentRequest
+	for i := range reqs {
+		if !strings.HasPrefix(reqs[i].ID.String(), prefix) {
+			continue
+		}
+		if res != nil {
+			return clientdb.PaymentRequest{}, fmt.Errorf("prefix %q "+
+				"matches multiple payment requests", prefix)
+		}
+		res = &reqs[i]
+	}
+	if res == nil {
+		return clientdb.PaymentRequest{}, fmt.Errorf("payment request "+
+			"with prefix %q: %w", prefix, clientdb.ErrNotFound)
+	}
+	return *res, nil
+}
@@ -0,0 +1,155 @@
+package client
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/companyzero/bisonrelay/client/clientdb"
+)
+
+// PayStatsExportFormat is the format used to export payment stats.
+type PayStatsExportFormat string
+
+const (
+	// PayStatsExportCSV exports payment stats as a CSV file.
+	PayStatsExportCSV PayStatsExportFormat = "csv"
+
+	// PayStatsExportJSON exports payment stats as a JSON file.
+	PayStatsExportJSON PayStatsExportFormat = "json"
+)
+
+// PayStatsExportOpts are the options used when exporting payment stats.
+type PayStatsExportOpts struct {
+	// Format is the format of the export.
+	Format PayStatsExportFormat
+
+	// Dest is the file where the export is written.
+	Dest string
+
+	// Period is the length of the time buckets of the export. Defaults to
+	// monthly.
+	Period clientdb.PayStatsPeriod
+
+	// Since restricts the export to payments made after this time.
+	Since time.Time
+
+	// UID restricts the export to payments related to this user. If nil,
+	// payments related to all users are exported.
+	UID *UserID
+
+	// DCRPrice is the price of one DCR in USD. If set, the amounts are
+	// also valued in USD. Note that the same price is used to value all
+	// amounts, regardless of when the payments were made.
+	DCRPrice float64
+}
+
+// payStatsExportEntry is a single entry of an exported payment stats report.
+type payStatsExportEntry struct {
+	Start       string  `json:"start"`
+	UID         UserID  `json:"uid"`
+	Nick        string  `json:"nick"`
+	Category    string  `json:"category"`
+	SentDCR     float64 `json:"sent_dcr"`
+	ReceivedDCR float64 `json:"received_dcr"`
+	PayFeeDCR   float64 `json:"pay_fee_dcr"`
+	SentUSD     float64 `json:"sent_usd,omitempty"`
+	ReceivedUSD float64 `json:"received_usd,omitempty"`
+	PayFeeUSD   float64 `json:"pay_fee_usd,omitempty"`
+}
+
+// ExportPayStats exports a report of the payment stats, aggregated in time
+// buckets per user and category, to a file that can be read outside the
+// client. It returns the number of exported entries.
+func (c *Client) ExportPayStats(opts PayStatsExportOpts) (int, error) {
+	if opts.Dest == "" {
+		return 0, errors.New("export destination cannot be empty")
+	}
+	if opts.Format != PayStatsExportCSV && opts.Format != PayStatsExportJSON {
+		return 0, fmt.Errorf("unknown pay stats export format %q", opts.Format)
+	}
+	if opts.Period == "" {
+		opts.Period = clientdb.PayStatsMonthly
+	}
+
+	buckets, err := c.PayStatsReport(opts.Period, opts.Since, opts.UID)
+	if err != nil {
+		return 0, err
+	}
+
+	// Amounts are recorded in milliatoms.
+	toDCR := func(matoms int64) float64 { return float64(matoms) / 1e11 }
+	entries := make([]payStatsExportEntry, len(buckets))
+	for i, b := range buckets {
+		nick, _ := c.UserNick(b.UID)
+		entries[i] = payStatsExportEntry{
+			Start:       b.Start.Format("2006-01-02"),
+			UID:         b.UID,
+			Nick:        nick,
+			Category:    b.Category,
+			SentDCR:     toDCR(b.Sent),
+			ReceivedDCR: toDCR(b.Received),
+			PayFeeDCR:   toDCR(b.PayFee),
+		}
+		if opts.DCRPrice > 0 {
+			entries[i].SentUSD = entries[i].SentDCR * opts.DCRPrice
+			entries[i].ReceivedUSD = entries[i].ReceivedDCR * opts.DCRPrice
+			entries[i].PayFeeUSD = entries[i].PayFeeDCR * opts.DCRPrice
+		}
+	}
+
+	f, err := os.Create(opts.Dest)
+	if err != nil {
+		return 0, err
+	}
+	if opts.Format == PayStatsExportJSON {
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(entries)
+	} else {
+		err = writePayStatsCSV(f, entries, opts.DCRPrice > 0)
+	}
+	if err != nil {
+		f.Close()
+		return 0, err
+	}
+	if err := f.Close(); err != nil {
+		return 0, err
+	}
+	return len(entries), nil
+}
+
+// writePayStatsCSV writes the payment stats entries as CSV records.
+func writePayStatsCSV(out io.Writer, entries []payStatsExportEntry, withUSD bool) error {
+	header := []string{"start", "uid", "nick", "category", "sent_dcr",
+		"received_dcr", "pay_fee_dcr"}
+	if withUSD {
+		header = append(header, "sent_usd", "received_usd", "pay_fee_usd")
+	}
+
+	fmtDCR := func(v float64) string { return strconv.FormatFloat(v, 'f', 11, 64) }
+	fmtUSD := func(v float64) string { return strconv.FormatFloat(v, 'f', 4, 64) }
+
+	w := csv.NewWriter(out)
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		record := []string{e.Start, e.UID.String(), e.Nick, e.Category,
+			fmtDCR(e.SentDCR), fmtDCR(e.ReceivedDCR), fmtDCR(e.PayFeeDCR)}
+		if withUSD {
+			record = append(record, fmtUSD(e.SentUSD),
+				fmtUSD(e.ReceivedUSD), fmtUSD(e.PayFeeUSD))
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
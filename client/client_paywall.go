@@ -0,0 +1,393 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/companyzero/bisonrelay/client/clientdb"
+	"github.com/companyzero/bisonrelay/client/clientintf"
+	"github.com/companyzero/bisonrelay/rpc"
+	"github.com/decred/slog"
+)
+
+// The paywalled post flow is:
+//
+//          Alice (reader)                           Bob (author)
+//         ----------------                         --------------
+//
+//                                            CreatePaywalledPost()
+//                              <-- RMPostShare ------/
+//                                (summary, price and body hash)
+//
+//   BuyPaywalledPost()
+//       \-------- RMGetInvoice -->
+//                  (PaywalledPost set)
+//
+//                                            handleGetPaywalledPostInvoice()
+//                               <-- RMInvoice --------/
+//
+//   (out-of-band payment)
+//
+//                                            (invoice settled)
+//                            <-- RMPostGetReply ------/
+//                                (body)
+//
+//   handlePostGetReply()
+
+// CreatePaywalledPost creates a new post where the summary is shared for free
+// with all current subscribers, while the body is only sent to readers that
+// pay the specified dcr price.
+func (c *Client) CreatePaywalledPost(summary, body, descr string, dcrPrice float64) (clientdb.PostSummary, error) {
+	var summ clientdb.PostSummary
+	if dcrPrice <= 0 {
+		return summ, fmt.Errorf("cannot create paywalled post with price %f <= 0", dcrPrice)
+	}
+	priceMAtoms := uint64(dcrPrice * 1e11)
+
+	var pm rpc.PostMetadata
+	var subs []clientdb.UserID
+	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		var err error
+		summ, pm, err = c.db.CreatePaywalledPost(tx, summary, body, descr,
+			priceMAtoms, c.id)
+		if err != nil {
+			return err
+		}
+
+		subs, err = c.db.ListPostSubscribers(tx)
+		return err
+	})
+	if err != nil {
+		return summ, fmt.Errorf("unable to create local post: %w", err)
+	}
+
+	c.log.Infof("Created paywalled post %s (price %.8f DCR)", summ.ID, dcrPrice)
+	rm := rpc.RMPostShare(pm)
+	if err := c.shareWithPostSubscribers(subs, summ.ID, rm, "sharecreated"); err != nil {
+		return summ, err
+	}
+
+	return summ, nil
+}
+
+// PaywalledPostBody returns the body of a paywalled post. This returns an
+// error wrapping clientdb.ErrNotFound if the post has not been unlocked yet.
+func (c *Client) PaywalledPostBody(from UserID, pid clientintf.PostID) (string, error) {
+	var content clientdb.PaywalledPostContent
+	err := c.dbView(func(tx clientdb.ReadTx) error {
+		var err error
+		content, err = c.db.ReadPaywalledPostContent(tx, from, pid)
+		return err
+	})
+	return content.Body, err
+}
+
+// BuyPaywalledPost pays the author of the given paywalled post (which must
+// have been received from the from user) to unlock its body. The body is sent
+// asynchronously by the author after the payment completes, at which point
+// the OnPostPaywallUnlockedNtfn notification is triggered.
+//
+// The author of the post must be KX'd with the local client.
+func (c *Client) BuyPaywalledPost(ctx context.Context, from UserID, pid clientintf.PostID) error {
+	var post rpc.PostMetadata
+	var unlocked bool
+	err := c.dbView(func(tx clientdb.ReadTx) error {
+		var err error
+		post, err = c.db.ReadPost(tx, from, pid)
+		if err != nil {
+			return err
+		}
+		_, err = c.db.ReadPaywalledPostContent(tx, from, pid)
+		unlocked = err == nil
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if unlocked {
+		return fmt.Errorf("post %s is already unlocked", pid)
+	}
+
+	price := clientintf.PaywalledPostPrice(&post)
+	if price == 0 {
+		return fmt.Errorf("post %s is not paywalled", pid)
+	}
+
+	var authorID UserID
+	if err := authorID.FromString(post.Attributes[rpc.RMPStatusFrom]); err != nil {
+		return fmt.Errorf("unable to decode post author: %v", err)
+	}
+	ru, err := c.rul.byID(authorID)
+	if err != nil {
+		return fmt.Errorf("author of paywalled post is not a known user: %w", err)
+	}
+	descr := fmt.Sprintf("unlock of post %s", pid.ShortLogID())
+	fetchInvoice := func(ctx context.Context) (string, error) {
+		ru.log.Infof("Requesting invoice to unlock post %s (%.8f DCR)",
+			pid, float64(price)/1e11)
+		getInvoice := rpc.RMGetInvoice{
+			PayScheme:     rpc.PaySchemeDCRLN,
+			MilliAtoms:    price,
+			PaywalledPost: &pid,
+		}
+		payEvent := fmt.Sprintf("posts.%s.getpaywallinvoice", pid.ShortLogID())
+		ir, err := c.fetchInvoice(ctx, ru, getInvoice, payEvent)
+		return ir.Invoice, err
+	}
+	record := func(tx clientdb.ReadWriteTx, amount, fees int64) error {
+		payEvent := fmt.Sprintf("posts.%s.paywall", pid.ShortLogID())
+		return c.db.RecordUserPayEvent(tx, ru.ID(), payEvent, amount, fees)
+	}
+	paid, err := c.payUserInvoice(ctx, ru, int64(price), true, descr,
+		fetchInvoice, record)
+	if err != nil {
+		return err
+	}
+	ru.log.Infof("Paid %.8f DCR to unlock post %s", float64(paid)/1e11, pid)
+	return nil
+}
+
+// handleGetPaywalledPostInvoice generates an invoice for a remote user to
+// unlock one of the local client's paywalled posts. The body is sent once the
+// invoice is settled.
+func (c *Client) handleGetPaywalledPostInvoice(ru *RemoteUser,
+	getInvoice rpc.RMGetInvoice, replyWithErr func(error)) error {
+
+	pid := *getInvoice.PaywalledPost
+	var post rpc.PostMetadata
+	err := c.dbView(func(tx clientdb.ReadTx) error {
+		var err error
+		post, err = c.db.ReadPost(tx, c.PublicID(), pid)
+		return err
+	})
+	if err != nil {
+		ru.log.Warnf("Requested invoice for unknown paywalled post %s: %v",
+			pid, err)
+		replyWithErr(fmt.Errorf("unknown post %s", pid))
+		return nil
+	}
+
+	// Only sell the body of posts authored by the local client (and not
+	// ones that were relayed).
+	price := clientintf.PaywalledPostPrice(&post)
+	if price == 0 || post.Attributes[rpc.RMPStatusFrom] != c.PublicID().String() {
+		ru.log.Warnf("Requested invoice for post %s that is not paywalled", pid)
+		replyWithErr(fmt.Errorf("post %s is not paywalled", pid))
+		return nil
+	}
+	if getInvoice.MilliAtoms != price {
+		err := fmt.Errorf("requested amount %d different than post price %d",
+			getInvoice.MilliAtoms, price)
+		replyWithErr(err)
+		return err
+	}
+
+	// cb will be called once the payment completes.
+	var inv string
+	cb := func(receivedMAtoms int64) {
+		if c.ctx.Err() != nil {
+			// Client is shutting down. The sale is completed
+			// once the client restarts.
+			return
+		}
+		err := c.completePaywalledPostSale(ru, pid, inv, price, receivedMAtoms)
+		if err != nil && !errors.Is(err, clientintf.ErrSubsysExiting) {
+			ru.log.Errorf("Unable to complete sale of post %s: %v", pid, err)
+		}
+	}
+
+	dcrAmount := float64(price) / 1e11
+	inv, err = c.pc.GetInvoice(c.ctx, int64(price), cb)
+	if err != nil {
+		c.ntfns.notifyInvoiceGenFailed(ru, dcrAmount, err)
+		replyWithErr(fmt.Errorf("unable to generate payment invoice"))
+		ru.log.Warnf("Unable to generate invoice for %.8f DCR: %v",
+			dcrAmount, err)
+		return nil
+	}
+
+	// Track the sale, so that the body is sent even if the client is
+	// restarted before the payment completes.
+	err = c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		sale := clientdb.PaywalledPostSale{Buyer: ru.ID(), Invoice: inv}
+		return c.db.AddPaywalledPostPendingSale(tx, pid, sale, c.id)
+	})
+	if err != nil {
+		replyWithErr(fmt.Errorf("unable to generate payment invoice"))
+		return err
+	}
+
+	if ru.log.Level() <= slog.LevelDebug {
+		ru.log.Debugf("Generated invoice to unlock post %s for %.8f DCR: %s",
+			pid, dcrAmount, inv)
+	} else {
+		ru.log.Infof("Generated invoice to unlock post %s for %.8f DCR",
+			pid, dcrAmount)
+	}
+
+	reply := rpc.RMInvoice{
+		Invoice: inv,
+		Tag:     getInvoice.Tag,
+	}
+	return ru.sendRM(reply, "getinvoicereply")
+}
+
+// completePaywalledPostSale records that the remote user paid the invoice to
+// unlock the given post and sends its body to them.
+func (c *Client) completePaywalledPostSale(ru *RemoteUser, pid clientintf.PostID,
+	invoice string, price uint64, receivedMAtoms int64) error {
+
+	if receivedMAtoms < int64(price) {
+		return fmt.Errorf("user paid wrong amount to unlock post "+
+			"(paid %d, wanted %d)", receivedMAtoms, price)
+	}
+
+	var body string
+	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		payEvent := fmt.Sprintf("posts.%s.paywall", pid.ShortLogID())
+		err := c.db.RecordUserPayEvent(tx, ru.ID(), payEvent, receivedMAtoms, 0)
+		if err != nil {
+			return err
+		}
+		body, err = c.db.AddPaywalledPostBuyer(tx, pid, ru.ID(), invoice, c.id)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("unable to record purchase: %w", err)
+	}
+
+	ru.log.Infof("Received %.8f DCR to unlock post %s",
+		float64(receivedMAtoms)/1e11, pid)
+	return c.sendPaywalledPostBody(ru, pid, body)
+}
+
+// restartPaywalledPostSales is called during client startup to complete the
+// sales of paywalled posts that were paid while the client was offline and to
+// drop the ones with expired invoices.
+func (c *Client) restartPaywalledPostSales(ctx context.Context) error {
+	var sales map[clientintf.PostID][]clientdb.PaywalledPostSale
+	err := c.dbView(func(tx clientdb.ReadTx) error {
+		var err error
+		sales, err = c.db.ListPaywalledPostPendingSales(tx, c.id)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	for pid, pidSales := range sales {
+		var post rpc.PostMetadata
+		err := c.dbView(func(tx clientdb.ReadTx) error {
+			var err error
+			post, err = c.db.ReadPost(tx, c.PublicID(), pid)
+			return err
+		})
+		if err != nil {
+			c.log.Warnf("Unable to read paywalled post %s: %v", pid, err)
+			continue
+		}
+		price := clientintf.PaywalledPostPrice(&post)
+
+		for _, sale := range pidSales {
+			ru, err := c.rul.byID(sale.Buyer)
+			if err != nil {
+				c.log.Warnf("Pending sale of post %s found for "+
+					"unknown user %s", pid, sale.Buyer)
+				continue
+			}
+
+			err = c.pc.IsInvoicePaid(ctx, int64(price), sale.Invoice)
+			if err == nil {
+				err := c.completePaywalledPostSale(ru, pid,
+					sale.Invoice, price, int64(price))
+				if err != nil && !errors.Is(err, clientintf.ErrSubsysExiting) {
+					ru.log.Errorf("Unable to complete sale of "+
+						"post %s: %v", pid, err)
+				}
+				continue
+			}
+
+			// Unpaid. If it's expired, remove it.
+			decoded, err := c.pc.DecodeInvoice(ctx, sale.Invoice)
+			if err != nil || !decoded.IsExpired(0) {
+				continue
+			}
+			ru.log.Debugf("Removing expired invoice to unlock post %s", pid)
+			err = c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+				return c.db.RemovePaywalledPostPendingSale(tx, pid,
+					sale.Invoice, c.id)
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// sendPaywalledPostBody sends the body of a paywalled post to a remote user
+// that paid for it.
+func (c *Client) sendPaywalledPostBody(ru *RemoteUser, pid clientintf.PostID, body string) error {
+	rm := rpc.RMPostGetReply{
+		Attributes: map[string]string{
+			rpc.RMPIdentifier: pid.String(),
+			rpc.RMPMain:       body,
+		},
+	}
+	payEvent := fmt.Sprintf("posts.%s.paywallbody", pid.ShortLogID())
+	return c.sendWithSendQ(payEvent, rm, ru.ID())
+}
+
+// handlePostGetReply handles the body of a paywalled post sent by its author.
+func (c *Client) handlePostGetReply(ru *RemoteUser, reply rpc.RMPostGetReply) error {
+	if reply.Error != nil {
+		ru.log.Warnf("Received error reply to post get: %s", *reply.Error)
+		return nil
+	}
+
+	var pid clientintf.PostID
+	if err := pid.FromString(reply.Attributes[rpc.RMPIdentifier]); err != nil {
+		return err
+	}
+	body := reply.Attributes[rpc.RMPMain]
+	bodyHash := rpc.PostBodyHash(body)
+	authorID := ru.ID().String()
+
+	// Save the body in every local copy of the post (one for each relayer)
+	// that was authored by the remote user and commits to this body.
+	var saved bool
+	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		relayers, err := c.db.ListPostRelayers(tx, pid)
+		if err != nil {
+			return err
+		}
+		for _, from := range relayers {
+			post, err := c.db.ReadPost(tx, from, pid)
+			if err != nil {
+				return err
+			}
+			if post.Attributes[rpc.RMPStatusFrom] != authorID ||
+				clientintf.PaywalledPostPrice(&post) == 0 ||
+				post.Attributes[rpc.RMPBodyHash] != bodyHash {
+				continue
+			}
+			if err := c.db.SavePaywalledPostBody(tx, from, pid, body); err != nil {
+				return err
+			}
+			saved = true
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if !saved {
+		return fmt.Errorf("received body for post %s that does not "+
+			"match any local paywalled post", pid)
+	}
+
+	ru.log.Infof("Unlocked paywalled post %s", pid)
+	c.ntfns.notifyOnPostPaywallUnlocked(ru, pid, body)
+	return nil
+}
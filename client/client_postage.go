@@ -0,0 +1,340 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/companyzero/bisonrelay/client/clientdb"
+	"github.com/companyzero/bisonrelay/client/clientintf"
+	"github.com/companyzero/bisonrelay/rpc"
+	"github.com/decred/slog"
+)
+
+// The postage flow is:
+//
+//          Alice (sender)                           Bob (recipient)
+//         ----------------                         -----------------
+//
+//   PM()
+//       \------ RMPrivateMessage -->
+//
+//                                            holdForPostage()
+//                         <-- RMPostageRequired ------/
+//                          (postage and nb of held msgs)
+//
+//   PayPostage()
+//       \-------- RMGetInvoice -->
+//                    (Postage set)
+//
+//                                            handleGetPostageInvoice()
+//                               <-- RMInvoice --------/
+//
+//   (out-of-band payment)
+//
+//                                            (invoice settled)
+//                                            releaseHeldMessages()
+//
+// The amount paid by the sender is credited to them, and the postage of each
+// following message is charged from this credit, so senders may prepay the
+// postage of multiple messages.
+
+// PostageSettings returns the postage settings of the local client.
+func (c *Client) PostageSettings() (clientdb.PostageSettings, error) {
+	var settings clientdb.PostageSettings
+	err := c.dbView(func(tx clientdb.ReadTx) error {
+		var err error
+		settings, err = c.db.GetPostageSettings(tx)
+		return err
+	})
+	return settings, err
+}
+
+// SetPostageSettings replaces the postage settings of the local client.
+// Messages held from users that no longer need to pay postage are released.
+func (c *Client) SetPostageSettings(settings clientdb.PostageSettings) error {
+	var held map[UserID]int
+	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		if err := c.db.SetPostageSettings(tx, settings); err != nil {
+			return err
+		}
+		var err error
+		held, err = c.db.ListHeldMessagesCount(tx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	for uid := range held {
+		if settings.UserPostage(uid) != 0 {
+			continue
+		}
+		if err := c.ReleaseHeldMessages(uid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListHeldMessagesCount returns the number of messages held from each user
+// that has messages held until their postage is paid.
+func (c *Client) ListHeldMessagesCount() (map[UserID]int, error) {
+	var res map[UserID]int
+	err := c.dbView(func(tx clientdb.ReadTx) error {
+		var err error
+		res, err = c.db.ListHeldMessagesCount(tx)
+		return err
+	})
+	return res, err
+}
+
+// PostageState returns the postage state related to the given user.
+func (c *Client) PostageState(uid UserID) (clientdb.PostageState, error) {
+	var res clientdb.PostageState
+	err := c.dbView(func(tx clientdb.ReadTx) error {
+		var err error
+		res, err = c.db.GetPostageState(tx, uid)
+		return err
+	})
+	return res, err
+}
+
+// holdForPostage charges the postage of a message received from the remote
+// user or holds the message until its postage is paid. It returns true if the
+// message was held.
+func (c *Client) holdForPostage(ru *RemoteUser, msg clientdb.HeldMessage) (bool, error) {
+	var held bool
+	var nbHeld int
+	var postage uint64
+	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		settings, err := c.db.GetPostageSettings(tx)
+		if err != nil {
+			return err
+		}
+		postage = settings.UserPostage(ru.ID())
+		if postage == 0 {
+			return nil
+		}
+		held, nbHeld, err = c.db.ChargeOrHoldMessage(tx, ru.ID(), postage, msg)
+		return err
+	})
+	if err != nil || !held {
+		return held, err
+	}
+
+	ru.log.Infof("Holding message until postage of %.8f DCR is paid "+
+		"(%d held messages)", float64(postage)/1e11, nbHeld)
+	c.ntfns.notifyMsgsHeldForPostage(ru, nbHeld, postage)
+
+	// Only alert the remote user when the first message is held, to avoid
+	// paying to reply to every message sent by spammers.
+	if nbHeld > 1 {
+		return true, nil
+	}
+	rm := rpc.RMPostageRequired{
+		MilliAtoms: postage,
+		Held:       uint32(nbHeld),
+	}
+	if err := ru.sendRM(rm, "postagerequired"); err != nil &&
+		!errors.Is(err, clientintf.ErrSubsysExiting) {
+		ru.log.Warnf("Unable to send postage required msg: %v", err)
+	}
+	return true, nil
+}
+
+// releaseHeldMessages delivers the held messages of the remote user that have
+// their postage paid (or all held messages if free is true).
+func (c *Client) releaseHeldMessages(ru *RemoteUser, free bool) error {
+	var msgs []clientdb.HeldMessage
+	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		settings, err := c.db.GetPostageSettings(tx)
+		if err != nil {
+			return err
+		}
+		postage := settings.UserPostage(ru.ID())
+		msgs, err = c.db.TakeHeldMessages(tx, ru.ID(), postage, free)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	ru.log.Infof("Releasing %d held messages", len(msgs))
+	for _, msg := range msgs {
+		switch {
+		case msg.PM != nil:
+			err = c.handlePM(ru, *msg.PM, msg.Timestamp)
+		case msg.GCM != nil:
+			err = c.handleGCMessage(ru, *msg.GCM, msg.Timestamp)
+		}
+		if err != nil {
+			ru.log.Warnf("Unable to handle released message: %v", err)
+		}
+	}
+	return nil
+}
+
+// ReleaseHeldMessages delivers all messages held from the given user, without
+// requiring their postage to be paid.
+func (c *Client) ReleaseHeldMessages(uid UserID) error {
+	ru, err := c.rul.byID(uid)
+	if err != nil {
+		return err
+	}
+	return c.releaseHeldMessages(ru, true)
+}
+
+// DropHeldMessages discards all messages held from the given user. It returns
+// the number of dropped messages.
+func (c *Client) DropHeldMessages(uid UserID) (int, error) {
+	var n int
+	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		var err error
+		n, err = c.db.DropHeldMessages(tx, uid)
+		return err
+	})
+	return n, err
+}
+
+// handlePostageRequired handles a remote user alerting that our messages are
+// held until their postage is paid.
+func (c *Client) handlePostageRequired(ru *RemoteUser, pr rpc.RMPostageRequired) error {
+	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		return c.db.SetRemotePostage(tx, ru.ID(), pr.MilliAtoms)
+	})
+	if err != nil {
+		return err
+	}
+
+	ru.log.Infof("Remote user requires postage of %.8f DCR per message "+
+		"(%d held messages)", float64(pr.MilliAtoms)/1e11, pr.Held)
+	c.ntfns.notifyPostageRequired(ru, pr.MilliAtoms, pr.Held)
+	return nil
+}
+
+// PayPostage pays the postage of nbMsgs messages sent to the given user, which
+// must have previously requested postage for messages sent to them. Messages
+// held by the remote user are released once their postage is paid.
+func (c *Client) PayPostage(ctx context.Context, uid UserID, nbMsgs uint32) error {
+	if nbMsgs == 0 {
+		return errors.New("number of messages cannot be zero")
+	}
+	ru, err := c.rul.byID(uid)
+	if err != nil {
+		return err
+	}
+
+	var state clientdb.PostageState
+	err = c.dbView(func(tx clientdb.ReadTx) error {
+		var err error
+		state, err = c.db.GetPostageState(tx, uid)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if state.RemotePostage == 0 {
+		return fmt.Errorf("user %s did not request postage", ru)
+	}
+
+	amount := state.RemotePostage * uint64(nbMsgs)
+	descr := fmt.Sprintf("postage of %d messages", nbMsgs)
+	fetchInvoice := func(ctx context.Context) (string, error) {
+		ru.log.Infof("Requesting invoice to pay postage of %d messages "+
+			"(%.8f DCR)", nbMsgs, float64(amount)/1e11)
+		getInvoice := rpc.RMGetInvoice{
+			PayScheme:  rpc.PaySchemeDCRLN,
+			MilliAtoms: amount,
+			Postage:    true,
+		}
+		ir, err := c.fetchInvoice(ctx, ru, getInvoice, "getpostageinvoice")
+		return ir.Invoice, err
+	}
+	record := func(tx clientdb.ReadWriteTx, amount, fees int64) error {
+		return c.db.RecordUserPayEvent(tx, uid, "postage", amount, fees)
+	}
+	paid, err := c.payUserInvoice(ctx, ru, int64(amount), false, descr,
+		fetchInvoice, record)
+	if err != nil {
+		return err
+	}
+	ru.log.Infof("Paid %.8f DCR of postage", float64(paid)/1e11)
+	return nil
+}
+
+// handleGetPostageInvoice generates an invoice for a remote user to prepay the
+// postage of their messages. Held messages are released once the invoice is
+// settled.
+func (c *Client) handleGetPostageInvoice(ru *RemoteUser, getInvoice rpc.RMGetInvoice,
+	replyWithErr func(error)) error {
+
+	var postage uint64
+	err := c.dbView(func(tx clientdb.ReadTx) error {
+		settings, err := c.db.GetPostageSettings(tx)
+		postage = settings.UserPostage(ru.ID())
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if postage == 0 {
+		ru.log.Warnf("Requested postage invoice when postage is not required")
+		replyWithErr(errors.New("postage is not required"))
+		return nil
+	}
+	if getInvoice.MilliAtoms < postage {
+		err := fmt.Errorf("requested amount %d lower than postage %d",
+			getInvoice.MilliAtoms, postage)
+		replyWithErr(err)
+		return err
+	}
+
+	cb := func(receivedMAtoms int64) {
+		if receivedMAtoms <= 0 {
+			return
+		}
+		err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+			err := c.db.RecordUserPayEvent(tx, ru.ID(), "postage", receivedMAtoms, 0)
+			if err != nil {
+				return err
+			}
+			return c.db.AddPostageCredit(tx, ru.ID(), uint64(receivedMAtoms))
+		})
+		if err != nil {
+			c.log.Errorf("Unable to record postage payment: %v", err)
+			return
+		}
+
+		ru.log.Infof("Received %.8f DCR of postage", float64(receivedMAtoms)/1e11)
+		if err := c.releaseHeldMessages(ru, false); err != nil {
+			ru.log.Errorf("Unable to release held messages: %v", err)
+		}
+	}
+
+	dcrAmount := float64(getInvoice.MilliAtoms) / 1e11
+	inv, err := c.pc.GetInvoice(c.ctx, int64(getInvoice.MilliAtoms), cb)
+	if err != nil {
+		c.ntfns.notifyInvoiceGenFailed(ru, dcrAmount, err)
+		replyWithErr(fmt.Errorf("unable to generate payment invoice"))
+		ru.log.Warnf("Unable to generate invoice for %.8f DCR: %v",
+			dcrAmount, err)
+		return nil
+	}
+
+	if ru.log.Level() <= slog.LevelDebug {
+		ru.log.Debugf("Generated invoice for postage of %.8f DCR: %s",
+			dcrAmount, inv)
+	} else {
+		ru.log.Infof("Generated invoice for postage of %.8f DCR", dcrAmount)
+	}
+
+	reply := rpc.RMInvoice{
+		Invoice: inv,
+		Tag:     getInvoice.Tag,
+	}
+	return ru.sendRM(reply, "getinvoicereply")
+}
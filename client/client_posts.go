@@ -41,9 +41,17 @@ func (c *Client) SubscribeToPosts(uid UserID) error {
 }
 
 func (c *Client) handlePostsSubscribe(ru *RemoteUser, ps rpc.RMPostsSubscribe) error {
+	price, err := c.postsSubscriptionPaymentDue(ru.ID())
+	if err != nil {
+		return err
+	}
+	if price != nil {
+		return c.requirePostsSubscriptionPayment(ru, *price)
+	}
+
 	var post rpc.PostMetadata
 	var updates []rpc.PostMetadataStatus
-	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+	err = c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
 		err := c.db.SubscribeToPosts(tx, ru.ID())
 		if err != nil {
 			return err
@@ -97,6 +105,9 @@ func (c *Client) handlePostsSubscribe(ru *RemoteUser, ps rpc.RMPostsSubscribe) e
 }
 
 func (c *Client) handlePostsSubscribeReply(ru *RemoteUser, psr rpc.RMPostsSubscribeReply) error {
+	if psr.Price != nil {
+		return c.handlePostsSubscriptionPaymentRequired(ru, *psr.Price)
+	}
 	if psr.Error != nil {
 		subErr := strings.TrimSpace(*psr.Error)
 		ru.log.Warnf("Received error reply when subscribing to posts: %q", subErr)
@@ -283,6 +294,23 @@ func (c *Client) handleListPostsReply(ru *RemoteUser, plr rpc.RMListPostsReply)
 func (c *Client) shareWithPostSubscribers(subs []clientintf.UserID,
 	pid clientintf.PostID, rm rpc.RMPostShare, payType string) error {
 
+	// Do not share with subscribers that have not paid for their
+	// subscription.
+	subs, err := c.dropLapsedPostsSubscribers(subs)
+	if err != nil {
+		return err
+	}
+
+	// Start tracking who was sent the post, so that future status updates
+	// are only sent to users that have it. Each subscriber is recorded once
+	// the post is sent to them.
+	err = c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		return c.db.AddPostRecipients(tx, c.PublicID(), pid)
+	})
+	if err != nil {
+		return err
+	}
+
 	if len(subs) == 0 {
 		c.log.Warnf("Attempting to share post without any subscribers")
 		return nil
@@ -307,8 +335,10 @@ func (c *Client) shareWithPostSubscribers(subs []clientintf.UserID,
 		}
 
 		// Send as a goroutine so all shares are concurrent.
+		c.startPostSend(pid, uid)
 		go func() {
 			err := ru.sendRM(rm, payEvent)
+			c.finishPostSend(ru, pid, err == nil)
 			if err != nil {
 				if !errors.Is(err, clientintf.ErrSubsysExiting) {
 					ru.log.Errorf("unable to send RMPostShare: %v", err)
@@ -559,6 +589,19 @@ func (c *Client) ListPosts() ([]clientdb.PostSummary, error) {
 	return res, err
 }
 
+// SearchPosts searches the posts created or received by the local client
+// (including their comments) for the given query, restricted by the specified
+// filters. An empty query returns all posts that match the filters.
+func (c *Client) SearchPosts(query string, filters clientdb.PostSearchFilters) ([]clientdb.PostSummary, error) {
+	var res []clientdb.PostSummary
+	err := c.dbView(func(tx clientdb.ReadTx) error {
+		var err error
+		res, err = c.db.SearchPosts(tx, query, filters)
+		return err
+	})
+	return res, err
+}
+
 // ReadReceivedPost returns the post data for the given user/post.
 func (c *Client) ReadPost(uid clientintf.UserID, pid clientintf.PostID) (rpc.PostMetadata, error) {
 	var res rpc.PostMetadata
@@ -579,7 +622,7 @@ func (c *Client) ReadPost(uid clientintf.UserID, pid clientintf.PostID) (rpc.Pos
 func (c *Client) addStatusToPost(statusFrom clientintf.UserID, pms *rpc.PostMetadataStatus) error {
 	var pid clientintf.PostID
 	var err error
-	var subs []clientintf.UserID
+	var subs, newSubs []clientintf.UserID
 	postFrom := c.PublicID()
 
 	if err = pid.FromString(pms.Link); err != nil {
@@ -591,8 +634,11 @@ func (c *Client) addStatusToPost(statusFrom clientintf.UserID, pms *rpc.PostMeta
 			if err := c.db.AddPostStatus(tx, postFrom, statusFrom, pid, pms); err != nil {
 				return err
 			}
-			var err error
-			subs, err = c.db.ListPostSubscribers(tx)
+			allSubs, err := c.db.ListPostSubscribers(tx)
+			if err != nil {
+				return err
+			}
+			subs, newSubs, err = c.splitPostRecipients(tx, pid, allSubs)
 			return err
 		})
 		if err != nil {
@@ -629,15 +675,100 @@ func (c *Client) addStatusToPost(statusFrom clientintf.UserID, pms *rpc.PostMeta
 	// Alert UI that we have a new post status.
 	c.ntfns.notifyOnPostStatusRcvd(nil, pid, statusFrom, *pms)
 
-	// Send status update to all subscribers.
-	if err := c.shareWithPostSubscribers(subs, pid, rm, "statusupdate"); err != nil {
-		return err
+	// Send status update to all subscribers that have the post.
+	if len(subs) > 0 {
+		if err := c.shareWithPostSubscribers(subs, pid, rm, "statusupdate"); err != nil {
+			return err
+		}
+	}
+
+	// Subscribers that do not have the post are only sent the post itself
+	// (along with all its updates, including this one) if the client is
+	// configured to do so.
+	if !c.cfg.SendPostsToNewSubscribers {
+		if len(newSubs) > 0 {
+			c.log.Debugf("Skipping status update on post %s for %d "+
+				"subscribers that do not have it", pid, len(newSubs))
+		}
+		return nil
+	}
+	for _, uid := range newSubs {
+		if err := c.sendPostToNewSubscriber(uid, pid); err != nil {
+			if errors.Is(err, clientintf.ErrSubsysExiting) {
+				return err
+			}
+			c.log.Errorf("Unable to send post %s to subscriber %s: %v",
+				pid, uid, err)
+		}
 	}
 	return nil
 }
 
+// splitPostRecipients splits the list of subscribers into the ones that were
+// already sent (or are being sent) the specified local post and the ones that
+// weren't.
+//
+// Posts for which no recipients were tracked are assumed to have been sent to
+// every subscriber.
+func (c *Client) splitPostRecipients(tx clientdb.ReadTx, pid clientintf.PostID,
+	subs []clientintf.UserID) (have, missing []clientintf.UserID, err error) {
+
+	recipients, err := c.db.ListPostRecipients(tx, c.PublicID(), pid)
+	if errors.Is(err, clientdb.ErrNotFound) {
+		return subs, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	isRecipient := make(map[clientintf.UserID]struct{}, len(recipients))
+	for _, uid := range recipients {
+		isRecipient[uid] = struct{}{}
+	}
+	for _, uid := range subs {
+		if _, ok := isRecipient[uid]; ok || c.isPostSendInFlight(pid, uid) {
+			have = append(have, uid)
+		} else {
+			missing = append(missing, uid)
+		}
+	}
+	return have, missing, nil
+}
+
+// sendPostToNewSubscriber sends the specified local post and its status
+// updates to a subscriber that does not have it yet.
+func (c *Client) sendPostToNewSubscriber(uid clientintf.UserID, pid clientintf.PostID) error {
+	ru, err := c.rul.byID(uid)
+	if errors.Is(err, userNotFoundError{}) {
+		c.log.Warnf("Unable to find subscriber to send post %s: %v", pid, err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var post rpc.PostMetadata
+	var updates []rpc.PostMetadataStatus
+	err = c.dbView(func(tx clientdb.ReadTx) error {
+		var err error
+		if post, err = c.db.ReadPost(tx, c.PublicID(), pid); err != nil {
+			return err
+		}
+		updates, err = c.db.ListPostStatusUpdates(tx, c.PublicID(), pid)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	ru.log.Infof("Sending post %s to subscriber that does not have it", pid)
+	return c.sendPostToUser(ru, pid, post, updates)
+}
+
 // sendPostStatus sends the given list of attributes as a status update on a
-// post.
+// post. Status updates on local posts are only shared with subscribers that
+// were sent the post; other subscribers are sent the post itself if
+// SendPostsToNewSubscribers is set in the client config.
 func (c *Client) sendPostStatus(postFrom clientintf.UserID,
 	pid clientintf.PostID, attr map[string]string) error {
 
@@ -835,14 +966,63 @@ func (c *Client) GetUserPost(from UserID, pid clientintf.PostID, includeStatus b
 	return ru.sendRM(rm, payEvent)
 }
 
+// postSendKey identifies a send of a local post to a user.
+type postSendKey struct {
+	pid clientintf.PostID
+	uid clientintf.UserID
+}
+
+// startPostSend marks the send of the given post to the user as in flight.
+// Users with in flight sends of a post are considered to have it when sending
+// status updates, given that those are sent after the post itself.
+func (c *Client) startPostSend(pid clientintf.PostID, uid clientintf.UserID) {
+	c.postSendsMtx.Lock()
+	c.postSends[postSendKey{pid: pid, uid: uid}]++
+	c.postSendsMtx.Unlock()
+}
+
+// finishPostSend finishes a send started with startPostSend. If the post was
+// sent, the user is recorded as a recipient of the post.
+func (c *Client) finishPostSend(ru *RemoteUser, pid clientintf.PostID, sent bool) {
+	if sent {
+		err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+			return c.db.AddPostRecipients(tx, c.PublicID(), pid, ru.ID())
+		})
+		if err != nil {
+			ru.log.Errorf("Unable to record recipient of post %s: %v", pid, err)
+		}
+	}
+
+	key := postSendKey{pid: pid, uid: ru.ID()}
+	c.postSendsMtx.Lock()
+	if c.postSends[key] <= 1 {
+		delete(c.postSends, key)
+	} else {
+		c.postSends[key]--
+	}
+	c.postSendsMtx.Unlock()
+}
+
+// isPostSendInFlight returns true if the given post is being sent to the user.
+func (c *Client) isPostSendInFlight(pid clientintf.PostID, uid clientintf.UserID) bool {
+	c.postSendsMtx.Lock()
+	_, ok := c.postSends[postSendKey{pid: pid, uid: uid}]
+	c.postSendsMtx.Unlock()
+	return ok
+}
+
 // sendPostToUser sends the given post to the user.
 func (c *Client) sendPostToUser(ru *RemoteUser, pid clientintf.PostID, post rpc.PostMetadata, updates []rpc.PostMetadataStatus) error {
 
 	ru.log.Infof("Sending requested post %s (IncludeStatus=%v)", pid,
 		updates != nil)
+
 	payEvent := fmt.Sprintf("posts.%s.getreply", pid.ShortLogID())
 	rm := rpc.RMPostShare(post)
-	if err := c.sendWithSendQ(payEvent, rm, ru.ID()); err != nil {
+	c.startPostSend(pid, ru.ID())
+	err := c.sendWithSendQ(payEvent, rm, ru.ID())
+	c.finishPostSend(ru, pid, err == nil)
+	if err != nil {
 		return err
 	}
 	if len(updates) > 0 {
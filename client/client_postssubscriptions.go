@@ -0,0 +1,391 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/companyzero/bisonrelay/client/clientdb"
+	"github.com/companyzero/bisonrelay/client/clientintf"
+	"github.com/companyzero/bisonrelay/rpc"
+	"github.com/decred/slog"
+)
+
+// The paid posts subscription flow is:
+//
+//          Alice (subscriber)                       Bob (author)
+//         --------------------                     --------------
+//
+//   SubscribeToPosts()
+//       \------ RMPostsSubscribe -->
+//
+//                                            handlePostsSubscribe()
+//                         <-- RMPostsSubscribeReply ------/
+//                              (price and period)
+//
+//   PayPostsSubscription()
+//       \-------- RMGetInvoice -->
+//              (PostsSubscription set)
+//
+//                                            handleGetPostsSubscriptionInvoice()
+//                               <-- RMInvoice --------/
+//
+//   (out-of-band payment)
+//
+//                                            (invoice settled)
+//                         <-- RMPostsSubscribeReply ------/
+//
+// When sharing posts, the author stops sharing with subscribers whose paid
+// period has lapsed and sends them a new RMPostsSubscribeReply with the price,
+// which is automatically paid by subscribers if it is within their auto renew
+// budget.
+
+// autoRenewBudgetPeriod is the period over which the auto renew budget of
+// posts subscriptions is specified.
+const autoRenewBudgetPeriod = 30 * 24 * time.Hour
+
+// PostsSubscriptionSettings returns the settings of paid subscriptions to
+// posts.
+func (c *Client) PostsSubscriptionSettings() (clientdb.PostsSubscriptionSettings, error) {
+	var settings clientdb.PostsSubscriptionSettings
+	err := c.dbView(func(tx clientdb.ReadTx) error {
+		var err error
+		settings, err = c.db.GetPostsSubscriptionSettings(tx)
+		return err
+	})
+	return settings, err
+}
+
+// SetPostsSubscriptionSettings replaces the settings of paid subscriptions to
+// posts. Changing the price does not affect the periods already paid for by
+// existing subscribers.
+func (c *Client) SetPostsSubscriptionSettings(settings clientdb.PostsSubscriptionSettings) error {
+	return c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		return c.db.SetPostsSubscriptionSettings(tx, settings)
+	})
+}
+
+// ListPaidPostsSubscribers returns the date until which each remote user has
+// paid for their subscription to the local client's posts.
+func (c *Client) ListPaidPostsSubscribers() (map[UserID]time.Time, error) {
+	var res map[UserID]time.Time
+	err := c.dbView(func(tx clientdb.ReadTx) error {
+		var err error
+		res, err = c.db.ListPaidPostsSubscribers(tx)
+		return err
+	})
+	return res, err
+}
+
+// postsSubscriptionPaymentDue returns the price the given user must pay to
+// subscribe to the local client's posts. It returns nil if subscriptions are
+// free or if the user has already paid for the current period.
+func (c *Client) postsSubscriptionPaymentDue(uid UserID) (*rpc.PostsSubscriptionPrice, error) {
+	var price *rpc.PostsSubscriptionPrice
+	err := c.dbView(func(tx clientdb.ReadTx) error {
+		settings, err := c.db.GetPostsSubscriptionSettings(tx)
+		if err != nil {
+			return err
+		}
+		if price = settings.SubscriptionPrice(); price == nil {
+			return nil
+		}
+		paid, err := c.db.ListPaidPostsSubscribers(tx)
+		if err != nil {
+			return err
+		}
+		if paid[uid].After(time.Now()) {
+			price = nil
+		}
+		return nil
+	})
+	return price, err
+}
+
+// requirePostsSubscriptionPayment alerts the remote user that they must pay
+// the given price to subscribe to the local client's posts.
+func (c *Client) requirePostsSubscriptionPayment(ru *RemoteUser, price rpc.PostsSubscriptionPrice) error {
+	ru.log.Infof("Requiring payment of %.8f DCR for subscription to posts",
+		float64(price.MilliAtoms)/1e11)
+	errMsg := "payment required to subscribe to posts"
+	rm := rpc.RMPostsSubscribeReply{Error: &errMsg, Price: &price}
+	return c.sendWithSendQ("posts.subscribereply", rm, ru.ID())
+}
+
+// dropLapsedPostsSubscribers returns the list of subscribers that have paid
+// for their subscription to the local client's posts. Subscribers with a
+// lapsed subscription are unsubscribed and asked to renew it.
+func (c *Client) dropLapsedPostsSubscribers(subs []clientintf.UserID) ([]clientintf.UserID, error) {
+	var lapsed []clientintf.UserID
+	var price *rpc.PostsSubscriptionPrice
+	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		settings, err := c.db.GetPostsSubscriptionSettings(tx)
+		if err != nil {
+			return err
+		}
+		if price = settings.SubscriptionPrice(); price == nil {
+			return nil
+		}
+		paid, err := c.db.ListPaidPostsSubscribers(tx)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		active := make([]clientintf.UserID, 0, len(subs))
+		for _, uid := range subs {
+			if paid[uid].After(now) {
+				active = append(active, uid)
+				continue
+			}
+
+			// Users that were already unsubscribed have already
+			// been asked to renew.
+			err := c.db.UnsubscribeToPosts(tx, uid)
+			if errors.Is(err, clientdb.ErrNotSubscribed) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			lapsed = append(lapsed, uid)
+		}
+		subs = active
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, uid := range lapsed {
+		ru, err := c.rul.byID(uid)
+		if err != nil {
+			c.log.Warnf("Unable to find lapsed posts subscriber %s: %v",
+				uid, err)
+			continue
+		}
+		ru.log.Infof("Subscription to our posts lapsed")
+		if c.cfg.SubscriptionChanged != nil {
+			c.cfg.SubscriptionChanged(ru, false)
+		}
+		if err := c.requirePostsSubscriptionPayment(ru, *price); err != nil {
+			return nil, err
+		}
+	}
+	return subs, nil
+}
+
+// handlePostsSubscriptionPaymentRequired handles a remote user requiring
+// payment for subscribing to their posts. The subscription is paid
+// automatically if its price is within the auto renew budget.
+func (c *Client) handlePostsSubscriptionPaymentRequired(ru *RemoteUser, price rpc.PostsSubscriptionPrice) error {
+	uid := ru.ID()
+	var settings clientdb.PostsSubscriptionSettings
+	var wasSubscribed bool
+	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		var err error
+		settings, err = c.db.GetPostsSubscriptionSettings(tx)
+		if err != nil {
+			return err
+		}
+		if err := c.db.SetRemotePostsSubscriptionPrice(tx, uid, price); err != nil {
+			return err
+		}
+		wasSubscribed, err = c.db.IsPostSubscription(tx, uid)
+		if err != nil || !wasSubscribed {
+			return err
+		}
+		return c.db.StorePostUnsubscription(tx, uid)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Convert the price to the budget period to decide whether to pay for
+	// it automatically.
+	var autoRenew bool
+	if period := price.Period(); period > 0 && settings.AutoRenewMaxPrice > 0 {
+		budgetPrice := float64(price.MilliAtoms) *
+			float64(autoRenewBudgetPeriod) / float64(period)
+		autoRenew = budgetPrice <= float64(settings.AutoRenewMaxPrice)
+	}
+
+	ru.log.Infof("Remote user requires %.8f DCR per %s for subscription to "+
+		"posts (auto renew: %v)", float64(price.MilliAtoms)/1e11,
+		price.Period(), autoRenew)
+	if wasSubscribed {
+		c.ntfns.notifyOnRemoteSubChanged(ru, false)
+	}
+	c.ntfns.notifyPostsSubscriptionPaymentRequired(ru, price, autoRenew)
+
+	if !autoRenew {
+		return nil
+	}
+	go func() {
+		err := c.PayPostsSubscription(c.ctx, uid)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			ru.log.Errorf("Unable to auto renew subscription to posts: %v", err)
+			c.ntfns.notifyOnRemoteSubErrored(ru, true, err.Error())
+		}
+	}()
+	return nil
+}
+
+// PayPostsSubscription pays for one period of the subscription to the posts of
+// the given user, which must have previously required payment to subscribe to
+// their posts. The subscription is confirmed by the remote user after the
+// payment completes.
+func (c *Client) PayPostsSubscription(ctx context.Context, uid UserID) error {
+	ru, err := c.rul.byID(uid)
+	if err != nil {
+		return err
+	}
+
+	var price rpc.PostsSubscriptionPrice
+	err = c.dbView(func(tx clientdb.ReadTx) error {
+		var err error
+		price, err = c.db.GetRemotePostsSubscriptionPrice(tx, uid)
+		return err
+	})
+	if errors.Is(err, clientdb.ErrNotFound) {
+		return fmt.Errorf("user %s did not require payment for "+
+			"subscribing to posts", ru)
+	}
+	if err != nil {
+		return err
+	}
+
+	amount := price.MilliAtoms
+	descr := fmt.Sprintf("subscription to posts for %s", price.Period())
+	fetchInvoice := func(ctx context.Context) (string, error) {
+		ru.log.Infof("Requesting invoice to pay subscription to posts "+
+			"(%.8f DCR)", float64(amount)/1e11)
+		getInvoice := rpc.RMGetInvoice{
+			PayScheme:         rpc.PaySchemeDCRLN,
+			MilliAtoms:        amount,
+			PostsSubscription: true,
+		}
+		ir, err := c.fetchInvoice(ctx, ru, getInvoice, "posts.getsubinvoice")
+		return ir.Invoice, err
+	}
+	record := func(tx clientdb.ReadWriteTx, amount, fees int64) error {
+		return c.db.RecordUserPayEvent(tx, uid, "posts.subscription",
+			amount, fees)
+	}
+	paid, err := c.payUserInvoice(ctx, ru, int64(amount), false, descr,
+		fetchInvoice, record)
+	if err != nil {
+		return err
+	}
+	ru.log.Infof("Paid %.8f DCR for subscription to posts", float64(paid)/1e11)
+	return nil
+}
+
+// handleGetPostsSubscriptionInvoice generates an invoice for a remote user to
+// pay for their subscription to the local client's posts. The user is
+// subscribed once the invoice is settled.
+func (c *Client) handleGetPostsSubscriptionInvoice(ru *RemoteUser, getInvoice rpc.RMGetInvoice,
+	replyWithErr func(error)) error {
+
+	var price *rpc.PostsSubscriptionPrice
+	err := c.dbView(func(tx clientdb.ReadTx) error {
+		settings, err := c.db.GetPostsSubscriptionSettings(tx)
+		price = settings.SubscriptionPrice()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if price == nil {
+		ru.log.Warnf("Requested posts subscription invoice when " +
+			"subscriptions are free")
+		replyWithErr(errors.New("subscription to posts is free"))
+		return nil
+	}
+	if getInvoice.MilliAtoms < price.MilliAtoms {
+		err := fmt.Errorf("requested amount %d lower than subscription "+
+			"price %d", getInvoice.MilliAtoms, price.MilliAtoms)
+		replyWithErr(err)
+		return err
+	}
+
+	cb := func(receivedMAtoms int64) {
+		if receivedMAtoms <= 0 {
+			return
+		}
+
+		// Only whole periods are credited to the subscriber.
+		nbPeriods := uint64(receivedMAtoms) / price.MilliAtoms
+		var until time.Time
+		var subscribed bool
+		err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+			err := c.db.RecordUserPayEvent(tx, ru.ID(),
+				"posts.subscription", receivedMAtoms, 0)
+			if err != nil {
+				return err
+			}
+			if nbPeriods == 0 {
+				return nil
+			}
+			until, err = c.db.ExtendPostsSubscriberPaidUntil(tx, ru.ID(),
+				time.Duration(nbPeriods)*price.Period())
+			if err != nil {
+				return err
+			}
+			err = c.db.SubscribeToPosts(tx, ru.ID())
+			if errors.Is(err, clientdb.ErrAlreadySubscribed) {
+				return nil
+			}
+			subscribed = err == nil
+			return err
+		})
+		if err != nil {
+			c.log.Errorf("Unable to record posts subscription payment: %v", err)
+			return
+		}
+		if nbPeriods == 0 {
+			ru.log.Warnf("Received %.8f DCR which is not enough to "+
+				"pay for subscription to posts",
+				float64(receivedMAtoms)/1e11)
+			return
+		}
+
+		ru.log.Infof("Received %.8f DCR for subscription to posts "+
+			"(paid until %s)", float64(receivedMAtoms)/1e11,
+			until.Format(time.RFC3339))
+		if subscribed && c.cfg.SubscriptionChanged != nil {
+			c.cfg.SubscriptionChanged(ru, true)
+		}
+
+		rm := rpc.RMPostsSubscribeReply{}
+		err = c.sendWithSendQ("posts.subscribereply", rm, ru.ID())
+		if err != nil && !errors.Is(err, clientintf.ErrSubsysExiting) {
+			ru.log.Errorf("Unable to send posts subscribe reply: %v", err)
+		}
+	}
+
+	dcrAmount := float64(getInvoice.MilliAtoms) / 1e11
+	inv, err := c.pc.GetInvoice(c.ctx, int64(getInvoice.MilliAtoms), cb)
+	if err != nil {
+		c.ntfns.notifyInvoiceGenFailed(ru, dcrAmount, err)
+		replyWithErr(fmt.Errorf("unable to generate payment invoice"))
+		ru.log.Warnf("Unable to generate invoice for %.8f DCR: %v",
+			dcrAmount, err)
+		return nil
+	}
+
+	if ru.log.Level() <= slog.LevelDebug {
+		ru.log.Debugf("Generated invoice for posts subscription of "+
+			"%.8f DCR: %s", dcrAmount, inv)
+	} else {
+		ru.log.Infof("Generated invoice for posts subscription of "+
+			"%.8f DCR", dcrAmount)
+	}
+
+	reply := rpc.RMInvoice{
+		Invoice: inv,
+		Tag:     getInvoice.Tag,
+	}
+	return ru.sendRM(reply, "getinvoicereply")
+}
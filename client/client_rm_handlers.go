@@ -98,6 +98,20 @@ func (c *Client) handleTransitiveMsgFwd(ru *RemoteUser, fwd rpc.RMTransitiveMess
 	}
 }
 
+// handlePM handles a PM received from a remote user.
+func (c *Client) handlePM(ru *RemoteUser, p rpc.RMPrivateMessage, ts time.Time) error {
+	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		return c.db.LogPM(tx, ru.ID(), false, ru.Nick(), p.Message, ts)
+	})
+	if err != nil {
+		return err
+	}
+	ru.log.Debugf("Received private message of length %d", len(p.Message))
+
+	c.ntfns.notifyOnPM(ru, p, ts)
+	return nil
+}
+
 func (c *Client) innerHandleUserRM(ru *RemoteUser, h *rpc.RMHeader,
 	p interface{}, ts time.Time) error {
 
@@ -110,15 +124,11 @@ func (c *Client) innerHandleUserRM(ru *RemoteUser, h *rpc.RMHeader,
 			return nil
 		}
 
-		err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
-			return c.db.LogPM(tx, ru.ID(), false, ru.Nick(), p.Message, ts)
-		})
-		if err != nil {
+		held, err := c.holdForPostage(ru, clientdb.HeldMessage{Timestamp: ts, PM: &p})
+		if held || err != nil {
 			return err
 		}
-		ru.log.Debugf("Received private message of length %d", len(p.Message))
-
-		c.ntfns.notifyOnPM(ru, p, ts)
+		return c.handlePM(ru, p, ts)
 
 	case rpc.RMGroupInvite:
 		return c.handleGCInvite(ru, p)
@@ -140,8 +150,22 @@ func (c *Client) innerHandleUserRM(ru *RemoteUser, h *rpc.RMHeader,
 			ru.log.Tracef("Ignoring received GC message")
 			return nil
 		}
+
+		held, err := c.holdForPostage(ru, clientdb.HeldMessage{Timestamp: ts, GCM: &p})
+		if held || err != nil {
+			return err
+		}
 		return c.handleGCMessage(ru, p, ts)
 
+	case rpc.RMPostageRequired:
+		return c.handlePostageRequired(ru, p)
+
+	case rpc.RMPaymentRequest:
+		return c.handlePaymentRequest(ru, p)
+
+	case rpc.RMPaymentRequestDeclined:
+		return c.handlePaymentRequestDeclined(ru, p)
+
 	case rpc.RMMediateIdentity:
 		return c.handleMediateID(ru, p)
 
@@ -175,6 +199,9 @@ func (c *Client) innerHandleUserRM(ru *RemoteUser, h *rpc.RMHeader,
 	case rpc.RMFTSendFile:
 		return c.handleFTSendFile(ru, p)
 
+	case rpc.RMFTCancel:
+		return c.handleFTCancel(ru, p)
+
 	case rpc.RMTransitiveMessage:
 		return c.handleTransitiveMsg(ru, p)
 
@@ -217,6 +244,9 @@ func (c *Client) innerHandleUserRM(ru *RemoteUser, h *rpc.RMHeader,
 	case rpc.RMPostStatusReply:
 		return c.handlePostStatusReply(ru, p)
 
+	case rpc.RMPostGetReply:
+		return c.handlePostGetReply(ru, p)
+
 	case rpc.RMGroupKick:
 		return c.handleGCKick(ru, p)
 
@@ -239,8 +269,6 @@ func (c *Client) innerHandleUserRM(ru *RemoteUser, h *rpc.RMHeader,
 		return fmt.Errorf("Received unknown command %q payload %T",
 			h.Command, p)
 	}
-
-	return nil
 }
 
 // handleUserRM is the main handler for remote user RoutedMessages. It decides
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+
+	"github.com/companyzero/bisonrelay/client/clientdb"
+	"github.com/companyzero/bisonrelay/client/internal/lowlevel"
+	"github.com/companyzero/bisonrelay/rpc"
+)
+
+// Servers that accept prepaid credit allow clients to pay for pushes and
+// subscriptions from a balance bought in advance, instead of paying one
+// invoice per batch of messages and subscriptions. The credit is associated
+// to a random account ID (that is not linked to the client identity) and
+// every change to its balance is acknowledged by the server with a signed
+// receipt.
+//
+// Once the credit runs out, payments fall back to individual invoices.
+
+// loadServerCredit loads (or creates, if needed) the account of the credit
+// prepaid to the server and sets up the subsystems to use it.
+func (c *Client) loadServerCredit(ctx context.Context) error {
+	var state clientdb.ServerCreditState
+	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		var err error
+		state, err = c.db.GetServerCreditState(tx)
+		if !errors.Is(err, clientdb.ErrNotFound) {
+			return err
+		}
+
+		// Create a new account.
+		if _, err := rand.Read(state.Account[:]); err != nil {
+			return err
+		}
+		return c.db.SetServerCreditState(tx, state)
+	})
+	if err != nil {
+		return err
+	}
+
+	crdb := &serverCreditDBAdapter{c: c}
+	c.credit = lowlevel.NewServerCredit(c.cfg.logger("CRDT"), crdb, state.Account)
+	c.q.UseServerCredit(c.credit)
+	c.rmgr.UseServerCredit(c.credit)
+	return nil
+}
+
+// ServerCreditBalance redeems any pending credit purchases and returns the
+// balance (in milliatoms) of the credit prepaid to the server.
+func (c *Client) ServerCreditBalance(ctx context.Context) (uint64, error) {
+	return c.credit.FetchBalance(ctx)
+}
+
+// BuyServerCredit pays the given amount (in milliatoms) to the server to be
+// used as prepaid credit. It returns the new balance of the credit and the
+// fees paid.
+func (c *Client) BuyServerCredit(ctx context.Context, amount uint64) (uint64, int64, error) {
+	if amount == 0 {
+		return 0, 0, errors.New("cannot buy zero credit")
+	}
+	return c.credit.Purchase(ctx, amount)
+}
+
+// ListServerCreditReceipts lists the receipts issued by the server for the
+// changes in the balance of the prepaid credit.
+func (c *Client) ListServerCreditReceipts() ([]rpc.CreditReceipt, error) {
+	var res []rpc.CreditReceipt
+	err := c.dbView(func(tx clientdb.ReadTx) error {
+		var err error
+		res, err = c.db.ListCreditReceipts(tx)
+		return err
+	})
+	return res, err
+}
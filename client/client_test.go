@@ -3,8 +3,11 @@ package client
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/companyzero/bisonrelay/rpc"
 )
 
 // TestCanceledRunTerminates ensures running with a canceled context correctly
@@ -39,3 +42,116 @@ func TestCanceledRunTerminates(t *testing.T) {
 		t.Fatal("timeout waiting for Run() to complete")
 	}
 }
+
+// TestChunkInvoiceRetryBackoff tests the delay before requesting new invoices
+// for chunks whose invoices expired.
+func TestChunkInvoiceRetryBackoff(t *testing.T) {
+	tests := []struct {
+		retries int
+		want    time.Duration
+	}{
+		{retries: 0, want: 0},
+		{retries: 1, want: 0},
+		{retries: 2, want: chunkInvoiceRetryDelay},
+		{retries: 3, want: chunkInvoiceRetryDelay * 2},
+		{retries: 4, want: chunkInvoiceRetryDelay * 4},
+		{retries: 100, want: maxChunkInvoiceRetryDelay},
+	}
+	for _, tc := range tests {
+		got := chunkInvoiceRetryBackoff(tc.retries)
+		if got != tc.want {
+			t.Fatalf("retries %d: unexpected delay. got %s, want %s",
+				tc.retries, got, tc.want)
+		}
+	}
+}
+
+// TestVerifyTipMemo tests the verification of the signed memos sent along with
+// tips.
+func TestVerifyTipMemo(t *testing.T) {
+	rnd := testRand(t)
+	aliceID := testID(t, rnd, "alice")
+	bobID := testID(t, rnd, "bob")
+	alice := &Client{id: aliceID}
+	bob := &Client{id: bobID}
+	aliceRemote := newRemoteUser(nil, nil, nil, &aliceID.Public, bobID, nil)
+
+	const amount = 1e8
+	tests := []struct {
+		name    string
+		tip     func() *rpc.TipMemo
+		wantErr bool
+	}{{
+		name: "signed memo",
+		tip:  func() *rpc.TipMemo { return alice.signTipMemo("thanks", nil, amount) },
+	}, {
+		name: "empty memo",
+		tip:  func() *rpc.TipMemo { return alice.signTipMemo("", nil, amount) },
+	}, {
+		name: "memo signed for different amount",
+		tip: func() *rpc.TipMemo {
+			return alice.signTipMemo("thanks", nil, amount+1)
+		},
+		wantErr: true,
+	}, {
+		name: "changed memo",
+		tip: func() *rpc.TipMemo {
+			tip := alice.signTipMemo("thanks", nil, amount)
+			tip.Memo = "thanks!"
+			return tip
+		},
+		wantErr: true,
+	}, {
+		name:    "memo signed by other user",
+		tip:     func() *rpc.TipMemo { return bob.signTipMemo("thanks", nil, amount) },
+		wantErr: true,
+	}, {
+		name: "memo too long",
+		tip: func() *rpc.TipMemo {
+			memo := strings.Repeat("x", rpc.MaxTipMemoLen+1)
+			return alice.signTipMemo(memo, nil, amount)
+		},
+		wantErr: true,
+	}, {
+		name: "oversized signature",
+		tip: func() *rpc.TipMemo {
+			tip := alice.signTipMemo("thanks", nil, amount)
+			tip.Signature += "00"
+			return tip
+		},
+		wantErr: true,
+	}, {
+		name: "truncated signature",
+		tip: func() *rpc.TipMemo {
+			tip := alice.signTipMemo("thanks", nil, amount)
+			tip.Signature = tip.Signature[:len(tip.Signature)-2]
+			return tip
+		},
+		wantErr: true,
+	}, {
+		name: "signature not hex",
+		tip: func() *rpc.TipMemo {
+			tip := alice.signTipMemo("thanks", nil, amount)
+			tip.Signature = "zz" + tip.Signature[2:]
+			return tip
+		},
+		wantErr: true,
+	}, {
+		name:    "missing signature",
+		tip:     func() *rpc.TipMemo { return &rpc.TipMemo{Memo: "thanks"} },
+		wantErr: true,
+	}}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := bob.verifyTipMemo(aliceRemote, tc.tip(), amount)
+			if tc.wantErr && err == nil {
+				t.Fatalf("unexpected success verifying tip memo")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,532 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/companyzero/bisonrelay/client/clientdb"
+	"github.com/companyzero/bisonrelay/client/clientintf"
+	"github.com/companyzero/bisonrelay/rpc"
+)
+
+// errTransferPaused is used internally to stop processing paused transfers.
+var errTransferPaused = errors.New("transfer is paused")
+
+// ftListTagFindSources is the tag of RMFTList requests sent when looking for
+// additional sources for downloads. Replies to these are not reported as
+// content listings.
+const ftListTagFindSources uint32 = 1
+
+// findDownload returns the outstanding download of the given file.
+func (c *Client) findDownload(tx clientdb.ReadTx, fid clientdb.FileID) (clientdb.FileDownload, error) {
+	fds, err := c.db.ListOutstandingDownloads(tx)
+	if err != nil {
+		return clientdb.FileDownload{}, err
+	}
+	for _, fd := range fds {
+		if fd.FID == fid {
+			return fd, nil
+		}
+	}
+	return clientdb.FileDownload{}, fmt.Errorf("download of file %s: %w",
+		fid, clientdb.ErrNotFound)
+}
+
+// PauseDownload pauses the download of the given file. No new chunks are
+// requested or paid for while the download is paused, but chunks that were
+// already paid for are still received.
+func (c *Client) PauseDownload(fid clientdb.FileID) error {
+	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		_, err := c.db.SetFileDownloadPaused(tx, fid, true)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	c.log.Infof("Paused download of file %s", fid)
+	return nil
+}
+
+// ResumeDownload resumes a previously paused download.
+func (c *Client) ResumeDownload(fid clientdb.FileID) error {
+	var fd clientdb.FileDownload
+	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		var err error
+		fd, err = c.db.SetFileDownloadPaused(tx, fid, false)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	ru, err := c.rul.byID(fd.UID)
+	if err != nil {
+		return err
+	}
+	ru.log.Infof("Resuming download of file %s", fid)
+
+	if fd.IsSentFile {
+		// Chunks are pushed by the uploader, so there's nothing to
+		// request.
+		return nil
+	}
+
+	if fd.Metadata == nil {
+		// Metadata was never received. Request it again.
+		rm := rpc.RMFTGet{FileID: fid.String()}
+		payEvent := fmt.Sprintf("ftget.%s", fid.ShortLogID())
+		return ru.sendRM(rm, payEvent)
+	}
+
+	go func() {
+		err := c.downloadChunks(ru, fd)
+		if err != nil && !errors.Is(err, clientintf.ErrSubsysExiting) {
+			ru.log.Errorf("Unable to download file chunk: %v", err)
+		}
+	}()
+	return nil
+}
+
+// CancelDownload cancels the download of the given file, removing all data
+// downloaded so far. The uploader is notified of the cancellation so that it
+// stops sending invoices and chunks.
+func (c *Client) CancelDownload(fid clientdb.FileID) error {
+	var fd clientdb.FileDownload
+	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		var err error
+		fd, err = c.findDownload(tx, fid)
+		if err != nil {
+			return err
+		}
+		return c.db.CancelFileDownload(tx, fid)
+	})
+	if err != nil {
+		return err
+	}
+
+	c.log.Infof("Canceled download of file %s", fid)
+
+	// Notify the uploader and every additional source of the download.
+	for _, uid := range fd.SourceUIDs() {
+		if _, err := c.rul.byID(uid); err != nil {
+			c.log.Warnf("Unable to notify uploader %s of canceled download: %v",
+				uid, err)
+			continue
+		}
+		srcFID, _ := fd.SourceFID(uid)
+		rm := rpc.RMFTCancel{FileID: srcFID.String()}
+		payEvent := fmt.Sprintf("ftcancel.%s", srcFID.ShortLogID())
+		if err := c.sendWithSendQ(payEvent, rm, uid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListUploads lists the outstanding uploads of files to remote users.
+func (c *Client) ListUploads() ([]clientdb.FileUpload, error) {
+	var res []clientdb.FileUpload
+	err := c.dbView(func(tx clientdb.ReadTx) error {
+		var err error
+		res, err = c.db.ListFileUploads(tx)
+		return err
+	})
+	return res, err
+}
+
+// TransferStatus is the status of an in-progress upload or download, used to
+// show both kinds of transfers in a single view.
+type TransferStatus struct {
+	IsUpload bool `json:"is_upload"`
+
+	// UID is the remote user: the uploader of downloads, or the user the
+	// file is being uploaded to.
+	UID UserID          `json:"uid"`
+	FID clientdb.FileID `json:"fid"`
+
+	Filename string `json:"filename"`
+	Size     uint64 `json:"size"`
+	NbChunks int    `json:"nb_chunks"`
+	Paused   bool   `json:"paused"`
+
+	// ChunkStates is the state of each chunk with a known state.
+	ChunkStates map[int]clientdb.ChunkState `json:"chunk_states"`
+
+	DoneChunks int    `json:"done_chunks"`
+	DoneBytes  uint64 `json:"done_bytes"`
+
+	// MAtoms is the amount paid for (downloads) or received for (uploads)
+	// the chunks of the file.
+	MAtoms int64 `json:"matoms"`
+
+	StartTime time.Time `json:"start_time"`
+
+	// Rate is the average transfer rate, in bytes per second.
+	Rate float64 `json:"rate"`
+
+	// ETA is the estimated time to complete the transfer at the average
+	// rate. It is zero if the rate is still unknown.
+	ETA time.Duration `json:"eta"`
+}
+
+// fillRate fills the rate and ETA of the transfer.
+func (ts *TransferStatus) fillRate(now time.Time) {
+	elapsed := now.Sub(ts.StartTime).Seconds()
+	if ts.StartTime.IsZero() || elapsed <= 0 || ts.DoneBytes == 0 {
+		return
+	}
+	ts.Rate = float64(ts.DoneBytes) / elapsed
+	if ts.Size > ts.DoneBytes {
+		secs := float64(ts.Size-ts.DoneBytes) / ts.Rate
+		ts.ETA = time.Duration(secs * float64(time.Second))
+	}
+}
+
+// ListTransfers lists all outstanding uploads and downloads.
+func (c *Client) ListTransfers() ([]TransferStatus, error) {
+	var res []TransferStatus
+	now := time.Now()
+	err := c.dbView(func(tx clientdb.ReadTx) error {
+		fds, err := c.db.ListOutstandingDownloads(tx)
+		if err != nil {
+			return err
+		}
+		for _, fd := range fds {
+			ts := TransferStatus{
+				UID:         fd.UID,
+				FID:         fd.FID,
+				Paused:      fd.Paused,
+				ChunkStates: fd.ChunkStates,
+				MAtoms:      fd.PaidMAtoms,
+				StartTime:   fd.StartTime,
+			}
+			if fd.Metadata != nil {
+				ts.Filename = fd.Metadata.Filename
+				ts.Size = fd.Metadata.Size
+				ts.NbChunks = fd.Metadata.ChunkCount()
+				for idx, cs := range fd.ChunkStates {
+					if cs != clientdb.ChunkStateDownloaded {
+						continue
+					}
+					ts.DoneChunks += 1
+					ts.DoneBytes += fd.Metadata.ChunkLen(idx)
+				}
+			}
+			ts.fillRate(now)
+			res = append(res, ts)
+		}
+
+		ups, err := c.db.ListFileUploads(tx)
+		if err != nil {
+			return err
+		}
+		for _, up := range ups {
+			ts := TransferStatus{
+				IsUpload:    true,
+				UID:         up.UID,
+				FID:         up.FID,
+				Paused:      up.Paused,
+				ChunkStates: make(map[int]clientdb.ChunkState, len(up.Chunks)),
+				DoneChunks:  len(up.Stats.SentChunks),
+				DoneBytes:   up.Stats.SentBytes,
+				MAtoms:      up.Stats.PaidMAtoms,
+				StartTime:   up.Stats.StartTime,
+			}
+			if _, md, err := c.db.GetSharedFileForUpload(tx, up.UID, up.FID); err == nil {
+				ts.Filename = md.Filename
+				ts.Size = md.Size
+				ts.NbChunks = md.ChunkCount()
+			}
+			for _, idx := range up.Stats.SentChunks {
+				ts.ChunkStates[idx] = clientdb.ChunkStateUploaded
+			}
+			for _, cup := range up.Chunks {
+				cs := cup.State
+				if cup.Paid > 0 {
+					cs = clientdb.ChunkStatePaid
+				}
+				ts.ChunkStates[cup.Index] = cs
+			}
+			ts.fillRate(now)
+			res = append(res, ts)
+		}
+		return nil
+	})
+	return res, err
+}
+
+// PauseUpload pauses the upload of the given file to the given user. While
+// paused, requests for chunks are recorded but no invoices or chunks are sent.
+func (c *Client) PauseUpload(uid UserID, fid clientdb.FileID) error {
+	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		if _, _, err := c.db.GetSharedFileForUpload(tx, uid, fid); err != nil {
+			return err
+		}
+		return c.db.SetFileUploadPaused(tx, uid, fid, true)
+	})
+	if err != nil {
+		return err
+	}
+	c.log.Infof("Paused upload of file %s to %s", fid, uid)
+	return nil
+}
+
+// ResumeUpload resumes the upload of the given file to the given user,
+// processing any chunks that were requested or paid for while the upload was
+// paused.
+func (c *Client) ResumeUpload(uid UserID, fid clientdb.FileID) error {
+	ru, err := c.rul.byID(uid)
+	if err != nil {
+		return err
+	}
+
+	var sends []func()
+	err = c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		if !c.db.IsFileUploadPaused(tx, uid, fid) {
+			return fmt.Errorf("upload of file %s is not paused", fid)
+		}
+		if err := c.db.SetFileUploadPaused(tx, uid, fid, false); err != nil {
+			return err
+		}
+
+		cups, err := c.db.ListOutstandingUploads(tx)
+		if err != nil {
+			return err
+		}
+
+		ru.log.Infof("Resuming upload of file %s", fid)
+		for _, cup := range cups {
+			if cup.UID != uid || cup.FID != fid {
+				continue
+			}
+			send, err := c.processChunkUpload(c.ctx, tx, ru, cup)
+			if err != nil {
+				return err
+			}
+			if send != nil {
+				sends = append(sends, send)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Send the invoices and paid chunks only after the DB transaction
+	// has been committed.
+	for _, send := range sends {
+		send()
+	}
+	return nil
+}
+
+// CancelUpload cancels the upload of the given file to the given user. The
+// downloader is notified of the cancellation so that it stops requesting
+// chunks.
+func (c *Client) CancelUpload(uid UserID, fid clientdb.FileID) error {
+	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		return c.db.CancelFileUpload(tx, uid, fid)
+	})
+	if err != nil {
+		return err
+	}
+
+	c.log.Infof("Canceled upload of file %s to %s", fid, uid)
+
+	rm := rpc.RMFTCancel{FileID: fid.String(), Upload: true}
+	payEvent := fmt.Sprintf("ftcancel.%s", fid.ShortLogID())
+	return c.sendWithSendQ(payEvent, rm, uid)
+}
+
+// handleFTCancel handles a remote user canceling a file transfer with the
+// local client.
+func (c *Client) handleFTCancel(ru *RemoteUser, fc rpc.RMFTCancel) error {
+	var fid clientdb.FileID
+	if err := fid.FromString(fc.FileID); err != nil {
+		return err
+	}
+
+	// When the remote user canceled an upload, the local client was
+	// downloading the file (and vice-versa).
+	isUpload := !fc.Upload
+	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		if isUpload {
+			return c.db.CancelFileUpload(tx, ru.ID(), fid)
+		}
+
+		// Only the uploader may cancel the download. When an
+		// additional source cancels, it is only removed from the
+		// list of sources.
+		fd, err := c.db.ReadFileDownloadBySource(tx, ru.ID(), fid)
+		if err != nil {
+			return err
+		}
+		if fd.CompletedName != "" {
+			return clientdb.ErrNotFound
+		}
+		if fd.UID != ru.ID() {
+			return c.db.RemoveFileDownloadSource(tx, &fd, ru.ID())
+		}
+		return c.db.CancelFileDownload(tx, fid)
+	})
+	if errors.Is(err, clientdb.ErrNotFound) {
+		ru.log.Debugf("Received cancellation of unknown transfer of file %s",
+			fid)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if isUpload {
+		ru.log.Infof("Remote user canceled download of file %s", fid)
+	} else {
+		ru.log.Infof("Remote user canceled upload of file %s", fid)
+	}
+	c.ntfns.notifyTransferCanceled(ru, fid, isUpload)
+	return nil
+}
+
+// sameFileContent returns true if both metadata refer to the same file
+// content, split into the same chunks.
+func sameFileContent(a, b *rpc.FileMetadata) bool {
+	if a.Hash != b.Hash || a.Size != b.Size || len(a.Manifest) != len(b.Manifest) {
+		return false
+	}
+	if a.IsMerkle() != b.IsMerkle() || a.ChunkSize != b.ChunkSize ||
+		!bytes.Equal(a.MerkleRoot, b.MerkleRoot) {
+		return false
+	}
+	for i := range a.Manifest {
+		if a.Manifest[i].Size != b.Manifest[i].Size ||
+			!bytes.Equal(a.Manifest[i].Hash, b.Manifest[i].Hash) {
+			return false
+		}
+	}
+	return true
+}
+
+// FindDownloadSources asks the given remote users to list their shared files,
+// so that users sharing the same content as the given download are added as
+// additional sources for it. Chunks of the download are then fetched from all
+// sources in parallel.
+//
+// Listing content requires a paid push to each user, so only the users chosen
+// by the caller are asked.
+func (c *Client) FindDownloadSources(fid clientdb.FileID, uids []UserID) error {
+	if len(uids) == 0 {
+		return fmt.Errorf("no users specified to find sources")
+	}
+
+	var fd clientdb.FileDownload
+	err := c.dbView(func(tx clientdb.ReadTx) error {
+		var err error
+		fd, err = c.findDownload(tx, fid)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if fd.Metadata == nil {
+		return fmt.Errorf("metadata for file %s not received yet", fid)
+	}
+	if fd.IsSentFile {
+		return fmt.Errorf("download %s is sent by the uploader", fid)
+	}
+
+	rus := make([]*RemoteUser, 0, len(uids))
+	for _, uid := range uids {
+		if _, ok := fd.SourceFID(uid); ok {
+			continue
+		}
+		ru, err := c.rul.byID(uid)
+		if err != nil {
+			return err
+		}
+		rus = append(rus, ru)
+	}
+
+	rm := rpc.RMFTList{
+		Directories: []string{rpc.RMFTDGlobal, rpc.RMFTDShared},
+		Tag:         ftListTagFindSources,
+	}
+	for _, ru := range rus {
+		if err := ru.sendRM(rm, "ftlist"); err != nil {
+			ru.log.Warnf("Unable to list content of user: %v", err)
+		}
+	}
+	return nil
+}
+
+// addDownloadSources adds the remote user as a source of every outstanding
+// download of a file with the same content as one of the listed files.
+func (c *Client) addDownloadSources(ru *RemoteUser, files []rpc.FileMetadata) error {
+	var added []clientdb.FileDownload
+	err := c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		fds, err := c.db.ListOutstandingDownloads(tx)
+		if err != nil {
+			return err
+		}
+		for i := range fds {
+			fd := &fds[i]
+			if fd.Metadata == nil || fd.IsSentFile || fd.UID == ru.ID() {
+				continue
+			}
+			for j := range files {
+				if !sameFileContent(fd.Metadata, &files[j]) {
+					continue
+				}
+
+				// Only add sources that do not charge more than
+				// the cost confirmed for the download.
+				if files[j].Cost > fd.Metadata.Cost {
+					ru.log.Infof("Not adding user as source of "+
+						"download %s: cost %d > confirmed cost %d",
+						fd.FID, files[j].Cost, fd.Metadata.Cost)
+					break
+				}
+				src := clientdb.DownloadSource{
+					UID:  ru.ID(),
+					FID:  files[j].MetadataHash(),
+					Cost: files[j].Cost,
+				}
+				ok, err := c.db.AddFileDownloadSource(tx, fd, src)
+				if err != nil {
+					return err
+				}
+				if ok {
+					added = append(added, *fd)
+				}
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, fd := range added {
+		fd := fd
+		ru.log.Infof("Added user as source of download %s", fd.FID)
+		c.ntfns.notifyDownloadSourceAdded(ru, fd.FID)
+
+		if fd.Paused {
+			continue
+		}
+		origRU, err := c.rul.byID(fd.UID)
+		if err != nil {
+			continue
+		}
+		go func() {
+			err := c.downloadChunks(origRU, fd)
+			if err != nil && !errors.Is(err, clientintf.ErrSubsysExiting) {
+				origRU.log.Errorf("Unable to download file chunk: %v", err)
+			}
+		}()
+	}
+	return nil
+}
@@ -50,10 +50,22 @@ type Config struct {
 	// no chunking.
 	ChunkSize int
 
+	// MerkleManifestChunks is the minimum number of chunks of a file for
+	// it to be shared with metadata that commits to its chunks through a
+	// Merkle root, instead of listing every chunk. Values <= 0 mean files
+	// are always shared with the full list of chunks.
+	MerkleManifestChunks int
+
 	// DownloadsRoot is where to put final downloaded files.
 	DownloadsRoot string
 }
 
+// DefaultMerkleManifestChunks is the default minimum number of chunks of a
+// file for it to be shared with Merkle metadata. With the max chunk size, this
+// means files of 1 GiB and larger, whose full manifest would take a sizeable
+// part of the max message size.
+const DefaultMerkleManifestChunks = 1024
+
 type DB struct {
 	cfg          Config
 	log          slog.Logger
@@ -74,6 +86,14 @@ type DB struct {
 
 	payStats map[string]UserPayStats
 
+	// payReservations are the amounts reserved for outbound payments that
+	// were approved but not yet recorded.
+	payReservations []paymentReservation
+
+	// postsIdx is the full-text index of posts. It is nil until the first
+	// search is performed.
+	postsIdx *postsIndex
+
 	blockedIDs map[string]time.Time
 }
 
@@ -0,0 +1,317 @@
+package clientdb
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/companyzero/bisonrelay/internal/strescape"
+	"github.com/companyzero/bisonrelay/rpc"
+)
+
+const collectionsDir = "collections"
+
+// collectionsShareDir returns the dir where collections shared with the given
+// user (or globally, if nil) are stored.
+func (db *DB) collectionsShareDir(uid *UserID) string {
+	if uid == nil {
+		return filepath.Join(db.root, sharedContentDir, collectionsDir)
+	}
+	return filepath.Join(db.root, inboundDir, uid.String(), sharedContentDir,
+		collectionsDir)
+}
+
+// splitCollectionCost splits the total cost of a collection among its files,
+// proportionally to their sizes. The last file absorbs any rounding
+// remainder, so that the costs add up to the total.
+func splitCollectionCost(total uint64, sizes []uint64) []uint64 {
+	res := make([]uint64, len(sizes))
+	if len(sizes) == 0 || total == 0 {
+		return res
+	}
+
+	var totalSize uint64
+	for _, s := range sizes {
+		totalSize += s
+	}
+
+	var assigned uint64
+	bigTotal := new(big.Int).SetUint64(total)
+	for i := 0; i < len(sizes)-1; i++ {
+		if totalSize == 0 {
+			res[i] = total / uint64(len(sizes))
+		} else {
+			c := new(big.Int).SetUint64(sizes[i])
+			c.Mul(c, bigTotal)
+			c.Div(c, new(big.Int).SetUint64(totalSize))
+			res[i] = c.Uint64()
+		}
+		assigned += res[i]
+	}
+	res[len(res)-1] = total - assigned
+	return res
+}
+
+// ShareCollection shares all regular files inside the given dir (recursively)
+// as a collection. Each file is shared individually (as with ShareFile) and
+// the collection manifest lists the files with their paths relative to the
+// dir.
+//
+// The total cost (in atoms) is split among the files proportionally to their
+// size. Files that were already shared keep their existing cost, so the
+// total cost of the collection may differ from the specified one.
+//
+// If uid is nil, then the collection is shared among all users.
+func (db *DB) ShareCollection(tx ReadWriteTx, dir string, uid *UserID,
+	name string, cost uint64, descr string,
+	sign func([]byte) ([]byte, error)) (SharedCollection, error) {
+
+	var sc SharedCollection
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return sc, err
+	}
+	if name == "" {
+		name = filepath.Base(dir)
+	}
+
+	// List the files of the collection. The contents of shared files are
+	// stored by their base name, so files with the same name can't be
+	// shared in the same collection.
+	var relPaths []string
+	var sizes []uint64
+	names := make(map[string]string)
+	err = filepath.WalkDir(dir, func(fpath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, fpath)
+		if err != nil {
+			return err
+		}
+		if other, ok := names[d.Name()]; ok {
+			return fmt.Errorf("files %q and %q have the same name",
+				other, rel)
+		}
+		names[d.Name()] = rel
+		relPaths = append(relPaths, rel)
+		sizes = append(sizes, uint64(info.Size()))
+		return nil
+	})
+	if err != nil {
+		return sc, err
+	}
+	if len(relPaths) == 0 {
+		return sc, fmt.Errorf("dir %s does not have any files to share", dir)
+	}
+
+	// Share each file.
+	costs := splitCollectionCost(cost, sizes)
+	fc := rpc.FileCollection{
+		Name:        name,
+		Description: descr,
+		Files:       make([]rpc.CollectionFile, len(relPaths)),
+	}
+	for i, rel := range relPaths {
+		f, md, err := db.ShareFile(tx, filepath.Join(dir, rel), uid,
+			costs[i], descr, false, sign)
+		if err != nil {
+			return sc, fmt.Errorf("unable to share file %q: %w", rel, err)
+		}
+		fc.Files[i] = rpc.CollectionFile{
+			Path:   filepath.ToSlash(rel),
+			FileID: f.FID.String(),
+			Size:   md.Size,
+			Cost:   md.Cost,
+		}
+		fc.Size += md.Size
+		fc.Cost += md.Cost
+	}
+
+	sig, err := sign(fc.SignedHash())
+	if err != nil {
+		return sc, fmt.Errorf("unable to sign collection: %w", err)
+	}
+	fc.Signature = hex.EncodeToString(sig)
+
+	sc = SharedCollection{
+		ID:         fc.ID(),
+		Dir:        dir,
+		UID:        uid,
+		Collection: fc,
+	}
+	fname := filepath.Join(db.collectionsShareDir(uid), sc.ID.String())
+	if err := db.saveJsonFile(fname, sc); err != nil {
+		return sc, err
+	}
+	return sc, nil
+}
+
+// ListSharedCollections lists the collections shared with the given user or
+// the collections shared with all users (if uid is nil).
+func (db *DB) ListSharedCollections(tx ReadTx, uid *UserID) ([]SharedCollection, error) {
+	dir := db.collectionsShareDir(uid)
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("unable to read collections dir: %v", err)
+	}
+
+	res := make([]SharedCollection, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || len(entry.Name()) != 64 {
+			continue
+		}
+		var sc SharedCollection
+		fname := filepath.Join(dir, entry.Name())
+		if err := db.readJsonFile(fname, &sc); err != nil {
+			return nil, err
+		}
+		res = append(res, sc)
+	}
+	return res, nil
+}
+
+// ListAllSharedCollections lists both globally and user shared collections.
+func (db *DB) ListAllSharedCollections(tx ReadTx) ([]SharedCollection, error) {
+	res, err := db.ListSharedCollections(tx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	pattern := filepath.Join(db.root, inboundDir, "*", sharedContentDir,
+		collectionsDir, "*")
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("unable to execute glob: %v", err)
+	}
+	for _, fname := range files {
+		var sc SharedCollection
+		if err := db.readJsonFile(fname, &sc); err != nil {
+			db.log.Warnf("Unable to read %s: %v", fname, err)
+			continue
+		}
+		res = append(res, sc)
+	}
+	return res, nil
+}
+
+// GetSharedCollectionForUpload returns the given collection if it is shared
+// with the user or globally.
+func (db *DB) GetSharedCollectionForUpload(tx ReadTx, uid UserID, id FileID) (SharedCollection, error) {
+	var sc SharedCollection
+	fname := filepath.Join(db.collectionsShareDir(nil), id.String())
+	err := db.readJsonFile(fname, &sc)
+	if errors.Is(err, ErrNotFound) {
+		fname = filepath.Join(db.collectionsShareDir(&uid), id.String())
+		err = db.readJsonFile(fname, &sc)
+	}
+	if errors.Is(err, ErrNotFound) {
+		return sc, fmt.Errorf("collection %s: %w", id, err)
+	}
+	return sc, err
+}
+
+// UnshareCollection stops sharing the given collection with the given user (or
+// globally, if uid is nil). Files of the collection are unshared, unless they
+// are also part of another collection in the same share.
+func (db *DB) UnshareCollection(tx ReadWriteTx, id FileID, uid *UserID) error {
+	var sc SharedCollection
+	fname := filepath.Join(db.collectionsShareDir(uid), id.String())
+	if err := db.readJsonFile(fname, &sc); err != nil {
+		return err
+	}
+	if err := os.Remove(fname); err != nil {
+		return err
+	}
+
+	others, err := db.ListSharedCollections(tx, uid)
+	if err != nil {
+		return err
+	}
+	inUse := make(map[string]struct{})
+	for _, other := range others {
+		for _, f := range other.Collection.Files {
+			inUse[f.FileID] = struct{}{}
+		}
+	}
+
+	for _, f := range sc.Collection.Files {
+		if _, ok := inUse[f.FileID]; ok {
+			continue
+		}
+		var fid FileID
+		if err := fid.FromString(f.FileID); err != nil {
+			return err
+		}
+		err := db.UnshareFile(tx, fid, uid)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// CollectionFileDestDir returns the dir (relative to the downloads dir of a
+// user) where the given file of a collection is placed when downloaded. Path
+// elements are escaped so that the result is always inside the dir of the
+// collection.
+func CollectionFileDestDir(fc *rpc.FileCollection, f *rpc.CollectionFile) string {
+	elems := []string{strescape.PathElement(fc.Name)}
+	for _, el := range strings.Split(path.Dir(f.Path), "/") {
+		el = strescape.PathElement(el)
+		if el == "" || el == "." || el == ".." {
+			continue
+		}
+		elems = append(elems, el)
+	}
+	if elems[0] == "" || elems[0] == "." || elems[0] == ".." {
+		elems[0] = "collection"
+	}
+	return filepath.Join(elems...)
+}
+
+// StartCollectionFileDownload starts the download of a file that is part of
+// a collection. It returns false if the file was already being downloaded.
+func (db *DB) StartCollectionFileDownload(tx ReadWriteTx, uid UserID, fid FileID,
+	collection string, cf *rpc.CollectionFile, destDir string) (FileDownload, bool, error) {
+
+	var fd FileDownload
+	diskDir := filepath.Join(db.root, downloadingDir)
+	metaPath := filepath.Join(diskDir, fid.String()+contentMetaExt)
+	err := db.readJsonFile(metaPath, &fd)
+	if err == nil {
+		return fd, false, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return fd, false, err
+	}
+
+	fd = FileDownload{
+		UID:            uid,
+		FID:            fid,
+		Collection:     collection,
+		CollectionCost: cf.Cost,
+		CollectionSize: cf.Size,
+		DestDir:        destDir,
+		StartTime:      time.Now(),
+	}
+	if err := db.saveJsonFile(metaPath, fd); err != nil {
+		return fd, false, err
+	}
+	return fd, true, nil
+}
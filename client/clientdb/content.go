@@ -9,6 +9,8 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/companyzero/bisonrelay/client/clientintf"
@@ -20,12 +22,19 @@ import (
 const (
 	sharedContentDir      = "shared"
 	sharedEveryone        = "everyone"
+	sharedGCsDir          = "gcs"
+	sharedGCPrefix        = "gc:"
 	contentMetaExt        = ".cr-meta"
 	chunkDirSuffix        = ".chunks"
 	uploadsDir            = "uploads"
 	contentHashSuffix     = ".filehash"
 	contentMetaHashSuffix = ".metahash"
+	contentManifestSuffix = ".manifest"
+	contentSrcHashSuffix  = ".srchash"
+	assembledFname        = "assembled"
 	downloadingDir        = "downloading"
+	uploadPausedExt       = ".paused"
+	uploadStatsExt        = ".stats"
 )
 
 // chunkFile creates a directory with appropriate chunks of the source file.
@@ -99,12 +108,91 @@ func (db *DB) chunkFile(srcFile, chunkDir string) ([]rpc.FileManifest, []byte, u
 	return fm, fHasher.Sum(nil), size, nil
 }
 
+// compressForSharing compresses the given file into a temporary file, to be
+// chunked in place of the original file. The compression attributes are added
+// to md. It returns an empty string if compressing the file does not reduce
+// its size.
+func (db *DB) compressForSharing(fname string, md *rpc.FileMetadata) (string, error) {
+	fi, err := os.Stat(fname)
+	if err != nil {
+		return "", err
+	}
+	tmpDir := filepath.Join(db.root, contentDir)
+	if err := os.MkdirAll(tmpDir, 0o700); err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(tmpDir, "compress-*")
+	if err != nil {
+		return "", err
+	}
+	tmpName := tmp.Name()
+	tmp.Close()
+
+	size, err := compressFile(fname, tmpName)
+	if err != nil || size >= uint64(fi.Size()) {
+		os.Remove(tmpName)
+		if err == nil {
+			db.log.Debugf("Not compressing file %s: compressed size %d "+
+				">= original size %d", fname, size, fi.Size())
+		}
+		return "", err
+	}
+
+	if md.Attributes == nil {
+		md.Attributes = make(map[string]string, 2)
+	}
+	md.Attributes[rpc.FileAttrCompression] = rpc.FileCompressionGzip
+	md.Attributes[rpc.FileAttrOriginalSize] = strconv.FormatUint(uint64(fi.Size()), 10)
+	db.log.Debugf("Compressed file %s from %d to %d bytes", fname,
+		fi.Size(), size)
+	return tmpName, nil
+}
+
+// userShare returns the dir where files shared with the given user (or all
+// users, if nil) are stored and the entry that identifies the share in the
+// list of shares of a file.
+func (db *DB) userShare(uid *UserID) (string, string) {
+	if uid == nil {
+		return filepath.Join(db.root, sharedContentDir), sharedEveryone
+	}
+	return filepath.Join(db.root, inboundDir, uid.String(), sharedContentDir),
+		uid.String()
+}
+
+// gcShare returns the dir where files shared with the members of the given GC
+// are stored and the entry that identifies the share in the list of shares of
+// a file.
+func (db *DB) gcShare(gcID zkidentity.ShortID) (string, string) {
+	return filepath.Join(db.root, sharedContentDir, sharedGCsDir, gcID.String()),
+		sharedGCPrefix + gcID.String()
+}
+
 // ShareFile registers the given file as a shared file.
 //
-// If uid is nil, then the file is registered as shared among all users.
+// If uid is nil, then the file is registered as shared among all users. If
+// compress is true, the file is compressed before being chunked, as long as
+// that reduces its size. Files that were previously shared keep their
+// original chunking.
 func (db *DB) ShareFile(tx ReadWriteTx, fname string, uid *UserID,
+	cost uint64, descr string, compress bool, sign func([]byte) ([]byte, error)) (SharedFile, rpc.FileMetadata, error) {
+
+	shareDir, thisShare := db.userShare(uid)
+	return db.shareFile(tx, fname, shareDir, thisShare, cost, descr, compress, sign)
+}
+
+// ShareFileWithGC registers the given file as shared with the members of the
+// given GC. Access to the file follows the membership of the GC.
+func (db *DB) ShareFileWithGC(tx ReadWriteTx, fname string, gcID zkidentity.ShortID,
 	cost uint64, descr string, sign func([]byte) ([]byte, error)) (SharedFile, rpc.FileMetadata, error) {
 
+	shareDir, thisShare := db.gcShare(gcID)
+	return db.shareFile(tx, fname, shareDir, thisShare, cost, descr, false, sign)
+}
+
+// shareFile registers the given file as shared in the given share dir.
+func (db *DB) shareFile(tx ReadWriteTx, fname string, shareDir, thisShare string,
+	cost uint64, descr string, compress bool, sign func([]byte) ([]byte, error)) (SharedFile, rpc.FileMetadata, error) {
+
 	var f SharedFile
 	var md rpc.FileMetadata
 
@@ -128,6 +216,19 @@ func (db *DB) ShareFile(tx ReadWriteTx, fname string, uid *UserID,
 			return f, md, err
 		}
 		copy(f.FileHash[:], fileHash)
+
+		// Compressed files are chunked (and identified) by the hash of
+		// the compressed contents.
+		srcHashFname := filepath.Join(chunksPath, f.FileHash.String()+contentSrcHashSuffix)
+		if fileExists(srcHashFname) {
+			var hash string
+			if err := db.readJsonFile(srcHashFname, &hash); err != nil {
+				return f, md, err
+			}
+			if err := f.FileHash.FromString(hash); err != nil {
+				return f, md, err
+			}
+		}
 		wantFileHashFile := f.FileHash.String() + contentHashSuffix
 		metaFname := filepath.Join(chunksPath, wantFileHashFile)
 		if !fileExists(metaFname) {
@@ -148,18 +249,73 @@ func (db *DB) ShareFile(tx ReadWriteTx, fname string, uid *UserID,
 			Filename:    baseName,
 		}
 
+		mimeType, err := DetectFileMIMEType(fname)
+		if err != nil {
+			return f, md, err
+		}
+		if mimeType != "" {
+			md.Attributes = map[string]string{rpc.FileAttrMIMEType: mimeType}
+		}
+
+		// Compress the file, if requested.
+		chunkSrc := fname
+		if compress {
+			compressed, err := db.compressForSharing(fname, &md)
+			if err != nil {
+				return f, md, err
+			}
+			if compressed != "" {
+				defer os.Remove(compressed)
+				chunkSrc = compressed
+			}
+		}
+
 		// File is being shared for the first time. Chunk the file.
-		var err error
 		var fhash []byte
-		md.Manifest, fhash, md.Size, err = db.chunkFile(fname, chunksPath)
+		md.Manifest, fhash, md.Size, err = db.chunkFile(chunkSrc, chunksPath)
 		if err != nil {
 			return f, md, err
 		}
 		copy(f.FileHash[:], fhash)
 		md.Hash = f.FileHash.String()
 
+		// Keep track of the hash of the uncompressed file, so that
+		// sharing it again finds the existing metadata.
+		if md.Compression() != "" {
+			srcHash, err := sha256File(fname)
+			if err != nil {
+				return f, md, err
+			}
+			srcHashFname := filepath.Join(chunksPath,
+				hex.EncodeToString(srcHash)+contentSrcHashSuffix)
+			if err := db.saveJsonFile(srcHashFname, md.Hash); err != nil {
+				return f, md, err
+			}
+		}
+
+		// Large files commit to their chunks through a Merkle root, and
+		// the full manifest is only kept locally.
+		if db.cfg.MerkleManifestChunks > 0 && len(md.Manifest) >= db.cfg.MerkleManifestChunks {
+			hashes := make([][]byte, len(md.Manifest))
+			for i := range md.Manifest {
+				hashes[i] = md.Manifest[i].Hash
+			}
+			manifestFname := filepath.Join(chunksPath, md.Hash+contentManifestSuffix)
+			if err := db.saveJsonFile(manifestFname, md.Manifest); err != nil {
+				return f, md, err
+			}
+			md.Version = rpc.FileMetadataVersionMerkle
+			md.MerkleRoot = rpc.MerkleRoot(hashes)
+			md.ChunkSize = md.Manifest[0].Size
+			md.Manifest = nil
+		}
+
 		// Sign the hash.
-		sig, err := sign(fhash)
+		signedHash, err := md.SignedHash()
+		if err != nil {
+			return f, md, err
+		}
+		sig, err := sign(signedHash)
 		if err != nil {
 			return f, md, fmt.Errorf("unable to sign hash of file: %w", err)
 		}
@@ -176,10 +332,6 @@ func (db *DB) ShareFile(tx ReadWriteTx, fname string, uid *UserID,
 	f.FID = md.MetadataHash()
 	metaMetaFname := filepath.Join(chunksPath, f.FID.String()+contentMetaHashSuffix)
 	var shares []string
-	thisShare := sharedEveryone
-	if uid != nil {
-		thisShare = uid.String()
-	}
 	if fileExists(metaMetaFname) {
 		if err := db.readJsonFile(metaMetaFname, &shares); err != nil {
 			return f, md, err
@@ -201,13 +353,8 @@ func (db *DB) ShareFile(tx ReadWriteTx, fname string, uid *UserID,
 		return f, md, err
 	}
 
-	// Now deal with the actual sharing of the file. If it's a global share,
-	// put in the global share dir, otherwise put in the user's share dir.
-	shareDir := sharedContentDir
-	if uid != nil {
-		shareDir = filepath.Join(inboundDir, uid.String(), sharedContentDir)
-	}
-	shareDir = filepath.Join(db.root, shareDir)
+	// Now deal with the actual sharing of the file, by putting it in the
+	// share dir.
 	shareFname := filepath.Join(shareDir, f.FID.String())
 	if err := db.saveJsonFile(shareFname, f); err != nil {
 		return f, md, err
@@ -244,14 +391,21 @@ func (db *DB) FindSharedFileID(tx ReadTx, fname string) (FileID, error) {
 // Unshare the file with the given user or globally. If the file is no longer
 // noted as shared with anyone, the content is removed.
 func (db *DB) UnshareFile(tx ReadWriteTx, fid FileID, uid *UserID) error {
+	shareDir, thisShare := db.userShare(uid)
+	return db.unshareFile(tx, fid, shareDir, thisShare)
+}
+
+// UnshareFileWithGC stops sharing the file with the members of the given GC.
+// If the file is no longer noted as shared with anyone, the content is
+// removed.
+func (db *DB) UnshareFileWithGC(tx ReadWriteTx, fid FileID, gcID zkidentity.ShortID) error {
+	shareDir, thisShare := db.gcShare(gcID)
+	return db.unshareFile(tx, fid, shareDir, thisShare)
+}
+
+// unshareFile removes the share of the file from the given share dir.
+func (db *DB) unshareFile(tx ReadWriteTx, fid FileID, shareDir, thisShare string) error {
 	// First, read the SharedFile metadata from the share.
-	shareDir := sharedContentDir
-	thisShare := sharedEveryone
-	if uid != nil {
-		shareDir = filepath.Join(inboundDir, uid.String(), sharedContentDir)
-		thisShare = uid.String()
-	}
-	shareDir = filepath.Join(db.root, shareDir)
 	shareFname := filepath.Join(shareDir, fid.String())
 
 	var sf SharedFile
@@ -334,14 +488,88 @@ func (db *DB) sharedFilesFromDirs(dirs []string) ([]SharedFile, error) {
 // ListSharedFile lists the files shared with a given user or shared files with
 // all users.
 func (db *DB) ListSharedFiles(tx ReadTx, uid *UserID) ([]rpc.FileMetadata, error) {
-	shareDir := sharedContentDir
-	if uid != nil {
-		shareDir = filepath.Join(inboundDir, uid.String(), sharedContentDir)
+	shareDir, _ := db.userShare(uid)
+	return db.listSharedFilesInDirs([]string{shareDir})
+}
+
+// ListGCSharedFiles lists the files shared with the members of the given GC.
+func (db *DB) ListGCSharedFiles(tx ReadTx, gcID zkidentity.ShortID) ([]rpc.FileMetadata, error) {
+	shareDir, _ := db.gcShare(gcID)
+	return db.listSharedFilesInDirs([]string{shareDir})
+}
+
+// sharedGCIDs returns the IDs of the GCs that have files shared with them.
+func (db *DB) sharedGCIDs() ([]zkidentity.ShortID, error) {
+	dir := filepath.Join(db.root, sharedContentDir, sharedGCsDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("unable to read gc shares dir: %v", err)
+	}
+	res := make([]zkidentity.ShortID, 0, len(entries))
+	for _, entry := range entries {
+		var gcID zkidentity.ShortID
+		if !entry.IsDir() || gcID.FromString(entry.Name()) != nil {
+			continue
+		}
+		res = append(res, gcID)
 	}
-	shareDir = filepath.Join(db.root, shareDir)
+	return res, nil
+}
 
+// isGCMember returns true if the given user is currently a member of the
+// given GC.
+func (db *DB) isGCMember(tx ReadTx, gcID zkidentity.ShortID, uid UserID) bool {
+	gc, err := db.GetGC(tx, gcID)
+	if err != nil {
+		return false
+	}
+	for _, member := range gc.Members {
+		if member == uid {
+			return true
+		}
+	}
+	return false
+}
+
+// ListGCSharedFilesForUser lists the files shared with the GCs the given user
+// is currently a member of.
+func (db *DB) ListGCSharedFilesForUser(tx ReadTx, uid UserID) ([]rpc.FileMetadata, error) {
+	gcIDs, err := db.sharedGCIDs()
+	if err != nil {
+		return nil, err
+	}
+	var dirs []string
+	for _, gcID := range gcIDs {
+		if db.isGCMember(tx, gcID, uid) {
+			shareDir, _ := db.gcShare(gcID)
+			dirs = append(dirs, shareDir)
+		}
+	}
+	if len(dirs) == 0 {
+		return nil, nil
+	}
+	files, err := db.listSharedFilesInDirs(dirs)
+	if err != nil {
+		return nil, err
+	}
+
+	// The same file may be shared with more than one GC.
+	res := make([]rpc.FileMetadata, 0, len(files))
+	seen := make(map[string]struct{}, len(files))
+	for _, md := range files {
+		var fid FileID = md.MetadataHash()
+		if _, ok := seen[fid.String()]; ok {
+			continue
+		}
+		seen[fid.String()] = struct{}{}
+		res = append(res, md)
+	}
+	return res, nil
+}
+
+// listSharedFilesInDirs lists the files shared in the given share dirs.
+func (db *DB) listSharedFilesInDirs(dirs []string) ([]rpc.FileMetadata, error) {
 	// List shares.
-	dirs := []string{shareDir}
 	shares, err := db.sharedFilesFromDirs(dirs)
 	if err != nil {
 		return nil, fmt.Errorf("unable to list files from dir: %v", err)
@@ -406,6 +634,7 @@ func (db *DB) ListAllSharedFiles(tx ReadTx) ([]SharedFileAndShares, error) {
 		// Check if it was globally shared.
 		global := false
 		uids := make([]clientintf.ID, 0, len(shares))
+		var gcs []zkidentity.ShortID
 		for i := range shares {
 			if shares[i] == sharedEveryone {
 				// Globally shared! Remove from list.
@@ -413,6 +642,19 @@ func (db *DB) ListAllSharedFiles(tx ReadTx) ([]SharedFileAndShares, error) {
 				continue
 			}
 
+			// Shared to a GC.
+			if strings.HasPrefix(shares[i], sharedGCPrefix) {
+				var gcID zkidentity.ShortID
+				err := gcID.FromString(shares[i][len(sharedGCPrefix):])
+				if err != nil {
+					db.log.Warnf("Not a GC ID (%q) in shares file %s: %v",
+						shares[i], sharesFname, err)
+					continue
+				}
+				gcs = append(gcs, gcID)
+				continue
+			}
+
 			// Shared to a user.
 			var uid clientintf.ID
 			if err := uid.FromString(shares[i]); err != nil {
@@ -433,6 +675,7 @@ func (db *DB) ListAllSharedFiles(tx ReadTx) ([]SharedFileAndShares, error) {
 			Size:   fm.Size,
 			Global: global,
 			Shares: uids,
+			GCs:    gcs,
 		})
 	}
 
@@ -442,11 +685,14 @@ func (db *DB) ListAllSharedFiles(tx ReadTx) ([]SharedFileAndShares, error) {
 // GetSharedFile returns information about the given shared file. If uid is
 // nil, then it's assumed the shared file is on the global dir.
 func (db *DB) GetSharedFile(tx ReadTx, uid *UserID, fid FileID) (SharedFile, rpc.FileMetadata, error) {
-	shareDir := sharedContentDir
-	if uid != nil {
-		shareDir = filepath.Join(inboundDir, uid.String(), sharedContentDir)
-	}
-	metaFname := filepath.Join(db.root, shareDir, fid.String())
+	shareDir, _ := db.userShare(uid)
+	return db.getSharedFileInDir(shareDir, fid)
+}
+
+// getSharedFileInDir returns information about the given file shared in the
+// given share dir.
+func (db *DB) getSharedFileInDir(shareDir string, fid FileID) (SharedFile, rpc.FileMetadata, error) {
+	metaFname := filepath.Join(shareDir, fid.String())
 	var res SharedFile
 	var md rpc.FileMetadata
 	if err := db.readJsonFile(metaFname, &res); errors.Is(err, ErrNotFound) {
@@ -474,7 +720,27 @@ func (db *DB) GetSharedFileForUpload(tx ReadTx, uid UserID, fid FileID) (SharedF
 	}
 
 	// Not globally shared. See if shared with user.
-	return db.GetSharedFile(tx, &uid, fid)
+	f, md, err = db.GetSharedFile(tx, &uid, fid)
+	if !errors.Is(err, ErrNotFound) {
+		return f, md, err
+	}
+
+	// Not shared with user. See if shared with a GC the user is
+	// currently a member of.
+	gcIDs, gcErr := db.sharedGCIDs()
+	if gcErr != nil {
+		return f, md, gcErr
+	}
+	for _, gcID := range gcIDs {
+		shareDir, _ := db.gcShare(gcID)
+		if !fileExists(filepath.Join(shareDir, fid.String())) {
+			continue
+		}
+		if db.isGCMember(tx, gcID, uid) {
+			return db.getSharedFileInDir(shareDir, fid)
+		}
+	}
+	return f, md, err
 }
 
 // readOrNewChunkUpload reads an existing or creates a new chunk upload
@@ -514,13 +780,41 @@ func (db *DB) removeChunkUpload(cup *ChunkUpload) error {
 		return err
 	}
 
-	// Remove upload dir if empty.
+	// Remove upload dir and stats if empty.
 	if dirExistsEmpty(dir) {
+		err := os.Remove(db.uploadStatsFname(cup.UID, cup.FID))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
 		return os.Remove(dir)
 	}
 	return nil
 }
 
+// uploadStatsFname returns the name of the file that tracks the progress of the
+// upload of the given file to the given user.
+func (db *DB) uploadStatsFname(uid UserID, fid FileID) string {
+	return filepath.Join(db.root, inboundDir, uid.String(), uploadsDir,
+		fid.String()+uploadStatsExt)
+}
+
+// updateUploadStats applies f to the stats of the upload of the given file to
+// the given user and saves them.
+func (db *DB) updateUploadStats(uid UserID, fid FileID, f func(*FileUploadStats)) (FileUploadStats, error) {
+	var stats FileUploadStats
+	fname := db.uploadStatsFname(uid, fid)
+	if err := db.readJsonFile(fname, &stats); err != nil && !errors.Is(err, ErrNotFound) {
+		return stats, err
+	}
+	now := time.Now()
+	if stats.StartTime.IsZero() {
+		stats.StartTime = now
+	}
+	stats.LastUpdate = now
+	f(&stats)
+	return stats, db.saveJsonFile(fname, stats)
+}
+
 // GetFileChunkUpload returns an existing chunk upload info.
 func (db *DB) GetFileChunkUpload(tx ReadTx, uid UserID, fid FileID, cid ChunkID) (ChunkUpload, error) {
 	fname := filepath.Join(db.root, inboundDir, uid.String(), uploadsDir,
@@ -542,7 +836,11 @@ func (db *DB) AddChunkUploadInvoice(tx ReadWriteTx, uid UserID, fid FileID,
 
 	cup.Invoices = append(cup.Invoices, invoice)
 	cup.State = ChunkStateHasInvoice
-	return db.saveChunkUpload(&cup)
+	if err := db.saveChunkUpload(&cup); err != nil {
+		return err
+	}
+	_, err = db.updateUploadStats(uid, fid, func(*FileUploadStats) {})
+	return err
 }
 
 // MarkChunkUploadInvoiceExpired registers the given invoice as expired and thus
@@ -588,12 +886,12 @@ func (db *DB) MarkChunkUploadInvoiceSent(tx ReadWriteTx, uid UserID, fid FileID,
 }
 
 // MarkChunkUploadPaid registers the given invoice as having been paid for
-// the given chunk upload.
+// the given chunk upload. It returns the updated stats of the upload.
 func (db *DB) MarkChunkUploadPaid(tx ReadWriteTx, uid UserID, fid FileID,
-	cid ChunkID, index int, invoice string) error {
+	cid ChunkID, index int, invoice string, matoms int64) (FileUploadStats, error) {
 	cup, err := db.readOrNewChunkUpload(uid, fid, cid, index)
 	if err != nil {
-		return err
+		return FileUploadStats{}, err
 	}
 
 	// Drop this invoice from list of outstanding invoices.
@@ -608,17 +906,34 @@ func (db *DB) MarkChunkUploadPaid(tx ReadWriteTx, uid UserID, fid FileID,
 
 	// Inc count of paid invoices for this chunk.
 	cup.Paid += 1
-	return db.saveChunkUpload(&cup)
+	if err := db.saveChunkUpload(&cup); err != nil {
+		return FileUploadStats{}, err
+	}
+
+	return db.updateUploadStats(uid, fid, func(stats *FileUploadStats) {
+		stats.PaidChunks += 1
+		stats.PaidMAtoms += matoms
+	})
 }
 
 // MarkChunkUploadSent registers the given upload as having been sent to the
-// remote user.
+// remote user. It returns the updated stats of the upload.
 func (db *DB) MarkChunkUploadSent(tx ReadWriteTx, uid UserID, fid FileID,
-	cid ChunkID, index int) error {
+	cid ChunkID, index int, size int) (FileUploadStats, error) {
 
 	cup, err := db.readOrNewChunkUpload(uid, fid, cid, index)
 	if err != nil {
-		return err
+		return FileUploadStats{}, err
+	}
+
+	// Update the stats before the chunk upload (and possibly the stats)
+	// are removed.
+	stats, err := db.updateUploadStats(uid, fid, func(stats *FileUploadStats) {
+		stats.SentChunks = append(stats.SentChunks, index)
+		stats.SentBytes += uint64(size)
+	})
+	if err != nil {
+		return stats, err
 	}
 
 	// Dec count of paid invoices for this chunk.
@@ -626,9 +941,9 @@ func (db *DB) MarkChunkUploadSent(tx ReadWriteTx, uid UserID, fid FileID,
 
 	// Remove chunk upload if no more uploads exist for it.
 	if cup.Paid <= 0 && len(cup.Invoices) == 0 {
-		return db.removeChunkUpload(&cup)
+		return stats, db.removeChunkUpload(&cup)
 	}
-	return db.saveChunkUpload(&cup)
+	return stats, db.saveChunkUpload(&cup)
 }
 
 // fileMetadataForSharedFile returns the corresponding FileMetadata info of the
@@ -641,22 +956,71 @@ func (db *DB) fileMetadataForSharedFile(sf *SharedFile) (rpc.FileMetadata, error
 	return md, err
 }
 
+// sharedFileManifest returns the full list of chunks of the given shared
+// file. For files shared with Merkle metadata, the manifest is only stored
+// locally.
+func (db *DB) sharedFileManifest(sf *SharedFile) ([]rpc.FileManifest, error) {
+	md, err := db.fileMetadataForSharedFile(sf)
+	if err != nil {
+		return nil, err
+	}
+	if !md.IsMerkle() {
+		return md.Manifest, nil
+	}
+
+	var manifest []rpc.FileManifest
+	chunksPath := filepath.Join(db.root, contentDir, sf.Filename)
+	manifestFname := filepath.Join(chunksPath, md.Hash+contentManifestSuffix)
+	if err := db.readJsonFile(manifestFname, &manifest); err != nil {
+		return nil, fmt.Errorf("unable to read manifest of shared file: %w", err)
+	}
+	return manifest, nil
+}
+
 // GetSharedFileChunkData returns the actual chunk data for a given shared file.
 func (db *DB) GetSharedFileChunkData(tx ReadTx, sf *SharedFile, chunkIdx int) ([]byte, error) {
-	md, err := db.fileMetadataForSharedFile(sf)
+	manifest, err := db.sharedFileManifest(sf)
 	if err != nil {
 		return nil, err
 	}
-	if chunkIdx >= len(md.Manifest) {
+	if chunkIdx < 0 || chunkIdx >= len(manifest) {
 		return nil, fmt.Errorf("chunkIdx %d > len(chunks) %d",
-			chunkIdx, len(md.Manifest))
+			chunkIdx, len(manifest))
 	}
-	chunkHash := hex.EncodeToString(md.Manifest[chunkIdx].Hash)
+	chunkHash := hex.EncodeToString(manifest[chunkIdx].Hash)
 	chunksPath := filepath.Join(db.root, contentDir, sf.Filename)
 	chunkFname := filepath.Join(chunksPath, chunkHash)
 	return os.ReadFile(chunkFname)
 }
 
+// GetSharedFileChunkProof returns the hash of the given chunk of a shared
+// file, along with the proof of its inclusion in the Merkle tree of the file.
+// The proof is nil for files not shared with Merkle metadata.
+func (db *DB) GetSharedFileChunkProof(tx ReadTx, sf *SharedFile, chunkIdx int) ([]byte, [][]byte, error) {
+	md, err := db.fileMetadataForSharedFile(sf)
+	if err != nil {
+		return nil, nil, err
+	}
+	manifest, err := db.sharedFileManifest(sf)
+	if err != nil {
+		return nil, nil, err
+	}
+	if chunkIdx < 0 || chunkIdx >= len(manifest) {
+		return nil, nil, fmt.Errorf("chunkIdx %d > len(chunks) %d",
+			chunkIdx, len(manifest))
+	}
+	hash := manifest[chunkIdx].Hash
+	if !md.IsMerkle() {
+		return hash, nil, nil
+	}
+
+	hashes := make([][]byte, len(manifest))
+	for i := range manifest {
+		hashes[i] = manifest[i].Hash
+	}
+	return hash, rpc.MerkleProof(hashes, chunkIdx), nil
+}
+
 func (db *DB) ListOutstandingUploads(tx ReadTx) ([]ChunkUpload, error) {
 	// db/inbound/<userid>/uploads/<fid>/<cid>
 	pattern := filepath.Join(db.root, inboundDir, "*", uploadsDir, "*", "*")
@@ -692,6 +1056,159 @@ func (db *DB) ListOutstandingUploads(tx ReadTx) ([]ChunkUpload, error) {
 	return res, nil
 }
 
+// AddChunkUploadRequest registers that the given chunk was requested by the
+// remote user while the upload was paused, so that it can be processed once
+// the upload is resumed.
+func (db *DB) AddChunkUploadRequest(tx ReadWriteTx, uid UserID, fid FileID,
+	cid ChunkID, index int) error {
+
+	cup, err := db.readOrNewChunkUpload(uid, fid, cid, index)
+	if err != nil {
+		return err
+	}
+	if len(cup.Invoices) > 0 || cup.Paid > 0 {
+		// Chunk upload already in progress.
+		return nil
+	}
+	cup.State = ChunkStateRequestedChunk
+	if err := db.saveChunkUpload(&cup); err != nil {
+		return err
+	}
+	_, err = db.updateUploadStats(uid, fid, func(*FileUploadStats) {})
+	return err
+}
+
+// uploadPausedFname returns the name of the file that marks the upload of the
+// given file to the given user as paused.
+func (db *DB) uploadPausedFname(uid UserID, fid FileID) string {
+	return filepath.Join(db.root, inboundDir, uid.String(), uploadsDir,
+		fid.String()+uploadPausedExt)
+}
+
+// SetFileUploadPaused sets whether uploads of the given file to the given user
+// are paused.
+func (db *DB) SetFileUploadPaused(tx ReadWriteTx, uid UserID, fid FileID, paused bool) error {
+	fname := db.uploadPausedFname(uid, fid)
+	if !paused {
+		err := os.Remove(fname)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return db.saveJsonFile(fname, struct{}{})
+}
+
+// IsFileUploadPaused returns true if uploads of the given file to the given
+// user are paused.
+func (db *DB) IsFileUploadPaused(tx ReadTx, uid UserID, fid FileID) bool {
+	return fileExists(db.uploadPausedFname(uid, fid))
+}
+
+// CancelFileUpload removes all outstanding chunk uploads of the given file to
+// the given user.
+func (db *DB) CancelFileUpload(tx ReadWriteTx, uid UserID, fid FileID) error {
+	dir := filepath.Join(db.root, inboundDir, uid.String(), uploadsDir,
+		fid.String())
+	pausedFname := db.uploadPausedFname(uid, fid)
+	if !fileExists(dir) && !fileExists(pausedFname) {
+		return fmt.Errorf("upload of file %s: %w", fid, ErrNotFound)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if err := os.Remove(pausedFname); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	err := os.Remove(db.uploadStatsFname(uid, fid))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// ListFileUploads lists the outstanding uploads, grouped by file and user.
+// Paused uploads are returned even if no chunks were requested yet.
+func (db *DB) ListFileUploads(tx ReadTx) ([]FileUpload, error) {
+	cups, err := db.ListOutstandingUploads(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	type uploadKey struct {
+		uid UserID
+		fid FileID
+	}
+	var res []FileUpload
+	idx := make(map[uploadKey]int)
+	for _, cup := range cups {
+		key := uploadKey{uid: cup.UID, fid: cup.FID}
+		i, ok := idx[key]
+		if !ok {
+			i = len(res)
+			idx[key] = i
+			res = append(res, FileUpload{
+				UID:    cup.UID,
+				FID:    cup.FID,
+				Paused: db.IsFileUploadPaused(tx, cup.UID, cup.FID),
+			})
+		}
+		res[i].Chunks = append(res[i].Chunks, cup)
+	}
+
+	// db/inbound/<userid>/uploads/<fid>.paused
+	pattern := filepath.Join(db.root, inboundDir, "*", uploadsDir, "*"+uploadPausedExt)
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	for _, fname := range files {
+		var key uploadKey
+		uidDir := filepath.Base(filepath.Dir(filepath.Dir(fname)))
+		if err := key.uid.FromString(uidDir); err != nil {
+			continue
+		}
+		fidStr := strings.TrimSuffix(filepath.Base(fname), uploadPausedExt)
+		if err := key.fid.FromString(fidStr); err != nil {
+			continue
+		}
+		if _, ok := idx[key]; ok {
+			continue
+		}
+		idx[key] = len(res)
+		res = append(res, FileUpload{UID: key.uid, FID: key.fid, Paused: true})
+	}
+
+	for i := range res {
+		fname := db.uploadStatsFname(res[i].UID, res[i].FID)
+		err := db.readJsonFile(fname, &res[i].Stats)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			db.log.Warnf("Unable to read upload stats %s: %v", fname, err)
+		}
+	}
+
+	return res, nil
+}
+
+// SetFileDownloadPaused sets whether the given download is paused.
+func (db *DB) SetFileDownloadPaused(tx ReadWriteTx, fid FileID, paused bool) (FileDownload, error) {
+	var fd FileDownload
+	diskDir := filepath.Join(db.root, downloadingDir)
+	metaPath := filepath.Join(diskDir, fid.String()+contentMetaExt)
+	if err := db.readJsonFile(metaPath, &fd); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			err = fmt.Errorf("download of file %s: %w", fid, ErrNotFound)
+		}
+		return fd, err
+	}
+	if fd.CompletedName != "" {
+		return fd, fmt.Errorf("download of file %s already completed", fid)
+	}
+
+	fd.Paused = paused
+	return fd, db.saveJsonFile(metaPath, fd)
+}
+
 func (db *DB) StartFileDownload(tx ReadWriteTx, uid UserID, fid FileID, isSentFile bool) (FileDownload, error) {
 	var fd FileDownload
 
@@ -702,6 +1219,7 @@ func (db *DB) StartFileDownload(tx ReadWriteTx, uid UserID, fid FileID, isSentFi
 		UID:        uid,
 		FID:        fid,
 		IsSentFile: isSentFile,
+		StartTime:  time.Now(),
 	}
 	if err := db.saveJsonFile(metaPath, fd); err != nil {
 		return fd, err
@@ -719,13 +1237,76 @@ func (db *DB) ReadFileDownload(tx ReadTx, uid UserID, fid FileID) (FileDownload,
 		return fd, err
 	}
 
-	// TODO: support downloading from multiple users at the same time?
 	if fd.UID != uid {
 		return fd, fmt.Errorf("specified user not the download user")
 	}
 	return fd, nil
 }
 
+// ReadFileDownloadBySource returns the download that is fetching the given
+// file from the given user. The user may be either the original uploader or
+// one of the additional sources of the download, in which case fid is the ID
+// of the file as shared by that source.
+func (db *DB) ReadFileDownloadBySource(tx ReadTx, uid UserID, fid FileID) (FileDownload, error) {
+	var fd FileDownload
+	diskDir := filepath.Join(db.root, downloadingDir)
+	metaPath := filepath.Join(diskDir, fid.String()+contentMetaExt)
+	err := db.readJsonFile(metaPath, &fd)
+	if err == nil && fd.UID == uid {
+		return fd, nil
+	}
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return fd, err
+	}
+
+	fds, err := db.ListOutstandingDownloads(tx)
+	if err != nil {
+		return fd, err
+	}
+	for _, fd := range fds {
+		for _, src := range fd.Sources {
+			if src.UID == uid && src.FID == fid {
+				return fd, nil
+			}
+		}
+	}
+	return fd, fmt.Errorf("download of file %s from user %s: %w", fid,
+		uid, ErrNotFound)
+}
+
+// AddFileDownloadSource adds the given source to the download. It returns
+// false if the user was already a source of the download.
+func (db *DB) AddFileDownloadSource(tx ReadWriteTx, fd *FileDownload,
+	src DownloadSource) (bool, error) {
+
+	if _, ok := fd.SourceFID(src.UID); ok {
+		return false, nil
+	}
+	fd.Sources = append(fd.Sources, src)
+
+	diskDir := filepath.Join(db.root, downloadingDir)
+	metaPath := filepath.Join(diskDir, fd.FID.String()+contentMetaExt)
+	return true, db.saveJsonFile(metaPath, fd)
+}
+
+// RemoveFileDownloadSource removes the given user from the list of additional
+// sources of the download. Chunks requested from that user but not yet
+// received may then be requested from other sources.
+func (db *DB) RemoveFileDownloadSource(tx ReadWriteTx, fd *FileDownload, uid UserID) error {
+	for i := range fd.Sources {
+		if fd.Sources[i].UID != uid {
+			continue
+		}
+		fd.Sources = append(fd.Sources[:i], fd.Sources[i+1:]...)
+
+		diskDir := filepath.Join(db.root, downloadingDir)
+		metaPath := filepath.Join(diskDir, fd.FID.String()+contentMetaExt)
+		return db.saveJsonFile(metaPath, fd)
+	}
+	return fmt.Errorf("user %s is not a source of download %s: %w",
+		uid, fd.FID, ErrNotFound)
+}
+
 // CancelFileDownload removes the in-progress download from the DB.
 func (db *DB) CancelFileDownload(tx ReadWriteTx, fid FileID) error {
 	diskDir := filepath.Join(db.root, downloadingDir)
@@ -752,6 +1333,15 @@ func (db *DB) UpdateFileDownloadMetadata(tx ReadWriteTx, fd *FileDownload,
 	if fd.Metadata != nil {
 		return fmt.Errorf("cannot update file metadata: metadata already filled")
 	}
+	if err := checkFileCompression(&md); err != nil {
+		return err
+	}
+	if err := md.CheckMerkle(); err != nil {
+		return fmt.Errorf("invalid file metadata: %w", err)
+	}
+	if err := checkManifestSize(&md); err != nil {
+		return fmt.Errorf("invalid file metadata: %w", err)
+	}
 	fd.Metadata = &md
 
 	diskDir := filepath.Join(db.root, downloadingDir)
@@ -811,28 +1401,82 @@ func (db *DB) ReplaceFileDownloadChunkState(tx ReadWriteTx, fd *FileDownload,
 	return db.saveJsonFile(metaPath, fd)
 }
 
+// MarkFileDownloadChunkPaid marks the given chunk of a download as paid and
+// adds the amount paid to the download total.
+func (db *DB) MarkFileDownloadChunkPaid(tx ReadWriteTx, fd *FileDownload,
+	chunkIdx int, matoms int64) error {
+
+	fd.PaidMAtoms += matoms
+	delete(fd.InvoiceRetries, chunkIdx)
+	return db.ReplaceFileDownloadChunkState(tx, fd, chunkIdx, ChunkStatePaid)
+}
+
+// MarkFileDownloadChunkInvoiceExpired records that the invoice for the given
+// chunk expired before it could be paid. The expired invoice is kept, so that
+// it can be sent to the uploader when requesting a new one. It returns how
+// many invoices for this chunk have expired.
+func (db *DB) MarkFileDownloadChunkInvoiceExpired(tx ReadWriteTx, fd *FileDownload,
+	chunkIdx int) (int, error) {
+
+	if fd.InvoiceRetries == nil {
+		fd.InvoiceRetries = make(map[int]int)
+	}
+	fd.InvoiceRetries[chunkIdx] += 1
+	err := db.ReplaceFileDownloadChunkState(tx, fd, chunkIdx,
+		ChunkStateInvoiceExpired)
+	return fd.InvoiceRetries[chunkIdx], err
+}
+
+// MarkFileDownloadChunkRequested records that the given chunk was requested
+// from the given source.
+func (db *DB) MarkFileDownloadChunkRequested(tx ReadWriteTx, fd *FileDownload,
+	chunkIdx int, source UserID) error {
+
+	if fd.ChunkSources == nil {
+		fd.ChunkSources = make(map[int]UserID)
+	}
+	fd.ChunkSources[chunkIdx] = source
+	return db.ReplaceFileDownloadChunkState(tx, fd, chunkIdx,
+		ChunkStateRequestedChunk)
+}
+
+// downloadChunkFname returns the name of the file where the given chunk of a
+// download is stored. Chunks of files with Merkle metadata are stored by
+// index, because their hashes are not known in advance.
+func downloadChunkFname(md *rpc.FileMetadata, chunkIdx int) string {
+	if md.IsMerkle() {
+		return fmt.Sprintf("chunk-%d", chunkIdx)
+	}
+	return hex.EncodeToString(md.Manifest[chunkIdx].Hash)
+}
+
+// SaveFileDownloadChunk saves the given chunk of a download. The proof is only
+// needed for files with Merkle metadata.
 func (db *DB) SaveFileDownloadChunk(tx ReadWriteTx, user string, fd *FileDownload,
-	chunkIdx int, data []byte) (string, error) {
+	chunkIdx int, data []byte, proof [][]byte) (string, error) {
 
 	// Hash the chunk.
 	hasher := sha256.New()
 	hasher.Write(data)
 	hash := hasher.Sum(nil)
-	hashStr := hex.EncodeToString(hash)
 
 	if fd.Metadata == nil {
 		return "", fmt.Errorf("file metadata is nil")
 	}
 
-	// Verify chunk index is correct.
-	if !clientintf.ChunkIndexMatches(fd.Metadata, chunkIdx, hash[:]) {
+	// Verify chunk size and index are correct.
+	if uint64(len(data)) != fd.Metadata.ChunkLen(chunkIdx) {
+		return "", fmt.Errorf("chunk %d has unexpected size %d (want %d)",
+			chunkIdx, len(data), fd.Metadata.ChunkLen(chunkIdx))
+	}
+	if !clientintf.ChunkProofMatches(fd.Metadata, chunkIdx, hash[:], proof) {
 		return "", fmt.Errorf("data does not hash to specified chunk index")
 	}
 
 	// Save the chunk.
 	diskDir := filepath.Join(db.root, downloadingDir)
 	chunkDir := filepath.Join(diskDir, fd.FID.String()+chunkDirSuffix)
-	chunkPath := filepath.Join(chunkDir, hashStr)
+	chunkPath := filepath.Join(chunkDir, downloadChunkFname(fd.Metadata, chunkIdx))
 	if err := os.MkdirAll(chunkDir, 0o700); err != nil {
 		return "", err
 	}
@@ -852,7 +1496,7 @@ func (db *DB) SaveFileDownloadChunk(tx ReadWriteTx, user string, fd *FileDownloa
 
 	// Assemble final file. First: figure out final name.
 	baseDestFileName := filepath.Join(db.downloadsDir, strescape.PathElement(user),
-		strescape.PathElement(fd.Metadata.Filename))
+		fd.DestDir, strescape.PathElement(fd.Metadata.Filename))
 	destFileName := baseDestFileName
 	ext := filepath.Ext(baseDestFileName)
 	if len(ext) > 0 {
@@ -871,28 +1515,60 @@ func (db *DB) SaveFileDownloadChunk(tx ReadWriteTx, user string, fd *FileDownloa
 
 	defer destFile.Close()
 
+	// Compressed files are assembled in the chunk dir and then
+	// decompressed into the final file.
+	assembledFile := destFile
+	if fd.Metadata.Compression() != "" {
+		assembledFile, err = os.Create(filepath.Join(chunkDir, assembledFname))
+		if err != nil {
+			return "", err
+		}
+		defer assembledFile.Close()
+	}
+
 	// Next: Copy over chunks, while accumulating final hash.
 	hasher = sha256.New()
-	for _, ch := range fd.Metadata.Manifest {
-		chunkFname := filepath.Join(chunkDir, hex.EncodeToString(ch.Hash))
+	for i := 0; i < fd.Metadata.ChunkCount(); i++ {
+		chunkFname := filepath.Join(chunkDir, downloadChunkFname(fd.Metadata, i))
 		data, err := os.ReadFile(chunkFname)
 		if err != nil {
 			return "", err
 		}
 		hasher.Write(data)
-		if _, err := destFile.Write(data); err != nil {
+		if _, err := assembledFile.Write(data); err != nil {
 			return "", err
 		}
 	}
 
 	// Ensure final file hash is correct.
 	hash = hasher.Sum(nil)
-	hashStr = hex.EncodeToString(hash)
+	hashStr := hex.EncodeToString(hash)
 	if hashStr != fd.Metadata.Hash {
 		return "", fmt.Errorf("unexpected final file hash (got %s, want %s)",
 			hashStr, fd.Metadata.Hash)
 	}
-	fd.CompletedName = filepath.Base(destFileName)
+
+	if assembledFile != destFile {
+		if err := assembledFile.Close(); err != nil {
+			return "", err
+		}
+		err := decompressFile(assembledFile.Name(), destFile, fd.Metadata)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// Detect the type of the downloaded file, in case the sharer did not
+	// specify it.
+	fd.MIMEType = MetadataMIMEType(fd.Metadata)
+	if fd.MIMEType == "" {
+		fd.MIMEType, err = DetectFileMIMEType(destFileName)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	fd.CompletedName = filepath.Join(fd.DestDir, filepath.Base(destFileName))
 	metaPath := filepath.Join(diskDir, fd.FID.String()+contentMetaExt)
 	if err := db.saveJsonFile(metaPath, fd); err != nil {
 		return "", err
@@ -922,9 +1598,10 @@ func (db *DB) MissingFileDownloadChunks(tx ReadTx, fd *FileDownload) []int {
 	}
 
 	// Verify which chunk files already exist.
-	res := make([]int, 0, len(fd.Metadata.Manifest)-len(filesMap))
-	for i, ch := range fd.Metadata.Manifest {
-		if _, ok := filesMap[hex.EncodeToString(ch.Hash)]; !ok {
+	nbChunks := fd.Metadata.ChunkCount()
+	res := make([]int, 0, nbChunks)
+	for i := 0; i < nbChunks; i++ {
+		if _, ok := filesMap[downloadChunkFname(fd.Metadata, i)]; !ok {
 			res = append(res, i)
 		}
 	}
@@ -0,0 +1,142 @@
+package clientdb
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/companyzero/bisonrelay/rpc"
+	"github.com/companyzero/bisonrelay/zkidentity"
+)
+
+const downloadRulesFile = "downloadrules.json"
+
+// DownloadRuleAction is the action taken on file offers that match a download
+// rule.
+type DownloadRuleAction string
+
+const (
+	DownloadRuleAccept DownloadRuleAction = "accept"
+	DownloadRuleReject DownloadRuleAction = "reject"
+)
+
+// DownloadRule is a rule to automatically accept or reject offers of files to
+// download. A rule matches a file offer only if all of its set conditions
+// match.
+type DownloadRule struct {
+	ID     uint64             `json:"id"`
+	Action DownloadRuleAction `json:"action"`
+
+	// UID matches files offered by the given user.
+	UID *UserID `json:"uid,omitempty"`
+
+	// GC matches files offered by members of the given GC.
+	GC *zkidentity.ShortID `json:"gc,omitempty"`
+
+	// MinSize and MaxSize match files with a size (in bytes) in the given
+	// range. A MaxSize of zero means no upper limit.
+	MinSize uint64 `json:"min_size,omitempty"`
+	MaxSize uint64 `json:"max_size,omitempty"`
+
+	// MinCost and MaxCost match files with a cost (in atoms) in the given
+	// range. A MaxCost of zero means no upper limit.
+	MinCost uint64 `json:"min_cost,omitempty"`
+	MaxCost uint64 `json:"max_cost,omitempty"`
+
+	// MIMETypes match files with one of the given MIME types, which is
+	// detected from the file's extension. A type may end in "/*" to match
+	// all of its subtypes (e.g. "image/*"). See MIMETypeMatches.
+	MIMETypes []string `json:"mime_types,omitempty"`
+}
+
+// Matches returns true if the rule matches a file with the given metadata
+// offered by the given user. gcs are the GCs the user is a member of.
+func (r *DownloadRule) Matches(uid UserID, gcs []zkidentity.ShortID, fm *rpc.FileMetadata) bool {
+	if r.UID != nil && *r.UID != uid {
+		return false
+	}
+	if r.GC != nil {
+		found := false
+		for _, gc := range gcs {
+			if gc == *r.GC {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if fm.Size < r.MinSize || (r.MaxSize > 0 && fm.Size > r.MaxSize) {
+		return false
+	}
+	if fm.Cost < r.MinCost || (r.MaxCost > 0 && fm.Cost > r.MaxCost) {
+		return false
+	}
+	if len(r.MIMETypes) > 0 {
+		typ := MetadataMIMEType(fm)
+		found := false
+		for _, want := range r.MIMETypes {
+			if found = MIMETypeMatches(want, typ); found {
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// ListDownloadRules lists the download rules, in the order they are
+// evaluated.
+func (db *DB) ListDownloadRules(tx ReadTx) ([]DownloadRule, error) {
+	var rules []DownloadRule
+	fname := filepath.Join(db.root, downloadRulesFile)
+	err := db.readJsonFile(fname, &rules)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// AddDownloadRule adds a rule to the end of the list of download rules. The ID
+// of the rule is set on the returned rule.
+func (db *DB) AddDownloadRule(tx ReadWriteTx, rule DownloadRule) (DownloadRule, error) {
+	switch rule.Action {
+	case DownloadRuleAccept, DownloadRuleReject:
+	default:
+		return rule, fmt.Errorf("unknown download rule action %q", rule.Action)
+	}
+
+	rules, err := db.ListDownloadRules(tx)
+	if err != nil {
+		return rule, err
+	}
+	rule.ID = 1
+	for _, r := range rules {
+		if r.ID >= rule.ID {
+			rule.ID = r.ID + 1
+		}
+	}
+	rules = append(rules, rule)
+	fname := filepath.Join(db.root, downloadRulesFile)
+	return rule, db.saveJsonFile(fname, rules)
+}
+
+// RemoveDownloadRule removes the download rule with the given ID.
+func (db *DB) RemoveDownloadRule(tx ReadWriteTx, id uint64) error {
+	rules, err := db.ListDownloadRules(tx)
+	if err != nil {
+		return err
+	}
+	for i := range rules {
+		if rules[i].ID != id {
+			continue
+		}
+		rules = append(rules[:i], rules[i+1:]...)
+		fname := filepath.Join(db.root, downloadRulesFile)
+		return db.saveJsonFile(fname, rules)
+	}
+	return fmt.Errorf("download rule %d: %w", id, ErrNotFound)
+}
@@ -0,0 +1,57 @@
+package clientdb
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/companyzero/bisonrelay/client/clientintf"
+)
+
+// SaveDraft saves (creating or replacing) the given post draft.
+func (db *DB) SaveDraft(tx ReadWriteTx, draft *PostDraft) error {
+	fname := filepath.Join(db.root, postDraftsDir, draft.ID.String())
+	return db.saveJsonFile(fname, draft)
+}
+
+// ReadDraft reads the post draft with the given id.
+func (db *DB) ReadDraft(tx ReadTx, id clientintf.ID) (PostDraft, error) {
+	fname := filepath.Join(db.root, postDraftsDir, id.String())
+	var draft PostDraft
+	err := db.readJsonFile(fname, &draft)
+	return draft, err
+}
+
+// ListDrafts lists all existing post drafts, sorted by last update time.
+func (db *DB) ListDrafts(tx ReadTx) ([]PostDraft, error) {
+	pattern := filepath.Join(db.root, postDraftsDir, "*")
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]PostDraft, 0, len(files))
+	for _, fname := range files {
+		var draft PostDraft
+		if err := db.readJsonFile(fname, &draft); err != nil {
+			db.log.Warnf("Unable to read post draft %s: %v", fname, err)
+			continue
+		}
+		res = append(res, draft)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Updated.Before(res[j].Updated)
+	})
+	return res, nil
+}
+
+// RemoveDraft removes the post draft with the given id.
+func (db *DB) RemoveDraft(tx ReadWriteTx, id clientintf.ID) error {
+	fname := filepath.Join(db.root, postDraftsDir, id.String())
+	err := os.Remove(fname)
+	if os.IsNotExist(err) {
+		return nil // Not an error.
+	}
+	return err
+}
@@ -0,0 +1,224 @@
+package clientdb
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/companyzero/bisonrelay/rpc"
+)
+
+// clampFileRange truncates the given range to the end of the file described
+// by the metadata.
+func clampFileRange(fm *rpc.FileMetadata, r FileRange) (FileRange, error) {
+	if r.Length == 0 {
+		return r, fmt.Errorf("empty file range")
+	}
+	if r.Offset >= fm.Size {
+		return r, fmt.Errorf("range offset %d is outside file of size %d",
+			r.Offset, fm.Size)
+	}
+	if r.Length > fm.Size-r.Offset {
+		r.Length = fm.Size - r.Offset
+	}
+	return r, nil
+}
+
+// checkManifestSize returns an error if the sizes of the chunks in the manifest
+// of the given (non-Merkle) metadata do not add up to the file size.
+func checkManifestSize(fm *rpc.FileMetadata) error {
+	if fm.IsMerkle() {
+		return nil
+	}
+	var total uint64
+	for i, chunk := range fm.Manifest {
+		if chunk.Size > fm.Size-total {
+			return fmt.Errorf("chunk %d extends past file of size %d",
+				i, fm.Size)
+		}
+		total += chunk.Size
+	}
+	if total != fm.Size {
+		return fmt.Errorf("chunks add up to %d bytes instead of file "+
+			"size %d", total, fm.Size)
+	}
+	return nil
+}
+
+// FileRangeChunks returns the indices of the chunks of the file described by
+// the given metadata that hold the bytes of the given range. Ranges that
+// extend past the end of the file are truncated.
+//
+// Ranges of compressed files are not supported, because their chunks do not
+// hold the original bytes of the file.
+func FileRangeChunks(fm *rpc.FileMetadata, r FileRange) ([]int, error) {
+	if fm.Compression() != "" {
+		return nil, fmt.Errorf("cannot read ranges of compressed file "+
+			"(compression %q)", fm.Compression())
+	}
+	r, err := clampFileRange(fm, r)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []int
+	end := r.Offset + r.Length
+	var chunkStart uint64
+	for i := 0; i < fm.ChunkCount() && chunkStart < end; i++ {
+		chunkEnd := chunkStart + fm.ChunkLen(i)
+		if chunkEnd > r.Offset {
+			res = append(res, i)
+		}
+		chunkStart = chunkEnd
+	}
+	return res, nil
+}
+
+// AddFileDownloadRange adds a range of the file to be fetched in the given
+// download.
+func (db *DB) AddFileDownloadRange(tx ReadWriteTx, fd *FileDownload, r FileRange) error {
+	if fd.Metadata != nil {
+		if _, err := FileRangeChunks(fd.Metadata, r); err != nil {
+			return err
+		}
+	}
+	for _, fr := range fd.Ranges {
+		if fr == r {
+			return nil
+		}
+	}
+	fd.Ranges = append(fd.Ranges, r)
+
+	diskDir := filepath.Join(db.root, downloadingDir)
+	metaPath := filepath.Join(diskDir, fd.FID.String()+contentMetaExt)
+	return db.saveJsonFile(metaPath, fd)
+}
+
+// MissingFileDownloadRangeChunks returns the missing chunks of the download
+// that should be requested. For partial downloads, only the missing chunks of
+// the requested ranges are returned.
+func (db *DB) MissingFileDownloadRangeChunks(tx ReadTx, fd *FileDownload) []int {
+	missing := db.MissingFileDownloadChunks(tx, fd)
+	if !fd.Partial || len(missing) == 0 {
+		return missing
+	}
+
+	wanted := make(map[int]struct{})
+	for _, r := range fd.Ranges {
+		chunks, err := FileRangeChunks(fd.Metadata, r)
+		if err != nil {
+			db.log.Warnf("Invalid range of download %s: %v", fd.FID, err)
+			continue
+		}
+		for _, idx := range chunks {
+			wanted[idx] = struct{}{}
+		}
+	}
+
+	res := make([]int, 0, len(wanted))
+	for _, idx := range missing {
+		if _, ok := wanted[idx]; ok {
+			res = append(res, idx)
+		}
+	}
+	return res
+}
+
+// CompleteFileDownloadRanges removes the requested ranges of the download for
+// which all chunks have been downloaded. It returns the removed ranges.
+func (db *DB) CompleteFileDownloadRanges(tx ReadWriteTx, fd *FileDownload) ([]FileRange, error) {
+	if len(fd.Ranges) == 0 || fd.Metadata == nil {
+		return nil, nil
+	}
+
+	var completed, pending []FileRange
+	if fd.CompletedName != "" {
+		completed = fd.Ranges
+	} else {
+		missing := make(map[int]struct{})
+		for _, idx := range db.MissingFileDownloadChunks(tx, fd) {
+			missing[idx] = struct{}{}
+		}
+		for _, r := range fd.Ranges {
+			chunks, err := FileRangeChunks(fd.Metadata, r)
+			if err != nil {
+				// Drop invalid ranges.
+				continue
+			}
+			done := true
+			for _, idx := range chunks {
+				if _, ok := missing[idx]; ok {
+					done = false
+					break
+				}
+			}
+			if done {
+				completed = append(completed, r)
+			} else {
+				pending = append(pending, r)
+			}
+		}
+	}
+	if len(pending) == len(fd.Ranges) {
+		return nil, nil
+	}
+
+	fd.Ranges = pending
+	diskDir := filepath.Join(db.root, downloadingDir)
+	metaPath := filepath.Join(diskDir, fd.FID.String()+contentMetaExt)
+	return completed, db.saveJsonFile(metaPath, fd)
+}
+
+// ReadFileDownloadRange returns the data of the given range of a download.
+// All chunks of the range must have been downloaded. Ranges that extend past
+// the end of the file are truncated.
+func (db *DB) ReadFileDownloadRange(tx ReadTx, fd *FileDownload, r FileRange) ([]byte, error) {
+	if fd.Metadata == nil {
+		return nil, fmt.Errorf("metadata of download %s not received yet", fd.FID)
+	}
+	r, err := clampFileRange(fd.Metadata, r)
+	if err != nil {
+		return nil, err
+	}
+	chunks, err := FileRangeChunks(fd.Metadata, r)
+	if err != nil {
+		return nil, err
+	}
+	if fd.CompletedName != "" {
+		return nil, fmt.Errorf("download %s already completed", fd.FID)
+	}
+	if len(chunks) == 0 {
+		return nil, fmt.Errorf("no chunks of download %s hold range %d+%d",
+			fd.FID, r.Offset, r.Length)
+	}
+
+	// Figure out the offset of the first chunk of the range.
+	var start uint64
+	for i := 0; i < chunks[0]; i++ {
+		start += fd.Metadata.ChunkLen(i)
+	}
+
+	diskDir := filepath.Join(db.root, downloadingDir)
+	chunkDir := filepath.Join(diskDir, fd.FID.String()+chunkDirSuffix)
+	data := make([]byte, 0, r.Length)
+	for _, idx := range chunks {
+		chunkFname := filepath.Join(chunkDir, downloadChunkFname(fd.Metadata, idx))
+		chunk, err := os.ReadFile(chunkFname)
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("chunk %d of download %s: %w", idx,
+				fd.FID, ErrNotFound)
+		}
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, chunk...)
+	}
+
+	begin := r.Offset - start
+	if r.Offset < start || uint64(len(data)) < begin+r.Length {
+		return nil, fmt.Errorf("chunks of download %s hold %d bytes at "+
+			"offset %d, which do not cover range %d+%d", fd.FID,
+			len(data), start, r.Offset, r.Length)
+	}
+	return data[begin : begin+r.Length], nil
+}
@@ -0,0 +1,252 @@
+package clientdb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/companyzero/bisonrelay/rpc"
+)
+
+// mimeTypes is the table of MIME types known to the client, indexed by file
+// extension. The same table is used to detect the type of shared, embedded
+// and downloaded files, so that the detected types are the ones the viewers
+// (see MIMETypeMatches) are configured with.
+var mimeTypes = map[string]string{
+	".7z":   "application/x-7z-compressed",
+	".avi":  "video/x-msvideo",
+	".bmp":  "image/bmp",
+	".bz2":  "application/x-bzip2",
+	".css":  "text/css",
+	".csv":  "text/csv",
+	".epub": "application/epub+zip",
+	".flac": "audio/flac",
+	".gif":  "image/gif",
+	".go":   "text/plain",
+	".gz":   "application/gzip",
+	".htm":  "text/html",
+	".html": "text/html",
+	".jpeg": "image/jpeg",
+	".jpg":  "image/jpeg",
+	".js":   "text/javascript",
+	".json": "application/json",
+	".log":  "text/plain",
+	".md":   "text/markdown",
+	".mkv":  "video/x-matroska",
+	".mov":  "video/quicktime",
+	".mp3":  "audio/mpeg",
+	".mp4":  "video/mp4",
+	".odt":  "application/vnd.oasis.opendocument.text",
+	".ogg":  "audio/ogg",
+	".opus": "audio/opus",
+	".pdf":  "application/pdf",
+	".png":  "image/png",
+	".svg":  "image/svg+xml",
+	".tar":  "application/x-tar",
+	".txt":  "text/plain",
+	".wav":  "audio/wav",
+	".webm": "video/webm",
+	".webp": "image/webp",
+	".xml":  "text/xml",
+	".xz":   "application/x-xz",
+	".zip":  "application/zip",
+	".zst":  "application/zstd",
+}
+
+// mimeSignatures are the magic numbers used to detect the type of files
+// without a known extension. Every type must also be in mimeTypes.
+var mimeSignatures = []struct {
+	magic string
+	typ   string
+}{
+	{"\x89PNG\r\n\x1a\n", "image/png"},
+	{"\xff\xd8\xff", "image/jpeg"},
+	{"GIF87a", "image/gif"},
+	{"GIF89a", "image/gif"},
+	{"BM", "image/bmp"},
+	{"%PDF-", "application/pdf"},
+	{"PK\x03\x04", "application/zip"},
+	{"\x1f\x8b\x08", "application/gzip"},
+	{"7z\xbc\xaf\x27\x1c", "application/x-7z-compressed"},
+	{"BZh", "application/x-bzip2"},
+	{"\xfd7zXZ\x00", "application/x-xz"},
+	{"\x28\xb5\x2f\xfd", "application/zstd"},
+	{"ID3", "audio/mpeg"},
+	{"OggS", "audio/ogg"},
+	{"fLaC", "audio/flac"},
+	{"\x1a\x45\xdf\xa3", "video/x-matroska"},
+}
+
+// FileMIMEType returns the MIME type of the given file, detected from its
+// extension. It returns an empty string if the type is unknown.
+func FileMIMEType(filename string) string {
+	return mimeTypes[strings.ToLower(filepath.Ext(filename))]
+}
+
+// MetadataMIMEType returns the MIME type of the file described by the given
+// metadata. The type detected by the sharer is used if available, otherwise
+// the type is detected from the extension of the file.
+func MetadataMIMEType(fm *rpc.FileMetadata) string {
+	if typ := mediaType(fm.MIMEType()); typ != "" {
+		return typ
+	}
+	return FileMIMEType(fm.Filename)
+}
+
+// DetectFileMIMEType detects the MIME type of the given local file. The
+// extension of the file is used when it maps to a known type, otherwise the
+// type is detected from the start of the file contents. It returns an empty
+// string if the type is unknown.
+func DetectFileMIMEType(fname string) (string, error) {
+	if typ := FileMIMEType(fname); typ != "" {
+		return typ, nil
+	}
+
+	f, err := os.Open(fname)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	return detectMIMEType(buf[:n]), nil
+}
+
+// detectMIMEType detects the MIME type of a file from the start of its
+// contents.
+func detectMIMEType(data []byte) string {
+	for _, sig := range mimeSignatures {
+		if bytes.HasPrefix(data, []byte(sig.magic)) {
+			return sig.typ
+		}
+	}
+	if len(data) == 0 {
+		return ""
+	}
+
+	// Consider valid UTF-8 without control characters (other than
+	// whitespace) as plain text. The data may be truncated in the middle
+	// of a multi-byte char.
+	for i, r := range string(data) {
+		if r == utf8.RuneError && len(data)-i >= utf8.UTFMax {
+			return ""
+		}
+		if r < 0x20 && r != '\t' && r != '\n' && r != '\r' && r != '\f' {
+			return ""
+		}
+	}
+	return "text/plain"
+}
+
+// MIMETypeMatches returns true if the given MIME type matches pattern. The
+// pattern may end in "/*" to match all subtypes of a type (e.g. "image/*").
+func MIMETypeMatches(pattern, typ string) bool {
+	if strings.HasSuffix(pattern, "/*") {
+		return strings.HasPrefix(typ, pattern[:len(pattern)-1])
+	}
+	return typ == pattern
+}
+
+// mediaType returns the media type of the given MIME type, without any
+// parameters.
+func mediaType(typ string) string {
+	if typ == "" {
+		return ""
+	}
+	if mediaType, _, err := mime.ParseMediaType(typ); err == nil {
+		return mediaType
+	}
+	return typ
+}
+
+// compressFile compresses srcFile into dstFile. It returns the size of the
+// compressed file.
+func compressFile(srcFile, dstFile string) (uint64, error) {
+	src, err := os.Open(srcFile)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(dstFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return 0, err
+	}
+	defer dst.Close()
+
+	// The gzip header is left empty (no name or modification time) so that
+	// compressing the same file always produces the same output.
+	w, err := gzip.NewWriterLevel(dst, gzip.BestCompression)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := io.Copy(w, src); err != nil {
+		return 0, err
+	}
+	if err := w.Close(); err != nil {
+		return 0, err
+	}
+	fi, err := dst.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return uint64(fi.Size()), nil
+}
+
+// decompressFile decompresses the downloaded srcFile into dst, according to
+// the compression attributes of the file metadata. The decompressed data must
+// have the original size of the file.
+func decompressFile(srcFile string, dst io.Writer, fm *rpc.FileMetadata) error {
+	if fm.Compression() != rpc.FileCompressionGzip {
+		return fmt.Errorf("unsupported file compression %q", fm.Compression())
+	}
+	origSize, err := strconv.ParseUint(fm.Attributes[rpc.FileAttrOriginalSize], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid original size of compressed file: %v", err)
+	}
+
+	src, err := os.Open(srcFile)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	r, err := gzip.NewReader(src)
+	if err != nil {
+		return err
+	}
+
+	// Read at most one byte past the original size, to detect (and avoid
+	// fully decompressing) files that are larger than advertised.
+	n, err := io.Copy(dst, io.LimitReader(r, int64(origSize)+1))
+	if err != nil {
+		return fmt.Errorf("unable to decompress file: %w", err)
+	}
+	if uint64(n) != origSize {
+		return fmt.Errorf("decompressed file size %d is different than "+
+			"original size %d", n, origSize)
+	}
+	return r.Close()
+}
+
+// checkFileCompression returns an error if the file described by the given
+// metadata is compressed with an unsupported algorithm.
+func checkFileCompression(fm *rpc.FileMetadata) error {
+	switch fm.Compression() {
+	case "", rpc.FileCompressionGzip:
+		return nil
+	default:
+		return fmt.Errorf("unsupported file compression %q", fm.Compression())
+	}
+}
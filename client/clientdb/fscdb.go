@@ -33,6 +33,12 @@ const (
 	postsSubscribers   = "subscribers"
 	postsSubscriptions = "subscriptns"
 	postsStatusExt     = ".status"
+	postsPaywallExt    = ".paywall"
+	postsRecipientsExt = ".recipients"
+	postsSubSettings   = "subsettings.json"
+	postsPaidSubs      = "paidsubscribers.json"
+	remoteSubPriceFile = "postssubprice.json"
+	postDraftsDir      = "postdrafts"
 	kxDir              = "kx"
 	transResetFile     = "transreset.json"
 	sendqDir           = "sendqueue"
@@ -43,8 +49,14 @@ const (
 	miRequestsDir      = "mirequests"
 	postKXActionsDir   = "postkxactions"
 	payStatsFile       = "paystats.json"
+	tipHistoryFile     = "tips.json"
+	paymentRequestsDir = "payrequests"
+	postageFile        = "postage.json"
+	postageStateFile   = "postagestate.json"
 	unackedRMsDir      = "unackedrms"
 	lastConnDateFile   = "lastconndate.json"
+	serverCreditFile   = "servercredit.json"
+	creditReceiptsFile = "creditreceipts.json"
 )
 
 func (db *DB) LocalID(tx ReadTx) (*zkidentity.FullIdentity, error) {
@@ -136,6 +136,37 @@ type SharedFileAndShares struct {
 	Size   uint64          `json:"size"`
 	Global bool            `json:"global"`
 	Shares []clientintf.ID `json:"shares"`
+
+	// GCs are the GCs whose members the file is shared with.
+	GCs []zkidentity.ShortID `json:"gcs,omitempty"`
+}
+
+// SharedCollection is a locally shared collection of files.
+type SharedCollection struct {
+	// ID is the ID of the collection (see rpc.FileCollection.ID).
+	ID FileID `json:"id"`
+
+	// Dir is the local dir that was shared as the collection.
+	Dir string `json:"dir"`
+
+	// UID is the user the collection is shared with. If nil, the
+	// collection is shared with all users.
+	UID *UserID `json:"uid,omitempty"`
+
+	Collection rpc.FileCollection `json:"collection"`
+}
+
+// Summary returns the summary of the collection that is sent in file
+// listings.
+func (sc *SharedCollection) Summary() rpc.CollectionSummary {
+	return rpc.CollectionSummary{
+		ID:          sc.ID.String(),
+		Name:        sc.Collection.Name,
+		Description: sc.Collection.Description,
+		Size:        sc.Collection.Size,
+		Cost:        sc.Collection.Cost,
+		NbFiles:     len(sc.Collection.Files),
+	}
 }
 
 type ChunkState string
@@ -148,6 +179,11 @@ const (
 	ChunkStatePaid           ChunkState = "paid"
 	ChunkStateUploaded       ChunkState = "uploaded"
 	ChunkStateDownloaded     ChunkState = "downloaded"
+
+	// ChunkStateInvoiceExpired is the state of a chunk download when the
+	// invoice received for it expired before it could be paid. A new
+	// invoice needs to be requested from the uploader.
+	ChunkStateInvoiceExpired ChunkState = "invoice_expired"
 )
 
 type FileDownload struct {
@@ -159,6 +195,116 @@ type FileDownload struct {
 	ChunkStates      map[int]ChunkState `json:"chunkstates"`
 	ChunkUpdatedTime map[int]time.Time  `json:"chunkupdttimes"`
 	IsSentFile       bool               `json:"is_sent_file"`
+	Paused           bool               `json:"paused,omitempty"`
+
+	// Sources are additional remote users sharing the same file, from
+	// which chunks may also be downloaded.
+	Sources []DownloadSource `json:"sources,omitempty"`
+
+	// ChunkSources tracks which user each chunk was requested from. Chunks
+	// not in this map are requested from UID.
+	ChunkSources map[int]UserID `json:"chunk_sources,omitempty"`
+
+	// Collection is the ID of the collection this file is being
+	// downloaded as part of, if any.
+	Collection string `json:"collection,omitempty"`
+
+	// CollectionCost and CollectionSize are the cost and size of the file
+	// as listed in the signed collection manifest. The metadata received
+	// for the file must match them.
+	CollectionCost uint64 `json:"collection_cost,omitempty"`
+	CollectionSize uint64 `json:"collection_size,omitempty"`
+
+	// DestDir is the dir, relative to the downloads dir of the user, where
+	// the completed file is placed. It is used to keep the folder
+	// structure of collections.
+	DestDir string `json:"dest_dir,omitempty"`
+
+	// StartTime is when the download was started.
+	StartTime time.Time `json:"start_time,omitempty"`
+
+	// PaidMAtoms is the total amount paid for chunks of the download.
+	PaidMAtoms int64 `json:"paid_matoms,omitempty"`
+
+	// InvoiceRetries tracks how many times the invoice for a chunk expired
+	// before it could be paid. Key is chunk index.
+	InvoiceRetries map[int]int `json:"invoice_retries,omitempty"`
+
+	// MIMEType is the MIME type of the completed file.
+	MIMEType string `json:"mime_type,omitempty"`
+
+	// Partial is set for downloads started by fetching a range of the
+	// file. Only the chunks of the pending Ranges are requested, until the
+	// full file is requested.
+	Partial bool `json:"partial,omitempty"`
+
+	// Ranges are the requested ranges of the file that have not been
+	// fully downloaded yet.
+	Ranges []FileRange `json:"ranges,omitempty"`
+}
+
+// FileRange is a range of bytes of a file. For compressed files, the range
+// refers to the compressed contents.
+type FileRange struct {
+	Offset uint64 `json:"offset"`
+	Length uint64 `json:"length"`
+}
+
+// DownloadSource is a remote user that shares a file with the same content as
+// the one being downloaded. FID is the ID of the file as shared by that user.
+type DownloadSource struct {
+	UID  UserID `json:"uid"`
+	FID  FileID `json:"fid"`
+	Cost uint64 `json:"cost"`
+}
+
+// SourceUIDs returns the IDs of all users the file may be downloaded from,
+// starting with the original uploader.
+func (fd *FileDownload) SourceUIDs() []UserID {
+	res := make([]UserID, 0, len(fd.Sources)+1)
+	res = append(res, fd.UID)
+	for _, src := range fd.Sources {
+		res = append(res, src.UID)
+	}
+	return res
+}
+
+// SourceFID returns the ID of the file as shared by the given source. It
+// returns false if uid is not a source of this download.
+func (fd *FileDownload) SourceFID(uid UserID) (FileID, bool) {
+	if uid == fd.UID {
+		return fd.FID, true
+	}
+	for _, src := range fd.Sources {
+		if src.UID == uid {
+			return src.FID, true
+		}
+	}
+	return FileID{}, false
+}
+
+// SourceMetadata returns the metadata of the file as shared by the given
+// source. The content of the file is the same for all sources, but the cost
+// to download it may differ.
+func (fd *FileDownload) SourceMetadata(uid UserID) *rpc.FileMetadata {
+	if fd.Metadata == nil || uid == fd.UID {
+		return fd.Metadata
+	}
+	md := *fd.Metadata
+	for _, src := range fd.Sources {
+		if src.UID == uid {
+			md.Cost = src.Cost
+		}
+	}
+	return &md
+}
+
+// ChunkSource returns the user the given chunk was requested from.
+func (fd *FileDownload) ChunkSource(chunkIdx int) UserID {
+	if uid, ok := fd.ChunkSources[chunkIdx]; ok {
+		return uid
+	}
+	return fd.UID
 }
 
 func (fd *FileDownload) GetChunkState(chunkIdx int) ChunkState {
@@ -204,6 +350,41 @@ type ChunkUpload struct {
 	State    ChunkState `json:"state"`
 }
 
+// HasInvoice returns true if the given invoice is one of the outstanding
+// invoices of the chunk upload.
+func (cup *ChunkUpload) HasInvoice(invoice string) bool {
+	for _, inv := range cup.Invoices {
+		if inv == invoice {
+			return true
+		}
+	}
+	return false
+}
+
+// FileUpload tracks the outstanding chunk uploads of a file to a remote user.
+type FileUpload struct {
+	UID    UserID          `json:"uid"`
+	FID    FileID          `json:"fid"`
+	Paused bool            `json:"paused"`
+	Chunks []ChunkUpload   `json:"chunks"`
+	Stats  FileUploadStats `json:"stats"`
+}
+
+// FileUploadStats tracks the progress of the upload of a file to a remote
+// user.
+type FileUploadStats struct {
+	StartTime  time.Time `json:"start_time"`
+	LastUpdate time.Time `json:"last_update"`
+
+	// SentChunks are the indices of the chunks already sent.
+	SentChunks []int  `json:"sent_chunks"`
+	SentBytes  uint64 `json:"sent_bytes"`
+
+	// PaidChunks and PaidMAtoms track the payments received for chunks.
+	PaidChunks int   `json:"paid_chunks"`
+	PaidMAtoms int64 `json:"paid_matoms"`
+}
+
 type RemoteFile struct {
 	FID      FileID           `json:"file_id"`
 	UID      UserID           `json:"uid"`
@@ -221,6 +402,70 @@ type PostSummary struct {
 	Title        string    `json:"title"`
 }
 
+// PaywalledPostContent is the content of a paywalled post that is only
+// released after payment.
+type PaywalledPostContent struct {
+	Body string `json:"body"`
+
+	// Buyers is the list of users that paid to unlock the post. Only
+	// filled for posts authored by the local client.
+	Buyers []UserID `json:"buyers,omitempty"`
+
+	// PendingSales are the invoices generated for users to unlock the
+	// post that have not been paid yet. Only filled for posts authored
+	// by the local client.
+	PendingSales []PaywalledPostSale `json:"pending_sales,omitempty"`
+}
+
+// PaywalledPostSale is an invoice generated for a user to unlock a paywalled
+// post.
+type PaywalledPostSale struct {
+	Buyer   UserID `json:"buyer"`
+	Invoice string `json:"invoice"`
+}
+
+// PostDraft is an unpublished post or comment, stored locally so that it can
+// be edited across restarts before being published.
+type PostDraft struct {
+	ID      clientintf.ID `json:"id"`
+	Content string        `json:"content"`
+	Descr   string        `json:"descr"`
+
+	// CommentFrom and CommentPID are set when the draft is of a comment
+	// on the given post. CommentParent is set when the comment is a reply
+	// to another comment.
+	CommentFrom   *UserID        `json:"comment_from,omitempty"`
+	CommentPID    *PostID        `json:"comment_pid,omitempty"`
+	CommentParent *clientintf.ID `json:"comment_parent,omitempty"`
+
+	// EstSize is the estimated size of the published post, as of the last
+	// time the draft was saved.
+	EstSize uint64 `json:"est_size"`
+
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+// IsComment returns true if this is a draft of a comment.
+func (d *PostDraft) IsComment() bool {
+	return d.CommentPID != nil
+}
+
+// PostSearchFilters are the optional filters applied when searching for
+// posts. Zero values disable the corresponding filter.
+type PostSearchFilters struct {
+	// Author restricts results to posts authored by this user.
+	Author *UserID
+
+	// Since and Until restrict results to posts received within the given
+	// date range.
+	Since time.Time
+	Until time.Time
+
+	// HasAttachment restricts results to posts that have an attached file.
+	HasAttachment bool
+}
+
 type PostSubscription struct {
 	To   UserID    `json:"to"`
 	Date time.Time `json:"date"`
@@ -286,6 +531,27 @@ type PayStatsSummary struct {
 	Total  int64  `json:"total"`
 }
 
+// PayStatsPeriod is the length of the time buckets of payment stats reports.
+type PayStatsPeriod string
+
+const (
+	PayStatsDaily   PayStatsPeriod = "daily"
+	PayStatsWeekly  PayStatsPeriod = "weekly"
+	PayStatsMonthly PayStatsPeriod = "monthly"
+)
+
+// PayStatsBucket aggregates the payment events of a single category (the first
+// level of the event), related to a single user, during a period of time.
+// Amounts are in milliatoms and are always positive.
+type PayStatsBucket struct {
+	Start    time.Time `json:"start"`
+	UID      UserID    `json:"uid"`
+	Category string    `json:"category"`
+	Sent     int64     `json:"sent"`
+	Received int64     `json:"received"`
+	PayFee   int64     `json:"pay_fee"`
+}
+
 // UnackedRM is an already encrypted but unacked RM.
 type UnackedRM struct {
 	UID       UserID  `json:"uid"`
@@ -0,0 +1,328 @@
+package clientdb
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/companyzero/bisonrelay/zkidentity"
+)
+
+const (
+	paymentLimitsFile = "paymentlimits.json"
+	paymentBudgetFile = "paymentbudget.json"
+)
+
+// ErrPaymentLimitExceeded is returned when an outbound payment would exceed
+// one of the configured spending limits.
+var ErrPaymentLimitExceeded = errors.New("spending limit exceeded")
+
+// PaymentLimit is the daily spending limit of payments related to a single
+// user or GC.
+type PaymentLimit struct {
+	ID         zkidentity.ShortID `json:"id"`
+	DailyLimit int64              `json:"daily_limit"`
+}
+
+// PaymentLimits are the limits enforced on outbound payments. All amounts are
+// in milliatoms and include the fees of the payments. Zero values mean no
+// limit.
+type PaymentLimits struct {
+	// DailyLimit is the max amount that may be spent per day, across all
+	// payments.
+	DailyLimit int64 `json:"daily_limit"`
+
+	// ApprovalThreshold is the amount above which any single payment needs
+	// to be explicitly approved.
+	ApprovalThreshold int64 `json:"approval_threshold"`
+
+	// Users are the daily limits of payments related to individual users.
+	Users []PaymentLimit `json:"users,omitempty"`
+
+	// GCs are the daily limits of payments related to individual GCs.
+	GCs []PaymentLimit `json:"gcs,omitempty"`
+}
+
+// UserLimit returns the daily limit of payments related to the given user.
+func (pl *PaymentLimits) UserLimit(uid UserID) int64 {
+	for _, l := range pl.Users {
+		if l.ID == uid {
+			return l.DailyLimit
+		}
+	}
+	return 0
+}
+
+// GCLimit returns the daily limit of payments related to the given GC.
+func (pl *PaymentLimits) GCLimit(gcID zkidentity.ShortID) int64 {
+	for _, l := range pl.GCs {
+		if l.ID == gcID {
+			return l.DailyLimit
+		}
+	}
+	return 0
+}
+
+// setLimit sets (or removes, if limit is zero) the limit of the given id in
+// the list of limits.
+func setLimit(limits []PaymentLimit, id zkidentity.ShortID, limit int64) []PaymentLimit {
+	for i := range limits {
+		if limits[i].ID != id {
+			continue
+		}
+		if limit == 0 {
+			return append(limits[:i], limits[i+1:]...)
+		}
+		limits[i].DailyLimit = limit
+		return limits
+	}
+	if limit == 0 {
+		return limits
+	}
+	return append(limits, PaymentLimit{ID: id, DailyLimit: limit})
+}
+
+// SetUserLimit sets the daily limit of payments related to the given user. A
+// zero limit removes the limit.
+func (pl *PaymentLimits) SetUserLimit(uid UserID, limit int64) {
+	pl.Users = setLimit(pl.Users, uid, limit)
+}
+
+// SetGCLimit sets the daily limit of payments related to the given GC. A zero
+// limit removes the limit.
+func (pl *PaymentLimits) SetGCLimit(gcID zkidentity.ShortID, limit int64) {
+	pl.GCs = setLimit(pl.GCs, gcID, limit)
+}
+
+// PaymentBudget tracks the amounts spent on outbound payments during a day.
+// Amounts are in milliatoms and include payment fees.
+type PaymentBudget struct {
+	// Day is the local date the amounts refer to, in YYYY-MM-DD format.
+	Day string `json:"day"`
+
+	// Spent is the total amount spent.
+	Spent int64 `json:"spent"`
+
+	// Users is the amount spent on payments related to each user, keyed
+	// by user ID.
+	Users map[string]int64 `json:"users,omitempty"`
+
+	// GCs is the amount spent on payments related to each GC, keyed by
+	// the short log ID of the GC (which is the one included in payment
+	// events).
+	GCs map[string]int64 `json:"gcs,omitempty"`
+}
+
+// UserSpent returns the amount spent on payments related to the given user.
+func (pb *PaymentBudget) UserSpent(uid UserID) int64 {
+	return pb.Users[uid.String()]
+}
+
+// GCSpent returns the amount spent on payments related to the given GC.
+func (pb *PaymentBudget) GCSpent(gcID zkidentity.ShortID) int64 {
+	return pb.GCs[gcID.ShortLogID()]
+}
+
+// GetPaymentLimits returns the configured limits of outbound payments.
+func (db *DB) GetPaymentLimits(tx ReadTx) (PaymentLimits, error) {
+	var limits PaymentLimits
+	fname := filepath.Join(db.root, paymentLimitsFile)
+	err := db.readJsonFile(fname, &limits)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return limits, err
+	}
+	return limits, nil
+}
+
+// SetPaymentLimits replaces the configured limits of outbound payments.
+func (db *DB) SetPaymentLimits(tx ReadWriteTx, limits PaymentLimits) error {
+	if limits.DailyLimit < 0 || limits.ApprovalThreshold < 0 {
+		return fmt.Errorf("payment limits cannot be negative")
+	}
+	fname := filepath.Join(db.root, paymentLimitsFile)
+	return db.saveJsonFile(fname, limits)
+}
+
+// GetPaymentBudget returns the amounts spent on outbound payments today.
+func (db *DB) GetPaymentBudget(tx ReadTx) (PaymentBudget, error) {
+	var budget PaymentBudget
+	fname := filepath.Join(db.root, paymentBudgetFile)
+	err := db.readJsonFile(fname, &budget)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return budget, err
+	}
+
+	// Start a new budget when the day changes.
+	today := time.Now().Format("2006-01-02")
+	if budget.Day != today {
+		budget = PaymentBudget{Day: today}
+	}
+	return budget, nil
+}
+
+// paymentReservationTimeout is how long a reservation made by ReservePayment
+// is kept when the payment is not recorded. This ensures reservations of
+// failed payments are eventually released.
+const paymentReservationTimeout = 10 * time.Minute
+
+// paymentReservation is an amount reserved for an outbound payment that has
+// been approved but not yet recorded.
+type paymentReservation struct {
+	uid     string
+	gc      string
+	amount  int64
+	expires time.Time
+}
+
+// reservedAmounts returns the total amounts of the unexpired reservations,
+// along with the amounts reserved for the given user and GC.
+func (db *DB) reservedAmounts(uid, gc string) (total, user, gcTotal int64) {
+	now := time.Now()
+	res := db.payReservations[:0]
+	for _, r := range db.payReservations {
+		if now.After(r.expires) {
+			continue
+		}
+		res = append(res, r)
+		total += r.amount
+		if uid != "" && r.uid == uid {
+			user += r.amount
+		}
+		if gc != "" && r.gc == gc {
+			gcTotal += r.amount
+		}
+	}
+	db.payReservations = res
+	return
+}
+
+// ReservePayment returns an error wrapping ErrPaymentLimitExceeded if an
+// outbound payment of the given amount, related to the given user and GC
+// (either of which may be nil), would exceed any of the daily limits.
+// Otherwise, the amount is reserved until the payment is recorded, so that
+// concurrent payments cannot overrun the limits.
+func (db *DB) ReservePayment(tx ReadWriteTx, uid *UserID, gcID *zkidentity.ShortID,
+	amount int64) error {
+
+	limits, err := db.GetPaymentLimits(tx)
+	if err != nil {
+		return err
+	}
+	budget, err := db.GetPaymentBudget(tx)
+	if err != nil {
+		return err
+	}
+
+	var uidStr, gcStr string
+	if uid != nil {
+		uidStr = uid.String()
+	}
+	if gcID != nil {
+		gcStr = gcID.ShortLogID()
+	}
+	reserved, userReserved, gcReserved := db.reservedAmounts(uidStr, gcStr)
+
+	exceeds := func(limit, spent int64) bool {
+		return limit > 0 && spent+amount > limit
+	}
+	if exceeds(limits.DailyLimit, budget.Spent+reserved) {
+		return fmt.Errorf("%w: daily limit of %d milliatoms (spent %d, "+
+			"reserved %d)", ErrPaymentLimitExceeded, limits.DailyLimit,
+			budget.Spent, reserved)
+	}
+	if uid != nil && exceeds(limits.UserLimit(*uid), budget.UserSpent(*uid)+userReserved) {
+		return fmt.Errorf("%w: daily limit of %d milliatoms for user %s "+
+			"(spent %d, reserved %d)", ErrPaymentLimitExceeded,
+			limits.UserLimit(*uid), uid, budget.UserSpent(*uid),
+			userReserved)
+	}
+	if gcID != nil && exceeds(limits.GCLimit(*gcID), budget.GCSpent(*gcID)+gcReserved) {
+		return fmt.Errorf("%w: daily limit of %d milliatoms for GC %s "+
+			"(spent %d, reserved %d)", ErrPaymentLimitExceeded,
+			limits.GCLimit(*gcID), gcID, budget.GCSpent(*gcID),
+			gcReserved)
+	}
+
+	db.payReservations = append(db.payReservations, paymentReservation{
+		uid:     uidStr,
+		gc:      gcStr,
+		amount:  amount,
+		expires: time.Now().Add(paymentReservationTimeout),
+	})
+	return nil
+}
+
+// ReleasePayment releases the amount reserved by ReservePayment for a
+// payment that will not be made.
+func (db *DB) ReleasePayment(tx ReadWriteTx, uid *UserID, gcID *zkidentity.ShortID,
+	amount int64) {
+
+	var uidStr, gcStr string
+	if uid != nil {
+		uidStr = uid.String()
+	}
+	if gcID != nil {
+		gcStr = gcID.ShortLogID()
+	}
+	db.releasePaymentReservation(uidStr, gcStr, amount)
+}
+
+// releasePaymentReservation releases up to amount from the reservations
+// made for payments related to the given user and GC.
+func (db *DB) releasePaymentReservation(uid, gc string, amount int64) {
+	for i := 0; i < len(db.payReservations) && amount > 0; {
+		r := &db.payReservations[i]
+		if r.uid != uid || r.gc != gc {
+			i++
+			continue
+		}
+		if r.amount > amount {
+			r.amount -= amount
+			return
+		}
+		amount -= r.amount
+		db.payReservations = append(db.payReservations[:i],
+			db.payReservations[i+1:]...)
+	}
+}
+
+// gcFromPayEvent returns the short log ID of the GC of a payment event related
+// to a GC (for example, "gc.<id>.msg").
+func gcFromPayEvent(event string) string {
+	if !strings.HasPrefix(event, "gc.") {
+		return ""
+	}
+	id, _, _ := strings.Cut(event[len("gc."):], ".")
+	return id
+}
+
+// recordPaymentSpent adds an outbound payment, related to the given user and
+// payment event, to today's budget. The amount includes the payment fee, while
+// the reservation made for the payment (if any) is released by the amount
+// without fees.
+func (db *DB) recordPaymentSpent(tx ReadWriteTx, uid UserID, event string, amount, fee int64) error {
+	gc := gcFromPayEvent(event)
+	db.releasePaymentReservation(uid.String(), gc, amount-fee)
+
+	budget, err := db.GetPaymentBudget(tx)
+	if err != nil {
+		return err
+	}
+	if budget.Users == nil {
+		budget.Users = make(map[string]int64, 1)
+	}
+
+	budget.Spent += amount
+	budget.Users[uid.String()] += amount
+	if gc != "" {
+		if budget.GCs == nil {
+			budget.GCs = make(map[string]int64, 1)
+		}
+		budget.GCs[gc] += amount
+	}
+
+	fname := filepath.Join(db.root, paymentBudgetFile)
+	return db.saveJsonFile(fname, budget)
+}
@@ -0,0 +1,94 @@
+package clientdb
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/companyzero/bisonrelay/zkidentity"
+)
+
+// PaymentRequestStatus is the status of a payment request.
+type PaymentRequestStatus string
+
+const (
+	PaymentRequestPending  PaymentRequestStatus = "pending"
+	PaymentRequestPaid     PaymentRequestStatus = "paid"
+	PaymentRequestDeclined PaymentRequestStatus = "declined"
+)
+
+// PaymentRequest is a request for a payment exchanged with a remote user.
+type PaymentRequest struct {
+	ID  zkidentity.ShortID `json:"id"`
+	UID UserID             `json:"uid"`
+
+	// Outbound is true for requests where the local client is requesting
+	// a payment from the remote user, and false for requests where the
+	// local client is expected to pay the remote user.
+	Outbound bool `json:"outbound"`
+
+	// Fetched is true for inbound requests that were created by fetching
+	// an invoice from the remote user (as opposed to being sent by them).
+	Fetched bool `json:"fetched,omitempty"`
+
+	Invoice       string               `json:"invoice"`
+	MilliAtoms    uint64               `json:"milliatoms"`
+	Memo          string               `json:"memo,omitempty"`
+	Created       time.Time            `json:"created"`
+	Status        PaymentRequestStatus `json:"status"`
+	DeclineReason string               `json:"decline_reason,omitempty"`
+}
+
+// SavePaymentRequest saves the given payment request, replacing any existing
+// request with the same ID.
+func (db *DB) SavePaymentRequest(tx ReadWriteTx, req *PaymentRequest) error {
+	fname := filepath.Join(db.root, inboundDir, req.UID.String(),
+		paymentRequestsDir, req.ID.String())
+	return db.saveJsonFile(fname, req)
+}
+
+// GetPaymentRequest returns the payment request with the given ID, exchanged
+// with the given user.
+func (db *DB) GetPaymentRequest(tx ReadTx, uid UserID, id zkidentity.ShortID) (PaymentRequest, error) {
+	var req PaymentRequest
+	fname := filepath.Join(db.root, inboundDir, uid.String(),
+		paymentRequestsDir, id.String())
+	err := db.readJsonFile(fname, &req)
+	if errors.Is(err, ErrNotFound) {
+		err = fmt.Errorf("payment request %s: %w", id, ErrNotFound)
+	}
+	return req, err
+}
+
+// ListPaymentRequests lists the payment requests exchanged with the given
+// user, or with all users if uid is nil. The result is sorted by creation
+// date.
+func (db *DB) ListPaymentRequests(tx ReadTx, uid *UserID) ([]PaymentRequest, error) {
+	userDir := "*"
+	if uid != nil {
+		userDir = uid.String()
+	}
+	pattern := filepath.Join(db.root, inboundDir, userDir,
+		paymentRequestsDir, "*")
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]PaymentRequest, 0, len(files))
+	for _, fname := range files {
+		var req PaymentRequest
+		if err := db.readJsonFile(fname, &req); err != nil {
+			db.log.Warnf("Unable to read payment request %s: %v",
+				fname, err)
+			continue
+		}
+		res = append(res, req)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Created.Before(res[j].Created)
+	})
+	return res, nil
+}
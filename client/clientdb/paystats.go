@@ -2,6 +2,9 @@ package clientdb
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
@@ -36,6 +39,13 @@ func (db *DB) RecordUserPayEvent(tx ReadWriteTx, user UserID, event string, amou
 	userStats.TotalPayFee += -payFee
 	db.payStats[uid] = userStats
 
+	if amount < 0 {
+		err := db.recordPaymentSpent(tx, user, event, -amount-payFee, -payFee)
+		if err != nil {
+			return err
+		}
+	}
+
 	statsFname := filepath.Join(db.root, payStatsFile)
 	return db.saveJsonFile(statsFname, &db.payStats)
 }
@@ -74,10 +84,7 @@ func (db *DB) SummarizeUserPayStats(tx ReadTx, uid UserID) ([]PayStatsSummary, e
 	var evnt PayStatEvent
 	aux := make(map[string]*PayStatsSummary)
 	for err := dec.Decode(&evnt); err == nil; err = dec.Decode(&evnt) {
-		prefix := evnt.Event
-		if p := strings.Index(prefix, "."); p > -1 {
-			prefix = prefix[:p]
-		}
+		prefix := payEventCategory(evnt.Event)
 
 		stats, ok := aux[prefix]
 		if !ok {
@@ -116,6 +123,125 @@ func (db *DB) SummarizeUserPayStats(tx ReadTx, uid UserID) ([]PayStatsSummary, e
 	return res, nil
 }
 
+// payEventCategory returns the category (i.e. the first level) of a payment
+// event.
+func payEventCategory(event string) string {
+	if p := strings.Index(event, "."); p > -1 {
+		return event[:p]
+	}
+	return event
+}
+
+// periodStart returns the start of the period that includes t, in the local
+// timezone. Weeks start on Mondays.
+func (p PayStatsPeriod) periodStart(t time.Time) (time.Time, error) {
+	y, m, d := t.Date()
+	switch p {
+	case PayStatsDaily:
+		return time.Date(y, m, d, 0, 0, 0, 0, t.Location()), nil
+	case PayStatsWeekly:
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(y, m, d-offset, 0, 0, 0, 0, t.Location()), nil
+	case PayStatsMonthly:
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location()), nil
+	default:
+		return time.Time{}, fmt.Errorf("unknown pay stats period %q", p)
+	}
+}
+
+// PayStatsReport aggregates the recorded payment events into buckets of the
+// given period, per user and category. Only events after since are included.
+// If uid is nil, the events of all users are included.
+//
+// The result is sorted by period, user and category.
+func (db *DB) PayStatsReport(tx ReadTx, period PayStatsPeriod, since time.Time,
+	uid *UserID) ([]PayStatsBucket, error) {
+
+	if _, err := period.periodStart(time.Now()); err != nil {
+		return nil, err
+	}
+
+	pattern := filepath.Join(db.root, inboundDir, "*", payStatsFile)
+	if uid != nil {
+		pattern = filepath.Join(db.root, inboundDir, uid.String(), payStatsFile)
+	}
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	type bucketKey struct {
+		start    int64
+		uid      UserID
+		category string
+	}
+	buckets := make(map[bucketKey]*PayStatsBucket)
+	sinceUnix := since.Unix()
+
+	for _, fname := range files {
+		var user UserID
+		if err := user.FromString(filepath.Base(filepath.Dir(fname))); err != nil {
+			db.log.Warnf("Not a valid user ID in pay stats file %s", fname)
+			continue
+		}
+
+		f, err := os.Open(fname)
+		if err != nil {
+			return nil, err
+		}
+
+		dec := json.NewDecoder(f)
+		var evnt PayStatEvent
+		for err = dec.Decode(&evnt); err == nil; err = dec.Decode(&evnt) {
+			if evnt.Timestamp < sinceUnix {
+				continue
+			}
+
+			start, _ := period.periodStart(time.Unix(evnt.Timestamp, 0))
+			key := bucketKey{
+				start:    start.Unix(),
+				uid:      user,
+				category: payEventCategory(evnt.Event),
+			}
+			bucket, ok := buckets[key]
+			if !ok {
+				bucket = &PayStatsBucket{
+					Start:    start,
+					UID:      user,
+					Category: key.category,
+				}
+				buckets[key] = bucket
+			}
+			if evnt.Amount < 0 {
+				bucket.Sent += -evnt.Amount
+			} else {
+				bucket.Received += evnt.Amount
+			}
+			bucket.PayFee += -evnt.PayFee
+		}
+		f.Close()
+		if !errors.Is(err, io.EOF) {
+			db.log.Warnf("Unable to decode pay stats file %s: %v",
+				fname, err)
+		}
+	}
+
+	res := make([]PayStatsBucket, 0, len(buckets))
+	for _, b := range buckets {
+		res = append(res, *b)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if !res[i].Start.Equal(res[j].Start) {
+			return res[i].Start.Before(res[j].Start)
+		}
+		if res[i].UID != res[j].UID {
+			return res[i].UID.String() < res[j].UID.String()
+		}
+		return res[i].Category < res[j].Category
+	})
+	return res, nil
+}
+
 // ClearPayStats removes pay stats for the given user or for all users if user
 // equals nil.
 func (db *DB) ClearPayStats(tx ReadWriteTx, user *UserID) error {
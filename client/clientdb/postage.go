@@ -0,0 +1,214 @@
+package clientdb
+
+import (
+	"errors"
+	"path/filepath"
+	"time"
+
+	"github.com/companyzero/bisonrelay/rpc"
+)
+
+// PostageSettings are the settings of the postage that remote users must pay
+// for their messages (PMs and GC messages) to be delivered to the local
+// client. Amounts are in milliatoms.
+type PostageSettings struct {
+	// Default is the postage of each message of users that do not have a
+	// specific postage. Zero means no postage is required.
+	Default uint64 `json:"default"`
+
+	// Users are the postages of specific users, keyed by user ID. A zero
+	// postage means the user is exempt from paying postage.
+	Users map[string]uint64 `json:"users,omitempty"`
+}
+
+// UserPostage returns the postage of each message of the given user.
+func (ps *PostageSettings) UserPostage(uid UserID) uint64 {
+	if postage, ok := ps.Users[uid.String()]; ok {
+		return postage
+	}
+	return ps.Default
+}
+
+// SetUserPostage sets the postage of the given user. A zero postage exempts
+// the user from paying postage.
+func (ps *PostageSettings) SetUserPostage(uid UserID, postage uint64) {
+	if ps.Users == nil {
+		ps.Users = make(map[string]uint64, 1)
+	}
+	ps.Users[uid.String()] = postage
+}
+
+// ClearUserPostage makes the given user use the default postage.
+func (ps *PostageSettings) ClearUserPostage(uid UserID) {
+	delete(ps.Users, uid.String())
+}
+
+// HeldMessage is a message from a remote user that is held until its postage
+// is paid. Only one of the messages is set.
+type HeldMessage struct {
+	Timestamp time.Time             `json:"timestamp"`
+	PM        *rpc.RMPrivateMessage `json:"pm,omitempty"`
+	GCM       *rpc.RMGroupMessage   `json:"gcm,omitempty"`
+}
+
+// PostageState is the postage state related to a remote user.
+type PostageState struct {
+	// Credit is the amount prepaid by the remote user for the postage of
+	// their messages that has not been used yet.
+	Credit uint64 `json:"credit"`
+
+	// Held are the messages of the remote user held until their postage
+	// is paid, in the order they were received.
+	Held []HeldMessage `json:"held,omitempty"`
+
+	// RemotePostage is the postage of each message sent to the remote
+	// user, as last requested by them.
+	RemotePostage uint64 `json:"remote_postage"`
+}
+
+// GetPostageSettings returns the postage settings of the local client.
+func (db *DB) GetPostageSettings(tx ReadTx) (PostageSettings, error) {
+	var settings PostageSettings
+	fname := filepath.Join(db.root, postageFile)
+	err := db.readJsonFile(fname, &settings)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return settings, err
+	}
+	return settings, nil
+}
+
+// SetPostageSettings replaces the postage settings of the local client.
+func (db *DB) SetPostageSettings(tx ReadWriteTx, settings PostageSettings) error {
+	fname := filepath.Join(db.root, postageFile)
+	return db.saveJsonFile(fname, settings)
+}
+
+// GetPostageState returns the postage state related to the given user.
+func (db *DB) GetPostageState(tx ReadTx, uid UserID) (PostageState, error) {
+	var state PostageState
+	fname := filepath.Join(db.root, inboundDir, uid.String(), postageStateFile)
+	err := db.readJsonFile(fname, &state)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return state, err
+	}
+	return state, nil
+}
+
+func (db *DB) savePostageState(uid UserID, state *PostageState) error {
+	fname := filepath.Join(db.root, inboundDir, uid.String(), postageStateFile)
+	return db.saveJsonFile(fname, state)
+}
+
+// ChargeOrHoldMessage charges the postage of a message received from the given
+// user from their credit. If the credit is not enough (or if there are
+// already held messages from the user), the message is held instead.
+//
+// It returns whether the message was held and the number of currently held
+// messages.
+func (db *DB) ChargeOrHoldMessage(tx ReadWriteTx, uid UserID, postage uint64,
+	msg HeldMessage) (bool, int, error) {
+
+	state, err := db.GetPostageState(tx, uid)
+	if err != nil {
+		return false, 0, err
+	}
+
+	// Messages are held while there are previously held messages to
+	// preserve their order.
+	if len(state.Held) == 0 && state.Credit >= postage {
+		state.Credit -= postage
+		return false, 0, db.savePostageState(uid, &state)
+	}
+
+	state.Held = append(state.Held, msg)
+	return true, len(state.Held), db.savePostageState(uid, &state)
+}
+
+// AddPostageCredit adds the given amount to the postage credit of the user.
+func (db *DB) AddPostageCredit(tx ReadWriteTx, uid UserID, amount uint64) error {
+	state, err := db.GetPostageState(tx, uid)
+	if err != nil {
+		return err
+	}
+	state.Credit += amount
+	return db.savePostageState(uid, &state)
+}
+
+// TakeHeldMessages removes and returns the held messages of the user whose
+// postage can be paid with their credit. If free is true, all held messages
+// are returned without charging postage.
+func (db *DB) TakeHeldMessages(tx ReadWriteTx, uid UserID, postage uint64,
+	free bool) ([]HeldMessage, error) {
+
+	state, err := db.GetPostageState(tx, uid)
+	if err != nil {
+		return nil, err
+	}
+	if len(state.Held) == 0 {
+		return nil, nil
+	}
+
+	n := len(state.Held)
+	if !free && postage > 0 {
+		if maxN := state.Credit / postage; uint64(n) > maxN {
+			n = int(maxN)
+		}
+		state.Credit -= uint64(n) * postage
+	}
+	res := state.Held[:n:n]
+	state.Held = state.Held[n:]
+	return res, db.savePostageState(uid, &state)
+}
+
+// DropHeldMessages removes the held messages of the user. It returns the
+// number of dropped messages.
+func (db *DB) DropHeldMessages(tx ReadWriteTx, uid UserID) (int, error) {
+	state, err := db.GetPostageState(tx, uid)
+	if err != nil {
+		return 0, err
+	}
+	n := len(state.Held)
+	if n == 0 {
+		return 0, nil
+	}
+	state.Held = nil
+	return n, db.savePostageState(uid, &state)
+}
+
+// SetRemotePostage records the postage requested by the remote user for the
+// messages sent to them.
+func (db *DB) SetRemotePostage(tx ReadWriteTx, uid UserID, postage uint64) error {
+	state, err := db.GetPostageState(tx, uid)
+	if err != nil {
+		return err
+	}
+	state.RemotePostage = postage
+	return db.savePostageState(uid, &state)
+}
+
+// ListHeldMessagesCount returns the number of held messages of each user that
+// has held messages.
+func (db *DB) ListHeldMessagesCount(tx ReadTx) (map[UserID]int, error) {
+	pattern := filepath.Join(db.root, inboundDir, "*", postageStateFile)
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[UserID]int)
+	for _, fname := range files {
+		var uid UserID
+		if err := uid.FromString(filepath.Base(filepath.Dir(fname))); err != nil {
+			db.log.Warnf("Not a valid user ID in postage file %s", fname)
+			continue
+		}
+		var state PostageState
+		if err := db.readJsonFile(fname, &state); err != nil {
+			return nil, err
+		}
+		if len(state.Held) > 0 {
+			res[uid] = len(state.Held)
+		}
+	}
+	return res, nil
+}
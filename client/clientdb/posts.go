@@ -231,6 +231,46 @@ func (db *DB) IsPostSubscriber(tx ReadTx, uid UserID) (bool, error) {
 func (db *DB) CreatePost(tx ReadWriteTx, post, descr string, fname string,
 	extraAttrs map[string]string, me *zkidentity.FullIdentity) (PostSummary, rpc.PostMetadata, error) {
 
+	return db.createPost(rpc.PostMetadataVersion, post, descr, fname,
+		extraAttrs, me)
+}
+
+// CreatePaywalledPost creates a post where only the summary is shared for
+// free, while the body is stored locally and released to readers once they
+// pay the specified price (in milliatoms).
+func (db *DB) CreatePaywalledPost(tx ReadWriteTx, summary, body, descr string,
+	priceMAtoms uint64, me *zkidentity.FullIdentity) (PostSummary, rpc.PostMetadata, error) {
+
+	if body == "" {
+		return PostSummary{}, rpc.PostMetadata{}, errors.New("paywalled body cannot be empty")
+	}
+	if priceMAtoms == 0 {
+		return PostSummary{}, rpc.PostMetadata{}, errors.New("paywalled post price cannot be zero")
+	}
+
+	extraAttrs := map[string]string{
+		rpc.RMPPrice:    strconv.FormatUint(priceMAtoms, 10),
+		rpc.RMPBodyHash: rpc.PostBodyHash(body),
+	}
+	summ, p, err := db.createPost(rpc.PostMetadataVersionPaywall, summary,
+		descr, "", extraAttrs, me)
+	if err != nil {
+		return summ, p, err
+	}
+
+	content := PaywalledPostContent{Body: body}
+	fname := filepath.Join(db.root, postsDir, me.Public.Identity.String(),
+		summ.ID.String()+postsPaywallExt)
+	if err := db.saveJsonFile(fname, &content); err != nil {
+		return summ, p, err
+	}
+	db.indexPost(me.Public.Identity, summ.ID)
+	return summ, p, nil
+}
+
+func (db *DB) createPost(version uint64, post, descr string, fname string,
+	extraAttrs map[string]string, me *zkidentity.FullIdentity) (PostSummary, rpc.PostMetadata, error) {
+
 	var pid PostID
 	var summ PostSummary
 	var p rpc.PostMetadata
@@ -274,7 +314,7 @@ func (db *DB) CreatePost(tx ReadWriteTx, post, descr string, fname string,
 
 	// Sign it.
 	p = rpc.PostMetadata{
-		Version:    rpc.PostMetadataVersion,
+		Version:    version,
 		Attributes: attrs,
 	}
 	pmHash := p.Hash()
@@ -303,6 +343,7 @@ func (db *DB) CreatePost(tx ReadWriteTx, post, descr string, fname string,
 
 	summ = PostSummFromMetadata(&p, me.Public.Identity)
 	summ.Date = finfo.ModTime()
+	db.indexPost(me.Public.Identity, pid)
 	return summ, p, nil
 }
 
@@ -442,6 +483,7 @@ func (db *DB) AddPostStatus(tx ReadWriteTx, postFrom, statusFrom UserID, pid Pos
 	if err != nil {
 		return err
 	}
+	db.indexPostStatus(postFrom, pid, pms)
 	return nil
 }
 
@@ -480,6 +522,7 @@ func (db *DB) SaveReceivedPost(tx ReadWriteTx, from UserID, p rpc.PostMetadata)
 
 	summ = PostSummFromMetadata(&p, from)
 	summ.Date = finfo.ModTime()
+	db.indexPost(from, pid)
 	return pid, summ, nil
 }
 
@@ -537,6 +580,7 @@ func (db *DB) AddPostStatusUpdate(tx ReadWriteTx, from UserID, p rpc.PostMetadat
 	if err != nil {
 		return fail(err)
 	}
+	db.indexPostStatus(from, pid, &update)
 
 	return statusFrom, update, nil
 }
@@ -587,8 +631,10 @@ func (db *DB) ListPosts(tx ReadTx) ([]PostSummary, error) {
 				continue
 			}
 
-			// Skip if it's the status update file.
-			if strings.HasSuffix(postFile.Name(), postsStatusExt) {
+			// Skip if it's the status update, paywall or recipients file.
+			if strings.HasSuffix(postFile.Name(), postsStatusExt) ||
+				strings.HasSuffix(postFile.Name(), postsPaywallExt) ||
+				strings.HasSuffix(postFile.Name(), postsRecipientsExt) {
 				continue
 			}
 
@@ -645,8 +691,10 @@ func (db *DB) ListUserPosts(tx ReadTx, from UserID) ([]rpc.PostMetadata, error)
 			continue
 		}
 
-		// Skip if it's the status update file.
-		if strings.HasSuffix(postFile.Name(), postsStatusExt) {
+		// Skip if it's the status update, paywall or recipients file.
+		if strings.HasSuffix(postFile.Name(), postsStatusExt) ||
+			strings.HasSuffix(postFile.Name(), postsPaywallExt) ||
+			strings.HasSuffix(postFile.Name(), postsRecipientsExt) {
 			continue
 		}
 
@@ -950,6 +998,7 @@ func (db *DB) RelayPost(tx ReadWriteTx, from UserID, pid PostID,
 		if err := copyFile(srcFilename, dstFilename); err != nil {
 			return rpc.PostMetadata{}, firstTime, err
 		}
+		db.indexPost(me.Public.Identity, pid)
 	}
 
 	pm, err := db.readPost(dstFilename)
@@ -961,3 +1010,176 @@ func (db *DB) RelayPost(tx ReadWriteTx, from UserID, pid PostID,
 	}
 	return *pm, firstTime, nil
 }
+
+// ReadPaywalledPostContent returns the content of the given paywalled post.
+// It returns ErrNotFound if the post is not paywalled or if it hasn't been
+// unlocked yet.
+func (db *DB) ReadPaywalledPostContent(tx ReadTx, from UserID, pid PostID) (PaywalledPostContent, error) {
+	var res PaywalledPostContent
+	fname := filepath.Join(db.root, postsDir, from.String(),
+		pid.String()+postsPaywallExt)
+	err := db.readJsonFile(fname, &res)
+	return res, err
+}
+
+// SavePaywalledPostBody saves the unlocked body of a paywalled post received
+// from the given user.
+func (db *DB) SavePaywalledPostBody(tx ReadWriteTx, from UserID, pid PostID, body string) error {
+	fname := filepath.Join(db.root, postsDir, from.String(),
+		pid.String()+postsPaywallExt)
+	content := PaywalledPostContent{Body: body}
+	if err := db.saveJsonFile(fname, &content); err != nil {
+		return err
+	}
+	db.indexPost(from, pid)
+	return nil
+}
+
+// AddPaywalledPostPendingSale records that an invoice was generated for the
+// given user to unlock a paywalled post authored by the local client.
+func (db *DB) AddPaywalledPostPendingSale(tx ReadWriteTx, pid PostID,
+	sale PaywalledPostSale, me *zkidentity.FullIdentity) error {
+
+	fname := filepath.Join(db.root, postsDir, me.Public.Identity.String(),
+		pid.String()+postsPaywallExt)
+	var content PaywalledPostContent
+	if err := db.readJsonFile(fname, &content); err != nil {
+		return err
+	}
+	content.PendingSales = append(content.PendingSales, sale)
+	return db.saveJsonFile(fname, &content)
+}
+
+// RemovePaywalledPostPendingSale removes the pending sale with the given
+// invoice from a paywalled post authored by the local client.
+func (db *DB) RemovePaywalledPostPendingSale(tx ReadWriteTx, pid PostID,
+	invoice string, me *zkidentity.FullIdentity) error {
+
+	fname := filepath.Join(db.root, postsDir, me.Public.Identity.String(),
+		pid.String()+postsPaywallExt)
+	var content PaywalledPostContent
+	if err := db.readJsonFile(fname, &content); err != nil {
+		return err
+	}
+	if !content.removePendingSale(invoice) {
+		return nil
+	}
+	return db.saveJsonFile(fname, &content)
+}
+
+// ListPaywalledPostPendingSales lists the pending sales of every paywalled
+// post authored by the local client.
+func (db *DB) ListPaywalledPostPendingSales(tx ReadTx,
+	me *zkidentity.FullIdentity) (map[PostID][]PaywalledPostSale, error) {
+
+	dir := filepath.Join(db.root, postsDir, me.Public.Identity.String())
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	res := make(map[PostID][]PaywalledPostSale)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, postsPaywallExt) {
+			continue
+		}
+		var pid PostID
+		if err := pid.FromString(strings.TrimSuffix(name, postsPaywallExt)); err != nil {
+			continue
+		}
+		var content PaywalledPostContent
+		if err := db.readJsonFile(filepath.Join(dir, name), &content); err != nil {
+			return nil, err
+		}
+		if len(content.PendingSales) > 0 {
+			res[pid] = content.PendingSales
+		}
+	}
+	return res, nil
+}
+
+// removePendingSale removes the pending sale with the given invoice. Returns
+// true if it was found.
+func (content *PaywalledPostContent) removePendingSale(invoice string) bool {
+	for i := range content.PendingSales {
+		if content.PendingSales[i].Invoice == invoice {
+			content.PendingSales = append(content.PendingSales[:i],
+				content.PendingSales[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// AddPaywalledPostBuyer records that the given user paid the specified
+// invoice to unlock a paywalled post authored by the local client. The
+// corresponding pending sale is removed. It returns the body of the post.
+func (db *DB) AddPaywalledPostBuyer(tx ReadWriteTx, pid PostID, buyer UserID,
+	invoice string, me *zkidentity.FullIdentity) (string, error) {
+
+	fname := filepath.Join(db.root, postsDir, me.Public.Identity.String(),
+		pid.String()+postsPaywallExt)
+	var content PaywalledPostContent
+	if err := db.readJsonFile(fname, &content); err != nil {
+		return "", err
+	}
+	removed := content.removePendingSale(invoice)
+	isBuyer := false
+	for _, uid := range content.Buyers {
+		if uid == buyer {
+			isBuyer = true
+			break
+		}
+	}
+	if isBuyer && !removed {
+		return content.Body, nil
+	}
+	if !isBuyer {
+		content.Buyers = append(content.Buyers, buyer)
+	}
+	if err := db.saveJsonFile(fname, &content); err != nil {
+		return "", err
+	}
+	return content.Body, nil
+}
+
+// AddPostRecipients records that the given users were sent the specified post
+// (which must be stored in the dir of the given author).
+func (db *DB) AddPostRecipients(tx ReadWriteTx, from UserID, pid PostID, uids ...UserID) error {
+	fname := filepath.Join(db.root, postsDir, from.String(),
+		pid.String()+postsRecipientsExt)
+	var recipients []UserID
+	if err := db.readJsonFile(fname, &recipients); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	changed := !fileExists(fname)
+nextUser:
+	for _, uid := range uids {
+		for i := range recipients {
+			if recipients[i] == uid {
+				continue nextUser
+			}
+		}
+		recipients = append(recipients, uid)
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return db.saveJsonFile(fname, recipients)
+}
+
+// ListPostRecipients lists the users that were sent the specified post. It
+// returns ErrNotFound if the list of recipients of the post was never recorded
+// (for example, for posts created before recipients were tracked).
+func (db *DB) ListPostRecipients(tx ReadTx, from UserID, pid PostID) ([]UserID, error) {
+	fname := filepath.Join(db.root, postsDir, from.String(),
+		pid.String()+postsRecipientsExt)
+	var recipients []UserID
+	if err := db.readJsonFile(fname, &recipients); err != nil {
+		return nil, err
+	}
+	return recipients, nil
+}
@@ -0,0 +1,238 @@
+package clientdb
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/companyzero/bisonrelay/rpc"
+)
+
+// postIndexKey identifies a post within the posts index. The same post may be
+// stored multiple times (once for each relayer), so the key includes the
+// user the post was received from.
+type postIndexKey struct {
+	from UserID
+	pid  PostID
+}
+
+// postIndexEntry is an entry of the posts index.
+type postIndexEntry struct {
+	summ          PostSummary
+	hasAttachment bool
+	terms         map[string]struct{}
+}
+
+// postsIndex is an in-memory full-text index of the local posts and their
+// comments. It is lazily built on the first search and afterwards kept up to
+// date as posts and status updates are written to the db.
+type postsIndex struct {
+	entries map[postIndexKey]*postIndexEntry
+}
+
+// searchTerms splits the given text into lowercase searchable terms.
+func searchTerms(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+func (e *postIndexEntry) addTerms(s string) {
+	for _, t := range searchTerms(s) {
+		e.terms[t] = struct{}{}
+	}
+}
+
+// matches returns true if every one of the query terms is a prefix of some
+// term of the entry.
+func (e *postIndexEntry) matches(query []string) bool {
+nextQueryTerm:
+	for _, q := range query {
+		if _, ok := e.terms[q]; ok {
+			continue
+		}
+		for t := range e.terms {
+			if strings.HasPrefix(t, q) {
+				continue nextQueryTerm
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func (e *postIndexEntry) addStatus(pms *rpc.PostMetadataStatus) {
+	e.addTerms(pms.Attributes[rpc.RMPSComment])
+	e.addTerms(pms.Attributes[rpc.RMPFromNick])
+}
+
+// newPostIndexEntry creates a new index entry for the given post.
+func newPostIndexEntry(summ PostSummary, post *rpc.PostMetadata) *postIndexEntry {
+	e := &postIndexEntry{
+		summ:          summ,
+		hasAttachment: post.Attributes[rpc.RMPAttachment] != "",
+		terms:         make(map[string]struct{}),
+	}
+	e.addTerms(post.Attributes[rpc.RMPTitle])
+	e.addTerms(post.Attributes[rpc.RMPMain])
+	e.addTerms(post.Attributes[rpc.RMPDescription])
+	e.addTerms(post.Attributes[rpc.RMPFromNick])
+	return e
+}
+
+// loadPostIndexEntry reads the given post (and its status updates) from disk
+// and creates the corresponding index entry.
+func (db *DB) loadPostIndexEntry(from UserID, pid PostID) (*postIndexEntry, error) {
+	fname := filepath.Join(db.root, postsDir, from.String(), pid.String())
+	finfo, err := os.Stat(fname)
+	if err != nil {
+		return nil, err
+	}
+	post, err := db.readPost(fname)
+	if err != nil {
+		return nil, err
+	}
+
+	summ := PostSummFromMetadata(post, from)
+	summ.Date = finfo.ModTime()
+	if finfo, err := os.Stat(fname + postsStatusExt); err == nil {
+		summ.LastStatusTS = finfo.ModTime()
+	}
+	e := newPostIndexEntry(summ, post)
+
+	updates, err := db.ListPostStatusUpdates(nil, from, pid)
+	if err != nil {
+		return nil, err
+	}
+	for i := range updates {
+		e.addStatus(&updates[i])
+	}
+
+	// Index the paywalled body if it has been unlocked.
+	var content PaywalledPostContent
+	if err := db.readJsonFile(fname+postsPaywallExt, &content); err == nil {
+		e.addTerms(content.Body)
+	}
+	return e, nil
+}
+
+// buildPostsIndex builds the posts index from the posts stored on disk.
+func (db *DB) buildPostsIndex() error {
+	idx := &postsIndex{entries: make(map[postIndexKey]*postIndexEntry)}
+
+	rootDir := filepath.Join(db.root, postsDir)
+	authorDirs, err := os.ReadDir(rootDir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, dir := range authorDirs {
+		if !dir.IsDir() {
+			continue
+		}
+		var from UserID
+		if err := from.FromString(dir.Name()); err != nil {
+			continue
+		}
+
+		fullDir := filepath.Join(rootDir, dir.Name())
+		postFiles, err := os.ReadDir(fullDir)
+		if err != nil {
+			return err
+		}
+		for _, postFile := range postFiles {
+			if postFile.IsDir() ||
+				strings.HasSuffix(postFile.Name(), postsStatusExt) ||
+				strings.HasSuffix(postFile.Name(), postsPaywallExt) ||
+				strings.HasSuffix(postFile.Name(), postsRecipientsExt) {
+				continue
+			}
+			var pid PostID
+			if err := pid.FromString(postFile.Name()); err != nil {
+				continue
+			}
+
+			e, err := db.loadPostIndexEntry(from, pid)
+			if err != nil {
+				db.log.Warnf("Unable to index post %s/%s: %v",
+					from, pid, err)
+				continue
+			}
+			idx.entries[postIndexKey{from: from, pid: pid}] = e
+		}
+	}
+
+	db.postsIdx = idx
+	return nil
+}
+
+// indexPost (re-)indexes the given post. This is a no-op if the index has not
+// been built yet.
+func (db *DB) indexPost(from UserID, pid PostID) {
+	if db.postsIdx == nil {
+		return
+	}
+	e, err := db.loadPostIndexEntry(from, pid)
+	if err != nil {
+		db.log.Warnf("Unable to index post %s/%s: %v", from, pid, err)
+		return
+	}
+	db.postsIdx.entries[postIndexKey{from: from, pid: pid}] = e
+}
+
+// indexPostStatus adds the given status update to the index of the post. This
+// is a no-op if the index has not been built yet.
+func (db *DB) indexPostStatus(from UserID, pid PostID, pms *rpc.PostMetadataStatus) {
+	if db.postsIdx == nil {
+		return
+	}
+	e, ok := db.postsIdx.entries[postIndexKey{from: from, pid: pid}]
+	if !ok {
+		db.indexPost(from, pid)
+		return
+	}
+	e.addStatus(pms)
+	e.summ.LastStatusTS = time.Now()
+}
+
+// SearchPosts searches the local posts (including their comments) for the
+// given query and returns the summary of the matching posts, sorted by date.
+//
+// Every term of the query must match (as a prefix) a word of the title, body,
+// description, author nick or comments of a post. An empty query matches all
+// posts, so that only the filters are applied.
+func (db *DB) SearchPosts(tx ReadTx, query string, filters PostSearchFilters) ([]PostSummary, error) {
+	if db.postsIdx == nil {
+		if err := db.buildPostsIndex(); err != nil {
+			return nil, err
+		}
+	}
+
+	terms := searchTerms(query)
+	var res []PostSummary
+	for _, e := range db.postsIdx.entries {
+		if filters.Author != nil && e.summ.AuthorID != *filters.Author {
+			continue
+		}
+		if !filters.Since.IsZero() && e.summ.Date.Before(filters.Since) {
+			continue
+		}
+		if !filters.Until.IsZero() && e.summ.Date.After(filters.Until) {
+			continue
+		}
+		if filters.HasAttachment && !e.hasAttachment {
+			continue
+		}
+		if !e.matches(terms) {
+			continue
+		}
+		res = append(res, e.summ)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Date.Before(res[j].Date)
+	})
+	return res, nil
+}
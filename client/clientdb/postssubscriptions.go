@@ -0,0 +1,136 @@
+package clientdb
+
+import (
+	"errors"
+	"path/filepath"
+	"time"
+
+	"github.com/companyzero/bisonrelay/rpc"
+)
+
+// PostsSubscriptionSettings are the settings of paid subscriptions to posts.
+// Amounts are in milliatoms.
+type PostsSubscriptionSettings struct {
+	// Price is the price remote users must pay to subscribe to the posts of
+	// the local client for Period. Zero means subscriptions are free.
+	Price  uint64        `json:"price"`
+	Period time.Duration `json:"period"`
+
+	// AutoRenewMaxPrice is the maximum price (per 30 days) that is
+	// automatically paid when subscribing to or renewing the subscription
+	// to posts of remote users. Zero means subscriptions are never paid
+	// automatically.
+	AutoRenewMaxPrice uint64 `json:"auto_renew_max_price"`
+}
+
+// SubscriptionPrice returns the price to subscribe to the local client's
+// posts or nil if subscriptions are free.
+func (s *PostsSubscriptionSettings) SubscriptionPrice() *rpc.PostsSubscriptionPrice {
+	if s.Price == 0 || s.Period <= 0 {
+		return nil
+	}
+	return &rpc.PostsSubscriptionPrice{
+		MilliAtoms: s.Price,
+		PeriodSecs: uint64(s.Period / time.Second),
+	}
+}
+
+// GetPostsSubscriptionSettings returns the settings of paid subscriptions to
+// posts.
+func (db *DB) GetPostsSubscriptionSettings(tx ReadTx) (PostsSubscriptionSettings, error) {
+	var settings PostsSubscriptionSettings
+	fname := filepath.Join(db.root, postsDir, postsSubSettings)
+	err := db.readJsonFile(fname, &settings)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return settings, err
+	}
+	return settings, nil
+}
+
+// SetPostsSubscriptionSettings replaces the settings of paid subscriptions to
+// posts.
+func (db *DB) SetPostsSubscriptionSettings(tx ReadWriteTx, settings PostsSubscriptionSettings) error {
+	if settings.Price > 0 && settings.Period < time.Second {
+		return errors.New("subscription period must be at least one second")
+	}
+	fname := filepath.Join(db.root, postsDir, postsSubSettings)
+	return db.saveJsonFile(fname, settings)
+}
+
+// readPaidPostsSubscribers reads the paid-through dates of the subscribers to
+// the local client's posts, keyed by user ID string.
+func (db *DB) readPaidPostsSubscribers() (map[string]time.Time, error) {
+	var paid map[string]time.Time
+	fname := filepath.Join(db.root, postsDir, postsPaidSubs)
+	err := db.readJsonFile(fname, &paid)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	if paid == nil {
+		paid = make(map[string]time.Time)
+	}
+	return paid, nil
+}
+
+// ListPaidPostsSubscribers returns the date until which each remote user has
+// paid for their subscription to the local client's posts.
+func (db *DB) ListPaidPostsSubscribers(tx ReadTx) (map[UserID]time.Time, error) {
+	paid, err := db.readPaidPostsSubscribers()
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[UserID]time.Time, len(paid))
+	for s, until := range paid {
+		var uid UserID
+		if err := uid.FromString(s); err != nil {
+			db.log.Warnf("Not a valid user ID in paid subscribers: %q", s)
+			continue
+		}
+		res[uid] = until
+	}
+	return res, nil
+}
+
+// ExtendPostsSubscriberPaidUntil extends the date until which the given user
+// has paid for their subscription to the local client's posts by the given
+// duration. Lapsed subscriptions are extended starting from the current time.
+//
+// It returns the new paid-through date.
+func (db *DB) ExtendPostsSubscriberPaidUntil(tx ReadWriteTx, uid UserID,
+	d time.Duration) (time.Time, error) {
+
+	paid, err := db.readPaidPostsSubscribers()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	until := paid[uid.String()]
+	if now := time.Now(); until.Before(now) {
+		until = now
+	}
+	until = until.Add(d)
+	paid[uid.String()] = until
+
+	fname := filepath.Join(db.root, postsDir, postsPaidSubs)
+	return until, db.saveJsonFile(fname, paid)
+}
+
+// GetRemotePostsSubscriptionPrice returns the price last requested by the
+// given remote user to subscribe to their posts. It returns an error wrapping
+// ErrNotFound if the user never requested payment for subscribing to their
+// posts.
+func (db *DB) GetRemotePostsSubscriptionPrice(tx ReadTx, uid UserID) (rpc.PostsSubscriptionPrice, error) {
+	var price rpc.PostsSubscriptionPrice
+	fname := filepath.Join(db.root, inboundDir, uid.String(), remoteSubPriceFile)
+	err := db.readJsonFile(fname, &price)
+	return price, err
+}
+
+// SetRemotePostsSubscriptionPrice records the price requested by the given
+// remote user to subscribe to their posts.
+func (db *DB) SetRemotePostsSubscriptionPrice(tx ReadWriteTx, uid UserID,
+	price rpc.PostsSubscriptionPrice) error {
+
+	fname := filepath.Join(db.root, inboundDir, uid.String(), remoteSubPriceFile)
+	return db.saveJsonFile(fname, price)
+}
@@ -0,0 +1,74 @@
+package clientdb
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/companyzero/bisonrelay/rpc"
+	"github.com/companyzero/bisonrelay/zkidentity"
+)
+
+// ServerCreditState is the state of the credit prepaid to the server.
+type ServerCreditState struct {
+	// Account is the ID of the credit account in the server.
+	Account zkidentity.ShortID `json:"account"`
+
+	// Purchases are the invoices (and the time the attempt was made) of
+	// attempts to buy credit that were not redeemed yet.
+	Purchases map[string]time.Time `json:"purchases,omitempty"`
+}
+
+// GetServerCreditState returns the state of the credit prepaid to the server.
+// It returns ErrNotFound if no credit account was created yet.
+func (db *DB) GetServerCreditState(tx ReadTx) (ServerCreditState, error) {
+	var state ServerCreditState
+	fname := filepath.Join(db.root, serverCreditFile)
+	err := db.readJsonFile(fname, &state)
+	return state, err
+}
+
+// SetServerCreditState replaces the state of the credit prepaid to the
+// server.
+func (db *DB) SetServerCreditState(tx ReadWriteTx, state ServerCreditState) error {
+	fname := filepath.Join(db.root, serverCreditFile)
+	return db.saveJsonFile(fname, state)
+}
+
+// RecordCreditReceipt records a receipt issued by the server for a change in
+// the credit balance.
+func (db *DB) RecordCreditReceipt(tx ReadWriteTx, receipt rpc.CreditReceipt) error {
+	fname := filepath.Join(db.root, creditReceiptsFile)
+	return db.appendToJsonFile(fname, receipt)
+}
+
+// ListCreditReceipts lists the receipts issued by the server for changes in
+// the credit balance, in the order they were recorded.
+func (db *DB) ListCreditReceipts(tx ReadTx) ([]rpc.CreditReceipt, error) {
+	fname := filepath.Join(db.root, creditReceiptsFile)
+	f, err := os.Open(fname)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var res []rpc.CreditReceipt
+	dec := json.NewDecoder(f)
+	for {
+		var receipt rpc.CreditReceipt
+		err := dec.Decode(&receipt)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, receipt)
+	}
+	return res, nil
+}
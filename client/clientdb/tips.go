@@ -0,0 +1,122 @@
+package clientdb
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// TipHistoryEntry is a tip sent to or received from a remote user.
+type TipHistoryEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+
+	// MilliAtoms is the amount of the tip. It is negative for tips sent
+	// to the remote user and positive for tips received from them.
+	MilliAtoms int64 `json:"milliatoms"`
+
+	// Fees is the (negative) amount of fees paid to send the tip.
+	Fees int64 `json:"fees,omitempty"`
+
+	Memo string `json:"memo,omitempty"`
+
+	// Post is the optional ID of the post that was tipped. Sent tips refer
+	// to posts of the remote user, while received tips refer to posts of
+	// the local client.
+	Post *PostID `json:"post,omitempty"`
+}
+
+// TipTotals are the totals of the tips exchanged with a remote user. Amounts
+// are in milliatoms.
+type TipTotals struct {
+	Sent       int64 `json:"sent"`
+	NbSent     int   `json:"nb_sent"`
+	Received   int64 `json:"received"`
+	NbReceived int   `json:"nb_received"`
+}
+
+// RecordTip records the given tip in the tip history of the user. The tip is
+// also recorded as a payment event.
+func (db *DB) RecordTip(tx ReadWriteTx, uid UserID, tip TipHistoryEntry) error {
+	if tip.Timestamp.IsZero() {
+		tip.Timestamp = time.Now()
+	}
+	fname := filepath.Join(db.root, inboundDir, uid.String(), tipHistoryFile)
+	if err := db.appendToJsonFile(fname, tip); err != nil {
+		return err
+	}
+
+	event := "tip"
+	if tip.MilliAtoms < 0 {
+		event = "paytip"
+	}
+	if tip.Post != nil {
+		event += ".post"
+	}
+	return db.RecordUserPayEvent(tx, uid, event, tip.MilliAtoms, tip.Fees)
+}
+
+// ListTipHistory lists the tips exchanged with the given user, in the order
+// they were recorded.
+func (db *DB) ListTipHistory(tx ReadTx, uid UserID) ([]TipHistoryEntry, error) {
+	fname := filepath.Join(db.root, inboundDir, uid.String(), tipHistoryFile)
+	f, err := os.Open(fname)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var res []TipHistoryEntry
+	dec := json.NewDecoder(f)
+	for {
+		var tip TipHistoryEntry
+		err := dec.Decode(&tip)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, tip)
+	}
+	return res, nil
+}
+
+// ListTipTotals returns the totals of the tips exchanged with each remote
+// user that has a tip history.
+func (db *DB) ListTipTotals(tx ReadTx) (map[UserID]TipTotals, error) {
+	pattern := filepath.Join(db.root, inboundDir, "*", tipHistoryFile)
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[UserID]TipTotals, len(files))
+	for _, fname := range files {
+		var uid UserID
+		if err := uid.FromString(filepath.Base(filepath.Dir(fname))); err != nil {
+			db.log.Warnf("Not a valid user ID in tip history file %s", fname)
+			continue
+		}
+		tips, err := db.ListTipHistory(tx, uid)
+		if err != nil {
+			return nil, err
+		}
+		var totals TipTotals
+		for _, tip := range tips {
+			if tip.MilliAtoms < 0 {
+				totals.Sent += -tip.MilliAtoms
+				totals.NbSent++
+			} else {
+				totals.Received += tip.MilliAtoms
+				totals.NbReceived++
+			}
+		}
+		res[uid] = totals
+	}
+	return res, nil
+}
@@ -53,6 +53,14 @@ type CertConfirmer func(context.Context, *tls.ConnectionState, *zkidentity.Publi
 type ServerPolicy struct {
 	PushPaymentLifetime time.Duration
 	MaxPushInvoices     int
+
+	// MinPushPayment is the minimum amount (in milliatoms) to pay to push
+	// an RM.
+	MinPushPayment uint64
+
+	// CreditEnabled is true if pushes and subscriptions may be paid from
+	// prepaid credit.
+	CreditEnabled bool
 }
 
 // ServerSessionIntf is the interface available from serverSession to
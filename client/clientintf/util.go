@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/companyzero/bisonrelay/ratchet"
@@ -50,25 +51,58 @@ func PostTitle(pm *rpc.PostMetadata) string {
 	return strings.TrimSpace(subs[1])
 }
 
+// PaywalledPostPrice returns the price (in milliatoms) to unlock the body of
+// a paywalled post. It returns zero if the post is not paywalled.
+func PaywalledPostPrice(pm *rpc.PostMetadata) uint64 {
+	if pm.Version < rpc.PostMetadataVersionPaywall {
+		return 0
+	}
+	price, err := strconv.ParseUint(pm.Attributes[rpc.RMPPrice], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return price
+}
+
 // ChunkIndexMatches returns true if the hash of the manifest file at the
 // specified index matches the given hash.
+//
+// Metadata with a Merkle root do not list the chunk hashes, so for those only
+// the index is checked. The hash of their chunks is verified with
+// ChunkProofMatches once the chunk data is received.
 func ChunkIndexMatches(fm *rpc.FileMetadata, index int, hash []byte) bool {
 	if fm == nil {
 		return false
 	}
-	if len(fm.Manifest) <= index {
+	if index < 0 || fm.ChunkCount() <= index {
 		return false
 	}
+	if fm.IsMerkle() {
+		return true
+	}
 	return bytes.Equal(fm.Manifest[index].Hash, hash)
 }
 
+// ChunkProofMatches returns true if the chunk with the given hash is the one
+// at the specified index of the file. The proof is only used for metadata
+// with a Merkle root.
+func ChunkProofMatches(fm *rpc.FileMetadata, index int, hash []byte, proof [][]byte) bool {
+	if fm == nil {
+		return false
+	}
+	if !fm.IsMerkle() {
+		return ChunkIndexMatches(fm, index, hash)
+	}
+	return rpc.VerifyMerkleProof(fm.MerkleRoot, hash, index, fm.ChunkCount(), proof)
+}
+
 // FileChunkMAtoms returns the cost to download the specified chunk from the
 // file.
 func FileChunkMAtoms(chunkIdx int, fm *rpc.FileMetadata) uint64 {
-	if chunkIdx >= len(fm.Manifest) {
+	if chunkIdx >= fm.ChunkCount() {
 		return 0
 	}
-	chunkSize := fm.Manifest[chunkIdx].Size
+	chunkSize := fm.ChunkLen(chunkIdx)
 	if chunkSize < 0 {
 		return 0
 	}
@@ -87,7 +121,7 @@ func FileChunkMAtoms(chunkIdx int, fm *rpc.FileMetadata) uint64 {
 var dummyIDEstimates = zkidentity.MustNew("", "")
 
 // Returns the estimate cost (in milliatoms) to upload a file of the given size
-// to a remote user. The feeRate must be specified in milliatoms/byte.
+// to a remote user. The feeRate must be specified in milliatoms/KB.
 func EstimateUploadCost(size int64, feeRate uint64) (uint64, error) {
 	if size <= 0 {
 		return 0, fmt.Errorf("size cannot be <= 0")
@@ -208,7 +242,7 @@ func EstimateUploadCost(size int64, feeRate uint64) (uint64, error) {
 
 	// Cost to upload the file will be the total nb of bytes used to send
 	// the messages related to it times the fee rate.
-	cost := totalSize * feeRate
+	cost := rpc.PushPaymentAmount(totalSize, feeRate, 0)
 	return cost, nil
 }
 
@@ -15,6 +15,10 @@ var (
 	errRMTooLarge        = errors.New("RM is too large")
 )
 
+// ErrPaymentNotApproved is returned when an outbound payment is above the
+// approval threshold and was not approved.
+var ErrPaymentNotApproved = errors.New("payment not approved")
+
 type userNotFoundError struct {
 	id string
 }
@@ -0,0 +1,119 @@
+package feedexport
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/companyzero/bisonrelay/rpc"
+)
+
+const atomNS = "http://www.w3.org/2005/Atom"
+
+type atomText struct {
+	Type string `xml:"type,attr,omitempty"`
+	Body string `xml:",chardata"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+type atomEntry struct {
+	ID        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Updated   string     `xml:"updated"`
+	Published string     `xml:"published"`
+	Author    atomPerson `xml:"author"`
+	Content   atomText   `xml:"content"`
+}
+
+type atomFeed struct {
+	XMLName   xml.Name    `xml:"feed"`
+	NS        string      `xml:"xmlns,attr"`
+	ID        string      `xml:"id"`
+	Title     string      `xml:"title"`
+	Updated   string      `xml:"updated"`
+	Generator string      `xml:"generator"`
+	Entries   []atomEntry `xml:"entry"`
+}
+
+func atomTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// postURN returns an URN that uniquely identifies the given post.
+func postURN(p *Post) string {
+	return fmt.Sprintf("urn:bisonrelay:post:%s:%s", p.Summary.AuthorID, p.Summary.ID)
+}
+
+// atomEntryContent returns the HTML content of an entry, which includes the
+// post and its comments.
+func atomEntryContent(p *Post) string {
+	var b strings.Builder
+	b.WriteString(string(contentHTML(p.Metadata.Attributes[rpc.RMPMain])))
+
+	var writeComments func(cmts []*Comment)
+	writeComments = func(cmts []*Comment) {
+		b.WriteString("<ul>")
+		for _, cmt := range cmts {
+			b.WriteString("<li><b>")
+			b.WriteString(template.HTMLEscapeString(cmt.From))
+			b.WriteString("</b>: ")
+			b.WriteString(string(contentHTML(cmt.Text)))
+			if len(cmt.Children) > 0 {
+				writeComments(cmt.Children)
+			}
+			b.WriteString("</li>")
+		}
+		b.WriteString("</ul>")
+	}
+	if cmts := p.comments(); len(cmts) > 0 {
+		b.WriteString("<hr><h4>Comments</h4>")
+		writeComments(cmts)
+	}
+	return b.String()
+}
+
+// WriteAtom writes the feed as an Atom (RFC 4287) document.
+func WriteAtom(w io.Writer, feed *Feed) error {
+	af := atomFeed{
+		NS:        atomNS,
+		ID:        feed.ID,
+		Title:     feed.Title,
+		Updated:   atomTime(feed.updated()),
+		Generator: "Bison Relay",
+	}
+	for _, p := range feed.sortedPosts() {
+		p := p
+		af.Entries = append(af.Entries, atomEntry{
+			ID:        postURN(&p),
+			Title:     p.title(),
+			Updated:   atomTime(p.Summary.Date),
+			Published: atomTime(p.Summary.Date),
+			Author: atomPerson{
+				Name: p.Summary.AuthorNick,
+				URI:  "urn:bisonrelay:user:" + p.Summary.AuthorID.String(),
+			},
+			Content: atomText{
+				Type: "html",
+				Body: atomEntryContent(&p),
+			},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(af); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
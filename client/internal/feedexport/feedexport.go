@@ -0,0 +1,205 @@
+// Package feedexport converts posts (and their comments) into formats that can
+// be read outside of the client, such as Atom feeds and static HTML sites.
+package feedexport
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/companyzero/bisonrelay/client/clientdb"
+	"github.com/companyzero/bisonrelay/client/clientintf"
+	"github.com/companyzero/bisonrelay/rpc"
+)
+
+// Post is a post to export, along with its status updates.
+type Post struct {
+	Summary  clientdb.PostSummary
+	Metadata rpc.PostMetadata
+	Status   []rpc.PostMetadataStatus
+}
+
+// Feed is a collection of posts to export.
+type Feed struct {
+	// Title is the title of the exported feed.
+	Title string
+
+	// ID is an unique identifier for the feed.
+	ID string
+
+	// Posts are the posts of the feed. They are exported from newest to
+	// oldest.
+	Posts []Post
+}
+
+// Comment is a comment on a post, as extracted from its status updates.
+type Comment struct {
+	ID       clientintf.ID
+	From     string
+	Text     string
+	Date     time.Time
+	Children []*Comment
+}
+
+// updated returns the time of the most recent post in the feed.
+func (f *Feed) updated() time.Time {
+	var res time.Time
+	for i := range f.Posts {
+		if d := f.Posts[i].Summary.Date; d.After(res) {
+			res = d
+		}
+	}
+	return res
+}
+
+// title returns the title to use for the post.
+func (p *Post) title() string {
+	if p.Summary.Title != "" {
+		return p.Summary.Title
+	}
+	return p.Summary.ID.ShortLogID()
+}
+
+// comments returns the tree of comments of the post.
+func (p *Post) comments() []*Comment {
+	var roots []*Comment
+	byID := make(map[clientintf.ID]*Comment)
+	for i := range p.Status {
+		status := &p.Status[i]
+		text, ok := status.Attributes[rpc.RMPSComment]
+		if !ok {
+			continue
+		}
+
+		from := status.Attributes[rpc.RMPFromNick]
+		if from == "" {
+			from = status.From
+		}
+		cmt := &Comment{
+			ID:   status.Hash(),
+			From: from,
+			Text: text,
+		}
+		if ts, err := strconv.ParseInt(status.Attributes[rpc.RMPTimestamp], 16, 64); err == nil {
+			cmt.Date = time.Unix(ts, 0)
+		}
+		byID[cmt.ID] = cmt
+
+		var parentID clientintf.ID
+		if err := parentID.FromString(status.Attributes[rpc.RMPParent]); err == nil {
+			if parent, ok := byID[parentID]; ok {
+				parent.Children = append(parent.Children, cmt)
+				continue
+			}
+		}
+		roots = append(roots, cmt)
+	}
+	return roots
+}
+
+// embedRegexp matches the embedded content tags of posts.
+var embedRegexp = regexp.MustCompile(`--embed\[.*?\]--`)
+
+// embed is the subset of the arguments of an embedded content tag that are
+// relevant when exporting posts.
+type embed struct {
+	typ      string
+	alt      string
+	filename string
+	data     []byte
+	download string
+}
+
+func parseEmbed(s string) embed {
+	var res embed
+	start, end := strings.Index(s, "["), strings.LastIndex(s, "]")
+	for _, arg := range strings.Split(s[start+1:end], ",") {
+		kv := strings.SplitN(arg, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "type":
+			res.typ = kv[1]
+		case "alt":
+			res.alt, _ = url.PathUnescape(kv[1])
+		case "filename":
+			res.filename = kv[1]
+		case "download":
+			res.download = kv[1]
+		case "data":
+			res.data, _ = base64.StdEncoding.DecodeString(kv[1])
+		}
+	}
+	return res
+}
+
+// describe returns a textual description of the embedded content.
+func (e *embed) describe() string {
+	var s string
+	switch {
+	case e.download != "" && e.filename != "":
+		s = fmt.Sprintf("[File %s]", e.filename)
+	case e.download != "":
+		s = fmt.Sprintf("[File %s]", e.download)
+	case e.typ != "":
+		s = fmt.Sprintf("[Embedded %s]", e.typ)
+	default:
+		s = "[Embedded data]"
+	}
+	if e.alt != "" {
+		s = e.alt + " " + s
+	}
+	return s
+}
+
+// contentHTML converts the content of a post or comment to HTML. Embedded
+// images are inlined, while other embedded content is replaced by a
+// description of it.
+func contentHTML(content string) template.HTML {
+	var b strings.Builder
+	writeText := func(s string) {
+		for i, line := range strings.Split(s, "\n") {
+			if i > 0 {
+				b.WriteString("<br>\n")
+			}
+			b.WriteString(template.HTMLEscapeString(line))
+		}
+	}
+
+	last := 0
+	for _, loc := range embedRegexp.FindAllStringIndex(content, -1) {
+		writeText(content[last:loc[0]])
+		last = loc[1]
+
+		e := parseEmbed(content[loc[0]:loc[1]])
+		if strings.HasPrefix(e.typ, "image/") && len(e.data) > 0 {
+			fmt.Fprintf(&b, `<img src="data:%s;base64,%s" alt="%s">`,
+				template.HTMLEscapeString(e.typ),
+				base64.StdEncoding.EncodeToString(e.data),
+				template.HTMLEscapeString(e.alt))
+			continue
+		}
+		fmt.Fprintf(&b, `<span class="embed">%s</span>`,
+			template.HTMLEscapeString(e.describe()))
+	}
+	writeText(content[last:])
+
+	return template.HTML(b.String())
+}
+
+// sortedPosts returns the posts of the feed sorted from newest to oldest.
+func (f *Feed) sortedPosts() []Post {
+	posts := make([]Post, len(f.Posts))
+	copy(posts, f.Posts)
+	sort.SliceStable(posts, func(i, j int) bool {
+		return posts[i].Summary.Date.After(posts[j].Summary.Date)
+	})
+	return posts
+}
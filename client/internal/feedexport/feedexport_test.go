@@ -0,0 +1,123 @@
+package feedexport
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/companyzero/bisonrelay/client/clientdb"
+	"github.com/companyzero/bisonrelay/client/clientintf"
+	"github.com/companyzero/bisonrelay/internal/assert"
+	"github.com/companyzero/bisonrelay/rpc"
+)
+
+// testFeed returns a feed with two posts, where the newest one has a comment
+// and a reply to it.
+func testFeed() *Feed {
+	comment := rpc.PostMetadataStatus{
+		From: "alice",
+		Attributes: map[string]string{
+			rpc.RMPSComment: "first <comment>",
+			rpc.RMPFromNick: "alice",
+		},
+	}
+	reply := rpc.PostMetadataStatus{
+		From: "bob",
+		Attributes: map[string]string{
+			rpc.RMPSComment: "a reply",
+			rpc.RMPFromNick: "bob",
+			rpc.RMPParent:   clientintf.ID(comment.Hash()).String(),
+		},
+	}
+
+	now := time.Now()
+	return &Feed{
+		Title: "Test Feed",
+		ID:    "urn:test",
+		Posts: []Post{{
+			Summary: clientdb.PostSummary{
+				ID:         clientintf.PostID{0: 1},
+				AuthorNick: "alice",
+				Date:       now.Add(-time.Hour),
+				Title:      "old post",
+			},
+			Metadata: rpc.PostMetadata{Attributes: map[string]string{
+				rpc.RMPMain: "old post",
+			}},
+		}, {
+			Summary: clientdb.PostSummary{
+				ID:         clientintf.PostID{0: 2},
+				AuthorNick: "alice",
+				Date:       now,
+				Title:      "new post",
+			},
+			Metadata: rpc.PostMetadata{Attributes: map[string]string{
+				rpc.RMPMain: "new post\n--embed[type=image/png,data=AAEC]--",
+			}},
+			Status: []rpc.PostMetadataStatus{comment, reply},
+		}},
+	}
+}
+
+// TestComments asserts that comments are correctly arranged in a tree.
+func TestComments(t *testing.T) {
+	feed := testFeed()
+	cmts := feed.Posts[1].comments()
+	assert.DeepEqual(t, len(cmts), 1)
+	assert.DeepEqual(t, cmts[0].Text, "first <comment>")
+	assert.DeepEqual(t, len(cmts[0].Children), 1)
+	assert.DeepEqual(t, cmts[0].Children[0].From, "bob")
+}
+
+// TestWriteAtom asserts the generated Atom feed is valid XML with the expected
+// entries.
+func TestWriteAtom(t *testing.T) {
+	var b bytes.Buffer
+	assert.NilErr(t, WriteAtom(&b, testFeed()))
+
+	var af atomFeed
+	assert.NilErr(t, xml.Unmarshal(b.Bytes(), &af))
+	assert.DeepEqual(t, af.Title, "Test Feed")
+	assert.DeepEqual(t, len(af.Entries), 2)
+
+	// Newest post first.
+	entry := af.Entries[0]
+	assert.DeepEqual(t, entry.Title, "new post")
+	if !strings.Contains(entry.Content.Body, "first &lt;comment&gt;") {
+		t.Fatalf("comment not escaped in content: %s", entry.Content.Body)
+	}
+	if !strings.Contains(entry.Content.Body, `<img src="data:image/png;base64,AAEC"`) {
+		t.Fatalf("embedded image not inlined: %s", entry.Content.Body)
+	}
+}
+
+// TestWriteHTML asserts the static HTML site is generated.
+func TestWriteHTML(t *testing.T) {
+	dir := t.TempDir()
+	feed := testFeed()
+	assert.NilErr(t, WriteHTML(dir, feed))
+
+	index, err := os.ReadFile(filepath.Join(dir, "index.html"))
+	assert.NilErr(t, err)
+	for _, p := range feed.Posts {
+		link := postFileName(p.Summary.ID.String())
+		if !bytes.Contains(index, []byte(link)) {
+			t.Fatalf("index does not link to %s", link)
+		}
+		fname := filepath.Join(dir, filepath.FromSlash(link))
+		if _, err := os.Stat(fname); err != nil {
+			t.Fatalf("post page not written: %v", err)
+		}
+	}
+
+	page, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(
+		postFileName(feed.Posts[1].Summary.ID.String()))))
+	assert.NilErr(t, err)
+	if !bytes.Contains(page, []byte("a reply")) {
+		t.Fatalf("post page does not include comments")
+	}
+}
@@ -0,0 +1,137 @@
+package feedexport
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/companyzero/bisonrelay/rpc"
+)
+
+const htmlStyle = `body { font-family: sans-serif; max-width: 50em; margin: auto; padding: 1em; }
+.meta { color: #777; font-size: 0.9em; }
+.embed { color: #557; font-style: italic; }
+img { max-width: 100%; }
+ul.comments { list-style: none; padding-left: 1.5em; border-left: 1px solid #ddd; }
+`
+
+var indexTmpl = template.Must(template.New("index").Funcs(tmplFuncs).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{ .Title }}</title>
+<link rel="stylesheet" href="style.css">
+</head>
+<body>
+<h1>{{ .Title }}</h1>
+<ul>
+{{- range .Posts }}
+<li><a href="{{ postFile . }}">{{ .Title }}</a>
+<span class="meta">by {{ .Author }} on {{ date .Date }}</span></li>
+{{- end }}
+</ul>
+</body>
+</html>
+`))
+
+var postTmpl = template.Must(template.New("post").Funcs(tmplFuncs).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{ .Title }}</title>
+<link rel="stylesheet" href="../style.css">
+</head>
+<body>
+<p><a href="../index.html">Back to {{ .FeedTitle }}</a></p>
+<h1>{{ .Title }}</h1>
+<p class="meta">by {{ .Author }} on {{ date .Date }}</p>
+<div class="content">{{ .Content }}</div>
+{{- if .Comments }}
+<hr>
+<h2>Comments</h2>
+{{ template "comments" .Comments }}
+{{- end }}
+</body>
+</html>
+{{ define "comments" }}<ul class="comments">
+{{- range . }}
+<li><p><b>{{ .From }}</b>{{ if not .Date.IsZero }} <span class="meta">{{ date .Date }}</span>{{ end }}</p>
+<p>{{ content .Text }}</p>
+{{- if .Children }}{{ template "comments" .Children }}{{ end }}</li>
+{{- end }}
+</ul>{{ end }}
+`))
+
+var tmplFuncs = template.FuncMap{
+	"date":     func(t time.Time) string { return t.Format("2006-01-02 15:04") },
+	"content":  contentHTML,
+	"postFile": func(p htmlPost) string { return postFileName(p.ID) },
+}
+
+// postFileName returns the name of the file of a post page, relative to the
+// root of the site.
+func postFileName(id string) string {
+	return filepath.ToSlash(filepath.Join("posts", id+".html"))
+}
+
+type htmlPost struct {
+	ID        string
+	FeedTitle string
+	Title     string
+	Author    string
+	Date      time.Time
+	Content   template.HTML
+	Comments  []*Comment
+}
+
+// WriteHTML writes the feed as a static HTML site in the given directory. The
+// site is composed of an index page linking to one page per post (which
+// includes the post comments).
+func WriteHTML(dir string, feed *Feed) error {
+	postsDir := filepath.Join(dir, "posts")
+	if err := os.MkdirAll(postsDir, 0o700); err != nil {
+		return err
+	}
+
+	var posts []htmlPost
+	for _, p := range feed.sortedPosts() {
+		hp := htmlPost{
+			ID:        p.Summary.ID.String(),
+			FeedTitle: feed.Title,
+			Title:     p.title(),
+			Author:    p.Summary.AuthorNick,
+			Date:      p.Summary.Date,
+			Content:   contentHTML(p.Metadata.Attributes[rpc.RMPMain]),
+			Comments:  p.comments(),
+		}
+		fname := filepath.Join(dir, filepath.FromSlash(postFileName(hp.ID)))
+		if err := writeTemplate(fname, postTmpl, hp); err != nil {
+			return err
+		}
+		posts = append(posts, hp)
+	}
+
+	index := struct {
+		Title string
+		Posts []htmlPost
+	}{Title: feed.Title, Posts: posts}
+	if err := writeTemplate(filepath.Join(dir, "index.html"), indexTmpl, index); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, "style.css"), []byte(htmlStyle), 0o600)
+}
+
+func writeTemplate(fname string, tmpl *template.Template, data interface{}) error {
+	f, err := os.Create(fname)
+	if err != nil {
+		return err
+	}
+	if err := tmpl.Execute(f, data); err != nil {
+		f.Close()
+		return fmt.Errorf("unable to render %s: %w", filepath.Base(fname), err)
+	}
+	return f.Close()
+}
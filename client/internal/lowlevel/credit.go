@@ -0,0 +1,355 @@
+package lowlevel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/companyzero/bisonrelay/client/clientintf"
+	"github.com/companyzero/bisonrelay/rpc"
+	"github.com/companyzero/bisonrelay/zkidentity"
+	"github.com/decred/slog"
+)
+
+var (
+	errCreditNotEnabled = errors.New("server does not accept prepaid credit")
+	errNoServerSession  = errors.New("not connected to server")
+)
+
+// ServerCreditDB is the interface for persisting the state of the credit
+// prepaid to the server.
+type ServerCreditDB interface {
+	// StoreCreditPurchase should store that an attempt to buy credit by
+	// paying the given invoice is being made.
+	StoreCreditPurchase(invoice string, ts time.Time) error
+
+	// CreditPurchases should return the invoices (and the time the
+	// attempts were made) of the stored attempts to buy credit that were
+	// not redeemed yet.
+	CreditPurchases() (map[string]time.Time, error)
+
+	// RemoveCreditPurchase should remove the stored attempt to buy credit
+	// by paying the given invoice.
+	RemoveCreditPurchase(invoice string) error
+
+	// StoreCreditReceipt should verify and store a receipt issued by the
+	// server for a change in the credit balance.
+	StoreCreditReceipt(rpc.CreditReceipt) error
+}
+
+// ServerCredit tracks the credit prepaid by the local client to the server.
+// The credit is used by the RMQ and RVManager to pay for pushes and
+// subscriptions without an invoice round trip per payment. Once the credit
+// runs out, they fall back to paying individual invoices.
+//
+// The balance tracked locally is the last balance fetched from the server
+// minus the debits made since then, which ensures concurrent payments do not
+// attempt to use more than the available credit.
+type ServerCredit struct {
+	log     slog.Logger
+	db      ServerCreditDB
+	account zkidentity.ShortID
+
+	mtx     sync.Mutex
+	sess    clientintf.ServerSessionIntf
+	balance uint64
+}
+
+// NewServerCredit returns a new ServerCredit for the given credit account.
+func NewServerCredit(log slog.Logger, db ServerCreditDB, account zkidentity.ShortID) *ServerCredit {
+	if log == nil {
+		log = slog.Disabled
+	}
+	return &ServerCredit{
+		log:     log,
+		db:      db,
+		account: account,
+	}
+}
+
+// Account returns the ID of the credit account.
+func (cr *ServerCredit) Account() zkidentity.ShortID {
+	return cr.account
+}
+
+// Balance returns the locally tracked balance of the credit account.
+func (cr *ServerCredit) Balance() uint64 {
+	cr.mtx.Lock()
+	res := cr.balance
+	cr.mtx.Unlock()
+	return res
+}
+
+// BindToSession binds the credit to the specified server session. When the
+// server accepts credit, any pending purchases are redeemed and the balance
+// is fetched.
+func (cr *ServerCredit) BindToSession(sess clientintf.ServerSessionIntf) {
+	cr.mtx.Lock()
+	cr.sess = sess
+	cr.balance = 0
+	cr.mtx.Unlock()
+
+	if sess == nil || !sess.Policy().CreditEnabled {
+		return
+	}
+
+	go func() {
+		balance, err := cr.refresh(sess.Context(), sess)
+		if err != nil {
+			if !errors.Is(err, context.Canceled) {
+				cr.log.Warnf("Unable to fetch credit balance: %v", err)
+			}
+			return
+		}
+		cr.log.Debugf("Server credit balance: %d MAtoms", balance)
+	}()
+}
+
+// session returns the currently bound session, if it accepts credit.
+func (cr *ServerCredit) session() (clientintf.ServerSessionIntf, error) {
+	cr.mtx.Lock()
+	sess := cr.sess
+	cr.mtx.Unlock()
+	if sess == nil {
+		return nil, errNoServerSession
+	}
+	if !sess.Policy().CreditEnabled {
+		return nil, errCreditNotEnabled
+	}
+	return sess, nil
+}
+
+// reserve attempts to debit amt from the locally tracked balance in order to
+// pay with credit for an action in the given session. It returns false if
+// the payment cannot be made with credit.
+func (cr *ServerCredit) reserve(sess clientintf.ServerSessionIntf, amt uint64) bool {
+	if cr == nil || !sess.Policy().CreditEnabled {
+		return false
+	}
+
+	cr.mtx.Lock()
+	defer cr.mtx.Unlock()
+	if cr.balance < amt {
+		return false
+	}
+	cr.balance -= amt
+	return true
+}
+
+// exhausted is called when the server rejects a payment due to insufficient
+// credit. Further payments are made with invoices until the balance is
+// fetched again.
+func (cr *ServerCredit) exhausted() {
+	cr.mtx.Lock()
+	cr.balance = 0
+	cr.mtx.Unlock()
+}
+
+// handleReceipt stores a receipt sent by the server.
+func (cr *ServerCredit) handleReceipt(receipt *rpc.CreditReceipt) {
+	if cr == nil || receipt == nil {
+		return
+	}
+	if receipt.Account != cr.account {
+		cr.log.Warnf("Received credit receipt for unknown account")
+		return
+	}
+	if err := cr.db.StoreCreditReceipt(*receipt); err != nil {
+		cr.log.Warnf("Unable to store credit receipt: %v", err)
+	}
+}
+
+// fetchBalance requests the balance of the credit account, adding to it the
+// amount paid to the invoice with the given ID (if not nil).
+func (cr *ServerCredit) fetchBalance(ctx context.Context, sess clientintf.ServerSessionIntf,
+	paidInvoiceID []byte) (uint64, error) {
+
+	msg := rpc.Message{Command: rpc.TaggedCmdGetCreditBalance}
+	payload := &rpc.GetCreditBalance{
+		Account:       cr.account,
+		PaidInvoiceID: paidInvoiceID,
+	}
+
+	replyChan := make(chan interface{})
+	err := sess.SendPRPC(msg, payload, replyChan)
+	if err != nil {
+		return 0, err
+	}
+
+	// Wait to get the balance back.
+	var reply interface{}
+	select {
+	case reply = <-replyChan:
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+
+	switch reply := reply.(type) {
+	case *rpc.GetCreditBalanceReply:
+		if reply.Error == rpc.ErrCreditAlreadyRedeemed.Error() {
+			return 0, rpc.ErrCreditAlreadyRedeemed
+		}
+		if reply.Error != "" {
+			return 0, errors.New(reply.Error)
+		}
+		cr.handleReceipt(reply.Receipt)
+		cr.mtx.Lock()
+		cr.balance = reply.Balance
+		cr.mtx.Unlock()
+		return reply.Balance, nil
+	case error:
+		return 0, reply
+	default:
+		return 0, fmt.Errorf("unknown reply from server: %v", reply)
+	}
+}
+
+// redeem adds the amount paid to the given invoice to the credit balance.
+func (cr *ServerCredit) redeem(ctx context.Context, sess clientintf.ServerSessionIntf,
+	invoice string) (uint64, error) {
+
+	decoded, err := sess.PayClient().DecodeInvoice(ctx, invoice)
+	if err != nil {
+		return 0, err
+	}
+	balance, err := cr.fetchBalance(ctx, sess, decoded.ID)
+	if err != nil && !errors.Is(err, rpc.ErrCreditAlreadyRedeemed) {
+		return 0, err
+	}
+
+	// When the invoice was already redeemed, the credit was added by a
+	// previous attempt, so the purchase is also done.
+	if err := cr.db.RemoveCreditPurchase(invoice); err != nil {
+		cr.log.Warnf("Unable to remove credit purchase: %v", err)
+	}
+	return balance, err
+}
+
+// refresh redeems the pending credit purchases and fetches the balance.
+func (cr *ServerCredit) refresh(ctx context.Context, sess clientintf.ServerSessionIntf) (uint64, error) {
+	purchases, err := cr.db.CreditPurchases()
+	if err != nil {
+		return 0, err
+	}
+
+	// The server redeems paid purchases regardless of how long ago they
+	// were paid, so they are kept until redeemed. Purchases that were not
+	// paid are dropped once the push payment lifetime elapses, which is
+	// well after their invoice expired.
+	lifetimeLimit := time.Now().Add(-sess.Policy().PushPaymentLifetime)
+	pc := sess.PayClient()
+	for invoice, ts := range purchases {
+		if err := pc.IsPaymentCompleted(ctx, invoice); err != nil {
+			if !ts.Before(lifetimeLimit) {
+				cr.log.Debugf("Credit purchase attempted at %s not "+
+					"completed: %v", ts.Format(time.RFC3339), err)
+				continue
+			}
+			cr.log.Warnf("Credit purchase attempted at %s was not "+
+				"completed (%v). Dropping invoice %s",
+				ts.Format(time.RFC3339), err, invoice)
+			if err := cr.db.RemoveCreditPurchase(invoice); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		_, err := cr.redeem(ctx, sess, invoice)
+		if errors.Is(err, rpc.ErrCreditAlreadyRedeemed) {
+			cr.log.Infof("Credit purchase attempted at %s was "+
+				"already redeemed", ts.Format(time.RFC3339))
+		} else if err != nil {
+			cr.log.Warnf("Unable to redeem credit purchase: %v", err)
+		}
+	}
+
+	return cr.fetchBalance(ctx, sess, nil)
+}
+
+// FetchBalance redeems pending credit purchases and fetches the balance of the
+// credit account from the server.
+func (cr *ServerCredit) FetchBalance(ctx context.Context) (uint64, error) {
+	sess, err := cr.session()
+	if err != nil {
+		return 0, err
+	}
+	return cr.refresh(ctx, sess)
+}
+
+// fetchInvoice requests an invoice to buy credit.
+func (cr *ServerCredit) fetchInvoice(ctx context.Context, sess clientintf.ServerSessionIntf) (string, error) {
+	msg := rpc.Message{Command: rpc.TaggedCmdGetInvoice}
+	pc := sess.PayClient()
+	payload := &rpc.GetInvoice{
+		PaymentScheme: pc.PayScheme(),
+		Action:        rpc.InvoiceActionCredit,
+	}
+
+	replyChan := make(chan interface{})
+	err := sess.SendPRPC(msg, payload, replyChan)
+	if err != nil {
+		return "", err
+	}
+
+	// Wait to get the invoice back.
+	var reply interface{}
+	select {
+	case reply = <-replyChan:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+
+	switch reply := reply.(type) {
+	case *rpc.GetInvoiceReply:
+		// Decode invoice and sanity check it.
+		decoded, err := pc.DecodeInvoice(ctx, reply.Invoice)
+		if err != nil {
+			return "", fmt.Errorf("unable to decode received invoice: %v", err)
+		}
+		if decoded.IsExpired(rpc.InvoiceExpiryAffordance) {
+			return "", fmt.Errorf("server sent expired invoice")
+		}
+		if decoded.MAtoms != 0 {
+			return "", fmt.Errorf("server sent invoice with amount instead of zero")
+		}
+
+		return reply.Invoice, nil
+	case error:
+		return "", reply
+	default:
+		return "", fmt.Errorf("unknown reply from server: %v", reply)
+	}
+}
+
+// Purchase buys credit by paying the given amount (in milliatoms) to the
+// server. It returns the new balance and the fees paid.
+func (cr *ServerCredit) Purchase(ctx context.Context, amount uint64) (uint64, int64, error) {
+	sess, err := cr.session()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	ctx, cancel := multiCtx(ctx, sess.Context())
+	defer cancel()
+
+	invoice, err := cr.fetchInvoice(ctx, sess)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Save that there's a purchase attempt outbound so that the credit is
+	// redeemed on the next session if it fails in this one.
+	if err := cr.db.StoreCreditPurchase(invoice, time.Now()); err != nil {
+		return 0, 0, err
+	}
+
+	cr.log.Infof("Buying %d MAtoms of server credit", amount)
+	fees, err := sess.PayClient().PayInvoiceAmount(ctx, invoice, int64(amount))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	balance, err := cr.redeem(ctx, sess, invoice)
+	return balance, fees, err
+}
@@ -195,3 +195,27 @@ func (err ErrRVAlreadyUnsubscribed) Is(target error) bool {
 func makeRdvzAlreadyUnsubscribedError(rv string) ErrRVAlreadyUnsubscribed {
 	return ErrRVAlreadyUnsubscribed{rv: rv}
 }
+
+// ErrPaymentRejected is returned when the DB rejects (i.e. does not
+// approve) a payment that would be needed to push or subscribe to RVs.
+type ErrPaymentRejected struct {
+	rvs []RVID
+	err error
+}
+
+func (err ErrPaymentRejected) Error() string {
+	return fmt.Sprintf("payment rejected: %v", err.err)
+}
+
+func (err ErrPaymentRejected) Unwrap() error {
+	return err.err
+}
+
+func (err ErrPaymentRejected) Is(target error) bool {
+	_, ok := target.(ErrPaymentRejected)
+	return ok
+}
+
+func makePaymentRejectedError(rvs []RVID, err error) ErrPaymentRejected {
+	return ErrPaymentRejected{rvs: rvs, err: err}
+}
@@ -71,6 +71,12 @@ type RVManagerDB interface {
 
 	// MarkRVUnpaid marks the specified RV as unpaid in the DB.
 	MarkRVUnpaid(rv RVID) error
+
+	// ApproveSubsPayment should return an error if the payment of the
+	// given amount (in milliatoms) to subscribe to the given RVs should
+	// not be made. In that case, the subscription to the RVs is delayed
+	// until the next update of the subscriptions.
+	ApproveSubsPayment(rvs []RVID, amount int64) error
 }
 
 // RVManager keeps track of the various rendezvous points that should be
@@ -90,6 +96,10 @@ type RVManager struct {
 	nextInvoice string
 	subDoneCB   func()
 
+	// credit is used to pay for subscriptions when the server accepts
+	// prepaid credit. It may be nil.
+	credit *ServerCredit
+
 	// subsDelayer is used to do some hysteresis around the full
 	// subscription set and avoid sending multiple subscription requests to
 	// the server in a very short time frame.
@@ -125,6 +135,12 @@ func NewRVManager(log slog.Logger, db RVManagerDB, subsDelayer func() <-chan tim
 	}
 }
 
+// UseServerCredit sets the manager to pay for subscriptions using the given
+// prepaid credit, when the server accepts it. This must be called before Run.
+func (rmgr *RVManager) UseServerCredit(credit *ServerCredit) {
+	rmgr.credit = credit
+}
+
 // Sub informs the manager to subscribe to the given rendezvous point and to
 // call handler once a message is received in the given point.
 //
@@ -285,16 +301,44 @@ func (rmgr *RVManager) fetchNextInvoice(ctx context.Context, sess clientintf.Ser
 	}
 }
 
+// subsPaid calls the subPaid handler of the given subs, splitting the total
+// fees among them.
+func subsPaid(unpaidRVs []ratchet.RVPoint, subs map[RVID]rdzvSub, subPayRate uint64,
+	totalFees int64) error {
+
+	for i, id := range unpaidRVs {
+		sub, ok := subs[id]
+		if !ok {
+			// Should not happen.
+			return fmt.Errorf("unpaid RV not in subs map: %s", id)
+		}
+
+		if sub.subPaid == nil {
+			continue
+		}
+
+		subFees := totalFees / int64(len(unpaidRVs))
+		if i == 0 {
+			// Add rest of fee to the first one.
+			subFees += totalFees % int64(len(unpaidRVs))
+		}
+		sub.subPaid(int64(subPayRate), subFees)
+	}
+	return nil
+}
+
 // payForSubs pays for any unpaid RVs contained in the passed list. Returns the
-// list of (previously) unpaid RVs.
+// list of (previously) unpaid RVs and whether they are being paid with
+// prepaid credit (in which case the server debits the credit when it receives
+// the subscription request).
 func (rmgr *RVManager) payForSubs(ctx context.Context, rlist []ratchet.RVPoint,
-	subs map[RVID]rdzvSub, sess clientintf.ServerSessionIntf) ([]ratchet.RVPoint, error) {
+	subs map[RVID]rdzvSub, sess clientintf.ServerSessionIntf) ([]ratchet.RVPoint, bool, error) {
 
 	// Determine payment amount. The amount to pay depends on how many
 	// unpaid for RVs we have.
 	unpaidRVs, err := rmgr.db.UnpaidRVs(rlist, sess.ExpirationDays())
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	// Fetch invoice if needed.
@@ -302,7 +346,7 @@ func (rmgr *RVManager) payForSubs(ctx context.Context, rlist []ratchet.RVPoint,
 	needsInvoice := false
 	if len(unpaidRVs) == 0 {
 		// No need to pay.
-		return nil, nil
+		return nil, false, nil
 	} else if rmgr.nextInvoice == "" {
 		needsInvoice = true
 	} else {
@@ -320,13 +364,26 @@ func (rmgr *RVManager) payForSubs(ctx context.Context, rlist []ratchet.RVPoint,
 	if needsInvoice {
 		err := rmgr.fetchNextInvoice(ctx, sess)
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
 	}
 
 	_, subPayRate := sess.PaymentRates()
 	amt := len(unpaidRVs) * int(subPayRate)
 
+	// Check the payment is allowed before making it.
+	if err := rmgr.db.ApproveSubsPayment(unpaidRVs, int64(amt)); err != nil {
+		return nil, false, makePaymentRejectedError(unpaidRVs, err)
+	}
+
+	// Pay with prepaid credit when there is enough of it. The invoice is
+	// kept for when the credit runs out.
+	if rmgr.credit.reserve(sess, uint64(amt)) {
+		rmgr.log.Debugf("Paying %d MAtoms for new subs %s with credit",
+			amt, joinRVList(unpaidRVs))
+		return unpaidRVs, true, nil
+	}
+
 	// Pay for it. Independently of payment result, clear the invoice to pay.
 	ctx, cancel := multiCtx(ctx, sess.Context())
 	rmgr.log.Debugf("Attempting to pay %d MAtoms for new subs %s", amt,
@@ -338,27 +395,12 @@ func (rmgr *RVManager) payForSubs(ctx context.Context, rlist []ratchet.RVPoint,
 	// If the payment completed, track the stats for the previously unpaid
 	// subs.
 	if err == nil {
-		for i, id := range unpaidRVs {
-			sub, ok := subs[id]
-			if !ok {
-				// Should not happen.
-				return nil, fmt.Errorf("unpaid RV not in subs map: %s", id)
-			}
-
-			if sub.subPaid == nil {
-				continue
-			}
-
-			subFees := totalFees / int64(len(unpaidRVs))
-			if i == 0 {
-				// Add rest of fee to the first one.
-				subFees += totalFees % int64(len(unpaidRVs))
-			}
-			sub.subPaid(int64(subPayRate), subFees)
+		if err := subsPaid(unpaidRVs, subs, subPayRate, totalFees); err != nil {
+			return nil, false, err
 		}
 	}
 
-	return unpaidRVs, err
+	return unpaidRVs, false, err
 }
 
 // updatePayloadSubscriptions (re-)subscribes to all rendezvous points in subs on
@@ -366,9 +408,20 @@ func (rmgr *RVManager) payForSubs(ctx context.Context, rlist []ratchet.RVPoint,
 func (rmgr *RVManager) updatePayloadSubscriptions(ctx context.Context,
 	add, del []ratchet.RVPoint, subs map[RVID]rdzvSub, sess clientintf.ServerSessionIntf) error {
 
-	// Pay for the subs we haven't paid yet.
-	unpaidRVs, err := rmgr.payForSubs(ctx, add, subs, sess)
-	if err != nil {
+	// Pay for the subs we haven't paid yet. If the payment is rejected,
+	// subscribe only to the other RVs and return the rejection error at
+	// the end, so that the rejected subscriptions are attempted again
+	// later.
+	unpaidRVs, paidWithCredit, err := rmgr.payForSubs(ctx, add, subs, sess)
+	var errRejected ErrPaymentRejected
+	if errors.As(err, &errRejected) {
+		add = excludeRVs(add, errRejected.rvs)
+		unpaidRVs = nil
+		paidWithCredit = false
+		if len(add) == 0 && len(del) == 0 {
+			return err
+		}
+	} else if err != nil {
 		return err
 	}
 
@@ -380,6 +433,10 @@ func (rmgr *RVManager) updatePayloadSubscriptions(ctx context.Context,
 		AddRendezvous: add,
 		DelRendezvous: del,
 	}
+	if paidWithCredit {
+		account := rmgr.credit.Account()
+		payload.CreditAccount = &account
+	}
 
 	replyChan := make(chan interface{})
 	err = sess.SendPRPC(msg, payload, replyChan)
@@ -398,6 +455,13 @@ func (rmgr *RVManager) updatePayloadSubscriptions(ctx context.Context,
 	// Resolve the subscription reply.
 	switch reply := reply.(type) {
 	case *rpc.SubscribeRoutedMessagesReply:
+		// When the server does not have enough credit to pay for the
+		// subs, stop using credit so that they are paid with an
+		// invoice on the next attempt.
+		if reply.Error == rpc.ErrInsufficientCredit.Error() {
+			rmgr.credit.exhausted()
+			return rpc.ErrInsufficientCredit
+		}
 		if reply.Error != "" {
 			// Handle the "unpaid subscription" error specially,
 			// in order to clear the paid flag from the local DB.
@@ -412,12 +476,21 @@ func (rmgr *RVManager) updatePayloadSubscriptions(ctx context.Context,
 		if reply.NextInvoice != "" {
 			rmgr.nextInvoice = reply.NextInvoice
 		}
+		rmgr.credit.handleReceipt(reply.Receipt)
 	case error:
 		return reply
 	default:
 		return fmt.Errorf("unknown reply from server: %v", err)
 	}
 
+	// Track the amount paid for the subs with credit.
+	if paidWithCredit {
+		_, subPayRate := sess.PaymentRates()
+		if err := subsPaid(unpaidRVs, subs, subPayRate, 0); err != nil {
+			return err
+		}
+	}
+
 	// Mark the unpaid RVs as paid (since server ack'd).
 	if sess.PayClient().PayScheme() != rpc.PaySchemeFree {
 		if err := rmgr.db.SavePaidRVs(unpaidRVs); err != nil {
@@ -432,6 +505,9 @@ func (rmgr *RVManager) updatePayloadSubscriptions(ctx context.Context,
 		rmgr.log.Debugf("RV subscriptions changed +%d -%d", len(add), len(del))
 	}
 
+	if errRejected.err != nil {
+		return errRejected
+	}
 	return nil
 }
 
@@ -578,6 +654,23 @@ loop:
 
 		case updateErr := <-updateResChan:
 			lastUpdateDone = true
+
+			// The subscriptions for which payment was rejected
+			// are attempted again on the next update. The
+			// remaining ones were updated.
+			var errRejected ErrPaymentRejected
+			if errors.As(updateErr, &errRejected) {
+				rmgr.log.Warnf("Delaying %d subscriptions due to "+
+					"rejected payment: %v", len(errRejected.rvs),
+					errRejected.err)
+				for _, rv := range errRejected.rvs {
+					if _, ok := subs[rv]; ok {
+						toAdd = append(toAdd, rv)
+					}
+				}
+				updateErr = nil
+			}
+
 			lastUpdateSuccess = updateErr == nil
 			if updateErr != nil {
 				// Dissociate from server due to send error.
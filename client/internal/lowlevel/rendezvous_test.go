@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/companyzero/bisonrelay/internal/assert"
 	"github.com/companyzero/bisonrelay/rpc"
 )
 
@@ -411,3 +412,58 @@ func TestRendezvousManagerNilSession(t *testing.T) {
 		t.Fatal("timeout")
 	}
 }
+
+// TestRendezvousRejectedSubsPayment asserts that subscriptions for which the
+// payment is rejected by the DB are delayed until the next update, while
+// already paid subscriptions are sent.
+func TestRendezvousRejectedSubsPayment(t *testing.T) {
+	t.Parallel()
+
+	errRejected := errors.New("rejected")
+	unpaidID := rvidFromStr("unpaid-id")
+	paidIDs := []RVID{rvidFromStr("paid-1"), rvidFromStr("paid-2")}
+	db := &mockRvMgrDB{
+		paid:       map[RVID]struct{}{paidIDs[0]: {}, paidIDs[1]: {}},
+		rejectSubs: errRejected,
+	}
+	rmgr := NewRVManager(nil, db, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runErr := make(chan error, 1)
+	go func() { runErr <- rmgr.Run(ctx) }()
+
+	handler := func(gotBlob RVBlob) error { return nil }
+	sess := newMockServerSession()
+	rmgr.BindToSession(sess)
+
+	// Subscribing to an unpaid RV is delayed after fetching the invoice.
+	subDoneChan := make(chan error, 1)
+	go func() { subDoneChan <- rmgr.Sub(unpaidID, handler, nil) }()
+	sess.replyNextPRPC(t, &rpc.GetInvoiceReply{})
+	assert.NilErrFromChan(t, subDoneChan)
+
+	// Subscribing to a paid RV works. The unpaid RV is rejected again.
+	go func() { subDoneChan <- rmgr.Sub(paidIDs[0], handler, nil) }()
+	sess.replyNextPRPC(t, &rpc.GetInvoiceReply{})
+	payload := sess.replyNextPRPC(t, &rpc.SubscribeRoutedMessagesReply{})
+	assert.DeepEqual(t, payload.(*rpc.SubscribeRoutedMessages).AddRendezvous,
+		[]RVID{paidIDs[0]})
+	assert.NilErrFromChan(t, subDoneChan)
+
+	// Once payments are approved, the next update subscribes to the
+	// previously rejected RV.
+	db.setRejectSubs(nil)
+	go func() { subDoneChan <- rmgr.Sub(paidIDs[1], handler, nil) }()
+	sess.replyNextPRPC(t, &rpc.GetInvoiceReply{})
+	payload = sess.replyNextPRPC(t, &rpc.SubscribeRoutedMessagesReply{})
+	assert.DeepEqual(t, payload.(*rpc.SubscribeRoutedMessages).AddRendezvous,
+		[]RVID{unpaidID, paidIDs[1]})
+	assert.NilErrFromChan(t, subDoneChan)
+
+	// Assert no run errors occurred.
+	select {
+	case err := <-runErr:
+		t.Fatal(err)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
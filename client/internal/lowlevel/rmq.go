@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	genericlist "github.com/bahlo/generic-list-go"
@@ -24,8 +25,9 @@ type rmmsg struct {
 	rv        RVID
 	encrypted []byte
 
-	mtx      sync.Mutex
-	paidHash []byte
+	mtx            sync.Mutex
+	paidHash       []byte
+	paidWithCredit bool
 }
 
 func (r *rmmsg) sendReply(err error) {
@@ -56,14 +58,20 @@ type RMQDB interface {
 	// DeleteRVPaymentAttempt removes the prior attempt to pay for the given
 	// RV.
 	DeleteRVPaymentAttempt(RVID) error
+
+	// ApprovePushPayment should return an error if the payment of the
+	// given amount (in milliatoms) to push the given RM should not be
+	// made. In that case, the RM is failed without being sent. This is
+	// called before paying for the RM and may block.
+	ApprovePushPayment(OutboundRM, int64) error
 }
 
 // RMQ is a queue for sending RoutedMessages (RMs) to the server. The rmq
 // supports a flickering server connection: any unsent RMs are queued (FIFO
 // style) until a new server session is bound via `bindToSession`.
 //
-// Sending an RM only fails when the rmq is shutting down or the rm failed to
-// encrypt itself.
+// Sending an RM only fails when the rmq is shutting down, the rm failed to
+// encrypt itself or its payment was not approved.
 type RMQ struct {
 	// The following fields should only be set during setup this struct and
 	// are not safe for concurrent modification.
@@ -78,8 +86,24 @@ type RMQ struct {
 	timingStat     timestats.Tracker
 	db             RMQDB
 
-	nextSendChan chan *rmmsg
-	sendDoneChan chan struct{}
+	nextApproveChan chan *rmmsg
+	nextSendChan    chan *rmmsg
+	sendDoneChan    chan struct{}
+
+	// credit is used to pay for RMs when the server accepts prepaid
+	// credit. It may be nil.
+	credit *ServerCredit
+
+	// sessMtx protects the following fields. sess is the currently bound
+	// session (which may be nil) and sessBound is closed once a session
+	// is bound.
+	sessMtx   sync.Mutex
+	sess      clientintf.ServerSessionIntf
+	sessBound chan struct{}
+
+	// approving is 1 while approveLoop is approving an RM. It is accessed
+	// atomically.
+	approving int32
 }
 
 func NewRMQ(log slog.Logger, payClient clientintf.PaymentClient,
@@ -99,9 +123,18 @@ func NewRMQ(log slog.Logger, payClient clientintf.PaymentClient,
 		nextSendChan:   make(chan *rmmsg),
 		sendDoneChan:   make(chan struct{}),
 		timingStat:     *timestats.NewTracker(250),
+
+		nextApproveChan: make(chan *rmmsg),
+		sessBound:       make(chan struct{}),
 	}
 }
 
+// UseServerCredit sets the rmq to pay for RMs using the given prepaid credit,
+// when the server accepts it. This must be called before Run.
+func (q *RMQ) UseServerCredit(credit *ServerCredit) {
+	q.credit = credit
+}
+
 // BindToSession binds the rmq to the specified server session. Queued and new
 // messages will be sent via this server until it is removed or the rmq stops.
 func (q *RMQ) BindToSession(sess clientintf.ServerSessionIntf) {
@@ -155,42 +188,40 @@ func (q *RMQ) TimingStats() []timestats.Quantile {
 
 // processRMAck processes the given ack'd reply from a previously sent rm rpc
 // message. It returns a new server invoice, if the reply indicates success
-// and there is a new invoice in it.
-func (q *RMQ) processRMAck(reply interface{}) (string, error) {
+// and there is a new invoice in it, and the credit receipt, if the RM was paid
+// with prepaid credit.
+func (q *RMQ) processRMAck(reply interface{}) (string, *rpc.CreditReceipt, error) {
 	q.log.Tracef("Processing RMAck reply %T", reply)
 
+	if r, ok := reply.(rpc.RouteMessageReply); ok {
+		reply = &r
+	}
+
 	var err error
 	var nextInvoice string
+	var receipt *rpc.CreditReceipt
 	switch reply := reply.(type) {
-	case rpc.RouteMessageReply:
-		if reply.Error != "" {
-			if reply.Error == rpc.ErrRMInvoicePayment.Error() {
-				err = rpc.ErrRMInvoicePayment
-			} else {
-				err = routeMessageReplyError{errorStr: reply.Error}
-			}
-		}
-		if reply.NextInvoice != "" {
-			nextInvoice = reply.NextInvoice
-		}
 	case *rpc.RouteMessageReply:
-		if reply.Error != "" {
-			if reply.Error == rpc.ErrRMInvoicePayment.Error() {
-				err = rpc.ErrRMInvoicePayment
-			} else {
-				err = routeMessageReplyError{errorStr: reply.Error}
-			}
+		switch reply.Error {
+		case "":
+		case rpc.ErrRMInvoicePayment.Error():
+			err = rpc.ErrRMInvoicePayment
+		case rpc.ErrInsufficientCredit.Error():
+			err = rpc.ErrInsufficientCredit
+		default:
+			err = routeMessageReplyError{errorStr: reply.Error}
 		}
 		if reply.NextInvoice != "" {
 			nextInvoice = reply.NextInvoice
 		}
+		receipt = reply.Receipt
 	case error:
 		err = reply
 	default:
 		err = fmt.Errorf("unknown reply of RMAck: %v", reply)
 	}
 
-	return nextInvoice, err
+	return nextInvoice, receipt, err
 }
 
 // fetchInvoice requests and returns an invoice for the server to pay for
@@ -290,20 +321,27 @@ func (q *RMQ) isRVInvoicePaid(ctx context.Context, rv RVID, amt int64, pc client
 	return decoded.ID
 }
 
+// PushPaymentAmount returns the amount to pay to push an RM with the given
+// encrypted length at the given push payment rate (in MAtoms/KB), respecting
+// the minimum push payment.
+func PushPaymentAmount(encLen uint32, pushPayRate, minPushPayment uint64) int64 {
+	return int64(rpc.PushPaymentAmount(uint64(encLen), pushPayRate, minPushPayment))
+}
+
+// sessPushPaymentAmount returns the amount to pay to push an RM with the
+// given encrypted length to the given session.
+func sessPushPaymentAmount(encLen uint32, sess clientintf.ServerSessionIntf) int64 {
+	pushPayRate, _ := sess.PaymentRates()
+	return PushPaymentAmount(encLen, pushPayRate, sess.Policy().MinPushPayment)
+}
+
 // payForRM pays for the given rm on the server.
 func (q *RMQ) payForRM(ctx context.Context, rmm *rmmsg, invoice string,
 	sess clientintf.ServerSessionIntf) error {
 
 	// Determine payment amount.
 	pc := sess.PayClient()
-	payloadSize := rmm.orm.EncryptedLen()
-	pushPayRate, _ := sess.PaymentRates()
-	amt := int64(payloadSize) * int64(pushPayRate)
-
-	// Enforce the minimum payment policy.
-	if amt < int64(rpc.MinRMPushPayment) {
-		amt = int64(rpc.MinRMPushPayment)
-	}
+	amt := sessPushPaymentAmount(rmm.orm.EncryptedLen(), sess)
 
 	// Check for a successful previous payment attempt.
 	paidHash := q.isRVInvoicePaid(ctx, rmm.rv, amt, pc, sess)
@@ -314,6 +352,20 @@ func (q *RMQ) payForRM(ctx context.Context, rmm *rmmsg, invoice string,
 		return nil
 	}
 
+	// Pay with prepaid credit when there is enough of it. The server
+	// debits the credit when it receives the RM.
+	if q.credit.reserve(sess, uint64(amt)) {
+		q.log.Tracef("Paying %d MAtoms to push RM %s with credit", amt,
+			rmm.orm)
+		rmm.mtx.Lock()
+		rmm.paidWithCredit = true
+		rmm.mtx.Unlock()
+		return nil
+	}
+	rmm.mtx.Lock()
+	rmm.paidWithCredit = false
+	rmm.mtx.Unlock()
+
 	// Fetch invoice if needed.
 	var err error
 	var decoded clientintf.DecodedInvoice
@@ -387,6 +439,10 @@ func (q *RMQ) sendToSession(ctx context.Context, rmm *rmmsg, sess clientintf.Ser
 		Rendezvous:    rmm.rv,
 		Message:       rmm.encrypted,
 	}
+	if rmm.paidWithCredit {
+		account := q.credit.Account()
+		payload.CreditAccount = &account
+	}
 
 	// Send it!
 	ackChan := make(chan interface{})
@@ -410,7 +466,8 @@ func (q *RMQ) sendToSession(ctx context.Context, rmm *rmmsg, sess clientintf.Ser
 	}
 
 	// Ack received from server. Process it.
-	nextInvoice, err := q.processRMAck(ackReply)
+	nextInvoice, receipt, err := q.processRMAck(ackReply)
+	q.credit.handleReceipt(receipt)
 
 	// Ignore ErrSubsysExiting. This error happens when (a) the session was
 	// closed or (b) the user is quitting the client.  Either way, the
@@ -441,6 +498,20 @@ func (q *RMQ) sendToSession(ctx context.Context, rmm *rmmsg, sess clientintf.Ser
 		return
 	}
 
+	// When the server does not have enough credit to pay for the RM,
+	// stop using credit and try again paying with an invoice.
+	if errors.Is(err, rpc.ErrInsufficientCredit) {
+		q.log.Warnf("Server credit exhausted when attempting to push "+
+			"to RV %s. Attempting again with new invoice.", rmm.rv)
+		q.credit.exhausted()
+		rmm.mtx.Lock()
+		rmm.paidWithCredit = false
+		rmm.mtx.Unlock()
+
+		q.sendToSession(ctx, rmm, sess, "", replyChan)
+		return
+	}
+
 	// Track how long it took to get the ack.
 	q.timingStat.Add(time.Since(sendTime))
 
@@ -457,6 +528,11 @@ func (q *RMQ) sendToSession(ctx context.Context, rmm *rmmsg, sess clientintf.Ser
 
 	// At this point, err == nil (RM was sent and acknowledged by server).
 
+	// Track the amount paid for the RM with credit.
+	if rmm.paidWithCredit {
+		rmm.orm.PaidForRM(sessPushPaymentAmount(rmm.orm.EncryptedLen(), sess), 0)
+	}
+
 	// Send reply to original caller.
 	go rmm.sendReply(err)
 
@@ -502,7 +578,7 @@ func (q *RMQ) Len() (int, int) {
 		return 0, 0
 	}
 
-	return lq, ls
+	return lq + int(atomic.LoadInt32(&q.approving)), ls
 }
 
 // enqueueLoop is responsible for maintaining the prioritized outbound queue of
@@ -521,7 +597,7 @@ func (q *RMQ) enqueueLoop(ctx context.Context) error {
 	// nextRMM to send (last dequeued value).
 	var nextRMM *rmmsg
 
-	// sendChan is set to either q.nextSendChan (when we have items to send)
+	// sendChan is set to either q.nextApproveChan (when we have items to send)
 	// or nil (when we have no items to send).
 	var sendChan chan *rmmsg
 
@@ -550,7 +626,7 @@ loop:
 		case rmm := <-q.rmChan:
 			enqueue(rmm)
 			if nextRMM == nil {
-				sendChan = q.nextSendChan
+				sendChan = q.nextApproveChan
 				nextRMM = dequeue()
 			}
 
@@ -587,6 +663,78 @@ loop:
 	return ctx.Err()
 }
 
+// setSession sets the currently bound session, used to approve the payment of
+// RMs.
+func (q *RMQ) setSession(sess clientintf.ServerSessionIntf) {
+	q.sessMtx.Lock()
+	if sess != nil && q.sess == nil {
+		close(q.sessBound)
+	} else if sess == nil && q.sess != nil {
+		q.sessBound = make(chan struct{})
+	}
+	q.sess = sess
+	q.sessMtx.Unlock()
+}
+
+// waitSession returns the currently bound session, waiting until one is
+// bound if needed.
+func (q *RMQ) waitSession(ctx context.Context) (clientintf.ServerSessionIntf, error) {
+	for {
+		q.sessMtx.Lock()
+		sess, bound := q.sess, q.sessBound
+		q.sessMtx.Unlock()
+		if sess != nil {
+			return sess, nil
+		}
+
+		select {
+		case <-bound:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// approveLoop checks that the payment to push each RM is allowed, before the
+// RM is sent to sendLoop. This is done with the payment rate of the current
+// session and before the RM is encrypted, because RMs that are encrypted but
+// not sent would break the ratchet with the remote user.
+//
+// RMs are approved one at a time, in the order they were dequeued, so that
+// RMs to the same user are still encrypted in order.
+func (q *RMQ) approveLoop(ctx context.Context) error {
+	for {
+		var rmm *rmmsg
+		select {
+		case rmm = <-q.nextApproveChan:
+			atomic.StoreInt32(&q.approving, 1)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		sess, err := q.waitSession(ctx)
+		if err != nil {
+			go rmm.sendReply(errRMQExiting)
+			return err
+		}
+		amt := sessPushPaymentAmount(rmm.orm.EncryptedLen(), sess)
+		if err := q.db.ApprovePushPayment(rmm.orm, amt); err != nil {
+			q.log.Debugf("Payment to push RM %s rejected: %v", rmm.orm, err)
+			atomic.StoreInt32(&q.approving, 0)
+			go rmm.sendReply(makePaymentRejectedError(nil, err))
+			continue
+		}
+
+		select {
+		case q.nextSendChan <- rmm:
+			atomic.StoreInt32(&q.approving, 0)
+		case <-ctx.Done():
+			go rmm.sendReply(errRMQExiting)
+			return ctx.Err()
+		}
+	}
+}
+
 // sendLoop attempts to send individual RMs to the server and waits until
 // they are acked before attempting to send the next one. It receives items
 // from enqueueLoop whenever needed.
@@ -626,6 +774,7 @@ loop:
 		select {
 		case sess = <-q.sessionChan:
 			q.log.Debugf("Using new server session %v", sess)
+			q.setSession(sess)
 			if sess == nil {
 				// Lost the server connection, so stop fetching
 				// new items to send.
@@ -754,6 +903,7 @@ loop:
 func (q *RMQ) Run(ctx context.Context) error {
 	g, gctx := errgroup.WithContext(ctx)
 	g.Go(func() error { return q.enqueueLoop(gctx) })
+	g.Go(func() error { return q.approveLoop(gctx) })
 	g.Go(func() error { return q.sendLoop(gctx) })
 	return g.Wait()
 }
@@ -740,3 +740,105 @@ func TestConcurrentRMQSends(t *testing.T) {
 		t.Fatalf("Unexpected queue len: got %d, want 0", gotLen)
 	}
 }
+
+// TestRMQRejectedPaymentFailsRM asserts that an RM for which the payment is
+// rejected by the DB is failed without being sent and that the RMQ is still
+// able to send other RMs.
+func TestRMQRejectedPaymentFailsRM(t *testing.T) {
+	t.Parallel()
+
+	errRejected := errors.New("rejected")
+	db := newMockRMQDB()
+	db.setRejectPush(errRejected)
+	mockID := &zkidentity.FullIdentity{}
+	q := NewRMQ(nil, clientintf.FreePaymentClient{}, mockID, db)
+	runErr := make(chan error)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { runErr <- q.Run(ctx) }()
+
+	// Bind to the server.
+	sess := newMockServerSession()
+	q.BindToSession(sess)
+
+	// Send the RM. It should fail without any calls to the server.
+	rm := mockRM("test")
+	rmErrChan := make(chan error)
+	go func() { rmErrChan <- q.SendRM(rm) }()
+	select {
+	case err := <-rmErrChan:
+		if !errors.Is(err, errRejected) {
+			t.Fatalf("unexpected error: got %v, want %v", err, errRejected)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+
+	// Approve payments and send another RM.
+	db.setRejectPush(nil)
+	go func() { rmErrChan <- q.SendRM(rm) }()
+	sess.replyNextPRPC(t, &rpc.GetInvoiceReply{})
+	sess.replyNextPRPC(t, &rpc.RouteMessageReply{})
+	select {
+	case err := <-rmErrChan:
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+
+	// Assert no run errors occurred.
+	select {
+	case err := <-runErr:
+		t.Fatal(err)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestRMQApprovesWithSessionRate asserts that RMs queued before a session is
+// bound are approved with the payment rate of the session used to send them.
+func TestRMQApprovesWithSessionRate(t *testing.T) {
+	t.Parallel()
+
+	db := newMockRMQDB()
+	mockID := &zkidentity.FullIdentity{}
+	q := NewRMQ(nil, clientintf.FreePaymentClient{}, mockID, db)
+	runErr := make(chan error)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { runErr <- q.Run(ctx) }()
+
+	// Queue the RM before binding to a session.
+	rm := mockRM("test")
+	rmErrChan := make(chan error)
+	go func() { rmErrChan <- q.SendRM(rm) }()
+	time.Sleep(50 * time.Millisecond)
+	if got := db.approvedAmounts(); len(got) != 0 {
+		t.Fatalf("unexpected approvals before session: %v", got)
+	}
+
+	// Bind to a session with a high push rate and send the RM.
+	sess := newMockServerSession()
+	sess.pushPayRate = 1e6
+	q.BindToSession(sess)
+	sess.replyNextPRPC(t, &rpc.GetInvoiceReply{})
+	sess.replyNextPRPC(t, &rpc.RouteMessageReply{})
+	select {
+	case err := <-rmErrChan:
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	case err := <-runErr:
+		t.Fatal(err)
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+
+	wantAmt := PushPaymentAmount(rm.EncryptedLen(), sess.pushPayRate,
+		sess.policy.MinPushPayment)
+	got := db.approvedAmounts()
+	if len(got) != 1 || got[0] != wantAmt {
+		t.Fatalf("unexpected approved amounts: got %v, want [%d]", got, wantAmt)
+	}
+}
@@ -74,8 +74,8 @@ type serverSession struct {
 
 	tagStackDepth  int64  // max nb of inflight requests
 	payScheme      string // negotiated between server and client on welcome
-	pushPayRate    uint64 // Push Payment rate in MAtoms/byte
-	subPayRate     uint64 // Sub payment rate in MAtoms/byte
+	pushPayRate    uint64 // Push Payment rate in MAtoms/KB
+	subPayRate     uint64 // Sub payment rate in MAtoms/sub
 	logPings       bool   // Whether to log ping/pong messages.
 	expirationDays int    // After When data is purged from server
 
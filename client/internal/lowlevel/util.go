@@ -42,6 +42,8 @@ func payloadForCmd(cmd string) (interface{}, error) {
 		p = new(rpc.PushRoutedMessage)
 	case rpc.TaggedCmdGetInvoiceReply:
 		p = new(rpc.GetInvoiceReply)
+	case rpc.TaggedCmdGetCreditBalanceReply:
+		p = new(rpc.GetCreditBalanceReply)
 	default:
 		return nil, errUnknownRPCCommand
 	}
@@ -99,6 +101,24 @@ func rvMapKeys(m map[RVID]rdzvSub) []ratchet.RVPoint {
 	return rlist
 }
 
+// excludeRVs returns the RVs of rvs that are not in exclude.
+func excludeRVs(rvs, exclude []ratchet.RVPoint) []ratchet.RVPoint {
+	res := make([]ratchet.RVPoint, 0, len(rvs))
+	for _, rv := range rvs {
+		excluded := false
+		for _, ex := range exclude {
+			if rv == ex {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			res = append(res, rv)
+		}
+	}
+	return res
+}
+
 // multiCtx returns a context that is canceled once any one of the passed
 // contexts are cancelled.
 //
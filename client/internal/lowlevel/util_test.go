@@ -318,6 +318,7 @@ type mockServerSession struct {
 	sendErrChan chan wireMsg
 	rpcChan     chan wireMsg
 	policy      clientintf.ServerPolicy
+	pushPayRate uint64
 }
 
 func newMockServerSession() *mockServerSession {
@@ -327,6 +328,7 @@ func newMockServerSession() *mockServerSession {
 		policy: clientintf.ServerPolicy{
 			MaxPushInvoices:     1,
 			PushPaymentLifetime: time.Second,
+			MinPushPayment:      rpc.MinRMPushPayment,
 		},
 	}
 }
@@ -369,7 +371,7 @@ func (m *mockServerSession) RequestClose(err error) {}
 func (m *mockServerSession) PayClient() clientintf.PaymentClient {
 	return clientintf.FreePaymentClient{}
 }
-func (m *mockServerSession) PaymentRates() (uint64, uint64)  { return 0, 0 }
+func (m *mockServerSession) PaymentRates() (uint64, uint64)  { return m.pushPayRate, 0 }
 func (m *mockServerSession) ExpirationDays() int             { return 7 }
 func (m *mockServerSession) Context() context.Context        { return context.Background() }
 func (m *mockServerSession) Policy() clientintf.ServerPolicy { return m.policy }
@@ -411,6 +413,9 @@ func (rm mockFailedRM) PaidForRM(amount, fees int64) {}
 type mockRvMgrDB struct {
 	alwaysPaid bool
 	paid       map[RVID]struct{}
+
+	mtx        sync.Mutex
+	rejectSubs error
 }
 
 func (db *mockRvMgrDB) UnpaidRVs(rvs []RVID, expirationDays int) ([]RVID, error) {
@@ -448,14 +453,28 @@ func (db *mockRvMgrDB) MarkRVUnpaid(rv RVID) error {
 	return nil
 }
 
+func (db *mockRvMgrDB) ApproveSubsPayment(rvs []RVID, amount int64) error {
+	db.mtx.Lock()
+	defer db.mtx.Unlock()
+	return db.rejectSubs
+}
+
+func (db *mockRvMgrDB) setRejectSubs(err error) {
+	db.mtx.Lock()
+	db.rejectSubs = err
+	db.mtx.Unlock()
+}
+
 type mockRMQDBEntry struct {
 	invoice string
 	date    time.Time
 }
 
 type mockRMQDB struct {
-	mtx   sync.Mutex
-	store map[RVID]mockRMQDBEntry
+	mtx        sync.Mutex
+	store      map[RVID]mockRMQDBEntry
+	rejectPush error
+	approved   []int64
 }
 
 func newMockRMQDB() *mockRMQDB {
@@ -486,3 +505,24 @@ func (m *mockRMQDB) DeleteRVPaymentAttempt(rv RVID) error {
 	delete(m.store, rv)
 	return nil
 }
+
+func (m *mockRMQDB) ApprovePushPayment(orm OutboundRM, amount int64) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	if m.rejectPush == nil {
+		m.approved = append(m.approved, amount)
+	}
+	return m.rejectPush
+}
+
+func (m *mockRMQDB) approvedAmounts() []int64 {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return append([]int64(nil), m.approved...)
+}
+
+func (m *mockRMQDB) setRejectPush(err error) {
+	m.mtx.Lock()
+	m.rejectPush = err
+	m.mtx.Unlock()
+}
@@ -177,6 +177,7 @@ func (ck *ConnKeeper) attemptWelcome(conn clientintf.Conn, kx msgReaderWriter) (
 		pt     int64  = -1
 		ps     string = ""
 		ppr    uint64 = 0
+		pprKB  uint64 = 0
 		spr    uint64 = 0
 		lnNode string = ""
 
@@ -186,6 +187,8 @@ func (ck *ConnKeeper) attemptWelcome(conn clientintf.Conn, kx msgReaderWriter) (
 
 		pushPaymentLifetime int64 = rpc.PropPushPaymentLifetimeDefault
 		maxPushInvoices     int64 = rpc.PropMaxPushInvoicesDefault
+		creditEnabled             = false
+		minPushPayment            = rpc.PropMinPushPaymentDefault
 	)
 
 	for _, v := range wmsg.Properties {
@@ -214,6 +217,20 @@ func (ck *ConnKeeper) attemptWelcome(conn clientintf.Conn, kx msgReaderWriter) (
 					err)
 			}
 
+		case rpc.PropPushPaymentRateKB:
+			pprKB, err = strconv.ParseUint(v.Value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid payment rate: %v",
+					err)
+			}
+
+		case rpc.PropMinPushPayment:
+			minPushPayment, err = strconv.ParseUint(v.Value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid min push payment: %v",
+					err)
+			}
+
 		case rpc.PropSubPaymentRate:
 			spr, err = strconv.ParseUint(v.Value, 10, 64)
 			if err != nil {
@@ -242,6 +259,9 @@ func (ck *ConnKeeper) attemptWelcome(conn clientintf.Conn, kx msgReaderWriter) (
 				return nil, fmt.Errorf("invalid max push invoices: %v", err)
 			}
 
+		case rpc.PropCreditEnabled:
+			creditEnabled = v.Value == "1"
+
 		default:
 			if v.Required {
 				errMsg := fmt.Sprintf("unhandled server property: %v", v.Key)
@@ -266,11 +286,21 @@ func (ck *ConnKeeper) attemptWelcome(conn clientintf.Conn, kx msgReaderWriter) (
 			maxClientTagDepth)
 	}
 
+	// Older servers only send the push payment rate per byte.
+	if pprKB == 0 {
+		pprKB = ppr * 1000
+	}
+
 	// Max payment rate enforcement.
-	const maxPushPaymentRate = uint64(rpc.PropPushPaymentRateDefault * 10)
-	if ppr > maxPushPaymentRate {
+	const maxPushPaymentRate = uint64(rpc.PropPushPaymentRateKBDefault * 10)
+	if pprKB > maxPushPaymentRate {
 		return nil, fmt.Errorf("push payment rate higher then maximum. got %d "+
-			"want %d", ppr, maxPushPaymentRate)
+			"want %d", pprKB, maxPushPaymentRate)
+	}
+	const maxMinPushPayment = rpc.PropMinPushPaymentDefault * 10
+	if minPushPayment > maxMinPushPayment {
+		return nil, fmt.Errorf("min push payment higher then maximum. got %d "+
+			"want %d", minPushPayment, maxMinPushPayment)
 	}
 	const maxSubPaymentRate = uint64(rpc.PropSubPaymentRateDefault * 10)
 	if spr > maxSubPaymentRate {
@@ -328,7 +358,7 @@ func (ck *ConnKeeper) attemptWelcome(conn clientintf.Conn, kx msgReaderWriter) (
 	sess := newServerSession(conn, kx, td, ck.log)
 	sess.pc = pc
 	sess.payScheme = ps
-	sess.pushPayRate = ppr
+	sess.pushPayRate = pprKB
 	sess.subPayRate = spr
 	sess.lnNode = lnNode
 	sess.pingInterval = ck.cfg.PingInterval
@@ -338,6 +368,8 @@ func (ck *ConnKeeper) attemptWelcome(conn clientintf.Conn, kx msgReaderWriter) (
 	sess.policy = clientintf.ServerPolicy{
 		PushPaymentLifetime: time.Duration(pushPaymentLifetime) * time.Second,
 		MaxPushInvoices:     int(maxPushInvoices),
+		MinPushPayment:      minPushPayment,
+		CreditEnabled:       creditEnabled && ps != rpc.PaySchemeFree,
 	}
 
 	ck.log.Infof("Connected to server %s",
@@ -44,6 +44,14 @@ type OnPostStatusRcvdNtfn func(*RemoteUser, clientintf.PostID, UserID,
 
 func (_ OnPostStatusRcvdNtfn) typ() string { return onPostStatusRcvdNtfnType }
 
+const onPostPaywallUnlockedNtfnType = "onPostPaywallUnlocked"
+
+// OnPostPaywallUnlockedNtfn is the handler for paywalled posts that had their
+// body unlocked after being paid for.
+type OnPostPaywallUnlockedNtfn func(*RemoteUser, clientintf.PostID, string)
+
+func (_ OnPostPaywallUnlockedNtfn) typ() string { return onPostPaywallUnlockedNtfnType }
+
 const onRemoteSubscriptionChangedType = "onSubChanged"
 
 // OnRemoteSubscriptionChanged is the handler for a remote user subscription
@@ -155,6 +163,108 @@ type OnGCAdminsChangedNtfn func(ru *RemoteUser, gc rpc.RMGroupList, added, remov
 
 func (_ OnGCAdminsChangedNtfn) typ() string { return onGCAdminsChangedNtfnType }
 
+const onTransferCanceledNtfnType = "onTransferCanceled"
+
+// OnTransferCanceledNtfn is the handler for file transfers canceled by the
+// remote user. The isUpload flag indicates whether the local side of the
+// canceled transfer was an upload (as opposed to a download).
+type OnTransferCanceledNtfn func(ru *RemoteUser, fid clientdb.FileID, isUpload bool)
+
+func (_ OnTransferCanceledNtfn) typ() string { return onTransferCanceledNtfnType }
+
+const onDownloadSourceAddedNtfnType = "onDownloadSourceAdded"
+
+// OnDownloadSourceAddedNtfn is the handler for remote users added as an
+// additional source of an outstanding download.
+type OnDownloadSourceAddedNtfn func(ru *RemoteUser, fid clientdb.FileID)
+
+func (_ OnDownloadSourceAddedNtfn) typ() string { return onDownloadSourceAddedNtfnType }
+
+const onCollectionsListedNtfnType = "onCollectionsListed"
+
+// OnCollectionsListedNtfn is the handler for collections listed in a reply to
+// a request to list the content of a remote user.
+type OnCollectionsListedNtfn func(ru *RemoteUser, collections []rpc.CollectionSummary)
+
+func (_ OnCollectionsListedNtfn) typ() string { return onCollectionsListedNtfnType }
+
+const onCollectionReceivedNtfnType = "onCollectionReceived"
+
+// OnCollectionReceivedNtfn is the handler for the manifest of a collection
+// received from a remote user, after a request to browse the collection.
+type OnCollectionReceivedNtfn func(ru *RemoteUser, fc rpc.FileCollection)
+
+func (_ OnCollectionReceivedNtfn) typ() string { return onCollectionReceivedNtfnType }
+
+const onCollectionDownloadStartedNtfnType = "onCollectionDownloadStarted"
+
+// OnCollectionDownloadStartedNtfn is the handler for the start of the download
+// of a collection. nbQueued is the number of files of the collection that were
+// not already being downloaded.
+type OnCollectionDownloadStartedNtfn func(ru *RemoteUser, fc rpc.FileCollection, nbQueued int)
+
+func (_ OnCollectionDownloadStartedNtfn) typ() string { return onCollectionDownloadStartedNtfnType }
+
+const onFileRangeFetchedNtfnType = "onFileRangeFetched"
+
+// OnFileRangeFetchedNtfn is the handler for ranges of files requested with
+// FetchFileRange that have been fully fetched and may be read.
+type OnFileRangeFetchedNtfn func(ru *RemoteUser, fid clientdb.FileID, r clientdb.FileRange)
+
+func (_ OnFileRangeFetchedNtfn) typ() string { return onFileRangeFetchedNtfnType }
+
+const onMsgsHeldForPostageNtfnType = "onMsgsHeldForPostage"
+
+// OnMsgsHeldForPostageNtfn is the handler for messages from remote users that
+// are held until their postage is paid. nbHeld is the total number of
+// messages currently held from the user.
+type OnMsgsHeldForPostageNtfn func(ru *RemoteUser, nbHeld int, postage uint64)
+
+func (_ OnMsgsHeldForPostageNtfn) typ() string { return onMsgsHeldForPostageNtfnType }
+
+const onPostageRequiredNtfnType = "onPostageRequired"
+
+// OnPostageRequiredNtfn is the handler for remote users that require postage
+// (in milliatoms per message) to be paid for the local client's messages to be
+// delivered to them.
+type OnPostageRequiredNtfn func(ru *RemoteUser, postage uint64, nbHeld uint32)
+
+func (_ OnPostageRequiredNtfn) typ() string { return onPostageRequiredNtfnType }
+
+const onTipReceivedNtfnType = "onTipReceived"
+
+// OnTipReceivedNtfn is the handler for tips received from remote users.
+type OnTipReceivedNtfn func(ru *RemoteUser, tip clientdb.TipHistoryEntry)
+
+func (_ OnTipReceivedNtfn) typ() string { return onTipReceivedNtfnType }
+
+const onPaymentRequestReceivedNtfnType = "onPaymentRequestReceived"
+
+// OnPaymentRequestReceivedNtfn is the handler for payment requests received
+// from remote users.
+type OnPaymentRequestReceivedNtfn func(ru *RemoteUser, req clientdb.PaymentRequest)
+
+func (_ OnPaymentRequestReceivedNtfn) typ() string { return onPaymentRequestReceivedNtfnType }
+
+const onPaymentRequestUpdatedNtfnType = "onPaymentRequestUpdated"
+
+// OnPaymentRequestUpdatedNtfn is the handler for payment requests sent by the
+// local client that were paid or declined by the remote user.
+type OnPaymentRequestUpdatedNtfn func(ru *RemoteUser, req clientdb.PaymentRequest)
+
+func (_ OnPaymentRequestUpdatedNtfn) typ() string { return onPaymentRequestUpdatedNtfnType }
+
+const onPostsSubscriptionPaymentRequiredNtfnType = "onPostsSubscriptionPaymentRequired"
+
+// OnPostsSubscriptionPaymentRequiredNtfn is the handler for remote users that
+// require payment to subscribe to (or renew the subscription to) their posts.
+// autoRenew is true if the subscription is being paid automatically.
+type OnPostsSubscriptionPaymentRequiredNtfn func(ru *RemoteUser, price rpc.PostsSubscriptionPrice, autoRenew bool)
+
+func (_ OnPostsSubscriptionPaymentRequiredNtfn) typ() string {
+	return onPostsSubscriptionPaymentRequiredNtfnType
+}
+
 // The following is used only in tests.
 
 const onTestNtfnType = "testNtfnType"
@@ -288,6 +398,11 @@ func (nmgr *NotificationManager) notifyOnPostStatusRcvd(user *RemoteUser, pid cl
 		visit(func(h OnPostStatusRcvdNtfn) { h(user, pid, statusFrom, status) })
 }
 
+func (nmgr *NotificationManager) notifyOnPostPaywallUnlocked(user *RemoteUser, pid clientintf.PostID, body string) {
+	nmgr.handlers[onPostPaywallUnlockedNtfnType].(*handlersFor[OnPostPaywallUnlockedNtfn]).
+		visit(func(h OnPostPaywallUnlockedNtfn) { h(user, pid, body) })
+}
+
 func (nmgr *NotificationManager) notifyOnRemoteSubChanged(user *RemoteUser, subscribed bool) {
 	nmgr.handlers[onRemoteSubscriptionChangedType].(*handlersFor[OnRemoteSubscriptionChangedNtfn]).
 		visit(func(h OnRemoteSubscriptionChangedNtfn) { h(user, subscribed) })
@@ -364,6 +479,67 @@ func (nmgr *NotificationManager) notifyGCAdminsChanged(ru *RemoteUser, gc rpc.RM
 		visit(func(h OnGCAdminsChangedNtfn) { h(ru, gc, added, removed) })
 }
 
+func (nmgr *NotificationManager) notifyTransferCanceled(ru *RemoteUser, fid clientdb.FileID, isUpload bool) {
+	nmgr.handlers[onTransferCanceledNtfnType].(*handlersFor[OnTransferCanceledNtfn]).
+		visit(func(h OnTransferCanceledNtfn) { h(ru, fid, isUpload) })
+}
+
+func (nmgr *NotificationManager) notifyDownloadSourceAdded(ru *RemoteUser, fid clientdb.FileID) {
+	nmgr.handlers[onDownloadSourceAddedNtfnType].(*handlersFor[OnDownloadSourceAddedNtfn]).
+		visit(func(h OnDownloadSourceAddedNtfn) { h(ru, fid) })
+}
+
+func (nmgr *NotificationManager) notifyCollectionsListed(ru *RemoteUser, collections []rpc.CollectionSummary) {
+	nmgr.handlers[onCollectionsListedNtfnType].(*handlersFor[OnCollectionsListedNtfn]).
+		visit(func(h OnCollectionsListedNtfn) { h(ru, collections) })
+}
+
+func (nmgr *NotificationManager) notifyCollectionReceived(ru *RemoteUser, fc rpc.FileCollection) {
+	nmgr.handlers[onCollectionReceivedNtfnType].(*handlersFor[OnCollectionReceivedNtfn]).
+		visit(func(h OnCollectionReceivedNtfn) { h(ru, fc) })
+}
+
+func (nmgr *NotificationManager) notifyCollectionDownloadStarted(ru *RemoteUser, fc rpc.FileCollection, nbQueued int) {
+	nmgr.handlers[onCollectionDownloadStartedNtfnType].(*handlersFor[OnCollectionDownloadStartedNtfn]).
+		visit(func(h OnCollectionDownloadStartedNtfn) { h(ru, fc, nbQueued) })
+}
+
+func (nmgr *NotificationManager) notifyFileRangeFetched(ru *RemoteUser, fid clientdb.FileID, r clientdb.FileRange) {
+	nmgr.handlers[onFileRangeFetchedNtfnType].(*handlersFor[OnFileRangeFetchedNtfn]).
+		visit(func(h OnFileRangeFetchedNtfn) { h(ru, fid, r) })
+}
+
+func (nmgr *NotificationManager) notifyMsgsHeldForPostage(ru *RemoteUser, nbHeld int, postage uint64) {
+	nmgr.handlers[onMsgsHeldForPostageNtfnType].(*handlersFor[OnMsgsHeldForPostageNtfn]).
+		visit(func(h OnMsgsHeldForPostageNtfn) { h(ru, nbHeld, postage) })
+}
+
+func (nmgr *NotificationManager) notifyPostageRequired(ru *RemoteUser, postage uint64, nbHeld uint32) {
+	nmgr.handlers[onPostageRequiredNtfnType].(*handlersFor[OnPostageRequiredNtfn]).
+		visit(func(h OnPostageRequiredNtfn) { h(ru, postage, nbHeld) })
+}
+
+func (nmgr *NotificationManager) notifyPostsSubscriptionPaymentRequired(ru *RemoteUser,
+	price rpc.PostsSubscriptionPrice, autoRenew bool) {
+	nmgr.handlers[onPostsSubscriptionPaymentRequiredNtfnType].(*handlersFor[OnPostsSubscriptionPaymentRequiredNtfn]).
+		visit(func(h OnPostsSubscriptionPaymentRequiredNtfn) { h(ru, price, autoRenew) })
+}
+
+func (nmgr *NotificationManager) notifyTipReceived(ru *RemoteUser, tip clientdb.TipHistoryEntry) {
+	nmgr.handlers[onTipReceivedNtfnType].(*handlersFor[OnTipReceivedNtfn]).
+		visit(func(h OnTipReceivedNtfn) { h(ru, tip) })
+}
+
+func (nmgr *NotificationManager) notifyPaymentRequestReceived(ru *RemoteUser, req clientdb.PaymentRequest) {
+	nmgr.handlers[onPaymentRequestReceivedNtfnType].(*handlersFor[OnPaymentRequestReceivedNtfn]).
+		visit(func(h OnPaymentRequestReceivedNtfn) { h(ru, req) })
+}
+
+func (nmgr *NotificationManager) notifyPaymentRequestUpdated(ru *RemoteUser, req clientdb.PaymentRequest) {
+	nmgr.handlers[onPaymentRequestUpdatedNtfnType].(*handlersFor[OnPaymentRequestUpdatedNtfn]).
+		visit(func(h OnPaymentRequestUpdatedNtfn) { h(ru, req) })
+}
+
 func NewNotificationManager() *NotificationManager {
 	return &NotificationManager{
 		handlers: map[string]handlersRegistry{
@@ -374,6 +550,8 @@ func NewNotificationManager() *NotificationManager {
 			onPostRcvdNtfnType:       &handlersFor[OnPostRcvdNtfn]{},
 			onPostStatusRcvdNtfnType: &handlersFor[OnPostStatusRcvdNtfn]{},
 
+			onPostPaywallUnlockedNtfnType: &handlersFor[OnPostPaywallUnlockedNtfn]{},
+
 			onGCVersionWarningType:     &handlersFor[OnGCVersionWarning]{},
 			onJoinedGCNtfnType:         &handlersFor[OnJoinedGCNtfn]{},
 			onAddedGCMembersNtfnType:   &handlersFor[OnAddedGCMembersNtfn]{},
@@ -385,6 +563,23 @@ func NewNotificationManager() *NotificationManager {
 			onGCKilledNtfnType:         &handlersFor[OnGCKilledNtfn]{},
 			onGCAdminsChangedNtfnType:  &handlersFor[OnGCAdminsChangedNtfn]{},
 
+			onTransferCanceledNtfnType:    &handlersFor[OnTransferCanceledNtfn]{},
+			onDownloadSourceAddedNtfnType: &handlersFor[OnDownloadSourceAddedNtfn]{},
+
+			onCollectionsListedNtfnType:         &handlersFor[OnCollectionsListedNtfn]{},
+			onCollectionReceivedNtfnType:        &handlersFor[OnCollectionReceivedNtfn]{},
+			onCollectionDownloadStartedNtfnType: &handlersFor[OnCollectionDownloadStartedNtfn]{},
+			onFileRangeFetchedNtfnType:          &handlersFor[OnFileRangeFetchedNtfn]{},
+
+			onMsgsHeldForPostageNtfnType: &handlersFor[OnMsgsHeldForPostageNtfn]{},
+			onPostageRequiredNtfnType:    &handlersFor[OnPostageRequiredNtfn]{},
+
+			onPostsSubscriptionPaymentRequiredNtfnType: &handlersFor[OnPostsSubscriptionPaymentRequiredNtfn]{},
+
+			onTipReceivedNtfnType:            &handlersFor[OnTipReceivedNtfn]{},
+			onPaymentRequestReceivedNtfnType: &handlersFor[OnPaymentRequestReceivedNtfn]{},
+			onPaymentRequestUpdatedNtfnType:  &handlersFor[OnPaymentRequestUpdatedNtfn]{},
+
 			onInvoiceGenFailedNtfnType:        &handlersFor[OnInvoiceGenFailedNtfn]{},
 			onRemoteSubscriptionChangedType:   &handlersFor[OnRemoteSubscriptionChangedNtfn]{},
 			onRemoteSubscriptionErrorNtfnType: &handlersFor[OnRemoteSubscriptionErrorNtfn]{},
@@ -724,8 +724,12 @@ nextAction:
 
 		case err = <-ru.sentRMChan:
 			// Completed a send. We only get errors if encryption
-			// failed on the RM or if the rmq is exiting.
-			if err != nil && !errors.Is(err, clientintf.ErrSubsysExiting) {
+			// failed on the RM, its payment was rejected or if the
+			// rmq is exiting. Rejected payments only fail the RM.
+			if errors.Is(err, lowlevel.ErrPaymentRejected{}) {
+				ru.log.Debugf("Payment to send RM rejected: %v", err)
+				err = nil
+			} else if err != nil && !errors.Is(err, clientintf.ErrSubsysExiting) {
 				ru.log.Errorf("Stopping remote user due to "+
 					"send error: %v", err)
 			} else {
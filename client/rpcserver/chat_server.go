@@ -159,6 +159,30 @@ func (c *chatServer) GCM(ctx context.Context, req *types.GCMRequest, res *types.
 	return c.c.GCMessage(gcid, req.Msg, rpc.MessageModeNormal, nil)
 }
 
+// EstimatePMCost estimates the cost of sending a PM to a user.
+func (c *chatServer) EstimatePMCost(_ context.Context, req *types.PMRequest, res *types.EstimateCostResponse) error {
+	if req.Msg == nil {
+		return fmt.Errorf("msg is nil")
+	}
+	user, err := c.c.UserByNick(req.User)
+	if err != nil {
+		return err
+	}
+	res.Milliatoms, err = c.c.EstimatePMCost(user.ID(), req.Msg.Message)
+	return err
+}
+
+// EstimateGCMCost estimates the cost of sending a message to all members of a
+// GC.
+func (c *chatServer) EstimateGCMCost(_ context.Context, req *types.GCMRequest, res *types.EstimateCostResponse) error {
+	gcid, err := c.c.GCIDByName(req.Gc)
+	if err != nil {
+		return err
+	}
+	res.Milliatoms, err = c.c.EstimateGCMessageCost(gcid, req.Msg)
+	return err
+}
+
 // GCMStream returns a stream that gets GC messages received by the client.
 func (c *chatServer) GCMStream(ctx context.Context, req *types.GCMStreamRequest, stream types.ChatService_GCMStreamServer) error {
 	id := replaymsglog.ID(req.UnackedFrom)
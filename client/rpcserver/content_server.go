@@ -0,0 +1,147 @@
+package rpcserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/companyzero/bisonrelay/client"
+	"github.com/companyzero/bisonrelay/client/clientdb"
+	"github.com/companyzero/bisonrelay/clientrpc/types"
+	"github.com/decred/slog"
+)
+
+type ContentServerCfg struct {
+	// Client should be set to the [client.Client] instance.
+	Client *client.Client
+
+	// Log should be set to the app's logger.
+	Log slog.Logger
+}
+
+type contentServer struct {
+	cfg ContentServerCfg
+	log slog.Logger
+	c   *client.Client
+}
+
+// transferTarget decodes the file and (for uploads) the user of a transfer
+// request.
+func (c *contentServer) transferTarget(req *types.TransferRequest) (clientdb.FileID, client.UserID, error) {
+	var fid clientdb.FileID
+	var uid client.UserID
+	if err := fid.FromString(req.FileId); err != nil {
+		return fid, uid, err
+	}
+	if !req.IsUpload {
+		return fid, uid, nil
+	}
+	ru, err := c.c.UserByNick(req.User)
+	if err != nil {
+		return fid, uid, err
+	}
+	return fid, ru.ID(), nil
+}
+
+func (c *contentServer) PauseTransfer(_ context.Context, req *types.TransferRequest, _ *types.TransferResponse) error {
+	fid, uid, err := c.transferTarget(req)
+	if err != nil {
+		return err
+	}
+	if req.IsUpload {
+		return c.c.PauseUpload(uid, fid)
+	}
+	return c.c.PauseDownload(fid)
+}
+
+func (c *contentServer) ResumeTransfer(_ context.Context, req *types.TransferRequest, _ *types.TransferResponse) error {
+	fid, uid, err := c.transferTarget(req)
+	if err != nil {
+		return err
+	}
+	if req.IsUpload {
+		return c.c.ResumeUpload(uid, fid)
+	}
+	return c.c.ResumeDownload(fid)
+}
+
+func (c *contentServer) CancelTransfer(_ context.Context, req *types.TransferRequest, _ *types.TransferResponse) error {
+	fid, uid, err := c.transferTarget(req)
+	if err != nil {
+		return err
+	}
+	if req.IsUpload {
+		return c.c.CancelUpload(uid, fid)
+	}
+	return c.c.CancelDownload(fid)
+}
+
+func (c *contentServer) TransfersStream(ctx context.Context, req *types.TransfersStreamRequest,
+	stream types.ContentService_TransfersStreamServer) error {
+
+	interval := time.Duration(req.Interval * int64(time.Millisecond))
+	if interval < time.Second {
+		interval = time.Second
+	}
+	for {
+		transfers, err := c.c.ListTransfers()
+		if err != nil {
+			return err
+		}
+
+		event := &types.TransfersStatus{
+			Timestamp: time.Now().Unix(),
+			Transfers: make([]*types.TransferStatus, len(transfers)),
+		}
+		for i, ts := range transfers {
+			nick, _ := c.c.UserNick(ts.UID)
+			chunkStates := make(map[uint32]string, len(ts.ChunkStates))
+			for idx, cs := range ts.ChunkStates {
+				chunkStates[uint32(idx)] = string(cs)
+			}
+			var startTime int64
+			if !ts.StartTime.IsZero() {
+				startTime = ts.StartTime.Unix()
+			}
+			event.Transfers[i] = &types.TransferStatus{
+				IsUpload:    ts.IsUpload,
+				User:        ts.UID.String(),
+				Nick:        nick,
+				FileId:      ts.FID.String(),
+				Filename:    ts.Filename,
+				Size:        ts.Size,
+				NbChunks:    uint32(ts.NbChunks),
+				Paused:      ts.Paused,
+				ChunkStates: chunkStates,
+				DoneChunks:  uint32(ts.DoneChunks),
+				DoneBytes:   ts.DoneBytes,
+				Matoms:      ts.MAtoms,
+				StartTime:   startTime,
+				Rate:        ts.Rate,
+				Eta:         int64(ts.ETA.Seconds()),
+			}
+		}
+		if err := stream.Send(event); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+var _ types.ContentServiceServer = (*contentServer)(nil)
+
+// InitContentService initializes and binds a ContentService server to the RPC
+// server.
+func (s *Server) InitContentService(cfg ContentServerCfg) error {
+	cs := &contentServer{
+		cfg: cfg,
+		log: cfg.Log,
+		c:   cfg.Client,
+	}
+	s.services.Bind("ContentService", types.ContentServiceDefn(), cs)
+	return nil
+}
@@ -2,6 +2,7 @@ package rpcserver
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/companyzero/bisonrelay/client"
 	"github.com/companyzero/bisonrelay/client/clientintf"
@@ -41,7 +42,14 @@ func (c *paymentsServer) TipUser(ctx context.Context, req *types.TipUserRequest,
 			return err
 		}
 	}
-	return c.c.TipUser(ctx, user.ID(), req.DcrAmount)
+	var post *clientintf.PostID
+	if req.Post != "" {
+		post = new(clientintf.PostID)
+		if err := post.FromString(req.Post); err != nil {
+			return fmt.Errorf("invalid post id: %v", err)
+		}
+	}
+	return c.c.TipUserWithMemo(ctx, user.ID(), req.DcrAmount, req.Memo, post)
 }
 
 var _ types.PaymentsServiceServer = (*paymentsServer)(nil)
@@ -2,7 +2,9 @@ package rpcserver
 
 import (
 	"context"
+	"fmt"
 	"sync"
+	"time"
 
 	"github.com/companyzero/bisonrelay/client"
 	"github.com/companyzero/bisonrelay/client/clientdb"
@@ -227,6 +229,114 @@ func (p *postsServer) AckReceivedPostStatus(_ context.Context, req *types.AckReq
 	return nil
 }
 
+func (p *postsServer) SearchPosts(_ context.Context, req *types.SearchPostsRequest, res *types.SearchPostsResponse) error {
+	filters := clientdb.PostSearchFilters{
+		HasAttachment: req.HasAttachment,
+	}
+	if req.Author != "" {
+		author, err := p.userIDFromString(req.Author)
+		if err != nil {
+			return err
+		}
+		filters.Author = &author
+	}
+	if req.Since > 0 {
+		filters.Since = time.Unix(req.Since, 0)
+	}
+	if req.Until > 0 {
+		filters.Until = time.Unix(req.Until, 0)
+	}
+
+	posts, err := p.c.SearchPosts(req.Query, filters)
+	if err != nil {
+		return err
+	}
+	res.Posts = make([]*types.PostSummary, len(posts))
+	for i, summ := range posts {
+		summ := summ
+		res.Posts[i] = &types.PostSummary{
+			Id:           summ.ID[:],
+			From:         summ.From[:],
+			AuthorId:     summ.AuthorID[:],
+			AuthorNick:   summ.AuthorNick,
+			Date:         summ.Date.Unix(),
+			LastStatusTs: summ.LastStatusTS.Unix(),
+			Title:        summ.Title,
+		}
+	}
+	return nil
+}
+
+func (p *postsServer) ExportFeed(_ context.Context, req *types.ExportFeedRequest, res *types.ExportFeedResponse) error {
+	opts := client.FeedExportOpts{
+		Format: client.FeedExportFormat(req.Format),
+		Dest:   req.Dest,
+		Title:  req.Title,
+	}
+	for _, s := range req.Authors {
+		author, err := p.userIDFromString(s)
+		if err != nil {
+			return err
+		}
+		opts.Authors = append(opts.Authors, author)
+	}
+
+	nbPosts, err := p.c.ExportFeed(opts)
+	if err != nil {
+		return err
+	}
+	res.NbPosts = uint32(nbPosts)
+	return nil
+}
+
+// userIDFromString decodes s as either an hex-encoded user id or the nick of
+// a known user.
+// EstimatePostShareCost estimates the cost of sharing a new post with all
+// post subscribers.
+func (p *postsServer) EstimatePostShareCost(_ context.Context, req *types.EstimatePostShareCostRequest,
+	res *types.EstimateCostResponse) error {
+
+	var err error
+	res.Milliatoms, err = p.c.EstimatePostShareCost(req.Post, req.Descr)
+	return err
+}
+
+// EstimateRelayCost estimates the cost of relaying a post to a user or to all
+// post subscribers.
+func (p *postsServer) EstimateRelayCost(_ context.Context, req *types.EstimateRelayCostRequest,
+	res *types.EstimateCostResponse) error {
+
+	from, err := p.userIDFromString(req.From)
+	if err != nil {
+		return err
+	}
+	var pid clientintf.PostID
+	if len(req.PostId) != len(pid) {
+		return fmt.Errorf("invalid post id length %d", len(req.PostId))
+	}
+	copy(pid[:], req.PostId)
+
+	var to *clientintf.UserID
+	if req.To != "" {
+		uid, err := p.userIDFromString(req.To)
+		if err != nil {
+			return err
+		}
+		to = &uid
+	}
+
+	res.Milliatoms, err = p.c.EstimateRelayCost(from, pid, to)
+	return err
+}
+
+func (p *postsServer) userIDFromString(s string) (clientintf.UserID, error) {
+	var uid clientintf.UserID
+	if err := uid.FromString(s); err == nil {
+		return uid, nil
+	}
+	return p.c.UIDByNick(s)
+}
+
 // registerOfflineMessageStorageHandlers registers the handlers for streams on
 // the client's notification manager.
 func (p *postsServer) registerOfflineMessageStorageHandlers() {
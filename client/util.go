@@ -4,11 +4,13 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/binary"
+	"fmt"
 	"sort"
 	"time"
 
 	"github.com/companyzero/bisonrelay/client/clientdb"
 	"github.com/companyzero/bisonrelay/client/internal/lowlevel"
+	"github.com/companyzero/bisonrelay/rpc"
 )
 
 // canceled returns true if the given context is done.
@@ -96,6 +98,10 @@ func (rvdb *rvManagerDBAdapter) SavePaidRVs(rvs []lowlevel.RVID) error {
 	return err
 }
 
+func (rvdb *rvManagerDBAdapter) ApproveSubsPayment(rvs []lowlevel.RVID, amount int64) error {
+	return rvdb.c.approveSubsPayment(rvs, amount)
+}
+
 func (rvdb *rvManagerDBAdapter) MarkRVUnpaid(rv lowlevel.RVID) error {
 	err := rvdb.c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
 		return rvdb.c.db.MarkRVUnpaid(tx, rv)
@@ -132,6 +138,64 @@ func (rmqdb *rmqDBAdapter) DeleteRVPaymentAttempt(rv lowlevel.RVID) error {
 	})
 }
 
+func (rmqdb *rmqDBAdapter) ApprovePushPayment(orm lowlevel.OutboundRM, amount int64) error {
+	return rmqdb.c.approvePushPayment(orm, amount)
+}
+
+// serverCreditDBAdapter is an adapter structure that satisfies the
+// ServerCreditDB interface using a client's db as backing storage.
+type serverCreditDBAdapter struct {
+	c *Client
+}
+
+func (crdb *serverCreditDBAdapter) StoreCreditPurchase(invoice string, ts time.Time) error {
+	return crdb.c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		state, err := crdb.c.db.GetServerCreditState(tx)
+		if err != nil {
+			return err
+		}
+		if state.Purchases == nil {
+			state.Purchases = make(map[string]time.Time, 1)
+		}
+		state.Purchases[invoice] = ts
+		return crdb.c.db.SetServerCreditState(tx, state)
+	})
+}
+
+func (crdb *serverCreditDBAdapter) CreditPurchases() (map[string]time.Time, error) {
+	var purchases map[string]time.Time
+	err := crdb.c.dbView(func(tx clientdb.ReadTx) error {
+		state, err := crdb.c.db.GetServerCreditState(tx)
+		purchases = state.Purchases
+		return err
+	})
+	return purchases, err
+}
+
+func (crdb *serverCreditDBAdapter) RemoveCreditPurchase(invoice string) error {
+	return crdb.c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		state, err := crdb.c.db.GetServerCreditState(tx)
+		if err != nil {
+			return err
+		}
+		delete(state.Purchases, invoice)
+		return crdb.c.db.SetServerCreditState(tx, state)
+	})
+}
+
+func (crdb *serverCreditDBAdapter) StoreCreditReceipt(receipt rpc.CreditReceipt) error {
+	return crdb.c.dbUpdate(func(tx clientdb.ReadWriteTx) error {
+		_, spid, err := crdb.c.db.ServerID(tx)
+		if err != nil {
+			return err
+		}
+		if !spid.VerifyMessage(receipt.SignedHash(), receipt.Signature) {
+			return fmt.Errorf("credit receipt has invalid server signature")
+		}
+		return crdb.c.db.RecordCreditReceipt(tx, receipt)
+	})
+}
+
 // SortedUserPayStatsIDs returns a sorted list of IDs from the passed stats
 // map, ordered by largest total payments.
 func SortedUserPayStatsIDs(stats map[UserID]clientdb.UserPayStats) []UserID {
@@ -1393,20 +1393,28 @@ func (x *ReceivedPostStatus) GetStatusFromNick() string {
 	return ""
 }
 
-// TipUserRequest is a request to tip a remote user.
-type TipUserRequest struct {
+// SearchPostsRequest is the request to search the local posts.
+type SearchPostsRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// user is the remote user nick or hex-encoded ID.
-	User string `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
-	// dcr_amount is the DCR amount to send as tip.
-	DcrAmount float64 `protobuf:"fixed64,2,opt,name=dcr_amount,json=dcrAmount,proto3" json:"dcr_amount,omitempty"`
-}
-
-func (x *TipUserRequest) Reset() {
-	*x = TipUserRequest{}
+	// query is the list of terms to search for in the title, body, author nick
+	// and comments of posts. If empty, all posts matching the filters are
+	// returned.
+	Query string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// author is the optional nick or hex-encoded ID of the author of the posts.
+	Author string `protobuf:"bytes,2,opt,name=author,proto3" json:"author,omitempty"`
+	// since is the optional unix timestamp of the earliest post to return.
+	Since int64 `protobuf:"varint,3,opt,name=since,proto3" json:"since,omitempty"`
+	// until is the optional unix timestamp of the latest post to return.
+	Until int64 `protobuf:"varint,4,opt,name=until,proto3" json:"until,omitempty"`
+	// has_attachment restricts the results to posts with an attached file.
+	HasAttachment bool `protobuf:"varint,5,opt,name=has_attachment,json=hasAttachment,proto3" json:"has_attachment,omitempty"`
+}
+
+func (x *SearchPostsRequest) Reset() {
+	*x = SearchPostsRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_clientrpc_proto_msgTypes[23]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1414,13 +1422,13 @@ func (x *TipUserRequest) Reset() {
 	}
 }
 
-func (x *TipUserRequest) String() string {
+func (x *SearchPostsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TipUserRequest) ProtoMessage() {}
+func (*SearchPostsRequest) ProtoMessage() {}
 
-func (x *TipUserRequest) ProtoReflect() protoreflect.Message {
+func (x *SearchPostsRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_clientrpc_proto_msgTypes[23]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1432,34 +1440,58 @@ func (x *TipUserRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TipUserRequest.ProtoReflect.Descriptor instead.
-func (*TipUserRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use SearchPostsRequest.ProtoReflect.Descriptor instead.
+func (*SearchPostsRequest) Descriptor() ([]byte, []int) {
 	return file_clientrpc_proto_rawDescGZIP(), []int{23}
 }
 
-func (x *TipUserRequest) GetUser() string {
+func (x *SearchPostsRequest) GetQuery() string {
 	if x != nil {
-		return x.User
+		return x.Query
 	}
 	return ""
 }
 
-func (x *TipUserRequest) GetDcrAmount() float64 {
+func (x *SearchPostsRequest) GetAuthor() string {
 	if x != nil {
-		return x.DcrAmount
+		return x.Author
+	}
+	return ""
+}
+
+func (x *SearchPostsRequest) GetSince() int64 {
+	if x != nil {
+		return x.Since
 	}
 	return 0
 }
 
-// TipUserResponse is the response to a tip user request.
-type TipUserResponse struct {
+func (x *SearchPostsRequest) GetUntil() int64 {
+	if x != nil {
+		return x.Until
+	}
+	return 0
+}
+
+func (x *SearchPostsRequest) GetHasAttachment() bool {
+	if x != nil {
+		return x.HasAttachment
+	}
+	return false
+}
+
+// SearchPostsResponse is the response to a search posts request.
+type SearchPostsResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	// posts is the list of matching posts, sorted by date.
+	Posts []*PostSummary `protobuf:"bytes,1,rep,name=posts,proto3" json:"posts,omitempty"`
 }
 
-func (x *TipUserResponse) Reset() {
-	*x = TipUserResponse{}
+func (x *SearchPostsResponse) Reset() {
+	*x = SearchPostsResponse{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_clientrpc_proto_msgTypes[24]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1467,13 +1499,13 @@ func (x *TipUserResponse) Reset() {
 	}
 }
 
-func (x *TipUserResponse) String() string {
+func (x *SearchPostsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TipUserResponse) ProtoMessage() {}
+func (*SearchPostsResponse) ProtoMessage() {}
 
-func (x *TipUserResponse) ProtoReflect() protoreflect.Message {
+func (x *SearchPostsResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_clientrpc_proto_msgTypes[24]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1485,27 +1517,37 @@ func (x *TipUserResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TipUserResponse.ProtoReflect.Descriptor instead.
-func (*TipUserResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use SearchPostsResponse.ProtoReflect.Descriptor instead.
+func (*SearchPostsResponse) Descriptor() ([]byte, []int) {
 	return file_clientrpc_proto_rawDescGZIP(), []int{24}
 }
 
-// MediateKXRequest is the request to perform a transitive KX with a given
-// user.
-type MediateKXRequest struct {
+func (x *SearchPostsResponse) GetPosts() []*PostSummary {
+	if x != nil {
+		return x.Posts
+	}
+	return nil
+}
+
+// ExportFeedRequest is the request to export posts.
+type ExportFeedRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// mediator is the nick or hex ID of the mediator user (which must already be
-	// KX'd with).
-	Mediator string `protobuf:"bytes,1,opt,name=mediator,proto3" json:"mediator,omitempty"`
-	// target is the hex ID of the target user to KX with.
-	Target string `protobuf:"bytes,2,opt,name=target,proto3" json:"target,omitempty"`
+	// format is the export format: either atom or html.
+	Format string `protobuf:"bytes,1,opt,name=format,proto3" json:"format,omitempty"`
+	// dest is the destination file (for atom) or directory (for html).
+	Dest string `protobuf:"bytes,2,opt,name=dest,proto3" json:"dest,omitempty"`
+	// authors restricts the export to posts by these users (nicks or hex ids).
+	// If empty, all posts are exported.
+	Authors []string `protobuf:"bytes,3,rep,name=authors,proto3" json:"authors,omitempty"`
+	// title is the title of the exported feed.
+	Title string `protobuf:"bytes,4,opt,name=title,proto3" json:"title,omitempty"`
 }
 
-func (x *MediateKXRequest) Reset() {
-	*x = MediateKXRequest{}
+func (x *ExportFeedRequest) Reset() {
+	*x = ExportFeedRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_clientrpc_proto_msgTypes[25]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1513,13 +1555,13 @@ func (x *MediateKXRequest) Reset() {
 	}
 }
 
-func (x *MediateKXRequest) String() string {
+func (x *ExportFeedRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MediateKXRequest) ProtoMessage() {}
+func (*ExportFeedRequest) ProtoMessage() {}
 
-func (x *MediateKXRequest) ProtoReflect() protoreflect.Message {
+func (x *ExportFeedRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_clientrpc_proto_msgTypes[25]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1531,34 +1573,51 @@ func (x *MediateKXRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MediateKXRequest.ProtoReflect.Descriptor instead.
-func (*MediateKXRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use ExportFeedRequest.ProtoReflect.Descriptor instead.
+func (*ExportFeedRequest) Descriptor() ([]byte, []int) {
 	return file_clientrpc_proto_rawDescGZIP(), []int{25}
 }
 
-func (x *MediateKXRequest) GetMediator() string {
+func (x *ExportFeedRequest) GetFormat() string {
 	if x != nil {
-		return x.Mediator
+		return x.Format
 	}
 	return ""
 }
 
-func (x *MediateKXRequest) GetTarget() string {
+func (x *ExportFeedRequest) GetDest() string {
 	if x != nil {
-		return x.Target
+		return x.Dest
 	}
 	return ""
 }
 
-// MediateKXResponse is the response to a mediate KX request.
-type MediateKXResponse struct {
+func (x *ExportFeedRequest) GetAuthors() []string {
+	if x != nil {
+		return x.Authors
+	}
+	return nil
+}
+
+func (x *ExportFeedRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+// ExportFeedResponse is the response to an export request.
+type ExportFeedResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	// nb_posts is the number of exported posts.
+	NbPosts uint32 `protobuf:"varint,1,opt,name=nb_posts,json=nbPosts,proto3" json:"nb_posts,omitempty"`
 }
 
-func (x *MediateKXResponse) Reset() {
-	*x = MediateKXResponse{}
+func (x *ExportFeedResponse) Reset() {
+	*x = ExportFeedResponse{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_clientrpc_proto_msgTypes[26]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1566,13 +1625,13 @@ func (x *MediateKXResponse) Reset() {
 	}
 }
 
-func (x *MediateKXResponse) String() string {
+func (x *ExportFeedResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MediateKXResponse) ProtoMessage() {}
+func (*ExportFeedResponse) ProtoMessage() {}
 
-func (x *MediateKXResponse) ProtoReflect() protoreflect.Message {
+func (x *ExportFeedResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_clientrpc_proto_msgTypes[26]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1584,25 +1643,33 @@ func (x *MediateKXResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MediateKXResponse.ProtoReflect.Descriptor instead.
-func (*MediateKXResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use ExportFeedResponse.ProtoReflect.Descriptor instead.
+func (*ExportFeedResponse) Descriptor() ([]byte, []int) {
 	return file_clientrpc_proto_rawDescGZIP(), []int{26}
 }
 
-// KXStreamRequest is the request sent when obtaining a stream of KX notifications.
-type KXStreamRequest struct {
+func (x *ExportFeedResponse) GetNbPosts() uint32 {
+	if x != nil {
+		return x.NbPosts
+	}
+	return 0
+}
+
+// EstimatePostShareCostRequest is a request to estimate the cost of sharing a
+// new post.
+type EstimatePostShareCostRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// unacked_from specifies to the server the sequence_id of the last completed
-	// KX. KXs completed by the server that have a higher sequence_id will be
-	// streamed back to the client.
-	UnackedFrom uint64 `protobuf:"varint,1,opt,name=unacked_from,json=unackedFrom,proto3" json:"unacked_from,omitempty"`
+	// post is the main content of the post.
+	Post string `protobuf:"bytes,1,opt,name=post,proto3" json:"post,omitempty"`
+	// descr is the optional description of the post.
+	Descr string `protobuf:"bytes,2,opt,name=descr,proto3" json:"descr,omitempty"`
 }
 
-func (x *KXStreamRequest) Reset() {
-	*x = KXStreamRequest{}
+func (x *EstimatePostShareCostRequest) Reset() {
+	*x = EstimatePostShareCostRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_clientrpc_proto_msgTypes[27]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1610,13 +1677,13 @@ func (x *KXStreamRequest) Reset() {
 	}
 }
 
-func (x *KXStreamRequest) String() string {
+func (x *EstimatePostShareCostRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*KXStreamRequest) ProtoMessage() {}
+func (*EstimatePostShareCostRequest) ProtoMessage() {}
 
-func (x *KXStreamRequest) ProtoReflect() protoreflect.Message {
+func (x *EstimatePostShareCostRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_clientrpc_proto_msgTypes[27]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1628,34 +1695,44 @@ func (x *KXStreamRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use KXStreamRequest.ProtoReflect.Descriptor instead.
-func (*KXStreamRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use EstimatePostShareCostRequest.ProtoReflect.Descriptor instead.
+func (*EstimatePostShareCostRequest) Descriptor() ([]byte, []int) {
 	return file_clientrpc_proto_rawDescGZIP(), []int{27}
 }
 
-func (x *KXStreamRequest) GetUnackedFrom() uint64 {
+func (x *EstimatePostShareCostRequest) GetPost() string {
 	if x != nil {
-		return x.UnackedFrom
+		return x.Post
 	}
-	return 0
+	return ""
 }
 
-// KXCompleted is the information about a completed KX event.
-type KXCompleted struct {
+func (x *EstimatePostShareCostRequest) GetDescr() string {
+	if x != nil {
+		return x.Descr
+	}
+	return ""
+}
+
+// EstimateRelayCostRequest is a request to estimate the cost of relaying a
+// post.
+type EstimateRelayCostRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// sequence_id is an opaque sequential ID.
-	SequenceId uint64 `protobuf:"varint,1,opt,name=sequence_id,json=sequenceId,proto3" json:"sequence_id,omitempty"`
-	// uid is the raw ID of the KX'd user.
-	Uid []byte `protobuf:"bytes,2,opt,name=uid,proto3" json:"uid,omitempty"`
-	// nick is the nick of the KX'd user.
-	Nick string `protobuf:"bytes,3,opt,name=nick,proto3" json:"nick,omitempty"`
+	// from is the nick or hex-encoded ID of the user the post was received
+	// from.
+	From string `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	// post_id is the id of the post to relay.
+	PostId []byte `protobuf:"bytes,2,opt,name=post_id,json=postId,proto3" json:"post_id,omitempty"`
+	// to is the nick or hex-encoded ID of the user to relay the post to. If
+	// empty, the estimate is for relaying the post to all post subscribers.
+	To string `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
 }
 
-func (x *KXCompleted) Reset() {
-	*x = KXCompleted{}
+func (x *EstimateRelayCostRequest) Reset() {
+	*x = EstimateRelayCostRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_clientrpc_proto_msgTypes[28]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1663,13 +1740,13 @@ func (x *KXCompleted) Reset() {
 	}
 }
 
-func (x *KXCompleted) String() string {
+func (x *EstimateRelayCostRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*KXCompleted) ProtoMessage() {}
+func (*EstimateRelayCostRequest) ProtoMessage() {}
 
-func (x *KXCompleted) ProtoReflect() protoreflect.Message {
+func (x *EstimateRelayCostRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_clientrpc_proto_msgTypes[28]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1681,124 +1758,917 @@ func (x *KXCompleted) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use KXCompleted.ProtoReflect.Descriptor instead.
-func (*KXCompleted) Descriptor() ([]byte, []int) {
+// Deprecated: Use EstimateRelayCostRequest.ProtoReflect.Descriptor instead.
+func (*EstimateRelayCostRequest) Descriptor() ([]byte, []int) {
 	return file_clientrpc_proto_rawDescGZIP(), []int{28}
 }
 
-func (x *KXCompleted) GetSequenceId() uint64 {
+func (x *EstimateRelayCostRequest) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *EstimateRelayCostRequest) GetPostId() []byte {
+	if x != nil {
+		return x.PostId
+	}
+	return nil
+}
+
+func (x *EstimateRelayCostRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+// EstimateCostResponse is the response to a cost estimation request.
+type EstimateCostResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// milliatoms is the estimated cost, not including the fees required to
+	// route the payments.
+	Milliatoms uint64 `protobuf:"varint,1,opt,name=milliatoms,proto3" json:"milliatoms,omitempty"`
+}
+
+func (x *EstimateCostResponse) Reset() {
+	*x = EstimateCostResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_clientrpc_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EstimateCostResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EstimateCostResponse) ProtoMessage() {}
+
+func (x *EstimateCostResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_clientrpc_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EstimateCostResponse.ProtoReflect.Descriptor instead.
+func (*EstimateCostResponse) Descriptor() ([]byte, []int) {
+	return file_clientrpc_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *EstimateCostResponse) GetMilliatoms() uint64 {
+	if x != nil {
+		return x.Milliatoms
+	}
+	return 0
+}
+
+// TipUserRequest is a request to tip a remote user.
+type TipUserRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// user is the remote user nick or hex-encoded ID.
+	User string `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	// dcr_amount is the DCR amount to send as tip.
+	DcrAmount float64 `protobuf:"fixed64,2,opt,name=dcr_amount,json=dcrAmount,proto3" json:"dcr_amount,omitempty"`
+	// memo is an optional memo shown to the remote user once the tip is
+	// received.
+	Memo string `protobuf:"bytes,3,opt,name=memo,proto3" json:"memo,omitempty"`
+	// post is the optional hex-encoded ID of the post of the remote user that
+	// is being tipped.
+	Post string `protobuf:"bytes,4,opt,name=post,proto3" json:"post,omitempty"`
+}
+
+func (x *TipUserRequest) Reset() {
+	*x = TipUserRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_clientrpc_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TipUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TipUserRequest) ProtoMessage() {}
+
+func (x *TipUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_clientrpc_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TipUserRequest.ProtoReflect.Descriptor instead.
+func (*TipUserRequest) Descriptor() ([]byte, []int) {
+	return file_clientrpc_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *TipUserRequest) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *TipUserRequest) GetDcrAmount() float64 {
+	if x != nil {
+		return x.DcrAmount
+	}
+	return 0
+}
+
+func (x *TipUserRequest) GetMemo() string {
+	if x != nil {
+		return x.Memo
+	}
+	return ""
+}
+
+func (x *TipUserRequest) GetPost() string {
+	if x != nil {
+		return x.Post
+	}
+	return ""
+}
+
+// TipUserResponse is the response to a tip user request.
+type TipUserResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *TipUserResponse) Reset() {
+	*x = TipUserResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_clientrpc_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TipUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TipUserResponse) ProtoMessage() {}
+
+func (x *TipUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_clientrpc_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TipUserResponse.ProtoReflect.Descriptor instead.
+func (*TipUserResponse) Descriptor() ([]byte, []int) {
+	return file_clientrpc_proto_rawDescGZIP(), []int{31}
+}
+
+// MediateKXRequest is the request to perform a transitive KX with a given
+// user.
+type MediateKXRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// mediator is the nick or hex ID of the mediator user (which must already be
+	// KX'd with).
+	Mediator string `protobuf:"bytes,1,opt,name=mediator,proto3" json:"mediator,omitempty"`
+	// target is the hex ID of the target user to KX with.
+	Target string `protobuf:"bytes,2,opt,name=target,proto3" json:"target,omitempty"`
+}
+
+func (x *MediateKXRequest) Reset() {
+	*x = MediateKXRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_clientrpc_proto_msgTypes[32]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MediateKXRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MediateKXRequest) ProtoMessage() {}
+
+func (x *MediateKXRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_clientrpc_proto_msgTypes[32]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MediateKXRequest.ProtoReflect.Descriptor instead.
+func (*MediateKXRequest) Descriptor() ([]byte, []int) {
+	return file_clientrpc_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *MediateKXRequest) GetMediator() string {
+	if x != nil {
+		return x.Mediator
+	}
+	return ""
+}
+
+func (x *MediateKXRequest) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+// MediateKXResponse is the response to a mediate KX request.
+type MediateKXResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *MediateKXResponse) Reset() {
+	*x = MediateKXResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_clientrpc_proto_msgTypes[33]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MediateKXResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MediateKXResponse) ProtoMessage() {}
+
+func (x *MediateKXResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_clientrpc_proto_msgTypes[33]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MediateKXResponse.ProtoReflect.Descriptor instead.
+func (*MediateKXResponse) Descriptor() ([]byte, []int) {
+	return file_clientrpc_proto_rawDescGZIP(), []int{33}
+}
+
+// KXStreamRequest is the request sent when obtaining a stream of KX notifications.
+type KXStreamRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// unacked_from specifies to the server the sequence_id of the last completed
+	// KX. KXs completed by the server that have a higher sequence_id will be
+	// streamed back to the client.
+	UnackedFrom uint64 `protobuf:"varint,1,opt,name=unacked_from,json=unackedFrom,proto3" json:"unacked_from,omitempty"`
+}
+
+func (x *KXStreamRequest) Reset() {
+	*x = KXStreamRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_clientrpc_proto_msgTypes[34]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *KXStreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KXStreamRequest) ProtoMessage() {}
+
+func (x *KXStreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_clientrpc_proto_msgTypes[34]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KXStreamRequest.ProtoReflect.Descriptor instead.
+func (*KXStreamRequest) Descriptor() ([]byte, []int) {
+	return file_clientrpc_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *KXStreamRequest) GetUnackedFrom() uint64 {
+	if x != nil {
+		return x.UnackedFrom
+	}
+	return 0
+}
+
+// KXCompleted is the information about a completed KX event.
+type KXCompleted struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// sequence_id is an opaque sequential ID.
+	SequenceId uint64 `protobuf:"varint,1,opt,name=sequence_id,json=sequenceId,proto3" json:"sequence_id,omitempty"`
+	// uid is the raw ID of the KX'd user.
+	Uid []byte `protobuf:"bytes,2,opt,name=uid,proto3" json:"uid,omitempty"`
+	// nick is the nick of the KX'd user.
+	Nick string `protobuf:"bytes,3,opt,name=nick,proto3" json:"nick,omitempty"`
+}
+
+func (x *KXCompleted) Reset() {
+	*x = KXCompleted{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_clientrpc_proto_msgTypes[35]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *KXCompleted) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KXCompleted) ProtoMessage() {}
+
+func (x *KXCompleted) ProtoReflect() protoreflect.Message {
+	mi := &file_clientrpc_proto_msgTypes[35]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KXCompleted.ProtoReflect.Descriptor instead.
+func (*KXCompleted) Descriptor() ([]byte, []int) {
+	return file_clientrpc_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *KXCompleted) GetSequenceId() uint64 {
+	if x != nil {
+		return x.SequenceId
+	}
+	return 0
+}
+
+func (x *KXCompleted) GetUid() []byte {
+	if x != nil {
+		return x.Uid
+	}
+	return nil
+}
+
+func (x *KXCompleted) GetNick() string {
+	if x != nil {
+		return x.Nick
+	}
+	return ""
+}
+
+// RMPrivateMessage is the network-level routed private message.
+type RMPrivateMessage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// message is the private message payload.
+	Message string `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	// mode is the message mode.
+	Mode MessageMode `protobuf:"varint,2,opt,name=mode,proto3,enum=MessageMode" json:"mode,omitempty"`
+}
+
+func (x *RMPrivateMessage) Reset() {
+	*x = RMPrivateMessage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_clientrpc_proto_msgTypes[36]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RMPrivateMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RMPrivateMessage) ProtoMessage() {}
+
+func (x *RMPrivateMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_clientrpc_proto_msgTypes[36]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RMPrivateMessage.ProtoReflect.Descriptor instead.
+func (*RMPrivateMessage) Descriptor() ([]byte, []int) {
+	return file_clientrpc_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *RMPrivateMessage) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *RMPrivateMessage) GetMode() MessageMode {
+	if x != nil {
+		return x.Mode
+	}
+	return MessageMode_MESSAGE_MODE_NORMAL
+}
+
+// RMGroupMessage is the network-level routed group message.
+type RMGroupMessage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// id is the group chat id where the message was sent.
+	Id []byte `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// generation is the internal generation of the group chat metadata when the
+	// sender sent this message.
+	Generation uint64 `protobuf:"varint,2,opt,name=generation,proto3" json:"generation,omitempty"`
+	// message is the textual content.
+	Message string `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	// mode is the mode of the message.
+	Mode MessageMode `protobuf:"varint,4,opt,name=mode,proto3,enum=MessageMode" json:"mode,omitempty"`
+}
+
+func (x *RMGroupMessage) Reset() {
+	*x = RMGroupMessage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_clientrpc_proto_msgTypes[37]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RMGroupMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RMGroupMessage) ProtoMessage() {}
+
+func (x *RMGroupMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_clientrpc_proto_msgTypes[37]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RMGroupMessage.ProtoReflect.Descriptor instead.
+func (*RMGroupMessage) Descriptor() ([]byte, []int) {
+	return file_clientrpc_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *RMGroupMessage) GetId() []byte {
+	if x != nil {
+		return x.Id
+	}
+	return nil
+}
+
+func (x *RMGroupMessage) GetGeneration() uint64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+func (x *RMGroupMessage) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *RMGroupMessage) GetMode() MessageMode {
+	if x != nil {
+		return x.Mode
+	}
+	return MessageMode_MESSAGE_MODE_NORMAL
+}
+
+// TransferRequest identifies a file transfer to control.
+type TransferRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// is_upload is true for uploads and false for downloads.
+	IsUpload bool `protobuf:"varint,1,opt,name=is_upload,json=isUpload,proto3" json:"is_upload,omitempty"`
+	// file_id is the hex-encoded ID of the file being transferred.
+	FileId string `protobuf:"bytes,2,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	// user is the nick or hex-encoded ID of the user the file is being
+	// uploaded to. Only used for uploads.
+	User string `protobuf:"bytes,3,opt,name=user,proto3" json:"user,omitempty"`
+}
+
+func (x *TransferRequest) Reset() {
+	*x = TransferRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_clientrpc_proto_msgTypes[38]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TransferRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransferRequest) ProtoMessage() {}
+
+func (x *TransferRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_clientrpc_proto_msgTypes[38]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransferRequest.ProtoReflect.Descriptor instead.
+func (*TransferRequest) Descriptor() ([]byte, []int) {
+	return file_clientrpc_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *TransferRequest) GetIsUpload() bool {
+	if x != nil {
+		return x.IsUpload
+	}
+	return false
+}
+
+func (x *TransferRequest) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *TransferRequest) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+// TransferResponse is the response to a transfer control request.
+type TransferResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *TransferResponse) Reset() {
+	*x = TransferResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_clientrpc_proto_msgTypes[39]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TransferResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransferResponse) ProtoMessage() {}
+
+func (x *TransferResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_clientrpc_proto_msgTypes[39]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransferResponse.ProtoReflect.Descriptor instead.
+func (*TransferResponse) Descriptor() ([]byte, []int) {
+	return file_clientrpc_proto_rawDescGZIP(), []int{39}
+}
+
+// TransfersStreamRequest is the request for a new stream of transfer status
+// updates.
+type TransfersStreamRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// interval is how often to send the status (in milliseconds). Intervals
+	// lower than one second are increased to one second.
+	Interval int64 `protobuf:"varint,1,opt,name=interval,proto3" json:"interval,omitempty"`
+}
+
+func (x *TransfersStreamRequest) Reset() {
+	*x = TransfersStreamRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_clientrpc_proto_msgTypes[40]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TransfersStreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransfersStreamRequest) ProtoMessage() {}
+
+func (x *TransfersStreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_clientrpc_proto_msgTypes[40]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransfersStreamRequest.ProtoReflect.Descriptor instead.
+func (*TransfersStreamRequest) Descriptor() ([]byte, []int) {
+	return file_clientrpc_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *TransfersStreamRequest) GetInterval() int64 {
+	if x != nil {
+		return x.Interval
+	}
+	return 0
+}
+
+// TransferStatus is the status of an outstanding upload or download.
+type TransferStatus struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// is_upload is true for uploads and false for downloads.
+	IsUpload bool `protobuf:"varint,1,opt,name=is_upload,json=isUpload,proto3" json:"is_upload,omitempty"`
+	// user is the hex-encoded ID of the remote user (the uploader of downloads
+	// or the user the file is being uploaded to).
+	User string `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+	// nick is the nick of the remote user.
+	Nick string `protobuf:"bytes,3,opt,name=nick,proto3" json:"nick,omitempty"`
+	// file_id is the hex-encoded ID of the file being transferred.
+	FileId string `protobuf:"bytes,4,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	// filename is the name of the file, if known.
+	Filename string `protobuf:"bytes,5,opt,name=filename,proto3" json:"filename,omitempty"`
+	// size is the size of the file in bytes, if known.
+	Size uint64 `protobuf:"varint,6,opt,name=size,proto3" json:"size,omitempty"`
+	// nb_chunks is the number of chunks of the file, if known.
+	NbChunks uint32 `protobuf:"varint,7,opt,name=nb_chunks,json=nbChunks,proto3" json:"nb_chunks,omitempty"`
+	// paused is true if the transfer is paused.
+	Paused bool `protobuf:"varint,8,opt,name=paused,proto3" json:"paused,omitempty"`
+	// chunk_states is the state of each chunk with a known state, keyed by
+	// chunk index.
+	ChunkStates map[uint32]string `protobuf:"bytes,9,rep,name=chunk_states,json=chunkStates,proto3" json:"chunk_states,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// done_chunks is the number of chunks already transferred.
+	DoneChunks uint32 `protobuf:"varint,10,opt,name=done_chunks,json=doneChunks,proto3" json:"done_chunks,omitempty"`
+	// done_bytes is the number of bytes already transferred.
+	DoneBytes uint64 `protobuf:"varint,11,opt,name=done_bytes,json=doneBytes,proto3" json:"done_bytes,omitempty"`
+	// matoms is the amount paid (downloads) or received (uploads) for the
+	// chunks of the file, in milliatoms.
+	Matoms int64 `protobuf:"varint,12,opt,name=matoms,proto3" json:"matoms,omitempty"`
+	// start_time is the unix timestamp of the start of the transfer.
+	StartTime int64 `protobuf:"varint,13,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	// rate is the average transfer rate, in bytes per second.
+	Rate float64 `protobuf:"fixed64,14,opt,name=rate,proto3" json:"rate,omitempty"`
+	// eta is the estimated number of seconds to complete the transfer, or zero
+	// if unknown.
+	Eta int64 `protobuf:"varint,15,opt,name=eta,proto3" json:"eta,omitempty"`
+}
+
+func (x *TransferStatus) Reset() {
+	*x = TransferStatus{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_clientrpc_proto_msgTypes[41]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TransferStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransferStatus) ProtoMessage() {}
+
+func (x *TransferStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_clientrpc_proto_msgTypes[41]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransferStatus.ProtoReflect.Descriptor instead.
+func (*TransferStatus) Descriptor() ([]byte, []int) {
+	return file_clientrpc_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *TransferStatus) GetIsUpload() bool {
+	if x != nil {
+		return x.IsUpload
+	}
+	return false
+}
+
+func (x *TransferStatus) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *TransferStatus) GetNick() string {
+	if x != nil {
+		return x.Nick
+	}
+	return ""
+}
+
+func (x *TransferStatus) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *TransferStatus) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *TransferStatus) GetSize() uint64 {
 	if x != nil {
-		return x.SequenceId
+		return x.Size
 	}
 	return 0
 }
 
-func (x *KXCompleted) GetUid() []byte {
+func (x *TransferStatus) GetNbChunks() uint32 {
 	if x != nil {
-		return x.Uid
+		return x.NbChunks
 	}
-	return nil
+	return 0
 }
 
-func (x *KXCompleted) GetNick() string {
+func (x *TransferStatus) GetPaused() bool {
 	if x != nil {
-		return x.Nick
+		return x.Paused
 	}
-	return ""
+	return false
 }
 
-// RMPrivateMessage is the network-level routed private message.
-type RMPrivateMessage struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
-
-	// message is the private message payload.
-	Message string `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
-	// mode is the message mode.
-	Mode MessageMode `protobuf:"varint,2,opt,name=mode,proto3,enum=MessageMode" json:"mode,omitempty"`
+func (x *TransferStatus) GetChunkStates() map[uint32]string {
+	if x != nil {
+		return x.ChunkStates
+	}
+	return nil
 }
 
-func (x *RMPrivateMessage) Reset() {
-	*x = RMPrivateMessage{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_clientrpc_proto_msgTypes[29]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
+func (x *TransferStatus) GetDoneChunks() uint32 {
+	if x != nil {
+		return x.DoneChunks
 	}
+	return 0
 }
 
-func (x *RMPrivateMessage) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *TransferStatus) GetDoneBytes() uint64 {
+	if x != nil {
+		return x.DoneBytes
+	}
+	return 0
 }
 
-func (*RMPrivateMessage) ProtoMessage() {}
-
-func (x *RMPrivateMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_clientrpc_proto_msgTypes[29]
-	if protoimpl.UnsafeEnabled && x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+func (x *TransferStatus) GetMatoms() int64 {
+	if x != nil {
+		return x.Matoms
 	}
-	return mi.MessageOf(x)
+	return 0
 }
 
-// Deprecated: Use RMPrivateMessage.ProtoReflect.Descriptor instead.
-func (*RMPrivateMessage) Descriptor() ([]byte, []int) {
-	return file_clientrpc_proto_rawDescGZIP(), []int{29}
+func (x *TransferStatus) GetStartTime() int64 {
+	if x != nil {
+		return x.StartTime
+	}
+	return 0
 }
 
-func (x *RMPrivateMessage) GetMessage() string {
+func (x *TransferStatus) GetRate() float64 {
 	if x != nil {
-		return x.Message
+		return x.Rate
 	}
-	return ""
+	return 0
 }
 
-func (x *RMPrivateMessage) GetMode() MessageMode {
+func (x *TransferStatus) GetEta() int64 {
 	if x != nil {
-		return x.Mode
+		return x.Eta
 	}
-	return MessageMode_MESSAGE_MODE_NORMAL
+	return 0
 }
 
-// RMGroupMessage is the network-level routed group message.
-type RMGroupMessage struct {
+// TransfersStatus is the status of all outstanding transfers.
+type TransfersStatus struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// id is the group chat id where the message was sent.
-	Id []byte `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	// generation is the internal generation of the group chat metadata when the
-	// sender sent this message.
-	Generation uint64 `protobuf:"varint,2,opt,name=generation,proto3" json:"generation,omitempty"`
-	// message is the textual content.
-	Message string `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
-	// mode is the mode of the message.
-	Mode MessageMode `protobuf:"varint,4,opt,name=mode,proto3,enum=MessageMode" json:"mode,omitempty"`
+	// timestamp is the unix timestamp of when the status was collected.
+	Timestamp int64 `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// transfers is the list of outstanding transfers.
+	Transfers []*TransferStatus `protobuf:"bytes,2,rep,name=transfers,proto3" json:"transfers,omitempty"`
 }
 
-func (x *RMGroupMessage) Reset() {
-	*x = RMGroupMessage{}
+func (x *TransfersStatus) Reset() {
+	*x = TransfersStatus{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_clientrpc_proto_msgTypes[30]
+		mi := &file_clientrpc_proto_msgTypes[42]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *RMGroupMessage) String() string {
+func (x *TransfersStatus) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RMGroupMessage) ProtoMessage() {}
+func (*TransfersStatus) ProtoMessage() {}
 
-func (x *RMGroupMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_clientrpc_proto_msgTypes[30]
+func (x *TransfersStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_clientrpc_proto_msgTypes[42]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1809,37 +2679,23 @@ func (x *RMGroupMessage) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RMGroupMessage.ProtoReflect.Descriptor instead.
-func (*RMGroupMessage) Descriptor() ([]byte, []int) {
-	return file_clientrpc_proto_rawDescGZIP(), []int{30}
-}
-
-func (x *RMGroupMessage) GetId() []byte {
-	if x != nil {
-		return x.Id
-	}
-	return nil
+// Deprecated: Use TransfersStatus.ProtoReflect.Descriptor instead.
+func (*TransfersStatus) Descriptor() ([]byte, []int) {
+	return file_clientrpc_proto_rawDescGZIP(), []int{42}
 }
 
-func (x *RMGroupMessage) GetGeneration() uint64 {
+func (x *TransfersStatus) GetTimestamp() int64 {
 	if x != nil {
-		return x.Generation
+		return x.Timestamp
 	}
 	return 0
 }
 
-func (x *RMGroupMessage) GetMessage() string {
-	if x != nil {
-		return x.Message
-	}
-	return ""
-}
-
-func (x *RMGroupMessage) GetMode() MessageMode {
+func (x *TransfersStatus) GetTransfers() []*TransferStatus {
 	if x != nil {
-		return x.Mode
+		return x.Transfers
 	}
-	return MessageMode_MESSAGE_MODE_NORMAL
+	return nil
 }
 
 // PostMetadata is the network-level post data.
@@ -1857,7 +2713,7 @@ type PostMetadata struct {
 func (x *PostMetadata) Reset() {
 	*x = PostMetadata{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_clientrpc_proto_msgTypes[31]
+		mi := &file_clientrpc_proto_msgTypes[43]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1870,7 +2726,7 @@ func (x *PostMetadata) String() string {
 func (*PostMetadata) ProtoMessage() {}
 
 func (x *PostMetadata) ProtoReflect() protoreflect.Message {
-	mi := &file_clientrpc_proto_msgTypes[31]
+	mi := &file_clientrpc_proto_msgTypes[43]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1883,7 +2739,7 @@ func (x *PostMetadata) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PostMetadata.ProtoReflect.Descriptor instead.
 func (*PostMetadata) Descriptor() ([]byte, []int) {
-	return file_clientrpc_proto_rawDescGZIP(), []int{31}
+	return file_clientrpc_proto_rawDescGZIP(), []int{43}
 }
 
 func (x *PostMetadata) GetVersion() uint64 {
@@ -1919,7 +2775,7 @@ type PostMetadataStatus struct {
 func (x *PostMetadataStatus) Reset() {
 	*x = PostMetadataStatus{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_clientrpc_proto_msgTypes[32]
+		mi := &file_clientrpc_proto_msgTypes[44]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1932,7 +2788,7 @@ func (x *PostMetadataStatus) String() string {
 func (*PostMetadataStatus) ProtoMessage() {}
 
 func (x *PostMetadataStatus) ProtoReflect() protoreflect.Message {
-	mi := &file_clientrpc_proto_msgTypes[32]
+	mi := &file_clientrpc_proto_msgTypes[44]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1945,7 +2801,7 @@ func (x *PostMetadataStatus) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PostMetadataStatus.ProtoReflect.Descriptor instead.
 func (*PostMetadataStatus) Descriptor() ([]byte, []int) {
-	return file_clientrpc_proto_rawDescGZIP(), []int{32}
+	return file_clientrpc_proto_rawDescGZIP(), []int{44}
 }
 
 func (x *PostMetadataStatus) GetVersion() uint64 {
@@ -2089,134 +2945,260 @@ var file_clientrpc_proto_rawDesc = []byte{
 	0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x28, 0x0a, 0x10, 0x73,
 	0x74, 0x61, 0x74, 0x75, 0x73, 0x5f, 0x66, 0x72, 0x6f, 0x6d, 0x5f, 0x6e, 0x69, 0x63, 0x6b, 0x18,
 	0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x46, 0x72, 0x6f,
-	0x6d, 0x4e, 0x69, 0x63, 0x6b, 0x22, 0x43, 0x0a, 0x0e, 0x54, 0x69, 0x70, 0x55, 0x73, 0x65, 0x72,
+	0x6d, 0x4e, 0x69, 0x63, 0x6b, 0x22, 0x95, 0x01, 0x0a, 0x12, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68,
+	0x50, 0x6f, 0x73, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05,
+	0x71, 0x75, 0x65, 0x72, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x71, 0x75, 0x65,
+	0x72, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x69,
+	0x6e, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x73, 0x69, 0x6e, 0x63, 0x65,
+	0x12, 0x14, 0x0a, 0x05, 0x75, 0x6e, 0x74, 0x69, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x05, 0x75, 0x6e, 0x74, 0x69, 0x6c, 0x12, 0x25, 0x0a, 0x0e, 0x68, 0x61, 0x73, 0x5f, 0x61, 0x74,
+	0x74, 0x61, 0x63, 0x68, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d,
+	0x68, 0x61, 0x73, 0x41, 0x74, 0x74, 0x61, 0x63, 0x68, 0x6d, 0x65, 0x6e, 0x74, 0x22, 0x39, 0x0a,
+	0x13, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x50, 0x6f, 0x73, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x22, 0x0a, 0x05, 0x70, 0x6f, 0x73, 0x74, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x50, 0x6f, 0x73, 0x74, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72,
+	0x79, 0x52, 0x05, 0x70, 0x6f, 0x73, 0x74, 0x73, 0x22, 0x6f, 0x0a, 0x11, 0x45, 0x78, 0x70, 0x6f,
+	0x72, 0x74, 0x46, 0x65, 0x65, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a,
+	0x06, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x66,
+	0x6f, 0x72, 0x6d, 0x61, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x65, 0x73, 0x74, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x64, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x75, 0x74,
+	0x68, 0x6f, 0x72, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x61, 0x75, 0x74, 0x68,
+	0x6f, 0x72, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x22, 0x2f, 0x0a, 0x12, 0x45, 0x78, 0x70,
+	0x6f, 0x72, 0x74, 0x46, 0x65, 0x65, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x19, 0x0a, 0x08, 0x6e, 0x62, 0x5f, 0x70, 0x6f, 0x73, 0x74, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x07, 0x6e, 0x62, 0x50, 0x6f, 0x73, 0x74, 0x73, 0x22, 0x48, 0x0a, 0x1c, 0x45, 0x73,
+	0x74, 0x69, 0x6d, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x73, 0x74, 0x53, 0x68, 0x61, 0x72, 0x65, 0x43,
+	0x6f, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x6f,
+	0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x6f, 0x73, 0x74, 0x12, 0x14,
+	0x0a, 0x05, 0x64, 0x65, 0x73, 0x63, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x64,
+	0x65, 0x73, 0x63, 0x72, 0x22, 0x57, 0x0a, 0x18, 0x45, 0x73, 0x74, 0x69, 0x6d, 0x61, 0x74, 0x65,
+	0x52, 0x65, 0x6c, 0x61, 0x79, 0x43, 0x6f, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x12, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x66, 0x72, 0x6f, 0x6d, 0x12, 0x17, 0x0a, 0x07, 0x70, 0x6f, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x70, 0x6f, 0x73, 0x74, 0x49, 0x64, 0x12, 0x0e, 0x0a,
+	0x02, 0x74, 0x6f, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x74, 0x6f, 0x22, 0x36, 0x0a,
+	0x14, 0x45, 0x73, 0x74, 0x69, 0x6d, 0x61, 0x74, 0x65, 0x43, 0x6f, 0x73, 0x74, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x6d, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x74,
+	0x6f, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x6d, 0x69, 0x6c, 0x6c, 0x69,
+	0x61, 0x74, 0x6f, 0x6d, 0x73, 0x22, 0x6b, 0x0a, 0x0e, 0x54, 0x69, 0x70, 0x55, 0x73, 0x65, 0x72,
 	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x72, 0x18,
 	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x12, 0x1d, 0x0a, 0x0a, 0x64,
 	0x63, 0x72, 0x5f, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52,
-	0x09, 0x64, 0x63, 0x72, 0x41, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0x11, 0x0a, 0x0f, 0x54, 0x69,
-	0x70, 0x55, 0x73, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x46, 0x0a,
-	0x10, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x74, 0x65, 0x4b, 0x58, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x12, 0x1a, 0x0a, 0x08, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x74, 0x6f, 0x72, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x08, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x16, 0x0a,
-	0x06, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x74,
-	0x61, 0x72, 0x67, 0x65, 0x74, 0x22, 0x13, 0x0a, 0x11, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x74, 0x65,
-	0x4b, 0x58, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x34, 0x0a, 0x0f, 0x4b, 0x58,
-	0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x21, 0x0a,
-	0x0c, 0x75, 0x6e, 0x61, 0x63, 0x6b, 0x65, 0x64, 0x5f, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x04, 0x52, 0x0b, 0x75, 0x6e, 0x61, 0x63, 0x6b, 0x65, 0x64, 0x46, 0x72, 0x6f, 0x6d,
-	0x22, 0x54, 0x0a, 0x0b, 0x4b, 0x58, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x12,
-	0x1f, 0x0a, 0x0b, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x49, 0x64,
-	0x12, 0x10, 0x0a, 0x03, 0x75, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x75,
-	0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x69, 0x63, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x04, 0x6e, 0x69, 0x63, 0x6b, 0x22, 0x4e, 0x0a, 0x10, 0x52, 0x4d, 0x50, 0x72, 0x69, 0x76,
-	0x61, 0x74, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65,
-	0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73,
-	0x73, 0x61, 0x67, 0x65, 0x12, 0x20, 0x0a, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x0e, 0x32, 0x0c, 0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x4d, 0x6f, 0x64, 0x65,
-	0x52, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x22, 0x7c, 0x0a, 0x0e, 0x52, 0x4d, 0x47, 0x72, 0x6f, 0x75,
-	0x70, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x0c, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x67, 0x65, 0x6e, 0x65,
-	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x67, 0x65,
-	0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73,
-	0x61, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61,
-	0x67, 0x65, 0x12, 0x20, 0x0a, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e,
-	0x32, 0x0c, 0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x04,
-	0x6d, 0x6f, 0x64, 0x65, 0x22, 0xa6, 0x01, 0x0a, 0x0c, 0x50, 0x6f, 0x73, 0x74, 0x4d, 0x65, 0x74,
-	0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12,
-	0x3d, 0x0a, 0x0a, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x18, 0x02, 0x20,
-	0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x50, 0x6f, 0x73, 0x74, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61,
-	0x74, 0x61, 0x2e, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x45, 0x6e, 0x74,
-	0x72, 0x79, 0x52, 0x0a, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x1a, 0x3d,
-	0x0a, 0x0f, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72,
-	0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
-	0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xda, 0x01,
-	0x0a, 0x12, 0x50, 0x6f, 0x73, 0x74, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x53, 0x74,
-	0x61, 0x74, 0x75, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x12,
-	0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x66, 0x72,
-	0x6f, 0x6d, 0x12, 0x12, 0x0a, 0x04, 0x6c, 0x69, 0x6e, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x04, 0x6c, 0x69, 0x6e, 0x6b, 0x12, 0x43, 0x0a, 0x0a, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62,
-	0x75, 0x74, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x50, 0x6f, 0x73,
-	0x74, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x2e,
-	0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52,
-	0x0a, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x1a, 0x3d, 0x0a, 0x0f, 0x41,
-	0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10,
-	0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79,
-	0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x2a, 0x3b, 0x0a, 0x0b, 0x4d, 0x65,
-	0x73, 0x73, 0x61, 0x67, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x17, 0x0a, 0x13, 0x4d, 0x45, 0x53,
-	0x53, 0x41, 0x47, 0x45, 0x5f, 0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x4e, 0x4f, 0x52, 0x4d, 0x41, 0x4c,
-	0x10, 0x00, 0x12, 0x13, 0x0a, 0x0f, 0x4d, 0x45, 0x53, 0x53, 0x41, 0x47, 0x45, 0x5f, 0x4d, 0x4f,
-	0x44, 0x45, 0x5f, 0x4d, 0x45, 0x10, 0x01, 0x32, 0x7d, 0x0a, 0x0e, 0x56, 0x65, 0x72, 0x73, 0x69,
-	0x6f, 0x6e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x2c, 0x0a, 0x07, 0x56, 0x65, 0x72,
-	0x73, 0x69, 0x6f, 0x6e, 0x12, 0x0f, 0x2e, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x52,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3d, 0x0a, 0x0f, 0x4b, 0x65, 0x65, 0x70, 0x61,
-	0x6c, 0x69, 0x76, 0x65, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x17, 0x2e, 0x4b, 0x65, 0x65,
-	0x70, 0x61, 0x6c, 0x69, 0x76, 0x65, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x1a, 0x0f, 0x2e, 0x4b, 0x65, 0x65, 0x70, 0x61, 0x6c, 0x69, 0x76, 0x65, 0x45,
-	0x76, 0x65, 0x6e, 0x74, 0x30, 0x01, 0x32, 0x95, 0x03, 0x0a, 0x0b, 0x43, 0x68, 0x61, 0x74, 0x53,
-	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x1d, 0x0a, 0x02, 0x50, 0x4d, 0x12, 0x0a, 0x2e, 0x50,
-	0x4d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0b, 0x2e, 0x50, 0x4d, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2b, 0x0a, 0x08, 0x50, 0x4d, 0x53, 0x74, 0x72, 0x65, 0x61,
-	0x6d, 0x12, 0x10, 0x2e, 0x50, 0x4d, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x1a, 0x0b, 0x2e, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x50, 0x4d,
-	0x30, 0x01, 0x12, 0x2a, 0x0a, 0x0d, 0x41, 0x63, 0x6b, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65,
-	0x64, 0x50, 0x4d, 0x12, 0x0b, 0x2e, 0x41, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x0c, 0x2e, 0x41, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x20,
-	0x0a, 0x03, 0x47, 0x43, 0x4d, 0x12, 0x0b, 0x2e, 0x47, 0x43, 0x4d, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x1a, 0x0c, 0x2e, 0x47, 0x43, 0x4d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x12, 0x30, 0x0a, 0x09, 0x47, 0x43, 0x4d, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x11, 0x2e,
-	0x47, 0x43, 0x4d, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x0e, 0x2e, 0x47, 0x43, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x4d, 0x73, 0x67,
-	0x30, 0x01, 0x12, 0x2b, 0x0a, 0x0e, 0x41, 0x63, 0x6b, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65,
-	0x64, 0x47, 0x43, 0x4d, 0x12, 0x0b, 0x2e, 0x41, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x0c, 0x2e, 0x41, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
-	0x32, 0x0a, 0x09, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x74, 0x65, 0x4b, 0x58, 0x12, 0x11, 0x2e, 0x4d,
-	0x65, 0x64, 0x69, 0x61, 0x74, 0x65, 0x4b, 0x58, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
-	0x12, 0x2e, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x74, 0x65, 0x4b, 0x58, 0x52, 0x65, 0x73, 0x70, 0x6f,
-	0x6e, 0x73, 0x65, 0x12, 0x2c, 0x0a, 0x08, 0x4b, 0x58, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12,
-	0x10, 0x2e, 0x4b, 0x58, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x0c, 0x2e, 0x4b, 0x58, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x30,
-	0x01, 0x12, 0x2b, 0x0a, 0x0e, 0x41, 0x63, 0x6b, 0x4b, 0x58, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65,
-	0x74, 0x65, 0x64, 0x12, 0x0b, 0x2e, 0x41, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x0c, 0x2e, 0x41, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x32, 0x84,
-	0x03, 0x0a, 0x0c, 0x50, 0x6f, 0x73, 0x74, 0x73, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12,
-	0x47, 0x0a, 0x10, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x54, 0x6f, 0x50, 0x6f,
-	0x73, 0x74, 0x73, 0x12, 0x18, 0x2e, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x54,
-	0x6f, 0x50, 0x6f, 0x73, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e,
-	0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x54, 0x6f, 0x50, 0x6f, 0x73, 0x74, 0x73,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4d, 0x0a, 0x12, 0x55, 0x6e, 0x73, 0x75,
-	0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x54, 0x6f, 0x50, 0x6f, 0x73, 0x74, 0x73, 0x12, 0x1a,
-	0x2e, 0x55, 0x6e, 0x73, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x54, 0x6f, 0x50, 0x6f,
-	0x73, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x55, 0x6e, 0x73,
-	0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x54, 0x6f, 0x50, 0x6f, 0x73, 0x74, 0x73, 0x52,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x33, 0x0a, 0x0b, 0x50, 0x6f, 0x73, 0x74, 0x73,
-	0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x13, 0x2e, 0x50, 0x6f, 0x73, 0x74, 0x73, 0x53, 0x74,
-	0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0d, 0x2e, 0x52, 0x65,
-	0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x50, 0x6f, 0x73, 0x74, 0x30, 0x01, 0x12, 0x2c, 0x0a, 0x0f,
-	0x41, 0x63, 0x6b, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x50, 0x6f, 0x73, 0x74, 0x12,
-	0x0b, 0x2e, 0x41, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0c, 0x2e, 0x41,
-	0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x45, 0x0a, 0x11, 0x50, 0x6f,
-	0x73, 0x74, 0x73, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12,
-	0x19, 0x2e, 0x50, 0x6f, 0x73, 0x74, 0x73, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x53, 0x74, 0x72,
-	0x65, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x52, 0x65, 0x63,
-	0x65, 0x69, 0x76, 0x65, 0x64, 0x50, 0x6f, 0x73, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x30,
-	0x01, 0x12, 0x32, 0x0a, 0x15, 0x41, 0x63, 0x6b, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64,
-	0x50, 0x6f, 0x73, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x0b, 0x2e, 0x41, 0x63, 0x6b,
+	0x09, 0x64, 0x63, 0x72, 0x41, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6d, 0x65,
+	0x6d, 0x6f, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6d, 0x65, 0x6d, 0x6f, 0x12, 0x12,
+	0x0a, 0x04, 0x70, 0x6f, 0x73, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x6f,
+	0x73, 0x74, 0x22, 0x11, 0x0a, 0x0f, 0x54, 0x69, 0x70, 0x55, 0x73, 0x65, 0x72, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x46, 0x0a, 0x10, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x74, 0x65,
+	0x4b, 0x58, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x6d, 0x65, 0x64,
+	0x69, 0x61, 0x74, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6d, 0x65, 0x64,
+	0x69, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x22, 0x13, 0x0a,
+	0x11, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x74, 0x65, 0x4b, 0x58, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x22, 0x34, 0x0a, 0x0f, 0x4b, 0x58, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x75, 0x6e, 0x61, 0x63, 0x6b, 0x65, 0x64,
+	0x5f, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x75, 0x6e, 0x61,
+	0x63, 0x6b, 0x65, 0x64, 0x46, 0x72, 0x6f, 0x6d, 0x22, 0x54, 0x0a, 0x0b, 0x4b, 0x58, 0x43, 0x6f,
+	0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x65, 0x71, 0x75, 0x65,
+	0x6e, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x73, 0x65,
+	0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x49, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x69, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x75, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x69,
+	0x63, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x69, 0x63, 0x6b, 0x22, 0x4e,
+	0x0a, 0x10, 0x52, 0x4d, 0x50, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x20, 0x0a, 0x04,
+	0x6d, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0c, 0x2e, 0x4d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x22, 0x7c,
+	0x0a, 0x0e, 0x52, 0x4d, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x02, 0x69, 0x64,
+	0x12, 0x1e, 0x0a, 0x0a, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x20, 0x0a, 0x04, 0x6d, 0x6f,
+	0x64, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0c, 0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x22, 0x5b, 0x0a, 0x0f,
+	0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x1b, 0x0a, 0x09, 0x69, 0x73, 0x5f, 0x75, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x08, 0x69, 0x73, 0x55, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x12, 0x17, 0x0a, 0x07,
+	0x66, 0x69, 0x6c, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x66,
+	0x69, 0x6c, 0x65, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x72, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x22, 0x12, 0x0a, 0x10, 0x54, 0x72, 0x61,
+	0x6e, 0x73, 0x66, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x34, 0x0a,
+	0x16, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x73, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x69, 0x6e, 0x74, 0x65, 0x72,
+	0x76, 0x61, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x69, 0x6e, 0x74, 0x65, 0x72,
+	0x76, 0x61, 0x6c, 0x22, 0xf5, 0x03, 0x0a, 0x0e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72,
+	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x69, 0x73, 0x5f, 0x75, 0x70, 0x6c,
+	0x6f, 0x61, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x69, 0x73, 0x55, 0x70, 0x6c,
+	0x6f, 0x61, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x69, 0x63, 0x6b, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x69, 0x63, 0x6b, 0x12, 0x17, 0x0a, 0x07, 0x66,
+	0x69, 0x6c, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x66, 0x69,
+	0x6c, 0x65, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x6e, 0x61, 0x6d, 0x65,
+	0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04,
+	0x73, 0x69, 0x7a, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x6e, 0x62, 0x5f, 0x63, 0x68, 0x75, 0x6e, 0x6b,
+	0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x6e, 0x62, 0x43, 0x68, 0x75, 0x6e, 0x6b,
+	0x73, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x61, 0x75, 0x73, 0x65, 0x64, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x06, 0x70, 0x61, 0x75, 0x73, 0x65, 0x64, 0x12, 0x43, 0x0a, 0x0c, 0x63, 0x68, 0x75,
+	0x6e, 0x6b, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x65, 0x73, 0x18, 0x09, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x20, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x2e, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x53, 0x74, 0x61, 0x74, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x52, 0x0b, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x53, 0x74, 0x61, 0x74, 0x65, 0x73, 0x12, 0x1f,
+	0x0a, 0x0b, 0x64, 0x6f, 0x6e, 0x65, 0x5f, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x73, 0x18, 0x0a, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x0a, 0x64, 0x6f, 0x6e, 0x65, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x73, 0x12,
+	0x1d, 0x0a, 0x0a, 0x64, 0x6f, 0x6e, 0x65, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x0b, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x09, 0x64, 0x6f, 0x6e, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x16,
+	0x0a, 0x06, 0x6d, 0x61, 0x74, 0x6f, 0x6d, 0x73, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06,
+	0x6d, 0x61, 0x74, 0x6f, 0x6d, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f,
+	0x74, 0x69, 0x6d, 0x65, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x73, 0x74, 0x61, 0x72,
+	0x74, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x61, 0x74, 0x65, 0x18, 0x0e, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x04, 0x72, 0x61, 0x74, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x65, 0x74, 0x61,
+	0x18, 0x0f, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x65, 0x74, 0x61, 0x1a, 0x3e, 0x0a, 0x10, 0x43,
+	0x68, 0x75, 0x6e, 0x6b, 0x53, 0x74, 0x61, 0x74, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12,
+	0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x6b, 0x65,
+	0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x5e, 0x0a, 0x0f, 0x54,
+	0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x73, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1c,
+	0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x2d, 0x0a, 0x09,
+	0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x0f, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x52, 0x09, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x73, 0x22, 0xa6, 0x01, 0x0a, 0x0c,
+	0x50, 0x6f, 0x73, 0x74, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x18, 0x0a, 0x07,
+	0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x76,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x3d, 0x0a, 0x0a, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62,
+	0x75, 0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x50, 0x6f, 0x73,
+	0x74, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x2e, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62,
+	0x75, 0x74, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0a, 0x61, 0x74, 0x74, 0x72, 0x69,
+	0x62, 0x75, 0x74, 0x65, 0x73, 0x1a, 0x3d, 0x0a, 0x0f, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75,
+	0x74, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x3a, 0x02, 0x38, 0x01, 0x22, 0xda, 0x01, 0x0a, 0x12, 0x50, 0x6f, 0x73, 0x74, 0x4d, 0x65, 0x74,
+	0x61, 0x64, 0x61, 0x74, 0x61, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x76,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x76, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x12, 0x0a, 0x04, 0x6c, 0x69, 0x6e,
+	0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6c, 0x69, 0x6e, 0x6b, 0x12, 0x43, 0x0a,
+	0x0a, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x23, 0x2e, 0x50, 0x6f, 0x73, 0x74, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61,
+	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x2e, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65,
+	0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0a, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74,
+	0x65, 0x73, 0x1a, 0x3d, 0x0a, 0x0f, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38,
+	0x01, 0x2a, 0x3b, 0x0a, 0x0b, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x4d, 0x6f, 0x64, 0x65,
+	0x12, 0x17, 0x0a, 0x13, 0x4d, 0x45, 0x53, 0x53, 0x41, 0x47, 0x45, 0x5f, 0x4d, 0x4f, 0x44, 0x45,
+	0x5f, 0x4e, 0x4f, 0x52, 0x4d, 0x41, 0x4c, 0x10, 0x00, 0x12, 0x13, 0x0a, 0x0f, 0x4d, 0x45, 0x53,
+	0x53, 0x41, 0x47, 0x45, 0x5f, 0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x4d, 0x45, 0x10, 0x01, 0x32, 0x7d,
+	0x0a, 0x0e, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
+	0x12, 0x2c, 0x0a, 0x07, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x0f, 0x2e, 0x56, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x56,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3d,
+	0x0a, 0x0f, 0x4b, 0x65, 0x65, 0x70, 0x61, 0x6c, 0x69, 0x76, 0x65, 0x53, 0x74, 0x72, 0x65, 0x61,
+	0x6d, 0x12, 0x17, 0x2e, 0x4b, 0x65, 0x65, 0x70, 0x61, 0x6c, 0x69, 0x76, 0x65, 0x53, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0f, 0x2e, 0x4b, 0x65, 0x65,
+	0x70, 0x61, 0x6c, 0x69, 0x76, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x30, 0x01, 0x32, 0x81, 0x04,
+	0x0a, 0x0b, 0x43, 0x68, 0x61, 0x74, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x1d, 0x0a,
+	0x02, 0x50, 0x4d, 0x12, 0x0a, 0x2e, 0x50, 0x4d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x0b, 0x2e, 0x50, 0x4d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2b, 0x0a, 0x08,
+	0x50, 0x4d, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x10, 0x2e, 0x50, 0x4d, 0x53, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0b, 0x2e, 0x52, 0x65, 0x63,
+	0x65, 0x69, 0x76, 0x65, 0x64, 0x50, 0x4d, 0x30, 0x01, 0x12, 0x2a, 0x0a, 0x0d, 0x41, 0x63, 0x6b,
+	0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x50, 0x4d, 0x12, 0x0b, 0x2e, 0x41, 0x63, 0x6b,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0c, 0x2e, 0x41, 0x63, 0x6b, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x20, 0x0a, 0x03, 0x47, 0x43, 0x4d, 0x12, 0x0b, 0x2e, 0x47,
+	0x43, 0x4d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0c, 0x2e, 0x47, 0x43, 0x4d, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x30, 0x0a, 0x09, 0x47, 0x43, 0x4d, 0x53, 0x74,
+	0x72, 0x65, 0x61, 0x6d, 0x12, 0x11, 0x2e, 0x47, 0x43, 0x4d, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0e, 0x2e, 0x47, 0x43, 0x52, 0x65, 0x63, 0x65,
+	0x69, 0x76, 0x65, 0x64, 0x4d, 0x73, 0x67, 0x30, 0x01, 0x12, 0x2b, 0x0a, 0x0e, 0x41, 0x63, 0x6b,
+	0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x47, 0x43, 0x4d, 0x12, 0x0b, 0x2e, 0x41, 0x63,
+	0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0c, 0x2e, 0x41, 0x63, 0x6b, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x32, 0x0a, 0x09, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x74,
+	0x65, 0x4b, 0x58, 0x12, 0x11, 0x2e, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x74, 0x65, 0x4b, 0x58, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x74, 0x65,
+	0x4b, 0x58, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2c, 0x0a, 0x08, 0x4b, 0x58,
+	0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x10, 0x2e, 0x4b, 0x58, 0x53, 0x74, 0x72, 0x65, 0x61,
+	0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0c, 0x2e, 0x4b, 0x58, 0x43, 0x6f, 0x6d,
+	0x70, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x30, 0x01, 0x12, 0x2b, 0x0a, 0x0e, 0x41, 0x63, 0x6b, 0x4b,
+	0x58, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x12, 0x0b, 0x2e, 0x41, 0x63, 0x6b,
 	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0c, 0x2e, 0x41, 0x63, 0x6b, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x32, 0x3f, 0x0a, 0x0f, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74,
-	0x73, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x2c, 0x0a, 0x07, 0x54, 0x69, 0x70, 0x55,
-	0x73, 0x65, 0x72, 0x12, 0x0f, 0x2e, 0x54, 0x69, 0x70, 0x55, 0x73, 0x65, 0x72, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x54, 0x69, 0x70, 0x55, 0x73, 0x65, 0x72, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x34, 0x5a, 0x32, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
-	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x6e, 0x79, 0x7a, 0x65, 0x72, 0x6f,
-	0x2f, 0x62, 0x69, 0x73, 0x63, 0x6f, 0x6e, 0x72, 0x65, 0x6c, 0x61, 0x79, 0x2f, 0x63, 0x6c, 0x69,
-	0x65, 0x6e, 0x74, 0x72, 0x70, 0x63, 0x2f, 0x74, 0x79, 0x70, 0x65, 0x73, 0x62, 0x06, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x33,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x33, 0x0a, 0x0e, 0x45, 0x73, 0x74, 0x69, 0x6d, 0x61, 0x74,
+	0x65, 0x50, 0x4d, 0x43, 0x6f, 0x73, 0x74, 0x12, 0x0a, 0x2e, 0x50, 0x4d, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x45, 0x73, 0x74, 0x69, 0x6d, 0x61, 0x74, 0x65, 0x43, 0x6f,
+	0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x35, 0x0a, 0x0f, 0x45, 0x73,
+	0x74, 0x69, 0x6d, 0x61, 0x74, 0x65, 0x47, 0x43, 0x4d, 0x43, 0x6f, 0x73, 0x74, 0x12, 0x0b, 0x2e,
+	0x47, 0x43, 0x4d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x45, 0x73, 0x74,
+	0x69, 0x6d, 0x61, 0x74, 0x65, 0x43, 0x6f, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x32, 0x8b, 0x05, 0x0a, 0x0c, 0x50, 0x6f, 0x73, 0x74, 0x73, 0x53, 0x65, 0x72, 0x76, 0x69,
+	0x63, 0x65, 0x12, 0x47, 0x0a, 0x10, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x54,
+	0x6f, 0x50, 0x6f, 0x73, 0x74, 0x73, 0x12, 0x18, 0x2e, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69,
+	0x62, 0x65, 0x54, 0x6f, 0x50, 0x6f, 0x73, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x19, 0x2e, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x54, 0x6f, 0x50, 0x6f,
+	0x73, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4d, 0x0a, 0x12, 0x55,
+	0x6e, 0x73, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x54, 0x6f, 0x50, 0x6f, 0x73, 0x74,
+	0x73, 0x12, 0x1a, 0x2e, 0x55, 0x6e, 0x73, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x54,
+	0x6f, 0x50, 0x6f, 0x73, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e,
+	0x55, 0x6e, 0x73, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x54, 0x6f, 0x50, 0x6f, 0x73,
+	0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x33, 0x0a, 0x0b, 0x50, 0x6f,
+	0x73, 0x74, 0x73, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x13, 0x2e, 0x50, 0x6f, 0x73, 0x74,
+	0x73, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0d,
+	0x2e, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x50, 0x6f, 0x73, 0x74, 0x30, 0x01, 0x12,
+	0x2c, 0x0a, 0x0f, 0x41, 0x63, 0x6b, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x50, 0x6f,
+	0x73, 0x74, 0x12, 0x0b, 0x2e, 0x41, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x0c, 0x2e, 0x41, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x45, 0x0a,
+	0x11, 0x50, 0x6f, 0x73, 0x74, 0x73, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x53, 0x74, 0x72, 0x65,
+	0x61, 0x6d, 0x12, 0x19, 0x2e, 0x50, 0x6f, 0x73, 0x74, 0x73, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e,
+	0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x50, 0x6f, 0x73, 0x74, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x30, 0x01, 0x12, 0x32, 0x0a, 0x15, 0x41, 0x63, 0x6b, 0x52, 0x65, 0x63, 0x65, 0x69,
+	0x76, 0x65, 0x64, 0x50, 0x6f, 0x73, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x0b, 0x2e,
+	0x41, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0c, 0x2e, 0x41, 0x63, 0x6b,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x38, 0x0a, 0x0b, 0x53, 0x65, 0x61, 0x72,
+	0x63, 0x68, 0x50, 0x6f, 0x73, 0x74, 0x73, 0x12, 0x13, 0x2e, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68,
+	0x50, 0x6f, 0x73, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x53,
+	0x65, 0x61, 0x72, 0x63, 0x68, 0x50, 0x6f, 0x73, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x35, 0x0a, 0x0a, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x46, 0x65, 0x65, 0x64,
+	0x12, 0x12, 0x2e, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x46, 0x65, 0x65, 0x64, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x46, 0x65, 0x65,
+	0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4d, 0x0a, 0x15, 0x45, 0x73, 0x74,
+	0x69, 0x6d, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x73, 0x74, 0x53, 0x68, 0x61, 0x72, 0x65, 0x43, 0x6f,
+	0x73, 0x74, 0x12, 0x1d, 0x2e, 0x45, 0x73, 0x74, 0x69, 0x6d, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x73,
+	0x74, 0x53, 0x68, 0x61, 0x72, 0x65, 0x43, 0x6f, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x15, 0x2e, 0x45, 0x73, 0x74, 0x69, 0x6d, 0x61, 0x74, 0x65, 0x43, 0x6f, 0x73, 0x74,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x45, 0x0a, 0x11, 0x45, 0x73, 0x74, 0x69,
+	0x6d, 0x61, 0x74, 0x65, 0x52, 0x65, 0x6c, 0x61, 0x79, 0x43, 0x6f, 0x73, 0x74, 0x12, 0x19, 0x2e,
+	0x45, 0x73, 0x74, 0x69, 0x6d, 0x61, 0x74, 0x65, 0x52, 0x65, 0x6c, 0x61, 0x79, 0x43, 0x6f, 0x73,
+	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x45, 0x73, 0x74, 0x69, 0x6d,
+	0x61, 0x74, 0x65, 0x43, 0x6f, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x32,
+	0x3f, 0x0a, 0x0f, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x53, 0x65, 0x72, 0x76, 0x69,
+	0x63, 0x65, 0x12, 0x2c, 0x0a, 0x07, 0x54, 0x69, 0x70, 0x55, 0x73, 0x65, 0x72, 0x12, 0x0f, 0x2e,
+	0x54, 0x69, 0x70, 0x55, 0x73, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x10,
+	0x2e, 0x54, 0x69, 0x70, 0x55, 0x73, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x32, 0xf4, 0x01, 0x0a, 0x0e, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x53, 0x65, 0x72, 0x76,
+	0x69, 0x63, 0x65, 0x12, 0x34, 0x0a, 0x0d, 0x50, 0x61, 0x75, 0x73, 0x65, 0x54, 0x72, 0x61, 0x6e,
+	0x73, 0x66, 0x65, 0x72, 0x12, 0x10, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65,
+	0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x35, 0x0a, 0x0e, 0x52, 0x65, 0x73,
+	0x75, 0x6d, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x12, 0x10, 0x2e, 0x54, 0x72,
+	0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e,
+	0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x35, 0x0a, 0x0e, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66,
+	0x65, 0x72, 0x12, 0x10, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3e, 0x0a, 0x0f, 0x54, 0x72, 0x61, 0x6e, 0x73,
+	0x66, 0x65, 0x72, 0x73, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x17, 0x2e, 0x54, 0x72, 0x61,
+	0x6e, 0x73, 0x66, 0x65, 0x72, 0x73, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x73, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x30, 0x01, 0x42, 0x34, 0x5a, 0x32, 0x67, 0x69, 0x74, 0x68, 0x75,
+	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x6e, 0x79, 0x7a, 0x65, 0x72,
+	0x6f, 0x2f, 0x62, 0x69, 0x73, 0x63, 0x6f, 0x6e, 0x72, 0x65, 0x6c, 0x61, 0x79, 0x2f, 0x63, 0x6c,
+	0x69, 0x65, 0x6e, 0x74, 0x72, 0x70, 0x63, 0x2f, 0x74, 0x79, 0x70, 0x65, 0x73, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -2232,97 +3214,133 @@ func file_clientrpc_proto_rawDescGZIP() []byte {
 }
 
 var file_clientrpc_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_clientrpc_proto_msgTypes = make([]protoimpl.MessageInfo, 35)
+var file_clientrpc_proto_msgTypes = make([]protoimpl.MessageInfo, 48)
 var file_clientrpc_proto_goTypes = []interface{}{
-	(MessageMode)(0),                   // 0: MessageMode
-	(*VersionRequest)(nil),             // 1: VersionRequest
-	(*VersionResponse)(nil),            // 2: VersionResponse
-	(*KeepaliveStreamRequest)(nil),     // 3: KeepaliveStreamRequest
-	(*KeepaliveEvent)(nil),             // 4: KeepaliveEvent
-	(*AckRequest)(nil),                 // 5: AckRequest
-	(*AckResponse)(nil),                // 6: AckResponse
-	(*PMRequest)(nil),                  // 7: PMRequest
-	(*PMResponse)(nil),                 // 8: PMResponse
-	(*PMStreamRequest)(nil),            // 9: PMStreamRequest
-	(*ReceivedPM)(nil),                 // 10: ReceivedPM
-	(*GCMRequest)(nil),                 // 11: GCMRequest
-	(*GCMResponse)(nil),                // 12: GCMResponse
-	(*GCMStreamRequest)(nil),           // 13: GCMStreamRequest
-	(*GCReceivedMsg)(nil),              // 14: GCReceivedMsg
-	(*SubscribeToPostsRequest)(nil),    // 15: SubscribeToPostsRequest
-	(*SubscribeToPostsResponse)(nil),   // 16: SubscribeToPostsResponse
-	(*UnsubscribeToPostsRequest)(nil),  // 17: UnsubscribeToPostsRequest
-	(*UnsubscribeToPostsResponse)(nil), // 18: UnsubscribeToPostsResponse
-	(*PostSummary)(nil),                // 19: PostSummary
-	(*PostsStreamRequest)(nil),         // 20: PostsStreamRequest
-	(*ReceivedPost)(nil),               // 21: ReceivedPost
-	(*PostsStatusStreamRequest)(nil),   // 22: PostsStatusStreamRequest
-	(*ReceivedPostStatus)(nil),         // 23: ReceivedPostStatus
-	(*TipUserRequest)(nil),             // 24: TipUserRequest
-	(*TipUserResponse)(nil),            // 25: TipUserResponse
-	(*MediateKXRequest)(nil),           // 26: MediateKXRequest
-	(*MediateKXResponse)(nil),          // 27: MediateKXResponse
-	(*KXStreamRequest)(nil),            // 28: KXStreamRequest
-	(*KXCompleted)(nil),                // 29: KXCompleted
-	(*RMPrivateMessage)(nil),           // 30: RMPrivateMessage
-	(*RMGroupMessage)(nil),             // 31: RMGroupMessage
-	(*PostMetadata)(nil),               // 32: PostMetadata
-	(*PostMetadataStatus)(nil),         // 33: PostMetadataStatus
-	nil,                                // 34: PostMetadata.AttributesEntry
-	nil,                                // 35: PostMetadataStatus.AttributesEntry
+	(MessageMode)(0),                     // 0: MessageMode
+	(*VersionRequest)(nil),               // 1: VersionRequest
+	(*VersionResponse)(nil),              // 2: VersionResponse
+	(*KeepaliveStreamRequest)(nil),       // 3: KeepaliveStreamRequest
+	(*KeepaliveEvent)(nil),               // 4: KeepaliveEvent
+	(*AckRequest)(nil),                   // 5: AckRequest
+	(*AckResponse)(nil),                  // 6: AckResponse
+	(*PMRequest)(nil),                    // 7: PMRequest
+	(*PMResponse)(nil),                   // 8: PMResponse
+	(*PMStreamRequest)(nil),              // 9: PMStreamRequest
+	(*ReceivedPM)(nil),                   // 10: ReceivedPM
+	(*GCMRequest)(nil),                   // 11: GCMRequest
+	(*GCMResponse)(nil),                  // 12: GCMResponse
+	(*GCMStreamRequest)(nil),             // 13: GCMStreamRequest
+	(*GCReceivedMsg)(nil),                // 14: GCReceivedMsg
+	(*SubscribeToPostsRequest)(nil),      // 15: SubscribeToPostsRequest
+	(*SubscribeToPostsResponse)(nil),     // 16: SubscribeToPostsResponse
+	(*UnsubscribeToPostsRequest)(nil),    // 17: UnsubscribeToPostsRequest
+	(*UnsubscribeToPostsResponse)(nil),   // 18: UnsubscribeToPostsResponse
+	(*PostSummary)(nil),                  // 19: PostSummary
+	(*PostsStreamRequest)(nil),           // 20: PostsStreamRequest
+	(*ReceivedPost)(nil),                 // 21: ReceivedPost
+	(*PostsStatusStreamRequest)(nil),     // 22: PostsStatusStreamRequest
+	(*ReceivedPostStatus)(nil),           // 23: ReceivedPostStatus
+	(*SearchPostsRequest)(nil),           // 24: SearchPostsRequest
+	(*SearchPostsResponse)(nil),          // 25: SearchPostsResponse
+	(*ExportFeedRequest)(nil),            // 26: ExportFeedRequest
+	(*ExportFeedResponse)(nil),           // 27: ExportFeedResponse
+	(*EstimatePostShareCostRequest)(nil), // 28: EstimatePostShareCostRequest
+	(*EstimateRelayCostRequest)(nil),     // 29: EstimateRelayCostRequest
+	(*EstimateCostResponse)(nil),         // 30: EstimateCostResponse
+	(*TipUserRequest)(nil),               // 31: TipUserRequest
+	(*TipUserResponse)(nil),              // 32: TipUserResponse
+	(*MediateKXRequest)(nil),             // 33: MediateKXRequest
+	(*MediateKXResponse)(nil),            // 34: MediateKXResponse
+	(*KXStreamRequest)(nil),              // 35: KXStreamRequest
+	(*KXCompleted)(nil),                  // 36: KXCompleted
+	(*RMPrivateMessage)(nil),             // 37: RMPrivateMessage
+	(*RMGroupMessage)(nil),               // 38: RMGroupMessage
+	(*TransferRequest)(nil),              // 39: TransferRequest
+	(*TransferResponse)(nil),             // 40: TransferResponse
+	(*TransfersStreamRequest)(nil),       // 41: TransfersStreamRequest
+	(*TransferStatus)(nil),               // 42: TransferStatus
+	(*TransfersStatus)(nil),              // 43: TransfersStatus
+	(*PostMetadata)(nil),                 // 44: PostMetadata
+	(*PostMetadataStatus)(nil),           // 45: PostMetadataStatus
+	nil,                                  // 46: TransferStatus.ChunkStatesEntry
+	nil,                                  // 47: PostMetadata.AttributesEntry
+	nil,                                  // 48: PostMetadataStatus.AttributesEntry
 }
 var file_clientrpc_proto_depIdxs = []int32{
-	30, // 0: PMRequest.msg:type_name -> RMPrivateMessage
-	30, // 1: ReceivedPM.msg:type_name -> RMPrivateMessage
-	31, // 2: GCReceivedMsg.msg:type_name -> RMGroupMessage
+	37, // 0: PMRequest.msg:type_name -> RMPrivateMessage
+	37, // 1: ReceivedPM.msg:type_name -> RMPrivateMessage
+	38, // 2: GCReceivedMsg.msg:type_name -> RMGroupMessage
 	19, // 3: ReceivedPost.summary:type_name -> PostSummary
-	32, // 4: ReceivedPost.post:type_name -> PostMetadata
-	33, // 5: ReceivedPostStatus.status:type_name -> PostMetadataStatus
-	0,  // 6: RMPrivateMessage.mode:type_name -> MessageMode
-	0,  // 7: RMGroupMessage.mode:type_name -> MessageMode
-	34, // 8: PostMetadata.attributes:type_name -> PostMetadata.AttributesEntry
-	35, // 9: PostMetadataStatus.attributes:type_name -> PostMetadataStatus.AttributesEntry
-	1,  // 10: VersionService.Version:input_type -> VersionRequest
-	3,  // 11: VersionService.KeepaliveStream:input_type -> KeepaliveStreamRequest
-	7,  // 12: ChatService.PM:input_type -> PMRequest
-	9,  // 13: ChatService.PMStream:input_type -> PMStreamRequest
-	5,  // 14: ChatService.AckReceivedPM:input_type -> AckRequest
-	11, // 15: ChatService.GCM:input_type -> GCMRequest
-	13, // 16: ChatService.GCMStream:input_type -> GCMStreamRequest
-	5,  // 17: ChatService.AckReceivedGCM:input_type -> AckRequest
-	26, // 18: ChatService.MediateKX:input_type -> MediateKXRequest
-	28, // 19: ChatService.KXStream:input_type -> KXStreamRequest
-	5,  // 20: ChatService.AckKXCompleted:input_type -> AckRequest
-	15, // 21: PostsService.SubscribeToPosts:input_type -> SubscribeToPostsRequest
-	17, // 22: PostsService.UnsubscribeToPosts:input_type -> UnsubscribeToPostsRequest
-	20, // 23: PostsService.PostsStream:input_type -> PostsStreamRequest
-	5,  // 24: PostsService.AckReceivedPost:input_type -> AckRequest
-	22, // 25: PostsService.PostsStatusStream:input_type -> PostsStatusStreamRequest
-	5,  // 26: PostsService.AckReceivedPostStatus:input_type -> AckRequest
-	24, // 27: PaymentsService.TipUser:input_type -> TipUserRequest
-	2,  // 28: VersionService.Version:output_type -> VersionResponse
-	4,  // 29: VersionService.KeepaliveStream:output_type -> KeepaliveEvent
-	8,  // 30: ChatService.PM:output_type -> PMResponse
-	10, // 31: ChatService.PMStream:output_type -> ReceivedPM
-	6,  // 32: ChatService.AckReceivedPM:output_type -> AckResponse
-	12, // 33: ChatService.GCM:output_type -> GCMResponse
-	14, // 34: ChatService.GCMStream:output_type -> GCReceivedMsg
-	6,  // 35: ChatService.AckReceivedGCM:output_type -> AckResponse
-	27, // 36: ChatService.MediateKX:output_type -> MediateKXResponse
-	29, // 37: ChatService.KXStream:output_type -> KXCompleted
-	6,  // 38: ChatService.AckKXCompleted:output_type -> AckResponse
-	16, // 39: PostsService.SubscribeToPosts:output_type -> SubscribeToPostsResponse
-	18, // 40: PostsService.UnsubscribeToPosts:output_type -> UnsubscribeToPostsResponse
-	21, // 41: PostsService.PostsStream:output_type -> ReceivedPost
-	6,  // 42: PostsService.AckReceivedPost:output_type -> AckResponse
-	23, // 43: PostsService.PostsStatusStream:output_type -> ReceivedPostStatus
-	6,  // 44: PostsService.AckReceivedPostStatus:output_type -> AckResponse
-	25, // 45: PaymentsService.TipUser:output_type -> TipUserResponse
-	28, // [28:46] is the sub-list for method output_type
-	10, // [10:28] is the sub-list for method input_type
-	10, // [10:10] is the sub-list for extension type_name
-	10, // [10:10] is the sub-list for extension extendee
-	0,  // [0:10] is the sub-list for field type_name
+	44, // 4: ReceivedPost.post:type_name -> PostMetadata
+	45, // 5: ReceivedPostStatus.status:type_name -> PostMetadataStatus
+	19, // 6: SearchPostsResponse.posts:type_name -> PostSummary
+	0,  // 7: RMPrivateMessage.mode:type_name -> MessageMode
+	0,  // 8: RMGroupMessage.mode:type_name -> MessageMode
+	46, // 9: TransferStatus.chunk_states:type_name -> TransferStatus.ChunkStatesEntry
+	42, // 10: TransfersStatus.transfers:type_name -> TransferStatus
+	47, // 11: PostMetadata.attributes:type_name -> PostMetadata.AttributesEntry
+	48, // 12: PostMetadataStatus.attributes:type_name -> PostMetadataStatus.AttributesEntry
+	1,  // 13: VersionService.Version:input_type -> VersionRequest
+	3,  // 14: VersionService.KeepaliveStream:input_type -> KeepaliveStreamRequest
+	7,  // 15: ChatService.PM:input_type -> PMRequest
+	9,  // 16: ChatService.PMStream:input_type -> PMStreamRequest
+	5,  // 17: ChatService.AckReceivedPM:input_type -> AckRequest
+	11, // 18: ChatService.GCM:input_type -> GCMRequest
+	13, // 19: ChatService.GCMStream:input_type -> GCMStreamRequest
+	5,  // 20: ChatService.AckReceivedGCM:input_type -> AckRequest
+	33, // 21: ChatService.MediateKX:input_type -> MediateKXRequest
+	35, // 22: ChatService.KXStream:input_type -> KXStreamRequest
+	5,  // 23: ChatService.AckKXCompleted:input_type -> AckRequest
+	7,  // 24: ChatService.EstimatePMCost:input_type -> PMRequest
+	11, // 25: ChatService.EstimateGCMCost:input_type -> GCMRequest
+	15, // 26: PostsService.SubscribeToPosts:input_type -> SubscribeToPostsRequest
+	17, // 27: PostsService.UnsubscribeToPosts:input_type -> UnsubscribeToPostsRequest
+	20, // 28: PostsService.PostsStream:input_type -> PostsStreamRequest
+	5,  // 29: PostsService.AckReceivedPost:input_type -> AckRequest
+	22, // 30: PostsService.PostsStatusStream:input_type -> PostsStatusStreamRequest
+	5,  // 31: PostsService.AckReceivedPostStatus:input_type -> AckRequest
+	24, // 32: PostsService.SearchPosts:input_type -> SearchPostsRequest
+	26, // 33: PostsService.ExportFeed:input_type -> ExportFeedRequest
+	28, // 34: PostsService.EstimatePostShareCost:input_type -> EstimatePostShareCostRequest
+	29, // 35: PostsService.EstimateRelayCost:input_type -> EstimateRelayCostRequest
+	31, // 36: PaymentsService.TipUser:input_type -> TipUserRequest
+	39, // 37: ContentService.PauseTransfer:input_type -> TransferRequest
+	39, // 38: ContentService.ResumeTransfer:input_type -> TransferRequest
+	39, // 39: ContentService.CancelTransfer:input_type -> TransferRequest
+	41, // 40: ContentService.TransfersStream:input_type -> TransfersStreamRequest
+	2,  // 41: VersionService.Version:output_type -> VersionResponse
+	4,  // 42: VersionService.KeepaliveStream:output_type -> KeepaliveEvent
+	8,  // 43: ChatService.PM:output_type -> PMResponse
+	10, // 44: ChatService.PMStream:output_type -> ReceivedPM
+	6,  // 45: ChatService.AckReceivedPM:output_type -> AckResponse
+	12, // 46: ChatService.GCM:output_type -> GCMResponse
+	14, // 47: ChatService.GCMStream:output_type -> GCReceivedMsg
+	6,  // 48: ChatService.AckReceivedGCM:output_type -> AckResponse
+	34, // 49: ChatService.MediateKX:output_type -> MediateKXResponse
+	36, // 50: ChatService.KXStream:output_type -> KXCompleted
+	6,  // 51: ChatService.AckKXCompleted:output_type -> AckResponse
+	30, // 52: ChatService.EstimatePMCost:output_type -> EstimateCostResponse
+	30, // 53: ChatService.EstimateGCMCost:output_type -> EstimateCostResponse
+	16, // 54: PostsService.SubscribeToPosts:output_type -> SubscribeToPostsResponse
+	18, // 55: PostsService.UnsubscribeToPosts:output_type -> UnsubscribeToPostsResponse
+	21, // 56: PostsService.PostsStream:output_type -> ReceivedPost
+	6,  // 57: PostsService.AckReceivedPost:output_type -> AckResponse
+	23, // 58: PostsService.PostsStatusStream:output_type -> ReceivedPostStatus
+	6,  // 59: PostsService.AckReceivedPostStatus:output_type -> AckResponse
+	25, // 60: PostsService.SearchPosts:output_type -> SearchPostsResponse
+	27, // 61: PostsService.ExportFeed:output_type -> ExportFeedResponse
+	30, // 62: PostsService.EstimatePostShareCost:output_type -> EstimateCostResponse
+	30, // 63: PostsService.EstimateRelayCost:output_type -> EstimateCostResponse
+	32, // 64: PaymentsService.TipUser:output_type -> TipUserResponse
+	40, // 65: ContentService.PauseTransfer:output_type -> TransferResponse
+	40, // 66: ContentService.ResumeTransfer:output_type -> TransferResponse
+	40, // 67: ContentService.CancelTransfer:output_type -> TransferResponse
+	43, // 68: ContentService.TransfersStream:output_type -> TransfersStatus
+	41, // [41:69] is the sub-list for method output_type
+	13, // [13:41] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
 }
 
 func init() { file_clientrpc_proto_init() }
@@ -2608,7 +3626,7 @@ func file_clientrpc_proto_init() {
 			}
 		}
 		file_clientrpc_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*TipUserRequest); i {
+			switch v := v.(*SearchPostsRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2620,7 +3638,7 @@ func file_clientrpc_proto_init() {
 			}
 		}
 		file_clientrpc_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*TipUserResponse); i {
+			switch v := v.(*SearchPostsResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2632,7 +3650,7 @@ func file_clientrpc_proto_init() {
 			}
 		}
 		file_clientrpc_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*MediateKXRequest); i {
+			switch v := v.(*ExportFeedRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2644,7 +3662,7 @@ func file_clientrpc_proto_init() {
 			}
 		}
 		file_clientrpc_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*MediateKXResponse); i {
+			switch v := v.(*ExportFeedResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2656,7 +3674,7 @@ func file_clientrpc_proto_init() {
 			}
 		}
 		file_clientrpc_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*KXStreamRequest); i {
+			switch v := v.(*EstimatePostShareCostRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2668,7 +3686,7 @@ func file_clientrpc_proto_init() {
 			}
 		}
 		file_clientrpc_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*KXCompleted); i {
+			switch v := v.(*EstimateRelayCostRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2680,7 +3698,7 @@ func file_clientrpc_proto_init() {
 			}
 		}
 		file_clientrpc_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*RMPrivateMessage); i {
+			switch v := v.(*EstimateCostResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2692,7 +3710,7 @@ func file_clientrpc_proto_init() {
 			}
 		}
 		file_clientrpc_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*RMGroupMessage); i {
+			switch v := v.(*TipUserRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2704,7 +3722,7 @@ func file_clientrpc_proto_init() {
 			}
 		}
 		file_clientrpc_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*PostMetadata); i {
+			switch v := v.(*TipUserResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2716,6 +3734,150 @@ func file_clientrpc_proto_init() {
 			}
 		}
 		file_clientrpc_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MediateKXRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_clientrpc_proto_msgTypes[33].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MediateKXResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_clientrpc_proto_msgTypes[34].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*KXStreamRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_clientrpc_proto_msgTypes[35].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*KXCompleted); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_clientrpc_proto_msgTypes[36].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RMPrivateMessage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_clientrpc_proto_msgTypes[37].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RMGroupMessage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_clientrpc_proto_msgTypes[38].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TransferRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_clientrpc_proto_msgTypes[39].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TransferResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_clientrpc_proto_msgTypes[40].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TransfersStreamRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_clientrpc_proto_msgTypes[41].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TransferStatus); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_clientrpc_proto_msgTypes[42].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TransfersStatus); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_clientrpc_proto_msgTypes[43].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PostMetadata); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_clientrpc_proto_msgTypes[44].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*PostMetadataStatus); i {
 			case 0:
 				return &v.state
@@ -2734,9 +3896,9 @@ func file_clientrpc_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_clientrpc_proto_rawDesc,
 			NumEnums:      1,
-			NumMessages:   35,
+			NumMessages:   48,
 			NumExtensions: 0,
-			NumServices:   4,
+			NumServices:   5,
 		},
 		GoTypes:           file_clientrpc_proto_goTypes,
 		DependencyIndexes: file_clientrpc_proto_depIdxs,
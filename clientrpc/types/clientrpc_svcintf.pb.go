@@ -129,6 +129,12 @@ type ChatServiceClient interface {
 	// AckKXCompleted acks to the server that KXs up to the sequence ID have been
 	// processed.
 	AckKXCompleted(ctx context.Context, in *AckRequest, out *AckResponse) error
+	// EstimatePMCost estimates the cost of sending a private message to a user,
+	// without sending it.
+	EstimatePMCost(ctx context.Context, in *PMRequest, out *EstimateCostResponse) error
+	// EstimateGCMCost estimates the cost of sending a message to all members of
+	// a GC, without sending it.
+	EstimateGCMCost(ctx context.Context, in *GCMRequest, out *EstimateCostResponse) error
 }
 
 type client_ChatService struct {
@@ -205,6 +211,16 @@ func (c *client_ChatService) AckKXCompleted(ctx context.Context, in *AckRequest,
 	return c.defn.Methods[method].ClientHandler(c.c, ctx, in, out)
 }
 
+func (c *client_ChatService) EstimatePMCost(ctx context.Context, in *PMRequest, out *EstimateCostResponse) error {
+	const method = "EstimatePMCost"
+	return c.defn.Methods[method].ClientHandler(c.c, ctx, in, out)
+}
+
+func (c *client_ChatService) EstimateGCMCost(ctx context.Context, in *GCMRequest, out *EstimateCostResponse) error {
+	const method = "EstimateGCMCost"
+	return c.defn.Methods[method].ClientHandler(c.c, ctx, in, out)
+}
+
 func NewChatServiceClient(c ClientConn) ChatServiceClient {
 	return &client_ChatService{c: c, defn: ChatServiceDefn()}
 }
@@ -235,6 +251,12 @@ type ChatServiceServer interface {
 	// AckKXCompleted acks to the server that KXs up to the sequence ID have been
 	// processed.
 	AckKXCompleted(context.Context, *AckRequest, *AckResponse) error
+	// EstimatePMCost estimates the cost of sending a private message to a user,
+	// without sending it.
+	EstimatePMCost(context.Context, *PMRequest, *EstimateCostResponse) error
+	// EstimateGCMCost estimates the cost of sending a message to all members of
+	// a GC, without sending it.
+	EstimateGCMCost(context.Context, *GCMRequest, *EstimateCostResponse) error
 }
 
 type ChatService_PMStreamServer interface {
@@ -388,6 +410,36 @@ func ChatServiceDefn() ServiceDefn {
 					return conn.Request(ctx, method, request, response)
 				},
 			},
+			"EstimatePMCost": {
+				IsStreaming:  false,
+				NewRequest:   func() proto.Message { return new(PMRequest) },
+				NewResponse:  func() proto.Message { return new(EstimateCostResponse) },
+				RequestDefn:  func() protoreflect.MessageDescriptor { return new(PMRequest).ProtoReflect().Descriptor() },
+				ResponseDefn: func() protoreflect.MessageDescriptor { return new(EstimateCostResponse).ProtoReflect().Descriptor() },
+				Help:         "EstimatePMCost estimates the cost of sending a private message to a user, without sending it.",
+				ServerHandler: func(x interface{}, ctx context.Context, request, response proto.Message) error {
+					return x.(ChatServiceServer).EstimatePMCost(ctx, request.(*PMRequest), response.(*EstimateCostResponse))
+				},
+				ClientHandler: func(conn ClientConn, ctx context.Context, request, response proto.Message) error {
+					method := "ChatService.EstimatePMCost"
+					return conn.Request(ctx, method, request, response)
+				},
+			},
+			"EstimateGCMCost": {
+				IsStreaming:  false,
+				NewRequest:   func() proto.Message { return new(GCMRequest) },
+				NewResponse:  func() proto.Message { return new(EstimateCostResponse) },
+				RequestDefn:  func() protoreflect.MessageDescriptor { return new(GCMRequest).ProtoReflect().Descriptor() },
+				ResponseDefn: func() protoreflect.MessageDescriptor { return new(EstimateCostResponse).ProtoReflect().Descriptor() },
+				Help:         "EstimateGCMCost estimates the cost of sending a message to all members of a GC, without sending it.",
+				ServerHandler: func(x interface{}, ctx context.Context, request, response proto.Message) error {
+					return x.(ChatServiceServer).EstimateGCMCost(ctx, request.(*GCMRequest), response.(*EstimateCostResponse))
+				},
+				ClientHandler: func(conn ClientConn, ctx context.Context, request, response proto.Message) error {
+					method := "ChatService.EstimateGCMCost"
+					return conn.Request(ctx, method, request, response)
+				},
+			},
 		},
 	}
 }
@@ -410,6 +462,18 @@ type PostsServiceClient interface {
 	// AckReceivedPostStatus acknowledges post status received up to a given
 	// sequence_id have been processed.
 	AckReceivedPostStatus(ctx context.Context, in *AckRequest, out *AckResponse) error
+	// SearchPosts searches the posts (and their comments) stored by the local
+	// client.
+	SearchPosts(ctx context.Context, in *SearchPostsRequest, out *SearchPostsResponse) error
+	// ExportFeed exports the posts (and their comments) stored by the local
+	// client as an Atom feed or a static HTML site.
+	ExportFeed(ctx context.Context, in *ExportFeedRequest, out *ExportFeedResponse) error
+	// EstimatePostShareCost estimates the cost of creating a post and sharing
+	// it with all post subscribers, without creating it.
+	EstimatePostShareCost(ctx context.Context, in *EstimatePostShareCostRequest, out *EstimateCostResponse) error
+	// EstimateRelayCost estimates the cost of relaying a post (and its status
+	// updates) to a user or to all post subscribers, without relaying it.
+	EstimateRelayCost(ctx context.Context, in *EstimateRelayCostRequest, out *EstimateCostResponse) error
 }
 
 type client_PostsService struct {
@@ -463,6 +527,26 @@ func (c *client_PostsService) AckReceivedPostStatus(ctx context.Context, in *Ack
 	return c.defn.Methods[method].ClientHandler(c.c, ctx, in, out)
 }
 
+func (c *client_PostsService) SearchPosts(ctx context.Context, in *SearchPostsRequest, out *SearchPostsResponse) error {
+	const method = "SearchPosts"
+	return c.defn.Methods[method].ClientHandler(c.c, ctx, in, out)
+}
+
+func (c *client_PostsService) ExportFeed(ctx context.Context, in *ExportFeedRequest, out *ExportFeedResponse) error {
+	const method = "ExportFeed"
+	return c.defn.Methods[method].ClientHandler(c.c, ctx, in, out)
+}
+
+func (c *client_PostsService) EstimatePostShareCost(ctx context.Context, in *EstimatePostShareCostRequest, out *EstimateCostResponse) error {
+	const method = "EstimatePostShareCost"
+	return c.defn.Methods[method].ClientHandler(c.c, ctx, in, out)
+}
+
+func (c *client_PostsService) EstimateRelayCost(ctx context.Context, in *EstimateRelayCostRequest, out *EstimateCostResponse) error {
+	const method = "EstimateRelayCost"
+	return c.defn.Methods[method].ClientHandler(c.c, ctx, in, out)
+}
+
 func NewPostsServiceClient(c ClientConn) PostsServiceClient {
 	return &client_PostsService{c: c, defn: PostsServiceDefn()}
 }
@@ -485,6 +569,18 @@ type PostsServiceServer interface {
 	// AckReceivedPostStatus acknowledges post status received up to a given
 	// sequence_id have been processed.
 	AckReceivedPostStatus(context.Context, *AckRequest, *AckResponse) error
+	// SearchPosts searches the posts (and their comments) stored by the local
+	// client.
+	SearchPosts(context.Context, *SearchPostsRequest, *SearchPostsResponse) error
+	// ExportFeed exports the posts (and their comments) stored by the local
+	// client as an Atom feed or a static HTML site.
+	ExportFeed(context.Context, *ExportFeedRequest, *ExportFeedResponse) error
+	// EstimatePostShareCost estimates the cost of creating a post and sharing
+	// it with all post subscribers, without creating it.
+	EstimatePostShareCost(context.Context, *EstimatePostShareCostRequest, *EstimateCostResponse) error
+	// EstimateRelayCost estimates the cost of relaying a post (and its status
+	// updates) to a user or to all post subscribers, without relaying it.
+	EstimateRelayCost(context.Context, *EstimateRelayCostRequest, *EstimateCostResponse) error
 }
 
 type PostsService_PostsStreamServer interface {
@@ -597,6 +693,70 @@ func PostsServiceDefn() ServiceDefn {
 					return conn.Request(ctx, method, request, response)
 				},
 			},
+			"SearchPosts": {
+				IsStreaming:  false,
+				NewRequest:   func() proto.Message { return new(SearchPostsRequest) },
+				NewResponse:  func() proto.Message { return new(SearchPostsResponse) },
+				RequestDefn:  func() protoreflect.MessageDescriptor { return new(SearchPostsRequest).ProtoReflect().Descriptor() },
+				ResponseDefn: func() protoreflect.MessageDescriptor { return new(SearchPostsResponse).ProtoReflect().Descriptor() },
+				Help:         "SearchPosts searches the posts (and their comments) stored by the local client.",
+				ServerHandler: func(x interface{}, ctx context.Context, request, response proto.Message) error {
+					return x.(PostsServiceServer).SearchPosts(ctx, request.(*SearchPostsRequest), response.(*SearchPostsResponse))
+				},
+				ClientHandler: func(conn ClientConn, ctx context.Context, request, response proto.Message) error {
+					method := "PostsService.SearchPosts"
+					return conn.Request(ctx, method, request, response)
+				},
+			},
+			"ExportFeed": {
+				IsStreaming:  false,
+				NewRequest:   func() proto.Message { return new(ExportFeedRequest) },
+				NewResponse:  func() proto.Message { return new(ExportFeedResponse) },
+				RequestDefn:  func() protoreflect.MessageDescriptor { return new(ExportFeedRequest).ProtoReflect().Descriptor() },
+				ResponseDefn: func() protoreflect.MessageDescriptor { return new(ExportFeedResponse).ProtoReflect().Descriptor() },
+				Help:         "ExportFeed exports the posts (and their comments) stored by the local client as an Atom feed or a static HTML site.",
+				ServerHandler: func(x interface{}, ctx context.Context, request, response proto.Message) error {
+					return x.(PostsServiceServer).ExportFeed(ctx, request.(*ExportFeedRequest), response.(*ExportFeedResponse))
+				},
+				ClientHandler: func(conn ClientConn, ctx context.Context, request, response proto.Message) error {
+					method := "PostsService.ExportFeed"
+					return conn.Request(ctx, method, request, response)
+				},
+			},
+			"EstimatePostShareCost": {
+				IsStreaming: false,
+				NewRequest:  func() proto.Message { return new(EstimatePostShareCostRequest) },
+				NewResponse: func() proto.Message { return new(EstimateCostResponse) },
+				RequestDefn: func() protoreflect.MessageDescriptor {
+					return new(EstimatePostShareCostRequest).ProtoReflect().Descriptor()
+				},
+				ResponseDefn: func() protoreflect.MessageDescriptor { return new(EstimateCostResponse).ProtoReflect().Descriptor() },
+				Help:         "EstimatePostShareCost estimates the cost of creating a post and sharing it with all post subscribers, without creating it.",
+				ServerHandler: func(x interface{}, ctx context.Context, request, response proto.Message) error {
+					return x.(PostsServiceServer).EstimatePostShareCost(ctx, request.(*EstimatePostShareCostRequest), response.(*EstimateCostResponse))
+				},
+				ClientHandler: func(conn ClientConn, ctx context.Context, request, response proto.Message) error {
+					method := "PostsService.EstimatePostShareCost"
+					return conn.Request(ctx, method, request, response)
+				},
+			},
+			"EstimateRelayCost": {
+				IsStreaming: false,
+				NewRequest:  func() proto.Message { return new(EstimateRelayCostRequest) },
+				NewResponse: func() proto.Message { return new(EstimateCostResponse) },
+				RequestDefn: func() protoreflect.MessageDescriptor {
+					return new(EstimateRelayCostRequest).ProtoReflect().Descriptor()
+				},
+				ResponseDefn: func() protoreflect.MessageDescriptor { return new(EstimateCostResponse).ProtoReflect().Descriptor() },
+				Help:         "EstimateRelayCost estimates the cost of relaying a post (and its status updates) to a user or to all post subscribers, without relaying it.",
+				ServerHandler: func(x interface{}, ctx context.Context, request, response proto.Message) error {
+					return x.(PostsServiceServer).EstimateRelayCost(ctx, request.(*EstimateRelayCostRequest), response.(*EstimateCostResponse))
+				},
+				ClientHandler: func(conn ClientConn, ctx context.Context, request, response proto.Message) error {
+					method := "PostsService.EstimateRelayCost"
+					return conn.Request(ctx, method, request, response)
+				},
+			},
 		},
 	}
 }
@@ -652,6 +812,143 @@ func PaymentsServiceDefn() ServiceDefn {
 	}
 }
 
+// ContentServiceClient is the client API for ContentService service.
+type ContentServiceClient interface {
+	// PauseTransfer pauses an in-progress download or upload.
+	PauseTransfer(ctx context.Context, in *TransferRequest, out *TransferResponse) error
+	// ResumeTransfer resumes a paused download or upload.
+	ResumeTransfer(ctx context.Context, in *TransferRequest, out *TransferResponse) error
+	// CancelTransfer cancels an in-progress download or upload. The remote
+	// user is notified of the cancellation.
+	CancelTransfer(ctx context.Context, in *TransferRequest, out *TransferResponse) error
+	// TransfersStream returns a stream where the server periodically writes
+	// the status of all outstanding uploads and downloads.
+	TransfersStream(ctx context.Context, in *TransfersStreamRequest) (ContentService_TransfersStreamClient, error)
+}
+
+type client_ContentService struct {
+	c    ClientConn
+	defn ServiceDefn
+}
+
+func (c *client_ContentService) PauseTransfer(ctx context.Context, in *TransferRequest, out *TransferResponse) error {
+	const method = "PauseTransfer"
+	return c.defn.Methods[method].ClientHandler(c.c, ctx, in, out)
+}
+
+func (c *client_ContentService) ResumeTransfer(ctx context.Context, in *TransferRequest, out *TransferResponse) error {
+	const method = "ResumeTransfer"
+	return c.defn.Methods[method].ClientHandler(c.c, ctx, in, out)
+}
+
+func (c *client_ContentService) CancelTransfer(ctx context.Context, in *TransferRequest, out *TransferResponse) error {
+	const method = "CancelTransfer"
+	return c.defn.Methods[method].ClientHandler(c.c, ctx, in, out)
+}
+
+type ContentService_TransfersStreamClient interface {
+	Recv(*TransfersStatus) error
+}
+
+func (c *client_ContentService) TransfersStream(ctx context.Context, in *TransfersStreamRequest) (ContentService_TransfersStreamClient, error) {
+	const method = "TransfersStream"
+	inner, err := c.defn.Methods[method].ClientStreamHandler(c.c, ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return streamerImpl[*TransfersStatus]{c: inner}, nil
+}
+
+func NewContentServiceClient(c ClientConn) ContentServiceClient {
+	return &client_ContentService{c: c, defn: ContentServiceDefn()}
+}
+
+// ContentServiceServer is the server API for ContentService service.
+type ContentServiceServer interface {
+	// PauseTransfer pauses an in-progress download or upload.
+	PauseTransfer(context.Context, *TransferRequest, *TransferResponse) error
+	// ResumeTransfer resumes a paused download or upload.
+	ResumeTransfer(context.Context, *TransferRequest, *TransferResponse) error
+	// CancelTransfer cancels an in-progress download or upload. The remote
+	// user is notified of the cancellation.
+	CancelTransfer(context.Context, *TransferRequest, *TransferResponse) error
+	// TransfersStream returns a stream where the server periodically writes
+	// the status of all outstanding uploads and downloads.
+	TransfersStream(context.Context, *TransfersStreamRequest, ContentService_TransfersStreamServer) error
+}
+
+type ContentService_TransfersStreamServer interface {
+	Send(m *TransfersStatus) error
+}
+
+func ContentServiceDefn() ServiceDefn {
+	return ServiceDefn{
+		Name: "ContentService",
+		Methods: map[string]MethodDefn{
+			"PauseTransfer": {
+				IsStreaming:  false,
+				NewRequest:   func() proto.Message { return new(TransferRequest) },
+				NewResponse:  func() proto.Message { return new(TransferResponse) },
+				RequestDefn:  func() protoreflect.MessageDescriptor { return new(TransferRequest).ProtoReflect().Descriptor() },
+				ResponseDefn: func() protoreflect.MessageDescriptor { return new(TransferResponse).ProtoReflect().Descriptor() },
+				Help:         "PauseTransfer pauses an in-progress download or upload.",
+				ServerHandler: func(x interface{}, ctx context.Context, request, response proto.Message) error {
+					return x.(ContentServiceServer).PauseTransfer(ctx, request.(*TransferRequest), response.(*TransferResponse))
+				},
+				ClientHandler: func(conn ClientConn, ctx context.Context, request, response proto.Message) error {
+					method := "ContentService.PauseTransfer"
+					return conn.Request(ctx, method, request, response)
+				},
+			},
+			"ResumeTransfer": {
+				IsStreaming:  false,
+				NewRequest:   func() proto.Message { return new(TransferRequest) },
+				NewResponse:  func() proto.Message { return new(TransferResponse) },
+				RequestDefn:  func() protoreflect.MessageDescriptor { return new(TransferRequest).ProtoReflect().Descriptor() },
+				ResponseDefn: func() protoreflect.MessageDescriptor { return new(TransferResponse).ProtoReflect().Descriptor() },
+				Help:         "ResumeTransfer resumes a paused download or upload.",
+				ServerHandler: func(x interface{}, ctx context.Context, request, response proto.Message) error {
+					return x.(ContentServiceServer).ResumeTransfer(ctx, request.(*TransferRequest), response.(*TransferResponse))
+				},
+				ClientHandler: func(conn ClientConn, ctx context.Context, request, response proto.Message) error {
+					method := "ContentService.ResumeTransfer"
+					return conn.Request(ctx, method, request, response)
+				},
+			},
+			"CancelTransfer": {
+				IsStreaming:  false,
+				NewRequest:   func() proto.Message { return new(TransferRequest) },
+				NewResponse:  func() proto.Message { return new(TransferResponse) },
+				RequestDefn:  func() protoreflect.MessageDescriptor { return new(TransferRequest).ProtoReflect().Descriptor() },
+				ResponseDefn: func() protoreflect.MessageDescriptor { return new(TransferResponse).ProtoReflect().Descriptor() },
+				Help:         "CancelTransfer cancels an in-progress download or upload. The remote user is notified of the cancellation.",
+				ServerHandler: func(x interface{}, ctx context.Context, request, response proto.Message) error {
+					return x.(ContentServiceServer).CancelTransfer(ctx, request.(*TransferRequest), response.(*TransferResponse))
+				},
+				ClientHandler: func(conn ClientConn, ctx context.Context, request, response proto.Message) error {
+					method := "ContentService.CancelTransfer"
+					return conn.Request(ctx, method, request, response)
+				},
+			},
+			"TransfersStream": {
+				IsStreaming:  true,
+				NewRequest:   func() proto.Message { return new(TransfersStreamRequest) },
+				NewResponse:  func() proto.Message { return new(TransfersStatus) },
+				RequestDefn:  func() protoreflect.MessageDescriptor { return new(TransfersStreamRequest).ProtoReflect().Descriptor() },
+				ResponseDefn: func() protoreflect.MessageDescriptor { return new(TransfersStatus).ProtoReflect().Descriptor() },
+				Help:         "TransfersStream returns a stream where the server periodically writes the status of all outstanding uploads and downloads.",
+				ServerStreamHandler: func(x interface{}, ctx context.Context, request proto.Message, stream ServerStream) error {
+					return x.(ContentServiceServer).TransfersStream(ctx, request.(*TransfersStreamRequest), streamerImpl[*TransfersStatus]{s: stream})
+				},
+				ClientStreamHandler: func(conn ClientConn, ctx context.Context, request proto.Message) (ClientStream, error) {
+					method := "ContentService.TransfersStream"
+					return conn.Stream(ctx, method, request)
+				},
+			},
+		},
+	}
+}
+
 var help_messages = map[string]map[string]string{
 	"VersionRequest": {
 		"@": "",
@@ -767,10 +1064,50 @@ var help_messages = map[string]map[string]string{
 		"status":           "status is the full status data.",
 		"status_from_nick": "status_from_nick is the nick of the original author of the status.",
 	},
+	"SearchPostsRequest": {
+		"@":              "SearchPostsRequest is the request to search the local posts.",
+		"query":          "query is the list of terms to search for in the title, body, author nick and comments of posts. If empty, all posts matching the filters are returned.",
+		"author":         "author is the optional nick or hex-encoded ID of the author of the posts.",
+		"since":          "since is the optional unix timestamp of the earliest post to return.",
+		"until":          "until is the optional unix timestamp of the latest post to return.",
+		"has_attachment": "has_attachment restricts the results to posts with an attached file.",
+	},
+	"SearchPostsResponse": {
+		"@":     "SearchPostsResponse is the response to a search posts request.",
+		"posts": "posts is the list of matching posts, sorted by date.",
+	},
+	"ExportFeedRequest": {
+		"@":       "ExportFeedRequest is the request to export posts.",
+		"format":  "format is the export format: either atom or html.",
+		"dest":    "dest is the destination file (for atom) or directory (for html).",
+		"authors": "authors restricts the export to posts by these users (nicks or hex ids). If empty, all posts are exported.",
+		"title":   "title is the title of the exported feed.",
+	},
+	"ExportFeedResponse": {
+		"@":        "ExportFeedResponse is the response to an export request.",
+		"nb_posts": "nb_posts is the number of exported posts.",
+	},
+	"EstimatePostShareCostRequest": {
+		"@":     "EstimatePostShareCostRequest is a request to estimate the cost of sharing a new post.",
+		"post":  "post is the main content of the post.",
+		"descr": "descr is the optional description of the post.",
+	},
+	"EstimateRelayCostRequest": {
+		"@":       "EstimateRelayCostRequest is a request to estimate the cost of relaying a post.",
+		"from":    "from is the nick or hex-encoded ID of the user the post was received from.",
+		"post_id": "post_id is the id of the post to relay.",
+		"to":      "to is the nick or hex-encoded ID of the user to relay the post to. If empty, the estimate is for relaying the post to all post subscribers.",
+	},
+	"EstimateCostResponse": {
+		"@":          "EstimateCostResponse is the response to a cost estimation request.",
+		"milliatoms": "milliatoms is the estimated cost, not including the fees required to route the payments.",
+	},
 	"TipUserRequest": {
 		"@":          "TipUserRequest is a request to tip a remote user.",
 		"user":       "user is the remote user nick or hex-encoded ID.",
 		"dcr_amount": "dcr_amount is the DCR amount to send as tip.",
+		"memo":       "memo is an optional memo shown to the remote user once the tip is received.",
+		"post":       "post is the optional hex-encoded ID of the post of the remote user that is being tipped.",
 	},
 	"TipUserResponse": {
 		"@": "TipUserResponse is the response to a tip user request.",
@@ -805,6 +1142,42 @@ var help_messages = map[string]map[string]string{
 		"message":    "message is the textual content.",
 		"mode":       "mode is the mode of the message.",
 	},
+	"TransferRequest": {
+		"@":         "TransferRequest identifies a file transfer to control.",
+		"is_upload": "is_upload is true for uploads and false for downloads.",
+		"file_id":   "file_id is the hex-encoded ID of the file being transferred.",
+		"user":      "user is the nick or hex-encoded ID of the user the file is being uploaded to. Only used for uploads.",
+	},
+	"TransferResponse": {
+		"@": "TransferResponse is the response to a transfer control request.",
+	},
+	"TransfersStreamRequest": {
+		"@":        "TransfersStreamRequest is the request for a new stream of transfer status updates.",
+		"interval": "interval is how often to send the status (in milliseconds). Intervals lower than one second are increased to one second.",
+	},
+	"TransferStatus": {
+		"@":            "TransferStatus is the status of an outstanding upload or download.",
+		"is_upload":    "is_upload is true for uploads and false for downloads.",
+		"user":         "user is the hex-encoded ID of the remote user (the uploader of downloads or the user the file is being uploaded to).",
+		"nick":         "nick is the nick of the remote user.",
+		"file_id":      "file_id is the hex-encoded ID of the file being transferred.",
+		"filename":     "filename is the name of the file, if known.",
+		"size":         "size is the size of the file in bytes, if known.",
+		"nb_chunks":    "nb_chunks is the number of chunks of the file, if known.",
+		"paused":       "paused is true if the transfer is paused.",
+		"chunk_states": "chunk_states is the state of each chunk with a known state, keyed by chunk index.",
+		"done_chunks":  "done_chunks is the number of chunks already transferred.",
+		"done_bytes":   "done_bytes is the number of bytes already transferred.",
+		"matoms":       "matoms is the amount paid (downloads) or received (uploads) for the chunks of the file, in milliatoms.",
+		"start_time":   "start_time is the unix timestamp of the start of the transfer.",
+		"rate":         "rate is the average transfer rate, in bytes per second.",
+		"eta":          "eta is the estimated number of seconds to complete the transfer, or zero if unknown.",
+	},
+	"TransfersStatus": {
+		"@":         "TransfersStatus is the status of all outstanding transfers.",
+		"timestamp": "timestamp is the unix timestamp of when the status was collected.",
+		"transfers": "transfers is the list of outstanding transfers.",
+	},
 	"PostMetadata": {
 		"@":          "PostMetadata is the network-level post data.",
 		"version":    "version defines the available fields within attributes.",
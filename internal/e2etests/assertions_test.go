@@ -158,6 +158,31 @@ func assertClientCannotSeeGCM(t testing.TB, gcID zkidentity.ShortID, src, target
 	reg.Unregister()
 }
 
+// assertUploadChunks asserts that the client eventually has the given number
+// of outstanding chunk uploads of the file to the specified user.
+func assertUploadChunks(t testing.TB, c *testClient, uid client.UserID,
+	fid zkidentity.ShortID, nbChunks int) {
+
+	t.Helper()
+	var got int
+	for i := 0; i < 100; i++ {
+		ups, err := c.ListUploads()
+		assert.NilErr(t, err)
+		got = 0
+		for _, up := range ups {
+			if up.UID == uid && up.FID == fid {
+				got = len(up.Chunks)
+			}
+		}
+		if got == nbChunks {
+			return
+		}
+		time.Sleep(time.Millisecond * 100)
+	}
+	t.Fatalf("Client %s has %d outstanding chunk uploads of file %s (want %d)",
+		c.name, got, fid, nbChunks)
+}
+
 func testRand(t testing.TB) *rand.Rand {
 	seed := time.Now().UnixNano()
 	rnd := rand.New(rand.NewSource(seed))
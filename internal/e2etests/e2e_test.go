@@ -16,6 +16,7 @@ import (
 	"github.com/companyzero/bisonrelay/client/clientdb"
 	"github.com/companyzero/bisonrelay/client/clientintf"
 	"github.com/companyzero/bisonrelay/internal/assert"
+	"github.com/companyzero/bisonrelay/internal/simln"
 	"github.com/companyzero/bisonrelay/internal/testutils"
 	"github.com/companyzero/bisonrelay/rpc"
 	"github.com/companyzero/bisonrelay/server"
@@ -24,8 +25,28 @@ import (
 	"github.com/decred/slog"
 )
 
+// testClientLNBalance is the initial balance (in milliatoms) of the LN nodes
+// of clients when using a simulated LN network.
+const testClientLNBalance = 1000 * 1e11
+
 type testScaffoldCfg struct {
 	showLog bool
+
+	// merkleManifestChunks is the min number of chunks for clients to
+	// share files with Merkle metadata.
+	merkleManifestChunks int
+
+	// simLN makes the server require LN payments, made through a simulated
+	// LN network where each client has its own funded node.
+	simLN bool
+
+	// serverCredit makes the server accept prepaid credit. Only used when
+	// simLN is also set.
+	serverCredit bool
+
+	// sendPostsToNewSubs makes clients send their posts to subscribers
+	// that do not have them when the posts are updated.
+	sendPostsToNewSubs bool
 }
 
 type testConn struct {
@@ -75,6 +96,7 @@ func (tc *testConn) RemoteAddr() net.Addr {
 type testClient struct {
 	*client.Client
 	db      *clientdb.DB
+	ln      *simln.Node // Only set when using simulated LN
 	name    string
 	id      *zkidentity.FullIdentity
 	rootDir string
@@ -163,10 +185,15 @@ type testScaffold struct {
 	svr     *server.ZKS
 	svrRunC chan error
 	svrAddr string
+
+	// lnNet and svrLN are the simulated LN network and the node of the
+	// server in it. Only set if simLN is true in the config.
+	lnNet *simln.Network
+	svrLN *simln.Node
 }
 
 func (ts *testScaffold) newClientWithOpts(name string, rootDir string,
-	id *zkidentity.FullIdentity) *testClient {
+	id *zkidentity.FullIdentity, ln *simln.Node) *testClient {
 
 	ts.t.Helper()
 	if name == "" {
@@ -222,6 +249,8 @@ func (ts *testScaffold) newClientWithOpts(name string, rootDir string,
 		DownloadsRoot: filepath.Join(rootDir, "downloads"),
 		Logger:        dbLog,
 		ChunkSize:     8,
+
+		MerkleManifestChunks: ts.cfg.merkleManifestChunks,
 	}
 	db, err := clientdb.New(dbCfg)
 	assert.NilErr(ts.t, err)
@@ -237,6 +266,8 @@ func (ts *testScaffold) newClientWithOpts(name string, rootDir string,
 		LocalIDIniter: idIniter,
 		Logger:        logBknd,
 
+		SendPostsToNewSubscribers: ts.cfg.sendPostsToNewSubs,
+
 		ServerSessionChanged: func(connected bool, pushRate, subRate, expDays uint64) {
 			tc.mtx.Lock()
 			f := tc.onConnChanged
@@ -246,6 +277,9 @@ func (ts *testScaffold) newClientWithOpts(name string, rootDir string,
 			}
 		},
 	}
+	if ln != nil {
+		cfg.PayClient = ln
+	}
 	c, err := client.New(cfg)
 	assert.NilErr(ts.t, err)
 
@@ -259,6 +293,7 @@ func (ts *testScaffold) newClientWithOpts(name string, rootDir string,
 		rootDir: rootDir,
 		runC:    make(chan error, 1),
 		db:      db,
+		ln:      ln,
 	}
 	go func() { tc.runC <- c.Run(ctx) }()
 
@@ -285,7 +320,11 @@ func (ts *testScaffold) newClient(name string) *testClient {
 
 	id, err := zkidentity.New(name, name)
 	assert.NilErr(ts.t, err)
-	return ts.newClientWithOpts(name, rootDir, id)
+	var ln *simln.Node
+	if ts.lnNet != nil {
+		ln = ts.lnNet.NewNode(testClientLNBalance)
+	}
+	return ts.newClientWithOpts(name, rootDir, id, ln)
 }
 
 // stopClient stops this client. It can't be used after this.
@@ -305,7 +344,7 @@ func (ts *testScaffold) recreateClient(tc *testClient) *testClient {
 	ts.stopClient(tc)
 
 	// Recreate client.
-	return ts.newClientWithOpts(tc.name, tc.rootDir, tc.id)
+	return ts.newClientWithOpts(tc.name, tc.rootDir, tc.id, tc.ln)
 }
 
 // kxUsers performs a kx between the two users, so that they can communicate
@@ -325,7 +364,7 @@ func (ts *testScaffold) run() {
 	}()
 }
 
-func newTestServer(t testing.TB, showLog bool) *server.ZKS {
+func newTestServer(t testing.TB, tcfg testScaffoldCfg, svrLN *simln.Node) *server.ZKS {
 	t.Helper()
 
 	cfg := settings.New()
@@ -343,15 +382,22 @@ func newTestServer(t testing.TB, showLog bool) *server.ZKS {
 
 	cfg.Root = dir
 	cfg.RoutedMessages = filepath.Join(dir, settings.ZKSRoutedMessages)
+	cfg.PaidRVs = filepath.Join(dir, settings.ZKSPaidRVs)
 	cfg.LogFile = filepath.Join(dir, "brserver.log")
 	cfg.Listen = []string{"127.0.0.1:0"}
 	cfg.InitSessTimeout = time.Second
 	cfg.DebugLevel = "debug"
-	if showLog {
+	if tcfg.showLog {
 		cfg.LogStdOut = testutils.NewTestLogBackend(t)
 	} else {
 		cfg.LogStdOut = nil
 	}
+	if svrLN != nil {
+		cfg.PayScheme = rpc.PaySchemeDCRLN
+		cfg.LNRPC = svrLN.LightningClient()
+		cfg.LNInvoices = svrLN.InvoicesClient()
+		cfg.CreditEnabled = tcfg.serverCredit
+	}
 
 	s, err := server.NewServer(cfg)
 	if err != nil {
@@ -369,10 +415,14 @@ func newTestScaffold(t *testing.T, cfg testScaffoldCfg) *testScaffold {
 		cfg:     cfg,
 		ctx:     ctx,
 		cancel:  cancel,
-		svr:     newTestServer(t, cfg.showLog),
 		svrRunC: make(chan error, 1),
 		showLog: cfg.showLog,
 	}
+	if cfg.simLN {
+		ts.lnNet = simln.NewNetwork()
+		ts.svrLN = ts.lnNet.NewNode(0)
+	}
+	ts.svr = newTestServer(t, cfg, ts.svrLN)
 	go ts.run()
 
 	// Figure out the actual server address.
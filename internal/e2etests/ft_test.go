@@ -0,0 +1,719 @@
+package e2etests
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/companyzero/bisonrelay/client"
+	"github.com/companyzero/bisonrelay/client/clientdb"
+	"github.com/companyzero/bisonrelay/client/clientintf"
+	"github.com/companyzero/bisonrelay/internal/assert"
+	"github.com/companyzero/bisonrelay/rpc"
+)
+
+// TestPauseCancelTransfers tests pausing and canceling file downloads and
+// uploads.
+func TestPauseCancelTransfers(t *testing.T) {
+	tcfg := testScaffoldCfg{}
+	ts := newTestScaffold(t, tcfg)
+	alice := ts.newClient("alice")
+	bob := ts.newClient("bob")
+	ts.kxUsers(alice, bob)
+
+	type canceledTransfer struct {
+		fid      clientdb.FileID
+		isUpload bool
+	}
+	aliceCanceled := make(chan canceledTransfer, 1)
+	alice.handle(client.OnTransferCanceledNtfn(func(ru *client.RemoteUser, fid clientdb.FileID, isUpload bool) {
+		aliceCanceled <- canceledTransfer{fid: fid, isUpload: isUpload}
+	}))
+	bobCanceled := make(chan canceledTransfer, 1)
+	bob.handle(client.OnTransferCanceledNtfn(func(ru *client.RemoteUser, fid clientdb.FileID, isUpload bool) {
+		bobCanceled <- canceledTransfer{fid: fid, isUpload: isUpload}
+	}))
+
+	// Alice shares a file. The test client uses 8 byte chunks.
+	fname := filepath.Join(t.TempDir(), "file")
+	assert.NilErr(t, os.WriteFile(fname, bytes.Repeat([]byte("0123456789"), 3), 0o600))
+	sf, _, err := alice.ShareFile(fname, nil, 1, false, "")
+	assert.NilErr(t, err)
+	fid := sf.FID
+	nbChunks := 4
+
+	// Alice pauses the upload before Bob starts downloading the file. Bob's
+	// chunk requests are recorded but not replied to.
+	assert.NilErr(t, alice.PauseUpload(bob.PublicID(), fid))
+	assert.NilErr(t, bob.GetUserContent(alice.PublicID(), fid))
+	assertUploadChunks(t, alice, bob.PublicID(), fid, nbChunks)
+	ups, err := alice.ListUploads()
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, len(ups), 1)
+	assert.DeepEqual(t, ups[0].Paused, true)
+
+	// Bob pauses the download.
+	assert.NilErr(t, bob.PauseDownload(fid))
+	fds, err := bob.ListDownloads()
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, len(fds), 1)
+	assert.DeepEqual(t, fds[0].Paused, true)
+
+	// Bob cancels the download. Alice is notified and drops the pending
+	// chunk uploads.
+	assert.NilErr(t, bob.CancelDownload(fid))
+	assert.ChanWrittenWithVal(t, aliceCanceled, canceledTransfer{fid: fid, isUpload: true})
+	ups, err = alice.ListUploads()
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, len(ups), 0)
+	fds, err = bob.ListDownloads()
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, len(fds), 0)
+
+	// Bob starts the download again, and this time Alice cancels the
+	// upload. Bob is notified and drops the download.
+	assert.NilErr(t, alice.PauseUpload(bob.PublicID(), fid))
+	assert.NilErr(t, bob.GetUserContent(alice.PublicID(), fid))
+	assertUploadChunks(t, alice, bob.PublicID(), fid, nbChunks)
+	assert.NilErr(t, alice.CancelUpload(bob.PublicID(), fid))
+	assert.ChanWrittenWithVal(t, bobCanceled, canceledTransfer{fid: fid, isUpload: false})
+	fds, err = bob.ListDownloads()
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, len(fds), 0)
+	ups, err = alice.ListUploads()
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, len(ups), 0)
+
+	// Canceling an unknown transfer fails.
+	assert.NonNilErr(t, bob.CancelDownload(fid))
+}
+
+// TestSwarmDownload tests that chunks of a download are requested from all
+// users sharing the same file.
+func TestSwarmDownload(t *testing.T) {
+	tcfg := testScaffoldCfg{}
+	ts := newTestScaffold(t, tcfg)
+	alice := ts.newClient("alice")
+	bob := ts.newClient("bob")
+	charlie := ts.newClient("charlie")
+	dave := ts.newClient("dave")
+	ts.kxUsers(alice, bob)
+	ts.kxUsers(bob, charlie)
+	ts.kxUsers(bob, dave)
+
+	sourceAdded := make(chan clientintf.UserID, 1)
+	bob.handle(client.OnDownloadSourceAddedNtfn(func(ru *client.RemoteUser, fid clientdb.FileID) {
+		sourceAdded <- ru.ID()
+	}))
+	bobCanceled := make(chan clientintf.UserID, 1)
+	bob.handle(client.OnTransferCanceledNtfn(func(ru *client.RemoteUser, fid clientdb.FileID, isUpload bool) {
+		bobCanceled <- ru.ID()
+	}))
+
+	// Alice and Charlie share the same file. Both pause their uploads so
+	// that Bob's chunk requests are recorded but not replied to.
+	fname := filepath.Join(t.TempDir(), "file")
+	assert.NilErr(t, os.WriteFile(fname, bytes.Repeat([]byte("0123456789"), 3), 0o600))
+	sfAlice, _, err := alice.ShareFile(fname, nil, 1, false, "")
+	assert.NilErr(t, err)
+	sfCharlie, _, err := charlie.ShareFile(fname, nil, 1, false, "")
+	assert.NilErr(t, err)
+	assert.NilErr(t, alice.PauseUpload(bob.PublicID(), sfAlice.FID))
+	assert.NilErr(t, charlie.PauseUpload(bob.PublicID(), sfCharlie.FID))
+
+	// Dave shares the same file, but charges more for it.
+	_, _, err = dave.ShareFile(fname, nil, 2, false, "")
+	assert.NilErr(t, err)
+
+	// Bob fetches the metadata of the file from Alice, with the download
+	// paused.
+	fid := sfAlice.FID
+	assert.NilErr(t, bob.GetUserContent(alice.PublicID(), fid))
+	assert.NilErr(t, bob.PauseDownload(fid))
+	for i := 0; ; i++ {
+		fds, err := bob.ListDownloads()
+		assert.NilErr(t, err)
+		if len(fds) == 1 && fds[0].Metadata != nil {
+			break
+		}
+		if i > 100 {
+			t.Fatalf("Bob did not receive file metadata")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	// Finding sources requires choosing which users to ask.
+	assert.NonNilErr(t, bob.FindDownloadSources(fid, nil))
+
+	// Bob finds Charlie as an additional source of the file.
+	assert.NilErr(t, bob.FindDownloadSources(fid, []clientintf.UserID{charlie.PublicID()}))
+	assert.ChanWrittenWithVal(t, sourceAdded, charlie.PublicID())
+	fds, err := bob.ListDownloads()
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, len(fds[0].Sources), 1)
+	assert.DeepEqual(t, fds[0].Sources[0].FID, sfCharlie.FID)
+
+	// Dave is not added as a source, because his cost is higher than the
+	// one Bob confirmed.
+	assert.NilErr(t, bob.FindDownloadSources(fid, []clientintf.UserID{dave.PublicID()}))
+	assert.ChanNotWritten(t, sourceAdded, time.Second)
+	fds, err = bob.ListDownloads()
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, len(fds[0].Sources), 1)
+
+	// Once resumed, chunks are requested from both Alice and Charlie.
+	assert.NilErr(t, bob.ResumeDownload(fid))
+	assertUploadChunks(t, alice, bob.PublicID(), sfAlice.FID, 2)
+	assertUploadChunks(t, charlie, bob.PublicID(), sfCharlie.FID, 2)
+
+	// Charlie cancels the upload. Bob only removes Charlie as a source of
+	// the download.
+	assert.NilErr(t, charlie.CancelUpload(bob.PublicID(), sfCharlie.FID))
+	assert.ChanWrittenWithVal(t, bobCanceled, charlie.PublicID())
+	fds, err = bob.ListDownloads()
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, len(fds), 1)
+	assert.DeepEqual(t, len(fds[0].Sources), 0)
+}
+
+// TestSendMerkleFile tests sending a file shared with Merkle metadata, where
+// each chunk is verified with its inclusion proof.
+func TestSendMerkleFile(t *testing.T) {
+	tcfg := testScaffoldCfg{merkleManifestChunks: 2}
+	ts := newTestScaffold(t, tcfg)
+	alice := ts.newClient("alice")
+	bob := ts.newClient("bob")
+	ts.kxUsers(alice, bob)
+
+	// The test client uses 8 byte chunks, so the file has 5 chunks and is
+	// shared with Merkle metadata.
+	data := bytes.Repeat([]byte("0123456789"), 4)
+	fname := filepath.Join(t.TempDir(), "file")
+	assert.NilErr(t, os.WriteFile(fname, data, 0o600))
+	sf, md, err := alice.ShareFile(fname, nil, 0, false, "")
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, md.IsMerkle(), true)
+	assert.DeepEqual(t, len(md.Manifest), 0)
+	assert.DeepEqual(t, md.ChunkCount(), 5)
+
+	// Alice sends the file to Bob, who assembles it after verifying every
+	// chunk.
+	assert.NilErr(t, alice.SendFile(bob.PublicID(), fname))
+	var diskPath string
+	for i := 0; diskPath == ""; i++ {
+		diskPath, err = bob.HasDownloadedFile(sf.FID)
+		assert.NilErr(t, err)
+		if i > 100 {
+			t.Fatalf("Bob did not complete the download")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	got, err := os.ReadFile(diskPath)
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, got, data)
+}
+
+// TestCompressedFileDownload tests sharing a compressed file and that the
+// downloader decompresses it once completed.
+func TestCompressedFileDownload(t *testing.T) {
+	tcfg := testScaffoldCfg{}
+	ts := newTestScaffold(t, tcfg)
+	alice := ts.newClient("alice")
+	bob := ts.newClient("bob")
+	ts.kxUsers(alice, bob)
+
+	// Files that do not compress are shared uncompressed.
+	rnd := testRand(t)
+	randData := make([]byte, 64)
+	rnd.Read(randData)
+	randFname := filepath.Join(t.TempDir(), "random")
+	assert.NilErr(t, os.WriteFile(randFname, randData, 0o600))
+	_, md, err := alice.ShareCompressedFile(randFname, nil, 0, "")
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, md.Compression(), "")
+	assert.DeepEqual(t, md.OriginalSize(), uint64(len(randData)))
+
+	// Alice shares a compressible file.
+	data := bytes.Repeat([]byte("0123456789"), 20)
+	fname := filepath.Join(t.TempDir(), "file.txt")
+	assert.NilErr(t, os.WriteFile(fname, data, 0o600))
+	sf, md, err := alice.ShareCompressedFile(fname, nil, 0, "")
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, md.Compression(), rpc.FileCompressionGzip)
+	assert.DeepEqual(t, md.OriginalSize(), uint64(len(data)))
+	assert.DeepEqual(t, md.MIMEType(), "text/plain")
+	if md.Size >= uint64(len(data)) {
+		t.Fatalf("compressed size %d not smaller than original size %d",
+			md.Size, len(data))
+	}
+
+	// Sharing the same file again reuses the compressed metadata.
+	bobID := bob.PublicID()
+	sf2, _, err := alice.ShareFile(fname, &bobID, 0, false, "")
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, sf2.FID, sf.FID)
+
+	// Alice sends the file to Bob, who decompresses it once completed.
+	assert.NilErr(t, alice.SendFile(bob.PublicID(), fname))
+	var diskPath string
+	for i := 0; diskPath == ""; i++ {
+		diskPath, err = bob.HasDownloadedFile(sf.FID)
+		assert.NilErr(t, err)
+		if i > 100 {
+			t.Fatalf("Bob did not complete the download")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	got, err := os.ReadFile(diskPath)
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, got, data)
+
+	// Ranges of the completed file refer to the decompressed contents.
+	got, err = bob.ReadFileRange(sf.FID, 5, 10)
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, got, data[5:15])
+
+	// Ranges of compressed files cannot be fetched. Bob cancels the
+	// download once he receives the metadata.
+	fname2 := filepath.Join(t.TempDir(), "file2.txt")
+	assert.NilErr(t, os.WriteFile(fname2, bytes.Repeat([]byte("abcdefghij"), 20), 0o600))
+	sf2, _, err = alice.ShareCompressedFile(fname2, nil, 0, "")
+	assert.NilErr(t, err)
+	assert.NilErr(t, bob.FetchFileRange(alice.PublicID(), sf2.FID, 0, 10))
+	for i := 0; ; i++ {
+		dls, err := bob.ListDownloads()
+		assert.NilErr(t, err)
+		found := false
+		for _, fd := range dls {
+			found = found || fd.FID == sf2.FID
+		}
+		if !found {
+			break
+		}
+		if i > 100 {
+			t.Fatalf("Bob did not cancel the range fetch of a compressed file")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	_, err = bob.ReadFileRange(sf2.FID, 0, 10)
+	assert.NonNilErr(t, err)
+}
+
+// TestFetchFileRange tests that fetching a range of a file only requests the
+// chunks needed for the range and that the full download may be requested
+// later.
+func TestFetchFileRange(t *testing.T) {
+	tcfg := testScaffoldCfg{simLN: true}
+	ts := newTestScaffold(t, tcfg)
+	alice := ts.newClient("alice")
+	bob := ts.newClient("bob")
+	ts.kxUsers(alice, bob)
+
+	// Alice shares a file. The test client uses 8 byte chunks, so the
+	// file has 5 chunks.
+	data := []byte("0123456789abcdefghijklmnopqrstuvwxyzABCD")
+	fname := filepath.Join(t.TempDir(), "file")
+	assert.NilErr(t, os.WriteFile(fname, data, 0o600))
+	sf, _, err := alice.ShareFile(fname, nil, 1, false, "")
+	assert.NilErr(t, err)
+	fid := sf.FID
+
+	// Alice pauses the upload, so that Bob's chunk requests are recorded
+	// but not replied to.
+	assert.NilErr(t, alice.PauseUpload(bob.PublicID(), fid))
+
+	// Bob fetches a range contained in the second chunk. Only that chunk
+	// is requested.
+	assert.NilErr(t, bob.FetchFileRange(alice.PublicID(), fid, 10, 4))
+	assertUploadChunks(t, alice, bob.PublicID(), fid, 1)
+	fds, err := bob.ListDownloads()
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, len(fds), 1)
+	assert.DeepEqual(t, fds[0].Partial, true)
+	assert.DeepEqual(t, fds[0].Ranges, []clientdb.FileRange{{Offset: 10, Length: 4}})
+	assert.DeepEqual(t, fds[0].GetChunkState(1), clientdb.ChunkStateRequestedChunk)
+
+	// The range cannot be read until its chunks are received.
+	_, err = bob.ReadFileRange(fid, 10, 4)
+	assert.ErrorIs(t, err, clientdb.ErrNotFound)
+
+	// Bob fetches a range that spans the second and third chunks. Only
+	// the third chunk is requested, as the second one was already
+	// requested.
+	assert.NilErr(t, bob.FetchFileRange(alice.PublicID(), fid, 14, 6))
+	assertUploadChunks(t, alice, bob.PublicID(), fid, 2)
+
+	// Ranges past the end of the file are rejected.
+	assert.NonNilErr(t, bob.FetchFileRange(alice.PublicID(), fid, 40, 1))
+
+	// Bob fetches the full file, which requests the remaining chunks.
+	assert.NilErr(t, bob.GetUserContent(alice.PublicID(), fid))
+	assertUploadChunks(t, alice, bob.PublicID(), fid, 5)
+	fds, err = bob.ListDownloads()
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, len(fds), 1)
+	assert.DeepEqual(t, fds[0].Partial, false)
+
+	// Alice resumes the upload and Bob completes the download.
+	assert.NilErr(t, alice.ResumeUpload(bob.PublicID(), fid))
+	for i := 0; ; i++ {
+		diskPath, err := bob.HasDownloadedFile(fid)
+		assert.NilErr(t, err)
+		if diskPath != "" {
+			break
+		}
+		if i > 100 {
+			t.Fatalf("Bob did not complete the download")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	// Ranges of the completed file are read from it, clamped to its size.
+	got, err := bob.ReadFileRange(fid, 10, 4)
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, got, data[10:14])
+	got, err = bob.ReadFileRange(fid, 36, math.MaxUint64)
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, got, data[36:])
+	_, err = bob.ReadFileRange(fid, math.MaxUint64, 1)
+	assert.NonNilErr(t, err)
+}
+
+// TestCollectionDownload tests sharing a dir as a collection, browsing it and
+// queueing the download of all its files.
+func TestCollectionDownload(t *testing.T) {
+	tcfg := testScaffoldCfg{}
+	ts := newTestScaffold(t, tcfg)
+	alice := ts.newClient("alice")
+	bob := ts.newClient("bob")
+	ts.kxUsers(alice, bob)
+
+	listed := make(chan []rpc.CollectionSummary, 1)
+	bob.handle(client.OnCollectionsListedNtfn(func(ru *client.RemoteUser, collections []rpc.CollectionSummary) {
+		listed <- collections
+	}))
+	received := make(chan rpc.FileCollection, 1)
+	bob.handle(client.OnCollectionReceivedNtfn(func(ru *client.RemoteUser, fc rpc.FileCollection) {
+		received <- fc
+	}))
+	started := make(chan int, 1)
+	bob.handle(client.OnCollectionDownloadStartedNtfn(func(ru *client.RemoteUser, fc rpc.FileCollection, nbQueued int) {
+		started <- nbQueued
+	}))
+
+	// Alice shares a dir with a subdir as a collection.
+	dir := filepath.Join(t.TempDir(), "album")
+	assert.NilErr(t, os.MkdirAll(filepath.Join(dir, "sub"), 0o700))
+	assert.NilErr(t, os.WriteFile(filepath.Join(dir, "a.txt"),
+		bytes.Repeat([]byte("0123456789"), 3), 0o600))
+	assert.NilErr(t, os.WriteFile(filepath.Join(dir, "sub", "b.txt"),
+		bytes.Repeat([]byte("abcdefghij"), 1), 0o600))
+	sc, err := alice.ShareCollection(dir, nil, "", 40, "")
+	assert.NilErr(t, err)
+	fc := sc.Collection
+	assert.DeepEqual(t, fc.Name, "album")
+	assert.DeepEqual(t, len(fc.Files), 2)
+	assert.DeepEqual(t, fc.Files[0].Path, "a.txt")
+	assert.DeepEqual(t, fc.Files[1].Path, "sub/b.txt")
+	assert.DeepEqual(t, fc.Files[0].Cost, uint64(30))
+	assert.DeepEqual(t, fc.Files[1].Cost, uint64(10))
+	assert.DeepEqual(t, fc.Size, uint64(40))
+
+	// Alice pauses the uploads so that Bob's chunk requests are recorded
+	// but not replied to.
+	var fids [2]clientdb.FileID
+	for i := range fids {
+		assert.NilErr(t, fids[i].FromString(fc.Files[i].FileID))
+		assert.NilErr(t, alice.PauseUpload(bob.PublicID(), fids[i]))
+	}
+
+	// Bob lists Alice's content and sees the collection.
+	assert.NilErr(t, bob.ListUserContent(alice.PublicID(), []string{rpc.RMFTDGlobal}, ""))
+	summaries := assert.ChanWritten(t, listed)
+	assert.DeepEqual(t, len(summaries), 1)
+	assert.DeepEqual(t, summaries[0].ID, sc.ID.String())
+	assert.DeepEqual(t, summaries[0].NbFiles, 2)
+
+	// Bob browses the collection.
+	assert.NilErr(t, bob.ListUserCollection(alice.PublicID(), sc.ID))
+	assert.DeepEqual(t, assert.ChanWritten(t, received), fc)
+
+	// Bob downloads the collection. Every file is queued, keeping the
+	// folder structure of the collection.
+	assert.NilErr(t, bob.GetUserCollection(alice.PublicID(), sc.ID))
+	assert.ChanWrittenWithVal(t, started, 2)
+	assertUploadChunks(t, alice, bob.PublicID(), fids[0], 4)
+	assertUploadChunks(t, alice, bob.PublicID(), fids[1], 2)
+	fds, err := bob.ListDownloads()
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, len(fds), 2)
+	for _, fd := range fds {
+		assert.DeepEqual(t, fd.Collection, sc.ID.String())
+		wantDir, wantCost := "album", fc.Files[0].Cost
+		if fd.FID == fids[1] {
+			wantDir, wantCost = filepath.Join("album", "sub"), fc.Files[1].Cost
+		}
+		assert.DeepEqual(t, fd.DestDir, wantDir)
+
+		// The metadata received for the file matches the collection.
+		assert.DeepEqual(t, fd.CollectionCost, wantCost)
+		assert.DeepEqual(t, fd.Metadata.Cost, wantCost)
+	}
+
+	// Downloading the collection again does not queue the files again.
+	assert.NilErr(t, bob.GetUserCollection(alice.PublicID(), sc.ID))
+	assert.ChanWrittenWithVal(t, started, 0)
+
+	// Once Alice unshares the collection, Bob can't browse it anymore.
+	assert.NilErr(t, alice.UnshareCollection(sc.ID, nil))
+	assert.NilErr(t, bob.ListUserCollection(alice.PublicID(), sc.ID))
+	assert.ChanNotWritten(t, received, 500*time.Millisecond)
+}
+
+// TestListTransfers tests that in-progress uploads and downloads are listed
+// with their chunk states.
+func TestListTransfers(t *testing.T) {
+	tcfg := testScaffoldCfg{}
+	ts := newTestScaffold(t, tcfg)
+	alice := ts.newClient("alice")
+	bob := ts.newClient("bob")
+	ts.kxUsers(alice, bob)
+
+	// Alice shares a file and pauses the upload, so that Bob's chunk
+	// requests are recorded but not replied to.
+	fname := filepath.Join(t.TempDir(), "file")
+	assert.NilErr(t, os.WriteFile(fname, bytes.Repeat([]byte("0123456789"), 3), 0o600))
+	sf, _, err := alice.ShareFile(fname, nil, 1, false, "")
+	assert.NilErr(t, err)
+	nbChunks := 4
+	assert.NilErr(t, alice.PauseUpload(bob.PublicID(), sf.FID))
+	assert.NilErr(t, bob.GetUserContent(alice.PublicID(), sf.FID))
+	assertUploadChunks(t, alice, bob.PublicID(), sf.FID, nbChunks)
+
+	// Alice lists the upload, with every chunk requested.
+	trs, err := alice.ListTransfers()
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, len(trs), 1)
+	up := trs[0]
+	assert.DeepEqual(t, up.IsUpload, true)
+	assert.DeepEqual(t, up.UID, bob.PublicID())
+	assert.DeepEqual(t, up.FID, sf.FID)
+	assert.DeepEqual(t, up.Filename, "file")
+	assert.DeepEqual(t, up.Size, uint64(30))
+	assert.DeepEqual(t, up.NbChunks, nbChunks)
+	assert.DeepEqual(t, up.Paused, true)
+	assert.DeepEqual(t, len(up.ChunkStates), nbChunks)
+	for _, cs := range up.ChunkStates {
+		assert.DeepEqual(t, cs, clientdb.ChunkStateRequestedChunk)
+	}
+	assert.DeepEqual(t, up.DoneChunks, 0)
+	if up.StartTime.IsZero() {
+		t.Fatalf("upload start time not set")
+	}
+
+	// Bob lists the download.
+	trs, err = bob.ListTransfers()
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, len(trs), 1)
+	down := trs[0]
+	assert.DeepEqual(t, down.IsUpload, false)
+	assert.DeepEqual(t, down.UID, alice.PublicID())
+	assert.DeepEqual(t, down.FID, sf.FID)
+	assert.DeepEqual(t, down.NbChunks, nbChunks)
+	assert.DeepEqual(t, down.DoneChunks, 0)
+	assert.DeepEqual(t, down.ETA, time.Duration(0))
+
+	// Once canceled, the upload is no longer listed.
+	assert.NilErr(t, alice.CancelUpload(bob.PublicID(), sf.FID))
+	trs, err = alice.ListTransfers()
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, len(trs), 0)
+}
+
+// TestDownloadRules tests that download rules automatically accept or reject
+// files offered by remote users.
+func TestDownloadRules(t *testing.T) {
+	tcfg := testScaffoldCfg{}
+	ts := newTestScaffold(t, tcfg)
+	alice := ts.newClient("alice")
+	bob := ts.newClient("bob")
+	charlie := ts.newClient("charlie")
+	ts.kxUsers(alice, bob)
+
+	// Bob rejects images.
+	rejectImgs, err := bob.AddDownloadRule(clientdb.DownloadRule{
+		Action:    clientdb.DownloadRuleReject,
+		MIMETypes: []string{"image/*"},
+	})
+	assert.NilErr(t, err)
+
+	dir := t.TempDir()
+	imgFname := filepath.Join(dir, "pic.png")
+	assert.NilErr(t, os.WriteFile(imgFname, []byte("not really a png"), 0o600))
+	txtFname := filepath.Join(dir, "doc.txt")
+	assert.NilErr(t, os.WriteFile(txtFname, []byte("some text"), 0o600))
+	sfImg, mdImg, err := alice.ShareFile(imgFname, nil, 0, false, "")
+	assert.NilErr(t, err)
+	sfTxt, _, err := alice.ShareFile(txtFname, nil, 0, false, "")
+	assert.NilErr(t, err)
+
+	// Alice sends both files. Messages are processed in order, so once
+	// the text file is downloaded, the image was already rejected.
+	assert.NilErr(t, alice.SendFile(bob.PublicID(), imgFname))
+	assert.NilErr(t, alice.SendFile(bob.PublicID(), txtFname))
+	var diskPath string
+	for i := 0; diskPath == ""; i++ {
+		diskPath, err = bob.HasDownloadedFile(sfTxt.FID)
+		assert.NilErr(t, err)
+		if i > 100 {
+			t.Fatalf("Bob did not complete the download")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	diskPath, err = bob.HasDownloadedFile(sfImg.FID)
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, diskPath, "")
+	fds, err := bob.ListDownloads()
+	assert.NilErr(t, err)
+	for _, fd := range fds {
+		if fd.FID == sfImg.FID {
+			t.Fatalf("Bob started downloading rejected file")
+		}
+	}
+
+	// After removing the rule, the image is downloaded.
+	assert.NilErr(t, bob.RemoveDownloadRule(rejectImgs.ID))
+	assert.NilErr(t, alice.SendFile(bob.PublicID(), imgFname))
+	for i := 0; diskPath == ""; i++ {
+		diskPath, err = bob.HasDownloadedFile(sfImg.FID)
+		assert.NilErr(t, err)
+		if i > 100 {
+			t.Fatalf("Bob did not complete the download")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	// Alice creates a GC with Bob. Bob accepts files from GC members up to
+	// a max size and cost, and rejects everything else.
+	gcID, err := alice.NewGroupChat("test gc")
+	assert.NilErr(t, err)
+	bobAcceptedChan := bob.acceptNextGCInvite(gcID)
+	assert.NilErr(t, alice.InviteToGroupChat(gcID, bob.PublicID()))
+	assert.NilErrFromChan(t, bobAcceptedChan)
+	assertClientInGC(t, bob, gcID)
+
+	acceptGC, err := bob.AddDownloadRule(clientdb.DownloadRule{
+		Action:  clientdb.DownloadRuleAccept,
+		GC:      &gcID,
+		MaxSize: 1000,
+		MaxCost: 1e8,
+	})
+	assert.NilErr(t, err)
+	rejectAll, err := bob.AddDownloadRule(clientdb.DownloadRule{
+		Action: clientdb.DownloadRuleReject,
+	})
+	assert.NilErr(t, err)
+	rules, err := bob.ListDownloadRules()
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, len(rules), 2)
+
+	tests := []struct {
+		name string
+		uid  clientintf.UserID
+		size uint64
+		cost uint64
+		want uint64
+	}{
+		{"gc member", alice.PublicID(), 100, 1000, acceptGC.ID},
+		{"not gc member", charlie.PublicID(), 100, 1000, rejectAll.ID},
+		{"too large", alice.PublicID(), 2000, 1000, rejectAll.ID},
+		{"too expensive", alice.PublicID(), 100, 2e8, rejectAll.ID},
+	}
+	for _, tc := range tests {
+		md := mdImg
+		md.Size = tc.size
+		md.Cost = tc.cost
+		rule, ok, err := bob.MatchDownloadRule(tc.uid, md)
+		assert.NilErr(t, err)
+		if !ok {
+			t.Fatalf("%s: no rule matched", tc.name)
+		}
+		if rule.ID != tc.want {
+			t.Fatalf("%s: unexpected rule matched: got %d, want %d",
+				tc.name, rule.ID, tc.want)
+		}
+	}
+}
+
+// TestGCSharedFiles tests that files shared with a GC can be fetched by its
+// members and that access is revoked when a member is kicked from the GC.
+func TestGCSharedFiles(t *testing.T) {
+	tcfg := testScaffoldCfg{}
+	ts := newTestScaffold(t, tcfg)
+	alice := ts.newClient("alice")
+	bob := ts.newClient("bob")
+	charlie := ts.newClient("charlie")
+	ts.kxUsers(alice, bob)
+	ts.kxUsers(alice, charlie)
+
+	bobGCMsgs := make(chan string, 1)
+	bob.handle(client.OnGCMNtfn(func(ru *client.RemoteUser, msg rpc.RMGroupMessage, ts time.Time) {
+		bobGCMsgs <- msg.Message
+	}))
+	charlieCanceled := make(chan clientdb.FileID, 1)
+	charlie.handle(client.OnTransferCanceledNtfn(func(ru *client.RemoteUser, fid clientdb.FileID, isUpload bool) {
+		charlieCanceled <- fid
+	}))
+
+	// Alice creates a GC and invites Bob and Charlie.
+	gcID, err := alice.NewGroupChat("testGC")
+	assert.NilErr(t, err)
+	bobAcceptedChan := bob.acceptNextGCInvite(gcID)
+	assert.NilErr(t, alice.InviteToGroupChat(gcID, bob.PublicID()))
+	assert.NilErrFromChan(t, bobAcceptedChan)
+	assertClientInGC(t, bob, gcID)
+	charlieAcceptedChan := charlie.acceptNextGCInvite(gcID)
+	assert.NilErr(t, alice.InviteToGroupChat(gcID, charlie.PublicID()))
+	assert.NilErrFromChan(t, charlieAcceptedChan)
+	assertClientInGC(t, charlie, gcID)
+	assertClientSeesInGC(t, alice, gcID, charlie.PublicID())
+
+	// Alice shares a file with the GC. Bob is notified through a GC
+	// message.
+	fname := filepath.Join(t.TempDir(), "file")
+	assert.NilErr(t, os.WriteFile(fname, bytes.Repeat([]byte("0123456789"), 3), 0o600))
+	sf, _, err := alice.ShareFileWithGC(fname, gcID, 1, "")
+	assert.NilErr(t, err)
+	fid := sf.FID
+	nbChunks := 4
+	gcMsg := assert.ChanWritten(t, bobGCMsgs)
+	if !strings.Contains(gcMsg, fid.String()) {
+		t.Fatalf("GC message %q does not contain file id %s", gcMsg, fid)
+	}
+	files, err := alice.ListGCSharedFiles(gcID)
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, len(files), 1)
+
+	// Charlie starts fetching the file. Alice pauses the upload so that
+	// the chunk requests remain outstanding.
+	assert.NilErr(t, alice.PauseUpload(charlie.PublicID(), fid))
+	assert.NilErr(t, charlie.GetUserContent(alice.PublicID(), fid))
+	assertUploadChunks(t, alice, charlie.PublicID(), fid, nbChunks)
+
+	// Alice kicks Charlie from the GC. The upload to Charlie is canceled.
+	assert.NilErr(t, alice.GCKick(gcID, charlie.PublicID(), "no reason"))
+	assert.ChanWrittenWithVal(t, charlieCanceled, fid)
+	assertUploadChunks(t, alice, charlie.PublicID(), fid, 0)
+
+	// Charlie can no longer fetch the file, while Bob, who is still a
+	// member of the GC, can.
+	assert.NilErr(t, charlie.GetUserContent(alice.PublicID(), fid))
+	assert.NilErr(t, alice.PauseUpload(bob.PublicID(), fid))
+	assert.NilErr(t, bob.GetUserContent(alice.PublicID(), fid))
+	assertUploadChunks(t, alice, bob.PublicID(), fid, nbChunks)
+	assertUploadChunks(t, alice, charlie.PublicID(), fid, 0)
+}
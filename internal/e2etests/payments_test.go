@@ -0,0 +1,963 @@
+package e2etests
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/companyzero/bisonrelay/client"
+	"github.com/companyzero/bisonrelay/client/clientdb"
+	"github.com/companyzero/bisonrelay/internal/assert"
+	"github.com/companyzero/bisonrelay/internal/simln"
+	"github.com/companyzero/bisonrelay/rpc"
+	"github.com/companyzero/bisonrelay/zkidentity"
+)
+
+// TestPaymentLimits asserts that the spending limits and approval threshold
+// are enforced on outbound payments.
+func TestPaymentLimits(t *testing.T) {
+	t.Parallel()
+
+	tcfg := testScaffoldCfg{}
+	ts := newTestScaffold(t, tcfg)
+	alice := ts.newClient("alice")
+	bob := ts.newClient("bob")
+	ts.kxUsers(alice, bob)
+
+	bobPMChan := make(chan string, 2)
+	bob.handle(client.OnPMNtfn(func(user *client.RemoteUser, msg rpc.RMPrivateMessage, ts time.Time) {
+		bobPMChan <- msg.Message
+	}))
+
+	// Sending a PM is accounted for in today's budget.
+	assert.NilErr(t, alice.PM(bob.PublicID(), "first msg"))
+	assert.ChanWrittenWithVal(t, bobPMChan, "first msg")
+	assertClientUpToDate(t, alice)
+	budget, err := alice.PaymentBudget()
+	assert.NilErr(t, err)
+	if budget.Spent <= 0 || budget.UserSpent(bob.PublicID()) <= 0 {
+		t.Fatalf("unexpected budget: %#v", budget)
+	}
+
+	// Exhausting the daily limit fails the next PM.
+	limits := clientdb.PaymentLimits{DailyLimit: budget.Spent}
+	assert.NilErr(t, alice.SetPaymentLimits(limits))
+	err = alice.PM(bob.PublicID(), "over daily limit")
+	assert.ErrorIs(t, err, clientdb.ErrPaymentLimitExceeded)
+
+	// Exhausting the user limit also fails the next PM.
+	limits = clientdb.PaymentLimits{}
+	limits.SetUserLimit(bob.PublicID(), budget.UserSpent(bob.PublicID()))
+	assert.NilErr(t, alice.SetPaymentLimits(limits))
+	err = alice.PM(bob.PublicID(), "over user limit")
+	assert.ErrorIs(t, err, clientdb.ErrPaymentLimitExceeded)
+
+	// Tips above the approval threshold are rejected without an approver.
+	limits = clientdb.PaymentLimits{ApprovalThreshold: 1e10}
+	assert.NilErr(t, alice.SetPaymentLimits(limits))
+	err = alice.TipUser(context.Background(), bob.PublicID(), 1)
+	assert.ErrorIs(t, err, client.ErrPaymentNotApproved)
+
+	// Removing the limits allows sending PMs again.
+	assert.NilErr(t, alice.SetPaymentLimits(clientdb.PaymentLimits{}))
+	assert.NilErr(t, alice.PM(bob.PublicID(), "last msg"))
+	assert.ChanWrittenWithVal(t, bobPMChan, "last msg")
+}
+
+// TestCostEstimates asserts that the cost of sending messages can be estimated
+// before sending them.
+func TestCostEstimates(t *testing.T) {
+	t.Parallel()
+
+	tcfg := testScaffoldCfg{}
+	ts := newTestScaffold(t, tcfg)
+	alice := ts.newClient("alice")
+	bob := ts.newClient("bob")
+	charlie := ts.newClient("charlie")
+	ts.kxUsers(alice, bob)
+	ts.kxUsers(alice, charlie)
+	ts.kxUsers(bob, charlie)
+
+	bobRecvPosts := make(chan clientdb.PostSummary, 1)
+	bob.handle(client.OnPostRcvdNtfn(func(ru *client.RemoteUser, summ clientdb.PostSummary, pm rpc.PostMetadata) {
+		bobRecvPosts <- summ
+	}))
+	bobSubChanged := make(chan bool, 1)
+	bob.handle(client.OnRemoteSubscriptionChangedNtfn(func(user *client.RemoteUser, subscribed bool) {
+		bobSubChanged <- subscribed
+	}))
+	charlieSubChanged := make(chan bool, 2)
+	charlie.handle(client.OnRemoteSubscriptionChangedNtfn(func(user *client.RemoteUser, subscribed bool) {
+		charlieSubChanged <- subscribed
+	}))
+
+	// PMs have a cost, which grows with the size of the message.
+	pmCost, err := alice.EstimatePMCost(bob.PublicID(), "short msg")
+	assert.NilErr(t, err)
+	if pmCost == 0 {
+		t.Fatalf("unexpected zero PM cost")
+	}
+	longMsg := randomHex(testRand(t), 10000)
+	longPMCost, err := alice.EstimatePMCost(bob.PublicID(), longMsg)
+	assert.NilErr(t, err)
+	if longPMCost <= pmCost {
+		t.Fatalf("unexpected long PM cost: %d <= %d", longPMCost, pmCost)
+	}
+
+	// GC messages are paid for every member of the GC.
+	gcID, err := alice.NewGroupChat("testGC")
+	assert.NilErr(t, err)
+	gcCost, err := alice.EstimateGCMessageCost(gcID, longMsg)
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, gcCost, uint64(0))
+	bobAcceptedChan := bob.acceptNextGCInvite(gcID)
+	assert.NilErr(t, alice.InviteToGroupChat(gcID, bob.PublicID()))
+	assert.NilErrFromChan(t, bobAcceptedChan)
+	assertClientInGC(t, bob, gcID)
+	gcCost, err = alice.EstimateGCMessageCost(gcID, longMsg)
+	assert.NilErr(t, err)
+	charlieAcceptedChan := charlie.acceptNextGCInvite(gcID)
+	assert.NilErr(t, alice.InviteToGroupChat(gcID, charlie.PublicID()))
+	assert.NilErrFromChan(t, charlieAcceptedChan)
+	assertClientSeesInGC(t, alice, gcID, charlie.PublicID())
+	gcCost2, err := alice.EstimateGCMessageCost(gcID, longMsg)
+	assert.NilErr(t, err)
+	if gcCost == 0 || gcCost2 <= gcCost {
+		t.Fatalf("unexpected GC costs: %d, %d", gcCost, gcCost2)
+	}
+
+	// Sharing posts is paid for every subscriber.
+	postCost, err := alice.EstimatePostShareCost(longMsg, "")
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, postCost, uint64(0))
+	assert.NilErr(t, bob.SubscribeToPosts(alice.PublicID()))
+	assert.ChanWrittenWithVal(t, bobSubChanged, true)
+	assert.NilErr(t, charlie.SubscribeToPosts(alice.PublicID()))
+	assert.ChanWrittenWithVal(t, charlieSubChanged, true)
+	assert.NilErr(t, charlie.SubscribeToPosts(bob.PublicID()))
+	assert.ChanWrittenWithVal(t, charlieSubChanged, true)
+	postCost, err = alice.EstimatePostShareCost(longMsg, "")
+	assert.NilErr(t, err)
+	if postCost == 0 {
+		t.Fatalf("unexpected zero post share cost")
+	}
+
+	// Relaying a post is paid for the target user.
+	_, err = alice.CreatePost(longMsg, "")
+	assert.NilErr(t, err)
+	summ := assert.ChanWritten(t, bobRecvPosts)
+	relayCost, err := bob.EstimateRelayCost(alice.PublicID(), summ.ID, nil)
+	assert.NilErr(t, err)
+	charlieID := charlie.PublicID()
+	relayToCharlieCost, err := bob.EstimateRelayCost(alice.PublicID(), summ.ID, &charlieID)
+	assert.NilErr(t, err)
+	if relayCost == 0 || relayCost != relayToCharlieCost {
+		t.Fatalf("unexpected relay costs: %d, %d", relayCost, relayToCharlieCost)
+	}
+}
+
+// TestPayStatsReport asserts that the payment stats can be aggregated in time
+// buckets and exported.
+func TestPayStatsReport(t *testing.T) {
+	t.Parallel()
+
+	tcfg := testScaffoldCfg{}
+	ts := newTestScaffold(t, tcfg)
+	alice := ts.newClient("alice")
+	bob := ts.newClient("bob")
+	ts.kxUsers(alice, bob)
+
+	// Clear the stats of the KX, so that only PMs are reported.
+	assertClientUpToDate(t, alice)
+	assert.NilErr(t, alice.ClearPayStats(nil))
+
+	assert.NilErr(t, alice.PM(bob.PublicID(), "first msg"))
+	assert.NilErr(t, alice.PM(bob.PublicID(), "second msg"))
+	assertClientUpToDate(t, alice)
+
+	// Both PMs are aggregated in a single bucket, both daily and monthly.
+	bobID := bob.PublicID()
+	for _, period := range []clientdb.PayStatsPeriod{clientdb.PayStatsDaily, clientdb.PayStatsMonthly} {
+		report, err := alice.PayStatsReport(period, time.Time{}, &bobID)
+		assert.NilErr(t, err)
+		assert.DeepEqual(t, len(report), 1)
+		assert.DeepEqual(t, report[0].UID, bobID)
+		assert.DeepEqual(t, report[0].Category, "pm")
+		if report[0].Sent <= 0 || report[0].Received != 0 {
+			t.Fatalf("unexpected report bucket: %#v", report[0])
+		}
+	}
+
+	// Payments made before since are not reported.
+	report, err := alice.PayStatsReport(clientdb.PayStatsDaily, time.Now().Add(time.Hour), nil)
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, len(report), 0)
+
+	// Export to CSV, valuing the amounts in USD.
+	dir := t.TempDir()
+	csvOpts := client.PayStatsExportOpts{
+		Format:   client.PayStatsExportCSV,
+		Dest:     filepath.Join(dir, "paystats.csv"),
+		DCRPrice: 10,
+	}
+	nb, err := alice.ExportPayStats(csvOpts)
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, nb, 1)
+	f, err := os.Open(csvOpts.Dest)
+	assert.NilErr(t, err)
+	records, err := csv.NewReader(f).ReadAll()
+	f.Close()
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, len(records), 2)
+	assert.DeepEqual(t, records[0][len(records[0])-1], "pay_fee_usd")
+	assert.DeepEqual(t, records[1][1], bobID.String())
+	assert.DeepEqual(t, records[1][3], "pm")
+
+	// Export to JSON.
+	jsonOpts := client.PayStatsExportOpts{
+		Format: client.PayStatsExportJSON,
+		Dest:   filepath.Join(dir, "paystats.json"),
+	}
+	nb, err = alice.ExportPayStats(jsonOpts)
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, nb, 1)
+	data, err := os.ReadFile(jsonOpts.Dest)
+	assert.NilErr(t, err)
+	var entries []map[string]interface{}
+	assert.NilErr(t, json.Unmarshal(data, &entries))
+	assert.DeepEqual(t, len(entries), 1)
+	assert.DeepEqual(t, entries[0]["category"], "pm")
+	if _, ok := entries[0]["sent_usd"]; ok {
+		t.Fatalf("unexpected USD valuation without exchange rate")
+	}
+}
+
+// TestPostage asserts that messages from users that have not paid postage are
+// held until they are released.
+func TestPostage(t *testing.T) {
+	t.Parallel()
+
+	tcfg := testScaffoldCfg{}
+	ts := newTestScaffold(t, tcfg)
+	alice := ts.newClient("alice")
+	bob := ts.newClient("bob")
+	charlie := ts.newClient("charlie")
+	ts.kxUsers(alice, bob)
+	ts.kxUsers(alice, charlie)
+
+	alicePMs := make(chan string, 5)
+	alice.handle(client.OnPMNtfn(func(user *client.RemoteUser, msg rpc.RMPrivateMessage, ts time.Time) {
+		alicePMs <- msg.Message
+	}))
+	aliceHeld := make(chan int, 5)
+	alice.handle(client.OnMsgsHeldForPostageNtfn(func(ru *client.RemoteUser, nbHeld int, postage uint64) {
+		aliceHeld <- nbHeld
+	}))
+	bobPostageReqs := make(chan uint64, 5)
+	bob.handle(client.OnPostageRequiredNtfn(func(ru *client.RemoteUser, postage uint64, nbHeld uint32) {
+		bobPostageReqs <- postage
+	}))
+
+	// Alice requires postage from everyone but Charlie.
+	const postage = 1e6
+	settings := clientdb.PostageSettings{Default: postage}
+	settings.SetUserPostage(charlie.PublicID(), 0)
+	assert.NilErr(t, alice.SetPostageSettings(settings))
+
+	// Bob's messages are held and he is alerted about the postage only
+	// once.
+	assert.NilErr(t, bob.PM(alice.PublicID(), "first msg"))
+	assert.ChanWrittenWithVal(t, aliceHeld, 1)
+	assert.NilErr(t, bob.PM(alice.PublicID(), "second msg"))
+	assert.ChanWrittenWithVal(t, aliceHeld, 2)
+	assert.ChanNotWritten(t, alicePMs, 100*time.Millisecond)
+	assert.ChanWrittenWithVal(t, bobPostageReqs, uint64(postage))
+	assert.ChanNotWritten(t, bobPostageReqs, 100*time.Millisecond)
+	state, err := bob.PostageState(alice.PublicID())
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, state.RemotePostage, uint64(postage))
+	held, err := alice.ListHeldMessagesCount()
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, held[bob.PublicID()], 2)
+
+	// Charlie is exempt from postage.
+	assert.NilErr(t, charlie.PM(alice.PublicID(), "charlie msg"))
+	assert.ChanWrittenWithVal(t, alicePMs, "charlie msg")
+
+	// Releasing the held messages delivers them.
+	assert.NilErr(t, alice.ReleaseHeldMessages(bob.PublicID()))
+	released := []string{assert.ChanWritten(t, alicePMs), assert.ChanWritten(t, alicePMs)}
+	sort.Strings(released)
+	assert.DeepEqual(t, released, []string{"first msg", "second msg"})
+
+	// Held messages may be dropped.
+	assert.NilErr(t, bob.PM(alice.PublicID(), "dropped msg"))
+	assert.ChanWrittenWithVal(t, aliceHeld, 1)
+	n, err := alice.DropHeldMessages(bob.PublicID())
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, n, 1)
+
+	// Removing the postage delivers messages directly.
+	assert.NilErr(t, alice.SetPostageSettings(clientdb.PostageSettings{}))
+	assert.NilErr(t, bob.PM(alice.PublicID(), "last msg"))
+	assert.ChanWrittenWithVal(t, alicePMs, "last msg")
+	assert.ChanNotWritten(t, alicePMs, 100*time.Millisecond)
+}
+
+func TestPaidPostsSubscription(t *testing.T) {
+	t.Parallel()
+
+	tcfg := testScaffoldCfg{}
+	ts := newTestScaffold(t, tcfg)
+	alice := ts.newClient("alice")
+	bob := ts.newClient("bob")
+	ts.kxUsers(alice, bob)
+
+	bobRecvPosts := make(chan rpc.PostMetadata, 3)
+	bob.handle(client.OnPostRcvdNtfn(func(ru *client.RemoteUser, summary clientdb.PostSummary, pm rpc.PostMetadata) {
+		bobRecvPosts <- pm
+	}))
+	bobSubChanged := make(chan bool, 3)
+	bob.handle(client.OnRemoteSubscriptionChangedNtfn(func(user *client.RemoteUser, subscribed bool) {
+		bobSubChanged <- subscribed
+	}))
+	bobPayReqs := make(chan rpc.PostsSubscriptionPrice, 3)
+	bob.handle(client.OnPostsSubscriptionPaymentRequiredNtfn(func(ru *client.RemoteUser,
+		price rpc.PostsSubscriptionPrice, autoRenew bool) {
+		bobPayReqs <- price
+	}))
+
+	// Bob subscribes to Alice's posts while subscriptions are free.
+	assert.NilErr(t, bob.SubscribeToPosts(alice.PublicID()))
+	assert.ChanWrittenWithVal(t, bobSubChanged, true)
+
+	// Alice starts charging for subscriptions.
+	settings := clientdb.PostsSubscriptionSettings{
+		Price:  1e8,
+		Period: 24 * time.Hour,
+	}
+	assert.NilErr(t, alice.SetPostsSubscriptionSettings(settings))
+	wantPrice := rpc.PostsSubscriptionPrice{MilliAtoms: 1e8, PeriodSecs: 86400}
+
+	// Bob has not paid for his subscription, so he does not get the next
+	// post and is asked to pay instead.
+	_, err := alice.CreatePost("paid post", "")
+	assert.NilErr(t, err)
+	assert.ChanWrittenWithVal(t, bobPayReqs, wantPrice)
+	assert.ChanWrittenWithVal(t, bobSubChanged, false)
+	assert.ChanNotWritten(t, bobRecvPosts, 100*time.Millisecond)
+	subs, err := alice.ListPostSubscribers()
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, len(subs), 0)
+	mySubs, err := bob.ListPostSubscriptions()
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, len(mySubs), 0)
+
+	// Further posts do not trigger new payment requests.
+	_, err = alice.CreatePost("second paid post", "")
+	assert.NilErr(t, err)
+	assert.ChanNotWritten(t, bobPayReqs, 100*time.Millisecond)
+
+	// Subscribing again requires payment.
+	assert.NilErr(t, bob.SubscribeToPosts(alice.PublicID()))
+	assert.ChanWrittenWithVal(t, bobPayReqs, wantPrice)
+	assert.ChanNotWritten(t, bobSubChanged, 100*time.Millisecond)
+
+	// Making subscriptions free allows Bob to subscribe again.
+	assert.NilErr(t, alice.SetPostsSubscriptionSettings(clientdb.PostsSubscriptionSettings{}))
+	assert.NilErr(t, bob.SubscribeToPosts(alice.PublicID()))
+	assert.ChanWrittenWithVal(t, bobSubChanged, true)
+	_, err = alice.CreatePost("free post", "")
+	assert.NilErr(t, err)
+	assert.ChanWritten(t, bobRecvPosts)
+}
+
+// assertLNFundsConserved asserts that the sum of the balances of the LN nodes
+// of the clients and server (plus the fees paid by them) equals the initial
+// balance given to the clients.
+func assertLNFundsConserved(t testing.TB, ts *testScaffold, clients ...*testClient) {
+	t.Helper()
+	svrStats := ts.svrLN.Stats()
+	total := svrStats.Balance + svrStats.Fees
+	for _, c := range clients {
+		stats := c.ln.Stats()
+		total += stats.Balance + stats.Fees
+	}
+	want := int64(len(clients)) * testClientLNBalance
+	if total != want {
+		t.Fatalf("unexpected total funds: got %d, want %d", total, want)
+	}
+}
+
+// TestSimLNPayments asserts that pushes, subscriptions and tips are paid for
+// when the server requires LN payments.
+func TestSimLNPayments(t *testing.T) {
+	t.Parallel()
+
+	tcfg := testScaffoldCfg{simLN: true}
+	ts := newTestScaffold(t, tcfg)
+	ts.lnNet.SetFees(1000, 1000)
+	alice := ts.newClient("alice")
+	bob := ts.newClient("bob")
+	ts.kxUsers(alice, bob)
+	assertClientUpToDate(t, alice)
+	assertClientUpToDate(t, bob)
+
+	// The KX required paying the server for pushes and subscriptions.
+	svrStats := ts.svrLN.Stats()
+	if svrStats.Received <= 0 || svrStats.PaymentsReceived <= 0 {
+		t.Fatalf("unexpected server stats after KX: %#v", svrStats)
+	}
+	assertLNFundsConserved(t, ts, alice, bob)
+
+	bobPMChan := make(chan string, 3)
+	bob.handle(client.OnPMNtfn(func(user *client.RemoteUser, msg rpc.RMPrivateMessage, ts time.Time) {
+		bobPMChan <- msg.Message
+	}))
+	bobTipChan := make(chan clientdb.TipHistoryEntry, 2)
+	bob.handle(client.OnTipReceivedNtfn(func(ru *client.RemoteUser, tip clientdb.TipHistoryEntry) {
+		bobTipChan <- tip
+	}))
+
+	// Everything Alice and Bob pay while exchanging a PM goes to the
+	// server, plus the fees.
+	aliceBefore, bobBefore, svrBefore := alice.ln.Stats(), bob.ln.Stats(), ts.svrLN.Stats()
+	assert.NilErr(t, alice.PM(bob.PublicID(), "first msg"))
+	assert.ChanWrittenWithVal(t, bobPMChan, "first msg")
+	assertClientUpToDate(t, alice)
+	assertClientUpToDate(t, bob)
+	aliceStats, bobStats, svrStats := alice.ln.Stats(), bob.ln.Stats(), ts.svrLN.Stats()
+	aliceSent := aliceStats.Sent - aliceBefore.Sent
+	bobSent := bobStats.Sent - bobBefore.Sent
+	if aliceSent <= 0 {
+		t.Fatalf("Alice did not pay to send PM")
+	}
+	assert.DeepEqual(t, svrStats.Received-svrBefore.Received, aliceSent+bobSent)
+	if aliceStats.Fees-aliceBefore.Fees < 1000 {
+		t.Fatalf("Alice did not pay fees")
+	}
+	assertLNFundsConserved(t, ts, alice, bob)
+
+	// Tips are paid directly to the remote user.
+	aliceBefore, bobBefore = alice.ln.Stats(), bob.ln.Stats()
+	assert.NilErr(t, alice.TipUser(context.Background(), bob.PublicID(), 0.001))
+	tip := assert.ChanWritten(t, bobTipChan)
+	assert.DeepEqual(t, tip.MilliAtoms, int64(1e8))
+	assertClientUpToDate(t, alice)
+	assertClientUpToDate(t, bob)
+	bobStats = bob.ln.Stats()
+	assert.DeepEqual(t, bobStats.Received-bobBefore.Received, int64(1e8))
+	if alice.ln.Stats().Sent-aliceBefore.Sent < 1e8 {
+		t.Fatalf("Alice did not pay the tip")
+	}
+	assertLNFundsConserved(t, ts, alice, bob)
+
+	// A failed tip payment does not transfer funds.
+	bobBefore = bob.ln.Stats()
+	alice.ln.FailNextPayments(bob.ln, 1)
+	err := alice.TipUser(context.Background(), bob.PublicID(), 0.001)
+	assert.ErrorIs(t, err, simln.ErrPaymentFailed)
+	assert.DeepEqual(t, bob.ln.Stats().Received, bobBefore.Received)
+	assert.ChanNotWritten(t, bobTipChan, 100*time.Millisecond)
+
+	// A failed push payment is retried after reconnecting.
+	aliceBefore = alice.ln.Stats()
+	alice.ln.FailNextPayments(ts.svrLN, 1)
+	assert.NilErr(t, alice.PM(bob.PublicID(), "after failure"))
+	assert.ChanWrittenWithVal(t, bobPMChan, "after failure")
+	if alice.ln.Stats().PaymentsFailed <= aliceBefore.PaymentsFailed {
+		t.Fatalf("Alice did not fail any payments")
+	}
+
+	// Expired invoices and slow payments do not prevent sending messages.
+	ts.lnNet.ExpireInvoices()
+	alice.ln.SetPaymentLatency(100 * time.Millisecond)
+	assert.NilErr(t, alice.PM(bob.PublicID(), "after expiry"))
+	assert.ChanWrittenWithVal(t, bobPMChan, "after expiry")
+	assertClientUpToDate(t, alice)
+	assertClientUpToDate(t, bob)
+	assertLNFundsConserved(t, ts, alice, bob)
+}
+
+// TestSimLNTipMemo asserts that tips may be sent along with a signed memo and
+// may refer to a post of the remote user.
+func TestSimLNTipMemo(t *testing.T) {
+	t.Parallel()
+
+	tcfg := testScaffoldCfg{simLN: true}
+	ts := newTestScaffold(t, tcfg)
+	alice := ts.newClient("alice")
+	bob := ts.newClient("bob")
+	ts.kxUsers(alice, bob)
+
+	bobTipChan := make(chan clientdb.TipHistoryEntry, 2)
+	bob.handle(client.OnTipReceivedNtfn(func(ru *client.RemoteUser, tip clientdb.TipHistoryEntry) {
+		bobTipChan <- tip
+	}))
+
+	// Alice tips Bob with a memo.
+	ctx := context.Background()
+	assert.NilErr(t, alice.TipUserWithMemo(ctx, bob.PublicID(), 0.001, "thanks", nil))
+	tip := assert.ChanWritten(t, bobTipChan)
+	assert.DeepEqual(t, tip.MilliAtoms, int64(1e8))
+	assert.DeepEqual(t, tip.Memo, "thanks")
+	if tip.Post != nil {
+		t.Fatalf("unexpected tipped post %s", tip.Post)
+	}
+
+	// Alice tips one of Bob's posts.
+	bobPost, err := bob.CreatePost("tip me", "")
+	assert.NilErr(t, err)
+	assert.NilErr(t, alice.TipUserWithMemo(ctx, bob.PublicID(), 0.002, "nice post", &bobPost.ID))
+	tip = assert.ChanWritten(t, bobTipChan)
+	assert.DeepEqual(t, tip.MilliAtoms, int64(2e8))
+	assert.DeepEqual(t, tip.Memo, "nice post")
+	assert.DeepEqual(t, tip.Post, &bobPost.ID)
+
+	// Tips of posts that Bob does not have are rejected by him.
+	var unknownPost clientdb.PostID
+	unknownPost[0] = 0x01
+	err = alice.TipUserWithMemo(ctx, bob.PublicID(), 0.001, "", &unknownPost)
+	assert.NonNilErr(t, err)
+	assert.ChanNotWritten(t, bobTipChan, 100*time.Millisecond)
+
+	// Memos that are too long are not sent.
+	longMemo := strings.Repeat("x", rpc.MaxTipMemoLen+1)
+	err = alice.TipUserWithMemo(ctx, bob.PublicID(), 0.001, longMemo, nil)
+	assert.NonNilErr(t, err)
+	assert.ChanNotWritten(t, bobTipChan, 100*time.Millisecond)
+
+	// Both sides recorded the tips in their history.
+	aliceHistory, err := alice.ListTipHistory(bob.PublicID())
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, len(aliceHistory), 2)
+	assert.DeepEqual(t, aliceHistory[0].MilliAtoms, int64(-1e8))
+	assert.DeepEqual(t, aliceHistory[0].Memo, "thanks")
+	assert.DeepEqual(t, aliceHistory[1].MilliAtoms, int64(-2e8))
+	assert.DeepEqual(t, aliceHistory[1].Post, &bobPost.ID)
+	bobHistory, err := bob.ListTipHistory(alice.PublicID())
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, len(bobHistory), 2)
+	assert.DeepEqual(t, bobHistory[0].Memo, "thanks")
+	assert.DeepEqual(t, bobHistory[1].Memo, "nice post")
+	assert.DeepEqual(t, bobHistory[1].Post, &bobPost.ID)
+
+	bobTotals, err := bob.ListTipTotals()
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, bobTotals[alice.PublicID()], clientdb.TipTotals{
+		Received:   3e8,
+		NbReceived: 2,
+	})
+	assertClientUpToDate(t, alice)
+	assertClientUpToDate(t, bob)
+	assertLNFundsConserved(t, ts, alice, bob)
+}
+
+// TestSimLNPaymentRequests asserts that invoices may be requested from and sent
+// to remote users and that the resulting payment requests may be paid or
+// declined.
+func TestSimLNPaymentRequests(t *testing.T) {
+	t.Parallel()
+
+	tcfg := testScaffoldCfg{simLN: true}
+	ts := newTestScaffold(t, tcfg)
+	alice := ts.newClient("alice")
+	bob := ts.newClient("bob")
+	ts.kxUsers(alice, bob)
+
+	aliceReqChan := make(chan clientdb.PaymentRequest, 2)
+	alice.handle(client.OnPaymentRequestReceivedNtfn(func(ru *client.RemoteUser, req clientdb.PaymentRequest) {
+		aliceReqChan <- req
+	}))
+	bobUpdatedChan := make(chan clientdb.PaymentRequest, 2)
+	bob.handle(client.OnPaymentRequestUpdatedNtfn(func(ru *client.RemoteUser, req clientdb.PaymentRequest) {
+		bobUpdatedChan <- req
+	}))
+	bobTipChan := make(chan clientdb.TipHistoryEntry, 2)
+	bob.handle(client.OnTipReceivedNtfn(func(ru *client.RemoteUser, tip clientdb.TipHistoryEntry) {
+		bobTipChan <- tip
+	}))
+
+	// Alice requests an invoice from Bob. Nothing is paid until she pays
+	// it, at which point Bob receives it as a tip.
+	ctx := context.Background()
+	bobBefore := bob.ln.Stats()
+	fetched, err := alice.RequestInvoice(ctx, bob.PublicID(), 0.001, "for coffee")
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, fetched.Fetched, true)
+	assert.DeepEqual(t, fetched.Status, clientdb.PaymentRequestPending)
+	assert.DeepEqual(t, fetched.MilliAtoms, uint64(1e8))
+	assert.ChanNotWritten(t, bobTipChan, 100*time.Millisecond)
+	assert.NilErr(t, alice.PayPaymentRequest(ctx, bob.PublicID(), fetched.ID))
+	tip := assert.ChanWritten(t, bobTipChan)
+	assert.DeepEqual(t, tip.MilliAtoms, int64(1e8))
+	assert.DeepEqual(t, tip.Memo, "for coffee")
+	assert.DeepEqual(t, bob.ln.Stats().Received-bobBefore.Received, int64(1e8))
+
+	// The request cannot be paid twice.
+	err = alice.PayPaymentRequest(ctx, bob.PublicID(), fetched.ID)
+	assert.NonNilErr(t, err)
+
+	// Bob sends an invoice to Alice, which she pays.
+	bobBefore = bob.ln.Stats()
+	sent, err := bob.SendInvoice(alice.PublicID(), 0.002, "lunch")
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, sent.Outbound, true)
+	received := assert.ChanWritten(t, aliceReqChan)
+	assert.DeepEqual(t, received.ID, sent.ID)
+	assert.DeepEqual(t, received.Memo, "lunch")
+	assert.DeepEqual(t, received.MilliAtoms, uint64(2e8))
+	assert.DeepEqual(t, received.Status, clientdb.PaymentRequestPending)
+
+	// Bob cannot pay or decline his own request.
+	assert.NonNilErr(t, bob.PayPaymentRequest(ctx, alice.PublicID(), sent.ID))
+	assert.NonNilErr(t, bob.DeclinePaymentRequest(alice.PublicID(), sent.ID, ""))
+
+	assert.NilErr(t, alice.PayPaymentRequest(ctx, bob.PublicID(), received.ID))
+	updated := assert.ChanWritten(t, bobUpdatedChan)
+	assert.DeepEqual(t, updated.ID, sent.ID)
+	assert.DeepEqual(t, updated.Status, clientdb.PaymentRequestPaid)
+	assert.DeepEqual(t, bob.ln.Stats().Received-bobBefore.Received, int64(2e8))
+
+	// Bob sends another invoice, which Alice declines.
+	bobBefore = bob.ln.Stats()
+	sent, err = bob.SendInvoice(alice.PublicID(), 0.003, "dinner")
+	assert.NilErr(t, err)
+	received = assert.ChanWritten(t, aliceReqChan)
+	assert.NilErr(t, alice.DeclinePaymentRequest(bob.PublicID(), received.ID, "too expensive"))
+	updated = assert.ChanWritten(t, bobUpdatedChan)
+	assert.DeepEqual(t, updated.ID, sent.ID)
+	assert.DeepEqual(t, updated.Status, clientdb.PaymentRequestDeclined)
+	assert.DeepEqual(t, updated.DeclineReason, "too expensive")
+
+	// A declined request cannot be paid.
+	err = alice.PayPaymentRequest(ctx, bob.PublicID(), received.ID)
+	assert.NonNilErr(t, err)
+	assert.DeepEqual(t, bob.ln.Stats().Received, bobBefore.Received)
+
+	// Declining a fetched invoice does not alert Bob.
+	fetched, err = alice.RequestInvoice(ctx, bob.PublicID(), 0.001, "")
+	assert.NilErr(t, err)
+	assert.NilErr(t, alice.DeclinePaymentRequest(bob.PublicID(), fetched.ID, ""))
+	assert.ChanNotWritten(t, bobUpdatedChan, 100*time.Millisecond)
+
+	// Attempting to pay an expired request fails without using up Alice's
+	// daily limit for Bob, so she can still pay a new request for the
+	// full limit (plus some leeway for the fees of messages).
+	budget, err := alice.PaymentBudget()
+	assert.NilErr(t, err)
+	limits := clientdb.PaymentLimits{}
+	limits.SetUserLimit(bob.PublicID(), budget.UserSpent(bob.PublicID())+4e8+1e6)
+	assert.NilErr(t, alice.SetPaymentLimits(limits))
+	_, err = bob.SendInvoice(alice.PublicID(), 0.004, "expired")
+	assert.NilErr(t, err)
+	received = assert.ChanWritten(t, aliceReqChan)
+	ts.lnNet.ExpireInvoices()
+	err = alice.PayPaymentRequest(ctx, bob.PublicID(), received.ID)
+	assert.NonNilErr(t, err)
+	_, err = bob.SendInvoice(alice.PublicID(), 0.004, "not expired")
+	assert.NilErr(t, err)
+	received = assert.ChanWritten(t, aliceReqChan)
+	assert.NilErr(t, alice.PayPaymentRequest(ctx, bob.PublicID(), received.ID))
+	updated = assert.ChanWritten(t, bobUpdatedChan)
+	assert.DeepEqual(t, updated.Status, clientdb.PaymentRequestPaid)
+	assert.NilErr(t, alice.SetPaymentLimits(clientdb.PaymentLimits{}))
+
+	// Both sides list the requests with their final status.
+	wantStatus := func(c *testClient, uid client.UserID, want map[clientdb.PaymentRequestStatus]int) {
+		t.Helper()
+		reqs, err := c.ListPaymentRequests(&uid)
+		assert.NilErr(t, err)
+		got := make(map[clientdb.PaymentRequestStatus]int)
+		for _, req := range reqs {
+			got[req.Status]++
+		}
+		assert.DeepEqual(t, got, want)
+	}
+	wantStatus(alice, bob.PublicID(), map[clientdb.PaymentRequestStatus]int{
+		clientdb.PaymentRequestPending:  1,
+		clientdb.PaymentRequestPaid:     3,
+		clientdb.PaymentRequestDeclined: 2,
+	})
+	wantStatus(bob, alice.PublicID(), map[clientdb.PaymentRequestStatus]int{
+		clientdb.PaymentRequestPending:  1,
+		clientdb.PaymentRequestPaid:     2,
+		clientdb.PaymentRequestDeclined: 1,
+	})
+
+	assertClientUpToDate(t, alice)
+	assertClientUpToDate(t, bob)
+	assertLNFundsConserved(t, ts, alice, bob)
+}
+
+// TestServerCredit asserts that pushes and subscriptions are paid with credit
+// prepaid to the server, that the server issues valid receipts for the changes
+// in the credit and that payments fall back to invoices once the credit runs
+// out.
+func TestServerCredit(t *testing.T) {
+	t.Parallel()
+
+	tcfg := testScaffoldCfg{simLN: true, serverCredit: true}
+	ts := newTestScaffold(t, tcfg)
+	alice := ts.newClient("alice")
+	bob := ts.newClient("bob")
+	ts.kxUsers(alice, bob)
+	assertClientUpToDate(t, alice)
+	assertClientUpToDate(t, bob)
+
+	alicePMChan := make(chan string, 3)
+	alice.handle(client.OnPMNtfn(func(user *client.RemoteUser, msg rpc.RMPrivateMessage, ts time.Time) {
+		alicePMChan <- msg.Message
+	}))
+	bobPMChan := make(chan string, 3)
+	bob.handle(client.OnPMNtfn(func(user *client.RemoteUser, msg rpc.RMPrivateMessage, ts time.Time) {
+		bobPMChan <- msg.Message
+	}))
+
+	// Alice buys credit.
+	const creditAmount = 100000
+	ctx := context.Background()
+	svrBefore := ts.svrLN.Stats()
+	balance, _, err := alice.BuyServerCredit(ctx, creditAmount)
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, balance, uint64(creditAmount))
+	assert.DeepEqual(t, ts.svrLN.Stats().Received-svrBefore.Received, int64(creditAmount))
+
+	// Alice and Bob exchange messages. Alice's pushes and subscriptions
+	// are debited from the credit, without paying any invoices.
+	aliceBefore := alice.ln.Stats()
+	for i := 0; i < 2; i++ {
+		assert.NilErr(t, alice.PM(bob.PublicID(), "alice msg"))
+		assert.ChanWrittenWithVal(t, bobPMChan, "alice msg")
+		assert.NilErr(t, bob.PM(alice.PublicID(), "bob msg"))
+		assert.ChanWrittenWithVal(t, alicePMChan, "bob msg")
+	}
+	assertClientUpToDate(t, alice)
+	assertClientUpToDate(t, bob)
+	assert.DeepEqual(t, alice.ln.Stats().PaymentsSent, aliceBefore.PaymentsSent)
+	balance, err = alice.ServerCreditBalance(ctx)
+	assert.NilErr(t, err)
+	if balance >= creditAmount {
+		t.Fatalf("credit was not debited (balance %d)", balance)
+	}
+
+	// Alice keeps sending messages until the credit runs out, after which
+	// pushes are paid with invoices.
+	for i := 0; alice.ln.Stats().PaymentsSent == aliceBefore.PaymentsSent; i++ {
+		if i > 20 {
+			t.Fatalf("Alice did not fall back to paying invoices")
+		}
+		assert.NilErr(t, alice.PM(bob.PublicID(), "alice msg"))
+		assert.ChanWrittenWithVal(t, bobPMChan, "alice msg")
+		assertClientUpToDate(t, alice)
+	}
+	balance, err = alice.ServerCreditBalance(ctx)
+	assert.NilErr(t, err)
+
+	// The receipts are signed by the server and account for every
+	// change in the balance.
+	var spid zkidentity.PublicIdentity
+	err = alice.db.View(ctx, func(tx clientdb.ReadTx) error {
+		var err error
+		_, spid, err = alice.db.ServerID(tx)
+		return err
+	})
+	assert.NilErr(t, err)
+	receipts, err := alice.ListServerCreditReceipts()
+	assert.NilErr(t, err)
+	var total int64
+	actions := make(map[rpc.GetInvoiceAction]int)
+	for _, r := range receipts {
+		if !spid.VerifyMessage(r.SignedHash(), r.Signature) {
+			t.Fatalf("receipt %v has invalid signature", r)
+		}
+		total += r.MilliAtoms
+		actions[r.Action]++
+	}
+	assert.DeepEqual(t, total, int64(balance))
+	assert.DeepEqual(t, actions[rpc.InvoiceActionCredit], 1)
+	if actions[rpc.InvoiceActionPush] == 0 || actions[rpc.InvoiceActionSub] == 0 {
+		t.Fatalf("unexpected receipt actions: %v", actions)
+	}
+
+	assertLNFundsConserved(t, ts, alice, bob)
+}
+
+// TestServerCreditLateRedemption asserts that a credit purchase that could not
+// be redeemed when it was paid is redeemed (only once) after the push payment
+// lifetime of the server elapses.
+func TestServerCreditLateRedemption(t *testing.T) {
+	t.Parallel()
+
+	tcfg := testScaffoldCfg{simLN: true, serverCredit: true}
+	ts := newTestScaffold(t, tcfg)
+	alice := ts.newClient("alice")
+	bob := ts.newClient("bob")
+	ts.kxUsers(alice, bob)
+	assertClientUpToDate(t, alice)
+
+	// Alice's payment takes longer than she is willing to wait, so the
+	// purchase is not redeemed.
+	const creditAmount = 100000
+	svrBefore := ts.svrLN.Stats()
+	alice.ln.SetPaymentLatency(time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	_, _, err := alice.BuyServerCredit(ctx, creditAmount)
+	cancel()
+	assert.NonNilErr(t, err)
+	alice.ln.SetPaymentLatency(0)
+
+	// Wait until the payment completes, then make it look like it was
+	// settled after the push payment lifetime.
+	for i := 0; ts.svrLN.Stats().Received == svrBefore.Received; i++ {
+		if i > 50 {
+			t.Fatalf("Alice's payment did not complete")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	ts.lnNet.AgeSettledInvoices(48 * time.Hour)
+
+	// Fetching the balance redeems the purchase. Fetching it again does
+	// not redeem it twice.
+	ctx = context.Background()
+	balance, err := alice.ServerCreditBalance(ctx)
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, balance, uint64(creditAmount))
+	balance, err = alice.ServerCreditBalance(ctx)
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, balance, uint64(creditAmount))
+
+	receipts, err := alice.ListServerCreditReceipts()
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, len(receipts), 1)
+	assert.DeepEqual(t, receipts[0].Action, rpc.InvoiceActionCredit)
+	assert.DeepEqual(t, receipts[0].MilliAtoms, int64(creditAmount))
+}
+
+// TestSimLNFileDownload asserts that the chunks of a paid file download are
+// paid to the uploader when using LN payments.
+func TestSimLNFileDownload(t *testing.T) {
+	t.Parallel()
+
+	tcfg := testScaffoldCfg{simLN: true}
+	ts := newTestScaffold(t, tcfg)
+	alice := ts.newClient("alice")
+	bob := ts.newClient("bob")
+	ts.kxUsers(alice, bob)
+
+	// Alice shares a file that costs 3000 atoms. The test client uses 8
+	// byte chunks.
+	data := bytes.Repeat([]byte("0123456789"), 3)
+	fname := filepath.Join(t.TempDir(), "file")
+	assert.NilErr(t, os.WriteFile(fname, data, 0o600))
+	sf, _, err := alice.ShareFile(fname, nil, 3000, false, "")
+	assert.NilErr(t, err)
+
+	// Bob downloads the file, paying for every chunk.
+	assert.NilErr(t, bob.GetUserContent(alice.PublicID(), sf.FID))
+	var diskPath string
+	for i := 0; diskPath == ""; i++ {
+		diskPath, err = bob.HasDownloadedFile(sf.FID)
+		assert.NilErr(t, err)
+		if i > 100 {
+			t.Fatalf("Bob did not complete the download")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	got, err := os.ReadFile(diskPath)
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, got, data)
+
+	// Alice only received payments for the chunks.
+	aliceStats := alice.ln.Stats()
+	assert.DeepEqual(t, aliceStats.Received, int64(3000*1000))
+	assert.DeepEqual(t, aliceStats.PaymentsReceived, 4)
+	assertClientUpToDate(t, alice)
+	assertClientUpToDate(t, bob)
+	assertLNFundsConserved(t, ts, alice, bob)
+}
+
+// TestSimLNFileDownloadExpiredInvoice asserts that a download completes when the
+// downloader receives chunk invoices that are already expired, by requesting
+// new ones from the uploader.
+func TestSimLNFileDownloadExpiredInvoice(t *testing.T) {
+	t.Parallel()
+
+	tcfg := testScaffoldCfg{simLN: true}
+	ts := newTestScaffold(t, tcfg)
+	alice := ts.newClient("alice")
+	bob := ts.newClient("bob")
+	ts.kxUsers(alice, bob)
+
+	// Alice shares a file that costs 3000 atoms. The test client uses 8
+	// byte chunks.
+	data := bytes.Repeat([]byte("0123456789"), 3)
+	fname := filepath.Join(t.TempDir(), "file")
+	assert.NilErr(t, os.WriteFile(fname, data, 0o600))
+	sf, _, err := alice.ShareFile(fname, nil, 3000, false, "")
+	assert.NilErr(t, err)
+
+	// Every invoice generated by Alice is already expired (within the
+	// affordance) by the time Bob receives it.
+	alice.ln.SetDefaultInvoiceExpiry(time.Second)
+
+	// Bob attempts to download the file. Every chunk ends up waiting for
+	// a new invoice.
+	assert.NilErr(t, bob.GetUserContent(alice.PublicID(), sf.FID))
+	for i := 0; ; i++ {
+		dls, err := bob.ListDownloads()
+		assert.NilErr(t, err)
+		var expired int
+		for _, fd := range dls {
+			if fd.FID != sf.FID {
+				continue
+			}
+			for _, cs := range fd.ChunkStates {
+				if cs == clientdb.ChunkStateInvoiceExpired {
+					expired++
+				}
+			}
+		}
+		if expired == 4 {
+			break
+		}
+		if i > 100 {
+			t.Fatalf("Bob did not receive expired invoices for every chunk")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	// Nothing was paid for the expired invoices.
+	assert.DeepEqual(t, alice.ln.Stats().PaymentsReceived, 0)
+
+	// Alice's invoices are valid again. Bob requests new invoices for
+	// the chunks and completes the download.
+	alice.ln.SetDefaultInvoiceExpiry(0)
+	var diskPath string
+	for i := 0; diskPath == ""; i++ {
+		diskPath, err = bob.HasDownloadedFile(sf.FID)
+		assert.NilErr(t, err)
+		if i > 300 {
+			t.Fatalf("Bob did not complete the download")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	got, err := os.ReadFile(diskPath)
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, got, data)
+
+	// Alice was paid once for each chunk.
+	aliceStats := alice.ln.Stats()
+	assert.DeepEqual(t, aliceStats.Received, int64(3000*1000))
+	assert.DeepEqual(t, aliceStats.PaymentsReceived, 4)
+	assertClientUpToDate(t, alice)
+	assertClientUpToDate(t, bob)
+	assertLNFundsConserved(t, ts, alice, bob)
+}
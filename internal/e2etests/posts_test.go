@@ -1,6 +1,10 @@
 package e2etests
 
 import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -113,3 +117,369 @@ func TestBasicPostFeatures(t *testing.T) {
 	gotComment = assert.ChanWritten(t, bobRecvComments)
 	assert.DeepEqual(t, gotComment, wantComment)
 }
+
+// TestSearchPosts tests that posts and their comments can be searched.
+func TestSearchPosts(t *testing.T) {
+	tcfg := testScaffoldCfg{}
+	ts := newTestScaffold(t, tcfg)
+	alice := ts.newClient("alice")
+	bob := ts.newClient("bob")
+
+	bobRecvPosts := make(chan clientdb.PostSummary, 1)
+	bob.handle(client.OnPostRcvdNtfn(func(ru *client.RemoteUser, summary clientdb.PostSummary, pm rpc.PostMetadata) {
+		bobRecvPosts <- summary
+	}))
+	bobRecvComments := make(chan string, 1)
+	bob.handle(client.OnPostStatusRcvdNtfn(func(user *client.RemoteUser, pid clientintf.PostID,
+		statusFrom client.UserID, status rpc.PostMetadataStatus) {
+		bobRecvComments <- status.Attributes[rpc.RMPSComment]
+	}))
+	bobSubChanged := make(chan bool, 1)
+	bob.handle(client.OnRemoteSubscriptionChangedNtfn(func(user *client.RemoteUser, subscribed bool) {
+		bobSubChanged <- subscribed
+	}))
+
+	ts.kxUsers(alice, bob)
+	assert.NilErr(t, bob.SubscribeToPosts(alice.PublicID()))
+	assert.ChanWrittenWithVal(t, bobSubChanged, true)
+
+	// Alice creates two posts, Bob creates one.
+	alicePost1, err := alice.CreatePost("The quick brown fox", "")
+	assert.NilErr(t, err)
+	assert.ChanWritten(t, bobRecvPosts)
+	_, err = alice.CreatePost("jumps over the lazy dog", "")
+	assert.NilErr(t, err)
+	assert.ChanWritten(t, bobRecvPosts)
+	bobPost, err := bob.CreatePost("My own quick post", "")
+	assert.NilErr(t, err)
+
+	assertSearch := func(query string, filters clientdb.PostSearchFilters,
+		want ...clientintf.PostID) {
+		t.Helper()
+		res, err := bob.SearchPosts(query, filters)
+		assert.NilErr(t, err)
+		if len(res) != len(want) {
+			t.Fatalf("unexpected nb of results for %q: got %d, want %d",
+				query, len(res), len(want))
+		}
+		for i := range want {
+			assert.DeepEqual(t, res[i].ID, want[i])
+		}
+	}
+
+	noFilters := clientdb.PostSearchFilters{}
+	aliceID := alice.PublicID()
+	assertSearch("quick", noFilters, alicePost1.ID, bobPost.ID)
+	assertSearch("QUI bro", noFilters, alicePost1.ID)
+	assertSearch("cat", noFilters)
+	assertSearch("quick", clientdb.PostSearchFilters{Author: &aliceID}, alicePost1.ID)
+	assertSearch("quick", clientdb.PostSearchFilters{HasAttachment: true})
+	assertSearch("", clientdb.PostSearchFilters{Until: time.Now().Add(-time.Hour)})
+
+	// Comments received after the index is built are searchable.
+	err = alice.CommentPost(alice.PublicID(), alicePost1.ID, "smart animal", nil)
+	assert.NilErr(t, err)
+	assert.ChanWritten(t, bobRecvComments)
+	assertSearch("animal", noFilters, alicePost1.ID)
+
+	// New posts are also indexed.
+	alicePost3, err := alice.CreatePost("another animal post", "")
+	assert.NilErr(t, err)
+	assert.ChanWritten(t, bobRecvPosts)
+	assertSearch("animal", noFilters, alicePost1.ID, alicePost3.ID)
+}
+
+// TestPostDrafts tests that post and comment drafts are persisted across
+// restarts and can be published.
+func TestPostDrafts(t *testing.T) {
+	tcfg := testScaffoldCfg{}
+	ts := newTestScaffold(t, tcfg)
+	alice := ts.newClient("alice")
+	bob := ts.newClient("bob")
+
+	bobRecvPosts := make(chan clientdb.PostSummary, 1)
+	bob.handle(client.OnPostRcvdNtfn(func(ru *client.RemoteUser, summary clientdb.PostSummary, pm rpc.PostMetadata) {
+		bobRecvPosts <- summary
+	}))
+	bobRecvComments := make(chan string, 1)
+	bob.handle(client.OnPostStatusRcvdNtfn(func(user *client.RemoteUser, pid clientintf.PostID,
+		statusFrom client.UserID, status rpc.PostMetadataStatus) {
+		bobRecvComments <- status.Attributes[rpc.RMPSComment]
+	}))
+	bobSubChanged := make(chan bool, 1)
+	bob.handle(client.OnRemoteSubscriptionChangedNtfn(func(user *client.RemoteUser, subscribed bool) {
+		bobSubChanged <- subscribed
+	}))
+
+	ts.kxUsers(alice, bob)
+	assert.NilErr(t, bob.SubscribeToPosts(alice.PublicID()))
+	assert.ChanWrittenWithVal(t, bobSubChanged, true)
+
+	// Alice saves a draft, then edits it.
+	draft, err := alice.SaveDraft(clientdb.PostDraft{Content: "first version"})
+	assert.NilErr(t, err)
+	if draft.ID.IsEmpty() || draft.EstSize == 0 {
+		t.Fatalf("unexpected draft %v", draft)
+	}
+	draft.Content = "second version of the post"
+	draft, err = alice.SaveDraft(draft)
+	assert.NilErr(t, err)
+
+	// Drafts survive a restart.
+	alice = ts.recreateClient(alice)
+	drafts, err := alice.ListDrafts()
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, len(drafts), 1)
+	assert.DeepEqual(t, drafts[0].ID, draft.ID)
+	assert.DeepEqual(t, drafts[0].Content, draft.Content)
+
+	// Publishing the draft sends the post to Bob and removes the draft.
+	summ, err := alice.PublishDraft(draft.ID)
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, assert.ChanWritten(t, bobRecvPosts).ID, summ.ID)
+	drafts, err = alice.ListDrafts()
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, len(drafts), 0)
+
+	// Comment drafts are published as comments.
+	aliceID := alice.PublicID()
+	commentDraft, err := alice.SaveDraft(clientdb.PostDraft{
+		Content:     "draft comment",
+		CommentFrom: &aliceID,
+		CommentPID:  &summ.ID,
+	})
+	assert.NilErr(t, err)
+	_, err = alice.PublishDraft(commentDraft.ID)
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, assert.ChanWritten(t, bobRecvComments), "draft comment")
+
+	// Comment drafts must specify the full post.
+	_, err = alice.SaveDraft(clientdb.PostDraft{Content: "bad", CommentPID: &summ.ID})
+	if err == nil {
+		t.Fatalf("expected error when saving incomplete comment draft")
+	}
+}
+
+// TestExportFeed tests exporting posts and their comments.
+func TestExportFeed(t *testing.T) {
+	tcfg := testScaffoldCfg{}
+	ts := newTestScaffold(t, tcfg)
+	alice := ts.newClient("alice")
+	bob := ts.newClient("bob")
+
+	bobRecvPosts := make(chan clientdb.PostSummary, 1)
+	bob.handle(client.OnPostRcvdNtfn(func(ru *client.RemoteUser, summary clientdb.PostSummary, pm rpc.PostMetadata) {
+		bobRecvPosts <- summary
+	}))
+	bobRecvComments := make(chan string, 1)
+	bob.handle(client.OnPostStatusRcvdNtfn(func(user *client.RemoteUser, pid clientintf.PostID,
+		statusFrom client.UserID, status rpc.PostMetadataStatus) {
+		bobRecvComments <- status.Attributes[rpc.RMPSComment]
+	}))
+	bobSubChanged := make(chan bool, 1)
+	bob.handle(client.OnRemoteSubscriptionChangedNtfn(func(user *client.RemoteUser, subscribed bool) {
+		bobSubChanged <- subscribed
+	}))
+
+	ts.kxUsers(alice, bob)
+	assert.NilErr(t, bob.SubscribeToPosts(alice.PublicID()))
+	assert.ChanWrittenWithVal(t, bobSubChanged, true)
+
+	alicePost, err := alice.CreatePost("exported post", "")
+	assert.NilErr(t, err)
+	assert.ChanWritten(t, bobRecvPosts)
+	err = alice.CommentPost(alice.PublicID(), alicePost.ID, "exported comment", nil)
+	assert.NilErr(t, err)
+	assert.ChanWritten(t, bobRecvComments)
+	_, err = bob.CreatePost("bob's post", "")
+	assert.NilErr(t, err)
+
+	// Export only Alice's posts as Atom.
+	atomFile := filepath.Join(t.TempDir(), "feed.atom")
+	nbPosts, err := bob.ExportFeed(client.FeedExportOpts{
+		Format:  client.FeedExportAtom,
+		Dest:    atomFile,
+		Authors: []client.UserID{alice.PublicID()},
+	})
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, nbPosts, 1)
+	atom, err := os.ReadFile(atomFile)
+	assert.NilErr(t, err)
+	for _, s := range []string{"exported post", "exported comment"} {
+		if !strings.Contains(string(atom), s) {
+			t.Fatalf("atom feed does not contain %q", s)
+		}
+	}
+
+	// Export all posts as HTML.
+	htmlDir := t.TempDir()
+	nbPosts, err = bob.ExportFeed(client.FeedExportOpts{
+		Format: client.FeedExportHTML,
+		Dest:   htmlDir,
+	})
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, nbPosts, 2)
+	page, err := os.ReadFile(filepath.Join(htmlDir, "posts", alicePost.ID.String()+".html"))
+	assert.NilErr(t, err)
+	if !strings.Contains(string(page), "exported comment") {
+		t.Fatalf("post page does not contain comment")
+	}
+}
+
+// TestPostStatusToNewSubscriber tests that status updates on a post are only
+// sent to subscribers that have the post, while subscribers that do not have
+// it are only sent the post itself when the author opts in to it.
+func TestPostStatusToNewSubscriber(t *testing.T) {
+	t.Run("send post", func(t *testing.T) { testPostStatusToNewSubscriber(t, true) })
+	t.Run("skip post", func(t *testing.T) { testPostStatusToNewSubscriber(t, false) })
+}
+
+func testPostStatusToNewSubscriber(t *testing.T, sendPostsToNewSubs bool) {
+	tcfg := testScaffoldCfg{sendPostsToNewSubs: sendPostsToNewSubs}
+	ts := newTestScaffold(t, tcfg)
+	alice := ts.newClient("alice")
+	bob := ts.newClient("bob")
+	charlie := ts.newClient("charlie")
+
+	type postStatus struct {
+		pid     clientintf.PostID
+		comment string
+	}
+	setupHandlers := func(c *testClient) (chan rpc.PostMetadata, chan postStatus, chan bool) {
+		recvPosts := make(chan rpc.PostMetadata, 3)
+		c.handle(client.OnPostRcvdNtfn(func(ru *client.RemoteUser, summary clientdb.PostSummary, pm rpc.PostMetadata) {
+			recvPosts <- pm
+		}))
+		recvStatus := make(chan postStatus, 3)
+		c.handle(client.OnPostStatusRcvdNtfn(func(user *client.RemoteUser, pid clientintf.PostID,
+			statusFrom client.UserID, status rpc.PostMetadataStatus) {
+			recvStatus <- postStatus{pid: pid, comment: status.Attributes[rpc.RMPSComment]}
+		}))
+		subChanged := make(chan bool, 3)
+		c.handle(client.OnRemoteSubscriptionChangedNtfn(func(user *client.RemoteUser, subscribed bool) {
+			subChanged <- subscribed
+		}))
+		return recvPosts, recvStatus, subChanged
+	}
+	bobRecvPosts, bobRecvStatus, bobSubChanged := setupHandlers(bob)
+	charlieRecvPosts, charlieRecvStatus, charlieSubChanged := setupHandlers(charlie)
+
+	ts.kxUsers(alice, bob)
+	ts.kxUsers(alice, charlie)
+
+	// Charlie subscribes to Alice's posts.
+	assert.NilErr(t, charlie.SubscribeToPosts(alice.PublicID()))
+	assert.ChanWrittenWithVal(t, charlieSubChanged, true)
+
+	// Alice creates a post. Only Charlie gets it.
+	alicePost, err := alice.CreatePost("first", "")
+	assert.NilErr(t, err)
+	pm := assert.ChanWritten(t, charlieRecvPosts)
+	assert.DeepEqual(t, pm.Hash(), alicePost.ID)
+
+	// Bob subscribes to Alice's posts after the post was created.
+	assert.NilErr(t, bob.SubscribeToPosts(alice.PublicID()))
+	assert.ChanWrittenWithVal(t, bobSubChanged, true)
+	assert.ChanNotWritten(t, bobRecvPosts, 50*time.Millisecond)
+
+	// Alice comments on the post. Charlie gets only the comment.
+	wantComment := "alice comment"
+	assert.NilErr(t, alice.CommentPost(alice.PublicID(), alicePost.ID, wantComment, nil))
+	gotStatus := assert.ChanWritten(t, charlieRecvStatus)
+	assert.DeepEqual(t, gotStatus, postStatus{pid: alicePost.ID, comment: wantComment})
+	assert.ChanNotWritten(t, charlieRecvPosts, 50*time.Millisecond)
+
+	// Bob only gets the post along with the comment if Alice is
+	// configured to send posts to new subscribers.
+	if !sendPostsToNewSubs {
+		assert.ChanNotWritten(t, bobRecvPosts, 50*time.Millisecond)
+		assert.ChanNotWritten(t, bobRecvStatus, 50*time.Millisecond)
+		return
+	}
+	pm = assert.ChanWritten(t, bobRecvPosts)
+	assert.DeepEqual(t, pm.Hash(), alicePost.ID)
+	gotStatus = assert.ChanWritten(t, bobRecvStatus)
+	assert.DeepEqual(t, gotStatus, postStatus{pid: alicePost.ID, comment: wantComment})
+
+	// Bob comments on the post. Now that Bob has the post, both only
+	// get the comment.
+	wantComment = "bob comment"
+	assert.NilErr(t, bob.CommentPost(alice.PublicID(), alicePost.ID, wantComment, nil))
+	gotStatus = assert.ChanWritten(t, bobRecvStatus)
+	assert.DeepEqual(t, gotStatus, postStatus{pid: alicePost.ID, comment: wantComment})
+	gotStatus = assert.ChanWritten(t, charlieRecvStatus)
+	assert.DeepEqual(t, gotStatus, postStatus{pid: alicePost.ID, comment: wantComment})
+	assert.ChanNotWritten(t, bobRecvPosts, 50*time.Millisecond)
+	assert.ChanNotWritten(t, charlieRecvPosts, 50*time.Millisecond)
+}
+
+// TestPaywalledPost tests buying the body of a paywalled post, including when
+// the author is offline by the time the payment completes.
+func TestPaywalledPost(t *testing.T) {
+	tcfg := testScaffoldCfg{simLN: true}
+	ts := newTestScaffold(t, tcfg)
+	alice := ts.newClient("alice")
+	bob := ts.newClient("bob")
+	ts.kxUsers(alice, bob)
+
+	bobRecvPosts := make(chan rpc.PostMetadata, 1)
+	bob.handle(client.OnPostRcvdNtfn(func(ru *client.RemoteUser, summary clientdb.PostSummary, pm rpc.PostMetadata) {
+		bobRecvPosts <- pm
+	}))
+	bobUnlocked := make(chan string, 1)
+	bob.handle(client.OnPostPaywallUnlockedNtfn(func(ru *client.RemoteUser, pid clientintf.PostID, body string) {
+		bobUnlocked <- body
+	}))
+	bobSubChanged := make(chan bool, 1)
+	bob.handle(client.OnRemoteSubscriptionChangedNtfn(func(user *client.RemoteUser, subscribed bool) {
+		bobSubChanged <- subscribed
+	}))
+
+	assert.NilErr(t, bob.SubscribeToPosts(alice.PublicID()))
+	assert.ChanWrittenWithVal(t, bobSubChanged, true)
+
+	// Alice creates a paywalled post. Bob only receives the summary.
+	const price = 0.0001
+	const priceMAtoms = int64(price * 1e11)
+	summ, err := alice.CreatePaywalledPost("summary", "first body", "", price)
+	assert.NilErr(t, err)
+	pm := assert.ChanWritten(t, bobRecvPosts)
+	assert.DeepEqual(t, pm.Attributes[rpc.RMPMain], "summary")
+	_, err = bob.PaywalledPostBody(alice.PublicID(), summ.ID)
+	assert.ErrorIs(t, err, clientdb.ErrNotFound)
+
+	// Bob buys the post and receives the body.
+	assert.NilErr(t, bob.BuyPaywalledPost(context.Background(), alice.PublicID(), summ.ID))
+	assert.DeepEqual(t, assert.ChanWritten(t, bobUnlocked), "first body")
+	body, err := bob.PaywalledPostBody(alice.PublicID(), summ.ID)
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, body, "first body")
+	assert.DeepEqual(t, alice.ln.Stats().Received, priceMAtoms)
+
+	// Buying an already unlocked post fails.
+	assert.NonNilErr(t, bob.BuyPaywalledPost(context.Background(), alice.PublicID(), summ.ID))
+
+	// Alice creates a second paywalled post.
+	summ, err = alice.CreatePaywalledPost("summary", "second body", "", price)
+	assert.NilErr(t, err)
+	assert.ChanWritten(t, bobRecvPosts)
+
+	// Bob buys it, but Alice goes offline while the payment is inflight.
+	bob.ln.SetPaymentLatency(500 * time.Millisecond)
+	buyErr := make(chan error, 1)
+	go func() { buyErr <- bob.BuyPaywalledPost(context.Background(), alice.PublicID(), summ.ID) }()
+	for i := 0; bob.ln.InflightPayments(alice.ln) == 0; i++ {
+		if i > 500 {
+			t.Fatalf("Bob did not start paying the invoice")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	ts.stopClient(alice)
+	assert.NilErr(t, assert.ChanWritten(t, buyErr))
+	assert.ChanNotWritten(t, bobUnlocked, 100*time.Millisecond)
+
+	// Once Alice comes back online, she sends the body of the post.
+	alice = ts.newClientWithOpts(alice.name, alice.rootDir, alice.id, alice.ln)
+	assert.DeepEqual(t, assert.ChanWritten(t, bobUnlocked), "second body")
+	assert.DeepEqual(t, alice.ln.Stats().Received, 2*priceMAtoms)
+}
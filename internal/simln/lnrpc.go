@@ -0,0 +1,157 @@
+package simln
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/decred/dcrlnd/lnrpc"
+	"github.com/decred/dcrlnd/lnrpc/invoicesrpc"
+	"google.golang.org/grpc"
+)
+
+// lightningClient implements the subset of lnrpc.LightningClient used by the
+// server's payment subsystem. Calling any other method panics.
+type lightningClient struct {
+	lnrpc.LightningClient
+	nd *Node
+}
+
+func (lc *lightningClient) GetInfo(ctx context.Context, in *lnrpc.GetInfoRequest,
+	opts ...grpc.CallOption) (*lnrpc.GetInfoResponse, error) {
+
+	return &lnrpc.GetInfoResponse{
+		IdentityPubkey: lc.nd.pubKey,
+		SyncedToChain:  true,
+		SyncedToGraph:  true,
+	}, nil
+}
+
+func (lc *lightningClient) AddInvoice(ctx context.Context, in *lnrpc.Invoice,
+	opts ...grpc.CallOption) (*lnrpc.AddInvoiceResponse, error) {
+
+	if in.Value != 0 && in.ValueMAtoms != 0 {
+		return nil, errors.New("value and value_m_atoms are mutually exclusive")
+	}
+	mat := in.ValueMAtoms
+	if in.Value != 0 {
+		mat = in.Value * 1000
+	}
+	if mat < 0 {
+		return nil, errors.New("negative invoice value")
+	}
+
+	inv := lc.nd.addInvoice(mat, in.Memo, time.Duration(in.Expiry)*time.Second)
+	return &lnrpc.AddInvoiceResponse{
+		RHash:          inv.hash[:],
+		PaymentRequest: inv.payReq,
+	}, nil
+}
+
+func (lc *lightningClient) LookupInvoice(ctx context.Context, in *lnrpc.PaymentHash,
+	opts ...grpc.CallOption) (*lnrpc.Invoice, error) {
+
+	if len(in.RHash) != 32 {
+		return nil, fmt.Errorf("invalid payment hash length %d", len(in.RHash))
+	}
+	var hash [32]byte
+	copy(hash[:], in.RHash)
+
+	n := lc.nd.net
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	inv, err := n.lookupHash(hash)
+	if err == nil && inv.payee != lc.nd {
+		err = errUnknownInvoice
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := &lnrpc.Invoice{
+		Memo:           inv.memo,
+		RHash:          inv.hash[:],
+		ValueMAtoms:    inv.mat,
+		Value:          inv.mat / 1000,
+		CreationDate:   inv.created.Unix(),
+		Expiry:         int64(inv.expiry / time.Second),
+		PaymentRequest: inv.payReq,
+		State:          inv.state,
+		Settled:        inv.state == lnrpc.Invoice_SETTLED,
+		AmtPaidMAtoms:  inv.paidMAt,
+		AmtPaidAtoms:   inv.paidMAt / 1000,
+	}
+	if inv.state == lnrpc.Invoice_SETTLED {
+		res.SettleDate = inv.settled.Unix()
+	}
+	return res, nil
+}
+
+func (lc *lightningClient) DecodePayReq(ctx context.Context, in *lnrpc.PayReqString,
+	opts ...grpc.CallOption) (*lnrpc.PayReq, error) {
+
+	n := lc.nd.net
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	inv, err := n.lookupInvoice(in.PayReq)
+	if err != nil {
+		return nil, err
+	}
+	return &lnrpc.PayReq{
+		Destination: inv.payee.pubKey,
+		PaymentHash: hex.EncodeToString(inv.hash[:]),
+		NumAtoms:    inv.mat / 1000,
+		NumMAtoms:   inv.mat,
+		Timestamp:   inv.created.Unix(),
+		Expiry:      int64(inv.expiry / time.Second),
+		Description: inv.memo,
+	}, nil
+}
+
+// invoicesClient implements the subset of invoicesrpc.InvoicesClient used by
+// the server's payment subsystem. Calling any other method panics.
+type invoicesClient struct {
+	invoicesrpc.InvoicesClient
+	nd *Node
+}
+
+func (ic *invoicesClient) CancelInvoice(ctx context.Context, in *invoicesrpc.CancelInvoiceMsg,
+	opts ...grpc.CallOption) (*invoicesrpc.CancelInvoiceResp, error) {
+
+	if len(in.PaymentHash) != 32 {
+		return nil, fmt.Errorf("invalid payment hash length %d", len(in.PaymentHash))
+	}
+	var hash [32]byte
+	copy(hash[:], in.PaymentHash)
+
+	n := ic.nd.net
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	inv, err := n.lookupHash(hash)
+	if err == nil && inv.payee != ic.nd {
+		err = errUnknownInvoice
+	}
+	if err != nil {
+		return nil, err
+	}
+	if inv.state == lnrpc.Invoice_SETTLED {
+		return nil, errors.New("invoice already settled")
+	}
+	inv.state = lnrpc.Invoice_CANCELED
+	inv.watchers = nil
+	return &invoicesrpc.CancelInvoiceResp{}, nil
+}
+
+// LightningClient returns an lnrpc.LightningClient backed by the node, with
+// the methods required by a server to generate and verify invoices.
+func (nd *Node) LightningClient() lnrpc.LightningClient {
+	return &lightningClient{nd: nd}
+}
+
+// InvoicesClient returns an invoicesrpc.InvoicesClient backed by the node,
+// with the methods required by a server to cancel invoices.
+func (nd *Node) InvoicesClient() invoicesrpc.InvoicesClient {
+	return &invoicesClient{nd: nd}
+}
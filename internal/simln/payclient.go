@@ -0,0 +1,125 @@
+package simln
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/companyzero/bisonrelay/client/clientintf"
+	"github.com/companyzero/bisonrelay/rpc"
+	"github.com/decred/dcrlnd/lnrpc"
+)
+
+// PayScheme returns the dcrln pay scheme, so that the node can be used with
+// servers that require LN payments.
+func (nd *Node) PayScheme() string {
+	return rpc.PaySchemeDCRLN
+}
+
+// PayInvoice pays an invoice that specifies an amount. It returns the fees
+// paid.
+func (nd *Node) PayInvoice(ctx context.Context, invoice string) (int64, error) {
+	return nd.pay(ctx, invoice, 0)
+}
+
+// PayInvoiceAmount pays the given amount to an invoice that does not specify
+// an amount. It returns the fees paid.
+func (nd *Node) PayInvoiceAmount(ctx context.Context, invoice string, amount int64) (int64, error) {
+	if amount <= 0 {
+		return 0, fmt.Errorf("invalid amount %d", amount)
+	}
+	return nd.pay(ctx, invoice, amount)
+}
+
+// GetInvoice generates an invoice paying to this node. The callback, if
+// specified, is called with the amount paid once the invoice is settled.
+func (nd *Node) GetInvoice(ctx context.Context, mat int64, cb func(int64)) (string, error) {
+	inv := nd.addInvoice(mat, "", 0)
+	if cb != nil {
+		nd.net.mtx.Lock()
+		inv.watchers = append(inv.watchers, cb)
+		nd.net.mtx.Unlock()
+	}
+	return inv.payReq, nil
+}
+
+// DecodeInvoice decodes an invoice generated by any node of the network.
+func (nd *Node) DecodeInvoice(ctx context.Context, invoice string) (clientintf.DecodedInvoice, error) {
+	nd.net.mtx.Lock()
+	defer nd.net.mtx.Unlock()
+	inv, err := nd.net.lookupInvoice(invoice)
+	if err != nil {
+		return clientintf.DecodedInvoice{}, fmt.Errorf("unable to decode pay req")
+	}
+	id := inv.hash
+	return clientintf.DecodedInvoice{
+		ID:         id[:],
+		MAtoms:     inv.mat,
+		ExpiryTime: inv.created.Add(inv.expiry),
+	}, nil
+}
+
+// IsInvoicePaid returns nil if the invoice, generated by this node, was paid
+// at least minMatAmt.
+func (nd *Node) IsInvoicePaid(ctx context.Context, minMatAmt int64, invoice string) error {
+	nd.net.mtx.Lock()
+	defer nd.net.mtx.Unlock()
+	inv, err := nd.net.lookupInvoice(invoice)
+	if err == nil && inv.payee != nd {
+		err = errUnknownInvoice
+	}
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case inv.state == lnrpc.Invoice_CANCELED:
+		return fmt.Errorf("LN invoice canceled")
+
+	case inv.state != lnrpc.Invoice_SETTLED:
+		return fmt.Errorf("Unexpected LN state: %d", inv.state)
+
+	case inv.paidMAt < minMatAmt:
+		return fmt.Errorf("paid %d < wanted %d: %w", inv.paidMAt,
+			minMatAmt, clientintf.ErrInvoiceInsufficientlyPaid)
+
+	default:
+		return nil
+	}
+}
+
+// IsPaymentCompleted returns nil if this node successfully paid the invoice.
+// If the payment is inflight, it waits until it completes.
+func (nd *Node) IsPaymentCompleted(ctx context.Context, invoice string) error {
+	nd.net.mtx.Lock()
+	inv, err := nd.net.lookupInvoice(invoice)
+	var p *payment
+	if err == nil {
+		p = nd.payments[inv.hash]
+	}
+	nd.net.mtx.Unlock()
+	if err != nil {
+		return fmt.Errorf("unable to decode pay req")
+	}
+	if p == nil {
+		return fmt.Errorf("payment status is unknown")
+	}
+
+	const paymentTimeout = time.Minute
+	select {
+	case <-p.done:
+	case <-time.After(paymentTimeout):
+		return fmt.Errorf("payment still inflight")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	nd.net.mtx.Lock()
+	defer nd.net.mtx.Unlock()
+	if p.status != lnrpc.Payment_SUCCEEDED {
+		return fmt.Errorf("payment failed due to %v", p.err)
+	}
+	return nil
+}
+
+var _ clientintf.PaymentClient = (*Node)(nil)
@@ -0,0 +1,441 @@
+// Package simln provides an in-process simulation of an LN network, suitable
+// for testing code that makes and receives payments without having to run
+// actual dcrlnd nodes.
+//
+// Each participant is represented by a Node. A Node can be used as a client's
+// PaymentClient or, through LightningClient() and InvoicesClient(), as the
+// payment backend of a server.
+package simln
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/decred/dcrlnd/lnrpc"
+)
+
+const (
+	// invoicePrefix is the prefix of the payment requests generated by
+	// simulated nodes.
+	invoicePrefix = "lnsim1"
+
+	// defaultInvoiceExpiry is the expiry of invoices that do not specify
+	// one. This is the same default as dcrlnd.
+	defaultInvoiceExpiry = 3600 * time.Second
+)
+
+var (
+	// ErrInsufficientBalance is returned when a node does not have enough
+	// balance to make a payment (including fees).
+	ErrInsufficientBalance = errors.New("insufficient balance")
+
+	// ErrInvoiceExpired is returned when attempting to pay an expired
+	// invoice.
+	ErrInvoiceExpired = errors.New("invoice expired")
+
+	// ErrInvoiceNotOpen is returned when attempting to pay an invoice that
+	// was already settled or canceled.
+	ErrInvoiceNotOpen = errors.New("invoice is not open")
+
+	// ErrPaymentFailed is the error returned by payments that failed due
+	// to a failure injected with FailNextPayments.
+	ErrPaymentFailed = errors.New("simulated payment failure")
+
+	// errUnknownInvoice mimics the error returned by dcrlnd when looking up
+	// an unknown invoice.
+	errUnknownInvoice = errors.New("unable to locate invoice")
+)
+
+type invoice struct {
+	hash     [32]byte
+	payReq   string
+	payee    *Node
+	mat      int64
+	memo     string
+	created  time.Time
+	expiry   time.Duration
+	state    lnrpc.Invoice_InvoiceState
+	paidMAt  int64
+	settled  time.Time
+	watchers []func(int64)
+}
+
+// expired returns true if the invoice is open and past its expiry time.
+func (inv *invoice) expired(now time.Time) bool {
+	return inv.state == lnrpc.Invoice_OPEN && !now.Before(inv.created.Add(inv.expiry))
+}
+
+type payment struct {
+	status lnrpc.Payment_PaymentStatus
+	err    error
+	done   chan struct{}
+}
+
+// Network is a simulated LN network. All nodes created in a network can pay
+// invoices generated by any other node of the same network.
+type Network struct {
+	mtx      sync.Mutex
+	invoices map[[32]byte]*invoice
+	feeBase  int64
+	feeRate  int64 // Parts per million.
+}
+
+// NewNetwork creates a new, empty simulated network.
+func NewNetwork() *Network {
+	return &Network{
+		invoices: make(map[[32]byte]*invoice),
+	}
+}
+
+// SetFees sets the fees charged to the payer on every payment. The fee of a
+// payment is baseMAtoms plus ratePPM parts per million of the amount paid.
+func (n *Network) SetFees(baseMAtoms, ratePPM int64) {
+	n.mtx.Lock()
+	n.feeBase = baseMAtoms
+	n.feeRate = ratePPM
+	n.mtx.Unlock()
+}
+
+// paymentFee returns the fee for a payment of the given amount. Must be called
+// with the mutex held.
+func (n *Network) paymentFee(amount int64) int64 {
+	return n.feeBase + amount*n.feeRate/1e6
+}
+
+// NewNode creates a new node in the network, with the given initial balance
+// (in milliatoms).
+func (n *Network) NewNode(balance int64) *Node {
+	var pubKey [33]byte
+	pubKey[0] = 0x02
+	if _, err := rand.Read(pubKey[1:]); err != nil {
+		panic(err)
+	}
+	return &Node{
+		net:      n,
+		pubKey:   hex.EncodeToString(pubKey[:]),
+		balance:  balance,
+		payments: make(map[[32]byte]*payment),
+	}
+}
+
+// ExpireInvoices expires every open invoice of the network, as if their
+// expiry time had elapsed.
+func (n *Network) ExpireInvoices() {
+	now := time.Now()
+	n.mtx.Lock()
+	for _, inv := range n.invoices {
+		if inv.state == lnrpc.Invoice_OPEN {
+			inv.expiry = now.Sub(inv.created)
+		}
+	}
+	n.mtx.Unlock()
+}
+
+// AgeSettledInvoices moves the settle time of every settled invoice of the
+// network d into the past, as if they had been settled that long ago.
+func (n *Network) AgeSettledInvoices(d time.Duration) {
+	n.mtx.Lock()
+	for _, inv := range n.invoices {
+		if inv.state == lnrpc.Invoice_SETTLED {
+			inv.settled = inv.settled.Add(-d)
+		}
+	}
+	n.mtx.Unlock()
+}
+
+// lookupInvoice returns the invoice with the given payment request, updating
+// its state in case it expired. Must be called with the mutex held.
+func (n *Network) lookupInvoice(payReq string) (*invoice, error) {
+	if !strings.HasPrefix(payReq, invoicePrefix) {
+		return nil, fmt.Errorf("invalid payment request")
+	}
+	b, err := hex.DecodeString(payReq[len(invoicePrefix):])
+	if err != nil || len(b) != 32 {
+		return nil, fmt.Errorf("invalid payment request")
+	}
+	var hash [32]byte
+	copy(hash[:], b)
+	return n.lookupHash(hash)
+}
+
+// lookupHash returns the invoice with the given payment hash, updating its
+// state in case it expired. Must be called with the mutex held.
+func (n *Network) lookupHash(hash [32]byte) (*invoice, error) {
+	inv, ok := n.invoices[hash]
+	if !ok {
+		return nil, errUnknownInvoice
+	}
+	if inv.expired(time.Now()) {
+		inv.state = lnrpc.Invoice_CANCELED
+		inv.watchers = nil
+	}
+	return inv, nil
+}
+
+// NodeStats is a snapshot of the accounting of a node.
+type NodeStats struct {
+	Balance  int64 // Current balance
+	Sent     int64 // Total paid to other nodes, excluding fees
+	Received int64 // Total received from other nodes
+	Fees     int64 // Total paid in fees
+
+	PaymentsSent     int // Nb of successful outbound payments
+	PaymentsReceived int // Nb of settled invoices
+	PaymentsFailed   int // Nb of failed outbound payments
+}
+
+// Node is a participant of a simulated network.
+type Node struct {
+	net    *Network
+	pubKey string
+
+	// The following fields are protected by the network's mutex.
+	balance       int64
+	stats         NodeStats
+	payments      map[[32]byte]*payment
+	latency       time.Duration
+	failPayments  int
+	failPayee     *Node
+	defaultExpiry time.Duration
+}
+
+// PubKey returns the (random) identity of the node.
+func (nd *Node) PubKey() string {
+	return nd.pubKey
+}
+
+// Stats returns the current accounting of the node.
+func (nd *Node) Stats() NodeStats {
+	nd.net.mtx.Lock()
+	res := nd.stats
+	res.Balance = nd.balance
+	nd.net.mtx.Unlock()
+	return res
+}
+
+// InflightPayments returns the number of payments made by this node to the
+// given payee that have not completed yet. If payee is nil, payments to any
+// node are counted.
+func (nd *Node) InflightPayments(payee *Node) int {
+	nd.net.mtx.Lock()
+	defer nd.net.mtx.Unlock()
+	var res int
+	for hash, p := range nd.payments {
+		if p.status != lnrpc.Payment_IN_FLIGHT {
+			continue
+		}
+		if inv, ok := nd.net.invoices[hash]; !ok || (payee != nil && inv.payee != payee) {
+			continue
+		}
+		res++
+	}
+	return res
+}
+
+// SetPaymentLatency sets how long payments made by this node take to
+// complete.
+func (nd *Node) SetPaymentLatency(d time.Duration) {
+	nd.net.mtx.Lock()
+	nd.latency = d
+	nd.net.mtx.Unlock()
+}
+
+// FailNextPayments makes the next count payments attempted by this node to the
+// given payee fail (after the payment latency elapses) without transferring
+// any funds. If payee is nil, payments to any node fail.
+func (nd *Node) FailNextPayments(payee *Node, count int) {
+	nd.net.mtx.Lock()
+	nd.failPayments = count
+	nd.failPayee = payee
+	nd.net.mtx.Unlock()
+}
+
+// SetDefaultInvoiceExpiry sets the expiry of invoices generated by this node
+// that do not specify one.
+func (nd *Node) SetDefaultInvoiceExpiry(d time.Duration) {
+	nd.net.mtx.Lock()
+	nd.defaultExpiry = d
+	nd.net.mtx.Unlock()
+}
+
+// addInvoice generates a new invoice paying to this node. An amount of zero
+// creates an invoice that may be paid any amount.
+func (nd *Node) addInvoice(mat int64, memo string, expiry time.Duration) *invoice {
+	var preimage [32]byte
+	if _, err := rand.Read(preimage[:]); err != nil {
+		panic(err)
+	}
+	hash := sha256.Sum256(preimage[:])
+
+	n := nd.net
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	if expiry <= 0 {
+		expiry = nd.defaultExpiry
+	}
+	if expiry <= 0 {
+		expiry = defaultInvoiceExpiry
+	}
+	inv := &invoice{
+		hash:    hash,
+		payReq:  invoicePrefix + hex.EncodeToString(hash[:]),
+		payee:   nd,
+		mat:     mat,
+		memo:    memo,
+		created: time.Now(),
+		expiry:  expiry,
+		state:   lnrpc.Invoice_OPEN,
+	}
+	n.invoices[hash] = inv
+	return inv
+}
+
+// startPayment checks whether the invoice can be paid and registers an
+// inflight payment for it. The returned payment must be completed by calling
+// finishPayment.
+func (nd *Node) startPayment(payReq string, amount int64) (*invoice, *payment, error) {
+	n := nd.net
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+
+	inv, err := n.lookupInvoice(payReq)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch {
+	case inv.state == lnrpc.Invoice_CANCELED:
+		return nil, nil, ErrInvoiceExpired
+	case inv.state != lnrpc.Invoice_OPEN:
+		return nil, nil, ErrInvoiceNotOpen
+	case inv.mat == 0 && amount == 0:
+		return nil, nil, fmt.Errorf("amount must be specified when " +
+			"paying a zero amount invoice")
+	case inv.mat != 0 && amount != 0 && amount != inv.mat:
+		return nil, nil, fmt.Errorf("amount must not be specified " +
+			"when paying a non-zero amount invoice")
+	}
+	if inv.mat != 0 {
+		amount = inv.mat
+	}
+	if amount < 0 {
+		return nil, nil, fmt.Errorf("negative payment amount")
+	}
+
+	// Only one payment attempt per invoice may be inflight or succeeded.
+	if p, ok := nd.payments[inv.hash]; ok && p.status != lnrpc.Payment_FAILED {
+		return nil, nil, fmt.Errorf("invoice already being paid")
+	}
+
+	fee := n.paymentFee(amount)
+	if nd.balance < amount+fee {
+		return nil, nil, fmt.Errorf("unable to pay %d MAtoms + %d "+
+			"MAtoms fees with balance %d MAtoms: %w", amount, fee,
+			nd.balance, ErrInsufficientBalance)
+	}
+
+	p := &payment{
+		status: lnrpc.Payment_IN_FLIGHT,
+		done:   make(chan struct{}),
+	}
+	if nd.failPayments > 0 && (nd.failPayee == nil || nd.failPayee == inv.payee) {
+		nd.failPayments--
+		p.err = ErrPaymentFailed
+	}
+	nd.payments[inv.hash] = p
+	return inv, p, nil
+}
+
+// finishPayment completes the payment of the invoice. It returns the fees paid.
+func (nd *Node) finishPayment(inv *invoice, p *payment, amount int64) (int64, error) {
+	n := nd.net
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	defer close(p.done)
+
+	if inv.mat != 0 {
+		amount = inv.mat
+	}
+
+	// Re-check the invoice state, because it might have expired or been
+	// canceled while the payment was inflight.
+	if p.err == nil {
+		if _, err := n.lookupHash(inv.hash); err != nil {
+			p.err = err
+		} else if inv.state == lnrpc.Invoice_CANCELED {
+			p.err = ErrInvoiceExpired
+		} else if inv.state != lnrpc.Invoice_OPEN {
+			p.err = ErrInvoiceNotOpen
+		}
+	}
+	fee := n.paymentFee(amount)
+	if p.err == nil && nd.balance < amount+fee {
+		p.err = ErrInsufficientBalance
+	}
+	if p.err != nil {
+		p.status = lnrpc.Payment_FAILED
+		nd.stats.PaymentsFailed++
+		return 0, p.err
+	}
+
+	// Transfer the funds.
+	nd.balance -= amount + fee
+	nd.stats.Sent += amount
+	nd.stats.Fees += fee
+	nd.stats.PaymentsSent++
+	inv.payee.balance += amount
+	inv.payee.stats.Received += amount
+	inv.payee.stats.PaymentsReceived++
+	p.status = lnrpc.Payment_SUCCEEDED
+
+	// Settle the invoice.
+	inv.state = lnrpc.Invoice_SETTLED
+	inv.paidMAt = amount
+	inv.settled = time.Now()
+	for _, cb := range inv.watchers {
+		go cb(amount)
+	}
+	inv.watchers = nil
+
+	return fee, nil
+}
+
+// pay pays the given invoice. The amount is only used on invoices that do not
+// specify an amount. It returns the fees paid.
+func (nd *Node) pay(ctx context.Context, payReq string, amount int64) (int64, error) {
+	inv, p, err := nd.startPayment(payReq, amount)
+	if err != nil {
+		return 0, fmt.Errorf("unable to complete LN payment: %w", err)
+	}
+
+	nd.net.mtx.Lock()
+	latency := nd.latency
+	nd.net.mtx.Unlock()
+
+	// Payments are not interrupted by the context being canceled, to
+	// simulate a payment that is still inflight after the caller gives
+	// up on it.
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-ctx.Done():
+			go func() {
+				time.Sleep(latency)
+				nd.finishPayment(inv, p, amount)
+			}()
+			return 0, ctx.Err()
+		}
+	}
+
+	fee, err := nd.finishPayment(inv, p, amount)
+	if err != nil {
+		return 0, fmt.Errorf("LN payment error: %w", err)
+	}
+	return fee, nil
+}
@@ -15,6 +15,20 @@ import (
 // interface.
 var ErrRMInvoicePayment = errors.New("invoice payment error on RM push")
 
+// ErrInsufficientCredit is generated on servers when a push or subscription
+// could not be paid from the prepaid credit of an account.
+//
+// Do not change this message as it's used in plain text across the C2S RPC
+// interface.
+var ErrInsufficientCredit = errors.New("insufficient prepaid credit")
+
+// ErrCreditAlreadyRedeemed is generated on servers when the invoice paid to
+// buy credit was already redeemed.
+//
+// Do not change this message as it's used in plain text across the C2S RPC
+// interface.
+var ErrCreditAlreadyRedeemed = errors.New("credit invoice already redeemed")
+
 const errUnpaidSubscriptionRVMsg = "unpaid subscription to RV"
 
 // ErrUnpaidSubscriptionRV is an error returned while attempting to subscribe
@@ -0,0 +1,115 @@
+package rpc
+
+import (
+	"bytes"
+	"crypto/sha256"
+)
+
+// The Merkle tree of a file is built over the hashes of its chunks. Leaf and
+// inner nodes are hashed with different prefixes, so that an inner node can't
+// be presented as a leaf. When a level has an odd number of nodes, the last
+// one is promoted unchanged to the next level.
+
+const (
+	merkleLeafPrefix  = 0x00
+	merkleInnerPrefix = 0x01
+)
+
+func merkleLeaf(chunkHash []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{merkleLeafPrefix})
+	h.Write(chunkHash)
+	return h.Sum(nil)
+}
+
+func merkleInner(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{merkleInnerPrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// merkleNextLevel returns the level of the tree above the given one.
+func merkleNextLevel(level [][]byte) [][]byte {
+	next := make([][]byte, 0, (len(level)+1)/2)
+	for i := 0; i < len(level); i += 2 {
+		if i+1 == len(level) {
+			next = append(next, level[i])
+		} else {
+			next = append(next, merkleInner(level[i], level[i+1]))
+		}
+	}
+	return next
+}
+
+func merkleLeaves(chunkHashes [][]byte) [][]byte {
+	level := make([][]byte, len(chunkHashes))
+	for i := range chunkHashes {
+		level[i] = merkleLeaf(chunkHashes[i])
+	}
+	return level
+}
+
+// MerkleRoot returns the root of the Merkle tree built over the given chunk
+// hashes.
+func MerkleRoot(chunkHashes [][]byte) []byte {
+	if len(chunkHashes) == 0 {
+		return nil
+	}
+	level := merkleLeaves(chunkHashes)
+	for len(level) > 1 {
+		level = merkleNextLevel(level)
+	}
+	return level[0]
+}
+
+// MerkleProof returns the proof that the chunk at the given index is included
+// in the Merkle tree built over the given chunk hashes. The proof is the list
+// of sibling nodes from the leaf up to the root.
+func MerkleProof(chunkHashes [][]byte, index int) [][]byte {
+	if index < 0 || index >= len(chunkHashes) {
+		return nil
+	}
+	var proof [][]byte
+	level := merkleLeaves(chunkHashes)
+	for len(level) > 1 {
+		switch {
+		case index%2 == 1:
+			proof = append(proof, level[index-1])
+		case index+1 < len(level):
+			proof = append(proof, level[index+1])
+		}
+		level = merkleNextLevel(level)
+		index /= 2
+	}
+	return proof
+}
+
+// VerifyMerkleProof returns true if the given proof shows that the chunk with
+// the given hash is at the specified index of a file with nbChunks chunks
+// and the given Merkle root.
+func VerifyMerkleProof(root, chunkHash []byte, index, nbChunks int, proof [][]byte) bool {
+	if index < 0 || index >= nbChunks {
+		return false
+	}
+	node := merkleLeaf(chunkHash)
+	for n := nbChunks; n > 1; n = (n + 1) / 2 {
+		switch {
+		case index%2 == 1:
+			if len(proof) == 0 {
+				return false
+			}
+			node = merkleInner(proof[0], node)
+			proof = proof[1:]
+		case index+1 < n:
+			if len(proof) == 0 {
+				return false
+			}
+			node = merkleInner(node, proof[0])
+			proof = proof[1:]
+		}
+		index /= 2
+	}
+	return len(proof) == 0 && bytes.Equal(node, root)
+}
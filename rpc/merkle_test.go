@@ -0,0 +1,116 @@
+package rpc
+
+import (
+	"crypto/sha256"
+	"testing"
+)
+
+// TestMerkleProofs tests that proofs generated for every chunk of files with
+// various number of chunks verify against the Merkle root, and that invalid
+// proofs are rejected.
+func TestMerkleProofs(t *testing.T) {
+	for nbChunks := 1; nbChunks <= 17; nbChunks++ {
+		hashes := make([][]byte, nbChunks)
+		for i := range hashes {
+			h := sha256.Sum256([]byte{byte(i)})
+			hashes[i] = h[:]
+		}
+		root := MerkleRoot(hashes)
+
+		for i := range hashes {
+			proof := MerkleProof(hashes, i)
+			if !VerifyMerkleProof(root, hashes[i], i, nbChunks, proof) {
+				t.Fatalf("%d/%d: valid proof failed to verify", i, nbChunks)
+			}
+
+			// Wrong chunk.
+			other := hashes[(i+1)%nbChunks]
+			if nbChunks > 1 && VerifyMerkleProof(root, other, i, nbChunks, proof) {
+				t.Fatalf("%d/%d: proof verified for wrong chunk", i, nbChunks)
+			}
+
+			// Wrong index.
+			if nbChunks > 1 && VerifyMerkleProof(root, hashes[i], (i+1)%nbChunks, nbChunks, proof) {
+				t.Fatalf("%d/%d: proof verified for wrong index", i, nbChunks)
+			}
+
+			// Out of bounds index.
+			if VerifyMerkleProof(root, hashes[i], nbChunks, nbChunks, proof) {
+				t.Fatalf("%d/%d: proof verified for out of bounds index", i, nbChunks)
+			}
+
+			// Extra proof element.
+			extra := append(append([][]byte{}, proof...), root)
+			if VerifyMerkleProof(root, hashes[i], i, nbChunks, extra) {
+				t.Fatalf("%d/%d: proof with extra element verified", i, nbChunks)
+			}
+		}
+	}
+}
+
+// TestMerkleMetadataHash tests that the metadata hash of Merkle metadata
+// commits to the Merkle root.
+func TestMerkleMetadataHash(t *testing.T) {
+	fm := FileMetadata{
+		Version:    FileMetadataVersionMerkle,
+		Size:       100,
+		ChunkSize:  30,
+		MerkleRoot: []byte{0x01},
+	}
+	if fm.ChunkCount() != 4 || fm.ChunkLen(3) != 10 || fm.ChunkLen(0) != 30 {
+		t.Fatalf("unexpected chunk sizes: %d %d %d", fm.ChunkCount(),
+			fm.ChunkLen(0), fm.ChunkLen(3))
+	}
+	h1 := fm.MetadataHash()
+	fm.MerkleRoot = []byte{0x02}
+	if h2 := fm.MetadataHash(); h1 == h2 {
+		t.Fatalf("metadata hash does not commit to the merkle root")
+	}
+}
+
+// TestCheckMerkle tests that inconsistent Merkle metadata is rejected.
+func TestCheckMerkle(t *testing.T) {
+	valid := FileMetadata{
+		Version:    FileMetadataVersionMerkle,
+		Size:       100,
+		ChunkSize:  30,
+		MerkleRoot: make([]byte, 32),
+	}
+	if err := valid.CheckMerkle(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		mod  func(fm *FileMetadata)
+	}{{
+		name: "zero chunk size",
+		mod:  func(fm *FileMetadata) { fm.ChunkSize = 0 },
+	}, {
+		name: "chunk size too large",
+		mod:  func(fm *FileMetadata) { fm.ChunkSize = MaxChunkSize + 1 },
+	}, {
+		name: "too many chunks",
+		mod: func(fm *FileMetadata) {
+			fm.ChunkSize = 1
+			fm.Size = MaxMerkleFileChunks + 1
+		},
+	}, {
+		name: "huge size",
+		mod: func(fm *FileMetadata) {
+			fm.ChunkSize = 1
+			fm.Size = ^uint64(0)
+		},
+	}, {
+		name: "short merkle root",
+		mod:  func(fm *FileMetadata) { fm.MerkleRoot = fm.MerkleRoot[:31] },
+	}}
+	for _, tc := range tests {
+		fm := valid
+		fm.MerkleRoot = make([]byte, 32)
+		tc.mod(&fm)
+		if err := fm.CheckMerkle(); err == nil {
+			t.Fatalf("%s: expected error", tc.name)
+		}
+	}
+}
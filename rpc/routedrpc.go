@@ -5,9 +5,11 @@ import (
 	"compress/zlib"
 	"crypto/sha256"
 	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
+	"strconv"
 	"time"
 
 	"github.com/companyzero/bisonrelay/ratchet"
@@ -127,6 +129,52 @@ type RMGetInvoice struct {
 	PayScheme  string
 	MilliAtoms uint64
 	Tag        uint32
+
+	// PaywalledPost is the optional ID of a paywalled post of the remote
+	// user that will be unlocked once the invoice is paid.
+	PaywalledPost *zkidentity.ShortID `json:"paywalled_post,omitempty"`
+
+	// Postage is set when the invoice is for prepaying the postage of
+	// messages sent to the remote user.
+	Postage bool `json:"postage,omitempty"`
+
+	// PostsSubscription is set when the invoice is for paying for a
+	// subscription to the posts of the remote user.
+	PostsSubscription bool `json:"posts_subscription,omitempty"`
+
+	// Tip is an optional memo sent along with a request for an invoice to
+	// pay a tip.
+	Tip *TipMemo `json:"tip,omitempty"`
+}
+
+// MaxTipMemoLen is the maximum length of the memo of a tip.
+const MaxTipMemoLen = 1024
+
+// TipMemo is a memo signed by the sender of a tip.
+type TipMemo struct {
+	Memo string `json:"memo,omitempty"`
+
+	// Post is the optional ID of the post of the remote user that is being
+	// tipped.
+	Post *zkidentity.ShortID `json:"post,omitempty"`
+
+	Signature string `json:"signature"`
+}
+
+// SignedHash returns the hash of the memo and the amount of the tip, which is
+// signed by the sender of the tip.
+func (tm *TipMemo) SignedHash(milliAtoms uint64) []byte {
+	h := sha256.New()
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], milliAtoms)
+	h.Write(b[:])
+	binary.LittleEndian.PutUint64(b[:], uint64(len(tm.Memo)))
+	h.Write(b[:])
+	h.Write([]byte(tm.Memo))
+	if tm.Post != nil {
+		h.Write(tm.Post[:])
+	}
+	return h.Sum(nil)
 }
 
 const RMCInvoice = "invoice"
@@ -137,6 +185,36 @@ type RMInvoice struct {
 	Error   *string `json:"error,omitempty"`
 }
 
+const RMCPostageRequired = "postagerequired"
+
+// RMPostageRequired is sent by a client to alert a remote user that their
+// messages are being held until postage is paid for them. MilliAtoms is the
+// postage of each message and Held is the number of currently held messages.
+type RMPostageRequired struct {
+	MilliAtoms uint64 `json:"milliatoms"`
+	Held       uint32 `json:"held"`
+}
+
+const RMCPaymentRequest = "paymentrequest"
+
+// RMPaymentRequest is sent by a client to request a payment from a remote
+// user. The remote user may pay the invoice or decline the request.
+type RMPaymentRequest struct {
+	ID         zkidentity.ShortID `json:"id"`
+	Invoice    string             `json:"invoice"`
+	MilliAtoms uint64             `json:"milliatoms"`
+	Memo       string             `json:"memo,omitempty"`
+}
+
+const RMCPaymentRequestDeclined = "paymentrequestdeclined"
+
+// RMPaymentRequestDeclined is sent by a client to alert the remote user that
+// their payment request was declined.
+type RMPaymentRequestDeclined struct {
+	ID     zkidentity.ShortID `json:"id"`
+	Reason string             `json:"reason,omitempty"`
+}
+
 const RMCKXSuggestion = "kxsuggestion"
 
 type RMKXSuggestion struct {
@@ -197,6 +275,15 @@ func ComposeCompressedRM(from *zkidentity.FullIdentity, rm interface{}, zlibLeve
 	case RMKXSuggestion:
 		h.Command = RMCKXSuggestion
 
+	case RMPostageRequired:
+		h.Command = RMCPostageRequired
+
+	case RMPaymentRequest:
+		h.Command = RMCPaymentRequest
+
+	case RMPaymentRequestDeclined:
+		h.Command = RMCPaymentRequestDeclined
+
 	// Group chat
 	case RMGroupInvite:
 		h.Command = RMCGroupInvite
@@ -250,6 +337,9 @@ func ComposeCompressedRM(from *zkidentity.FullIdentity, rm interface{}, zlibLeve
 	case RMFTSendFile:
 		h.Command = RMCFTSendFile
 
+	case RMFTCancel:
+		h.Command = RMCFTCancel
+
 	// User
 	case RMUser:
 		h.Command = RMCUser
@@ -457,6 +547,21 @@ func DecomposeRM(id *zkidentity.PublicIdentity, mb []byte) (*RMHeader, interface
 		err = pmd.Decode(&kxsg)
 		payload = kxsg
 
+	case RMCPostageRequired:
+		var pr RMPostageRequired
+		err = pmd.Decode(&pr)
+		payload = pr
+
+	case RMCPaymentRequest:
+		var pr RMPaymentRequest
+		err = pmd.Decode(&pr)
+		payload = pr
+
+	case RMCPaymentRequestDeclined:
+		var prd RMPaymentRequestDeclined
+		err = pmd.Decode(&prd)
+		payload = prd
+
 		// Group vhat
 	case RMCGroupInvite:
 		var groupInvite RMGroupInvite
@@ -539,6 +644,11 @@ func DecomposeRM(id *zkidentity.PublicIdentity, mb []byte) (*RMHeader, interface
 		err = pmd.Decode(&ftSendFile)
 		payload = ftSendFile
 
+	case RMCFTCancel:
+		var ftCancel RMFTCancel
+		err = pmd.Decode(&ftCancel)
+		payload = ftCancel
+
 	case RMCGroupMessage:
 		var groupMessage RMGroupMessage
 		err = pmd.Decode(&groupMessage)
@@ -786,6 +896,11 @@ type RMFTList struct {
 	Directories []string `json:"directories"`      // Which directories to obtain
 	Filter      string   `json:"filter,omitempty"` // Filter list by this regex
 	Tag         uint32   `json:"tag"`              // Tag to copy in replies
+
+	// Collection is the ID of a collection to browse. When set, the reply
+	// contains the full manifest of the collection instead of the list of
+	// files.
+	Collection string `json:"collection,omitempty"`
 }
 
 const (
@@ -812,9 +927,169 @@ type FileMetadata struct {
 	Manifest    []FileManifest    `json:"manifest"` // len == number of chunks
 	Signature   string            `json:"signature"`
 	Attributes  map[string]string `json:"attributes,omitempty"`
+
+	// MerkleRoot is the root of the Merkle tree built over the hashes of
+	// the chunks of the file. It is only set on metadata with version
+	// FileMetadataVersionMerkle, which do not include the Manifest.
+	MerkleRoot []byte `json:"merkle_root,omitempty"`
+
+	// ChunkSize is the size of every chunk of the file, except possibly
+	// the last one. It is only set on metadata with version
+	// FileMetadataVersionMerkle.
+	ChunkSize uint64 `json:"chunk_size,omitempty"`
+}
+
+const (
+	FileMetadataVersion = 1
+
+	// FileMetadataVersionMerkle is the version of file metadata that
+	// commits to the chunks of the file through a Merkle root, instead of
+	// listing every chunk in the manifest. Chunks of these files are sent
+	// along with a proof of their inclusion in the tree.
+	FileMetadataVersionMerkle = 2
+
+	// MaxMerkleFileChunks is the maximum number of chunks accepted in a
+	// file with FileMetadataVersionMerkle metadata.
+	MaxMerkleFileChunks = 1 << 20
+)
+
+// Well-known keys of FileMetadata.Attributes.
+const (
+	// FileAttrCompression is the algorithm the file was compressed with
+	// before being chunked. When set, the chunks (and Size and Hash) refer
+	// to the compressed file, which the downloader decompresses once all
+	// chunks have been received.
+	FileAttrCompression = "compression"
+
+	// FileAttrOriginalSize is the size of a compressed file before it was
+	// compressed.
+	FileAttrOriginalSize = "original_size"
+
+	// FileAttrMIMEType is the MIME type of the (uncompressed) file, as
+	// detected by the sharer.
+	FileAttrMIMEType = "mime_type"
+)
+
+// FileCompressionGzip is the FileAttrCompression value of files compressed
+// with gzip.
+const FileCompressionGzip = "gzip"
+
+// Compression returns the algorithm the file was compressed with before
+// being chunked, or an empty string if the file is not compressed.
+func (fm *FileMetadata) Compression() string {
+	return fm.Attributes[FileAttrCompression]
+}
+
+// OriginalSize returns the size of the file once it is decompressed. For
+// files that are not compressed, this is the same as Size.
+func (fm *FileMetadata) OriginalSize() uint64 {
+	if fm.Compression() == "" {
+		return fm.Size
+	}
+	size, err := strconv.ParseUint(fm.Attributes[FileAttrOriginalSize], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return size
+}
+
+// MIMEType returns the MIME type of the file, as detected by the sharer. It
+// returns an empty string if the type is unknown.
+func (fm *FileMetadata) MIMEType() string {
+	return fm.Attributes[FileAttrMIMEType]
+}
+
+// IsMerkle returns true if the chunks of the file are committed to by the
+// Merkle root (as opposed to the manifest).
+func (fm *FileMetadata) IsMerkle() bool {
+	return fm.Version >= FileMetadataVersionMerkle
+}
+
+// ChunkCount returns the number of chunks of the file.
+func (fm *FileMetadata) ChunkCount() int {
+	if !fm.IsMerkle() {
+		return len(fm.Manifest)
+	}
+	if fm.Size == 0 {
+		return 0
+	}
+	if fm.ChunkSize == 0 {
+		return 1
+	}
+	nbChunks := fm.Size / fm.ChunkSize
+	if fm.Size%fm.ChunkSize != 0 {
+		nbChunks++
+	}
+	return int(nbChunks)
+}
+
+// CheckMerkle returns an error if the fields of Merkle metadata are not
+// consistent. Merkle metadata is received from remote peers, so this must be
+// checked before using the metadata to size any buffers.
+func (fm *FileMetadata) CheckMerkle() error {
+	if !fm.IsMerkle() {
+		return nil
+	}
+	if len(fm.MerkleRoot) != 32 {
+		return fmt.Errorf("invalid merkle root length %d", len(fm.MerkleRoot))
+	}
+	if fm.ChunkSize == 0 || fm.ChunkSize > MaxChunkSize {
+		return fmt.Errorf("invalid chunk size %d", fm.ChunkSize)
+	}
+	nbChunks := fm.Size / fm.ChunkSize
+	if fm.Size%fm.ChunkSize != 0 {
+		nbChunks++
+	}
+	if nbChunks > MaxMerkleFileChunks {
+		return fmt.Errorf("too many chunks in file (%d > %d)", nbChunks,
+			MaxMerkleFileChunks)
+	}
+	return nil
+}
+
+// ChunkLen returns the size of the chunk at the given index.
+func (fm *FileMetadata) ChunkLen(index int) uint64 {
+	if index < 0 || index >= fm.ChunkCount() {
+		return 0
+	}
+	if !fm.IsMerkle() {
+		return fm.Manifest[index].Size
+	}
+	if fm.ChunkSize == 0 {
+		return fm.Size
+	}
+	if index == fm.ChunkCount()-1 && fm.Size%fm.ChunkSize != 0 {
+		return fm.Size % fm.ChunkSize
+	}
+	return fm.ChunkSize
 }
 
-const FileMetadataVersion = 1
+// ChunkHash returns the hash of the chunk at the given index, as listed in
+// the manifest. It returns nil for Merkle metadata, where chunk hashes are
+// only known once the chunk (and its inclusion proof) is received.
+func (fm *FileMetadata) ChunkHash(index int) []byte {
+	if fm.IsMerkle() || index < 0 || index >= len(fm.Manifest) {
+		return nil
+	}
+	return fm.Manifest[index].Hash
+}
+
+// SignedHash returns the hash that is signed by the sharer of the file. For
+// Merkle metadata, this covers both the hash of the file contents and the
+// Merkle root.
+func (fm *FileMetadata) SignedHash() ([]byte, error) {
+	fileHash, err := hex.DecodeString(fm.Hash)
+	if err != nil {
+		return nil, err
+	}
+	if !fm.IsMerkle() {
+		return fileHash, nil
+	}
+	h := sha256.New()
+	h.Write(fileHash)
+	h.Write(fm.MerkleRoot)
+	return h.Sum(nil), nil
+}
 
 // MetadataHash calculates the hash of the metadata info. Note that the specific
 // information that is hashed depends on the version of the metadata.
@@ -839,16 +1114,100 @@ func (fm *FileMetadata) MetadataHash() [32]byte {
 
 	// In the future, add new fields conditional on the metadata version so
 	// that old versions will still calculate the same hash.
+	if fm.IsMerkle() {
+		h.Write(fm.MerkleRoot)
+		writeUint64(fm.ChunkSize)
+	}
 
 	copy(b[:], h.Sum(nil))
 	return b
 }
 
+// CollectionFile is a member file of a FileCollection.
+type CollectionFile struct {
+	Path   string `json:"path"` // Slash-separated, relative to the collection
+	FileID string `json:"file_id"`
+	Size   uint64 `json:"size"`
+	Cost   uint64 `json:"cost"`
+}
+
+// FileCollection is the signed manifest of a collection of shared files (for
+// example, a shared directory). Member files are fetched individually, by
+// their file IDs.
+type FileCollection struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Size        uint64           `json:"size"` // Total size of all files
+	Cost        uint64           `json:"cost"` // Total cost of all files
+	Files       []CollectionFile `json:"files"`
+	Signature   string           `json:"signature"`
+}
+
+// SignedHash returns the hash of the contents of the collection, which is
+// signed by its sharer.
+func (fc *FileCollection) SignedHash() []byte {
+	h := sha256.New()
+	var b [8]byte
+
+	writeUint64 := func(i uint64) {
+		binary.LittleEndian.PutUint64(b[:], i)
+		h.Write(b[:])
+	}
+
+	// Strings are prefixed by their length so that the hash is not
+	// ambiguous.
+	writeStr := func(s string) {
+		writeUint64(uint64(len(s)))
+		h.Write([]byte(s))
+	}
+
+	writeStr(fc.Name)
+	writeStr(fc.Description)
+	writeUint64(fc.Size)
+	writeUint64(fc.Cost)
+	writeUint64(uint64(len(fc.Files)))
+	for _, f := range fc.Files {
+		writeStr(f.Path)
+		writeStr(f.FileID)
+		writeUint64(f.Size)
+		writeUint64(f.Cost)
+	}
+	return h.Sum(nil)
+}
+
+// ID returns the ID of the collection, which commits to both its contents and
+// signature.
+func (fc *FileCollection) ID() [32]byte {
+	h := sha256.New()
+	h.Write(fc.SignedHash())
+	h.Write([]byte(fc.Signature))
+	var id [32]byte
+	copy(id[:], h.Sum(nil))
+	return id
+}
+
+// CollectionSummary summarizes a collection in file listings.
+type CollectionSummary struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Size        uint64 `json:"size"`
+	Cost        uint64 `json:"cost"`
+	NbFiles     int    `json:"nb_files"`
+}
+
 type RMFTListReply struct {
 	Global []FileMetadata `json:"global,omitempty"`
 	Shared []FileMetadata `json:"shared,omitempty"`
 	Tag    uint32
 	Error  *string `json:"error,omitempty"`
+
+	// Collections lists the collections in the requested directories.
+	Collections []CollectionSummary `json:"collections,omitempty"`
+
+	// Collection is the manifest of the collection requested with
+	// RMFTList.Collection.
+	Collection *FileCollection `json:"collection,omitempty"`
 }
 
 const RMCFTListReply = "ftlsreply"
@@ -875,9 +1234,13 @@ const RMCFTGetReply = "ftgetreply"
 // RMFTGetChunk attempts to retrieve a file chunk from another user.
 type RMFTGetChunk struct {
 	FileID string `json:"file_id"`
-	Hash   []byte `json:"hash"` // Chunk to retrieve
+	Hash   []byte `json:"hash"` // Chunk to retrieve (unset for Merkle metadata)
 	Index  int    `json:"index"`
 	Tag    uint32 `json:"tag"` // Tag to copy in replies
+
+	// ExpiredInvoice is set when requesting a new invoice for a chunk,
+	// after the previously sent invoice expired before it could be paid.
+	ExpiredInvoice string `json:"expired_invoice,omitempty"`
 }
 
 const RMCFTGetChunk = "ftgetchunk"
@@ -889,6 +1252,10 @@ type RMFTGetChunkReply struct {
 	Chunk  []byte  `json:"chunk"` // Actual data, needs to be hashed to verify
 	Tag    uint32  `json:"tag"`
 	Error  *string `json:"error,omitempty"`
+
+	// Proof is the Merkle inclusion proof of the chunk. Only sent for
+	// files with FileMetadataVersionMerkle metadata.
+	Proof [][]byte `json:"proof,omitempty"`
 }
 
 const RMCFTGetChunkReply = "ftgetchunkreply"
@@ -910,6 +1277,19 @@ type RMFTSendFile struct {
 
 const RMCFTSendFile = "ftsendfile"
 
+// RMFTCancel is sent by either side of a file transfer to signal the transfer
+// was canceled and that the remote peer should stop sending further requests,
+// invoices or chunks related to it.
+type RMFTCancel struct {
+	FileID string `json:"file_id"`
+
+	// Upload is true when the sender of the message canceled an upload to
+	// the receiver (and false when it canceled a download).
+	Upload bool `json:"upload"`
+}
+
+const RMCFTCancel = "ftcancel"
+
 // RMUser retrieves user attributes such as status, profile etc. Attributes is a
 // key value store that is used to describe the user attributes.
 type RMUser struct{}
@@ -985,6 +1365,23 @@ const RMCPostsSubscribe = "postssubscribe"
 
 type RMPostsSubscribeReply struct {
 	Error *string `json:"error,omitempty"`
+
+	// Price is set when a payment is required to subscribe to (or to
+	// renew the subscription to) the posts of the user. In this case,
+	// Error is also set.
+	Price *PostsSubscriptionPrice `json:"price,omitempty"`
+}
+
+// PostsSubscriptionPrice is the price to subscribe to the posts of a user for
+// a given period.
+type PostsSubscriptionPrice struct {
+	MilliAtoms uint64 `json:"milliatoms"`
+	PeriodSecs uint64 `json:"period_secs"`
+}
+
+// Period returns the subscription period as a duration.
+func (p PostsSubscriptionPrice) Period() time.Duration {
+	return time.Duration(p.PeriodSecs) * time.Second
 }
 
 const RMCPostsSubscribeReply = "postssubscribereply"
@@ -1035,6 +1432,8 @@ const (
 	RMPNonce       = "nonce"       // Random nonce to avoid equal hashes
 	RMPFromNick    = "from_nick"   // Nick of origin for post/status
 	RMPTimestamp   = "timestamp"   // Timestamp of the status update
+	RMPPrice       = "price"       // Price (in milliatoms) of a paywalled body
+	RMPBodyHash    = "bodyhash"    // Hash of a paywalled body
 )
 
 type PostMetadata struct {
@@ -1064,12 +1463,30 @@ func (pm *PostMetadata) Hash() [32]byte {
 
 	// Gate newer fields with a version check to ensure older copies of the
 	// metadata still hash to the same value.
+	if pm.Version >= PostMetadataVersionPaywall {
+		wattr(RMPPrice)
+		wattr(RMPBodyHash)
+	}
+
 	copy(b[:], h.Sum(nil))
 	return b
 }
 
 const PostMetadataVersion = 1
 
+// PostMetadataVersionPaywall is the version of posts that have a paywalled
+// body. The post metadata of these posts commit to the RMPPrice and
+// RMPBodyHash attributes, while the body itself is only sent (in an
+// RMPostGetReply) after the reader pays for it.
+const PostMetadataVersionPaywall = 2
+
+// PostBodyHash returns the hash of a paywalled post body, as stored in the
+// RMPBodyHash attribute.
+func PostBodyHash(body string) string {
+	h := sha256.Sum256([]byte(body))
+	return hex.EncodeToString(h[:])
+}
+
 type PostMetadataStatus struct {
 	Version    uint64            `json:"version"`
 	From       string            `json:"from"` // Who sent update
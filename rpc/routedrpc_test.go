@@ -370,3 +370,35 @@ func TestMetadataStatusHash(t *testing.T) {
 		})
 	}
 }
+
+// TestPostMetadataPaywallHash asserts that the paywall attributes are only
+// committed to by the hash of paywalled post versions.
+func TestPostMetadataPaywallHash(t *testing.T) {
+	attrs := map[string]string{RMPMain: "summary"}
+	paywallAttrs := map[string]string{
+		RMPMain:     "summary",
+		RMPPrice:    "1000",
+		RMPBodyHash: PostBodyHash("body"),
+	}
+
+	// Version 1 posts are not affected by the paywall attributes.
+	pm1 := PostMetadata{Version: PostMetadataVersion, Attributes: attrs}
+	pm2 := PostMetadata{Version: PostMetadataVersion, Attributes: paywallAttrs}
+	if pm1.Hash() != pm2.Hash() {
+		t.Fatalf("v1 hash changed due to paywall attributes")
+	}
+
+	// Paywalled posts commit to both the price and the body hash.
+	pm1 = PostMetadata{Version: PostMetadataVersionPaywall, Attributes: paywallAttrs}
+	h := pm1.Hash()
+	for _, k := range []string{RMPPrice, RMPBodyHash} {
+		pm2 := PostMetadata{Version: PostMetadataVersionPaywall, Attributes: map[string]string{}}
+		for k, v := range paywallAttrs {
+			pm2.Attributes[k] = v
+		}
+		pm2.Attributes[k] = "changed"
+		if pm2.Hash() == h {
+			t.Fatalf("paywalled hash does not commit to %s", k)
+		}
+	}
+}
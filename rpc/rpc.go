@@ -14,11 +14,15 @@
 package rpc
 
 import (
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/binary"
+	"fmt"
 	"strconv"
 	"time"
 
 	"github.com/companyzero/bisonrelay/ratchet"
+	"github.com/companyzero/bisonrelay/zkidentity"
 )
 
 type MessageMode uint32
@@ -48,6 +52,9 @@ const (
 
 	TaggedCmdPushRoutedMessage = "pushroutedmessage"
 
+	TaggedCmdGetCreditBalance      = "getcreditbalance"
+	TaggedCmdGetCreditBalanceReply = "getcreditbalancereply"
+
 	// misc
 	MessageModeNormal MessageMode = 0
 	MessageModeMe     MessageMode = 1
@@ -72,7 +79,8 @@ const (
 	MaxMsgSize = 1887437 // ~1.8 MiB
 
 	// MinRMPushPayment is the minimum payment amount required to push a payment
-	// to the server (in milliatoms).
+	// to the server (in milliatoms). Servers may advertise a different
+	// minimum with the PropMinPushPayment property.
 	MinRMPushPayment uint64 = 1000
 
 	// InvoiceExpiryAffordance is the time before the expiry a client may
@@ -101,21 +109,32 @@ type RouteMessage struct {
 	Rendezvous    ratchet.RVPoint
 	PaidInvoiceID []byte
 	Message       []byte
+
+	// CreditAccount is set when the push is paid from the prepaid credit
+	// of the account instead of with PaidInvoiceID.
+	CreditAccount *zkidentity.ShortID `json:",omitempty"`
 }
 
 type RouteMessageReply struct {
 	Error       string
 	NextInvoice string
+	Receipt     *CreditReceipt `json:",omitempty"`
 }
 
 type SubscribeRoutedMessages struct {
 	AddRendezvous []ratchet.RVPoint // Add to subscribed RVs
 	DelRendezvous []ratchet.RVPoint // Del from subscribed RVs
+
+	// CreditAccount is set when new subscriptions that were not paid with
+	// the last invoice should be paid from the prepaid credit of the
+	// account.
+	CreditAccount *zkidentity.ShortID `json:",omitempty"`
 }
 
 type SubscribeRoutedMessagesReply struct {
 	NextInvoice string
 	Error       string
+	Receipt     *CreditReceipt `json:",omitempty"`
 }
 
 type PushRoutedMessage struct {
@@ -137,8 +156,9 @@ type Acknowledge struct {
 type GetInvoiceAction string
 
 const (
-	InvoiceActionPush GetInvoiceAction = "push"
-	InvoiceActionSub  GetInvoiceAction = "sub"
+	InvoiceActionPush   GetInvoiceAction = "push"
+	InvoiceActionSub    GetInvoiceAction = "sub"
+	InvoiceActionCredit GetInvoiceAction = "credit"
 )
 
 type GetInvoice struct {
@@ -150,6 +170,55 @@ type GetInvoiceReply struct {
 	Invoice string // Depends on payment scheme
 }
 
+// GetCreditBalance requests the balance of a prepaid credit account. If
+// PaidInvoiceID is set, the amount paid to that invoice (which must have been
+// requested with InvoiceActionCredit) is first added to the balance.
+type GetCreditBalance struct {
+	Account       zkidentity.ShortID
+	PaidInvoiceID []byte `json:",omitempty"`
+}
+
+type GetCreditBalanceReply struct {
+	Balance uint64         // In milliatoms
+	Receipt *CreditReceipt `json:",omitempty"`
+	Error   string
+}
+
+// CreditReceipt is issued and signed by the server whenever the balance of a
+// prepaid credit account changes.
+type CreditReceipt struct {
+	Account zkidentity.ShortID
+	Action  GetInvoiceAction
+
+	// MilliAtoms is positive when credit was added and negative when it
+	// was debited.
+	MilliAtoms int64
+	Balance    uint64
+	Timestamp  int64
+	Signature  zkidentity.FixedSizeSignature
+}
+
+// SignedHash returns the hash of the receipt that is signed by the server.
+func (r *CreditReceipt) SignedHash() []byte {
+	h := sha256.New()
+	h.Write(r.Account[:])
+	h.Write([]byte(r.Action))
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], uint64(r.MilliAtoms))
+	h.Write(b[:])
+	binary.LittleEndian.PutUint64(b[:], r.Balance)
+	h.Write(b[:])
+	binary.LittleEndian.PutUint64(b[:], uint64(r.Timestamp))
+	h.Write(b[:])
+	return h.Sum(nil)
+}
+
+// String returns a description of the receipt suitable for logging.
+func (r *CreditReceipt) String() string {
+	return fmt.Sprintf("%s %s %d MAtoms (balance %d MAtoms)", r.Account,
+		r.Action, r.MilliAtoms, r.Balance)
+}
+
 const (
 	ProtocolVersion = 10
 )
@@ -192,6 +261,20 @@ const (
 	PropPushPaymentRate        = "pushpayrate"
 	PropPushPaymentRateDefault = 100 // MilliAtoms/byte
 
+	// PropPushPaymentRateKB is the required payment rate to push RMs when
+	// the payment scheme is not free (in milli-atoms per kilobyte). This
+	// allows rates lower than one milliatom per byte. When specified, it
+	// takes precedence over PropPushPaymentRate, which is still sent for
+	// older clients.
+	PropPushPaymentRateKB        = "pushpayratekb"
+	PropPushPaymentRateKBDefault = PropPushPaymentRateDefault * 1000 // MilliAtoms/KB
+
+	// PropMinPushPayment is the minimum payment amount (in milli-atoms)
+	// required to push an RM. Older clients assume this is
+	// MinRMPushPayment.
+	PropMinPushPayment        = "minpushpayment"
+	PropMinPushPaymentDefault = MinRMPushPayment
+
 	// Sub payment rate is the required payment rate to sub to RVs when the
 	// payment scheme is not free (in milli-atoms per byte).
 	PropSubPaymentRate        = "subpayrate"
@@ -216,6 +299,10 @@ const (
 	// for them.
 	PropMaxPushInvoices        = "maxpushinvoices"
 	PropMaxPushInvoicesDefault = 8
+
+	// PropCreditEnabled is set when the server accepts payments for pushes
+	// and subscriptions from prepaid credit accounts.
+	PropCreditEnabled = "creditenabled"
 )
 
 var (
@@ -268,6 +355,21 @@ var (
 	}
 
 	// optional
+	DefaultPropPushPaymentRateKB = ServerProperty{
+		Key:      PropPushPaymentRateKB,
+		Value:    strconv.Itoa(PropPushPaymentRateKBDefault),
+		Required: false,
+	}
+	DefaultPropMinPushPayment = ServerProperty{
+		Key:      PropMinPushPayment,
+		Value:    strconv.FormatUint(PropMinPushPaymentDefault, 10),
+		Required: false,
+	}
+	DefaultPropCreditEnabled = ServerProperty{
+		Key:      PropCreditEnabled,
+		Value:    "1",
+		Required: false,
+	}
 	DefaultPropServerLNNode = ServerProperty{
 		Key:      PropServerLNNode,
 		Value:    "",
@@ -290,9 +392,31 @@ var (
 
 		// optional
 		DefaultPropServerLNNode,
+		DefaultPropPushPaymentRateKB,
+		DefaultPropMinPushPayment,
 	}
 )
 
+// PushPaymentAmount returns the amount (in milliatoms) to pay to push an RM
+// with the given size at the given push payment rate (in milliatoms per
+// kilobyte), respecting the minimum push payment. Fractions of milliatoms are
+// rounded up.
+func PushPaymentAmount(msgLen, rateKB, minPayment uint64) uint64 {
+	amt := (msgLen*rateKB + 999) / 1000
+	if amt < minPayment {
+		amt = minPayment
+	}
+	return amt
+}
+
+// LegacyPushPaymentRate returns the push payment rate (in milliatoms per byte)
+// to advertise to clients that do not support PropPushPaymentRateKB, given
+// the rate in milliatoms per kilobyte. The rate is rounded up, so that older
+// clients never pay less than required.
+func LegacyPushPaymentRate(rateKB uint64) uint64 {
+	return (rateKB + 999) / 1000
+}
+
 // Ping is a PRPC that is used to determine if the server is alive.
 // This command must be acknowledged by the remote side.
 type Ping struct{}
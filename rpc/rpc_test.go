@@ -0,0 +1,67 @@
+package rpc
+
+import "testing"
+
+// TestPushPaymentAmount tests the push payment amount calculation.
+func TestPushPaymentAmount(t *testing.T) {
+	tests := []struct {
+		name       string
+		msgLen     uint64
+		rateKB     uint64
+		minPayment uint64
+		want       uint64
+	}{{
+		name:       "default rate",
+		msgLen:     1000,
+		rateKB:     PropPushPaymentRateKBDefault,
+		minPayment: PropMinPushPaymentDefault,
+		want:       100000,
+	}, {
+		name:       "below min payment",
+		msgLen:     5,
+		rateKB:     PropPushPaymentRateKBDefault,
+		minPayment: PropMinPushPaymentDefault,
+		want:       PropMinPushPaymentDefault,
+	}, {
+		name:       "sub-milliatom per byte rate",
+		msgLen:     10000,
+		rateKB:     50,
+		minPayment: 0,
+		want:       500,
+	}, {
+		name:       "fraction rounded up",
+		msgLen:     1001,
+		rateKB:     50,
+		minPayment: 0,
+		want:       51,
+	}, {
+		name:       "custom min payment",
+		msgLen:     10000,
+		rateKB:     50,
+		minPayment: 600,
+		want:       600,
+	}}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got := PushPaymentAmount(tc.msgLen, tc.rateKB, tc.minPayment)
+			if got != tc.want {
+				t.Fatalf("unexpected amount: got %d, want %d", got, tc.want)
+			}
+		})
+	}
+
+	// The legacy rate never makes older clients pay less than required.
+	for _, rateKB := range []uint64{1, 50, 999, 1000, 1001, 100000} {
+		legacy := LegacyPushPaymentRate(rateKB)
+		for _, msgLen := range []uint64{1, 999, 1000, 12345} {
+			want := PushPaymentAmount(msgLen, rateKB, 0)
+			if got := msgLen * legacy; got < want {
+				t.Fatalf("legacy rate %d underpays msg of len %d "+
+					"at rate %d/KB: %d < %d", legacy, msgLen,
+					rateKB, got, want)
+			}
+		}
+	}
+}
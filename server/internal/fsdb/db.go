@@ -8,6 +8,8 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/companyzero/bisonrelay/ratchet"
@@ -18,6 +20,11 @@ type fsdb struct {
 	rootMsgs                 string
 	rootSubs                 string
 	rootRedeemedPushPayments string
+	rootRedeemedCredits      string
+	rootCredits              string
+
+	// creditMtx serializes changes to the credit balances.
+	creditMtx sync.Mutex
 }
 
 func NewFSDB(rootMsgs, rootSubs string) (serverdb.ServerDB, error) {
@@ -33,11 +40,21 @@ func NewFSDB(rootMsgs, rootSubs string) (serverdb.ServerDB, error) {
 	if err := os.MkdirAll(rootRedeemedPushPayments, 0700); err != nil {
 		return nil, err
 	}
+	rootRedeemedCredits := filepath.Join(rootMsgs, "redeemedCredits")
+	if err := os.MkdirAll(rootRedeemedCredits, 0700); err != nil {
+		return nil, err
+	}
+	rootCredits := filepath.Join(rootMsgs, "credits")
+	if err := os.MkdirAll(rootCredits, 0700); err != nil {
+		return nil, err
+	}
 
 	return &fsdb{
 		rootMsgs:                 rootMsgs,
 		rootSubs:                 rootSubs,
 		rootRedeemedPushPayments: rootRedeemedPushPayments,
+		rootRedeemedCredits:      rootRedeemedCredits,
+		rootCredits:              rootCredits,
 	}, nil
 }
 
@@ -117,6 +134,95 @@ func (db *fsdb) StorePushPaymentRedeemed(ctx context.Context, payID []byte, inse
 	return os.WriteFile(fname, content, 0o600)
 }
 
+func (db *fsdb) readCreditBalance(account []byte) (uint64, error) {
+	fname := filepath.Join(db.rootCredits, hex.EncodeToString(account))
+	content, err := os.ReadFile(fname)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(string(content), 10, 64)
+}
+
+func (db *fsdb) writeCreditBalance(account []byte, balance uint64) error {
+	fname := filepath.Join(db.rootCredits, hex.EncodeToString(account))
+	content := []byte(strconv.FormatUint(balance, 10))
+	return os.WriteFile(fname, content, 0o600)
+}
+
+func (db *fsdb) CreditBalance(ctx context.Context, account []byte) (uint64, error) {
+	db.creditMtx.Lock()
+	defer db.creditMtx.Unlock()
+	return db.readCreditBalance(account)
+}
+
+func (db *fsdb) AddCredit(ctx context.Context, account []byte, amount uint64) (uint64, error) {
+	db.creditMtx.Lock()
+	defer db.creditMtx.Unlock()
+	balance, err := db.readCreditBalance(account)
+	if err != nil {
+		return 0, err
+	}
+	balance += amount
+	return balance, db.writeCreditBalance(account, balance)
+}
+
+func (db *fsdb) RedeemCredit(ctx context.Context, account, payID []byte,
+	amount uint64, insertTime time.Time) (uint64, error) {
+
+	db.creditMtx.Lock()
+	defer db.creditMtx.Unlock()
+
+	// Creating the file with O_EXCL ensures the payment is redeemed only
+	// once.
+	fname := filepath.Join(db.rootRedeemedCredits, hex.EncodeToString(payID))
+	f, err := os.OpenFile(fname, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if os.IsExist(err) {
+		return 0, serverdb.ErrAlreadyRedeemed
+	}
+	if err != nil {
+		return 0, err
+	}
+	content, err := insertTime.MarshalJSON()
+	if err == nil {
+		_, err = f.Write(content)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	var balance uint64
+	if err == nil {
+		balance, err = db.readCreditBalance(account)
+	}
+	if err == nil {
+		balance += amount
+		err = db.writeCreditBalance(account, balance)
+	}
+	if err != nil {
+		// No credit was added, so allow redeeming the payment again.
+		_ = os.Remove(fname)
+		return 0, err
+	}
+	return balance, nil
+}
+
+func (db *fsdb) DebitCredit(ctx context.Context, account []byte, amount uint64) (uint64, error) {
+	db.creditMtx.Lock()
+	defer db.creditMtx.Unlock()
+	balance, err := db.readCreditBalance(account)
+	if err != nil {
+		return 0, err
+	}
+	if balance < amount {
+		return balance, serverdb.ErrInsufficientCredit
+	}
+	balance -= amount
+	return balance, db.writeCreditBalance(account, balance)
+}
+
 // Expire the old messages from the specified date.
 //
 // Note: this is currently significantly slow, as it involves listing all
@@ -43,7 +43,7 @@ const (
 
 const (
 	// currentDBVersion indicates the current database version.
-	currentDBVersion = 3
+	currentDBVersion = 4
 
 	// pgDateFormat is the format string to use when specifying the date ranges
 	// for partitions in the format Postgres understands such that they refer to
@@ -313,6 +313,35 @@ func (db *DB) createRedeemedPushPaymentsQuery() string {
 	return fmt.Sprintf(query, tablespace)
 }
 
+// createCreditBalancesQuery returns a SQL query that creates the table that
+// tracks the balances of prepaid credit accounts if it does not already exist.
+// This table is not partitioned, because balances do not expire.
+func (db *DB) createCreditBalancesQuery() string {
+	const query = "CREATE TABLE IF NOT EXISTS credit_balances (" +
+		"	account TEXT NOT NULL," +
+		"	balance BIGINT NOT NULL CHECK (balance >= 0)," +
+		"	updated TIMESTAMP NOT NULL DEFAULT current_timestamp," +
+		"	PRIMARY KEY(account) USING INDEX TABLESPACE %s" +
+		") TABLESPACE %s;"
+	return fmt.Sprintf(query, pq.QuoteIdentifier(db.indexTablespace),
+		pq.QuoteIdentifier(db.bulkDataTablespace))
+}
+
+// createRedeemedCreditPaymentsQuery returns a SQL query that creates the table
+// that tracks payments redeemed for credit if it does not already exist. This
+// table is not partitioned, so that a payment may never be redeemed twice.
+func (db *DB) createRedeemedCreditPaymentsQuery() string {
+	const query = "CREATE TABLE IF NOT EXISTS redeemed_credit_payments (" +
+		"	payment_id TEXT NOT NULL," +
+		"	account TEXT NOT NULL," +
+		"	amount BIGINT NOT NULL," +
+		"	insert_ts TIMESTAMP NOT NULL DEFAULT current_timestamp," +
+		"	PRIMARY KEY(payment_id) USING INDEX TABLESPACE %s" +
+		") TABLESPACE %s;"
+	return fmt.Sprintf(query, pq.QuoteIdentifier(db.indexTablespace),
+		pq.QuoteIdentifier(db.bulkDataTablespace))
+}
+
 // procedureExists returns whether or not the provided stored procedure exists.
 func procedureExists(tx *sql.Tx, procName string) (bool, error) {
 	//	--SELECT * FROM information_schema.routines WHERE routine_name = 'global_data_rv_unique';
@@ -458,7 +487,9 @@ func (db *DB) discoverExistingRedeemedPushPayments(tx *sql.Tx) error {
 // all possible upgrades iteratively.
 //
 // NOTE: The passed database info will be updated with the latest versions.
-func upgradeDB(ctx context.Context, tx *sql.Tx, dbInfo *databaseInfo, indexTablespace string) error {
+func upgradeDB(ctx context.Context, tx *sql.Tx, dbInfo *databaseInfo, indexTablespace,
+	bulkTablespace string) error {
+
 	if dbInfo.version == 1 {
 		if err := upgradeDBToV2(ctx, tx, dbInfo); err != nil {
 			return err
@@ -479,6 +510,16 @@ func upgradeDB(ctx context.Context, tx *sql.Tx, dbInfo *databaseInfo, indexTable
 		}
 	}
 
+	if dbInfo.version == 3 {
+		if err := upgradeDBToV4(ctx, tx, dbInfo, indexTablespace, bulkTablespace); err != nil {
+			return err
+		}
+		dbInfo.version = 4
+		if err := updateDatabaseInfo(tx, dbInfo); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -576,6 +617,19 @@ func (db *DB) initDB(ctx context.Context, tx *sql.Tx) error {
 			return contextError(ErrQueryFailed, str, err)
 		}
 
+		// Create the credit balances table if needed.
+		_, err = tx.Exec(db.createCreditBalancesQuery())
+		if err != nil {
+			str := fmt.Sprintf("unable to create credit balances table: %v", err)
+			return contextError(ErrQueryFailed, str, err)
+		}
+
+		// Create the redeemed credit payments table if needed.
+		_, err = tx.Exec(db.createRedeemedCreditPaymentsQuery())
+		if err != nil {
+			str := fmt.Sprintf("unable to create redeemed credit payments table: %v", err)
+			return contextError(ErrQueryFailed, str, err)
+		}
 	}
 
 	if db.dbInfo.version > currentDBVersion {
@@ -586,7 +640,7 @@ func (db *DB) initDB(ctx context.Context, tx *sql.Tx) error {
 	}
 
 	// Upgrade the database if needed.
-	if err := upgradeDB(ctx, tx, db.dbInfo, db.indexTablespace); err != nil {
+	if err := upgradeDB(ctx, tx, db.dbInfo, db.indexTablespace, db.bulkDataTablespace); err != nil {
 		return err
 	}
 
@@ -709,7 +763,8 @@ func (db *DB) checkDatabaseSanity(tx *sql.Tx) error {
 	}
 
 	// Ensure the virtual partitioned tables exist.
-	tables := []string{"data", "paid_subs", "redeemed_push_payments"}
+	tables := []string{"data", "paid_subs", "redeemed_push_payments",
+		"credit_balances", "redeemed_credit_payments"}
 	for _, tableName := range tables {
 		// Ensure the main virtual partitioned data table exists.
 		exists, err := tableExists(tx, tableName)
@@ -1251,6 +1306,127 @@ func (db *DB) StorePushPaymentRedeemed(ctx context.Context, payID []byte, insert
 	return nil
 }
 
+// CreditBalance returns the balance of the given credit account. Accounts that
+// do not exist have a zero balance.
+func (db *DB) CreditBalance(ctx context.Context, account []byte) (uint64, error) {
+	ctx, task := trace.NewTask(ctx, "creditBalance")
+	defer task.End()
+
+	const query = "SELECT balance FROM credit_balances WHERE account = $1;"
+	row := db.db.QueryRowContext(ctx, query, hex.EncodeToString(account))
+	var balance int64
+	if err := row.Scan(&balance); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+
+		str := fmt.Sprintf("unable to fetch credit balance: %v", err)
+		return 0, contextError(ErrQueryFailed, str, err)
+	}
+
+	return uint64(balance), nil
+}
+
+// addCreditQuery adds to the balance of a credit account, creating it if
+// needed, and returns the new balance.
+const addCreditQuery = "INSERT INTO credit_balances (account, balance, updated) " +
+	"VALUES ($1, $2, $3) " +
+	"ON CONFLICT (account) DO UPDATE SET " +
+	"balance = credit_balances.balance + $2, updated = $3 " +
+	"RETURNING balance;"
+
+// AddCredit adds the given amount to the balance of the credit account,
+// creating the account if needed. It returns the new balance.
+func (db *DB) AddCredit(ctx context.Context, account []byte, amount uint64) (uint64, error) {
+	ctx, task := trace.NewTask(ctx, "addCredit")
+	defer task.End()
+
+	row := db.db.QueryRowContext(ctx, addCreditQuery,
+		hex.EncodeToString(account), int64(amount), time.Now().UTC())
+	var balance int64
+	if err := row.Scan(&balance); err != nil {
+		str := fmt.Sprintf("unable to add credit: %v", err)
+		return 0, contextError(ErrQueryFailed, str, err)
+	}
+
+	return uint64(balance), nil
+}
+
+// RedeemCredit records the payment with the given ID as redeemed for credit and
+// adds the amount to the balance of the credit account in a single
+// transaction. It returns the new balance or serverdb.ErrAlreadyRedeemed if
+// the payment was already redeemed.
+func (db *DB) RedeemCredit(ctx context.Context, account, payID []byte,
+	amount uint64, insertTime time.Time) (uint64, error) {
+
+	ctx, task := trace.NewTask(ctx, "redeemCredit")
+	defer task.End()
+
+	var balance int64
+	err := db.sqlTx(ctx, func(tx *sql.Tx) error {
+		// A concurrent redemption of the same payment blocks until the
+		// other transaction finishes, so at most one of them inserts
+		// the payment.
+		const query = "INSERT INTO redeemed_credit_payments " +
+			"(payment_id, account, amount, insert_ts) " +
+			"VALUES ($1, $2, $3, $4) " +
+			"ON CONFLICT (payment_id) DO NOTHING;"
+		res, err := tx.ExecContext(ctx, query, hex.EncodeToString(payID),
+			hex.EncodeToString(account), int64(amount), insertTime.UTC())
+		if err != nil {
+			str := fmt.Sprintf("unable to mark credit payment as redeemed: %v", err)
+			return contextError(ErrQueryFailed, str, err)
+		}
+		inserted, err := res.RowsAffected()
+		if err != nil {
+			str := fmt.Sprintf("unable to mark credit payment as redeemed: %v", err)
+			return contextError(ErrQueryFailed, str, err)
+		}
+		if inserted == 0 {
+			return serverdb.ErrAlreadyRedeemed
+		}
+
+		row := tx.QueryRowContext(ctx, addCreditQuery,
+			hex.EncodeToString(account), int64(amount), time.Now().UTC())
+		if err := row.Scan(&balance); err != nil {
+			str := fmt.Sprintf("unable to add credit: %v", err)
+			return contextError(ErrQueryFailed, str, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(balance), nil
+}
+
+// DebitCredit debits the given amount from the balance of the credit account.
+// It returns the new balance or serverdb.ErrInsufficientCredit if the balance
+// is lower than the amount.
+func (db *DB) DebitCredit(ctx context.Context, account []byte, amount uint64) (uint64, error) {
+	ctx, task := trace.NewTask(ctx, "debitCredit")
+	defer task.End()
+
+	const query = "UPDATE credit_balances SET " +
+		"balance = balance - $2, updated = $3 " +
+		"WHERE account = $1 AND balance >= $2 " +
+		"RETURNING balance;"
+	row := db.db.QueryRowContext(ctx, query, hex.EncodeToString(account),
+		int64(amount), time.Now().UTC())
+	var balance int64
+	if err := row.Scan(&balance); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, serverdb.ErrInsufficientCredit
+		}
+
+		str := fmt.Sprintf("unable to debit credit: %v", err)
+		return 0, contextError(ErrQueryFailed, str, err)
+	}
+
+	return uint64(balance), nil
+}
+
 // Expire removes all entries that were inserted on the same day as the day
 // associated with the provided date.  The provided date will be converted to
 // UTC if needed.  It returns the number of entries that were removed.
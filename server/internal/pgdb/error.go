@@ -71,6 +71,10 @@ const (
 	// ErrUpgradeV3 indicates an error that happened during the upgrade to
 	// the version 3 database.
 	ErrUpgradeV3 = ErrorKind("ErrUpgradeV3")
+
+	// ErrUpgradeV4 indicates an error that happened during the upgrade to
+	// the version 4 database.
+	ErrUpgradeV4 = ErrorKind("ErrUpgradeV4")
 )
 
 // Error satisfies the error interface and prints human-readable errors.
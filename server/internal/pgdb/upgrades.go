@@ -59,3 +59,49 @@ func upgradeDBToV3(ctx context.Context, tx *sql.Tx, dbInfo *databaseInfo, indexT
 
 	return nil
 }
+
+// upgradeDBToV4 upgrades the database to V4. This involves adding the new
+// tables to store the balances of prepaid credit accounts and the payments
+// redeemed for credit.
+func upgradeDBToV4(ctx context.Context, tx *sql.Tx, dbInfo *databaseInfo,
+	indexTablespace, bulkTablespace string) error {
+
+	if dbInfo.version != 3 {
+		str := fmt.Sprintf("cannot upgrade db to version 4 from version %d",
+			dbInfo.version)
+		return contextError(ErrUpgradeV4, str, nil)
+	}
+
+	const queryTemplate = "CREATE TABLE IF NOT EXISTS credit_balances (" +
+		"	account TEXT NOT NULL," +
+		"	balance BIGINT NOT NULL CHECK (balance >= 0)," +
+		"	updated TIMESTAMP NOT NULL DEFAULT current_timestamp," +
+		"	PRIMARY KEY(account) USING INDEX TABLESPACE %s" +
+		") TABLESPACE %s;"
+	query := fmt.Sprintf(queryTemplate, pq.QuoteIdentifier(indexTablespace),
+		pq.QuoteIdentifier(bulkTablespace))
+
+	_, err := tx.Exec(query)
+	if err != nil {
+		str := fmt.Sprintf("unable to create credit balances table: %v", err)
+		return contextError(ErrUpgradeV4, str, err)
+	}
+
+	const redeemedQueryTemplate = "CREATE TABLE IF NOT EXISTS redeemed_credit_payments (" +
+		"	payment_id TEXT NOT NULL," +
+		"	account TEXT NOT NULL," +
+		"	amount BIGINT NOT NULL," +
+		"	insert_ts TIMESTAMP NOT NULL DEFAULT current_timestamp," +
+		"	PRIMARY KEY(payment_id) USING INDEX TABLESPACE %s" +
+		") TABLESPACE %s;"
+	query = fmt.Sprintf(redeemedQueryTemplate, pq.QuoteIdentifier(indexTablespace),
+		pq.QuoteIdentifier(bulkTablespace))
+
+	_, err = tx.Exec(query)
+	if err != nil {
+		str := fmt.Sprintf("unable to create redeemed credit payments table: %v", err)
+		return contextError(ErrUpgradeV4, str, err)
+	}
+
+	return nil
+}
@@ -128,6 +128,66 @@ func testServerDBInterface(t *testing.T, db serverdb.ServerDB) {
 	assert.NilErr(t, err)
 	assert.DeepEqual(t, isRedeemed, true)
 
+	// Unknown credit accounts have zero balance.
+	account := rv[:]
+	balance, err := db.CreditBalance(ctx, account)
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, balance, uint64(0))
+
+	// Debiting from an empty account fails.
+	_, err = db.DebitCredit(ctx, account, 1)
+	assert.ErrorIs(t, err, serverdb.ErrInsufficientCredit)
+
+	// Add credit twice, then debit from it.
+	balance, err = db.AddCredit(ctx, account, 1000)
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, balance, uint64(1000))
+	balance, err = db.AddCredit(ctx, account, 500)
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, balance, uint64(1500))
+	balance, err = db.DebitCredit(ctx, account, 1200)
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, balance, uint64(300))
+
+	// Debiting more than the balance fails without changing it.
+	_, err = db.DebitCredit(ctx, account, 301)
+	assert.ErrorIs(t, err, serverdb.ErrInsufficientCredit)
+	balance, err = db.CreditBalance(ctx, account)
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, balance, uint64(300))
+
+	// Concurrently redeeming the same payment for credit only adds the
+	// credit once.
+	var payID [32]byte
+	rng.Read(payID[:])
+	const nbRedeems = 10
+	redeemErrs := make(chan error, nbRedeems)
+	for i := 0; i < nbRedeems; i++ {
+		go func() {
+			_, err := db.RedeemCredit(ctx, account, payID[:], 1000, now)
+			redeemErrs <- err
+		}()
+	}
+	var nbRedeemed int
+	for i := 0; i < nbRedeems; i++ {
+		err := <-redeemErrs
+		if err == nil {
+			nbRedeemed++
+			continue
+		}
+		assert.ErrorIs(t, err, serverdb.ErrAlreadyRedeemed)
+	}
+	assert.DeepEqual(t, nbRedeemed, 1)
+	balance, err = db.CreditBalance(ctx, account)
+	assert.NilErr(t, err)
+	assert.DeepEqual(t, balance, uint64(1300))
+
+	// Redeeming after the redemptions of push payments expire still fails.
+	_, err = db.Expire(ctx, now)
+	assert.NilErr(t, err)
+	_, err = db.RedeemCredit(ctx, account, payID[:], 1000, now)
+	assert.ErrorIs(t, err, serverdb.ErrAlreadyRedeemed)
+
 	// Store a different rv to test expiration.
 	rng.Read(rv[:])
 	assert.NilErr(t, db.StorePayload(ctx, rv, data1, now))
@@ -40,7 +40,17 @@ func (z *ZKS) handleRouteMessage(ctx context.Context, writer chan *RPCWrapper,
 		},
 	}
 
-	err := z.isRMPaid(ctx, &r, sc)
+	receipt, err := z.isRMPaid(ctx, &r, sc)
+	if errors.Is(err, rpc.ErrInsufficientCredit) {
+		// Reply with the specific error, so that the client falls back
+		// to paying with an invoice.
+		reply.Payload = rpc.RouteMessageReply{
+			Error: rpc.ErrInsufficientCredit.Error(),
+		}
+		writer <- &reply
+		sc.log.Debugf("handleRouteMessage: %v", err)
+		return nil
+	}
 	if err != nil {
 		// Reply with a generic invoice error.
 		reply.Payload = rpc.RouteMessageReply{
@@ -51,7 +61,7 @@ func (z *ZKS) handleRouteMessage(ctx context.Context, writer chan *RPCWrapper,
 		return nil
 	}
 
-	payload := rpc.RouteMessageReply{}
+	payload := rpc.RouteMessageReply{Receipt: receipt}
 	var invoiceID string
 
 	// Generate the next invoice that needs to be paid, if needed.
@@ -61,6 +71,11 @@ func (z *ZKS) handleRouteMessage(ctx context.Context, writer chan *RPCWrapper,
 		payload.NextInvoice = "free invoice"
 
 	case rpc.PaySchemeDCRLN:
+		// Clients paying from credit do not need invoices.
+		if receipt != nil {
+			break
+		}
+
 		var err error
 		invoiceAction := rpc.InvoiceActionPush
 		payload.NextInvoice, invoiceID, err = z.generateNextLNInvoice(ctx, sc, invoiceAction)
@@ -112,7 +127,21 @@ func (z *ZKS) handleSubscribeRoutedMessages(ctx context.Context, msg rpc.Message
 
 	var payload rpc.SubscribeRoutedMessagesReply
 
-	if err := z.areSubsPaid(ctx, &r, sc); errors.Is(err, rpc.ErrUnpaidSubscriptionRV{}) {
+	receipt, err := z.areSubsPaid(ctx, &r, sc)
+	payload.Receipt = receipt
+	if errors.Is(err, rpc.ErrInsufficientCredit) {
+		// Reply with the specific error, so that the client falls back
+		// to paying with an invoice.
+		payload.Error = err.Error()
+		sc.writer <- &RPCWrapper{
+			Message: rpc.Message{
+				Command: rpc.TaggedCmdSubscribeRoutedMessagesReply,
+				Tag:     msg.Tag,
+			},
+			Payload: payload,
+		}
+		return nil
+	} else if errors.Is(err, rpc.ErrUnpaidSubscriptionRV{}) {
 		// This specific error (unpaid RV) is returned to the client and
 		// then the client session is forcibly closed.
 		payload.Error = err.Error()
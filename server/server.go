@@ -135,7 +135,11 @@ func (z *ZKS) welcome(kx *session.KX) error {
 		case rpc.PropServerLNNode:
 			properties[k].Value = z.lnNode
 		case rpc.PropPushPaymentRate:
-			properties[k].Value = strconv.FormatUint(z.settings.MilliAtomsPerByte, 10)
+			properties[k].Value = strconv.FormatUint(rpc.LegacyPushPaymentRate(z.settings.MilliAtomsPerKB), 10)
+		case rpc.PropPushPaymentRateKB:
+			properties[k].Value = strconv.FormatUint(z.settings.MilliAtomsPerKB, 10)
+		case rpc.PropMinPushPayment:
+			properties[k].Value = strconv.FormatUint(z.settings.MinPushPayment, 10)
 		case rpc.PropSubPaymentRate:
 			properties[k].Value = strconv.FormatUint(z.settings.MilliAtomsPerSub, 10)
 		case rpc.PropExpirationDays:
@@ -156,6 +160,12 @@ func (z *ZKS) welcome(kx *session.KX) error {
 		properties = append(properties, prop)
 	}
 
+	// Only advertise credit when enabled, as older clients do not handle
+	// it.
+	if z.creditEnabled() {
+		properties = append(properties, rpc.DefaultPropCreditEnabled)
+	}
+
 	// assemble command
 	message := rpc.Message{
 		Command: rpc.SessionCmdWelcome,
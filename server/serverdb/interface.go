@@ -10,6 +10,14 @@ import (
 
 var ErrAlreadyStoredRV = errors.New("already stored payload at the RV point")
 
+// ErrInsufficientCredit is returned when debiting more than the balance of a
+// credit account.
+var ErrInsufficientCredit = errors.New("insufficient credit")
+
+// ErrAlreadyRedeemed is returned when redeeming for credit a payment that was
+// already redeemed.
+var ErrAlreadyRedeemed = errors.New("payment already redeemed")
+
 type FetchPayloadResult struct {
 	Payload    []byte
 	InsertTime time.Time
@@ -24,4 +32,24 @@ type ServerDB interface {
 	Expire(ctx context.Context, date time.Time) (uint64, error)
 	IsPushPaymentRedeemed(ctx context.Context, payID []byte) (bool, error)
 	StorePushPaymentRedeemed(ctx context.Context, payID []byte, insertTime time.Time) error
+
+	// CreditBalance returns the balance of the given credit account (zero
+	// if the account does not exist).
+	CreditBalance(ctx context.Context, account []byte) (uint64, error)
+
+	// AddCredit adds the amount to the balance of the given credit account
+	// (creating it if needed) and returns the new balance.
+	AddCredit(ctx context.Context, account []byte, amount uint64) (uint64, error)
+
+	// RedeemCredit records the payment with the given ID as redeemed and
+	// adds the amount to the balance of the given credit account, as a
+	// single atomic operation. It returns the new balance or
+	// ErrAlreadyRedeemed if the payment was already redeemed for credit.
+	RedeemCredit(ctx context.Context, account, payID []byte, amount uint64,
+		insertTime time.Time) (uint64, error)
+
+	// DebitCredit debits the amount from the balance of the given credit
+	// account and returns the new balance. It returns
+	// ErrInsufficientCredit if the balance is lower than the amount.
+	DebitCredit(ctx context.Context, account []byte, amount uint64) (uint64, error)
 }
@@ -415,6 +415,19 @@ func (z *ZKS) sessionReader(ctx context.Context, sc *sessionContext) error {
 					r.Action, err)
 			}
 
+		case rpc.TaggedCmdGetCreditBalance:
+			sc.log.Tracef("TaggedCmdGetCreditBalance")
+
+			var r rpc.GetCreditBalance
+			err = z.unmarshal(dec, &r)
+			if err != nil {
+				return fmt.Errorf("unmarshal GetCreditBalance failed")
+			}
+			err = z.handleGetCreditBalance(ctx, sc, message, r)
+			if err != nil {
+				return fmt.Errorf("handleGetCreditBalance: %v", err)
+			}
+
 		default:
 			return fmt.Errorf("invalid message: %v", message)
 		}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"os/user"
 	"strconv"
@@ -12,6 +13,8 @@ import (
 
 	"github.com/companyzero/bisonrelay/rpc"
 	brpgdb "github.com/companyzero/bisonrelay/server/internal/pgdb"
+	"github.com/decred/dcrlnd/lnrpc"
+	"github.com/decred/dcrlnd/lnrpc/invoicesrpc"
 	"github.com/vaughan0/go-ini"
 )
 
@@ -43,10 +46,12 @@ type Settings struct {
 	LNRPCHost           string
 	LNTLSCert           string
 	LNMacaroonPath      string
-	MilliAtomsPerByte   uint64
+	MilliAtomsPerKB     uint64 // push payment rate
+	MinPushPayment      uint64 // in milliatoms
 	MilliAtomsPerSub    uint64
 	PushPaymentLifetime int // how long a payment to a push is valid
 	MaxPushInvoices     int
+	CreditEnabled       bool // whether clients may prepay credit
 
 	// log section
 	LogFile    string // log filename
@@ -70,6 +75,11 @@ type Settings struct {
 
 	// LogStdOut is the stdout to write the log to. Defaults to os.Stdout.
 	LogStdOut io.Writer
+
+	// LNRPC and LNInvoices, when specified, are used as the dcrlnd clients
+	// instead of connecting to LNRPCHost. Used in tests.
+	LNRPC      lnrpc.LightningClient
+	LNInvoices invoicesrpc.InvoicesClient
 }
 
 var (
@@ -91,7 +101,8 @@ func New() *Settings {
 
 		// payment
 		PayScheme:           "free",
-		MilliAtomsPerByte:   rpc.PropPushPaymentRateDefault,
+		MilliAtomsPerKB:     rpc.PropPushPaymentRateKBDefault,
+		MinPushPayment:      rpc.PropMinPushPaymentDefault,
 		MilliAtomsPerSub:    rpc.PropSubPaymentRateDefault,
 		PushPaymentLifetime: rpc.PropPushPaymentLifetimeDefault,
 		MaxPushInvoices:     rpc.PropMaxPushInvoicesDefault,
@@ -211,12 +222,33 @@ func (s *Settings) Load(filename string) error {
 		s.LNMacaroonPath = strings.Replace(lnMacaroonPath, "~", usr.HomeDir, 1)
 	}
 
+	// The push rate may be specified either per byte or (with finer
+	// control) per kilobyte, with the latter taking precedence.
 	var atomsPerByte float64 = float64(rpc.PropPushPaymentRateDefault) / 1000
 	err = iniFloat(cfg, &atomsPerByte, "payment", "atomsperbyte")
 	if err != nil && !errors.Is(err, errIniNotFound) {
 		return err
 	}
-	s.MilliAtomsPerByte = uint64(atomsPerByte * 1000)
+	atomsPerKB := atomsPerByte * 1000
+	err = iniFloat(cfg, &atomsPerKB, "payment", "atomsperkb")
+	if err != nil && !errors.Is(err, errIniNotFound) {
+		return err
+	}
+	s.MilliAtomsPerKB = uint64(math.Round(atomsPerKB * 1000))
+
+	var minPushAtoms float64 = float64(rpc.PropMinPushPaymentDefault) / 1000
+	err = iniFloat(cfg, &minPushAtoms, "payment", "minpushpayment")
+	if err != nil && !errors.Is(err, errIniNotFound) {
+		return err
+	}
+	s.MinPushPayment = uint64(math.Round(minPushAtoms * 1000))
+
+	// Older clients always pay at least MinRMPushPayment for a push, so a
+	// higher minimum would cause their small messages to be rejected.
+	if s.MinPushPayment > rpc.MinRMPushPayment {
+		return fmt.Errorf("[payment]minpushpayment must not be higher "+
+			"than %.3f atoms", float64(rpc.MinRMPushPayment)/1000)
+	}
 
 	var atomsPerSub float64 = float64(rpc.PropSubPaymentRateDefault) / 1000
 	err = iniFloat(cfg, &atomsPerSub, "payment", "atomspersub")
@@ -225,6 +257,11 @@ func (s *Settings) Load(filename string) error {
 	}
 	s.MilliAtomsPerSub = uint64(atomsPerSub * 1000)
 
+	err = iniBool(cfg, &s.CreditEnabled, "payment", "credit")
+	if err != nil && !errors.Is(err, errIniNotFound) {
+		return err
+	}
+
 	err = iniBool(cfg, &s.PGEnabled, "postgres", "enabled")
 	if err != nil && !errors.Is(err, errIniNotFound) {
 		return err
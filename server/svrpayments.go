@@ -3,12 +3,16 @@ package server
 import (
 	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
 	"time"
 
+	"github.com/companyzero/bisonrelay/ratchet"
 	"github.com/companyzero/bisonrelay/rpc"
+	"github.com/companyzero/bisonrelay/server/serverdb"
+	"github.com/companyzero/bisonrelay/zkidentity"
 	"github.com/decred/dcrlnd/lnrpc"
 	"github.com/decred/dcrlnd/lnrpc/invoicesrpc"
 	"github.com/decred/dcrlnd/macaroons"
@@ -25,38 +29,13 @@ func (z *ZKS) initPayments() error {
 		return nil
 
 	case rpc.PaySchemeDCRLN:
-		// First attempt to establish a connection to lnd's RPC sever.
-		creds, err := credentials.NewClientTLSFromFile(z.settings.LNTLSCert, "")
-		if err != nil {
-			return fmt.Errorf("unable to read cert file: %v", err)
-		}
-		opts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
-
-		// Load the specified macaroon file.
-		macBytes, err := os.ReadFile(z.settings.LNMacaroonPath)
-		if err != nil {
-			return err
-		}
-		mac := &macaroon.Macaroon{}
-		if err = mac.UnmarshalBinary(macBytes); err != nil {
+		if z.settings.LNRPC != nil && z.settings.LNInvoices != nil {
+			z.lnRpc = z.settings.LNRPC
+			z.lnInvoices = z.settings.LNInvoices
+		} else if err := z.dialLN(); err != nil {
 			return err
 		}
 
-		// Now we append the macaroon credentials to the dial options.
-		opts = append(
-			opts,
-			grpc.WithPerRPCCredentials(macaroons.NewMacaroonCredential(mac)),
-		)
-
-		conn, err := grpc.Dial(z.settings.LNRPCHost, opts...)
-		if err != nil {
-			return fmt.Errorf("unable to dial to dcrlnd's gRPC server: %v", err)
-		}
-
-		// Start RPCs.
-		z.lnRpc = lnrpc.NewLightningClient(conn)
-		z.lnInvoices = invoicesrpc.NewInvoicesClient(conn)
-
 		// Check chain and network (mainnet, testnet, etc)?
 		ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
 		defer cancel()
@@ -75,6 +54,52 @@ func (z *ZKS) initPayments() error {
 	}
 }
 
+// dialLN establishes a connection to dcrlnd's RPC server.
+func (z *ZKS) dialLN() error {
+	// First attempt to establish a connection to lnd's RPC sever.
+	creds, err := credentials.NewClientTLSFromFile(z.settings.LNTLSCert, "")
+	if err != nil {
+		return fmt.Errorf("unable to read cert file: %v", err)
+	}
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+
+	// Load the specified macaroon file.
+	macBytes, err := os.ReadFile(z.settings.LNMacaroonPath)
+	if err != nil {
+		return err
+	}
+	mac := &macaroon.Macaroon{}
+	if err = mac.UnmarshalBinary(macBytes); err != nil {
+		return err
+	}
+
+	// Now we append the macaroon credentials to the dial options.
+	opts = append(
+		opts,
+		grpc.WithPerRPCCredentials(macaroons.NewMacaroonCredential(mac)),
+	)
+
+	conn, err := grpc.Dial(z.settings.LNRPCHost, opts...)
+	if err != nil {
+		return fmt.Errorf("unable to dial to dcrlnd's gRPC server: %v", err)
+	}
+
+	// Start RPCs.
+	z.lnRpc = lnrpc.NewLightningClient(conn)
+	z.lnInvoices = invoicesrpc.NewInvoicesClient(conn)
+	return nil
+}
+
+// creditEnabled returns true if clients may pay for pushes and subscriptions
+// from prepaid credit accounts.
+func (z *ZKS) creditEnabled() bool {
+	return z.settings.CreditEnabled && z.settings.PayScheme == rpc.PaySchemeDCRLN
+}
+
+// creditInvoiceMemo is the memo of the invoices generated to buy credit. It is
+// used to identify these invoices when they are redeemed.
+const creditInvoiceMemo = "BR server credit invoice"
+
 func (z *ZKS) generateNextLNInvoice(ctx context.Context, sc *sessionContext, action rpc.GetInvoiceAction) (string, string, error) {
 
 	// Configurable timeout limit?
@@ -86,8 +111,18 @@ func (z *ZKS) generateNextLNInvoice(ctx context.Context, sc *sessionContext, act
 
 	// Check for limits of invoice generation. Depending on the action,
 	// different limits are applied.
+	memo := "BR server invoice"
 	switch action {
-	case rpc.InvoiceActionPush:
+	case rpc.InvoiceActionPush, rpc.InvoiceActionCredit:
+		// Invoices to buy credit are tracked along with push invoices,
+		// because they are redeemed the same way.
+		if action == rpc.InvoiceActionCredit {
+			if !z.creditEnabled() {
+				return "", "", fmt.Errorf("credit is not enabled")
+			}
+			memo = creditInvoiceMemo
+		}
+
 		// When at the limit of max amount of concurrent invoices,
 		// check if any have already expired.
 		if len(sc.lnPushHashes) >= z.settings.MaxPushInvoices {
@@ -142,7 +177,7 @@ func (z *ZKS) generateNextLNInvoice(ctx context.Context, sc *sessionContext, act
 
 	expirySeconds := 3600
 	addInvoiceReq := &lnrpc.Invoice{
-		Memo:   "BR server invoice",
+		Memo:   memo,
 		Expiry: int64(expirySeconds),
 	}
 	addInvoiceRes, err := z.lnRpc.AddInvoice(ctx, addInvoiceReq)
@@ -152,7 +187,7 @@ func (z *ZKS) generateNextLNInvoice(ctx context.Context, sc *sessionContext, act
 
 	// Store the generated invoice to count it towards the limits.
 	switch action {
-	case rpc.InvoiceActionPush:
+	case rpc.InvoiceActionPush, rpc.InvoiceActionCredit:
 		// Track when this invoice will expire.
 		var hash [32]byte
 		copy(hash[:], addInvoiceRes.RHash)
@@ -214,26 +249,141 @@ func (z *ZKS) handleGetInvoice(ctx context.Context, sc *sessionContext,
 	return nil
 }
 
+// checkInvoiceSettled verifies that the invoice with the given payment hash
+// was generated for the given action, that it was settled with at least
+// wantMAtoms, recently enough to be redeemed, and that it was not redeemed yet.
+// Invoices to buy credit may be redeemed at any time after being settled. It
+// returns the amount paid to the invoice.
+func (z *ZKS) checkInvoiceSettled(ctx context.Context, payID []byte,
+	action rpc.GetInvoiceAction, wantMAtoms int64) (int64, error) {
+
+	// Verify the potentially paid invoice was not redeemed yet.
+	redeemed, err := z.db.IsPushPaymentRedeemed(ctx, payID)
+	if err != nil {
+		return 0, err
+	}
+	if redeemed {
+		return 0, fmt.Errorf("already redeemed invoice %x", payID)
+	}
+
+	// Verify the invoice was settled.
+	lookupReq := &lnrpc.PaymentHash{
+		RHash: payID,
+	}
+
+	maxLifetimeDuration := time.Duration(z.settings.PushPaymentLifetime) * time.Second
+	payTimeLimit := time.Now().Add(-maxLifetimeDuration)
+
+	lookupRes, err := z.lnRpc.LookupInvoice(ctx, lookupReq)
+	if err != nil {
+		return 0, err
+	}
+	isCredit := lookupRes.Memo == creditInvoiceMemo
+	switch {
+	case action == rpc.InvoiceActionCredit && !isCredit:
+		return 0, fmt.Errorf("LN invoice was not generated to buy credit")
+
+	case action != rpc.InvoiceActionCredit && isCredit:
+		return 0, fmt.Errorf("LN invoice was generated to buy credit")
+
+	case lookupRes.State == lnrpc.Invoice_CANCELED:
+		return 0, fmt.Errorf("LN invoice canceled")
+
+	case lookupRes.State != lnrpc.Invoice_SETTLED:
+		return 0, fmt.Errorf("Unexpected LN state: %d", lookupRes.State)
+
+	case lookupRes.AmtPaidMAtoms < wantMAtoms:
+		// Also have upper limit if overpaid?
+		return 0, fmt.Errorf("LN invoice not sufficiently paid "+
+			"(got %d, want %d)", lookupRes.AmtPaidMAtoms, wantMAtoms)
+
+	case !isCredit && time.Unix(lookupRes.SettleDate, 0).Before(payTimeLimit):
+		return 0, fmt.Errorf("LN invoice settled at %s while limit "+
+			"date for redemption is %s",
+			time.Unix(lookupRes.SettleDate, 0), payTimeLimit)
+	}
+
+	z.stats.invoicesRecv.add(1)
+	z.stats.matomsRecv.add(lookupRes.AmtPaidMAtoms)
+	return lookupRes.AmtPaidMAtoms, nil
+}
+
+// redeemInvoice stores that the invoice with the given payment hash was
+// redeemed, so that it cannot be used to pay for anything else.
+func (z *ZKS) redeemInvoice(ctx context.Context, payID []byte, sc *sessionContext) error {
+	err := z.db.StorePushPaymentRedeemed(ctx, payID, time.Now())
+	z.untrackInvoice(payID, sc)
+	return err
+}
+
+// untrackInvoice removes the invoice with the given payment hash from the
+// total amount of concurrent invoices of the session.
+func (z *ZKS) untrackInvoice(payID []byte, sc *sessionContext) {
+	var hash [32]byte
+	copy(hash[:], payID)
+	sc.Lock()
+	delete(sc.lnPushHashes, hash)
+	sc.Unlock()
+}
+
+// signedCreditReceipt returns a receipt for a change in the balance of the
+// credit account, signed by the server.
+func (z *ZKS) signedCreditReceipt(account zkidentity.ShortID, action rpc.GetInvoiceAction,
+	milliAtoms int64, balance uint64) *rpc.CreditReceipt {
+
+	receipt := &rpc.CreditReceipt{
+		Account:    account,
+		Action:     action,
+		MilliAtoms: milliAtoms,
+		Balance:    balance,
+		Timestamp:  time.Now().Unix(),
+	}
+	receipt.Signature = z.id.SignMessage(receipt.SignedHash())
+	return receipt
+}
+
+// debitCredit debits the amount from the given credit account and returns
+// the receipt of the debit. It returns rpc.ErrInsufficientCredit if the
+// balance of the account is lower than the amount.
+func (z *ZKS) debitCredit(ctx context.Context, account zkidentity.ShortID,
+	action rpc.GetInvoiceAction, amount uint64, sc *sessionContext) (*rpc.CreditReceipt, error) {
+
+	balance, err := z.db.DebitCredit(ctx, account[:], amount)
+	if errors.Is(err, serverdb.ErrInsufficientCredit) {
+		return nil, rpc.ErrInsufficientCredit
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sc.log.Debugf("Debited %d MAtoms from credit for action %q "+
+		"(balance %d MAtoms)", amount, action, balance)
+	return z.signedCreditReceipt(account, action, -int64(amount), balance), nil
+}
+
 // isRMPaid returns whether the received routed message was paid for. Returns
-// nil if it is paid, or an error if not.
-func (z *ZKS) isRMPaid(ctx context.Context, rm *rpc.RouteMessage, sc *sessionContext) error {
+// nil if it is paid, or an error if not. When the RM is paid from the prepaid
+// credit of an account, the receipt of the debit is returned.
+func (z *ZKS) isRMPaid(ctx context.Context, rm *rpc.RouteMessage, sc *sessionContext) (*rpc.CreditReceipt, error) {
 	switch z.settings.PayScheme {
 	case rpc.PaySchemeFree:
-		return nil
+		return nil, nil
 
 	case rpc.PaySchemeDCRLN:
-		msgLen := int64(len(rm.Message))
-		wantMAtoms := msgLen * int64(z.settings.MilliAtomsPerByte)
+		msgLen := uint64(len(rm.Message))
+		wantMAtoms := int64(rpc.PushPaymentAmount(msgLen,
+			z.settings.MilliAtomsPerKB, z.settings.MinPushPayment))
 
-		// Enforce the minimum payment policy.
-		if wantMAtoms < int64(rpc.MinRMPushPayment) {
-			wantMAtoms = int64(rpc.MinRMPushPayment)
-		}
-
-		var err error
 		if wantMAtoms < 0 {
 			// Sanity check. Should never happen.
-			err = fmt.Errorf("wantMAtoms (%d) < 0", wantMAtoms)
+			return nil, fmt.Errorf("wantMAtoms (%d) < 0", wantMAtoms)
+		}
+
+		// Pay from the credit account when the client did not pay an
+		// invoice.
+		if rm.PaidInvoiceID == nil && rm.CreditAccount != nil && z.creditEnabled() {
+			return z.debitCredit(ctx, *rm.CreditAccount,
+				rpc.InvoiceActionPush, uint64(wantMAtoms), sc)
 		}
 
 		// Compat to old clients: if the PaidInvoiceID field is nil and
@@ -252,97 +402,40 @@ func (z *ZKS) isRMPaid(ctx context.Context, rm *rpc.RouteMessage, sc *sessionCon
 		}
 
 		// Sanity check paid invoice id.
-		if err == nil && len(paidInvoiceID) != 32 {
-			err = fmt.Errorf("paid invoice ID was not specified")
+		if len(paidInvoiceID) != 32 {
+			return nil, fmt.Errorf("paid invoice ID was not specified")
 		}
 
-		// Verify the potentially paid invoice was not redeemed yet.
-		if err == nil {
-			var redeemed bool
-			redeemed, err = z.db.IsPushPaymentRedeemed(ctx, paidInvoiceID)
-			if err == nil && redeemed {
-				err = fmt.Errorf("already redeemed invoice %x", paidInvoiceID)
-			}
-		}
-
-		// Verify the invoice was settled.
-		if err == nil {
-			lookupReq := &lnrpc.PaymentHash{
-				RHash: paidInvoiceID,
-			}
-
-			maxLifetimeDuration := time.Duration(z.settings.PushPaymentLifetime) * time.Second
-			payTimeLimit := time.Now().Add(-maxLifetimeDuration)
-
-			// Use a 5-second timeout context to avoid stalling the
-			// server.
-			var lookupRes *lnrpc.Invoice
-			lookupRes, err = z.lnRpc.LookupInvoice(ctx, lookupReq)
-			if lookupRes != nil {
-				switch {
-				case lookupRes.State == lnrpc.Invoice_CANCELED:
-					err = fmt.Errorf("LN invoice canceled")
-
-				case lookupRes.State != lnrpc.Invoice_SETTLED:
-					err = fmt.Errorf("Unexpected LN state: %d",
-						lookupRes.State)
-
-				case lookupRes.AmtPaidMAtoms < wantMAtoms:
-					// Also have upper limit if
-					// overpaid?
-					err = fmt.Errorf("LN invoice not "+
-						"sufficiently paid (got %d, want %d)",
-						lookupRes.AmtPaidMAtoms, wantMAtoms)
-
-				case time.Unix(lookupRes.SettleDate, 0).Before(payTimeLimit):
-					err = fmt.Errorf("LN invoice settled at %s "+
-						"while limit date for redemption "+
-						"is %s", time.Unix(lookupRes.SettleDate, 0),
-						payTimeLimit)
-
-				default:
-					z.stats.invoicesRecv.add(1)
-					z.stats.matomsRecv.add(lookupRes.AmtPaidMAtoms)
-
-					// Everything ok.
-					sc.log.Debugf("LN invoice %x settled "+
-						"w/ %d MAtoms for %d bytes",
-						lookupRes.RHash,
-						lookupRes.AmtPaidMAtoms,
-						msgLen)
-				}
-			}
+		amtPaid, err := z.checkInvoiceSettled(ctx, paidInvoiceID,
+			rpc.InvoiceActionPush, wantMAtoms)
+		if err != nil {
+			return nil, err
 		}
 
-		if err == nil {
-			// Store that the invoice was redeemed.
-			err = z.db.StorePushPaymentRedeemed(ctx, paidInvoiceID, time.Now())
-
-			// And decrement from total amount of concurrent invoices.
-			var hash [32]byte
-			copy(hash[:], paidInvoiceID)
-			sc.Lock()
-			delete(sc.lnPushHashes, hash)
-			sc.Unlock()
-		}
+		// Everything ok.
+		sc.log.Debugf("LN invoice %x settled w/ %d MAtoms for %d bytes",
+			paidInvoiceID, amtPaid, msgLen)
 
-		return err
+		// Store that the invoice was redeemed.
+		return nil, z.redeemInvoice(ctx, paidInvoiceID, sc)
 	default:
-		return fmt.Errorf("unimplemented isNextRMPaid for scheme %s",
+		return nil, fmt.Errorf("unimplemented isNextRMPaid for scheme %s",
 			z.settings.PayScheme)
 	}
 }
 
 // areSubsPaid verifies whether all subscriptions in the given message were paid
-// for, either previously or with the most recent payment.
-func (z *ZKS) areSubsPaid(ctx context.Context, r *rpc.SubscribeRoutedMessages, sc *sessionContext) error {
+// for, either previously, with the most recent payment or from the prepaid
+// credit of an account. In the latter case, the receipt of the debit is
+// returned.
+func (z *ZKS) areSubsPaid(ctx context.Context, r *rpc.SubscribeRoutedMessages, sc *sessionContext) (*rpc.CreditReceipt, error) {
 	var err error
 	var nbAllowed int64 // nb of max new entries allowed, based on paid invoice
 
 	switch z.settings.PayScheme {
 	case rpc.PaySchemeFree:
 		// Always paid.
-		return nil
+		return nil, nil
 
 	case rpc.PaySchemeDCRLN:
 		sc.Lock()
@@ -396,23 +489,41 @@ func (z *ZKS) areSubsPaid(ctx context.Context, r *rpc.SubscribeRoutedMessages, s
 	}
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Store in DB the new unpaid items.
+	// Determine the new unpaid items.
+	var unpaid []ratchet.RVPoint
 	for _, rv := range r.AddRendezvous {
 		if paid, err := z.db.IsSubscriptionPaid(ctx, rv); err != nil {
-			return err
-		} else if paid {
-			continue
+			return nil, err
+		} else if !paid {
+			unpaid = append(unpaid, rv)
 		}
+	}
 
+	// Pay from the credit account for the subscriptions that were not
+	// paid with the last invoice.
+	var receipt *rpc.CreditReceipt
+	needed := int64(len(unpaid)) - nbAllowed
+	if needed > 0 && r.CreditAccount != nil && z.creditEnabled() {
+		amount := uint64(needed) * z.settings.MilliAtomsPerSub
+		receipt, err = z.debitCredit(ctx, *r.CreditAccount,
+			rpc.InvoiceActionSub, amount, sc)
+		if err != nil {
+			return nil, err
+		}
+		nbAllowed += needed
+	}
+
+	// Store in DB the new unpaid items.
+	for _, rv := range unpaid {
 		if nbAllowed <= 0 {
-			return rpc.ErrUnpaidSubscriptionRV(rv)
+			return receipt, rpc.ErrUnpaidSubscriptionRV(rv)
 		}
 		nbAllowed -= 1
 		if err := z.db.StoreSubscriptionPaid(z.dbCtx, rv, time.Now()); err != nil {
-			return err
+			return receipt, err
 		}
 		sc.log.Debugf("Stored RV %s as paid", rv)
 	}
@@ -422,7 +533,70 @@ func (z *ZKS) areSubsPaid(ctx context.Context, r *rpc.SubscribeRoutedMessages, s
 			"performed", nbAllowed)
 	}
 
-	return err
+	return receipt, nil
+}
+
+// handleGetCreditBalance replies with the balance of a credit account, after
+// adding to it the amount paid to an invoice requested to buy credit.
+//
+// Invoices to buy credit are not limited by the push payment lifetime: the
+// redemptions of these invoices are never expired, so they may be redeemed at
+// any time.
+func (z *ZKS) handleGetCreditBalance(ctx context.Context, sc *sessionContext,
+	msg rpc.Message, r rpc.GetCreditBalance) error {
+
+	var reply rpc.GetCreditBalanceReply
+	err := func() error {
+		if !z.creditEnabled() {
+			return fmt.Errorf("credit is not enabled")
+		}
+
+		var err error
+		if r.PaidInvoiceID == nil {
+			reply.Balance, err = z.db.CreditBalance(ctx, r.Account[:])
+			return err
+		}
+
+		if len(r.PaidInvoiceID) != 32 {
+			return fmt.Errorf("invalid paid invoice ID")
+		}
+		amtPaid, err := z.checkInvoiceSettled(ctx, r.PaidInvoiceID,
+			rpc.InvoiceActionCredit, 1)
+		if err != nil {
+			return err
+		}
+
+		// Redeeming the invoice and adding the credit is atomic, so
+		// concurrent requests cannot redeem the same invoice twice.
+		reply.Balance, err = z.db.RedeemCredit(ctx, r.Account[:],
+			r.PaidInvoiceID, uint64(amtPaid), time.Now())
+		if errors.Is(err, serverdb.ErrAlreadyRedeemed) {
+			return rpc.ErrCreditAlreadyRedeemed
+		}
+		if err != nil {
+			return err
+		}
+		z.untrackInvoice(r.PaidInvoiceID, sc)
+
+		sc.log.Debugf("LN invoice %x settled w/ %d MAtoms for credit "+
+			"(balance %d MAtoms)", r.PaidInvoiceID, amtPaid, reply.Balance)
+		reply.Receipt = z.signedCreditReceipt(r.Account,
+			rpc.InvoiceActionCredit, amtPaid, reply.Balance)
+		return nil
+	}()
+	if err != nil {
+		sc.log.Warnf("Unable to get credit balance: %v", err)
+		reply.Error = err.Error()
+	}
+
+	sc.writer <- &RPCWrapper{
+		Message: rpc.Message{
+			Command: rpc.TaggedCmdGetCreditBalanceReply,
+			Tag:     msg.Tag,
+		},
+		Payload: reply,
+	}
+	return nil
 }
 
 func (z *ZKS) cancelLNInvoice(ctx context.Context, hash []byte) error {
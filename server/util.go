@@ -33,6 +33,8 @@ func payloadForCmd(cmd string) (interface{}, error) {
 		p = new(rpc.PushRoutedMessage)
 	case rpc.TaggedCmdGetInvoiceReply:
 		p = new(rpc.GetInvoiceReply)
+	case rpc.TaggedCmdGetCreditBalanceReply:
+		p = new(rpc.GetCreditBalanceReply)
 	default:
 		return nil, errUnknownRPCCommand
 	}